@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/control"
+	"github.com/ausil/i2c-display/internal/ctl"
+)
+
+// command describes an HTTP request sent to each selected display daemon
+type command struct {
+	method  string
+	path    string
+	summary string
+	args    string                              // argument synopsis for usage output
+	body    func(args []string) (string, error) // builds the request body; nil takes no arguments
+}
+
+var commands = map[string]command{
+	"health": {method: http.MethodGet, path: "/health", summary: "Report daemon health"},
+	"wake":   {method: http.MethodPost, path: "/wake", summary: "Wake the display from the screensaver"},
+	"pause":  {method: http.MethodPost, path: "/pause", summary: "Stop page rotation on the current page"},
+	"resume": {method: http.MethodPost, path: "/resume", summary: "Restart page rotation"},
+	"hold": {
+		method:  http.MethodPost,
+		path:    "/hold",
+		summary: "Show a page (index or type) and hold it, optionally for a duration",
+		args:    "<page> [duration]",
+		body:    holdBody,
+	},
+	"message": {
+		method:  http.MethodPost,
+		path:    "/api/message",
+		summary: "Show a text message in place of page rotation, 30s by default",
+		args:    "<text> [duration]",
+		body:    messageBody,
+	},
+	"loglevel": {
+		method:  http.MethodPut,
+		path:    "/api/loglevel",
+		summary: "Change the daemon log level until restart",
+		args:    "<debug|info|warn|error>",
+		body:    logLevelBody,
+	},
+}
+
+// messageBody builds the /api/message request from the text and an optional
+// duration
+func messageBody(args []string) (string, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return "", fmt.Errorf("usage: message <text> [duration]")
+	}
+	req := map[string]string{"text": args[0]}
+	if len(args) == 2 {
+		if _, err := time.ParseDuration(args[1]); err != nil {
+			return "", fmt.Errorf("invalid duration %q: %w", args[1], err)
+		}
+		req["duration"] = args[1]
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// logLevelBody builds the /api/loglevel request. The level is checked by the
+// daemon so the error names its accepted levels.
+func logLevelBody(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("usage: loglevel <debug|info|warn|error>")
+	}
+	data, err := json.Marshal(map[string]string{"level": args[0]})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// holdBody builds the /hold request from a page index or page type and an
+// optional duration
+func holdBody(args []string) (string, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return "", fmt.Errorf("usage: hold <page> [duration]")
+	}
+	req := map[string]any{}
+	if idx, err := strconv.Atoi(args[0]); err == nil {
+		req["page"] = idx
+	} else {
+		req["type"] = args[0]
+	}
+	if len(args) == 2 {
+		if _, err := time.ParseDuration(args[1]); err != nil {
+			return "", fmt.Errorf("invalid duration %q: %w", args[1], err)
+		}
+		req["duration"] = args[1]
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// sortedCommandNames returns command names in alphabetical order for usage output
+func sortedCommandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] <command> [args]\n\nCommands:\n", os.Args[0])
+	for _, name := range sortedCommandNames() {
+		cmd := commands[name]
+		fmt.Fprintf(os.Stderr, "  %-24s %s\n", strings.TrimSpace(name+" "+cmd.args), cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nLocal control socket commands:\n")
+	for _, name := range sortedSocketCommandNames() {
+		cmd := socketCommands[name]
+		fmt.Fprintf(os.Stderr, "  %-24s %s\n", strings.TrimSpace(name+" "+cmd.args), cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	hostList := flag.String("hosts", "", "Comma-separated host[:port] list (overrides -hosts-file)")
+	hostsFile := flag.String("hosts-file", ctl.DefaultHostsFile, "File listing display hosts and their groups")
+	group := flag.String("group", "", "Only address hosts in this group")
+	parallel := flag.Int("parallel", 8, "Maximum number of hosts contacted concurrently")
+	timeout := flag.Duration("timeout", 5*time.Second, "Timeout for the command on each host, from when it is contacted")
+	socket := flag.String("socket", control.DefaultSocket, "Control socket used by the local socket commands")
+	useTLS := flag.Bool("tls", false, "Connect to daemons over HTTPS")
+	caFile := flag.String("ca-cert", "", "CA certificate verifying the daemons (implies -tls; default: system roots)")
+	certFile := flag.String("cert", "", "Client certificate for daemons requiring one (implies -tls)")
+	keyFile := flag.String("key", "", "Key of the client certificate")
+	token := flag.String("token", os.Getenv("I2C_DISPLAY_TOKEN"), "Bearer token for daemons requiring one (default $I2C_DISPLAY_TOKEN)")
+	user := flag.String("user", os.Getenv("I2C_DISPLAY_USER"), "user:password for daemons requiring basic auth (default $I2C_DISPLAY_USER)")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+	if sockCmd, ok := socketCommands[flag.Arg(0)]; ok {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		err := runSocketCommand(ctx, *socket, sockCmd, flag.Args()[1:])
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	var body string
+	if cmd.body != nil {
+		var err error
+		if body, err = cmd.body(flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+	} else if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "%s takes no arguments\n", flag.Arg(0))
+		os.Exit(2)
+	}
+
+	hosts, err := resolveHosts(*hostList, *hostsFile, *group)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	client, err := newClient(*useTLS, *caFile, *certFile, *keyFile, *token, *user)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+	results := ctl.Broadcast(context.Background(), hosts, *parallel, *timeout, func(ctx context.Context, h ctl.Host) (string, error) {
+		return client.Do(ctx, h, cmd.method, cmd.path, body)
+	})
+
+	if !report(results, len(hosts) > 1) {
+		os.Exit(1)
+	}
+}
+
+// newClient creates the control client with the TLS and credential flags
+func newClient(useTLS bool, caFile, certFile, keyFile, token, user string) (*ctl.Client, error) {
+	client := ctl.NewClient()
+	client.Token = token
+	if user != "" {
+		name, password, ok := strings.Cut(user, ":")
+		if !ok {
+			return nil, fmt.Errorf("-user must be user:password")
+		}
+		client.Username, client.Password = name, password
+	}
+	if useTLS || caFile != "" || certFile != "" || keyFile != "" {
+		tlsConfig, err := ctl.TLSConfig(caFile, certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		client.HTTPS = true
+		client.HTTP.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+	return client, nil
+}
+
+// resolveHosts picks the target hosts from the explicit list or hosts file
+func resolveHosts(hostList, hostsFile, group string) ([]ctl.Host, error) {
+	var hosts []ctl.Host
+	var err error
+	if hostList != "" {
+		hosts, err = ctl.HostsFromList(hostList)
+	} else if _, statErr := os.Stat(hostsFile); statErr == nil {
+		hosts, err = ctl.LoadHostsFile(hostsFile)
+	} else {
+		hosts = []ctl.Host{{Addr: "127.0.0.1:9090"}}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hosts = ctl.SelectHosts(hosts, group)
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no hosts selected")
+	}
+	return hosts, nil
+}
+
+// report prints one line per host and returns true if every host succeeded
+func report(results []ctl.Result, prefix bool) bool {
+	ok := true
+	for _, r := range results {
+		status, text := "ok", r.Output
+		if r.Err != nil {
+			ok = false
+			status, text = "FAILED", r.Err.Error()
+		}
+		if prefix {
+			fmt.Printf("%-24s %-6s %s\n", r.Host.Addr, status, text)
+		} else if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", text)
+		} else if text != "" {
+			fmt.Println(text)
+		}
+	}
+	return ok
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/control"
+)
+
+// socketCommand describes a request sent over the local control socket
+// rather than HTTP, for systems that do not expose the metrics port
+type socketCommand struct {
+	name    string // control protocol command
+	summary string
+	args    string                           // argument synopsis for usage output
+	build   func(args []string) (any, error) // builds the request args; nil takes no arguments
+}
+
+var socketCommands = map[string]socketCommand{
+	"status": {name: control.CommandStatus, summary: "Report version, uptime, current page and health"},
+	"next":   {name: control.CommandNext, summary: "Advance to the next page now"},
+	"show-message": {
+		name:    control.CommandShowMessage,
+		summary: "Show a text message in place of page rotation, 30s by default",
+		args:    "<text> [duration]",
+		build:   showMessageArgs,
+	},
+	"reload": {name: control.CommandReload, summary: "Reload the daemon configuration"},
+}
+
+// showMessageArgs builds the show-message args from the text and an
+// optional duration
+func showMessageArgs(args []string) (any, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("usage: show-message <text> [duration]")
+	}
+	req := control.MessageArgs{Text: args[0]}
+	if len(args) == 2 {
+		if _, err := time.ParseDuration(args[1]); err != nil {
+			return nil, fmt.Errorf("invalid duration %q: %w", args[1], err)
+		}
+		req.Duration = args[1]
+	}
+	return req, nil
+}
+
+// sortedSocketCommandNames returns socket command names in alphabetical
+// order for usage output
+func sortedSocketCommandNames() []string {
+	names := make([]string, 0, len(socketCommands))
+	for name := range socketCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runSocketCommand sends cmd to the control socket and prints its result
+func runSocketCommand(ctx context.Context, socket string, cmd socketCommand, args []string) error {
+	var reqArgs any
+	if cmd.build != nil {
+		var err error
+		if reqArgs, err = cmd.build(args); err != nil {
+			return err
+		}
+	} else if len(args) != 0 {
+		return fmt.Errorf("%s takes no arguments", cmd.name)
+	}
+
+	result, err := control.Call(ctx, socket, cmd.name, reqArgs)
+	if err != nil {
+		return err
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, result, "", "  "); err != nil {
+		return fmt.Errorf("invalid result: %w", err)
+	}
+	fmt.Println(out.String())
+	return nil
+}
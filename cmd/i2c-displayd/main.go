@@ -2,32 +2,88 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"image/color"
+	"io"
+	"maps"
+	"net"
 	"os"
 	"os/signal"
+	"reflect"
+	"strconv"
+	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/ausil/i2c-display/internal/alerts"
+	"github.com/ausil/i2c-display/internal/autobrightness"
+	"github.com/ausil/i2c-display/internal/backlight"
+	"github.com/ausil/i2c-display/internal/bench"
+	"github.com/ausil/i2c-display/internal/buttons"
+	"github.com/ausil/i2c-display/internal/calibration"
 	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/control"
 	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/fan"
+	"github.com/ausil/i2c-display/internal/health"
+	"github.com/ausil/i2c-display/internal/i18n"
 	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/logind"
 	"github.com/ausil/i2c-display/internal/metrics"
+	"github.com/ausil/i2c-display/internal/mqtt"
+	"github.com/ausil/i2c-display/internal/nightmode"
+	"github.com/ausil/i2c-display/internal/panellock"
+	"github.com/ausil/i2c-display/internal/plugin"
 	"github.com/ausil/i2c-display/internal/renderer"
 	"github.com/ausil/i2c-display/internal/rotation"
 	"github.com/ausil/i2c-display/internal/screensaver"
+	"github.com/ausil/i2c-display/internal/sdnotify"
+	"github.com/ausil/i2c-display/internal/setup"
+	"github.com/ausil/i2c-display/internal/soak"
 	"github.com/ausil/i2c-display/internal/stats"
+	"github.com/ausil/i2c-display/internal/thermal"
+	"github.com/ausil/i2c-display/internal/timeseries"
 )
 
+// takeoverTimeout is how long -takeover waits for the previous instance to
+// shut down and release the display
+const takeoverTimeout = 15 * time.Second
+
 //nolint:funlen,gocyclo // main function naturally has many statements for initialization
 func main() {
+	// "i2c-displayd setup" runs the setup wizard instead of the daemon
+	if len(os.Args) > 1 && os.Args[1] == "setup" {
+		runSetup(os.Args[2:])
+		return
+	}
+
 	// Parse command-line flags
 	configPath := flag.String("config", "", "Path to configuration file")
 	useMock := flag.Bool("mock", false, "Use mock display (for testing without hardware)")
 	validateConfig := flag.Bool("validate-config", false, "Validate configuration and exit")
 	testDisplay := flag.Bool("test-display", false, "Run display hardware test pattern and exit")
+	takeover := flag.Bool("takeover", false, "Ask another instance driving the same display to exit, then take it over")
+	recordPath := flag.String("record", "", "Record the collected stats to this file, for -replay")
+	replayPath := flag.String("replay", "", "Render stats played back from a file written by -record instead of collecting them")
+	printSchema := flag.Bool("print-config-schema", false, "Print a JSON schema of the configuration file and exit")
+	soakFor := flag.Duration("soak", 0, "Flush worst-case frames back to back for this long, report error rates and flush latencies, and exit")
+	benchFor := flag.Duration("bench", 0, "Animate the display as fast as it goes for this long, report frames/sec, bytes/sec and allocations, and exit")
+	printEffective := flag.Bool("print-effective-config", false, "Print every setting with its value and where it came from, and exit")
 	flag.Parse()
 
+	if *printSchema {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(config.Schema()); err != nil {
+			logger.NewDefault().FatalWithErr(err, "Failed to print configuration schema")
+		}
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.LoadWithPriority(*configPath)
 	if err != nil {
@@ -36,6 +92,13 @@ func main() {
 		log.FatalWithErr(err, "Failed to load configuration")
 	}
 
+	if *printEffective {
+		if err := printSettings(os.Stdout, cfg); err != nil {
+			logger.NewDefault().FatalWithErr(err, "Failed to print configuration")
+		}
+		return
+	}
+
 	// If validate-config flag is set, validate and exit
 	if *validateConfig {
 		log := logger.NewDefault()
@@ -56,21 +119,58 @@ func main() {
 	})
 	logger.SetGlobalLogger(log)
 
-	log.Info("I2C Display Service starting...")
+	startTime := time.Now()
+	buildVer, buildCommit := buildVersion()
+	log.With().Str("version", buildVer).Str("commit", buildCommit).Logger().Info("I2C Display Service starting...")
 	log.With().Str("type", cfg.Display.Type).Logger().Info("Display configuration loaded")
 	log.With().Str("mode", cfg.SystemInfo.HostnameDisplay).Logger().Info("Hostname display mode configured")
 
+	// Resolve display auto-detection before anything depends on dimensions.
+	// Keep the configured values so SIGHUP reloads compare like with like.
+	configuredDisplay := cfg.Display
+	if cfg.Display.Type == config.DisplayTypeAuto {
+		resolveDisplayType(&cfg.Display, *useMock, log)
+	} else if len(cfg.Display.I2CAddresses) > 1 && !*useMock {
+		resolveI2CAddress(&cfg.Display, log)
+	}
+
+	// Only one instance may drive a panel; mock and preview displays need no lock
+	if !*useMock && !cfg.Display.IsPreview() {
+		lock, err := lockDisplay(&cfg.Display, *takeover, log)
+		if err != nil {
+			log.FatalWithErr(err, "Failed to lock display")
+		}
+		defer func() {
+			if err := lock.Release(); err != nil {
+				log.ErrorWithErr(err, "Error releasing display lock")
+			}
+		}()
+	}
+
 	// Create display
 	var disp display.Display
 	if *useMock {
 		log.Info("Using mock display (no hardware)")
 		disp = display.NewMockDisplay(cfg.Display.Width, cfg.Display.Height)
+	} else if cfg.Display.IsPreview() {
+		if cfg.Display.IsTerminal() && !strings.EqualFold(cfg.Logging.Output, "stderr") {
+			log.Warn("Terminal display draws on stdout; set logging.output to \"stderr\" and redirect it to keep logs out of the frame")
+		}
+		disp, err = display.NewDisplay(&cfg.Display)
+		if err != nil {
+			log.FatalWithErr(err, "Failed to create preview display")
+		}
 	} else {
-		log.With().
-			Str("type", cfg.Display.Type).
-			Str("bus", cfg.Display.I2CBus).
-			Str("address", cfg.Display.I2CAddress).
-			Logger().Info("Initializing display hardware")
+		initLog := log.With().Str("type", cfg.Display.Type)
+		if cfg.Display.IsFramebuffer() {
+			initLog = initLog.Str("device", cfg.Display.FBDevice)
+		} else {
+			initLog = initLog.Str("bus", cfg.Display.I2CBus).Str("address", cfg.Display.I2CAddress)
+		}
+		initLog.Logger().Info("Initializing display hardware")
+		if cfg.Display.FaultInjectionRate > 0 {
+			log.With().Float64("rate", cfg.Display.FaultInjectionRate).Logger().Warn("Display fault injection enabled — hardware operations will fail randomly")
+		}
 		hardwareDisp, err := display.NewDisplay(&cfg.Display)
 		if err != nil {
 			log.ErrorWithErr(err, "Failed to initialize hardware display")
@@ -81,9 +181,80 @@ func main() {
 		}
 	}
 
+	// Retry transient bus errors on real hardware, below recovery so only
+	// errors that outlast the retries count towards re-initialization
+	_, isMock := disp.(*display.MockDisplay)
+	var busRetry *display.BusRetry
+	if !isMock && !cfg.Display.IsPreview() {
+		busRetry = display.NewBusRetry(log)
+		disp = busRetry.Wrap(disp)
+	}
+
 	// Initialize display
 	if err := disp.Init(); err != nil {
-		log.FatalWithErr(err, "Failed to initialize display")
+		log.With().Err(err).Str("class", display.ClassifyError(err).String()).Logger().Fatal("Failed to initialize display")
+	}
+
+	// Automatically re-create real hardware after persistent I/O errors
+	var recovering *display.RecoveringDisplay
+	if !isMock && cfg.Display.ReinitAfterErrors > 0 {
+		displayCfg := cfg.Display
+		recovering = display.NewRecoveringDisplay(disp, func() (display.Display, error) {
+			fresh, err := display.NewDisplay(&displayCfg)
+			if err != nil || busRetry == nil {
+				return fresh, err
+			}
+			return busRetry.Wrap(fresh), nil
+		}, cfg.Display.ReinitAfterErrors, log)
+		disp = recovering
+	}
+
+	// The soak test and benchmark want every flush to reach the panel, so
+	// they run before the frame skipping and background flushing below
+	if *benchFor > 0 {
+		driver := cfg.Display.Type
+		if *useMock {
+			driver = "mock"
+		}
+		if err := runBench(disp, driver, *benchFor, log); err != nil {
+			_ = disp.Close()
+			log.FatalWithErr(err, "Benchmark failed")
+		}
+		if err := disp.Close(); err != nil {
+			log.ErrorWithErr(err, "Error closing display")
+		}
+		return
+	}
+	if *soakFor > 0 {
+		if err := runSoak(disp, *soakFor, log); err != nil {
+			_ = disp.Close()
+			log.FatalWithErr(err, "Soak test failed")
+		}
+		if err := disp.Close(); err != nil {
+			log.ErrorWithErr(err, "Error closing display")
+		}
+		return
+	}
+
+	// Skip flushing frames identical to the one already on the panel
+	var dedup *display.DedupDisplay
+	if !cfg.Display.RefreshUnchanged {
+		dedup = display.NewDedupDisplay(disp)
+		disp = dedup
+	}
+
+	// Record when frames get through for the stall watchdog. It sits below
+	// the double buffer, whose Show returns before the frame is flushed.
+	var watched *display.WatchedDisplay
+	if cfg.Display.StallRefreshes > 0 {
+		watched = display.NewWatchedDisplay(disp)
+		disp = watched
+	}
+
+	// Flush frames in the background so a slow bus does not hold up stats
+	// collection; recovery above still sees every flush error
+	if cfg.Display.DoubleBuffer {
+		disp = display.NewQueuedDisplay(disp, log)
 	}
 	defer func() {
 		log.Info("Closing display...")
@@ -102,57 +273,266 @@ func main() {
 		return
 	}
 
-	// Create stats collector
-	collector, err := stats.NewSystemCollector(cfg)
-	if err != nil {
-		log.FatalWithErr(err, "Failed to create stats collector")
+	// Set up context for graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create stats collector; the service reads each source in the
+	// background so rendering never waits on a slow one
+	var collector stats.Collector
+	var service *stats.Service // nil when replaying a recording
+	if *replayPath != "" {
+		replay, err := stats.LoadReplay(*replayPath)
+		if err != nil {
+			log.FatalWithErr(err, "Failed to load stats recording")
+		}
+		log.With().Str("path", *replayPath).Int("frames", replay.Frames()).Logger().Info("Replaying recorded stats")
+		collector = replay
+	} else {
+		systemCollector, err := stats.NewSystemCollector(cfg)
+		if err != nil {
+			log.FatalWithErr(err, "Failed to create stats collector")
+		}
+		service = stats.NewService(systemCollector)
+		if err := service.Start(ctx); err != nil {
+			log.FatalWithErr(err, "Failed to collect initial stats")
+		}
+		collector = service
 	}
+	if *recordPath != "" {
+		f, err := os.Create(*recordPath) // #nosec G304 -- path given on the command line
+		if err != nil {
+			log.FatalWithErr(err, "Failed to create stats recording")
+		}
+		defer f.Close()
+		collector = stats.NewRecorder(collector, f)
+		log.With().Str("path", *recordPath).Logger().Info("Recording stats")
+	}
+
+	// Contrast and gamma even out panel-to-panel differences; calibration
+	// changes them while the service runs
+	calibrated := display.NewCalibratedDisplay(disp, display.CalibrationFromConfig(&cfg.Display))
+	disp = calibrated
 
 	// Create renderer
-	rend := renderer.NewRenderer(disp, cfg)
+	// All rendering goes through the shift wrapper so the screensaver can
+	// move content around for burn-in protection
+	shifter := display.NewShiftDisplay(disp)
+	disp = shifter
+
+	// Render reports count the renderer's display traffic per frame
+	var frameCounter *display.CountingDisplay
+	rendDisp := disp
+	if cfg.Logging.RenderReports {
+		frameCounter = display.NewCountingDisplay(disp)
+		rendDisp = frameCounter
+	}
+	// Keep the last frames rendered for post-mortem dumps
+	var capture *display.CaptureDisplay
+	if cfg.Logging.CaptureFrames > 0 {
+		capture = display.NewCaptureDisplay(rendDisp, cfg.Logging.CaptureFrames)
+		rendDisp = capture
+	}
+	// Characters beyond ASCII are drawn with the configured fallback font
+	if err := renderer.LoadFont(cfg.Display.Font); err != nil {
+		log.With().Err(err).Str("font", cfg.Display.Font).Logger().Warn("Failed to load font, non-ASCII text will show as boxes")
+	}
+	// Labels and numbers in the configured language; validated at load time
+	locale, _ := i18n.Lookup(cfg.Pages.Locale)
+	renderer.SetLocale(locale)
+	if !locale.ASCII() && cfg.Display.Font == "" {
+		log.With().Str("locale", locale.Name()).Logger().Warn("Accented letters in page labels need display.font, they will show as boxes")
+	}
+	renderer.SetThresholds(cfg.Thresholds)
+	rend := renderer.NewRenderer(rendDisp, cfg)
+
+	// Page scripts are optional; a broken script is skipped, not fatal
+	scripts, err := plugin.LoadDir(cfg.Pages.PluginDir, log)
+	if err != nil {
+		log.With().Err(err).Logger().Warn("Failed to load page scripts")
+	}
+	rend.SetPlugins(scripts)
 
 	// Collect initial stats to build pages
 	initialStats, err := collector.Collect()
 	if err != nil {
 		log.FatalWithErr(err, "Failed to collect initial stats")
 	}
+	// Resume the graphs where the last run left them
+	historyFile := cfg.History.File
+	if historyFile != "" {
+		if err := rend.History().Load(historyFile); err != nil {
+			log.With().Err(err).Str("path", historyFile).Logger().Warn("Failed to restore history, starting afresh")
+		}
+		saveInterval, _ := cfg.History.GetSaveInterval() // validated at load time
+		go runHistorySaver(ctx, rend.History(), historyFile, saveInterval, log)
+	}
 	rend.BuildPages(initialStats)
 
 	log.With().Int("count", rend.PageCount()).Logger().Info("Pages built successfully")
 
 	// Create rotation manager
 	mgr := rotation.NewManager(cfg, collector, rend)
+	if frameCounter != nil {
+		mgr.SetRenderReports(frameCounter, cfg.Logging.RenderReportEvery)
+		if cfg.Logging.Level != "debug" {
+			log.Warn("logging.render_reports is enabled but reports are only logged at debug level")
+		}
+	}
 
 	// Create and attach metrics collector
 	metricsCollector := metrics.New(log)
+	metricsCollector.SetBuildInfo(buildVer, buildCommit)
 	mgr.SetMetrics(metricsCollector)
-
-	// Set up context for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	healthChecker := health.New()
+	mgr.SetHealthChecker(healthChecker)
+	if service != nil {
+		service.SetHealthChecker(healthChecker)
+	}
+	metricsCollector.RegisterHealthChecker(healthChecker)
+	if recovering != nil {
+		recovering.SetHealthChecker(healthChecker)
+	}
+	if busRetry != nil {
+		busRetry.SetHealthChecker(healthChecker)
+		busRetry.SetOnErrorFunc(metricsCollector.RecordI2CAttemptFailed)
+	}
+	if capture != nil {
+		healthChecker.OnUnhealthy(dumpFrames(capture, cfg.Logging.CrashDir, log))
+	}
+	if dedup != nil {
+		dedup.SetOnSkipFunc(metricsCollector.RecordFrameSkipped)
+	}
 
 	// Start metrics server if enabled
+	if cfg.Metrics.Enabled && !cfg.Metrics.Auth.IsSet() && cfg.Metrics.TLS.ClientCAFile == "" && !loopbackAddress(cfg.Metrics.Address) {
+		log.With().Str("address", cfg.Metrics.Address).Logger().Warn("Metrics server accepts control requests from the network without authentication; set metrics.auth or metrics.tls.client_ca_file")
+	}
 	metricsServer, err := metrics.StartMetricsServer(metrics.Config{
-		Enabled: cfg.Metrics.Enabled,
-		Address: cfg.Metrics.Address,
+		Enabled:      cfg.Metrics.Enabled,
+		Address:      cfg.Metrics.Address,
+		SocketGroup:  cfg.Metrics.SocketGroup,
+		CertFile:     cfg.Metrics.TLS.CertFile,
+		KeyFile:      cfg.Metrics.TLS.KeyFile,
+		ClientCAFile: cfg.Metrics.TLS.ClientCAFile,
+		Username:     cfg.Metrics.Auth.Username,
+		Password:     cfg.Metrics.Auth.Password,
+		Token:        cfg.Metrics.Auth.Token,
 	}, metricsCollector, log)
 	if err != nil {
 		log.ErrorWithErr(err, "Failed to start metrics server")
 	}
+	if cfg.Metrics.Push.Protocol != "" {
+		go metrics.NewPusher(metricsPushConfig(cfg), metricsCollector, log).Run(ctx)
+	}
+
+	// Track backlight on-time and enforce panel lifetime limits. The guard
+	// wraps the display so every brightness change passes through it.
+	if cfg.Backlight.Enabled {
+		guard := newBacklightGuard(cfg, disp, log)
+		guard.SetOnHoursFunc(metricsCollector.SetBacklightOnHours)
+		guard.Start(ctx)
+		defer guard.Stop()
+		disp = guard
+	}
 
 	// Create and start screensaver
 	ss, err := newScreenSaver(cfg, disp, log)
 	if err != nil {
 		log.FatalWithErr(err, "Invalid screensaver configuration")
 	}
+	ss.SetShifter(shifter)
 	if err := ss.Start(ctx); err != nil {
 		log.ErrorWithErr(err, "Failed to start screensaver")
 	}
 	defer ss.Stop()
 
-	// Register wake handler with metrics server so POST /wake reaches the screensaver
+	// Ambient light auto-brightness feeds the screensaver's normal level so
+	// dim/blank modes keep working on top of it
+	var autoBright *autobrightness.Controller
+	if cfg.AutoBright.Enabled {
+		autoBright, err = newAutoBrightness(cfg, ss, log)
+		if err != nil {
+			log.ErrorWithErr(err, "Failed to start auto-brightness")
+		} else {
+			autoBright.Start(ctx)
+			defer autoBright.Stop()
+			log.With().Str("sensor", cfg.AutoBright.Sensor).Logger().Info("Auto-brightness enabled")
+		}
+	}
+
+	// Night mode caps brightness through the screensaver on a daily schedule,
+	// independent of idle time and active hours
+	var night *nightmode.Controller
+	if cfg.NightMode.Enabled {
+		night = newNightMode(cfg, ss, log)
+		night.Start(ctx)
+		defer night.Stop()
+		log.With().Str("start", cfg.NightMode.Start).Str("end", cfg.NightMode.End).Logger().Info("Night mode enabled")
+	}
+
+	// The clock screensaver replaces rotation while idle, as does night mode
+	// with clock_only; checked on every refresh so a SIGHUP mode change takes
+	// effect without rewiring
+	mgr.SetClockFunc(func() bool {
+		return ss.ShowClock() || (night != nil && night.ShowClock())
+	})
+
+	// Fade transitions ramp brightness through the same path as the
+	// screensaver and return to whatever level it currently holds
+	rend.SetBrightnessControl(disp.SetBrightness, ss.Brightness)
+
+	// Calibration shows a test image in place of rotation while contrast
+	// and gamma are tuned over HTTP or with the buttons
+	calibrator := calibration.New(calibrated, mgr, cfg.Path(), log)
+	calibrator.SetActivityFunc(ss.ResetActivity)
+
+	// Register wake, health, rotation and calibration control handlers with the metrics server
 	if metricsServer != nil {
 		metricsServer.SetWakeHandler(ss.Wake)
+		metricsServer.SetHealthChecker(healthChecker)
+		metricsServer.SetRotationControl(mgr)
+		metricsServer.SetHistory(rend.History())
+		metricsServer.SetCalibrationControl(calibrator)
+		metricsServer.SetMessageHandler(func(text, size, colour string, d time.Duration) error {
+			return showMessage(mgr, text, size, colour, d)
+		})
+	}
+
+	// Attach alert engine so threshold rules interrupt rotation
+	if cfg.Alerts.Enabled {
+		mgr.SetAlertEngine(alerts.NewEngine(alerts.RulesFromConfig(cfg.Alerts.Rules, cfg.SystemInfo.TemperatureUnit)))
+		mgr.SetWakeFunc(ss.Wake)
+		if notifier := alerts.NewNotifier(cfg.Alerts.Notify, log); notifier != nil {
+			mgr.SetAlertNotifier(notifier)
+			defer notifier.Wait()
+		}
+		log.With().Int("rules", len(cfg.Alerts.Rules)).Logger().Info("Alert engine enabled")
+	}
+
+	// Attach thermal shutdown monitor so a critical temperature triggers the
+	// configured command after an on-screen countdown
+	if cfg.Thermal.Enabled {
+		mgr.SetThermalMonitor(thermal.NewMonitor(cfg.Thermal, log))
+		mgr.SetWakeFunc(ss.Wake)
+		log.With().Float64("threshold", cfg.Thermal.Threshold).Int("samples", cfg.Thermal.Samples).Logger().Warn("Thermal shutdown enabled")
+	}
+
+	// Drive the fan from the CPU temperature on every refresh
+	if cfg.Fan.Enabled {
+		out, err := fan.OpenGPIO(cfg.Fan.Pin)
+		if err != nil {
+			log.ErrorWithErr(err, "Failed to set up fan control")
+		} else {
+			fanCtl := fan.New(cfg.Fan, out, log)
+			mgr.SetFanController(fanCtl)
+			defer func() {
+				if err := fanCtl.Close(); err != nil {
+					log.ErrorWithErr(err, "Error leaving fan at full speed")
+				}
+			}()
+			log.With().Str("pin", cfg.Fan.Pin).Logger().Info("Fan control enabled")
+		}
 	}
 
 	// Start rotation manager
@@ -160,49 +540,165 @@ func main() {
 		log.FatalWithErr(err, "Failed to start rotation manager")
 	}
 
+	// Re-create the display when no frame gets through, as when a wedged
+	// bus blocks flushing and the screen would otherwise freeze silently
+	if watched != nil {
+		var reinit func()
+		if recovering != nil {
+			reinit = recovering.Reinit
+		}
+		go mgr.WatchStalls(ctx, cfg.Display.StallRefreshes, watched.LastFlush, reinit)
+	}
+
+	// Rebuild pages as soon as an interface or address changes
+	if err := mgr.WatchNetwork(ctx); err != nil {
+		log.With().Err(err).Logger().Info("Network change notifications unavailable, polling interfaces")
+	} else {
+		log.Debug("Watching for network changes")
+	}
+
+	// Power the panel down before the host suspends or powers off instead
+	// of leaving the last frame frozen on it
+	if !*useMock && !cfg.Display.IsPreview() {
+		if err := logind.Watch(ctx, powerHandler(mgr, disp, ss, log), log); err != nil {
+			log.With().Err(err).Logger().Info("Suspend and shutdown notifications unavailable, the display stays on")
+		} else {
+			log.Debug("Watching for suspend and shutdown")
+		}
+	}
+
+	// GPIO buttons pause rotation or hold a page
+	if cfg.Buttons.Enabled {
+		watcher, err := newButtonWatcher(cfg, mgr, calibrator, log)
+		if err != nil {
+			log.ErrorWithErr(err, "Failed to set up buttons")
+		} else {
+			watcher.Start(ctx)
+			defer watcher.Stop()
+			log.Info("Buttons enabled")
+		}
+	}
+
+	// Reloads requested over the control socket run on this goroutine,
+	// which owns the configuration
+	reloadReq := make(chan chan error)
+	var controlServer *control.Server
+	if cfg.Control.Enabled {
+		controlServer = newControlServer(cfg, controlDeps{
+			mgr:       mgr,
+			rend:      rend,
+			health:    healthChecker,
+			version:   buildVer,
+			startTime: startTime,
+			reloadReq: reloadReq,
+			ctx:       ctx,
+		}, log)
+		if err := controlServer.Start(); err != nil {
+			log.ErrorWithErr(err, "Failed to start control socket")
+			controlServer = nil
+		}
+	}
+
+	// Home Assistant drives messages, brightness and the page over MQTT
+	if cfg.MQTT.Enabled {
+		bridge := newMQTTBridge(cfg, mgr, rend, ss, buildVer, log)
+		bridge.Start(ctx)
+		defer bridge.Stop()
+		log.With().Str("broker", cfg.MQTT.Broker).Bool("discovery", cfg.MQTT.Discovery).Logger().Info("MQTT enabled")
+	}
+
+	// Under a Type=notify unit, tell systemd startup is complete and ping its
+	// watchdog while the render loop is healthy
+	if _, err := sdnotify.Notify(sdnotify.Ready); err != nil {
+		log.ErrorWithErr(err, "Failed to notify systemd of readiness")
+	}
+	if timeout := sdnotify.WatchdogTimeout(); timeout > 0 {
+		refreshInterval, _ := cfg.Pages.GetRefreshInterval() // validated at startup
+		refreshInterval = max(refreshInterval, rend.MinRefreshInterval())
+		go sdnotify.RunWatchdog(ctx, timeout, watchdogCheck(healthChecker, max(timeout, 3*refreshInterval)), log)
+		log.With().Dur("timeout", timeout).Logger().Info("Systemd watchdog enabled")
+	}
+
 	log.Info("Display service running. Press Ctrl+C to stop.")
 
-	// Wait for interrupt signal or SIGHUP for reload
+	// reload re-reads the configuration and applies what can change at runtime
+	reload := func() error {
+		_, _ = sdnotify.Notify(sdnotify.Reloading)
+		defer func() { _, _ = sdnotify.Notify(sdnotify.Ready) }()
+
+		newCfg, err := config.LoadWithPriority(*configPath)
+		if err != nil {
+			return fmt.Errorf("failed to reload configuration: %w", err)
+		}
+		if err := newCfg.Validate(); err != nil {
+			return fmt.Errorf("new configuration invalid: %w", err)
+		}
+		// Warn if display hardware config changed — requires a restart.
+		// Contrast and gamma apply live unless calibration is under way.
+		hardware := newCfg.Display
+		hardware.Contrast, hardware.Gamma = configuredDisplay.Contrast, configuredDisplay.Gamma
+		if !reflect.DeepEqual(hardware, configuredDisplay) {
+			log.Warn("Display configuration changed — restart required for changes to take effect")
+		}
+		configuredDisplay = newCfg.Display
+		if cal := display.CalibrationFromConfig(&newCfg.Display); cal != calibrated.Calibration() && !calibrator.Active() {
+			if err := calibrated.SetCalibration(cal); err != nil {
+				log.ErrorWithErr(err, "Failed to apply display calibration")
+			} else {
+				log.With().Int("contrast", int(cal.Contrast)).Float64("gamma", cal.Gamma).Logger().Info("Display calibration updated")
+			}
+		}
+		// Update logging if changed
+		if newCfg.Logging != cfg.Logging {
+			log = logger.New(logger.Config{
+				Level:  newCfg.Logging.Level,
+				Output: newCfg.Logging.Output,
+				JSON:   newCfg.Logging.JSON,
+			})
+			logger.SetGlobalLogger(log)
+			log.Info("Logging configuration updated")
+		}
+		// Update screensaver config
+		newSS, ssErr := newScreenSaver(newCfg, disp, log)
+		if ssErr != nil {
+			log.ErrorWithErr(ssErr, "Invalid screensaver configuration, keeping current")
+		} else {
+			ssCfg := newSS.Config()
+			if autoBright != nil {
+				// Keep the sensor-driven level rather than the static config value
+				ssCfg.NormalBrightness = ss.Config().NormalBrightness
+			}
+			ss.UpdateConfig(ssCfg)
+		}
+		cfg = newCfg
+		log.Info("Configuration reloaded successfully")
+		return nil
+	}
+
+	// Wait for interrupt signal, SIGHUP or a control socket reload
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1)
 
 	for {
-		sig := <-sigChan
+		var sig os.Signal
+		select {
+		case reply := <-reloadReq:
+			log.Info("Reload requested over the control socket, reloading configuration...")
+			err := reload()
+			if err != nil {
+				log.ErrorWithErr(err, "Keeping current configuration")
+			}
+			reply <- err
+			continue
+		case sig = <-sigChan:
+		}
+
 		switch sig {
 		case syscall.SIGHUP:
 			log.Info("Received SIGHUP, reloading configuration...")
-			newCfg, err := config.LoadWithPriority(*configPath)
-			if err != nil {
-				log.ErrorWithErr(err, "Failed to reload configuration, keeping current config")
-				continue
-			}
-			if err := newCfg.Validate(); err != nil {
-				log.ErrorWithErr(err, "New configuration invalid, keeping current config")
-				continue
-			}
-			// Warn if display hardware config changed — requires a restart
-			if newCfg.Display != cfg.Display {
-				log.Warn("Display configuration changed — restart required for changes to take effect")
+			if err := reload(); err != nil {
+				log.ErrorWithErr(err, "Keeping current configuration")
 			}
-			// Update logging if changed
-			if newCfg.Logging != cfg.Logging {
-				log = logger.New(logger.Config{
-					Level:  newCfg.Logging.Level,
-					Output: newCfg.Logging.Output,
-					JSON:   newCfg.Logging.JSON,
-				})
-				logger.SetGlobalLogger(log)
-				log.Info("Logging configuration updated")
-			}
-			// Update screensaver config
-			newSS, ssErr := newScreenSaver(newCfg, disp, log)
-			if ssErr != nil {
-				log.ErrorWithErr(ssErr, "Invalid screensaver configuration, keeping current")
-			} else {
-				ss.UpdateConfig(newSS.Config())
-			}
-			cfg = newCfg
-			log.Info("Configuration reloaded successfully")
 			continue
 
 		case syscall.SIGUSR1:
@@ -217,12 +713,28 @@ func main() {
 	}
 
 shutdown:
+	_, _ = sdnotify.Notify(sdnotify.Stopping)
 
 	// Cancel context to stop rotation manager and screensaver
 	cancel()
 
+	// Stop accepting control commands before the manager goes away
+	if controlServer != nil {
+		if err := controlServer.Stop(); err != nil {
+			log.ErrorWithErr(err, "Error stopping control socket")
+		}
+	}
+
 	// Stop manager gracefully
 	mgr.Stop()
+	if historyFile != "" {
+		if err := rend.History().Save(historyFile); err != nil {
+			log.With().Err(err).Str("path", historyFile).Logger().Warn("Failed to save history")
+		}
+	}
+	if service != nil {
+		service.Stop()
+	}
 
 	// Stop metrics server if running
 	if metricsServer != nil {
@@ -243,14 +755,15 @@ shutdown:
 //
 //nolint:gocyclo // test sequence naturally has many steps
 func runDisplayTest(disp display.Display, log *logger.Logger) error {
+	if caps := display.AsColorDisplay(disp).Capabilities(); caps.Text() {
+		return runTextDisplayTest(disp, caps.TextColumns, caps.TextRows, log)
+	}
+
 	bounds := disp.GetBounds()
 	w := bounds.Dx()
 	h := bounds.Dy()
 
-	steps := []struct {
-		name string
-		fn   func() error
-	}{
+	steps := []displayTestStep{
 		{
 			// Step 1 — solid white: verifies the full display area is addressed.
 			// If only part of the screen lights up the window/offset is wrong.
@@ -318,6 +831,14 @@ func runDisplayTest(disp display.Display, log *logger.Logger) error {
 				return disp.Show()
 			},
 		},
+	}
+
+	// Colour panels also check the colour order and gamma
+	if cd := display.AsColorDisplay(disp); cd.Capabilities().Color() {
+		steps = append(steps, colourTestSteps(cd, w, h)...)
+	}
+
+	steps = append(steps, []displayTestStep{
 		{
 			// Step 5 — clear: leave the display blank.
 			name: "clear",
@@ -328,8 +849,94 @@ func runDisplayTest(disp display.Display, log *logger.Logger) error {
 				return disp.Show()
 			},
 		},
+	}...)
+
+	return runDisplayTestSteps(steps, log)
+}
+
+// testBarColours are the colour bars of the display test, left to right
+var testBarColours = []color.NRGBA{
+	{R: 255, G: 255, B: 255, A: 255}, // white
+	{R: 255, G: 255, A: 255},         // yellow
+	{G: 255, B: 255, A: 255},         // cyan
+	{G: 255, A: 255},                 // green
+	{R: 255, B: 255, A: 255},         // magenta
+	{R: 255, A: 255},                 // red
+	{B: 255, A: 255},                 // blue
+	{A: 255},                         // black
+}
+
+// colourTestSteps are the display test steps for colour panels: colour
+// bars, a pixel order check and a grey gradient
+func colourTestSteps(cd display.ColorDisplay, w, h int) []displayTestStep {
+	return []displayTestStep{
+		{
+			// Colour bars: each primary and mix in order. Swapped red and
+			// blue bars need display.bgr_order, a negative picture
+			// display.invert_colors.
+			name: "colour bars: white, yellow, cyan, green, magenta, red, blue, black",
+			fn: func() error {
+				for i, c := range testBarColours {
+					x0, x1 := i*w/len(testBarColours), (i+1)*w/len(testBarColours)
+					if err := cd.FillRectColor(x0, 0, x1-x0, h, c); err != nil {
+						return err
+					}
+				}
+				return cd.Show()
+			},
+		},
+		{
+			// Pixel order: each block carries the letter of its colour, so
+			// a block labelled R that shows blue means BGR order
+			name: "pixel order: R, G and B blocks labelled with their colour",
+			fn: func() error {
+				labels := []string{"R", "G", "B"}
+				blocks := []color.NRGBA{renderer.ColorRed, renderer.ColorGreen, {B: 255, A: 255}}
+				for i, c := range blocks {
+					x0, x1 := i*w/3, (i+1)*w/3
+					if err := cd.FillRectColor(x0, 0, x1-x0, h, c); err != nil {
+						return err
+					}
+					if err := renderer.DrawTextColor(cd, x0+2, 2, labels[i], color.White); err != nil {
+						return err
+					}
+				}
+				return cd.Show()
+			},
+		},
+		{
+			// Grey gradient: a smooth ramp above 16 steps. Each step should
+			// differ from its neighbours; merged dark or light steps call
+			// for display.gamma_positive and gamma_negative.
+			name: "grey gradient: smooth ramp above 16 steps",
+			fn: func() error {
+				for x := range w {
+					v := uint8(x * 255 / max(w-1, 1)) // #nosec G115 -- 0-255
+					if err := cd.FillRectColor(x, 0, 1, h/2, color.NRGBA{R: v, G: v, B: v, A: 255}); err != nil {
+						return err
+					}
+				}
+				for i := range 16 {
+					v := uint8(i * 17) // #nosec G115 -- 0-255
+					x0, x1 := i*w/16, (i+1)*w/16
+					if err := cd.FillRectColor(x0, h/2, x1-x0, h-h/2, color.NRGBA{R: v, G: v, B: v, A: 255}); err != nil {
+						return err
+					}
+				}
+				return cd.Show()
+			},
+		},
 	}
+}
 
+// displayTestStep is one pattern of the display test
+type displayTestStep struct {
+	name string
+	fn   func() error
+}
+
+// runDisplayTestSteps runs the steps in order, pausing after each
+func runDisplayTestSteps(steps []displayTestStep, log *logger.Logger) error {
 	for i, step := range steps {
 		log.With().Int("step", i+1).Str("name", step.name).Logger().Info("Test step")
 		if err := step.fn(); err != nil {
@@ -342,6 +949,30 @@ func runDisplayTest(disp display.Display, log *logger.Logger) error {
 	return nil
 }
 
+// runTextDisplayTest is the display test for character displays, which
+// cannot show pixel patterns.
+//
+// Pass: every cell filled → text → clear.
+func runTextDisplayTest(disp display.Display, cols, rows int, log *logger.Logger) error {
+	show := func(lines ...string) error {
+		if err := display.WriteLines(disp, lines); err != nil {
+			return err
+		}
+		return disp.Show()
+	}
+	full := make([]string, rows)
+	for i := range full {
+		full[i] = strings.Repeat("#", cols)
+	}
+
+	return runDisplayTestSteps([]displayTestStep{
+		// Every cell lit: verifies all rows and columns are addressed
+		{name: "fill all cells", fn: func() error { return show(full...) }},
+		{name: "text rendering", fn: func() error { return show("DISPLAY OK", fmt.Sprintf("%dx%d", cols, rows)) }},
+		{name: "clear", fn: func() error { return show() }},
+	}, log)
+}
+
 // newScreenSaver constructs a screensaver from application config.
 func newScreenSaver(cfg *config.Config, disp display.Display, log *logger.Logger) (*screensaver.ScreenSaver, error) {
 	idleTimeout, err := time.ParseDuration(cfg.ScreenSaver.IdleTimeout)
@@ -352,6 +983,10 @@ func newScreenSaver(cfg *config.Config, disp display.Display, log *logger.Logger
 	if err != nil || wakeDuration <= 0 {
 		wakeDuration = 30 * time.Second
 	}
+	shiftInterval, err := time.ParseDuration(cfg.ScreenSaver.ShiftInterval)
+	if err != nil || shiftInterval <= 0 {
+		shiftInterval = time.Minute
+	}
 	ssCfg := screensaver.Config{
 		Enabled:          cfg.ScreenSaver.Enabled,
 		Mode:             screensaver.Mode(cfg.ScreenSaver.Mode),
@@ -359,6 +994,8 @@ func newScreenSaver(cfg *config.Config, disp display.Display, log *logger.Logger
 		DimBrightness:    cfg.ScreenSaver.DimBrightness,
 		NormalBrightness: cfg.ScreenSaver.NormalBrightness,
 		WakeDuration:     wakeDuration,
+		ShiftPixels:      cfg.ScreenSaver.ShiftPixels,
+		ShiftInterval:    shiftInterval,
 		ActiveHours: screensaver.ActiveHours{
 			Enabled: cfg.ScreenSaver.ActiveHours.Enabled,
 			Start:   cfg.ScreenSaver.ActiveHours.Start,
@@ -367,3 +1004,477 @@ func newScreenSaver(cfg *config.Config, disp display.Display, log *logger.Logger
 	}
 	return screensaver.New(ssCfg, disp, log), nil
 }
+
+// newAutoBrightness opens the configured light sensor and returns a
+// controller that routes brightness changes through the screensaver.
+func newAutoBrightness(cfg *config.Config, ss *screensaver.ScreenSaver, log *logger.Logger) (*autobrightness.Controller, error) {
+	ab := cfg.AutoBright
+	bus := ab.I2CBus
+	if bus == "" {
+		bus = cfg.Display.I2CBus
+	}
+	var addr uint64
+	if ab.I2CAddress != "" {
+		var err error
+		addr, err = strconv.ParseUint(strings.TrimPrefix(strings.ToLower(ab.I2CAddress), "0x"), 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auto_brightness.i2c_address: %w", err)
+		}
+	}
+	interval, _ := time.ParseDuration(ab.Interval)
+	return autobrightness.Open(ab.Sensor, bus, uint16(addr), autobrightness.Config{
+		Interval:      interval,
+		MinBrightness: ab.MinBrightness,
+		MaxBrightness: ab.MaxBrightness,
+		MaxLux:        ab.MaxLux,
+	}, ss.SetNormalBrightness, log)
+}
+
+// showMessage builds a message page and shows it for d. An empty colour
+// means white.
+func showMessage(mgr *rotation.Manager, text, size, colour string, d time.Duration) error {
+	if colour == "" {
+		colour = "white"
+	}
+	c, err := renderer.ParseColor(colour)
+	if err != nil {
+		return err
+	}
+	page, err := renderer.NewMessagePage(text, size, c)
+	if err != nil {
+		return err
+	}
+	return mgr.ShowMessage(page, d)
+}
+
+// lockDisplay takes the panel lock for d. With takeover set, an instance
+// already holding it is sent SIGTERM and given takeoverTimeout to exit.
+func lockDisplay(d *config.DisplayConfig, takeover bool, log *logger.Logger) (*panellock.Lock, error) {
+	path := panellock.Path(panellock.DefaultDir, d)
+	if !takeover {
+		lock, err := panellock.Acquire(path)
+		var held *panellock.HeldError
+		if errors.As(err, &held) {
+			return nil, fmt.Errorf("%w; stop the other instance or start with -takeover", err)
+		}
+		return lock, err
+	}
+
+	log.With().Str("lock", path).Logger().Info("Taking over display from any running instance")
+	return panellock.Takeover(path, takeoverTimeout)
+}
+
+// watchdogCheck reports whether systemd's watchdog should be pinged: the
+// service must not be unhealthy and the render loop, which collects stats on
+// every refresh, must have run within maxAge
+func watchdogCheck(h *health.Checker, maxAge time.Duration) func() error {
+	return func() error {
+		if status := h.GetOverallStatus(); status == health.StatusUnhealthy {
+			return fmt.Errorf("service is %s", status)
+		}
+		if comp := h.GetComponentStatus(health.ComponentCollector); comp != nil {
+			if since := time.Since(comp.LastCheck); since > maxAge {
+				return fmt.Errorf("render loop has not run for %s", since.Round(time.Second))
+			}
+		}
+		return nil
+	}
+}
+
+// powerHandler puts the display to sleep before the host suspends or shuts
+// down, and wakes it with the screensaver's brightness on resume
+func powerHandler(mgr *rotation.Manager, disp display.Display, ss *screensaver.ScreenSaver, log *logger.Logger) func(logind.Event) {
+	return func(e logind.Event) {
+		log.With().Str("event", e.String()).Logger().Info("Host power state changing")
+		if e != logind.EventResume {
+			if err := mgr.Sleep(); err != nil {
+				log.ErrorWithErr(err, "Failed to put the display to sleep")
+			}
+			return
+		}
+		if err := mgr.Wake(); err != nil {
+			log.ErrorWithErr(err, "Failed to wake the display")
+			return
+		}
+		if err := disp.SetBrightness(ss.Brightness()); err != nil {
+			log.ErrorWithErr(err, "Failed to restore brightness")
+		}
+	}
+}
+
+// runSoak runs the soak test on disp until d passes or the process is
+// interrupted, logging progress every minute. Any failed draw or flush
+// fails the test.
+func runSoak(disp display.Display, d time.Duration, log *logger.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.With().Dur("duration", d).Logger().Info("Starting soak test")
+	rep, err := soak.Run(ctx, disp, soak.Options{
+		Duration:       d,
+		ReportInterval: time.Minute,
+		OnReport: func(r soak.Report) {
+			l := log.With().
+				Dur("elapsed", r.Elapsed.Round(time.Second)).
+				Int("flushes", r.Flushes).
+				Int("errors", r.Errors).
+				Float64("error_rate", r.ErrorRate()).
+				Dur("latency_min", r.Min).
+				Dur("latency_mean", r.Mean).
+				Dur("latency_p50", r.P50).
+				Dur("latency_p95", r.P95).
+				Dur("latency_p99", r.P99).
+				Dur("latency_max", r.Max)
+			for frame, n := range r.ByFrame {
+				l = l.Int("errors_"+strings.ReplaceAll(frame, " ", "_"), n)
+			}
+			if r.LastErr != nil {
+				l = l.Err(r.LastErr)
+			}
+			l.Logger().Info("Soak test progress")
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if rep.Errors > 0 {
+		return fmt.Errorf("%d of %d flushes failed", rep.Errors, rep.Flushes)
+	}
+	log.With().Int("flushes", rep.Flushes).Logger().Info("Soak test passed")
+	return nil
+}
+
+// runBench benchmarks disp for d, or until the process is interrupted, and
+// logs the result
+func runBench(disp display.Display, driver string, d time.Duration, log *logger.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.With().Str("driver", driver).Dur("duration", d).Logger().Info("Starting benchmark")
+	res, err := bench.Run(ctx, disp, d)
+	if err != nil {
+		return err
+	}
+	log.With().
+		Str("driver", driver).
+		Int("frames", res.Frames).
+		Float64("fps", res.FPS()).
+		Float64("bytes_per_sec", res.BytesPerSecond()).
+		Dur("render", res.Render).
+		Dur("flush", res.Flush).
+		Float64("allocs_per_frame", res.AllocsPerFrame).
+		Float64("alloc_bytes_per_frame", res.AllocBytes).
+		Logger().Info("Benchmark complete")
+	log.With().Dur("frame_time", res.FrameTime()).Logger().
+		Info("Refresh intervals and animations faster than the frame time leave the bus no idle time")
+	return nil
+}
+
+// runSetup runs the interactive setup wizard, writing the config file given
+// with -config
+func runSetup(args []string) {
+	fs := flag.NewFlagSet("setup", flag.ExitOnError)
+	path := fs.String("config", "/etc/i2c-display/config.json", "Path of the configuration file to write")
+	_ = fs.Parse(args) // #nosec G104 -- ExitOnError
+	w := &setup.Wizard{In: os.Stdin, Out: os.Stdout}
+	if err := w.Run(*path); err != nil {
+		logger.NewDefault().FatalWithErr(err, "Setup failed")
+	}
+}
+
+// printSettings writes every setting of cfg as a line of its path, source
+// and JSON value. Values come last as lists can be long.
+func printSettings(w io.Writer, cfg *config.Config) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, s := range cfg.Settings() {
+		value, err := json.Marshal(s.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.Path, err)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Path, s.Source, value)
+	}
+	return tw.Flush()
+}
+
+// dumpFrames returns a health hook writing the captured frames to dir when
+// the display or the rotation loop, which dies on a panic, becomes unhealthy
+func dumpFrames(capture *display.CaptureDisplay, dir string, log *logger.Logger) func(name string, err error) {
+	return func(name string, err error) {
+		if name != health.ComponentDisplay && name != health.ComponentRotation {
+			return
+		}
+		out, dumpErr := capture.Dump(dir, name)
+		if dumpErr != nil {
+			log.With().Err(dumpErr).Str("component", name).Logger().Warn("Failed to write captured frames")
+			return
+		}
+		log.With().Str("component", name).Str("reason", err.Error()).Str("path", out).Logger().Warn("Wrote captured frames")
+	}
+}
+
+// errShuttingDown answers a reload requested once the daemon is stopping
+var errShuttingDown = errors.New("shutting down")
+
+// controlDeps are the daemon parts the control socket commands use
+type controlDeps struct {
+	mgr       *rotation.Manager
+	rend      *renderer.Renderer
+	health    *health.Checker
+	version   string
+	startTime time.Time
+	reloadReq chan<- chan error // served by the main loop until ctx is done
+	ctx       context.Context   // the daemon's, done once it is shutting down
+}
+
+// newControlServer creates the control socket serving status, next,
+// show-message and reload
+func newControlServer(cfg *config.Config, d controlDeps, log *logger.Logger) *control.Server {
+	srv := control.NewServer(cfg.Control.Socket, cfg.Control.Group, log)
+	srv.Handle(control.CommandStatus, func(json.RawMessage) (any, error) {
+		page := d.mgr.CurrentPage()
+		return control.Status{
+			Version:   d.version,
+			Uptime:    time.Since(d.startTime).Round(time.Second).String(),
+			Page:      page,
+			PageCount: d.rend.PageCount(),
+			PageTitle: d.rend.PageTitle(page),
+			PageType:  d.rend.PageType(page),
+			Paused:    d.mgr.Paused(),
+			Health:    string(d.health.GetOverallStatus()),
+		}, nil
+	})
+	srv.Handle(control.CommandNext, func(json.RawMessage) (any, error) {
+		d.mgr.Next()
+		return nil, nil
+	})
+	srv.Handle(control.CommandShowMessage, func(raw json.RawMessage) (any, error) {
+		var args control.MessageArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+		dur, err := args.Validate()
+		if err != nil {
+			return nil, err
+		}
+		return nil, showMessage(d.mgr, args.Text, args.FontSize, args.Color, dur)
+	})
+	srv.Handle(control.CommandReload, func(json.RawMessage) (any, error) {
+		// The main loop stops serving reloads once shutdown begins
+		reply := make(chan error, 1)
+		select {
+		case d.reloadReq <- reply:
+		case <-d.ctx.Done():
+			return nil, errShuttingDown
+		}
+		select {
+		case err := <-reply:
+			return nil, err
+		case <-d.ctx.Done():
+			return nil, errShuttingDown
+		}
+	})
+	return srv
+}
+
+// newMQTTBridge creates the Home Assistant bridge. Selecting a page shows it
+// for one rotation interval, after which rotation carries on from there.
+func newMQTTBridge(cfg *config.Config, mgr *rotation.Manager, rend *renderer.Renderer, ss *screensaver.ScreenSaver, version string, log *logger.Logger) *mqtt.Bridge {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "i2c-display"
+	}
+	rotationInterval, _ := cfg.Pages.GetRotationInterval() // validated at startup
+	return mqtt.NewBridge(cfg.MQTT, mqtt.Device{
+		Hostname: hostname,
+		Model:    cfg.Display.Type,
+		Version:  version,
+	}, mqtt.Controls{
+		ShowMessage: func(text, size, colour string, d time.Duration) error {
+			return showMessage(mgr, text, size, colour, d)
+		},
+		SetBrightness: ss.SetNormalBrightness,
+		Brightness:    func() uint8 { return ss.Config().NormalBrightness },
+		PageTitles:    rend.PageTitles,
+		CurrentPage:   mgr.CurrentPage,
+		ShowPage: func(idx int) error {
+			return mgr.HoldPage(idx, rotationInterval)
+		},
+	}, log)
+}
+
+// newNightMode returns a night schedule that applies its brightness cap
+// through the screensaver
+func newNightMode(cfg *config.Config, ss *screensaver.ScreenSaver, log *logger.Logger) *nightmode.Controller {
+	nm := cfg.NightMode
+	return nightmode.New(nightmode.Config{
+		Start:     nm.Start,
+		End:       nm.End,
+		ClockOnly: nm.ClockOnly,
+	}, func(on bool) {
+		ss.SetNight(on, nm.Brightness)
+	}, log)
+}
+
+// newButtonWatcher opens the configured button pins. The pause button toggles
+// rotation; the hold button shows the configured page and holds it, and
+// releases any pause or hold when pressed again. While calibrating, the
+// pause button steps the selected setting and the hold button selects the
+// next, saving after the last.
+func newButtonWatcher(cfg *config.Config, mgr *rotation.Manager, cal *calibration.Calibrator, log *logger.Logger) (*buttons.Watcher, error) {
+	bc := cfg.Buttons
+	var btns []buttons.Button
+	if bc.PausePin != "" {
+		pin, err := buttons.OpenPin(bc.PausePin)
+		if err != nil {
+			return nil, err
+		}
+		btns = append(btns, buttons.Button{Name: "pause", Pin: pin, OnPress: func() {
+			if cal.Active() {
+				if err := cal.Step(); err != nil {
+					log.ErrorWithErr(err, "Failed to adjust calibration")
+				}
+				return
+			}
+			if mgr.Paused() {
+				mgr.Resume()
+			} else {
+				mgr.Pause()
+			}
+		}})
+	}
+	if bc.HoldPin != "" {
+		pin, err := buttons.OpenPin(bc.HoldPin)
+		if err != nil {
+			return nil, err
+		}
+		// Validated at config load time; empty holds until pressed again
+		holdFor, _ := time.ParseDuration(bc.HoldDuration)
+		btns = append(btns, buttons.Button{Name: "hold", Pin: pin, OnPress: func() {
+			if cal.Active() {
+				if err := cal.Next(); err != nil {
+					log.ErrorWithErr(err, "Failed to save calibration")
+				}
+				return
+			}
+			if mgr.Paused() {
+				mgr.Resume()
+				return
+			}
+			idx := mgr.PageIndex(bc.HoldPage)
+			if idx < 0 {
+				log.With().Str("page", bc.HoldPage).Logger().Warn("Hold button pressed but page is not in rotation")
+				return
+			}
+			if err := mgr.HoldPage(idx, holdFor); err != nil {
+				log.ErrorWithErr(err, "Failed to hold page")
+			}
+		}})
+	}
+	debounce, _ := time.ParseDuration(bc.Debounce)
+	return buttons.New(debounce, log, btns...), nil
+}
+
+// newBacklightGuard constructs a backlight guard from application config.
+// Durations are validated at config load time; empty values disable the limit.
+func newBacklightGuard(cfg *config.Config, disp display.Display, log *logger.Logger) *backlight.Guard {
+	maxDaily, _ := time.ParseDuration(cfg.Backlight.MaxDailyOn)
+	autoDim, _ := time.ParseDuration(cfg.Backlight.AutoDimAfter)
+	return backlight.NewGuard(disp, backlight.Config{
+		StatePath:         cfg.Backlight.StatePath,
+		MaxDailyOn:        maxDaily,
+		AutoDimAfter:      autoDim,
+		AutoDimBrightness: cfg.Backlight.AutoDimBrightness,
+	}, log)
+}
+
+// loopbackAddress reports whether a listen address only accepts local
+// connections, as Unix sockets do. An empty host, as in ":9090", listens on
+// every interface.
+func loopbackAddress(addr string) bool {
+	if strings.HasPrefix(addr, metrics.UnixPrefix) {
+		return true
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// metricsPushConfig converts the metrics.push settings, labelling the
+// metrics with the host name as instance unless labels set one
+func metricsPushConfig(cfg *config.Config) metrics.PushConfig {
+	interval, _ := cfg.Metrics.Push.GetInterval() // validated at load time
+	labels := maps.Clone(cfg.Metrics.Push.Labels)
+	if labels == nil {
+		labels = make(map[string]string, 1)
+	}
+	if _, ok := labels["instance"]; !ok {
+		if hostname, err := os.Hostname(); err == nil {
+			labels["instance"] = hostname
+		}
+	}
+	return metrics.PushConfig{
+		Protocol: cfg.Metrics.Push.Protocol,
+		URL:      cfg.Metrics.Push.URL,
+		Address:  cfg.Metrics.Push.Address,
+		Interval: interval,
+		Headers:  cfg.Metrics.Push.Headers,
+		Labels:   labels,
+	}
+}
+
+// runHistorySaver saves the metric history every interval until ctx is
+// done, so little is lost if the process is killed rather than stopped
+func runHistorySaver(ctx context.Context, history *timeseries.Store, path string, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := history.Save(path); err != nil {
+				log.With().Err(err).Str("path", path).Logger().Warn("Failed to save history")
+			}
+		}
+	}
+}
+
+// resolveDisplayType replaces display type "auto" with a concrete type by
+// probing the I2C bus. If nothing is found it falls back to an SSD1306 at the
+// configured address, which in turn falls back to the mock display if the
+// hardware cannot be opened.
+func resolveDisplayType(dc *config.DisplayConfig, useMock bool, log *logger.Logger) {
+	if useMock {
+		dc.Type = "ssd1306"
+	} else if det, err := display.Detect(dc.I2CBus); err != nil {
+		log.With().Str("bus", dc.I2CBus).Str("address", dc.I2CAddress).Err(err).Logger().
+			Warn("Display auto-detection failed, falling back to ssd1306 at configured address")
+		dc.Type = "ssd1306"
+	} else {
+		log.With().Str("type", det.Type).Str("address", det.Address).Logger().Info("Display auto-detected")
+		dc.Type = det.Type
+		dc.I2CAddress = det.Address
+	}
+	dc.ApplyDisplayDefaults()
+}
+
+// resolveI2CAddress picks the first of the configured I2C addresses that a
+// device answers at. If none answers the first is kept, so the driver
+// reports the failure as it would for a single address.
+func resolveI2CAddress(dc *config.DisplayConfig, log *logger.Logger) {
+	addrs := strings.Join(dc.I2CAddresses, ",")
+	addr, err := display.ProbeI2CAddress(dc.I2CBus, dc.I2CAddresses)
+	if err != nil {
+		log.With().Str("bus", dc.I2CBus).Str("addresses", addrs).Err(err).Logger().
+			Warn("No device answered at the configured I2C addresses, using the first")
+		return
+	}
+	log.With().Str("address", addr).Str("addresses", addrs).Logger().Info("Display found at I2C address")
+	dc.I2CAddress = addr
+}
@@ -0,0 +1,33 @@
+package main
+
+import "runtime/debug"
+
+// Set at build time with -ldflags "-X main.version=... -X main.commit=..."
+var (
+	version = ""
+	commit  = ""
+)
+
+// buildVersion returns the version and commit of the running binary. Values
+// not set at link time fall back to the module and VCS information embedded
+// by the Go toolchain.
+func buildVersion() (string, string) {
+	v, c := version, commit
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v == "" {
+			v = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			if c == "" && setting.Key == "vcs.revision" {
+				c = setting.Value
+			}
+		}
+	}
+	if v == "" {
+		v = "unknown"
+	}
+	if c == "" {
+		c = "unknown"
+	}
+	return v, c
+}
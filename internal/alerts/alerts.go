@@ -0,0 +1,155 @@
+package alerts
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// Metric names that rules can be evaluated against
+const (
+	MetricDisk    = "disk"     // disk usage percent
+	MetricMemory  = "memory"   // memory usage percent
+	MetricCPUTemp = "cpu_temp" // CPU temperature in the configured unit; thresholds are configured in Celsius
+	MetricLoad    = "load"     // 1-minute load average
+)
+
+// Rule describes a threshold condition that raises an alert
+type Rule struct {
+	Name      string
+	Metric    string
+	Operator  string // ">", ">=", "<", "<="
+	Threshold float64
+	Wake      bool // wake the screensaver when the rule fires
+}
+
+// Alert is an active (firing) rule together with the value that triggered it
+type Alert struct {
+	Rule  Rule
+	Value float64
+	Since time.Time
+}
+
+// Message returns a short human-readable description suitable for the display
+func (a Alert) Message() string {
+	return fmt.Sprintf("%s: %.1f", a.Rule.Name, a.Value)
+}
+
+// Engine evaluates rules against collected stats and tracks which are firing
+type Engine struct {
+	mu     sync.Mutex
+	rules  []Rule
+	active map[int]*Alert // keyed by rule index to preserve config order
+}
+
+// NewEngine creates an alert engine for the given rules
+func NewEngine(rules []Rule) *Engine {
+	return &Engine{
+		rules:  rules,
+		active: make(map[int]*Alert),
+	}
+}
+
+// RulesFromConfig converts configured alert rules to engine rules.
+// cpu_temp thresholds are configured in Celsius and converted to unit,
+// system_info.temperature_unit, which temperatures are collected in.
+func RulesFromConfig(cfg []config.AlertRuleConfig, unit string) []Rule {
+	rules := make([]Rule, 0, len(cfg))
+	for _, r := range cfg {
+		op := r.Operator
+		if op == "" {
+			op = ">"
+		}
+		name := r.Name
+		if name == "" {
+			name = r.Metric
+		}
+		threshold := r.Threshold
+		if r.Metric == MetricCPUTemp {
+			threshold = stats.FromCelsius(threshold, unit)
+		}
+		rules = append(rules, Rule{
+			Name:      name,
+			Metric:    r.Metric,
+			Operator:  op,
+			Threshold: threshold,
+			Wake:      r.Wake,
+		})
+	}
+	return rules
+}
+
+// Evaluate checks all rules against s and returns the alerts that started
+// firing and the alerts that cleared since the previous evaluation.
+func (e *Engine) Evaluate(s *stats.SystemStats) (fired, cleared []Alert) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	for i, rule := range e.rules {
+		value, ok := metricValue(rule.Metric, s)
+		triggered := ok && compare(value, rule.Operator, rule.Threshold)
+
+		existing, wasActive := e.active[i]
+		switch {
+		case triggered && !wasActive:
+			a := &Alert{Rule: rule, Value: value, Since: now}
+			e.active[i] = a
+			fired = append(fired, *a)
+		case triggered && wasActive:
+			existing.Value = value
+		case !triggered && wasActive:
+			existing.Value = value
+			cleared = append(cleared, *existing)
+			delete(e.active, i)
+		}
+	}
+	return fired, cleared
+}
+
+// Active returns the currently firing alerts in rule order
+func (e *Engine) Active() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := make([]Alert, 0, len(e.active))
+	for i := range e.rules {
+		if a, ok := e.active[i]; ok {
+			result = append(result, *a)
+		}
+	}
+	return result
+}
+
+// metricValue extracts the named metric from stats. ok is false when the
+// metric is unknown or unavailable (e.g. no temperature sensor).
+func metricValue(metric string, s *stats.SystemStats) (value float64, ok bool) {
+	switch metric {
+	case MetricDisk:
+		return s.DiskPercent(), s.DiskTotal > 0
+	case MetricMemory:
+		return s.MemoryPercent(), s.MemoryTotal > 0
+	case MetricCPUTemp:
+		return s.CPUTemp, s.CPUTemp > 0
+	case MetricLoad:
+		return s.LoadAvg1, true
+	default:
+		return 0, false
+	}
+}
+
+func compare(value float64, op string, threshold float64) bool {
+	switch op {
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	default:
+		return value > threshold
+	}
+}
@@ -0,0 +1,126 @@
+package alerts
+
+import (
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestRulesFromConfig(t *testing.T) {
+	rules := RulesFromConfig([]config.AlertRuleConfig{
+		{Metric: "disk", Threshold: 90},
+		{Name: "Hot", Metric: "cpu_temp", Operator: ">=", Threshold: 80, Wake: true},
+	}, config.UnitCelsius)
+
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+	if rules[0].Name != "disk" {
+		t.Errorf("expected name to default to metric, got %q", rules[0].Name)
+	}
+	if rules[0].Operator != ">" {
+		t.Errorf("expected operator to default to '>', got %q", rules[0].Operator)
+	}
+	if rules[1].Name != "Hot" || !rules[1].Wake {
+		t.Errorf("unexpected rule %+v", rules[1])
+	}
+}
+
+func TestRulesFromConfigTemperatureUnit(t *testing.T) {
+	// cpu_temp thresholds are in Celsius whatever unit temperatures are collected in
+	rules := RulesFromConfig([]config.AlertRuleConfig{
+		{Metric: "cpu_temp", Threshold: 80},
+		{Metric: "disk", Threshold: 80},
+	}, config.UnitKelvin)
+	if rules[0].Threshold != 353.15 {
+		t.Errorf("expected 80C as 353.15K, got %g", rules[0].Threshold)
+	}
+	if rules[1].Threshold != 80 {
+		t.Errorf("expected the disk threshold unchanged, got %g", rules[1].Threshold)
+	}
+}
+
+func TestEngineTransitions(t *testing.T) {
+	e := NewEngine([]Rule{
+		{Name: "Disk", Metric: MetricDisk, Operator: ">", Threshold: 90},
+		{Name: "Temp", Metric: MetricCPUTemp, Operator: ">", Threshold: 80},
+	})
+
+	s := &stats.SystemStats{DiskUsed: 50, DiskTotal: 100, CPUTemp: 50}
+	fired, cleared := e.Evaluate(s)
+	if len(fired) != 0 || len(cleared) != 0 {
+		t.Fatalf("expected no transitions, got fired=%v cleared=%v", fired, cleared)
+	}
+
+	s.DiskUsed = 95
+	fired, _ = e.Evaluate(s)
+	if len(fired) != 1 || fired[0].Rule.Name != "Disk" {
+		t.Fatalf("expected Disk alert to fire, got %v", fired)
+	}
+	if len(e.Active()) != 1 {
+		t.Errorf("expected 1 active alert, got %d", len(e.Active()))
+	}
+
+	// Still firing: no new transition
+	fired, cleared = e.Evaluate(s)
+	if len(fired) != 0 || len(cleared) != 0 {
+		t.Errorf("expected no transitions while still firing")
+	}
+
+	s.CPUTemp = 85
+	fired, _ = e.Evaluate(s)
+	if len(fired) != 1 || fired[0].Rule.Name != "Temp" {
+		t.Fatalf("expected Temp alert to fire, got %v", fired)
+	}
+	active := e.Active()
+	if len(active) != 2 || active[0].Rule.Name != "Disk" || active[1].Rule.Name != "Temp" {
+		t.Errorf("expected active alerts in rule order, got %v", active)
+	}
+
+	s.DiskUsed = 10
+	_, cleared = e.Evaluate(s)
+	if len(cleared) != 1 || cleared[0].Rule.Name != "Disk" {
+		t.Fatalf("expected Disk alert to clear, got %v", cleared)
+	}
+	if len(e.Active()) != 1 {
+		t.Errorf("expected 1 active alert after clear, got %d", len(e.Active()))
+	}
+}
+
+func TestEngineOperators(t *testing.T) {
+	tests := []struct {
+		op        string
+		value     float64
+		threshold float64
+		want      bool
+	}{
+		{">", 5, 5, false},
+		{">=", 5, 5, true},
+		{"<", 4, 5, true},
+		{"<=", 5, 5, true},
+		{"<", 6, 5, false},
+	}
+	for _, tt := range tests {
+		if got := compare(tt.value, tt.op, tt.threshold); got != tt.want {
+			t.Errorf("compare(%v %s %v) = %v, want %v", tt.value, tt.op, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestEngineUnavailableMetric(t *testing.T) {
+	e := NewEngine([]Rule{{Name: "Cold", Metric: MetricCPUTemp, Operator: "<", Threshold: 10}})
+
+	// CPUTemp of 0 means no sensor; the rule must not fire
+	fired, _ := e.Evaluate(&stats.SystemStats{})
+	if len(fired) != 0 {
+		t.Errorf("expected no alert for unavailable metric, got %v", fired)
+	}
+}
+
+func TestAlertMessage(t *testing.T) {
+	a := Alert{Rule: Rule{Name: "Disk"}, Value: 92.34}
+	if got := a.Message(); got != "Disk: 92.3" {
+		t.Errorf("expected 'Disk: 92.3', got %q", got)
+	}
+}
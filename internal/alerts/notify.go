@@ -0,0 +1,143 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// Alert transition events reported to notifiers
+const (
+	EventFired   = "fired"
+	EventCleared = "cleared"
+)
+
+const defaultNotifyTimeout = 10 * time.Second
+
+// Payload is the JSON document delivered to webhooks and commands
+type Payload struct {
+	Event     string    `json:"event"`
+	Hostname  string    `json:"hostname"`
+	Rule      string    `json:"rule"`
+	Metric    string    `json:"metric"`
+	Operator  string    `json:"operator"`
+	Threshold float64   `json:"threshold"`
+	Value     float64   `json:"value"`
+	Message   string    `json:"message"`
+	Since     time.Time `json:"since"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewPayload builds the notification payload for an alert transition
+func NewPayload(event string, a Alert, hostname string) Payload {
+	return Payload{
+		Event:     event,
+		Hostname:  hostname,
+		Rule:      a.Rule.Name,
+		Metric:    a.Rule.Metric,
+		Operator:  a.Rule.Operator,
+		Threshold: a.Rule.Threshold,
+		Value:     a.Value,
+		Message:   a.Message(),
+		Since:     a.Since,
+		Timestamp: time.Now(),
+	}
+}
+
+// Notifier delivers alert transitions to a webhook and/or external command.
+// Deliveries run in the background so a slow endpoint never stalls rendering.
+type Notifier struct {
+	webhookURL string
+	command    []string
+	timeout    time.Duration
+	hostname   string
+	client     *http.Client
+	log        *logger.Logger
+	wg         sync.WaitGroup
+}
+
+// NewNotifier creates a notifier from config. Returns nil when neither a
+// webhook nor a command is configured.
+func NewNotifier(cfg config.AlertNotifyConfig, log *logger.Logger) *Notifier {
+	if cfg.WebhookURL == "" && len(cfg.Command) == 0 {
+		return nil
+	}
+
+	timeout := defaultNotifyTimeout
+	if d, err := time.ParseDuration(cfg.Timeout); err == nil && d > 0 {
+		timeout = d
+	}
+
+	hostname, _ := os.Hostname()
+	return &Notifier{
+		webhookURL: cfg.WebhookURL,
+		command:    cfg.Command,
+		timeout:    timeout,
+		hostname:   hostname,
+		client:     &http.Client{Timeout: timeout},
+		log:        log,
+	}
+}
+
+// Notify asynchronously delivers an alert transition event
+func (n *Notifier) Notify(event string, a Alert) {
+	body, err := json.Marshal(NewPayload(event, a, n.hostname))
+	if err != nil {
+		n.log.ErrorWithErr(err, "Failed to encode alert notification")
+		return
+	}
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		if n.webhookURL != "" {
+			if err := n.postWebhook(body); err != nil {
+				n.log.With().Str("rule", a.Rule.Name).Str("event", event).Err(err).Logger().Warn("Alert webhook failed")
+			}
+		}
+		if len(n.command) > 0 {
+			if err := n.runCommand(body); err != nil {
+				n.log.With().Str("rule", a.Rule.Name).Str("event", event).Err(err).Logger().Warn("Alert command failed")
+			}
+		}
+	}()
+}
+
+// Wait blocks until all in-flight notifications have completed
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
+
+func (n *Notifier) postWebhook(body []byte) error {
+	resp, err := n.client.Post(n.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *Notifier) runCommand(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, n.command[0], n.command[1:]...) // #nosec G204 -- command comes from trusted config
+	cmd.Stdin = bytes.NewReader(body)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
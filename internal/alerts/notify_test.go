@@ -0,0 +1,77 @@
+package alerts
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+func testAlert() Alert {
+	return Alert{
+		Rule:  Rule{Name: "Disk", Metric: MetricDisk, Operator: ">", Threshold: 90},
+		Value: 95,
+		Since: time.Now(),
+	}
+}
+
+func TestNewNotifierDisabled(t *testing.T) {
+	if n := NewNotifier(config.AlertNotifyConfig{}, logger.NewDefault()); n != nil {
+		t.Error("expected nil notifier when nothing is configured")
+	}
+}
+
+func TestNotifierWebhook(t *testing.T) {
+	received := make(chan Payload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected application/json content type, got %q", ct)
+		}
+		var p Payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		received <- p
+	}))
+	defer srv.Close()
+
+	n := NewNotifier(config.AlertNotifyConfig{WebhookURL: srv.URL}, logger.NewDefault())
+	n.Notify(EventFired, testAlert())
+	n.Wait()
+
+	select {
+	case p := <-received:
+		if p.Event != EventFired || p.Rule != "Disk" || p.Value != 95 || p.Threshold != 90 {
+			t.Errorf("unexpected payload %+v", p)
+		}
+	default:
+		t.Fatal("webhook was not called")
+	}
+}
+
+func TestNotifierCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "payload.json")
+	n := NewNotifier(config.AlertNotifyConfig{
+		Command: []string{"sh", "-c", "cat > " + out},
+	}, logger.NewDefault())
+	n.Notify(EventCleared, testAlert())
+	n.Wait()
+
+	data, err := os.ReadFile(out) // #nosec G304 -- test temp file
+	if err != nil {
+		t.Fatalf("command did not write payload: %v", err)
+	}
+	var p Payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatalf("invalid payload JSON: %v", err)
+	}
+	if p.Event != EventCleared || p.Metric != MetricDisk {
+		t.Errorf("unexpected payload %+v", p)
+	}
+}
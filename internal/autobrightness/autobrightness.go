@@ -0,0 +1,186 @@
+package autobrightness
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/host/v3"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// smoothing is the weight given to a new lux sample in the moving average
+const smoothing = 0.3
+
+// minStep is the smallest brightness change applied, to avoid constant
+// tiny adjustments from sensor noise
+const minStep = 4
+
+// Config holds auto-brightness settings
+type Config struct {
+	Interval      time.Duration // how often the sensor is sampled
+	MinBrightness uint8         // brightness in darkness
+	MaxBrightness uint8         // brightness at or above MaxLux
+	MaxLux        float64       // ambient light level mapped to MaxBrightness
+}
+
+// Controller periodically samples a light sensor and reports the brightness
+// the display should use. It does not touch the display directly; apply is
+// expected to route the level through the screensaver so the two never fight.
+type Controller struct {
+	cfg    Config
+	sensor Sensor
+	apply  func(level uint8)
+	log    *logger.Logger
+	closer func() error
+
+	mu       sync.Mutex
+	lux      float64 // smoothed lux
+	haveLux  bool
+	level    uint8
+	applied  bool
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a controller that reads sensor and calls apply with the
+// computed brightness
+func New(sensor Sensor, cfg Config, apply func(level uint8), log *logger.Logger) *Controller {
+	return &Controller{
+		cfg:      cfg,
+		sensor:   sensor,
+		apply:    apply,
+		log:      log,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Open opens the I2C bus and initializes the named sensor. The returned
+// controller closes the bus when stopped.
+func Open(sensorName, i2cBus string, addr uint16, cfg Config, apply func(level uint8), log *logger.Logger) (*Controller, error) {
+	if _, err := host.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize periph: %w", err)
+	}
+
+	bus, err := i2creg.Open(i2cBus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open I2C bus %s: %w", i2cBus, err)
+	}
+
+	sensor, err := openSensor(sensorName, bus, addr)
+	if err != nil {
+		bus.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, err
+	}
+
+	c := New(sensor, cfg, apply, log)
+	c.closer = bus.Close
+	return c, nil
+}
+
+func openSensor(name string, bus i2c.Bus, addr uint16) (Sensor, error) {
+	if addr == 0 {
+		def, ok := DefaultAddress(name)
+		if !ok {
+			return nil, fmt.Errorf("unsupported light sensor %q", name)
+		}
+		addr = def
+	}
+	return NewSensor(name, bus, addr)
+}
+
+// Start samples the sensor immediately and then every Interval
+func (c *Controller) Start(ctx context.Context) {
+	c.sample()
+
+	go func() {
+		ticker := time.NewTicker(c.cfg.Interval)
+		defer ticker.Stop()
+		defer func() {
+			if r := recover(); r != nil {
+				c.log.Errorf("PANIC in auto-brightness: %v", r)
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.stopChan:
+				return
+			case <-ticker.C:
+				c.sample()
+			}
+		}
+	}()
+}
+
+// Stop stops sampling and releases the sensor bus
+func (c *Controller) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+		if c.closer != nil {
+			if err := c.closer(); err != nil {
+				c.log.With().Err(err).Logger().Debug("Error closing light sensor bus")
+			}
+		}
+	})
+}
+
+// Level returns the most recently computed brightness and smoothed lux
+func (c *Controller) Level() (level uint8, lux float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.level, c.lux
+}
+
+// sample reads the sensor, updates the smoothed lux and applies the new
+// brightness when it moved by at least minStep
+func (c *Controller) sample() {
+	lux, err := c.sensor.Lux()
+	if err != nil {
+		c.log.With().Err(err).Logger().Warn("Failed to read light sensor")
+		return
+	}
+
+	c.mu.Lock()
+	if c.haveLux {
+		c.lux = smoothing*lux + (1-smoothing)*c.lux
+	} else {
+		c.lux, c.haveLux = lux, true
+	}
+	level := c.brightnessFor(c.lux)
+	diff := int(level) - int(c.level)
+	changed := !c.applied || diff >= minStep || diff <= -minStep ||
+		(level != c.level && (level == c.cfg.MinBrightness || level == c.cfg.MaxBrightness))
+	if changed {
+		c.level = level
+		c.applied = true
+	}
+	smoothed := c.lux
+	c.mu.Unlock()
+
+	if changed {
+		c.log.With().Float64("lux", smoothed).Int("brightness", int(level)).Logger().Debug("Adjusting brightness for ambient light")
+		c.apply(level)
+	}
+}
+
+// brightnessFor maps lux onto [MinBrightness, MaxBrightness] on a log scale,
+// which tracks perceived brightness far better than a linear mapping
+func (c *Controller) brightnessFor(lux float64) uint8 {
+	lo, hi := float64(c.cfg.MinBrightness), float64(c.cfg.MaxBrightness)
+	if c.cfg.MaxLux <= 0 || lux <= 0 {
+		return c.cfg.MinBrightness
+	}
+	frac := math.Log10(1+lux) / math.Log10(1+c.cfg.MaxLux)
+	if frac > 1 {
+		frac = 1
+	}
+	return uint8(math.Round(lo + (hi-lo)*frac)) // #nosec G115 -- result is within [lo, hi] ⊆ [0, 255]
+}
@@ -0,0 +1,159 @@
+package autobrightness
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/physic"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// fakeSensor returns queued lux readings
+type fakeSensor struct {
+	readings []float64
+	err      error
+}
+
+func (s *fakeSensor) Lux() (float64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	lux := s.readings[0]
+	if len(s.readings) > 1 {
+		s.readings = s.readings[1:]
+	}
+	return lux, nil
+}
+
+func testConfig() Config {
+	return Config{Interval: time.Second, MinBrightness: 10, MaxBrightness: 255, MaxLux: 1000}
+}
+
+func TestBrightnessFor(t *testing.T) {
+	c := New(&fakeSensor{}, testConfig(), func(uint8) {}, logger.NewDefault())
+
+	tests := []struct {
+		lux  float64
+		want uint8
+	}{
+		{0, 10},
+		{1000, 255},
+		{50000, 255},
+		{math.Sqrt(1001) - 1, 133}, // halfway on the log scale
+	}
+	for _, tt := range tests {
+		if got := c.brightnessFor(tt.lux); got != tt.want {
+			t.Errorf("brightnessFor(%.1f) = %d, want %d", tt.lux, got, tt.want)
+		}
+	}
+}
+
+func TestControllerAppliesOnSignificantChange(t *testing.T) {
+	sensor := &fakeSensor{readings: []float64{1000}}
+	var applied []uint8
+	c := New(sensor, testConfig(), func(l uint8) { applied = append(applied, l) }, logger.NewDefault())
+
+	c.sample()
+	if len(applied) != 1 || applied[0] != 255 {
+		t.Fatalf("expected initial brightness 255, got %v", applied)
+	}
+
+	// Tiny fluctuations are ignored
+	sensor.readings = []float64{990}
+	c.sample()
+	if len(applied) != 1 {
+		t.Errorf("expected noise to be ignored, got %v", applied)
+	}
+
+	// Darkness is approached gradually through smoothing
+	sensor.readings = []float64{0}
+	for i := 0; i < 60; i++ {
+		c.sample()
+	}
+	if last := applied[len(applied)-1]; last != 10 {
+		t.Errorf("expected brightness to settle at the minimum, got %d", last)
+	}
+	for i := 1; i < len(applied); i++ {
+		if applied[i] > applied[i-1] {
+			t.Errorf("expected brightness to decrease monotonically, got %v", applied)
+			break
+		}
+	}
+}
+
+func TestControllerSensorError(t *testing.T) {
+	called := false
+	c := New(&fakeSensor{err: errors.New("nack")}, testConfig(), func(uint8) { called = true }, logger.NewDefault())
+	c.sample()
+	if called {
+		t.Error("expected no brightness change on sensor error")
+	}
+}
+
+func TestTSL2561Lux(t *testing.T) {
+	if got := tsl2561Lux(0, 0); got != 0 {
+		t.Errorf("expected 0 lux in darkness, got %f", got)
+	}
+	// Mostly visible light: ratio 0.1
+	if got := tsl2561Lux(1000, 100); got < 27 || got > 29 {
+		t.Errorf("expected ~28 lux, got %f", got)
+	}
+	// Pure infrared yields no visible lux
+	if got := tsl2561Lux(100, 200); got != 0 {
+		t.Errorf("expected 0 lux for IR-dominated light, got %f", got)
+	}
+}
+
+// fakeBus records writes and answers reads with a fixed payload
+type fakeBus struct {
+	writes [][]byte
+	read   []byte
+}
+
+func (b *fakeBus) String() string                    { return "fake" }
+func (b *fakeBus) SetSpeed(f physic.Frequency) error { return nil }
+func (b *fakeBus) Tx(addr uint16, w, r []byte) error {
+	if len(w) > 0 {
+		b.writes = append(b.writes, append([]byte(nil), w...))
+	}
+	copy(r, b.read)
+	return nil
+}
+
+func TestSensorDrivers(t *testing.T) {
+	tests := []struct {
+		name    string
+		read    []byte
+		wantLux float64
+	}{
+		{SensorBH1750, []byte{0x01, 0x2C}, 250},             // 300 counts / 1.2
+		{SensorVEML7700, []byte{0xE8, 0x03}, 1000 * 0.0576}, // 1000 counts little-endian
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, _ := DefaultAddress(tt.name)
+			bus := &fakeBus{read: tt.read}
+			s, err := NewSensor(tt.name, bus, addr)
+			if err != nil {
+				t.Fatalf("NewSensor() failed: %v", err)
+			}
+			lux, err := s.Lux()
+			if err != nil {
+				t.Fatalf("Lux() failed: %v", err)
+			}
+			if math.Abs(lux-tt.wantLux) > 0.01 {
+				t.Errorf("Lux() = %f, want %f", lux, tt.wantLux)
+			}
+			if len(bus.writes) == 0 {
+				t.Error("expected configuration writes")
+			}
+		})
+	}
+
+	if _, err := NewSensor("bogus", &fakeBus{}, 0x10); err == nil {
+		t.Error("expected error for unsupported sensor")
+	}
+}
@@ -0,0 +1,180 @@
+package autobrightness
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"periph.io/x/conn/v3/i2c"
+)
+
+// Supported sensor names
+const (
+	SensorBH1750   = "bh1750"
+	SensorTSL2561  = "tsl2561"
+	SensorVEML7700 = "veml7700"
+)
+
+// Sensor reads ambient light in lux
+type Sensor interface {
+	Lux() (float64, error)
+}
+
+// DefaultAddress returns the factory I2C address for a sensor
+func DefaultAddress(sensor string) (uint16, bool) {
+	switch sensor {
+	case SensorBH1750:
+		return 0x23, true
+	case SensorTSL2561:
+		return 0x39, true
+	case SensorVEML7700:
+		return 0x10, true
+	default:
+		return 0, false
+	}
+}
+
+// NewSensor powers up and configures the named sensor on bus
+func NewSensor(name string, bus i2c.Bus, addr uint16) (Sensor, error) {
+	dev := &i2c.Dev{Bus: bus, Addr: addr}
+	switch name {
+	case SensorBH1750:
+		return newBH1750(dev)
+	case SensorTSL2561:
+		return newTSL2561(dev)
+	case SensorVEML7700:
+		return newVEML7700(dev)
+	default:
+		return nil, fmt.Errorf("unsupported light sensor %q", name)
+	}
+}
+
+// BH1750 commands
+const (
+	bh1750PowerOn        byte = 0x01
+	bh1750ContinuousHRes byte = 0x10 // 1 lx resolution, 120ms measurement
+)
+
+type bh1750 struct {
+	dev *i2c.Dev
+}
+
+func newBH1750(dev *i2c.Dev) (*bh1750, error) {
+	if err := dev.Tx([]byte{bh1750PowerOn}, nil); err != nil {
+		return nil, fmt.Errorf("bh1750 power on: %w", err)
+	}
+	if err := dev.Tx([]byte{bh1750ContinuousHRes}, nil); err != nil {
+		return nil, fmt.Errorf("bh1750 set mode: %w", err)
+	}
+	time.Sleep(180 * time.Millisecond) // first measurement
+	return &bh1750{dev: dev}, nil
+}
+
+// Lux returns the last continuous measurement
+func (s *bh1750) Lux() (float64, error) {
+	buf := make([]byte, 2)
+	if err := s.dev.Tx(nil, buf); err != nil {
+		return 0, fmt.Errorf("bh1750 read: %w", err)
+	}
+	return float64(binary.BigEndian.Uint16(buf)) / 1.2, nil
+}
+
+// TSL2561 registers, accessed with the command bit set
+const (
+	tsl2561Command    byte = 0x80
+	tsl2561Word       byte = 0x20
+	tsl2561RegControl byte = 0x00
+	tsl2561RegTiming  byte = 0x01
+	tsl2561RegData0   byte = 0x0C
+	tsl2561RegData1   byte = 0x0E
+	tsl2561PowerOn    byte = 0x03
+	tsl2561Gain16x402 byte = 0x12 // 16x gain, 402ms integration (datasheet lux formula baseline)
+)
+
+type tsl2561 struct {
+	dev *i2c.Dev
+}
+
+func newTSL2561(dev *i2c.Dev) (*tsl2561, error) {
+	if err := dev.Tx([]byte{tsl2561Command | tsl2561RegControl, tsl2561PowerOn}, nil); err != nil {
+		return nil, fmt.Errorf("tsl2561 power on: %w", err)
+	}
+	if err := dev.Tx([]byte{tsl2561Command | tsl2561RegTiming, tsl2561Gain16x402}, nil); err != nil {
+		return nil, fmt.Errorf("tsl2561 set timing: %w", err)
+	}
+	time.Sleep(410 * time.Millisecond) // first integration cycle
+	return &tsl2561{dev: dev}, nil
+}
+
+func (s *tsl2561) readChannel(reg byte) (uint16, error) {
+	buf := make([]byte, 2)
+	if err := s.dev.Tx([]byte{tsl2561Command | tsl2561Word | reg}, buf); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(buf), nil
+}
+
+// Lux reads both photodiode channels and applies the datasheet formula
+func (s *tsl2561) Lux() (float64, error) {
+	ch0, err := s.readChannel(tsl2561RegData0)
+	if err != nil {
+		return 0, fmt.Errorf("tsl2561 read ch0: %w", err)
+	}
+	ch1, err := s.readChannel(tsl2561RegData1)
+	if err != nil {
+		return 0, fmt.Errorf("tsl2561 read ch1: %w", err)
+	}
+	return tsl2561Lux(float64(ch0), float64(ch1)), nil
+}
+
+// tsl2561Lux converts broadband (ch0) and infrared (ch1) counts to lux using
+// the piecewise approximation from the TSL2561 datasheet (T/FN/CL package)
+func tsl2561Lux(ch0, ch1 float64) float64 {
+	if ch0 == 0 {
+		return 0
+	}
+	ratio := ch1 / ch0
+	var lux float64
+	switch {
+	case ratio <= 0.50:
+		lux = 0.0304*ch0 - 0.062*ch0*math.Pow(ratio, 1.4)
+	case ratio <= 0.61:
+		lux = 0.0224*ch0 - 0.031*ch1
+	case ratio <= 0.80:
+		lux = 0.0128*ch0 - 0.0153*ch1
+	case ratio <= 1.30:
+		lux = 0.00146*ch0 - 0.00112*ch1
+	}
+	return math.Max(lux, 0)
+}
+
+// VEML7700 registers and settings
+const (
+	veml7700RegConfig byte = 0x00
+	veml7700RegALS    byte = 0x04
+	// Resolution in lux/count at gain 1x, 100ms integration time
+	veml7700Resolution = 0.0576
+)
+
+type veml7700 struct {
+	dev *i2c.Dev
+}
+
+func newVEML7700(dev *i2c.Dev) (*veml7700, error) {
+	// Config 0x0000: gain 1x, 100ms integration, interrupts off, powered on
+	if err := dev.Tx([]byte{veml7700RegConfig, 0x00, 0x00}, nil); err != nil {
+		return nil, fmt.Errorf("veml7700 configure: %w", err)
+	}
+	time.Sleep(110 * time.Millisecond) // first integration cycle
+	return &veml7700{dev: dev}, nil
+}
+
+// Lux reads the ambient light channel
+func (s *veml7700) Lux() (float64, error) {
+	buf := make([]byte, 2)
+	if err := s.dev.Tx([]byte{veml7700RegALS}, buf); err != nil {
+		return 0, fmt.Errorf("veml7700 read: %w", err)
+	}
+	return float64(binary.LittleEndian.Uint16(buf)) * veml7700Resolution, nil
+}
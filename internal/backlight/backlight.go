@@ -0,0 +1,252 @@
+package backlight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// DefaultStatePath is where cumulative on-time is persisted between runs
+const DefaultStatePath = "/var/lib/i2c-display/backlight.json"
+
+// Config holds backlight protection settings
+type Config struct {
+	StatePath         string
+	MaxDailyOn        time.Duration // 0 = unlimited; panel is blanked once exceeded until midnight
+	AutoDimAfter      time.Duration // 0 = disabled; dim after this much continuous on-time
+	AutoDimBrightness uint8
+	SaveInterval      time.Duration // how often state is written to disk
+}
+
+// state is the persisted on-time record
+type state struct {
+	TotalSeconds float64 `json:"total_seconds"`
+	Day          string  `json:"day"` // YYYY-MM-DD in local time
+	DaySeconds   float64 `json:"day_seconds"`
+}
+
+// Guard wraps a Display, tracking how long the backlight has been on and
+// enforcing daily/continuous on-time limits by clamping SetBrightness.
+type Guard struct {
+	display.Display
+
+	cfg Config
+	log *logger.Logger
+	now func() time.Time
+
+	mu              sync.Mutex
+	st              state
+	requested       uint8     // brightness last requested by callers
+	applied         uint8     // brightness last sent to the panel
+	lastTick        time.Time // last time on-time was accumulated
+	continuousSince time.Time // zero while the backlight is off
+	onHoursFunc     func(hours float64)
+	stopChan        chan struct{}
+	doneChan        chan struct{}
+	stopOnce        sync.Once
+}
+
+// NewGuard wraps disp and loads any persisted on-time from cfg.StatePath.
+// The backlight is assumed on at full brightness until told otherwise.
+func NewGuard(disp display.Display, cfg Config, log *logger.Logger) *Guard {
+	if cfg.StatePath == "" {
+		cfg.StatePath = DefaultStatePath
+	}
+	if cfg.SaveInterval <= 0 {
+		cfg.SaveInterval = 5 * time.Minute
+	}
+	g := &Guard{
+		Display:   disp,
+		cfg:       cfg,
+		log:       log,
+		now:       time.Now,
+		requested: 255,
+		applied:   255,
+		stopChan:  make(chan struct{}),
+		doneChan:  make(chan struct{}),
+	}
+	if err := g.load(); err != nil && !os.IsNotExist(err) {
+		log.ErrorWithErr(err, "Failed to load backlight state, starting from zero")
+	}
+	now := g.now()
+	g.lastTick = now
+	g.continuousSince = now
+	return g
+}
+
+// SetOnHoursFunc registers a callback receiving cumulative on-hours after
+// each accumulation tick (used to export the value as a metric).
+func (g *Guard) SetOnHoursFunc(fn func(hours float64)) {
+	g.mu.Lock()
+	g.onHoursFunc = fn
+	g.mu.Unlock()
+}
+
+// SetBrightness records the requested level and applies it, clamped by any active limit.
+func (g *Guard) SetBrightness(level uint8) error {
+	g.mu.Lock()
+	g.accumulate()
+	g.requested = level
+	target := g.limitedLevel()
+	g.mu.Unlock()
+
+	return g.apply(target)
+}
+
+// Start begins periodic accumulation and limit enforcement
+func (g *Guard) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	saveTicker := time.NewTicker(g.cfg.SaveInterval)
+	go func() {
+		defer close(g.doneChan)
+		defer ticker.Stop()
+		defer saveTicker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				g.save()
+				return
+			case <-g.stopChan:
+				g.save()
+				return
+			case <-ticker.C:
+				g.check()
+			case <-saveTicker.C:
+				g.save()
+			}
+		}
+	}()
+}
+
+// Stop stops the enforcement loop and waits for the final state save.
+// Must only be called after Start.
+func (g *Guard) Stop() {
+	g.stopOnce.Do(func() { close(g.stopChan) })
+	<-g.doneChan
+}
+
+// OnTime returns cumulative and today's backlight-on durations
+func (g *Guard) OnTime() (total, today time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.accumulate()
+	return secondsToDuration(g.st.TotalSeconds), secondsToDuration(g.st.DaySeconds)
+}
+
+// check accumulates on-time and re-applies brightness if a limit changed the target
+func (g *Guard) check() {
+	g.mu.Lock()
+	g.accumulate()
+	target := g.limitedLevel()
+	changed := target != g.applied
+	fn := g.onHoursFunc
+	hours := g.st.TotalSeconds / 3600
+	g.mu.Unlock()
+
+	if fn != nil {
+		fn(hours)
+	}
+	if changed {
+		if err := g.apply(target); err != nil {
+			g.log.ErrorWithErr(err, "Failed to apply backlight limit")
+		}
+	}
+}
+
+// apply sends level to the panel and updates continuous on-time tracking
+func (g *Guard) apply(level uint8) error {
+	if err := g.Display.SetBrightness(level); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	wasOn := g.applied > 0
+	g.applied = level
+	switch {
+	case level == 0:
+		g.continuousSince = time.Time{}
+	case !wasOn:
+		g.continuousSince = g.now()
+	}
+	return nil
+}
+
+// limitedLevel returns the brightness to apply given the current limits.
+// Must be called with g.mu held.
+func (g *Guard) limitedLevel() uint8 {
+	level := g.requested
+	if g.cfg.MaxDailyOn > 0 && secondsToDuration(g.st.DaySeconds) >= g.cfg.MaxDailyOn {
+		return 0
+	}
+	if g.cfg.AutoDimAfter > 0 && !g.continuousSince.IsZero() &&
+		g.now().Sub(g.continuousSince) >= g.cfg.AutoDimAfter && level > g.cfg.AutoDimBrightness {
+		return g.cfg.AutoDimBrightness
+	}
+	return level
+}
+
+// accumulate adds elapsed on-time since the last tick, rolling the daily
+// counter over at local midnight. Must be called with g.mu held.
+func (g *Guard) accumulate() {
+	now := g.now()
+	today := now.Format("2006-01-02")
+	if g.st.Day != today {
+		g.st.Day = today
+		g.st.DaySeconds = 0
+	}
+	if g.applied > 0 {
+		elapsed := now.Sub(g.lastTick).Seconds()
+		if elapsed > 0 {
+			g.st.TotalSeconds += elapsed
+			g.st.DaySeconds += elapsed
+		}
+	}
+	g.lastTick = now
+}
+
+func (g *Guard) load() error {
+	data, err := os.ReadFile(g.cfg.StatePath)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &g.st); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", g.cfg.StatePath, err)
+	}
+	return nil
+}
+
+// save writes the state atomically via a temporary file
+func (g *Guard) save() {
+	g.mu.Lock()
+	g.accumulate()
+	data, err := json.Marshal(g.st)
+	g.mu.Unlock()
+	if err != nil {
+		g.log.ErrorWithErr(err, "Failed to encode backlight state")
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(g.cfg.StatePath), 0o750); err != nil {
+		g.log.ErrorWithErr(err, "Failed to create backlight state directory")
+		return
+	}
+	tmp := g.cfg.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		g.log.ErrorWithErr(err, "Failed to write backlight state")
+		return
+	}
+	if err := os.Rename(tmp, g.cfg.StatePath); err != nil {
+		g.log.ErrorWithErr(err, "Failed to save backlight state")
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,149 @@
+package backlight
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// fakeClock is a manually advanced time source
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+func newTestGuard(t *testing.T, cfg Config) (*Guard, *display.MockDisplay, *fakeClock) {
+	t.Helper()
+	if cfg.StatePath == "" {
+		cfg.StatePath = filepath.Join(t.TempDir(), "backlight.json")
+	}
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)}
+	mock := display.NewMockDisplay(160, 80)
+	g := NewGuard(mock, cfg, logger.NewDefault())
+	g.now = clock.now
+	g.lastTick = clock.now()
+	g.continuousSince = clock.now()
+	return g, mock, clock
+}
+
+func lastBrightness(mock *display.MockDisplay) string {
+	calls := mock.GetCalls()
+	for i := len(calls) - 1; i >= 0; i-- {
+		if len(calls[i]) > 13 && calls[i][:13] == "SetBrightness" {
+			return calls[i]
+		}
+	}
+	return ""
+}
+
+func TestGuardAccumulatesOnTime(t *testing.T) {
+	g, _, clock := newTestGuard(t, Config{})
+
+	clock.advance(2 * time.Hour)
+	total, today := g.OnTime()
+	if total != 2*time.Hour || today != 2*time.Hour {
+		t.Errorf("expected 2h on-time, got total=%v today=%v", total, today)
+	}
+
+	// Time with the backlight off is not counted
+	if err := g.SetBrightness(0); err != nil {
+		t.Fatalf("SetBrightness() failed: %v", err)
+	}
+	clock.advance(time.Hour)
+	total, _ = g.OnTime()
+	if total != 2*time.Hour {
+		t.Errorf("expected on-time to stop while off, got %v", total)
+	}
+}
+
+func TestGuardMaxDailyOn(t *testing.T) {
+	g, mock, clock := newTestGuard(t, Config{MaxDailyOn: time.Hour})
+
+	clock.advance(61 * time.Minute)
+	g.check()
+	if got := lastBrightness(mock); got != "SetBrightness([0])" {
+		t.Errorf("expected panel blanked after daily limit, got %q", got)
+	}
+
+	// Requests during the limit are clamped to 0
+	if err := g.SetBrightness(200); err != nil {
+		t.Fatalf("SetBrightness() failed: %v", err)
+	}
+	if got := lastBrightness(mock); got != "SetBrightness([0])" {
+		t.Errorf("expected brightness clamped to 0, got %q", got)
+	}
+
+	// New day lifts the limit and restores the requested level
+	clock.advance(24 * time.Hour)
+	g.check()
+	if got := lastBrightness(mock); got != "SetBrightness([200])" {
+		t.Errorf("expected requested brightness restored on new day, got %q", got)
+	}
+}
+
+func TestGuardAutoDim(t *testing.T) {
+	g, mock, clock := newTestGuard(t, Config{AutoDimAfter: 4 * time.Hour, AutoDimBrightness: 40})
+
+	clock.advance(3 * time.Hour)
+	g.check()
+	if got := lastBrightness(mock); got != "" {
+		t.Errorf("expected no brightness change before auto-dim, got %q", got)
+	}
+
+	clock.advance(time.Hour)
+	g.check()
+	if got := lastBrightness(mock); got != "SetBrightness([40])" {
+		t.Errorf("expected auto-dim to 40, got %q", got)
+	}
+
+	// Turning the backlight off and on again resets continuous operation
+	_ = g.SetBrightness(0)
+	clock.advance(time.Minute)
+	_ = g.SetBrightness(255)
+	if got := lastBrightness(mock); got != "SetBrightness([255])" {
+		t.Errorf("expected full brightness after power cycle, got %q", got)
+	}
+}
+
+func TestGuardPersistsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "backlight.json")
+	g, _, clock := newTestGuard(t, Config{StatePath: path})
+	clock.advance(90 * time.Minute)
+	g.save()
+
+	g2 := NewGuard(display.NewMockDisplay(160, 80), Config{StatePath: path}, logger.NewDefault())
+	g2.now = clock.now
+	g2.lastTick = clock.now()
+	total, _ := g2.OnTime()
+	if total != 90*time.Minute {
+		t.Errorf("expected persisted on-time of 90m, got %v", total)
+	}
+}
+
+func TestGuardOnHoursFunc(t *testing.T) {
+	g, _, clock := newTestGuard(t, Config{})
+	var got float64
+	g.SetOnHoursFunc(func(h float64) { got = h })
+
+	clock.advance(3 * time.Hour)
+	g.check()
+	if got != 3 {
+		t.Errorf("expected 3 on-hours reported, got %f", got)
+	}
+}
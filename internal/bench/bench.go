@@ -0,0 +1,143 @@
+// Package bench implements the -bench benchmark: it renders a synthetic
+// animation as fast as the display takes it and reports the frame rate,
+// bus throughput and allocations, to pick refresh intervals the bus can
+// keep up with.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+)
+
+// Result is the outcome of a benchmark
+type Result struct {
+	Frames  int
+	Elapsed time.Duration
+
+	Render time.Duration // mean time drawing a frame into the buffer
+	Flush  time.Duration // mean time sending a frame to the panel
+
+	BytesFlushed   int     // framebuffer bytes sent
+	AllocsPerFrame float64 // heap allocations per frame
+	AllocBytes     float64 // heap bytes allocated per frame
+}
+
+// FPS returns the frames shown per second
+func (r Result) FPS() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Frames) / r.Elapsed.Seconds()
+}
+
+// BytesPerSecond returns the framebuffer bytes sent per second
+func (r Result) BytesPerSecond() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.BytesFlushed) / r.Elapsed.Seconds()
+}
+
+// FrameTime returns the mean time to draw and show a frame, the shortest
+// refresh interval the display can sustain
+func (r Result) FrameTime() time.Duration {
+	return r.Render + r.Flush
+}
+
+// Run animates disp for d, or until ctx is cancelled, and returns the
+// result. It stops at the first error, as a failing bus makes the numbers
+// meaningless.
+func Run(ctx context.Context, disp display.Display, d time.Duration) (Result, error) {
+	if d <= 0 {
+		return Result{}, fmt.Errorf("benchmark duration must be positive, got %v", d)
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	counting := display.NewCountingDisplay(disp)
+	draw := animation(counting)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	var res Result
+	var render, flush time.Duration
+	start := time.Now()
+	for ; ctx.Err() == nil; res.Frames++ {
+		t := time.Now()
+		if err := draw(res.Frames); err != nil {
+			return res, fmt.Errorf("frame %d: %w", res.Frames, err)
+		}
+		t2 := time.Now()
+		render += t2.Sub(t)
+		if err := counting.Show(); err != nil {
+			return res, fmt.Errorf("frame %d: %w", res.Frames, err)
+		}
+		flush += time.Since(t2)
+	}
+	res.Elapsed = time.Since(start)
+
+	runtime.ReadMemStats(&after)
+	res.BytesFlushed = counting.TakeCounts().BytesFlushed
+	if res.Frames > 0 {
+		n := time.Duration(res.Frames)
+		res.Render, res.Flush = render/n, flush/n
+		res.AllocsPerFrame = float64(after.Mallocs-before.Mallocs) / float64(res.Frames)
+		res.AllocBytes = float64(after.TotalAlloc-before.TotalAlloc) / float64(res.Frames)
+	}
+	return res, nil
+}
+
+// animation returns a function drawing frame n of the benchmark animation:
+// a box bouncing across the display above a frame counter, so every frame
+// differs. Character displays get a marker moving along the rows.
+func animation(disp display.Display) func(n int) error {
+	caps := display.AsColorDisplay(disp).Capabilities()
+	if caps.Text() {
+		cols, rows := max(caps.TextColumns, 1), max(caps.TextRows, 1)
+		return func(n int) error {
+			cells := []byte(strings.Repeat(" ", cols*rows))
+			copy(cells, fmt.Sprintf("%d", n))
+			cells[n%len(cells)] = '*'
+			lines := make([]string, rows)
+			for i := range lines {
+				lines[i] = string(cells[i*cols : (i+1)*cols])
+			}
+			return display.WriteLines(disp, lines)
+		}
+	}
+
+	b := disp.GetBounds()
+	size := max(min(b.Dx(), b.Dy())/4, 2)
+	return func(n int) error {
+		if err := disp.Clear(); err != nil {
+			return err
+		}
+		x := bounce(n*2, b.Dx()-size)
+		y := bounce(n, b.Dy()-size)
+		if err := disp.DrawRect(x, y, size, size, n%2 == 0); err != nil {
+			return err
+		}
+		if err := disp.DrawLine(0, b.Dy()-1, n%b.Dx()+1); err != nil {
+			return err
+		}
+		return disp.DrawText(0, 0, fmt.Sprintf("%d", n), display.FontSmall)
+	}
+}
+
+// bounce returns position n of a point moving back and forth over 0..span
+func bounce(n, span int) int {
+	if span <= 0 {
+		return 0
+	}
+	n %= 2 * span
+	if n > span {
+		return 2*span - n
+	}
+	return n
+}
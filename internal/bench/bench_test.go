@@ -0,0 +1,77 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+)
+
+func TestRun(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	res, err := Run(context.Background(), disp, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.Frames == 0 || res.FPS() <= 0 {
+		t.Fatalf("frames = %d, fps = %v, want some", res.Frames, res.FPS())
+	}
+	if want := res.Frames * 128 * 64 / 8; res.BytesFlushed != want {
+		t.Errorf("BytesFlushed = %d, want %d for %d frames", res.BytesFlushed, want, res.Frames)
+	}
+	if res.BytesPerSecond() <= 0 {
+		t.Errorf("BytesPerSecond() = %v", res.BytesPerSecond())
+	}
+	if res.FrameTime() <= 0 || res.FrameTime() > res.Elapsed {
+		t.Errorf("FrameTime() = %v over %v", res.FrameTime(), res.Elapsed)
+	}
+	if !slices.Contains(disp.GetCalls(), "Show") {
+		t.Error("frames were not shown")
+	}
+}
+
+func TestRunStopsOnError(t *testing.T) {
+	disp := display.NewFaultyDisplay(display.NewMockDisplay(32, 16), 1)
+	res, err := Run(context.Background(), disp, time.Second)
+	if !errors.Is(err, display.ErrInjectedFault) {
+		t.Fatalf("Run error = %v, want an injected fault", err)
+	}
+	if res.Frames != 0 {
+		t.Errorf("frames = %d, want 0", res.Frames)
+	}
+}
+
+func TestRunRejectsZeroDuration(t *testing.T) {
+	if _, err := Run(context.Background(), display.NewMockDisplay(32, 16), 0); err == nil {
+		t.Error("expected an error for a zero duration")
+	}
+}
+
+func TestAnimationChangesEveryFrame(t *testing.T) {
+	disp := display.NewMockDisplay(64, 32)
+	draw := animation(disp)
+	var prev []byte
+	for n := range 100 {
+		if err := draw(n); err != nil {
+			t.Fatalf("frame %d: %v", n, err)
+		}
+		buf := disp.GetBuffer()
+		if prev != nil && string(buf) == string(prev) {
+			t.Fatalf("frame %d is the same as frame %d", n, n-1)
+		}
+		prev = buf
+	}
+}
+
+func TestBounce(t *testing.T) {
+	for _, tt := range []struct{ n, span, want int }{
+		{0, 10, 0}, {4, 10, 4}, {10, 10, 10}, {13, 10, 7}, {20, 10, 0}, {23, 10, 3}, {5, 0, 0},
+	} {
+		if got := bounce(tt.n, tt.span); got != tt.want {
+			t.Errorf("bounce(%d, %d) = %d, want %d", tt.n, tt.span, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package buttons
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/host/v3"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// edgePollInterval bounds how long a watcher blocks waiting for an edge, so
+// Stop returns promptly
+const edgePollInterval = 250 * time.Millisecond
+
+// Pin is the part of a GPIO input the watcher uses. Buttons are wired between
+// the pin and ground, so a press reads low.
+type Pin interface {
+	WaitForEdge(timeout time.Duration) bool
+	Read() gpio.Level
+}
+
+// Button binds a pin to the action run on each press
+type Button struct {
+	Name    string
+	Pin     Pin
+	OnPress func()
+}
+
+// Watcher runs button actions when their pins are pressed
+type Watcher struct {
+	buttons  []Button
+	debounce time.Duration
+	log      *logger.Logger
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// New creates a watcher. Edges within debounce of the previous press on the
+// same pin are ignored.
+func New(debounce time.Duration, log *logger.Logger, buttons ...Button) *Watcher {
+	return &Watcher{
+		buttons:  buttons,
+		debounce: debounce,
+		log:      log,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// OpenPin looks up a GPIO by name (e.g. "GPIO17") and configures it as a
+// pulled-up input reporting falling edges
+func OpenPin(name string) (Pin, error) {
+	if _, err := host.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize periph: %w", err)
+	}
+	pin := gpioreg.ByName(name)
+	if pin == nil {
+		return nil, fmt.Errorf("GPIO pin %s not found", name)
+	}
+	if err := pin.In(gpio.PullUp, gpio.FallingEdge); err != nil {
+		return nil, fmt.Errorf("failed to configure GPIO pin %s as input: %w", name, err)
+	}
+	return pin, nil
+}
+
+// Start watches every button in its own goroutine
+func (w *Watcher) Start(ctx context.Context) {
+	for _, b := range w.buttons {
+		w.wg.Add(1)
+		go w.watch(ctx, b)
+	}
+}
+
+// Stop stops watching and waits for the watchers to exit
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopChan)
+	})
+	w.wg.Wait()
+}
+
+// watch waits for presses on one button until stopped
+func (w *Watcher) watch(ctx context.Context, b Button) {
+	defer w.wg.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			w.log.Errorf("PANIC in button watcher %s: %v", b.Name, r)
+		}
+	}()
+
+	var lastPress time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopChan:
+			return
+		default:
+		}
+
+		if !b.Pin.WaitForEdge(edgePollInterval) {
+			continue
+		}
+		now := time.Now()
+		if now.Sub(lastPress) < w.debounce || b.Pin.Read() != gpio.Low {
+			// Contact bounce, or the release edge of a bouncing press
+			continue
+		}
+		lastPress = now
+
+		w.log.With().Str("button", b.Name).Logger().Debug("Button pressed")
+		b.OnPress()
+	}
+}
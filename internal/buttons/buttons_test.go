@@ -0,0 +1,93 @@
+package buttons
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// fakePin delivers queued edges with the level read after each
+type fakePin struct {
+	edges chan gpio.Level
+	mu    sync.Mutex
+	level gpio.Level
+}
+
+func newFakePin() *fakePin {
+	return &fakePin{edges: make(chan gpio.Level, 16), level: gpio.High}
+}
+
+func (p *fakePin) WaitForEdge(timeout time.Duration) bool {
+	select {
+	case l := <-p.edges:
+		p.mu.Lock()
+		p.level = l
+		p.mu.Unlock()
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (p *fakePin) Read() gpio.Level {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.level
+}
+
+func TestWatcherDebouncesPresses(t *testing.T) {
+	pin := newFakePin()
+	var mu sync.Mutex
+	presses := 0
+
+	w := New(50*time.Millisecond, logger.NewDefault(), Button{
+		Name: "pause",
+		Pin:  pin,
+		OnPress: func() {
+			mu.Lock()
+			presses++
+			mu.Unlock()
+		},
+	})
+	w.Start(context.Background())
+
+	// One press with contact bounce, then the release
+	pin.edges <- gpio.Low
+	pin.edges <- gpio.Low
+	pin.edges <- gpio.High
+	time.Sleep(80 * time.Millisecond)
+
+	// A second, separate press
+	pin.edges <- gpio.Low
+	time.Sleep(20 * time.Millisecond)
+	w.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if presses != 2 {
+		t.Errorf("expected 2 presses after debouncing, got %d", presses)
+	}
+}
+
+func TestWatcherStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := New(0, logger.NewDefault(), Button{Name: "hold", Pin: newFakePin(), OnPress: func() {}})
+	w.Start(ctx)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("watcher did not stop after context cancel")
+	}
+}
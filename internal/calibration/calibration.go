@@ -0,0 +1,211 @@
+// Package calibration lets users tune a panel's contrast and gamma while
+// viewing a test image, then save the values into the configuration file.
+package calibration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/renderer"
+)
+
+// Button steps
+const (
+	contrastStep = 16
+	gammaStep    = 0.1
+)
+
+// ErrNotActive is returned when adjusting or saving while not calibrating
+var ErrNotActive = errors.New("calibration is not active")
+
+// Screen shows the calibration page in place of normal rotation
+type Screen interface {
+	ShowCalibration(page *renderer.CalibrationPage)
+	StopCalibration()
+}
+
+// Calibrator runs calibration sessions. A session starts from the values
+// in use, applies every change to the display straight away, and ends by
+// saving the values or by restoring the ones it started from.
+type Calibrator struct {
+	disp     *display.CalibratedDisplay
+	screen   Screen
+	path     string // configuration file values are saved to; "" if none
+	log      *logger.Logger
+	activity func() // optional, called on every change
+
+	mu       sync.Mutex
+	page     *renderer.CalibrationPage // shown while calibrating; nil otherwise
+	original display.Calibration
+	selected renderer.CalibrationSetting
+}
+
+// New creates a calibrator adjusting disp, showing the test image on screen
+// and saving into the configuration file at path
+func New(disp *display.CalibratedDisplay, screen Screen, path string, log *logger.Logger) *Calibrator {
+	return &Calibrator{disp: disp, screen: screen, path: path, log: log}
+}
+
+// SetActivityFunc sets a function called whenever calibration starts or a
+// value changes, e.g. to keep the screensaver from dimming the test image
+func (c *Calibrator) SetActivityFunc(f func()) {
+	c.activity = f
+}
+
+// Active reports whether a calibration session is running
+func (c *Calibrator) Active() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.page != nil
+}
+
+// Values returns the contrast and gamma in use
+func (c *Calibrator) Values() (int, float64) {
+	cal := c.disp.Calibration()
+	return int(cal.Contrast), cal.Gamma
+}
+
+// Start shows the test image, selecting the contrast for the buttons to
+// adjust. Starting while already calibrating does nothing.
+func (c *Calibrator) Start() {
+	c.mu.Lock()
+	if c.page != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.original = c.disp.Calibration()
+	c.selected = renderer.SettingContrast
+	c.page = renderer.NewCalibrationPage(int(c.original.Contrast), c.original.Gamma)
+	page := c.page
+	c.mu.Unlock()
+
+	c.log.Info("Calibration started")
+	c.touch()
+	c.screen.ShowCalibration(page)
+}
+
+// Adjust applies a new contrast (1-255) and gamma
+func (c *Calibrator) Adjust(contrast int, gamma float64) error {
+	if contrast < 1 || contrast > 255 {
+		return fmt.Errorf("contrast must be 1-255, got %d", contrast)
+	}
+	if gamma < config.MinGamma || gamma > config.MaxGamma {
+		return fmt.Errorf("gamma must be between %g and %g, got %g", config.MinGamma, config.MaxGamma, gamma)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.page == nil {
+		return ErrNotActive
+	}
+	return c.apply(contrast, gamma)
+}
+
+// apply sets the values on the display and the page. Callers hold mu.
+func (c *Calibrator) apply(contrast int, gamma float64) error {
+	cal := display.Calibration{Contrast: uint8(contrast), Gamma: gamma} // #nosec G115 -- 1-255
+	c.page.Set(contrast, gamma, c.selected)
+	c.touch()
+	if err := c.disp.SetCalibration(cal); err != nil {
+		return fmt.Errorf("failed to apply calibration: %w", err)
+	}
+	c.log.With().Int("contrast", contrast).Float64("gamma", gamma).Logger().Debug("Calibration adjusted")
+	return nil
+}
+
+// Step raises the selected setting by one step, wrapping round to its
+// lowest value past the highest, for a button to cycle through
+func (c *Calibrator) Step() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.page == nil {
+		return ErrNotActive
+	}
+	contrast, gamma := c.Values()
+	switch c.selected {
+	case renderer.SettingContrast:
+		if contrast == 255 {
+			contrast = contrastStep
+		} else {
+			contrast = min(contrast+contrastStep, 255)
+		}
+	case renderer.SettingGamma:
+		gamma = math.Round((gamma+gammaStep)*10) / 10
+		if gamma > config.MaxGamma {
+			gamma = config.MinGamma
+		}
+	}
+	return c.apply(contrast, gamma)
+}
+
+// Next selects the next setting for Step, saving the values after the last
+func (c *Calibrator) Next() error {
+	c.mu.Lock()
+	if c.page == nil {
+		c.mu.Unlock()
+		return ErrNotActive
+	}
+	if c.selected == renderer.SettingContrast {
+		c.selected = renderer.SettingGamma
+		contrast, gamma := c.Values()
+		c.page.Set(contrast, gamma, c.selected)
+		c.touch()
+		c.mu.Unlock()
+		return nil
+	}
+	c.mu.Unlock()
+	return c.Save()
+}
+
+// Save writes the values into the configuration file and ends the session,
+// keeping them in use. The session carries on if they cannot be saved.
+func (c *Calibrator) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.page == nil {
+		return ErrNotActive
+	}
+	if c.path == "" {
+		return errors.New("no configuration file to save to")
+	}
+	contrast, gamma := c.Values()
+	if err := config.SaveCalibration(c.path, contrast, gamma); err != nil {
+		return err
+	}
+	c.stop()
+	c.log.With().Int("contrast", contrast).Float64("gamma", gamma).Str("path", c.path).Logger().Info("Calibration saved")
+	return nil
+}
+
+// Cancel restores the values in use when the session started and ends it
+func (c *Calibrator) Cancel() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.page == nil {
+		return ErrNotActive
+	}
+	c.stop()
+	c.log.Info("Calibration cancelled")
+	if err := c.disp.SetCalibration(c.original); err != nil {
+		return fmt.Errorf("failed to apply calibration: %w", err)
+	}
+	return nil
+}
+
+// stop removes the test image. Callers hold mu.
+func (c *Calibrator) stop() {
+	c.page = nil
+	c.screen.StopCalibration()
+}
+
+// touch reports activity
+func (c *Calibrator) touch() {
+	if c.activity != nil {
+		c.activity()
+	}
+}
@@ -0,0 +1,156 @@
+package calibration
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/renderer"
+)
+
+// fakeScreen records the page shown
+type fakeScreen struct {
+	page *renderer.CalibrationPage
+}
+
+func (s *fakeScreen) ShowCalibration(page *renderer.CalibrationPage) { s.page = page }
+func (s *fakeScreen) StopCalibration()                               { s.page = nil }
+
+func newCalibrator(t *testing.T) (*Calibrator, *fakeScreen, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"display": {"type": "ssd1306"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	disp := display.NewCalibratedDisplay(display.NewMockDisplay(128, 64), display.NoCalibration)
+	screen := &fakeScreen{}
+	return New(disp, screen, path, logger.NewDefault()), screen, path
+}
+
+func TestCalibratorSave(t *testing.T) {
+	c, screen, path := newCalibrator(t)
+	if err := c.Adjust(100, 1.5); !errors.Is(err, ErrNotActive) {
+		t.Errorf("Adjust() before Start = %v, want ErrNotActive", err)
+	}
+
+	activity := 0
+	c.SetActivityFunc(func() { activity++ })
+	c.Start()
+	if !c.Active() || screen.page == nil {
+		t.Fatal("expected the calibration page to be shown")
+	}
+	for _, bad := range [][2]float64{{0, 1}, {256, 1}, {100, 0.2}, {100, 3.5}} {
+		if err := c.Adjust(int(bad[0]), bad[1]); err == nil {
+			t.Errorf("Adjust(%v, %v) succeeded, want an error", bad[0], bad[1])
+		}
+	}
+	if err := c.Adjust(100, 1.5); err != nil {
+		t.Fatalf("Adjust() failed: %v", err)
+	}
+	if contrast, gamma := c.Values(); contrast != 100 || gamma != 1.5 {
+		t.Errorf("Values() = %d, %g, want 100, 1.5", contrast, gamma)
+	}
+	if activity != 2 {
+		t.Errorf("activity reported %d times, want 2", activity)
+	}
+
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if c.Active() || screen.page != nil {
+		t.Error("expected the session to end once saved")
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Display.Contrast != 100 || cfg.Display.Gamma != 1.5 {
+		t.Errorf("saved %d and %g, want 100 and 1.5", cfg.Display.Contrast, cfg.Display.Gamma)
+	}
+}
+
+func TestCalibratorCancel(t *testing.T) {
+	c, _, _ := newCalibrator(t)
+	c.Start()
+	if err := c.Adjust(50, 2); err != nil {
+		t.Fatalf("Adjust() failed: %v", err)
+	}
+	if err := c.Cancel(); err != nil {
+		t.Fatalf("Cancel() failed: %v", err)
+	}
+	if contrast, gamma := c.Values(); contrast != 255 || gamma != 1 {
+		t.Errorf("Values() after Cancel = %d, %g, want the originals", contrast, gamma)
+	}
+	if err := c.Cancel(); !errors.Is(err, ErrNotActive) {
+		t.Errorf("second Cancel() = %v, want ErrNotActive", err)
+	}
+}
+
+func TestCalibratorButtons(t *testing.T) {
+	c, _, path := newCalibrator(t)
+	c.Start()
+
+	// Contrast wraps from full round to the first step
+	if err := c.Step(); err != nil {
+		t.Fatalf("Step() failed: %v", err)
+	}
+	if contrast, _ := c.Values(); contrast != contrastStep {
+		t.Errorf("contrast after a step from 255 = %d, want %d", contrast, contrastStep)
+	}
+
+	if err := c.Next(); err != nil {
+		t.Fatalf("Next() failed: %v", err)
+	}
+	for range 3 {
+		if err := c.Step(); err != nil {
+			t.Fatalf("Step() failed: %v", err)
+		}
+	}
+	if contrast, gamma := c.Values(); contrast != contrastStep || gamma != 1.3 {
+		t.Errorf("Values() = %d, %g, want %d, 1.3", contrast, gamma, contrastStep)
+	}
+
+	// Next after the last setting saves
+	if err := c.Next(); err != nil {
+		t.Fatalf("Next() failed: %v", err)
+	}
+	if c.Active() {
+		t.Error("expected the session to end after the last setting")
+	}
+	if cfg, err := config.Load(path); err != nil || cfg.Display.Gamma != 1.3 {
+		t.Errorf("expected gamma 1.3 saved, got %v", err)
+	}
+}
+
+func TestCalibratorGammaWraps(t *testing.T) {
+	c, _, _ := newCalibrator(t)
+	c.Start()
+	if err := c.Adjust(255, config.MaxGamma); err != nil {
+		t.Fatalf("Adjust() failed: %v", err)
+	}
+	if err := c.Next(); err != nil {
+		t.Fatalf("Next() failed: %v", err)
+	}
+	if err := c.Step(); err != nil {
+		t.Fatalf("Step() failed: %v", err)
+	}
+	if _, gamma := c.Values(); gamma != config.MinGamma {
+		t.Errorf("gamma after a step from the maximum = %g, want %g", gamma, config.MinGamma)
+	}
+}
+
+func TestCalibratorSaveWithoutFile(t *testing.T) {
+	disp := display.NewCalibratedDisplay(display.NewMockDisplay(128, 64), display.NoCalibration)
+	c := New(disp, &fakeScreen{}, "", logger.NewDefault())
+	c.Start()
+	if err := c.Save(); err == nil {
+		t.Error("expected an error with no configuration file")
+	}
+	if !c.Active() {
+		t.Error("expected the session to carry on after a failed save")
+	}
+}
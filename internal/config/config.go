@@ -3,22 +3,57 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/ausil/i2c-display/internal/i18n"
+	"github.com/ausil/i2c-display/internal/jsonpath"
+	"github.com/ausil/i2c-display/internal/pagetemplate"
+	"github.com/ausil/i2c-display/internal/qrcode"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Display     DisplayConfig     `json:"display"`
-	Pages       PagesConfig       `json:"pages"`
-	SystemInfo  SystemInfoConfig  `json:"system_info"`
-	Network     NetworkConfig     `json:"network"`
-	Logging     LoggingConfig     `json:"logging"`
-	Metrics     MetricsConfig     `json:"metrics"`
-	ScreenSaver ScreenSaverConfig `json:"screensaver"`
+	Display      DisplayConfig      `json:"display"`
+	Pages        PagesConfig        `json:"pages"`
+	SystemInfo   SystemInfoConfig   `json:"system_info"`
+	Network      NetworkConfig      `json:"network"`
+	Connectivity ConnectivityConfig `json:"connectivity"`
+	Latency      LatencyConfig      `json:"latency"`
+	Updates      UpdatesConfig      `json:"updates"`
+	TimeSync     TimeSyncConfig     `json:"time_sync"`
+	Logging      LoggingConfig      `json:"logging"`
+	Metrics      MetricsConfig      `json:"metrics"`
+	ScreenSaver  ScreenSaverConfig  `json:"screensaver"`
+	Alerts       AlertsConfig       `json:"alerts"`
+	Thresholds   ThresholdsConfig   `json:"thresholds"`
+	History      HistoryConfig      `json:"history"`
+	Backlight    BacklightConfig    `json:"backlight"`
+	Thermal      ThermalConfig      `json:"thermal_shutdown"`
+	Fan          FanConfig          `json:"fan"`
+	AutoBright   AutoBrightConfig   `json:"auto_brightness"`
+	Transitions  TransitionsConfig  `json:"transitions"`
+	Buttons      ButtonsConfig      `json:"buttons"`
+	NightMode    NightModeConfig    `json:"night_mode"`
+	Control      ControlConfig      `json:"control"`
+	MQTT         MQTTConfig         `json:"mqtt"`
+
+	// Strict rejects keys no setting reads, such as a misspelt
+	// "rotaton_interval", instead of ignoring them
+	Strict bool `json:"strict,omitempty"`
+
+	// sources maps the paths of settings not left at their defaults to
+	// where their values came from; see Settings
+	sources map[string]string
+
+	// path is the file the configuration was loaded from, empty for defaults
+	path string
 }
 
 // DisplayConfig holds display-related settings
@@ -29,39 +64,405 @@ type DisplayConfig struct {
 	SPIBus     string `json:"spi_bus"`
 	DCPin      string `json:"dc_pin"`
 	RSTPin     string `json:"rst_pin"`
+	BLPin      string `json:"bl_pin"`             // optional backlight pin for SPI TFTs, driven with PWM
+	BusyPin    string `json:"busy_pin,omitempty"` // BUSY pin of SPI e-paper panels
 	Width      int    `json:"width"`
 	Height     int    `json:"height"`
 	Rotation   int    `json:"rotation"`
 	Lines      int    `json:"lines"` // Content lines on small displays: 0=auto, 2=header+1 line (default), 4=compact 4-line no header
+	// MirrorX and MirrorY flip the picture left to right and top to bottom, for panels viewed through a mirror or mounted reversed
+	MirrorX bool `json:"mirror_x,omitempty"`
+	MirrorY bool `json:"mirror_y,omitempty"`
+	// Contrast scales every brightness level sent to the panel, 1-255 (0 = 255, unscaled), to even out panel-to-panel differences
+	Contrast int `json:"contrast,omitempty"`
+	// Gamma raises colour channels to this power before they reach the panel, between MinGamma and MaxGamma (0 = 1, uncorrected)
+	Gamma float64 `json:"gamma,omitempty"`
+	// DoubleBuffer draws pages into a back buffer and flushes frames to the panel from a background goroutine
+	DoubleBuffer bool `json:"double_buffer,omitempty"`
+	// RefreshUnchanged flushes every frame, even when identical to the last one
+	RefreshUnchanged bool `json:"refresh_unchanged,omitempty"`
+	// SPISpeedHz is the SPI clock for TFT panels (0 = the driver's default)
+	SPISpeedHz int `json:"spi_speed_hz,omitempty"`
+	// I2CSpeedHz is the I2C clock (0 = keep the bus's speed); only some hosts, such as the Raspberry Pi, can change it
+	I2CSpeedHz int `json:"i2c_speed_hz,omitempty"`
+	// I2CAddresses holds the candidates when i2c_address is a list, tried in order; I2CAddress is the first
+	I2CAddresses []string `json:"-"`
+	// Variant is the ST7735 module's tab colour: "greentab", "redtab" or "blacktab" (default)
+	Variant string `json:"variant,omitempty"`
+	// InvertColors turns on colour inversion for ST7735 panels that show a negative image
+	InvertColors bool `json:"invert_colors,omitempty"`
+	// BGROrder swaps red and blue on ST7735 panels with BGR subpixels
+	BGROrder bool `json:"bgr_order,omitempty"`
+	// GammaPositive and GammaNegative replace the ST7735 gamma tables (16 values of 0-63 each)
+	GammaPositive []int `json:"gamma_positive,omitempty"`
+	GammaNegative []int `json:"gamma_negative,omitempty"`
+	// ReinitAfterErrors re-creates the display after this many consecutive failed refreshes (0 = disabled)
+	ReinitAfterErrors int `json:"reinit_after_errors"`
+	// StallRefreshes re-initializes the display and marks it unhealthy when no frame has been flushed for this many refresh intervals (0 = disabled)
+	StallRefreshes int `json:"stall_refreshes"`
+	// FaultInjectionRate makes hardware operations fail with this probability (0-1) for testing recovery logic
+	FaultInjectionRate float64 `json:"fault_injection_rate,omitempty"`
+	// WindowScale magnifies each pixel of window preview types (0 = default of 4)
+	WindowScale int `json:"window_scale,omitempty"`
+	// FBDevice is the framebuffer device for the fbdev type (default /dev/fb0)
+	FBDevice string `json:"fb_device,omitempty"`
+	// Font draws the characters the built-in ASCII fonts lack: "go" for the
+	// embedded Go Mono font (Latin, Greek, Cyrillic) or the path of a
+	// TrueType/OpenType font, e.g. a Noto CJK font. Empty draws them as boxes.
+	Font string `json:"font,omitempty"`
+}
+
+// Limits of display.gamma
+const (
+	MinGamma = 0.5
+	MaxGamma = 3.0
+)
+
+// FontGo is the display.font value selecting the embedded Go Mono font
+const FontGo = "go"
+
+// Limits for resizable display types
+const (
+	maxResizableSize = 1024
+	maxWindowScale   = 16
+)
+
+// maxSPISpeedHz is the fastest SPI clock accepted; Raspberry Pi SPI tops out
+// at 125 MHz and panels well below that
+const maxSPISpeedHz = 125_000_000
+
+// ST7735 variants accepted in display.variant
+var validST7735Variants = []string{"greentab", "redtab", "blacktab"}
+
+// st7735GammaLen is the number of values in each ST7735 gamma table; each is
+// at most st7735GammaMax
+const (
+	st7735GammaLen = 16
+	st7735GammaMax = 63
+)
+
+// I2C clock limits: the SMBus minimum and high-speed mode
+const (
+	minI2CSpeedHz = 10_000
+	maxI2CSpeedHz = 3_400_000
+)
+
+// UnmarshalJSON accepts i2c_address as a single address or as a list of
+// addresses to try in order, e.g. ["0x3C", "0x3D"] for panel batches
+// strapped differently.
+func (c *DisplayConfig) UnmarshalJSON(data []byte) error {
+	type plain DisplayConfig
+	aux := struct {
+		*plain
+		I2CAddress json.RawMessage `json:"i2c_address"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.I2CAddress == nil {
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(aux.I2CAddress, &c.I2CAddress); err == nil {
+		c.I2CAddresses = nil
+		return nil
+	}
+	if err := json.Unmarshal(aux.I2CAddress, &list); err != nil {
+		return fmt.Errorf("display.i2c_address must be an address or a list of addresses: %w", err)
+	}
+	c.I2CAddress, c.I2CAddresses = "", nil
+	if len(list) > 0 {
+		c.I2CAddress = list[0]
+	}
+	if len(list) > 1 {
+		c.I2CAddresses = list
+	}
+	return nil
+}
+
+// AddressCandidates returns the I2C addresses to try, in order
+func (c *DisplayConfig) AddressCandidates() []string {
+	if len(c.I2CAddresses) > 0 {
+		return c.I2CAddresses
+	}
+	return []string{c.I2CAddress}
 }
 
 // IsI2C returns true if this display connects via I2C
 func (c *DisplayConfig) IsI2C() bool {
 	t := strings.ToLower(c.Type)
-	return strings.HasPrefix(t, "ssd1306") ||
+	return t == DisplayTypeAuto ||
+		strings.HasPrefix(t, "ssd1306") ||
+		(strings.HasPrefix(t, "ssd1309") && !strings.HasPrefix(t, "ssd1309_spi")) ||
+		strings.HasPrefix(t, "ssd1305") ||
 		strings.HasPrefix(t, "sh1106") ||
+		strings.HasPrefix(t, "sh1107") ||
 		strings.HasPrefix(t, "ssd1327") ||
 		strings.HasPrefix(t, "ssd1331") ||
-		strings.HasPrefix(t, "uctronics")
+		strings.HasPrefix(t, "uctronics") ||
+		strings.HasPrefix(t, "hd44780")
 }
 
 // IsSPI returns true if this display connects via SPI
 func (c *DisplayConfig) IsSPI() bool {
-	return strings.HasPrefix(strings.ToLower(c.Type), "st7735")
+	t := strings.ToLower(c.Type)
+	return strings.HasPrefix(t, "st7735") || strings.HasPrefix(t, "st7789") || strings.HasPrefix(t, "ili9341") ||
+		strings.HasPrefix(t, "ssd1309_spi") || c.IsEPaper()
+}
+
+// IsEPaper returns true for e-paper displays, which connect via SPI and
+// refresh slowly
+func (c *DisplayConfig) IsEPaper() bool {
+	return strings.HasPrefix(strings.ToLower(c.Type), "ssd1680")
+}
+
+// IsTerminal returns true if this display is drawn in the console instead
+// of on hardware
+func (c *DisplayConfig) IsTerminal() bool {
+	return strings.HasPrefix(strings.ToLower(c.Type), "terminal")
+}
+
+// IsWindow returns true if this display is drawn in a desktop window
+// instead of on hardware
+func (c *DisplayConfig) IsWindow() bool {
+	return strings.HasPrefix(strings.ToLower(c.Type), "window")
+}
+
+// IsPreview returns true for simulated displays (terminal or window), which
+// have no hardware
+func (c *DisplayConfig) IsPreview() bool {
+	return c.IsTerminal() || c.IsWindow()
+}
+
+// IsFramebuffer returns true if this display is a Linux framebuffer device
+func (c *DisplayConfig) IsFramebuffer() bool {
+	return strings.EqualFold(c.Type, DisplayTypeFBDev)
+}
+
+// IsResizable returns true for display types whose width and height are
+// chosen in the configuration rather than fixed by the panel
+func (c *DisplayConfig) IsResizable() bool {
+	return c.IsPreview() || c.IsFramebuffer()
 }
 
 // PagesConfig holds page rotation settings
 type PagesConfig struct {
 	RotationInterval string `json:"rotation_interval"`
 	RefreshInterval  string `json:"refresh_interval"`
+	// RefreshIntervals overrides how often individual data sources are
+	// re-collected and their page elements redrawn, keyed by source name.
+	// Sources not listed follow RefreshInterval.
+	RefreshIntervals map[string]string `json:"refresh_intervals,omitempty"`
+	// CollectTimeout bounds each read of a data source, so a hung read such
+	// as statfs on a dead NFS mount fails instead of stalling; default 5s
+	CollectTimeout string `json:"collect_timeout,omitempty"`
+	// Durations overrides how long each page type stays on screen, keyed by
+	// page type. Types not listed use RotationInterval.
+	Durations map[string]string `json:"durations,omitempty"`
+	// Disabled lists page types left out of the rotation
+	Disabled []string `json:"disabled,omitempty"`
+	// Marquee scrolls network lines and hostnames too wide for the display
+	// a few pixels per refresh instead of truncating them with "..."
+	Marquee bool `json:"marquee,omitempty"`
+	// Locale translates page labels and writes decimal numbers for a
+	// language, e.g. "de"; empty is English
+	Locale string `json:"locale,omitempty"`
+	// TemperatureUnits overrides the unit temperatures are shown in, keyed
+	// by page type. Types not listed use system_info.temperature_unit.
+	TemperatureUnits map[string]string `json:"temperature_units,omitempty"`
+	// ByteUnits is how sizes are written: ByteUnitsIEC, in powers of 1024
+	// such as GiB, or ByteUnitsSI, in powers of 1000 such as GB; empty is IEC
+	ByteUnits string `json:"byte_units,omitempty"`
+	// Exec adds pages showing the output of external commands
+	Exec []ExecPageConfig `json:"exec,omitempty"`
+	// HTTPJSON adds pages showing values read from JSON HTTP endpoints
+	HTTPJSON []HTTPJSONPageConfig `json:"http_json,omitempty"`
+	// Templates adds pages laid out with Go templates over the stats
+	Templates []TemplatePageConfig `json:"templates,omitempty"`
+	// QR adds a page showing a QR code of a URL, e.g. to reach the device
+	QR QRPageConfig `json:"qr"`
+	// Load sets how the load average page shows the load
+	Load LoadPageConfig `json:"load"`
+	// Top adds pages listing the busiest processes
+	Top TopPageConfig `json:"top"`
+	// Storage adds a page estimating SD card and eMMC wear
+	Storage StoragePageConfig `json:"storage"`
+	// FirstBoot puts a page with the device's address first in the rotation
+	// while it has only just booted
+	FirstBoot FirstBootPageConfig `json:"first_boot"`
+	// PluginDir holds Starlark page scripts (*.star); empty disables them
+	PluginDir string `json:"plugin_dir"`
+}
+
+// ExecPageConfig describes a page showing the stdout of a command, one line
+// per content row. The command is run directly, not through a shell.
+type ExecPageConfig struct {
+	Title    string   `json:"title"`    // page header; must be unique
+	Command  []string `json:"command"`  // program and arguments, e.g. ["fail2ban-client", "status"]
+	Interval string   `json:"interval"` // how often the command is run, e.g. "1m"
+	Timeout  string   `json:"timeout"`  // maximum run time; default 10s
+}
+
+// HTTPJSONPageConfig describes a page showing values extracted from the JSON
+// response of an HTTP endpoint, e.g. Pi-hole or OctoPrint, one field per
+// content row
+type HTTPJSONPageConfig struct {
+	Title    string            `json:"title"`             // page header; must be unique
+	URL      string            `json:"url"`               // http or https URL fetched with GET
+	Headers  map[string]string `json:"headers,omitempty"` // request headers, e.g. an API key
+	Interval string            `json:"interval"`          // how often the URL is fetched, e.g. "30s"
+	Timeout  string            `json:"timeout"`           // maximum request time; default 10s
+	Fields   []HTTPJSONField   `json:"fields"`
+}
+
+// HTTPJSONField is a value extracted from an http_json response and the row
+// it is shown on
+type HTTPJSONField struct {
+	// Path selects the value, e.g. "$.queries.blocked" or "jobs[0].state"
+	Path string `json:"path"`
+	// Label is the row text: "{value}" is replaced with the value and
+	// "{value:%.1f}" formats it with a printf verb. Default "{value}".
+	Label string `json:"label,omitempty"`
+}
+
+// TemplatePageConfig describes a page whose rows are Go text/templates
+// executed against the collected stats, e.g.
+// "{{.Hostname}} {{printf \"%.0f\" .CPUTemp}}C"
+type TemplatePageConfig struct {
+	Title string         `json:"title"` // page header; must be unique
+	Lines []TemplateLine `json:"lines"` // one per content row
+}
+
+// TemplateLine is a row of a template page and how it is coloured
+type TemplateLine struct {
+	Text  string `json:"text"`            // text/template over the stats
+	Color string `json:"color,omitempty"` // a name or #rrggbb; default white
+	// Colors override Color: the first rule whose When template renders
+	// "true" gives the row its colour
+	Colors []TemplateColorRule `json:"colors,omitempty"`
+}
+
+// TemplateColorRule colours a template line when its condition holds
+type TemplateColorRule struct {
+	When  string `json:"when"`  // text/template, e.g. "{{gt .CPUTemp 70.0}}"
+	Color string `json:"color"` // a name or #rrggbb
+}
+
+// QRPageConfig describes a page showing a QR code, e.g. of the device's web
+// interface or SSH address, for scanning with a phone
+type QRPageConfig struct {
+	// URL is encoded after replacing {hostname}, {ipv4} and {ipv6} with the
+	// device's; empty disables the page
+	URL   string `json:"url,omitempty"`
+	Title string `json:"title,omitempty"` // shown beside the code; default "Scan to connect"
+}
+
+// LoadPageConfig describes the load average page
+type LoadPageConfig struct {
+	// PerCore shows the load averages divided by the number of CPUs, so 1.00
+	// is every core busy on any board
+	PerCore bool `json:"per_core,omitempty"`
+}
+
+// TopPageConfig describes the pages listing the processes using the most CPU
+// and the most memory
+type TopPageConfig struct {
+	Count int `json:"count,omitempty"` // processes listed per page; 0 disables the pages
+}
+
+// StoragePageConfig describes the page showing the flash storage's wear, from
+// the life time estimates eMMC devices report, and the identity of SD cards,
+// which report none
+type StoragePageConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// Percent of the rated life used at which the wear is shown yellow and
+	// red; defaults 70 and 90
+	Warn     int `json:"warn,omitempty"`
+	Critical int `json:"critical,omitempty"`
+}
+
+// FirstBootPageConfig describes the provisioning page shown on a freshly
+// booted device: host name, primary IP in large type, and mDNS name
+type FirstBootPageConfig struct {
+	// UntilUptime is how long after boot the page leads the rotation, e.g.
+	// "15m"; empty disables the page
+	UntilUptime string `json:"until_uptime,omitempty"`
+}
+
+// QR URL placeholders and the longest value each can take
+var qrPlaceholders = map[string]int{
+	"{hostname}": 63, // one DNS label; longer names are reported on the page
+	"{ipv4}":     len("255.255.255.255"),
+	"{ipv6}":     len("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"),
+}
+
+// Page types that can be given their own duration in pages.durations or
+// listed in pages.disabled. Small displays split the system page into
+// separate disk, memory and CPU pages; they all count as "system".
+const (
+	PageSystem        = "system"
+	PageTemperatures  = "temperatures"
+	PagePower         = "power" // shown on Raspberry Pis, which report throttling
+	PageLoad          = "load"
+	PageNetwork       = "network"
+	PageNetworkDetail = "network_detail" // the pages enabled by network.detail_page
+	PageConnectivity  = "connectivity"   // the page enabled by connectivity.enabled
+	PageLatency       = "latency"        // the page enabled by latency.enabled
+	PageExec          = "exec"           // all pages configured in pages.exec
+	PageHTTPJSON      = "http_json"      // all pages configured in pages.http_json
+	PageTemplate      = "template"       // all pages configured in pages.templates
+	PageQR            = "qr"             // the page configured in pages.qr
+	PageFirstBoot     = "first_boot"     // the page configured in pages.first_boot
+	PageTop           = "top"            // the pages enabled by pages.top
+	PageStorage       = "storage"        // the page enabled by pages.storage
+	PagePlugin        = "plugin"         // all scripts loaded from pages.plugin_dir
+	PageCustom        = "custom"         // all pages registered by programs embedding the renderer
+)
+
+// maxTopCount caps pages.top.count; no display shows more rows
+const maxTopCount = 20
+
+// PageTypes lists the valid page types
+var PageTypes = []string{PageSystem, PageTemperatures, PagePower, PageLoad, PageNetwork, PageNetworkDetail, PageConnectivity, PageLatency, PageExec, PageHTTPJSON, PageTemplate, PageQR, PageFirstBoot, PageTop, PageStorage, PagePlugin, PageCustom}
+
+// Data sources that can be given their own refresh cadence in
+// pages.refresh_intervals
+const (
+	SourceTemperature = "temperature"
+	SourceMemory      = "memory"
+	SourceDisk        = "disk"
+	SourceLoad        = "load"
+	SourceNetwork     = "network"
+	SourceProcesses   = "processes" // the /proc scan for pages.top
+	SourceStorage     = "storage"   // the flash wear estimates for pages.storage
+)
+
+// RefreshSources lists the valid keys of pages.refresh_intervals
+var RefreshSources = []string{SourceTemperature, SourceMemory, SourceDisk, SourceLoad, SourceNetwork, SourceProcesses, SourceStorage}
+
+// TransitionsConfig holds animated page transition settings
+type TransitionsConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Type     string `json:"type"`     // "slide-left", "fade", or "wipe"
+	Duration string `json:"duration"` // how long a transition takes, e.g. "300ms"
 }
 
 // SystemInfoConfig holds system information settings
 type SystemInfoConfig struct {
 	HostnameDisplay   string `json:"hostname_display"`
 	DiskPath          string `json:"disk_path"`
-	TemperatureSource string `json:"temperature_source"`
-	TemperatureUnit   string `json:"temperature_unit"`
+	TemperatureSource string `json:"temperature_source"` // sysfs path, "vcgencmd", or "hwmon:<label>"
+	TemperatureUnit   string `json:"temperature_unit"`   // one of TemperatureUnits
+
+	// TemperatureSensors lists additional named sensors shown on the temperatures page
+	TemperatureSensors []TemperatureSensorConfig `json:"temperature_sensors,omitempty"`
+}
+
+// TemperatureSensorConfig describes a named temperature sensor
+type TemperatureSensorConfig struct {
+	Name   string `json:"name"`   // short label, e.g. "GPU"
+	Source string `json:"source"` // sysfs path, "vcgencmd", or "hwmon:<label>"
 }
 
 // NetworkConfig holds network interface settings
@@ -71,6 +472,110 @@ type NetworkConfig struct {
 	ShowIPv4             bool            `json:"show_ipv4"`
 	ShowIPv6             bool            `json:"show_ipv6"`
 	MaxInterfacesPerPage int             `json:"max_interfaces_per_page"`
+	// DetailPage adds a page per interface with its gateway, name servers
+	// and whether it is addressed by DHCP
+	DetailPage bool `json:"detail_page,omitempty"`
+	// IPv6Format abbreviates IPv6 addresses on the network pages; one of
+	// IPv6Formats
+	IPv6Format string `json:"ipv6_format"`
+	// IPv6Labels marks IPv6 addresses on the network pages as global
+	// ("GUA"), unique local ("ULA") or link-local ("LL")
+	IPv6Labels bool `json:"ipv6_labels,omitempty"`
+	// IPv6LinkLocal lists link-local (fe80::/10) addresses, which are
+	// otherwise skipped
+	IPv6LinkLocal bool `json:"ipv6_link_local,omitempty"`
+	// IPv6Zones keeps the zone, e.g. "%eth0", on link-local addresses
+	IPv6Zones bool `json:"ipv6_zones,omitempty"`
+	// PreferGUA orders global IPv6 addresses before unique local and
+	// link-local ones, so lines showing a single address show a global one
+	PreferGUA bool `json:"prefer_gua,omitempty"`
+}
+
+// Temperature units for system_info.temperature_unit and
+// pages.temperature_units
+const (
+	UnitCelsius    = "celsius"
+	UnitFahrenheit = "fahrenheit"
+	UnitKelvin     = "kelvin"
+)
+
+// TemperatureUnits lists the valid temperature units
+var TemperatureUnits = []string{UnitCelsius, UnitFahrenheit, UnitKelvin}
+
+// TemperaturePageTypes lists the page types showing temperatures, whose unit
+// pages.temperature_units can override
+var TemperaturePageTypes = []string{PageSystem, PageTemperatures}
+
+// Byte unit systems for pages.byte_units
+const (
+	ByteUnitsIEC = "iec" // KiB, MiB, GiB: powers of 1024
+	ByteUnitsSI  = "si"  // kB, MB, GB: powers of 1000
+)
+
+// ByteUnits lists the valid values of pages.byte_units
+var ByteUnits = []string{ByteUnitsIEC, ByteUnitsSI}
+
+// IPv6 address formats for network.ipv6_format
+const (
+	IPv6FormatFull   = "full"   // the whole address
+	IPv6FormatPrefix = "prefix" // the /64 network, e.g. "2001:db8:1::/64"
+	IPv6FormatSuffix = "suffix" // the interface identifier, e.g. "::8a2e:370:7334"
+	IPv6FormatShort  = "short"  // the first two groups and the last, e.g. "2001:db8..7334"
+)
+
+// IPv6Formats lists the valid values of network.ipv6_format
+var IPv6Formats = []string{IPv6FormatFull, IPv6FormatPrefix, IPv6FormatSuffix, IPv6FormatShort}
+
+// ConnectivityConfig holds the internet reachability check and the optional
+// public IP lookup
+type ConnectivityConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Target   string `json:"target"`   // http(s) URL sent a HEAD request, or host:port connected to over TCP
+	Interval string `json:"interval"` // time between checks, e.g. "30s"
+	Timeout  string `json:"timeout"`  // maximum time for a check, e.g. "5s"
+	// PublicIP allows the public address to be looked up from
+	// PublicIPURL, a third-party service that then learns the address
+	PublicIP         bool   `json:"public_ip"`
+	PublicIPURL      string `json:"public_ip_url"`      // returns the caller's address as plain text
+	PublicIPInterval string `json:"public_ip_interval"` // how long a looked-up address is cached, e.g. "1h"
+}
+
+// LatencyConfig holds the ping latency monitor settings
+type LatencyConfig struct {
+	Enabled  bool            `json:"enabled"`
+	Hosts    []LatencyTarget `json:"hosts"`    // pinged in parallel, one row each
+	Interval string          `json:"interval"` // time between pings, e.g. "10s"
+	Timeout  string          `json:"timeout"`  // how long to wait for a reply, e.g. "2s"
+	Warn     string          `json:"warn"`     // round trip time shown in yellow from, e.g. "50ms"
+	Critical string          `json:"critical"` // round trip time shown in red from, e.g. "200ms"
+}
+
+// LatencyTarget is a host pinged by the latency monitor
+type LatencyTarget struct {
+	Name string `json:"name"` // label on the page; default the host
+	Host string `json:"host"` // host name or address
+}
+
+// UpdatesConfig holds the pending package update check shown in the system
+// page footer
+type UpdatesConfig struct {
+	Enabled bool `json:"enabled"`
+	// Manager is the package manager asked for updates: "auto" to detect
+	// apt or dnf, "apt", "dnf", or "none" to only report a pending reboot
+	Manager  string `json:"manager"`
+	Interval string `json:"interval"` // time between checks, e.g. "6h"
+	Timeout  string `json:"timeout"`  // maximum time for a check, e.g. "2m"
+}
+
+// Package managers understood by the update check
+var UpdateManagers = []string{"auto", "apt", "dnf", "none"}
+
+// TimeSyncConfig holds the clock synchronisation check shown on the clock
+// page
+type TimeSyncConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Interval string `json:"interval"` // time between checks, e.g. "1m"
+	Timeout  string `json:"timeout"`  // maximum time for a check, e.g. "5s"
 }
 
 // InterfaceFilter defines include/exclude patterns for network interfaces
@@ -81,15 +586,95 @@ type InterfaceFilter struct {
 
 // LoggingConfig holds logging settings
 type LoggingConfig struct {
-	Level  string `json:"level"`
-	Output string `json:"output"`
-	JSON   bool   `json:"json"` // true for JSON output, false for console
+	Level             string `json:"level"`
+	Output            string `json:"output"`
+	JSON              bool   `json:"json"`                // true for JSON output, false for console
+	RenderReports     bool   `json:"render_reports"`      // log a debug record per rendered frame
+	RenderReportEvery int    `json:"render_report_every"` // log one frame in this many
+	// CaptureFrames keeps this many of the last frames shown in memory and
+	// writes them as PNGs to CrashDir when the display or rotation loop
+	// becomes unhealthy (0 = off)
+	CaptureFrames int    `json:"capture_frames,omitempty"`
+	CrashDir      string `json:"crash_dir,omitempty"`
 }
 
+// maxCaptureFrames bounds logging.capture_frames; a 320x240 colour frame
+// takes 150 KB
+const maxCaptureFrames = 600
+
 // MetricsConfig holds Prometheus metrics settings
 type MetricsConfig struct {
 	Enabled bool   `json:"enabled"`
-	Address string `json:"address"` // e.g., "127.0.0.1:9090"
+	Address string `json:"address"` // e.g., "127.0.0.1:9090", or "unix:/run/i2c-display/http.sock"
+	// SocketGroup is given access to a Unix socket address; empty keeps the
+	// daemon's group
+	SocketGroup string `json:"socket_group,omitempty"`
+	// TLS serves the metrics and control endpoints over HTTPS
+	TLS MetricsTLSConfig `json:"tls"`
+	// Auth requires credentials for every endpoint but /health
+	Auth MetricsAuthConfig `json:"auth"`
+	// Push sends the metrics to a collector, with or without the server
+	Push MetricsPushConfig `json:"push"`
+}
+
+// MetricsTLSConfig holds the metrics server's certificate. With a client
+// CA, clients must present a certificate it signed (mutual TLS).
+type MetricsTLSConfig struct {
+	CertFile     string `json:"cert_file,omitempty"`
+	KeyFile      string `json:"key_file,omitempty"`
+	ClientCAFile string `json:"client_ca_file,omitempty"`
+}
+
+// MetricsAuthConfig holds the credentials the metrics server requires:
+// basic auth, a bearer token, or either when both are set
+type MetricsAuthConfig struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// IsSet reports whether the metrics server requires credentials
+func (a MetricsAuthConfig) IsSet() bool {
+	return a.Username != "" || a.Token != ""
+}
+
+// MetricsPushConfig describes pushing the metrics served at /metrics to a
+// collector, for deployments without a scraping Prometheus
+type MetricsPushConfig struct {
+	Protocol string `json:"protocol,omitempty"` // one of MetricsPushProtocols; empty disables pushing
+	// URL receives remote_write and influx pushes, e.g.
+	// "http://prometheus:9090/api/v1/write" or
+	// "http://influxdb:8086/api/v2/write?org=home&bucket=pi"
+	URL string `json:"url,omitempty"`
+	// Address receives statsd pushes over UDP, e.g. "127.0.0.1:8125"
+	Address  string `json:"address,omitempty"`
+	Interval string `json:"interval,omitempty"` // how often to push; default 15s
+	// Headers are sent with HTTP pushes, e.g. an Authorization token
+	Headers map[string]string `json:"headers,omitempty"`
+	// Labels are added to every metric, e.g. {"instance": "kitchen-pi"}
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Metrics push protocols
+const (
+	MetricsPushRemoteWrite = "remote_write" // Prometheus remote write
+	MetricsPushStatsd      = "statsd"       // statsd gauges with DogStatsD tags
+	MetricsPushInflux      = "influx"       // InfluxDB line protocol
+)
+
+// MetricsPushProtocols lists the valid metrics.push.protocol values
+var MetricsPushProtocols = []string{MetricsPushRemoteWrite, MetricsPushStatsd, MetricsPushInflux}
+
+// DefaultMetricsPushInterval is how often metrics are pushed when
+// metrics.push.interval is empty
+const DefaultMetricsPushInterval = 15 * time.Second
+
+// GetInterval returns how often metrics are pushed
+func (m *MetricsPushConfig) GetInterval() (time.Duration, error) {
+	if m.Interval == "" {
+		return DefaultMetricsPushInterval, nil
+	}
+	return time.ParseDuration(m.Interval)
 }
 
 // ActiveHoursConfig defines the time window during which the display is kept on.
@@ -103,12 +688,206 @@ type ActiveHoursConfig struct {
 // ScreenSaverConfig holds screen saver settings
 type ScreenSaverConfig struct {
 	Enabled          bool              `json:"enabled"`
-	Mode             string            `json:"mode"`              // "off", "dim", or "blank"
+	Mode             string            `json:"mode"`              // "off", "dim", "blank", "shift" or "clock"
 	IdleTimeout      string            `json:"idle_timeout"`      // e.g., "5m"
 	DimBrightness    uint8             `json:"dim_brightness"`    // 0-255
 	NormalBrightness uint8             `json:"normal_brightness"` // 0-255
 	ActiveHours      ActiveHoursConfig `json:"active_hours,omitempty"`
-	WakeDuration     string            `json:"wake_duration"` // how long a manual wake keeps the display on, e.g. "30s"
+	WakeDuration     string            `json:"wake_duration"`  // how long a manual wake keeps the display on, e.g. "30s"
+	ShiftPixels      int               `json:"shift_pixels"`   // maximum content offset in "shift" mode
+	ShiftInterval    string            `json:"shift_interval"` // how often content moves in "shift" mode, e.g. "1m"
+}
+
+// AutoBrightConfig holds ambient light sensor auto-brightness settings
+type AutoBrightConfig struct {
+	Enabled       bool    `json:"enabled"`
+	Sensor        string  `json:"sensor"`         // "bh1750", "tsl2561", or "veml7700"
+	I2CBus        string  `json:"i2c_bus"`        // defaults to display.i2c_bus
+	I2CAddress    string  `json:"i2c_address"`    // defaults to the sensor's factory address
+	Interval      string  `json:"interval"`       // sampling interval, e.g. "5s"
+	MinBrightness uint8   `json:"min_brightness"` // brightness in darkness (0-255)
+	MaxBrightness uint8   `json:"max_brightness"` // brightness in bright light (0-255)
+	MaxLux        float64 `json:"max_lux"`        // ambient lux mapped to max_brightness
+}
+
+// ButtonsConfig holds GPIO push button settings. Buttons are wired between
+// the pin and ground; the internal pull-up is enabled.
+type ButtonsConfig struct {
+	Enabled      bool   `json:"enabled"`
+	PausePin     string `json:"pause_pin"`     // toggles rotation pause, e.g. "GPIO17"
+	HoldPin      string `json:"hold_pin"`      // shows hold_page and holds it; press again to release
+	HoldPage     string `json:"hold_page"`     // page type shown by the hold button
+	HoldDuration string `json:"hold_duration"` // how long the hold lasts; empty holds until released
+	Debounce     string `json:"debounce"`      // presses closer together than this are ignored
+}
+
+// NightModeConfig holds the night schedule. Unlike the screensaver it is
+// driven purely by the time of day.
+type NightModeConfig struct {
+	Enabled    bool   `json:"enabled"`
+	Start      string `json:"start"`      // "HH:MM" (24-hour) when night begins
+	End        string `json:"end"`        // "HH:MM" (24-hour); may be earlier than Start for overnight ranges
+	Brightness uint8  `json:"brightness"` // brightness cap during the night (0-255)
+	ClockOnly  bool   `json:"clock_only"` // show only the clock during the night
+}
+
+// ControlConfig holds the local control socket used by i2c-displayctl
+type ControlConfig struct {
+	Enabled bool   `json:"enabled"`
+	Socket  string `json:"socket"` // Unix socket path
+	Group   string `json:"group"`  // group given access to the socket; empty keeps the daemon's group
+}
+
+// MQTTConfig holds the MQTT broker connection, used to make the display a
+// Home Assistant device
+type MQTTConfig struct {
+	Enabled         bool   `json:"enabled"`
+	Broker          string `json:"broker"`           // "tcp://host:1883" or "ssl://host:8883"
+	ClientID        string `json:"client_id"`        // empty = "i2c-display-<hostname>"
+	Username        string `json:"username"`         // optional
+	Password        string `json:"password"`         // optional
+	TopicPrefix     string `json:"topic_prefix"`     // empty = "i2c-display/<hostname>"
+	Discovery       bool   `json:"discovery"`        // publish Home Assistant discovery payloads
+	DiscoveryPrefix string `json:"discovery_prefix"` // Home Assistant's discovery topic prefix
+}
+
+// MQTTBrokerSchemes are the URL schemes accepted for mqtt.broker
+var MQTTBrokerSchemes = []string{"tcp", "mqtt", "ssl", "tls", "mqtts"}
+
+// BacklightConfig holds backlight on-time tracking and burn-out protection settings
+type BacklightConfig struct {
+	Enabled           bool   `json:"enabled"`
+	StatePath         string `json:"state_path"`          // where cumulative on-time is persisted
+	MaxDailyOn        string `json:"max_daily_on"`        // e.g. "16h"; empty = unlimited
+	AutoDimAfter      string `json:"auto_dim_after"`      // e.g. "4h" of continuous on-time; empty = disabled
+	AutoDimBrightness uint8  `json:"auto_dim_brightness"` // 0-255
+}
+
+// ThermalConfig holds the critical-temperature shutdown hook settings
+type ThermalConfig struct {
+	Enabled   bool     `json:"enabled"`
+	Threshold float64  `json:"threshold"` // critical CPU temperature in Celsius
+	Samples   int      `json:"samples"`   // consecutive samples above threshold before the countdown starts
+	Countdown string   `json:"countdown"` // how long the countdown page is shown before running the command, e.g. "30s"
+	Command   []string `json:"command"`   // argv executed once the countdown expires
+}
+
+// FanConfig holds temperature-driven fan control settings
+type FanConfig struct {
+	Enabled    bool       `json:"enabled"`
+	Pin        string     `json:"pin"`        // GPIO switching the fan, e.g. "GPIO18"; PWM-capable pins get variable speed
+	Curve      []FanPoint `json:"curve"`      // fan speed by CPU temperature, in ascending temperature order
+	Hysteresis float64    `json:"hysteresis"` // degrees Celsius the temperature must drop below a point before the fan slows
+}
+
+// FanPoint is a point on the fan curve. Between points the speed is
+// interpolated; below the first the fan is off.
+type FanPoint struct {
+	Temp float64 `json:"temp"` // CPU temperature in Celsius
+	Duty int     `json:"duty"` // fan speed in percent (0-100)
+}
+
+// ThresholdsConfig holds the levels at which the metrics on pages turn
+// yellow and red. Metrics left unset keep the defaults: 60% and 85% for
+// usage, 55°C and 75°C for temperature, 0.7 and 1.0 for load per CPU.
+type ThresholdsConfig struct {
+	Disk        LevelsConfig `json:"disk"`        // percent of space or inodes used
+	Memory      LevelsConfig `json:"memory"`      // percent used, also by a process on the top page
+	CPU         LevelsConfig `json:"cpu"`         // percent used by a process on the top page
+	Load        LevelsConfig `json:"load"`        // load average per CPU
+	Temperature LevelsConfig `json:"temperature"` // in Celsius
+}
+
+// LevelsConfig holds a metric's colour levels. Both are set, or neither to
+// keep the default.
+type LevelsConfig struct {
+	Warn float64 `json:"warn,omitempty"` // yellow at or above
+	Crit float64 `json:"crit,omitempty"` // red above
+}
+
+// IsSet reports whether the levels override the default
+func (l LevelsConfig) IsSet() bool {
+	return l.Warn != 0 || l.Crit != 0
+}
+
+// HistoryConfig describes the samples of load, temperature, memory and disk
+// use kept for the graph pages and the HTTP API
+type HistoryConfig struct {
+	// Retention is how far back samples are kept, e.g. "1h"; default 5m
+	Retention string `json:"retention,omitempty"`
+	// File keeps the samples across restarts, e.g.
+	// "/var/lib/i2c-display/history.bin"; empty starts each run afresh
+	File string `json:"file,omitempty"`
+	// SaveInterval is how often the samples are saved to File, besides on
+	// exit; default 5m
+	SaveInterval string `json:"save_interval,omitempty"`
+}
+
+// History defaults and limits
+const (
+	DefaultHistoryRetention    = 5 * time.Minute
+	DefaultHistorySaveInterval = 5 * time.Minute
+	// MaxHistorySamples caps the samples kept per metric, a day at the
+	// default 1s refresh
+	MaxHistorySamples = 86400
+)
+
+// GetRetention returns how far back history samples are kept
+func (h *HistoryConfig) GetRetention() (time.Duration, error) {
+	if h.Retention == "" {
+		return DefaultHistoryRetention, nil
+	}
+	return time.ParseDuration(h.Retention)
+}
+
+// GetSaveInterval returns how often the history is saved
+func (h *HistoryConfig) GetSaveInterval() (time.Duration, error) {
+	if h.SaveInterval == "" {
+		return DefaultHistorySaveInterval, nil
+	}
+	return time.ParseDuration(h.SaveInterval)
+}
+
+// HistorySamples returns how many samples of each metric cover the
+// retention at the refresh interval, at most MaxHistorySamples
+func (c *Config) HistorySamples() int {
+	retention, err := c.History.GetRetention()
+	if err != nil {
+		retention = DefaultHistoryRetention
+	}
+	refresh, err := c.Pages.GetRefreshInterval()
+	if err != nil || refresh <= 0 {
+		refresh = time.Second
+	}
+	n := (retention + refresh - 1) / refresh
+	if n >= MaxHistorySamples {
+		return MaxHistorySamples
+	}
+	return int(n) + 1
+}
+
+// AlertsConfig holds threshold alert settings
+type AlertsConfig struct {
+	Enabled bool              `json:"enabled"`
+	Rules   []AlertRuleConfig `json:"rules"`
+	Notify  AlertNotifyConfig `json:"notify"`
+}
+
+// AlertNotifyConfig configures external notifications on alert transitions.
+// Each transition is delivered as a JSON payload.
+type AlertNotifyConfig struct {
+	WebhookURL string   `json:"webhook_url"` // payload is POSTed here; empty = disabled
+	Command    []string `json:"command"`     // argv run with the payload on stdin; empty = disabled
+	Timeout    string   `json:"timeout"`     // per-notification timeout, e.g. "10s"
+}
+
+// AlertRuleConfig defines a single threshold alert rule
+type AlertRuleConfig struct {
+	Name      string  `json:"name"`      // short label shown on the alert page
+	Metric    string  `json:"metric"`    // "disk", "memory", "cpu_temp", or "load"
+	Operator  string  `json:"operator"`  // ">", ">=", "<", "<=" (default ">")
+	Threshold float64 `json:"threshold"` // value compared against the metric
+	Wake      bool    `json:"wake"`      // wake the screensaver when the alert fires
 }
 
 // GetRotationInterval returns the parsed rotation interval duration
@@ -121,26 +900,81 @@ func (p *PagesConfig) GetRefreshInterval() (time.Duration, error) {
 	return time.ParseDuration(p.RefreshInterval)
 }
 
+// GetSourceIntervals returns the parsed per-source refresh intervals. Sources
+// without an entry are absent from the map and refresh on every tick.
+func (p *PagesConfig) GetSourceIntervals() (map[string]time.Duration, error) {
+	intervals := make(map[string]time.Duration, len(p.RefreshIntervals))
+	for source, value := range p.RefreshIntervals {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pages.refresh_intervals.%s: %w", source, err)
+		}
+		intervals[source] = d
+	}
+	return intervals, nil
+}
+
+// GetPageDurations returns the parsed per-page-type durations. Page types
+// without an entry are absent from the map and use the rotation interval.
+func (p *PagesConfig) GetPageDurations() (map[string]time.Duration, error) {
+	durations := make(map[string]time.Duration, len(p.Durations))
+	for pageType, value := range p.Durations {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pages.durations.%s: %w", pageType, err)
+		}
+		durations[pageType] = d
+	}
+	return durations, nil
+}
+
+// GetFirstBootUptime returns the parsed pages.first_boot.until_uptime, or 0
+// when the first-boot page is disabled
+func (p *PagesConfig) GetFirstBootUptime() (time.Duration, error) {
+	if p.FirstBoot.UntilUptime == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(p.FirstBoot.UntilUptime)
+}
+
+// IsDisabled reports whether pageType is listed in pages.disabled
+func (p *PagesConfig) IsDisabled(pageType string) bool {
+	return slices.Contains(p.Disabled, pageType)
+}
+
 // Default returns a configuration with sensible defaults
 func Default() *Config {
 	cfg := &Config{
 		Display: DisplayConfig{
-			Type:       "ssd1306",
-			I2CBus:     "/dev/i2c-1",
-			I2CAddress: "0x3C",
-			Width:      0, // Will be set by ApplyDisplayDefaults based on type
-			Height:     0, // Will be set by ApplyDisplayDefaults based on type
-			Rotation:   0,
+			Type:              "ssd1306",
+			I2CBus:            "/dev/i2c-1",
+			I2CAddress:        "0x3C",
+			Width:             0, // Will be set by ApplyDisplayDefaults based on type
+			Height:            0, // Will be set by ApplyDisplayDefaults based on type
+			Rotation:          0,
+			ReinitAfterErrors: 3,
+			StallRefreshes:    10,
 		},
 		Pages: PagesConfig{
 			RotationInterval: "5s",
 			RefreshInterval:  "1s",
+			RefreshIntervals: map[string]string{
+				SourceDisk:      "30s",
+				SourceNetwork:   "5s",
+				SourceProcesses: "10s",
+				SourceStorage:   "1h",
+			},
+			Storage: StoragePageConfig{
+				Warn:     70,
+				Critical: 90,
+			},
+			PluginDir: "/etc/i2c-display/pages.d",
 		},
 		SystemInfo: SystemInfoConfig{
 			HostnameDisplay:   "short",
 			DiskPath:          "/",
 			TemperatureSource: "/sys/class/thermal/thermal_zone0/temp",
-			TemperatureUnit:   "celsius",
+			TemperatureUnit:   UnitCelsius,
 		},
 		Network: NetworkConfig{
 			AutoDetect: true,
@@ -151,11 +985,14 @@ func Default() *Config {
 			ShowIPv4:             true,
 			ShowIPv6:             false,
 			MaxInterfacesPerPage: 3,
+			IPv6Format:           IPv6FormatFull,
 		},
 		Logging: LoggingConfig{
-			Level:  "info",
-			Output: "stdout",
-			JSON:   false,
+			Level:             "info",
+			Output:            "stdout",
+			JSON:              false,
+			RenderReportEvery: 10,
+			CrashDir:          "/var/lib/i2c-display/crash",
 		},
 		Metrics: MetricsConfig{
 			Enabled: false,
@@ -168,6 +1005,86 @@ func Default() *Config {
 			DimBrightness:    50,
 			NormalBrightness: 255,
 			WakeDuration:     "30s",
+			ShiftPixels:      2,
+			ShiftInterval:    "1m",
+		},
+		Backlight: BacklightConfig{
+			Enabled:           false,
+			StatePath:         "/var/lib/i2c-display/backlight.json",
+			AutoDimBrightness: 50,
+		},
+		AutoBright: AutoBrightConfig{
+			Enabled:       false,
+			Sensor:        "bh1750",
+			Interval:      "5s",
+			MinBrightness: 16,
+			MaxBrightness: 255,
+			MaxLux:        500,
+		},
+		Thermal: ThermalConfig{
+			Enabled:   false,
+			Threshold: 85,
+			Samples:   5,
+			Countdown: "30s",
+			Command:   []string{"systemctl", "poweroff"},
+		},
+		Connectivity: ConnectivityConfig{
+			Enabled:          false,
+			Target:           "1.1.1.1:53",
+			Interval:         "30s",
+			Timeout:          "5s",
+			PublicIP:         false,
+			PublicIPURL:      "https://api.ipify.org",
+			PublicIPInterval: "1h",
+		},
+		Latency: LatencyConfig{
+			Enabled:  false,
+			Interval: "10s",
+			Timeout:  "2s",
+			Warn:     "50ms",
+			Critical: "200ms",
+		},
+		Updates: UpdatesConfig{
+			Enabled:  false,
+			Manager:  "auto",
+			Interval: "6h",
+			Timeout:  "2m",
+		},
+		TimeSync: TimeSyncConfig{
+			Enabled:  false,
+			Interval: "1m",
+			Timeout:  "5s",
+		},
+		Fan: FanConfig{
+			Enabled:    false,
+			Curve:      []FanPoint{{Temp: 50, Duty: 30}, {Temp: 60, Duty: 60}, {Temp: 70, Duty: 100}},
+			Hysteresis: 3,
+		},
+		Transitions: TransitionsConfig{
+			Enabled:  false,
+			Type:     "slide-left",
+			Duration: "300ms",
+		},
+		Buttons: ButtonsConfig{
+			Enabled:      false,
+			HoldPage:     PageNetwork,
+			HoldDuration: "5m",
+			Debounce:     "50ms",
+		},
+		NightMode: NightModeConfig{
+			Enabled:    false,
+			Start:      "22:00",
+			End:        "07:00",
+			Brightness: 16,
+		},
+		Control: ControlConfig{
+			Enabled: false,
+			Socket:  "/run/i2c-display.sock",
+		},
+		MQTT: MQTTConfig{
+			Enabled:         false,
+			Discovery:       true,
+			DiscoveryPrefix: "homeassistant",
 		},
 	}
 
@@ -179,6 +1096,12 @@ func Default() *Config {
 
 // Load loads configuration from a file path
 func Load(path string) (*Config, error) {
+	return load(path, path)
+}
+
+// load loads configuration from a file path, recording source as where the
+// settings it sets came from
+func load(path, source string) (*Config, error) {
 	data, err := os.ReadFile(path) // #nosec G304,G703 -- config path is from trusted sources (CLI flag, env var, well-known paths)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -188,9 +1111,29 @@ func Load(path string) (*Config, error) {
 	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	if cfg.Strict {
+		unknown, err := unknownKeys(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		if len(unknown) > 0 {
+			return nil, fmt.Errorf("invalid configuration: unknown keys %s", strings.Join(unknown, ", "))
+		}
+	}
+	cfg.path = path
+	cfg.sources = map[string]string{}
+	if err := walkKeys(data, func(path string, known bool) {
+		if known {
+			cfg.sources[path] = source
+		}
+	}); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
 
 	// Apply display defaults based on type
+	configured := cfg.Display
 	cfg.Display.ApplyDisplayDefaults()
+	cfg.markDisplayDefaults(configured)
 
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -199,6 +1142,12 @@ func Load(path string) (*Config, error) {
 	return cfg, nil
 }
 
+// Path returns the file the configuration was loaded from, or "" if it was
+// not loaded from a file
+func (c *Config) Path() string {
+	return c.path
+}
+
 // LoadWithPriority loads configuration using cascading priority:
 // 1. Explicit path (if provided and exists)
 // 2. I2C_DISPLAY_CONFIG_PATH environment variable
@@ -214,7 +1163,8 @@ func LoadWithPriority(explicitPath string) (*Config, error) {
 	}
 
 	// Priority 2: Environment variable
-	if envPath := os.Getenv("I2C_DISPLAY_CONFIG_PATH"); envPath != "" {
+	envPath := os.Getenv("I2C_DISPLAY_CONFIG_PATH")
+	if envPath != "" {
 		paths = append(paths, envPath)
 	}
 
@@ -232,7 +1182,11 @@ func LoadWithPriority(explicitPath string) (*Config, error) {
 	var lastErr error
 	for _, path := range paths {
 		if _, err := os.Stat(path); err == nil { // #nosec G703 -- config paths are from trusted sources
-			cfg, err := Load(path)
+			source := path
+			if path == envPath && path != explicitPath {
+				source += " ($I2C_DISPLAY_CONFIG_PATH)"
+			}
+			cfg, err := load(path, source)
 			if err != nil {
 				lastErr = fmt.Errorf("%s: %w", path, err)
 				continue
@@ -262,13 +1216,64 @@ func (c *Config) Validate() error {
 	if err := c.validateNetwork(); err != nil {
 		return err
 	}
+	if err := c.validateConnectivity(); err != nil {
+		return err
+	}
+	if err := c.validateLatency(); err != nil {
+		return err
+	}
+	if err := c.validateUpdates(); err != nil {
+		return err
+	}
+	if err := c.validateTimeSync(); err != nil {
+		return err
+	}
 	if err := c.validateLogging(); err != nil {
 		return err
 	}
 	if err := c.validateScreenSaver(); err != nil {
 		return err
 	}
-	return c.validateMetrics()
+	if err := c.validateAlerts(); err != nil {
+		return err
+	}
+	if err := c.validateThresholds(); err != nil {
+		return err
+	}
+	if err := validateOptionalDuration("history.retention", c.History.Retention); err != nil {
+		return err
+	}
+	if err := validateOptionalDuration("history.save_interval", c.History.SaveInterval); err != nil {
+		return err
+	}
+	if err := c.validateBacklight(); err != nil {
+		return err
+	}
+	if err := c.validateThermal(); err != nil {
+		return err
+	}
+	if err := c.validateFan(); err != nil {
+		return err
+	}
+	if err := c.validateAutoBright(); err != nil {
+		return err
+	}
+	if err := c.validateTransitions(); err != nil {
+		return err
+	}
+	if err := c.validateButtons(); err != nil {
+		return err
+	}
+	if err := c.validateNightMode(); err != nil {
+		return err
+	}
+	if err := c.validateMetrics(); err != nil {
+		return err
+	}
+	if err := c.validateControl(); err != nil {
+		return err
+	}
+	return c.validateMQTT()
 }
 
 //nolint:gocyclo // linear validation sequence
@@ -277,8 +1282,10 @@ func (c *Config) validateDisplay() error {
 		c.Display.Type = "ssd1306" // Default to SSD1306
 	}
 
+	// "auto" resolves to a concrete type at startup, so dimensions are not known yet
+	isAuto := c.Display.Type == DisplayTypeAuto
 	spec, validType := GetDisplaySpec(c.Display.Type)
-	if !validType {
+	if !validType && !isAuto {
 		return fmt.Errorf("display.type %q is not a recognized display type", c.Display.Type)
 	}
 
@@ -292,12 +1299,17 @@ func (c *Config) validateDisplay() error {
 		if c.Display.I2CAddress == "" {
 			return fmt.Errorf("display.i2c_address cannot be empty")
 		}
-		addrLower := strings.ToLower(c.Display.I2CAddress)
-		if !strings.HasPrefix(addrLower, "0x") {
-			return fmt.Errorf("display.i2c_address must be in hex format (e.g., 0x3C), got %s", c.Display.I2CAddress)
+		for _, addr := range c.Display.AddressCandidates() {
+			addrLower := strings.ToLower(addr)
+			if !strings.HasPrefix(addrLower, "0x") {
+				return fmt.Errorf("display.i2c_address must be in hex format (e.g., 0x3C), got %s", addr)
+			}
+			if _, err := strconv.ParseUint(addrLower[2:], 16, 8); err != nil {
+				return fmt.Errorf("display.i2c_address is not a valid 8-bit hex address (e.g., 0x3C), got %s", addr)
+			}
 		}
-		if _, err := strconv.ParseUint(addrLower[2:], 16, 8); err != nil {
-			return fmt.Errorf("display.i2c_address is not a valid 8-bit hex address (e.g., 0x3C), got %s", c.Display.I2CAddress)
+		if s := c.Display.I2CSpeedHz; s != 0 && (s < minI2CSpeedHz || s > maxI2CSpeedHz) {
+			return fmt.Errorf("display.i2c_speed_hz must be 0 or between %d and %d, got %d", minI2CSpeedHz, maxI2CSpeedHz, s)
 		}
 	}
 
@@ -310,36 +1322,308 @@ func (c *Config) validateDisplay() error {
 		}
 	}
 
-	if c.Display.Width <= 0 {
-		return fmt.Errorf("display.width must be positive, got %d", c.Display.Width)
+	if c.Display.SPISpeedHz < 0 || c.Display.SPISpeedHz > maxSPISpeedHz {
+		return fmt.Errorf("display.spi_speed_hz must be between 0 and %d, got %d", maxSPISpeedHz, c.Display.SPISpeedHz)
 	}
-	if c.Display.Height <= 0 {
-		return fmt.Errorf("display.height must be positive, got %d", c.Display.Height)
+
+	if (c.Display.MirrorX || c.Display.MirrorY) && strings.HasPrefix(strings.ToLower(c.Display.Type), "hd44780") {
+		return fmt.Errorf("display.mirror_x and mirror_y are not supported by character display type %s", c.Display.Type)
 	}
 
-	if c.Display.Width != spec.Width || c.Display.Height != spec.Height {
-		return fmt.Errorf("display dimensions (%dx%d) don't match type %s (expected %dx%d)",
-			c.Display.Width, c.Display.Height, c.Display.Type, spec.Width, spec.Height)
+	if c.Display.Contrast < 0 || c.Display.Contrast > 255 {
+		return fmt.Errorf("display.contrast must be 0-255, got %d", c.Display.Contrast)
 	}
 
-	if c.Display.Rotation < 0 || c.Display.Rotation > 3 {
-		return fmt.Errorf("display.rotation must be 0-3, got %d", c.Display.Rotation)
+	if c.Display.Gamma != 0 && (c.Display.Gamma < MinGamma || c.Display.Gamma > MaxGamma) {
+		return fmt.Errorf("display.gamma must be between %g and %g, got %g", MinGamma, MaxGamma, c.Display.Gamma)
 	}
 
-	if c.Display.Lines != 0 && c.Display.Lines != 2 && c.Display.Lines != 4 {
-		return fmt.Errorf("display.lines must be 0 (auto), 2, or 4, got %d", c.Display.Lines)
+	if c.Display.DoubleBuffer && strings.HasPrefix(strings.ToLower(c.Display.Type), "hd44780") {
+		return fmt.Errorf("display.double_buffer is not supported by character display type %s", c.Display.Type)
 	}
 
-	return nil
-}
+	if err := c.Display.validateST7735Tuning(); err != nil {
+		return err
+	}
 
-func (c *Config) validatePages() error {
-	if _, err := c.Pages.GetRotationInterval(); err != nil {
+	if c.Display.IsEPaper() && c.Display.BusyPin == "" {
+		return fmt.Errorf("display.busy_pin cannot be empty for e-paper display type %s", c.Display.Type)
+	}
+
+	if !isAuto {
+		if c.Display.Width <= 0 {
+			return fmt.Errorf("display.width must be positive, got %d", c.Display.Width)
+		}
+		if c.Display.Height <= 0 {
+			return fmt.Errorf("display.height must be positive, got %d", c.Display.Height)
+		}
+
+		if c.Display.IsResizable() && (c.Display.Width > maxResizableSize || c.Display.Height > maxResizableSize) {
+			return fmt.Errorf("display dimensions (%dx%d) must be at most %dx%d for type %s",
+				c.Display.Width, c.Display.Height, maxResizableSize, maxResizableSize, c.Display.Type)
+		}
+		if !c.Display.IsResizable() && (c.Display.Width != spec.Width || c.Display.Height != spec.Height) {
+			return fmt.Errorf("display dimensions (%dx%d) don't match type %s (expected %dx%d)",
+				c.Display.Width, c.Display.Height, c.Display.Type, spec.Width, spec.Height)
+		}
+	}
+
+	if c.Display.IsFramebuffer() && !strings.HasPrefix(c.Display.FBDevice, "/") {
+		return fmt.Errorf("display.fb_device must be an absolute path, got %q", c.Display.FBDevice)
+	}
+
+	if c.Display.WindowScale < 0 || c.Display.WindowScale > maxWindowScale {
+		return fmt.Errorf("display.window_scale must be 0-%d, got %d", maxWindowScale, c.Display.WindowScale)
+	}
+
+	if c.Display.Font != "" && c.Display.Font != FontGo {
+		if _, err := os.Stat(c.Display.Font); err != nil {
+			return fmt.Errorf("display.font must be %q or a font file: %w", FontGo, err)
+		}
+	}
+
+	if c.Display.Rotation < 0 || c.Display.Rotation > 3 {
+		return fmt.Errorf("display.rotation must be 0-3, got %d", c.Display.Rotation)
+	}
+
+	if c.Display.Lines != 0 && c.Display.Lines != 2 && c.Display.Lines != 4 {
+		return fmt.Errorf("display.lines must be 0 (auto), 2, or 4, got %d", c.Display.Lines)
+	}
+
+	if c.Display.ReinitAfterErrors < 0 {
+		return fmt.Errorf("display.reinit_after_errors cannot be negative, got %d", c.Display.ReinitAfterErrors)
+	}
+
+	if c.Display.StallRefreshes < 0 {
+		return fmt.Errorf("display.stall_refreshes cannot be negative, got %d", c.Display.StallRefreshes)
+	}
+
+	if c.Display.FaultInjectionRate < 0 || c.Display.FaultInjectionRate > 1 {
+		return fmt.Errorf("display.fault_injection_rate must be between 0 and 1, got %g", c.Display.FaultInjectionRate)
+	}
+
+	return nil
+}
+
+func (c *Config) validatePages() error {
+	if _, err := c.Pages.GetRotationInterval(); err != nil {
 		return fmt.Errorf("invalid pages.rotation_interval: %w", err)
 	}
 	if _, err := c.Pages.GetRefreshInterval(); err != nil {
 		return fmt.Errorf("invalid pages.refresh_interval: %w", err)
 	}
+	for source := range c.Pages.RefreshIntervals {
+		if !slices.Contains(RefreshSources, source) {
+			return fmt.Errorf("pages.refresh_intervals key must be one of %v, got %q", RefreshSources, source)
+		}
+	}
+	if _, err := i18n.Lookup(c.Pages.Locale); err != nil {
+		return fmt.Errorf("invalid pages.locale: %w", err)
+	}
+	if err := validateOptionalDuration("pages.collect_timeout", c.Pages.CollectTimeout); err != nil {
+		return err
+	}
+	intervals, err := c.Pages.GetSourceIntervals()
+	if err != nil {
+		return err
+	}
+	for source, d := range intervals {
+		if d <= 0 {
+			return fmt.Errorf("pages.refresh_intervals.%s must be positive", source)
+		}
+	}
+	for pageType := range c.Pages.Durations {
+		if !slices.Contains(PageTypes, pageType) {
+			return fmt.Errorf("pages.durations key must be one of %v, got %q", PageTypes, pageType)
+		}
+	}
+	if c.Pages.ByteUnits != "" && !slices.Contains(ByteUnits, c.Pages.ByteUnits) {
+		return fmt.Errorf("pages.byte_units must be one of %v, got %q", ByteUnits, c.Pages.ByteUnits)
+	}
+	for pageType, unit := range c.Pages.TemperatureUnits {
+		if !slices.Contains(TemperaturePageTypes, pageType) {
+			return fmt.Errorf("pages.temperature_units key must be one of %v, got %q", TemperaturePageTypes, pageType)
+		}
+		if !slices.Contains(TemperatureUnits, unit) {
+			return fmt.Errorf("pages.temperature_units.%s must be one of %v, got %q", pageType, TemperatureUnits, unit)
+		}
+	}
+	durations, err := c.Pages.GetPageDurations()
+	if err != nil {
+		return err
+	}
+	for pageType, d := range durations {
+		if d <= 0 {
+			return fmt.Errorf("pages.durations.%s must be positive", pageType)
+		}
+	}
+	for _, pageType := range c.Pages.Disabled {
+		if !slices.Contains(PageTypes, pageType) {
+			return fmt.Errorf("pages.disabled entries must be one of %v, got %q", PageTypes, pageType)
+		}
+	}
+	if c.Pages.IsDisabled(PageSystem) && c.Pages.IsDisabled(PageNetwork) {
+		// Temperatures and load pages depend on optional data, so at least
+		// one of the always-available pages must stay enabled
+		return fmt.Errorf("pages.disabled cannot disable both system and network pages")
+	}
+	if err := c.validateExecPages(); err != nil {
+		return err
+	}
+	if err := c.validateHTTPJSONPages(); err != nil {
+		return err
+	}
+	if err := c.validateTemplatePages(); err != nil {
+		return err
+	}
+	if c.Pages.Top.Count < 0 || c.Pages.Top.Count > maxTopCount {
+		return fmt.Errorf("pages.top.count must be between 0 and %d, got %d", maxTopCount, c.Pages.Top.Count)
+	}
+	if err := c.validateStoragePage(); err != nil {
+		return err
+	}
+	firstBoot, err := c.Pages.GetFirstBootUptime()
+	if err != nil {
+		return fmt.Errorf("invalid pages.first_boot.until_uptime: %w", err)
+	}
+	if c.Pages.FirstBoot.UntilUptime != "" && firstBoot <= 0 {
+		return fmt.Errorf("pages.first_boot.until_uptime must be positive")
+	}
+	return c.validateQRPage()
+}
+
+// validateStoragePage checks the wear thresholds are percentages in order
+func (c *Config) validateStoragePage() error {
+	st := c.Pages.Storage
+	if st.Warn < 0 || st.Warn > 100 {
+		return fmt.Errorf("pages.storage.warn must be between 0 and 100, got %d", st.Warn)
+	}
+	if st.Critical < 0 || st.Critical > 100 {
+		return fmt.Errorf("pages.storage.critical must be between 0 and 100, got %d", st.Critical)
+	}
+	if st.Warn > 0 && st.Critical > 0 && st.Warn >= st.Critical {
+		return fmt.Errorf("pages.storage.warn must be below pages.storage.critical")
+	}
+	return nil
+}
+
+// validateQRPage checks the QR URL only uses known placeholders and still
+// fits in a QR code with the longest values they can take
+func (c *Config) validateQRPage() error {
+	tmpl := c.Pages.QR.URL
+	if tmpl == "" {
+		return nil
+	}
+	longest := tmpl
+	for placeholder, n := range qrPlaceholders {
+		longest = strings.ReplaceAll(longest, placeholder, strings.Repeat("x", n))
+	}
+	if i := strings.IndexByte(longest, '{'); i >= 0 && strings.IndexByte(longest[i:], '}') > 0 {
+		return fmt.Errorf("pages.qr.url has an unknown placeholder; use {hostname}, {ipv4} or {ipv6}")
+	}
+	if len(longest) > qrcode.MaxLen {
+		return fmt.Errorf("pages.qr.url is too long for a QR code: up to %d bytes with placeholders filled, got %d", qrcode.MaxLen, len(longest))
+	}
+	return nil
+}
+
+func (c *Config) validateExecPages() error {
+	titles := make(map[string]bool, len(c.Pages.Exec))
+	for i, e := range c.Pages.Exec {
+		field := fmt.Sprintf("pages.exec[%d]", i)
+		if e.Title == "" {
+			return fmt.Errorf("%s.title cannot be empty", field)
+		}
+		if titles[e.Title] {
+			return fmt.Errorf("%s.title %q is used by another exec page", field, e.Title)
+		}
+		titles[e.Title] = true
+		if len(e.Command) == 0 || e.Command[0] == "" {
+			return fmt.Errorf("%s.command cannot be empty", field)
+		}
+		d, err := time.ParseDuration(e.Interval)
+		if err != nil {
+			return fmt.Errorf("%s.interval is not a valid duration: %w", field, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("%s.interval must be positive, got %s", field, e.Interval)
+		}
+		if err := validateOptionalDuration(field+".timeout", e.Timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateHTTPJSONPages() error {
+	titles := make(map[string]bool, len(c.Pages.HTTPJSON))
+	for i, h := range c.Pages.HTTPJSON {
+		field := fmt.Sprintf("pages.http_json[%d]", i)
+		if h.Title == "" {
+			return fmt.Errorf("%s.title cannot be empty", field)
+		}
+		if titles[h.Title] {
+			return fmt.Errorf("%s.title %q is used by another http_json page", field, h.Title)
+		}
+		titles[h.Title] = true
+		if u, err := url.Parse(h.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s.url must be an http or https URL, got %q", field, h.URL)
+		}
+		d, err := time.ParseDuration(h.Interval)
+		if err != nil {
+			return fmt.Errorf("%s.interval is not a valid duration: %w", field, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("%s.interval must be positive, got %s", field, h.Interval)
+		}
+		if err := validateOptionalDuration(field+".timeout", h.Timeout); err != nil {
+			return err
+		}
+		if len(h.Fields) == 0 {
+			return fmt.Errorf("%s.fields cannot be empty", field)
+		}
+		for j, f := range h.Fields {
+			if _, err := jsonpath.Parse(f.Path); err != nil {
+				return fmt.Errorf("%s.fields[%d].path: %w", field, j, err)
+			}
+			if _, after, found := strings.Cut(f.Label, "{value"); found && !strings.Contains(after, "}") {
+				return fmt.Errorf("%s.fields[%d].label has an unclosed {value placeholder: %q", field, j, f.Label)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTemplatePages checks the templates parse; colours are checked when
+// the pages are built
+func (c *Config) validateTemplatePages() error {
+	titles := make(map[string]bool, len(c.Pages.Templates))
+	for i, t := range c.Pages.Templates {
+		field := fmt.Sprintf("pages.templates[%d]", i)
+		if t.Title == "" {
+			return fmt.Errorf("%s.title cannot be empty", field)
+		}
+		if titles[t.Title] {
+			return fmt.Errorf("%s.title %q is used by another template page", field, t.Title)
+		}
+		titles[t.Title] = true
+		if len(t.Lines) == 0 {
+			return fmt.Errorf("%s.lines cannot be empty", field)
+		}
+		for j, line := range t.Lines {
+			if _, err := pagetemplate.Parse("text", line.Text); err != nil {
+				return fmt.Errorf("%s.lines[%d].text: %w", field, j, err)
+			}
+			for k, rule := range line.Colors {
+				if _, err := pagetemplate.Parse("when", rule.When); err != nil {
+					return fmt.Errorf("%s.lines[%d].colors[%d].when: %w", field, j, k, err)
+				}
+				if rule.Color == "" {
+					return fmt.Errorf("%s.lines[%d].colors[%d].color cannot be empty", field, j, k)
+				}
+			}
+		}
+	}
 	return nil
 }
 
@@ -353,8 +1637,16 @@ func (c *Config) validateSystemInfo() error {
 	if _, err := os.Stat(c.SystemInfo.DiskPath); err != nil {
 		return fmt.Errorf("system_info.disk_path %q does not exist: %w", c.SystemInfo.DiskPath, err)
 	}
-	if c.SystemInfo.TemperatureUnit != "celsius" && c.SystemInfo.TemperatureUnit != "fahrenheit" {
-		return fmt.Errorf("system_info.temperature_unit must be 'celsius' or 'fahrenheit', got %s", c.SystemInfo.TemperatureUnit)
+	if !slices.Contains(TemperatureUnits, c.SystemInfo.TemperatureUnit) {
+		return fmt.Errorf("system_info.temperature_unit must be one of %v, got %q", TemperatureUnits, c.SystemInfo.TemperatureUnit)
+	}
+	for i, sensor := range c.SystemInfo.TemperatureSensors {
+		if sensor.Name == "" {
+			return fmt.Errorf("system_info.temperature_sensors[%d].name cannot be empty", i)
+		}
+		if sensor.Source == "" {
+			return fmt.Errorf("system_info.temperature_sensors[%d].source cannot be empty", i)
+		}
 	}
 	return nil
 }
@@ -373,6 +1665,9 @@ func (c *Config) validateNetwork() error {
 			return fmt.Errorf("network.interface_filter.exclude contains invalid glob pattern %q: %w", pattern, err)
 		}
 	}
+	if !slices.Contains(IPv6Formats, c.Network.IPv6Format) {
+		return fmt.Errorf("network.ipv6_format must be one of %v, got %q", IPv6Formats, c.Network.IPv6Format)
+	}
 	return nil
 }
 
@@ -381,6 +1676,15 @@ func (c *Config) validateLogging() error {
 	if !validLevels[c.Logging.Level] {
 		return fmt.Errorf("logging.level must be one of [debug, info, warn, error], got %s", c.Logging.Level)
 	}
+	if c.Logging.RenderReports && c.Logging.RenderReportEvery < 1 {
+		return fmt.Errorf("logging.render_report_every must be at least 1, got %d", c.Logging.RenderReportEvery)
+	}
+	if c.Logging.CaptureFrames < 0 || c.Logging.CaptureFrames > maxCaptureFrames {
+		return fmt.Errorf("logging.capture_frames must be between 0 and %d, got %d", maxCaptureFrames, c.Logging.CaptureFrames)
+	}
+	if c.Logging.CaptureFrames > 0 && c.Logging.CrashDir == "" {
+		return fmt.Errorf("logging.crash_dir is required when logging.capture_frames is set")
+	}
 	return nil
 }
 
@@ -389,9 +1693,22 @@ func (c *Config) validateScreenSaver() error {
 		return nil
 	}
 
-	validModes := map[string]bool{"off": true, "dim": true, "blank": true}
+	validModes := map[string]bool{"off": true, "dim": true, "blank": true, "shift": true, "clock": true}
 	if !validModes[c.ScreenSaver.Mode] {
-		return fmt.Errorf("screensaver.mode must be one of [off, dim, blank], got %s", c.ScreenSaver.Mode)
+		return fmt.Errorf("screensaver.mode must be one of [off, dim, blank, shift, clock], got %s", c.ScreenSaver.Mode)
+	}
+
+	if c.ScreenSaver.Mode == "shift" {
+		if c.ScreenSaver.ShiftPixels < 1 || c.ScreenSaver.ShiftPixels > 8 {
+			return fmt.Errorf("screensaver.shift_pixels must be 1-8, got %d", c.ScreenSaver.ShiftPixels)
+		}
+		d, err := time.ParseDuration(c.ScreenSaver.ShiftInterval)
+		if err != nil {
+			return fmt.Errorf("screensaver.shift_interval is not a valid duration: %w", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("screensaver.shift_interval must be positive, got %s", c.ScreenSaver.ShiftInterval)
+		}
 	}
 
 	// idle_timeout is only required when active_hours is not driving activation
@@ -405,7 +1722,8 @@ func (c *Config) validateScreenSaver() error {
 		}
 	}
 
-	if c.ScreenSaver.Mode == "dim" && c.ScreenSaver.DimBrightness >= c.ScreenSaver.NormalBrightness {
+	dims := c.ScreenSaver.Mode == "dim" || c.ScreenSaver.Mode == "clock"
+	if dims && c.ScreenSaver.DimBrightness >= c.ScreenSaver.NormalBrightness {
 		return fmt.Errorf("screensaver.dim_brightness (%d) must be less than normal_brightness (%d)",
 			c.ScreenSaver.DimBrightness, c.ScreenSaver.NormalBrightness)
 	}
@@ -432,6 +1750,349 @@ func (c *Config) validateScreenSaver() error {
 	return nil
 }
 
+func (c *Config) validateThresholds() error {
+	metrics := []struct {
+		name     string
+		levels   LevelsConfig
+		unsigned bool // readings are never negative
+	}{
+		{"disk", c.Thresholds.Disk, true},
+		{"memory", c.Thresholds.Memory, true},
+		{"cpu", c.Thresholds.CPU, true},
+		{"load", c.Thresholds.Load, true},
+		{"temperature", c.Thresholds.Temperature, false},
+	}
+	for _, m := range metrics {
+		if !m.levels.IsSet() {
+			continue
+		}
+		if m.levels.Crit == 0 {
+			return fmt.Errorf("thresholds.%s needs both warn and crit", m.name)
+		}
+		if m.levels.Warn >= m.levels.Crit {
+			return fmt.Errorf("thresholds.%s.warn (%g) must be below crit (%g)", m.name, m.levels.Warn, m.levels.Crit)
+		}
+		if m.unsigned && m.levels.Warn < 0 {
+			return fmt.Errorf("thresholds.%s.warn must not be negative", m.name)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateAlerts() error {
+	if !c.Alerts.Enabled {
+		return nil
+	}
+
+	validMetrics := map[string]bool{"disk": true, "memory": true, "cpu_temp": true, "load": true}
+	validOperators := map[string]bool{"": true, ">": true, ">=": true, "<": true, "<=": true}
+	for i, rule := range c.Alerts.Rules {
+		if !validMetrics[rule.Metric] {
+			return fmt.Errorf("alerts.rules[%d].metric must be one of [disk, memory, cpu_temp, load], got %q", i, rule.Metric)
+		}
+		if !validOperators[rule.Operator] {
+			return fmt.Errorf("alerts.rules[%d].operator must be one of [>, >=, <, <=], got %q", i, rule.Operator)
+		}
+	}
+
+	if n := c.Alerts.Notify; n.WebhookURL != "" {
+		u, err := url.Parse(n.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("alerts.notify.webhook_url must be an http or https URL, got %q", n.WebhookURL)
+		}
+	}
+	return validateOptionalDuration("alerts.notify.timeout", c.Alerts.Notify.Timeout)
+}
+
+func (c *Config) validateBacklight() error {
+	if !c.Backlight.Enabled {
+		return nil
+	}
+	if c.Backlight.StatePath == "" {
+		return fmt.Errorf("backlight.state_path cannot be empty when backlight tracking is enabled")
+	}
+	if err := validateOptionalDuration("backlight.max_daily_on", c.Backlight.MaxDailyOn); err != nil {
+		return err
+	}
+	return validateOptionalDuration("backlight.auto_dim_after", c.Backlight.AutoDimAfter)
+}
+
+func (c *Config) validateThermal() error {
+	if !c.Thermal.Enabled {
+		return nil
+	}
+	if c.Thermal.Threshold <= 0 {
+		return fmt.Errorf("thermal_shutdown.threshold must be positive, got %g", c.Thermal.Threshold)
+	}
+	if c.Thermal.Samples < 1 {
+		return fmt.Errorf("thermal_shutdown.samples must be at least 1, got %d", c.Thermal.Samples)
+	}
+	if len(c.Thermal.Command) == 0 || c.Thermal.Command[0] == "" {
+		return fmt.Errorf("thermal_shutdown.command cannot be empty when thermal shutdown is enabled")
+	}
+	if c.SystemInfo.TemperatureSource == "" {
+		return fmt.Errorf("thermal_shutdown requires system_info.temperature_source to be set")
+	}
+	d, err := time.ParseDuration(c.Thermal.Countdown)
+	if err != nil {
+		return fmt.Errorf("thermal_shutdown.countdown is not a valid duration: %w", err)
+	}
+	if d < 0 {
+		return fmt.Errorf("thermal_shutdown.countdown cannot be negative, got %s", c.Thermal.Countdown)
+	}
+	return nil
+}
+
+func (c *Config) validateConnectivity() error {
+	cc := c.Connectivity
+	if !cc.Enabled {
+		return nil
+	}
+	if strings.Contains(cc.Target, "://") {
+		u, err := url.Parse(cc.Target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("connectivity.target must be an http or https URL or host:port, got %q", cc.Target)
+		}
+	} else if _, port, err := net.SplitHostPort(cc.Target); err != nil || port == "" {
+		return fmt.Errorf("connectivity.target must be an http or https URL or host:port, got %q", cc.Target)
+	}
+	for _, d := range [][2]string{{"interval", cc.Interval}, {"timeout", cc.Timeout}} {
+		if d[1] == "" {
+			return fmt.Errorf("connectivity.%s cannot be empty", d[0])
+		}
+		if err := validateOptionalDuration("connectivity."+d[0], d[1]); err != nil {
+			return err
+		}
+	}
+	if !cc.PublicIP {
+		return nil
+	}
+	u, err := url.Parse(cc.PublicIPURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("connectivity.public_ip_url must be an http or https URL, got %q", cc.PublicIPURL)
+	}
+	if cc.PublicIPInterval == "" {
+		return fmt.Errorf("connectivity.public_ip_interval cannot be empty")
+	}
+	return validateOptionalDuration("connectivity.public_ip_interval", cc.PublicIPInterval)
+}
+
+func (c *Config) validateLatency() error {
+	l := c.Latency
+	if !l.Enabled {
+		return nil
+	}
+	if len(l.Hosts) == 0 {
+		return fmt.Errorf("latency.hosts must have at least one host when the latency monitor is enabled")
+	}
+	for i, h := range l.Hosts {
+		if h.Host == "" {
+			return fmt.Errorf("latency.hosts[%d].host cannot be empty", i)
+		}
+	}
+	for _, d := range [][2]string{{"interval", l.Interval}, {"timeout", l.Timeout}, {"warn", l.Warn}, {"critical", l.Critical}} {
+		if d[1] == "" {
+			return fmt.Errorf("latency.%s cannot be empty", d[0])
+		}
+		if err := validateOptionalDuration("latency."+d[0], d[1]); err != nil {
+			return err
+		}
+	}
+	warn, _ := time.ParseDuration(l.Warn)
+	critical, _ := time.ParseDuration(l.Critical)
+	if critical < warn {
+		return fmt.Errorf("latency.critical (%s) cannot be below latency.warn (%s)", l.Critical, l.Warn)
+	}
+	return nil
+}
+
+func (c *Config) validateUpdates() error {
+	u := c.Updates
+	if !u.Enabled {
+		return nil
+	}
+	if !slices.Contains(UpdateManagers, u.Manager) {
+		return fmt.Errorf("updates.manager must be one of %v, got %q", UpdateManagers, u.Manager)
+	}
+	for _, d := range [][2]string{{"interval", u.Interval}, {"timeout", u.Timeout}} {
+		if d[1] == "" {
+			return fmt.Errorf("updates.%s cannot be empty", d[0])
+		}
+		if err := validateOptionalDuration("updates."+d[0], d[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateTimeSync() error {
+	ts := c.TimeSync
+	if !ts.Enabled {
+		return nil
+	}
+	for _, d := range [][2]string{{"interval", ts.Interval}, {"timeout", ts.Timeout}} {
+		if d[1] == "" {
+			return fmt.Errorf("time_sync.%s cannot be empty", d[0])
+		}
+		if err := validateOptionalDuration("time_sync."+d[0], d[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateFan() error {
+	if !c.Fan.Enabled {
+		return nil
+	}
+	if c.Fan.Pin == "" {
+		return fmt.Errorf("fan.pin cannot be empty when fan control is enabled")
+	}
+	if len(c.Fan.Curve) == 0 {
+		return fmt.Errorf("fan.curve must have at least one point")
+	}
+	for i, p := range c.Fan.Curve {
+		if p.Duty < 0 || p.Duty > 100 {
+			return fmt.Errorf("fan.curve[%d].duty must be between 0 and 100, got %d", i, p.Duty)
+		}
+		if i > 0 && p.Temp <= c.Fan.Curve[i-1].Temp {
+			return fmt.Errorf("fan.curve temperatures must be ascending, got %g after %g", p.Temp, c.Fan.Curve[i-1].Temp)
+		}
+	}
+	if c.Fan.Hysteresis < 0 {
+		return fmt.Errorf("fan.hysteresis cannot be negative, got %g", c.Fan.Hysteresis)
+	}
+	if c.SystemInfo.TemperatureSource == "" {
+		return fmt.Errorf("fan requires system_info.temperature_source to be set")
+	}
+	return nil
+}
+
+func (c *Config) validateAutoBright() error {
+	ab := c.AutoBright
+	if !ab.Enabled {
+		return nil
+	}
+	validSensors := map[string]bool{"bh1750": true, "tsl2561": true, "veml7700": true}
+	if !validSensors[ab.Sensor] {
+		return fmt.Errorf("auto_brightness.sensor must be one of [bh1750, tsl2561, veml7700], got %q", ab.Sensor)
+	}
+	if ab.I2CBus != "" && !strings.HasPrefix(ab.I2CBus, "/") {
+		return fmt.Errorf("auto_brightness.i2c_bus must be an absolute path, got %s", ab.I2CBus)
+	}
+	if ab.I2CBus == "" && c.Display.I2CBus == "" {
+		return fmt.Errorf("auto_brightness.i2c_bus is required when the display is not on I2C")
+	}
+	if ab.I2CAddress != "" {
+		addrLower := strings.ToLower(ab.I2CAddress)
+		if _, err := strconv.ParseUint(strings.TrimPrefix(addrLower, "0x"), 16, 8); err != nil || !strings.HasPrefix(addrLower, "0x") {
+			return fmt.Errorf("auto_brightness.i2c_address must be a hex address (e.g., 0x23), got %s", ab.I2CAddress)
+		}
+	}
+	interval, err := time.ParseDuration(ab.Interval)
+	if err != nil {
+		return fmt.Errorf("auto_brightness.interval is not a valid duration: %w", err)
+	}
+	if interval < 100*time.Millisecond {
+		return fmt.Errorf("auto_brightness.interval must be at least 100ms, got %s", ab.Interval)
+	}
+	if ab.MinBrightness > ab.MaxBrightness {
+		return fmt.Errorf("auto_brightness.min_brightness (%d) cannot exceed max_brightness (%d)", ab.MinBrightness, ab.MaxBrightness)
+	}
+	if ab.MaxLux <= 0 {
+		return fmt.Errorf("auto_brightness.max_lux must be positive, got %g", ab.MaxLux)
+	}
+	return nil
+}
+
+func (c *Config) validateTransitions() error {
+	tr := c.Transitions
+	if !tr.Enabled {
+		return nil
+	}
+	validTypes := map[string]bool{"slide-left": true, "fade": true, "wipe": true}
+	if !validTypes[tr.Type] {
+		return fmt.Errorf("transitions.type must be one of [slide-left, fade, wipe], got %q", tr.Type)
+	}
+	d, err := time.ParseDuration(tr.Duration)
+	if err != nil {
+		return fmt.Errorf("transitions.duration is not a valid duration: %w", err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("transitions.duration must be positive, got %s", tr.Duration)
+	}
+	// Rotation interval and page durations have already been validated by validatePages
+	if rotation, _ := c.Pages.GetRotationInterval(); d >= rotation {
+		return fmt.Errorf("transitions.duration (%s) must be shorter than pages.rotation_interval (%s)", tr.Duration, c.Pages.RotationInterval)
+	}
+	durations, _ := c.Pages.GetPageDurations()
+	for pageType, pd := range durations {
+		if d >= pd {
+			return fmt.Errorf("transitions.duration (%s) must be shorter than pages.durations.%s (%s)", tr.Duration, pageType, c.Pages.Durations[pageType])
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateButtons() error {
+	b := c.Buttons
+	if !b.Enabled {
+		return nil
+	}
+	if b.PausePin == "" && b.HoldPin == "" {
+		return fmt.Errorf("buttons.pause_pin or buttons.hold_pin is required when buttons are enabled")
+	}
+	if b.PausePin != "" && b.PausePin == b.HoldPin {
+		return fmt.Errorf("buttons.pause_pin and buttons.hold_pin must be different pins")
+	}
+	if b.HoldPin != "" && !slices.Contains(PageTypes, b.HoldPage) {
+		return fmt.Errorf("buttons.hold_page must be one of %v, got %q", PageTypes, b.HoldPage)
+	}
+	if err := validateOptionalDuration("buttons.hold_duration", b.HoldDuration); err != nil {
+		return err
+	}
+	debounce, err := time.ParseDuration(b.Debounce)
+	if err != nil {
+		return fmt.Errorf("buttons.debounce is not a valid duration: %w", err)
+	}
+	if debounce < 0 {
+		return fmt.Errorf("buttons.debounce must not be negative, got %s", b.Debounce)
+	}
+	return nil
+}
+
+func (c *Config) validateNightMode() error {
+	n := c.NightMode
+	if !n.Enabled {
+		return nil
+	}
+	if err := validateHHMM("night_mode.start", n.Start); err != nil {
+		return err
+	}
+	if err := validateHHMM("night_mode.end", n.End); err != nil {
+		return err
+	}
+	if n.Start == n.End {
+		return fmt.Errorf("night_mode.start and night_mode.end must differ, got %s", n.Start)
+	}
+	return nil
+}
+
+// validateOptionalDuration checks that s is empty or a positive duration
+func validateOptionalDuration(field, s string) error {
+	if s == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid duration: %w", field, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("%s must be positive, got %s", field, s)
+	}
+	return nil
+}
+
 func validateHHMM(field, s string) error {
 	var h, m int
 	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || n != 2 {
@@ -446,14 +2107,133 @@ func validateHHMM(field, s string) error {
 	return nil
 }
 
-func (c *Config) validateMetrics() error {
-	if !c.Metrics.Enabled {
+func (c *Config) validateControl() error {
+	if !c.Control.Enabled {
+		return nil
+	}
+	if c.Control.Socket == "" {
+		return fmt.Errorf("control.socket cannot be empty when the control socket is enabled")
+	}
+	if !filepath.IsAbs(c.Control.Socket) {
+		return fmt.Errorf("control.socket must be an absolute path, got %q", c.Control.Socket)
+	}
+	return nil
+}
+
+func (c *Config) validateMQTT() error {
+	m := c.MQTT
+	if !m.Enabled {
 		return nil
 	}
+	if m.Broker == "" {
+		return fmt.Errorf("mqtt.broker cannot be empty when MQTT is enabled")
+	}
+	u, err := url.Parse(m.Broker)
+	if err != nil || u.Host == "" || !slices.Contains(MQTTBrokerSchemes, u.Scheme) {
+		return fmt.Errorf("mqtt.broker must be a URL like tcp://host:1883 with a scheme of %v, got %q", MQTTBrokerSchemes, m.Broker)
+	}
+	if strings.ContainsAny(m.TopicPrefix, "#+") || strings.HasSuffix(m.TopicPrefix, "/") {
+		return fmt.Errorf("mqtt.topic_prefix must not contain wildcards or end with /, got %q", m.TopicPrefix)
+	}
+	if m.Discovery && (m.DiscoveryPrefix == "" || strings.ContainsAny(m.DiscoveryPrefix, "#+")) {
+		return fmt.Errorf("mqtt.discovery_prefix must be a topic without wildcards when discovery is enabled, got %q", m.DiscoveryPrefix)
+	}
+	return nil
+}
 
-	if c.Metrics.Address == "" {
+func (c *Config) validateMetrics() error {
+	if c.Metrics.Enabled && c.Metrics.Address == "" {
 		return fmt.Errorf("metrics.address cannot be empty when metrics are enabled")
 	}
+	if path, ok := strings.CutPrefix(c.Metrics.Address, "unix:"); ok && !filepath.IsAbs(path) {
+		return fmt.Errorf("metrics.address socket must be an absolute path, got %q", path)
+	}
+
+	t := c.Metrics.TLS
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("metrics.tls needs both cert_file and key_file")
+	}
+	if t.ClientCAFile != "" && t.CertFile == "" {
+		return fmt.Errorf("metrics.tls.client_ca_file needs cert_file and key_file")
+	}
+	a := c.Metrics.Auth
+	if (a.Username == "") != (a.Password == "") {
+		return fmt.Errorf("metrics.auth needs both username and password for basic auth")
+	}
+
+	return c.Metrics.Push.validate()
+}
 
+// validate checks the metrics push settings. The protocol decides which of
+// url and address is needed.
+func (m *MetricsPushConfig) validate() error {
+	if m.Protocol == "" {
+		return nil
+	}
+	if !slices.Contains(MetricsPushProtocols, m.Protocol) {
+		return fmt.Errorf("metrics.push.protocol must be one of %v, got %q", MetricsPushProtocols, m.Protocol)
+	}
+	if m.Protocol == MetricsPushStatsd {
+		if _, _, err := net.SplitHostPort(m.Address); err != nil {
+			return fmt.Errorf("metrics.push.address must be host:port for statsd: %w", err)
+		}
+	} else {
+		u, err := url.Parse(m.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("metrics.push.url must be an http or https URL for %s, got %q", m.Protocol, m.URL)
+		}
+	}
+	for name := range m.Labels {
+		if !validLabelName(name) {
+			return fmt.Errorf("metrics.push.labels: invalid label name %q", name)
+		}
+	}
+	return validateOptionalDuration("metrics.push.interval", m.Interval)
+}
+
+// validLabelName reports whether name is a valid Prometheus label name
+func validLabelName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "__") {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// validateST7735Tuning checks the ST7735 panel variant options, which no
+// other display type understands
+func (c *DisplayConfig) validateST7735Tuning() error {
+	if !strings.HasPrefix(strings.ToLower(c.Type), "st7735") {
+		if c.Variant != "" || c.InvertColors || c.BGROrder || len(c.GammaPositive) > 0 || len(c.GammaNegative) > 0 {
+			return fmt.Errorf("display.variant, invert_colors, bgr_order and gamma tables only apply to st7735 types, not %s", c.Type)
+		}
+		return nil
+	}
+	if c.Variant != "" && !slices.Contains(validST7735Variants, c.Variant) {
+		return fmt.Errorf("display.variant must be one of %v, got %q", validST7735Variants, c.Variant)
+	}
+	if err := validateGamma("gamma_positive", c.GammaPositive); err != nil {
+		return err
+	}
+	return validateGamma("gamma_negative", c.GammaNegative)
+}
+
+// validateGamma checks an optional ST7735 gamma table
+func validateGamma(name string, gamma []int) error {
+	if len(gamma) == 0 {
+		return nil
+	}
+	if len(gamma) != st7735GammaLen {
+		return fmt.Errorf("display.%s must have %d values, got %d", name, st7735GammaLen, len(gamma))
+	}
+	for _, v := range gamma {
+		if v < 0 || v > st7735GammaMax {
+			return fmt.Errorf("display.%s values must be between 0 and %d, got %d", name, st7735GammaMax, v)
+		}
+	}
 	return nil
 }
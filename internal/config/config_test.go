@@ -1,8 +1,11 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -113,26 +116,1129 @@ func TestValidate(t *testing.T) {
 		{
 			name: "invalid temperature unit",
 			modify: func(c *Config) {
-				c.SystemInfo.TemperatureUnit = "kelvin"
+				c.SystemInfo.TemperatureUnit = "rankine"
 			},
 			wantErr: true,
-			errMsg:  "temperature_unit must be 'celsius' or 'fahrenheit'",
+			errMsg:  "temperature_unit must be one of",
+		},
+		{
+			name: "kelvin temperature unit",
+			modify: func(c *Config) {
+				c.SystemInfo.TemperatureUnit = UnitKelvin
+			},
+			wantErr: false,
 		},
 		{
 			name: "invalid max interfaces per page",
 			modify: func(c *Config) {
-				c.Network.MaxInterfacesPerPage = 0
+				c.Network.MaxInterfacesPerPage = 0
+			},
+			wantErr: true,
+			errMsg:  "max_interfaces_per_page must be positive",
+		},
+		{
+			name: "invalid log level",
+			modify: func(c *Config) {
+				c.Logging.Level = "verbose"
+			},
+			wantErr: true,
+			errMsg:  "logging.level must be one of",
+		},
+		{
+			name: "relative metrics socket",
+			modify: func(c *Config) {
+				c.Metrics.Enabled = true
+				c.Metrics.Address = "unix:http.sock"
+			},
+			wantErr: true,
+			errMsg:  "metrics.address socket must be an absolute path",
+		},
+		{
+			name: "metrics on a unix socket",
+			modify: func(c *Config) {
+				c.Metrics.Enabled = true
+				c.Metrics.Address = "unix:/run/i2c-display/http.sock"
+				c.Metrics.SocketGroup = "www-data"
+			},
+			wantErr: false,
+		},
+		{
+			name: "metrics tls without key",
+			modify: func(c *Config) {
+				c.Metrics.TLS.CertFile = "/etc/i2c-display/server.crt"
+			},
+			wantErr: true,
+			errMsg:  "metrics.tls needs both cert_file and key_file",
+		},
+		{
+			name: "metrics client ca without certificate",
+			modify: func(c *Config) {
+				c.Metrics.TLS.ClientCAFile = "/etc/i2c-display/ca.crt"
+			},
+			wantErr: true,
+			errMsg:  "metrics.tls.client_ca_file needs cert_file",
+		},
+		{
+			name: "metrics basic auth without password",
+			modify: func(c *Config) {
+				c.Metrics.Auth.Username = "admin"
+			},
+			wantErr: true,
+			errMsg:  "metrics.auth needs both username and password",
+		},
+		{
+			name: "metrics token auth over mutual tls",
+			modify: func(c *Config) {
+				c.Metrics.Enabled = true
+				c.Metrics.Address = "0.0.0.0:9090"
+				c.Metrics.TLS = MetricsTLSConfig{CertFile: "/etc/i2c-display/server.crt", KeyFile: "/etc/i2c-display/server.key", ClientCAFile: "/etc/i2c-display/ca.crt"}
+				c.Metrics.Auth.Token = "s3cret"
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid metrics push protocol",
+			modify: func(c *Config) {
+				c.Metrics.Push.Protocol = "graphite"
+			},
+			wantErr: true,
+			errMsg:  "metrics.push.protocol must be one of",
+		},
+		{
+			name: "remote_write push without url",
+			modify: func(c *Config) {
+				c.Metrics.Push.Protocol = MetricsPushRemoteWrite
+			},
+			wantErr: true,
+			errMsg:  "metrics.push.url must be an http or https URL",
+		},
+		{
+			name: "statsd push without address",
+			modify: func(c *Config) {
+				c.Metrics.Push.Protocol = MetricsPushStatsd
+				c.Metrics.Push.URL = "http://127.0.0.1:8125"
+			},
+			wantErr: true,
+			errMsg:  "metrics.push.address must be host:port",
+		},
+		{
+			name: "invalid metrics push label",
+			modify: func(c *Config) {
+				c.Metrics.Push.Protocol = MetricsPushInflux
+				c.Metrics.Push.URL = "http://influxdb:8086/write?db=pi"
+				c.Metrics.Push.Labels = map[string]string{"1st": "x"}
+			},
+			wantErr: true,
+			errMsg:  "invalid label name",
+		},
+		{
+			name: "valid statsd push",
+			modify: func(c *Config) {
+				c.Metrics.Push.Protocol = MetricsPushStatsd
+				c.Metrics.Push.Address = "127.0.0.1:8125"
+				c.Metrics.Push.Interval = "1m"
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid history save interval",
+			modify: func(c *Config) {
+				c.History.File = "/var/lib/i2c-display/history.bin"
+				c.History.SaveInterval = "0s"
+			},
+			wantErr: true,
+			errMsg:  "history.save_interval must be positive",
+		},
+		{
+			name: "invalid history retention",
+			modify: func(c *Config) {
+				c.History.Retention = "an hour"
+			},
+			wantErr: true,
+			errMsg:  "history.retention is not a valid duration",
+		},
+		{
+			name: "negative load threshold",
+			modify: func(c *Config) {
+				c.Thresholds.Load = LevelsConfig{Warn: -1, Crit: 2}
+			},
+			wantErr: true,
+			errMsg:  "thresholds.load.warn must not be negative",
+		},
+		{
+			name: "custom temperature thresholds",
+			modify: func(c *Config) {
+				c.Thresholds.Temperature = LevelsConfig{Warn: 65, Crit: 80}
+			},
+			wantErr: false,
+		},
+		{
+			name: "threshold warn above crit",
+			modify: func(c *Config) {
+				c.Thresholds.Disk = LevelsConfig{Warn: 90, Crit: 80}
+			},
+			wantErr: true,
+			errMsg:  "thresholds.disk.warn (90) must be below crit (80)",
+		},
+		{
+			name: "threshold with only warn set",
+			modify: func(c *Config) {
+				c.Thresholds.Memory = LevelsConfig{Warn: 70}
+			},
+			wantErr: true,
+			errMsg:  "thresholds.memory needs both warn and crit",
+		},
+		{
+			name: "negative usage threshold",
+			modify: func(c *Config) {
+				c.Thresholds.CPU = LevelsConfig{Warn: -10, Crit: 50}
+			},
+			wantErr: true,
+			errMsg:  "thresholds.cpu.warn must not be negative",
+		},
+		{
+			name: "si byte units",
+			modify: func(c *Config) {
+				c.Pages.ByteUnits = ByteUnitsSI
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid byte units",
+			modify: func(c *Config) {
+				c.Pages.ByteUnits = "jedec"
+			},
+			wantErr: true,
+			errMsg:  "pages.byte_units must be one of",
+		},
+		{
+			name: "per-page temperature unit",
+			modify: func(c *Config) {
+				c.Pages.TemperatureUnits = map[string]string{PageTemperatures: UnitFahrenheit}
+			},
+			wantErr: false,
+		},
+		{
+			name: "temperature unit for a page without temperatures",
+			modify: func(c *Config) {
+				c.Pages.TemperatureUnits = map[string]string{PageNetwork: UnitKelvin}
+			},
+			wantErr: true,
+			errMsg:  "pages.temperature_units key must be one of",
+		},
+		{
+			name: "invalid per-page temperature unit",
+			modify: func(c *Config) {
+				c.Pages.TemperatureUnits = map[string]string{PageSystem: "rankine"}
+			},
+			wantErr: true,
+			errMsg:  "pages.temperature_units.system must be one of",
+		},
+		{
+			name: "unknown locale",
+			modify: func(c *Config) {
+				c.Pages.Locale = "tlh"
+			},
+			wantErr: true,
+			errMsg:  "invalid pages.locale",
+		},
+		{
+			name: "locale with region",
+			modify: func(c *Config) {
+				c.Pages.Locale = "de_DE.UTF-8"
+			},
+			wantErr: false,
+		},
+		{
+			name: "contrast out of range",
+			modify: func(c *Config) {
+				c.Display.Contrast = 300
+			},
+			wantErr: true,
+			errMsg:  "display.contrast must be 0-255",
+		},
+		{
+			name: "gamma out of range",
+			modify: func(c *Config) {
+				c.Display.Gamma = 0.2
+			},
+			wantErr: true,
+			errMsg:  "display.gamma must be between 0.5 and 3",
+		},
+		{
+			name: "calibrated display",
+			modify: func(c *Config) {
+				c.Display.Contrast = 200
+				c.Display.Gamma = 1.2
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative capture frames",
+			modify: func(c *Config) {
+				c.Logging.CaptureFrames = -1
+			},
+			wantErr: true,
+			errMsg:  "logging.capture_frames",
+		},
+		{
+			name: "capture frames without a crash dir",
+			modify: func(c *Config) {
+				c.Logging.CaptureFrames = 30
+				c.Logging.CrashDir = ""
+			},
+			wantErr: true,
+			errMsg:  "logging.crash_dir",
+		},
+		{
+			name: "negative collect timeout",
+			modify: func(c *Config) {
+				c.Pages.CollectTimeout = "-1s"
+			},
+			wantErr: true,
+			errMsg:  "pages.collect_timeout must be positive",
+		},
+		{
+			name: "unknown ipv6 format",
+			modify: func(c *Config) {
+				c.Network.IPv6Format = "compact"
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing font file",
+			modify: func(c *Config) {
+				c.Display.Font = "/nonexistent/font.ttf"
+			},
+			wantErr: true,
+		},
+		{
+			name: "template page with syntax error",
+			modify: func(c *Config) {
+				c.Pages.Templates = []TemplatePageConfig{{
+					Title: "Custom",
+					Lines: []TemplateLine{{Text: "{{.Hostname"}},
+				}}
+			},
+			wantErr: true,
+			errMsg:  "pages.templates[0].lines[0].text",
+		},
+		{
+			name: "http_json page with invalid path",
+			modify: func(c *Config) {
+				c.Pages.HTTPJSON = []HTTPJSONPageConfig{{
+					Title:    "Pi-hole",
+					URL:      "http://localhost/admin/api.php",
+					Interval: "30s",
+					Fields:   []HTTPJSONField{{Path: "queries..blocked"}},
+				}}
+			},
+			wantErr: true,
+			errMsg:  "pages.http_json[0].fields[0].path",
+		},
+		{
+			name: "http_json page without http url",
+			modify: func(c *Config) {
+				c.Pages.HTTPJSON = []HTTPJSONPageConfig{{
+					Title:    "Pi-hole",
+					URL:      "file:///etc/passwd",
+					Interval: "30s",
+					Fields:   []HTTPJSONField{{Path: "status"}},
+				}}
+			},
+			wantErr: true,
+			errMsg:  "pages.http_json[0].url must be an http or https URL",
+		},
+		{
+			name: "mqtt broker without scheme",
+			modify: func(c *Config) {
+				c.MQTT.Enabled = true
+				c.MQTT.Broker = "broker.local:1883"
+			},
+			wantErr: true,
+			errMsg:  "mqtt.broker must be a URL",
+		},
+		{
+			name: "mqtt topic prefix with wildcard",
+			modify: func(c *Config) {
+				c.MQTT.Enabled = true
+				c.MQTT.Broker = "tcp://broker.local:1883"
+				c.MQTT.TopicPrefix = "displays/#"
+			},
+			wantErr: true,
+			errMsg:  "mqtt.topic_prefix must not contain wildcards",
+		},
+		{
+			name: "empty time sync interval",
+			modify: func(c *Config) {
+				c.TimeSync.Enabled = true
+				c.TimeSync.Interval = ""
+			},
+			wantErr: true,
+			errMsg:  "time_sync.interval cannot be empty",
+		},
+		{
+			name: "invalid update manager",
+			modify: func(c *Config) {
+				c.Updates.Enabled = true
+				c.Updates.Manager = "pacman"
+			},
+			wantErr: true,
+			errMsg:  "updates.manager must be one of",
+		},
+		{
+			name: "invalid update interval",
+			modify: func(c *Config) {
+				c.Updates.Enabled = true
+				c.Updates.Interval = "daily"
+			},
+			wantErr: true,
+			errMsg:  "updates.interval",
+		},
+		{
+			name: "storage warn out of range",
+			modify: func(c *Config) {
+				c.Pages.Storage.Warn = 120
+			},
+			wantErr: true,
+			errMsg:  "pages.storage.warn must be between 0 and 100",
+		},
+		{
+			name: "storage warn above critical",
+			modify: func(c *Config) {
+				c.Pages.Storage.Warn = 95
+			},
+			wantErr: true,
+			errMsg:  "pages.storage.warn must be below pages.storage.critical",
+		},
+		{
+			name: "storage critical only",
+			modify: func(c *Config) {
+				c.Pages.Storage.Enabled = true
+				c.Pages.Storage.Warn = 0
+			},
+			wantErr: false,
+		},
+		{
+			name: "latency monitor",
+			modify: func(c *Config) {
+				c.Latency.Enabled = true
+				c.Latency.Hosts = []LatencyTarget{{Name: "ISP", Host: "192.0.2.1"}, {Host: "1.1.1.1"}}
+			},
+			wantErr: false,
+		},
+		{
+			name: "latency monitor without hosts",
+			modify: func(c *Config) {
+				c.Latency.Enabled = true
+			},
+			wantErr: true,
+			errMsg:  "latency.hosts must have at least one host",
+		},
+		{
+			name: "latency critical below warn",
+			modify: func(c *Config) {
+				c.Latency.Enabled = true
+				c.Latency.Hosts = []LatencyTarget{{Host: "1.1.1.1"}}
+				c.Latency.Warn = "100ms"
+				c.Latency.Critical = "50ms"
+			},
+			wantErr: true,
+			errMsg:  "latency.critical (50ms) cannot be below latency.warn (100ms)",
+		},
+		{
+			name: "connectivity check",
+			modify: func(c *Config) {
+				c.Connectivity.Enabled = true
+				c.Connectivity.Target = "https://example.com/"
+				c.Connectivity.PublicIP = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "connectivity target without port",
+			modify: func(c *Config) {
+				c.Connectivity.Enabled = true
+				c.Connectivity.Target = "1.1.1.1"
+			},
+			wantErr: true,
+			errMsg:  "connectivity.target must be an http or https URL or host:port",
+		},
+		{
+			name: "connectivity invalid interval",
+			modify: func(c *Config) {
+				c.Connectivity.Enabled = true
+				c.Connectivity.Interval = "often"
+			},
+			wantErr: true,
+			errMsg:  "connectivity.interval is not a valid duration",
+		},
+		{
+			name: "connectivity public ip url",
+			modify: func(c *Config) {
+				c.Connectivity.Enabled = true
+				c.Connectivity.PublicIP = true
+				c.Connectivity.PublicIPURL = "ftp://example.com"
+			},
+			wantErr: true,
+			errMsg:  "connectivity.public_ip_url must be an http or https URL",
+		},
+		{
+			name: "first boot page",
+			modify: func(c *Config) {
+				c.Pages.FirstBoot.UntilUptime = "15m"
+			},
+			wantErr: false,
+		},
+		{
+			name: "first boot page invalid uptime",
+			modify: func(c *Config) {
+				c.Pages.FirstBoot.UntilUptime = "soon"
+			},
+			wantErr: true,
+			errMsg:  "invalid pages.first_boot.until_uptime",
+		},
+		{
+			name: "first boot page zero uptime",
+			modify: func(c *Config) {
+				c.Pages.FirstBoot.UntilUptime = "0s"
+			},
+			wantErr: true,
+			errMsg:  "pages.first_boot.until_uptime must be positive",
+		},
+		{
+			name: "qr page url",
+			modify: func(c *Config) {
+				c.Pages.QR.URL = "http://{ipv4}:8080/{hostname}"
+			},
+			wantErr: false,
+		},
+		{
+			name: "qr page unknown placeholder",
+			modify: func(c *Config) {
+				c.Pages.QR.URL = "http://{ip}:8080"
+			},
+			wantErr: true,
+			errMsg:  "pages.qr.url has an unknown placeholder",
+		},
+		{
+			name: "qr page url too long",
+			modify: func(c *Config) {
+				c.Pages.QR.URL = "https://example.com/" + strings.Repeat("a", 200)
+			},
+			wantErr: true,
+			errMsg:  "pages.qr.url is too long",
+		},
+		{
+			name: "double buffered character display",
+			modify: func(c *Config) {
+				c.Display.Type = "hd44780_16x2"
+				c.Display.Width = 80
+				c.Display.Height = 16
+				c.Display.I2CAddress = "0x27"
+				c.Display.DoubleBuffer = true
+			},
+			wantErr: true,
+			errMsg:  "display.double_buffer is not supported",
+		},
+		{
+			name: "double buffered uctronics",
+			modify: func(c *Config) {
+				c.Display.Type = "uctronics_colour"
+				c.Display.Width = 160
+				c.Display.Height = 80
+				c.Display.DoubleBuffer = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "fan without pin",
+			modify: func(c *Config) {
+				c.Fan.Enabled = true
+			},
+			wantErr: true,
+			errMsg:  "fan.pin cannot be empty",
+		},
+		{
+			name: "fan curve out of order",
+			modify: func(c *Config) {
+				c.Fan.Enabled = true
+				c.Fan.Pin = "GPIO18"
+				c.Fan.Curve = []FanPoint{{Temp: 60, Duty: 50}, {Temp: 50, Duty: 100}}
+			},
+			wantErr: true,
+			errMsg:  "fan.curve temperatures must be ascending",
+		},
+		{
+			name: "fan duty above 100",
+			modify: func(c *Config) {
+				c.Fan.Enabled = true
+				c.Fan.Pin = "GPIO18"
+				c.Fan.Curve = []FanPoint{{Temp: 60, Duty: 150}}
+			},
+			wantErr: true,
+			errMsg:  "fan.curve[0].duty must be between 0 and 100",
+		},
+		{
+			name: "fan enabled with defaults",
+			modify: func(c *Config) {
+				c.Fan.Enabled = true
+				c.Fan.Pin = "GPIO18"
+			},
+			wantErr: false,
+		},
+		{
+			name: "mirrored character display",
+			modify: func(c *Config) {
+				c.Display.Type = "hd44780_16x2"
+				c.Display.Width = 80
+				c.Display.Height = 16
+				c.Display.I2CAddress = "0x27"
+				c.Display.MirrorX = true
+			},
+			wantErr: true,
+			errMsg:  "display.mirror_x and mirror_y are not supported",
+		},
+		{
+			name: "mirrored oled",
+			modify: func(c *Config) {
+				c.Display.MirrorX = true
+				c.Display.MirrorY = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "st7735 unknown variant",
+			modify: func(c *Config) {
+				c.Display.Type = "st7735"
+				c.Display.Width, c.Display.Height = 128, 160
+				c.Display.SPIBus, c.Display.DCPin = "SPI0.0", "GPIO24"
+				c.Display.Variant = "bluetab"
+			},
+			wantErr: true,
+			errMsg:  "display.variant must be one of",
+		},
+		{
+			name: "st7735 short gamma table",
+			modify: func(c *Config) {
+				c.Display.Type = "st7735"
+				c.Display.Width, c.Display.Height = 128, 160
+				c.Display.SPIBus, c.Display.DCPin = "SPI0.0", "GPIO24"
+				c.Display.GammaPositive = []int{1, 2, 3}
+			},
+			wantErr: true,
+			errMsg:  "display.gamma_positive must have 16 values",
+		},
+		{
+			name: "st7735 gamma value out of range",
+			modify: func(c *Config) {
+				c.Display.Type = "st7735"
+				c.Display.Width, c.Display.Height = 128, 160
+				c.Display.SPIBus, c.Display.DCPin = "SPI0.0", "GPIO24"
+				c.Display.GammaNegative = []int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 64}
+			},
+			wantErr: true,
+			errMsg:  "display.gamma_negative values must be between 0 and 63",
+		},
+		{
+			name: "st7735 green tab inverted",
+			modify: func(c *Config) {
+				c.Display.Type = "st7735"
+				c.Display.Width, c.Display.Height = 128, 160
+				c.Display.SPIBus, c.Display.DCPin = "SPI0.0", "GPIO24"
+				c.Display.Variant = "greentab"
+				c.Display.InvertColors = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "panel tuning on a non-st7735 type",
+			modify: func(c *Config) {
+				c.Display.InvertColors = true
+			},
+			wantErr: true,
+			errMsg:  "only apply to st7735 types",
+		},
+		{
+			name: "i2c address list with invalid entry",
+			modify: func(c *Config) {
+				c.Display.I2CAddresses = []string{"0x3C", "3D"}
+			},
+			wantErr: true,
+			errMsg:  "display.i2c_address must be in hex format",
+		},
+		{
+			name: "i2c speed below minimum",
+			modify: func(c *Config) {
+				c.Display.I2CSpeedHz = 1000
+			},
+			wantErr: true,
+			errMsg:  "display.i2c_speed_hz must be 0 or between",
+		},
+		{
+			name: "i2c speed 400kHz",
+			modify: func(c *Config) {
+				c.Display.I2CSpeedHz = 400_000
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative spi speed",
+			modify: func(c *Config) {
+				c.Display.SPISpeedHz = -1
+			},
+			wantErr: true,
+			errMsg:  "display.spi_speed_hz must be between 0 and",
+		},
+		{
+			name: "spi speed above 125MHz",
+			modify: func(c *Config) {
+				c.Display.SPISpeedHz = 200_000_000
+			},
+			wantErr: true,
+			errMsg:  "display.spi_speed_hz must be between 0 and",
+		},
+		{
+			name: "ssd1309 over SPI without dc pin",
+			modify: func(c *Config) {
+				c.Display.Type = "ssd1309_spi"
+				c.Display.SPIBus = "SPI0.0"
+			},
+			wantErr: true,
+			errMsg:  "display.dc_pin cannot be empty",
+		},
+		{
+			name: "sh1107 with landscape dimensions",
+			modify: func(c *Config) {
+				c.Display.Type = "sh1107_64x128"
+				c.Display.Width = 128
+				c.Display.Height = 64
+			},
+			wantErr: true,
+			errMsg:  "don't match type sh1107_64x128",
+		},
+		{
+			name: "e-paper without busy pin",
+			modify: func(c *Config) {
+				c.Display.Type = "ssd1680"
+				c.Display.Width = 250
+				c.Display.Height = 122
+				c.Display.SPIBus = "SPI0.0"
+				c.Display.DCPin = "GPIO25"
+			},
+			wantErr: true,
+			errMsg:  "display.busy_pin cannot be empty",
+		},
+		{
+			name: "hd44780 without i2c address",
+			modify: func(c *Config) {
+				c.Display.Type = "hd44780_16x2"
+				c.Display.Width = 80
+				c.Display.Height = 16
+				c.Display.I2CAddress = ""
+			},
+			wantErr: true,
+			errMsg:  "display.i2c_address cannot be empty",
+		},
+		{
+			name: "fbdev with relative device",
+			modify: func(c *Config) {
+				c.Display.Type = "fbdev"
+				c.Display.Width = 320
+				c.Display.Height = 240
+				c.Display.FBDevice = "fb0"
+			},
+			wantErr: true,
+			errMsg:  "display.fb_device must be an absolute path",
+		},
+		{
+			name: "preview display too large",
+			modify: func(c *Config) {
+				c.Display.Type = "window"
+				c.Display.Width = 2048
+				c.Display.Height = 64
+			},
+			wantErr: true,
+			errMsg:  "must be at most 1024x1024 for type window",
+		},
+		{
+			name: "window scale out of range",
+			modify: func(c *Config) {
+				c.Display.Type = "window_colour"
+				c.Display.Width = 160
+				c.Display.Height = 80
+				c.Display.WindowScale = 32
+			},
+			wantErr: true,
+			errMsg:  "display.window_scale must be 0-16",
+		},
+		{
+			name: "terminal with custom size",
+			modify: func(c *Config) {
+				c.Display.Type = "terminal"
+				c.Display.Width = 96
+				c.Display.Height = 16
+			},
+			wantErr: false,
+		},
+		{
+			name: "control socket with relative path",
+			modify: func(c *Config) {
+				c.Control.Enabled = true
+				c.Control.Socket = "i2c-display.sock"
+			},
+			wantErr: true,
+			errMsg:  "control.socket must be an absolute path",
+		},
+		{
+			name: "control socket enabled",
+			modify: func(c *Config) {
+				c.Control.Enabled = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "exec page without command",
+			modify: func(c *Config) {
+				c.Pages.Exec = []ExecPageConfig{{Title: "Mail", Interval: "1m"}}
+			},
+			wantErr: true,
+			errMsg:  "pages.exec[0].command",
+		},
+		{
+			name: "exec pages with duplicate titles",
+			modify: func(c *Config) {
+				c.Pages.Exec = []ExecPageConfig{
+					{Title: "Mail", Command: []string{"mailq"}, Interval: "1m"},
+					{Title: "Mail", Command: []string{"postqueue", "-p"}, Interval: "1m"},
+				}
 			},
 			wantErr: true,
-			errMsg:  "max_interfaces_per_page must be positive",
+			errMsg:  "used by another exec page",
 		},
 		{
-			name: "invalid log level",
+			name: "exec page with invalid interval",
 			modify: func(c *Config) {
-				c.Logging.Level = "verbose"
+				c.Pages.Exec = []ExecPageConfig{{Title: "Mail", Command: []string{"mailq"}, Interval: "often"}}
 			},
 			wantErr: true,
-			errMsg:  "logging.level must be one of",
+			errMsg:  "pages.exec[0].interval",
+		},
+		{
+			name: "valid exec page",
+			modify: func(c *Config) {
+				c.Pages.Exec = []ExecPageConfig{{Title: "Mail", Command: []string{"mailq"}, Interval: "1m", Timeout: "5s"}}
+			},
+			wantErr: false,
+		},
+		{
+			name: "render reports with zero sampling",
+			modify: func(c *Config) {
+				c.Logging.RenderReports = true
+				c.Logging.RenderReportEvery = 0
+			},
+			wantErr: true,
+			errMsg:  "logging.render_report_every",
+		},
+		{
+			name: "night mode with invalid start",
+			modify: func(c *Config) {
+				c.NightMode.Enabled = true
+				c.NightMode.Start = "25:00"
+			},
+			wantErr: true,
+			errMsg:  "night_mode.start",
+		},
+		{
+			name: "night mode with equal start and end",
+			modify: func(c *Config) {
+				c.NightMode.Enabled = true
+				c.NightMode.Start = "07:00"
+				c.NightMode.End = "07:00"
+			},
+			wantErr: true,
+			errMsg:  "must differ",
+		},
+		{
+			name: "valid night mode",
+			modify: func(c *Config) {
+				c.NightMode.Enabled = true
+				c.NightMode.ClockOnly = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "buttons enabled without pins",
+			modify: func(c *Config) {
+				c.Buttons.Enabled = true
+			},
+			wantErr: true,
+			errMsg:  "buttons.pause_pin or buttons.hold_pin is required",
+		},
+		{
+			name: "buttons sharing a pin",
+			modify: func(c *Config) {
+				c.Buttons.Enabled = true
+				c.Buttons.PausePin = "GPIO17"
+				c.Buttons.HoldPin = "GPIO17"
+			},
+			wantErr: true,
+			errMsg:  "must be different pins",
+		},
+		{
+			name: "invalid button hold page",
+			modify: func(c *Config) {
+				c.Buttons.Enabled = true
+				c.Buttons.HoldPin = "GPIO27"
+				c.Buttons.HoldPage = "weather"
+			},
+			wantErr: true,
+			errMsg:  "buttons.hold_page must be one of",
+		},
+		{
+			name: "invalid button debounce",
+			modify: func(c *Config) {
+				c.Buttons.Enabled = true
+				c.Buttons.PausePin = "GPIO17"
+				c.Buttons.Debounce = "-5ms"
+			},
+			wantErr: true,
+			errMsg:  "buttons.debounce must not be negative",
+		},
+		{
+			name: "unknown page duration type",
+			modify: func(c *Config) {
+				c.Pages.Durations = map[string]string{"weather": "5s"}
+			},
+			wantErr: true,
+			errMsg:  "pages.durations key must be one of",
+		},
+		{
+			name: "invalid page duration",
+			modify: func(c *Config) {
+				c.Pages.Durations = map[string]string{PageSystem: "long"}
+			},
+			wantErr: true,
+			errMsg:  "invalid pages.durations.system",
+		},
+		{
+			name: "unknown disabled page type",
+			modify: func(c *Config) {
+				c.Pages.Disabled = []string{"weather"}
+			},
+			wantErr: true,
+			errMsg:  "pages.disabled entries must be one of",
+		},
+		{
+			name: "system and network pages disabled",
+			modify: func(c *Config) {
+				c.Pages.Disabled = []string{PageSystem, PageNetwork}
+			},
+			wantErr: true,
+			errMsg:  "cannot disable both system and network pages",
+		},
+		{
+			name: "transition longer than a page duration",
+			modify: func(c *Config) {
+				c.Transitions.Enabled = true
+				c.Pages.Durations = map[string]string{PageNetwork: "200ms"}
+			},
+			wantErr: true,
+			errMsg:  "must be shorter than pages.durations.network",
+		},
+		{
+			name: "unknown refresh interval source",
+			modify: func(c *Config) {
+				c.Pages.RefreshIntervals = map[string]string{"gpu": "5s"}
+			},
+			wantErr: true,
+			errMsg:  "pages.refresh_intervals key must be one of",
+		},
+		{
+			name: "invalid source refresh interval",
+			modify: func(c *Config) {
+				c.Pages.RefreshIntervals = map[string]string{SourceDisk: "soon"}
+			},
+			wantErr: true,
+			errMsg:  "invalid pages.refresh_intervals.disk",
+		},
+		{
+			name: "non-positive source refresh interval",
+			modify: func(c *Config) {
+				c.Pages.RefreshIntervals = map[string]string{SourceNetwork: "0s"}
+			},
+			wantErr: true,
+			errMsg:  "pages.refresh_intervals.network must be positive",
+		},
+		{
+			name: "invalid transition type",
+			modify: func(c *Config) {
+				c.Transitions.Enabled = true
+				c.Transitions.Type = "spin"
+			},
+			wantErr: true,
+			errMsg:  "transitions.type must be one of",
+		},
+		{
+			name: "transition longer than rotation interval",
+			modify: func(c *Config) {
+				c.Transitions.Enabled = true
+				c.Transitions.Duration = "10s"
+			},
+			wantErr: true,
+			errMsg:  "transitions.duration (10s) must be shorter than pages.rotation_interval (5s)",
+		},
+		{
+			name: "clock screensaver not dimmer than normal",
+			modify: func(c *Config) {
+				c.ScreenSaver.Enabled = true
+				c.ScreenSaver.Mode = "clock"
+				c.ScreenSaver.DimBrightness = 200
+				c.ScreenSaver.NormalBrightness = 100
+			},
+			wantErr: true,
+			errMsg:  "screensaver.dim_brightness (200) must be less than normal_brightness (100)",
+		},
+		{
+			name: "shift screensaver with too many pixels",
+			modify: func(c *Config) {
+				c.ScreenSaver.Enabled = true
+				c.ScreenSaver.Mode = "shift"
+				c.ScreenSaver.ShiftPixels = 20
+			},
+			wantErr: true,
+			errMsg:  "screensaver.shift_pixels must be 1-8",
+		},
+		{
+			name: "invalid auto-brightness sensor",
+			modify: func(c *Config) {
+				c.AutoBright.Enabled = true
+				c.AutoBright.Sensor = "tsl2591"
+			},
+			wantErr: true,
+			errMsg:  "auto_brightness.sensor must be one of",
+		},
+		{
+			name: "auto-brightness min above max",
+			modify: func(c *Config) {
+				c.AutoBright.Enabled = true
+				c.AutoBright.MinBrightness = 200
+				c.AutoBright.MaxBrightness = 100
+			},
+			wantErr: true,
+			errMsg:  "auto_brightness.min_brightness (200) cannot exceed max_brightness (100)",
+		},
+		{
+			name: "auto display type",
+			modify: func(c *Config) {
+				c.Display.Type = DisplayTypeAuto
+				c.Display.Width = 0
+				c.Display.Height = 0
+			},
+			wantErr: false,
+		},
+		{
+			name: "auto display type requires i2c bus",
+			modify: func(c *Config) {
+				c.Display.Type = DisplayTypeAuto
+				c.Display.I2CBus = ""
+			},
+			wantErr: true,
+			errMsg:  "display.i2c_bus cannot be empty",
+		},
+		{
+			name: "fault injection rate out of range",
+			modify: func(c *Config) {
+				c.Display.FaultInjectionRate = 1.5
+			},
+			wantErr: true,
+			errMsg:  "display.fault_injection_rate must be between 0 and 1",
+		},
+		{
+			name: "negative display reinit threshold",
+			modify: func(c *Config) {
+				c.Display.ReinitAfterErrors = -1
+			},
+			wantErr: true,
+			errMsg:  "display.reinit_after_errors cannot be negative",
+		},
+		{
+			name: "negative display stall refreshes",
+			modify: func(c *Config) {
+				c.Display.StallRefreshes = -1
+			},
+			wantErr: true,
+			errMsg:  "display.stall_refreshes cannot be negative",
+		},
+		{
+			name: "thermal shutdown without command",
+			modify: func(c *Config) {
+				c.Thermal.Enabled = true
+				c.Thermal.Command = nil
+			},
+			wantErr: true,
+			errMsg:  "thermal_shutdown.command cannot be empty",
+		},
+		{
+			name: "thermal shutdown with zero samples",
+			modify: func(c *Config) {
+				c.Thermal.Enabled = true
+				c.Thermal.Samples = 0
+			},
+			wantErr: true,
+			errMsg:  "thermal_shutdown.samples must be at least 1",
+		},
+		{
+			name: "invalid alert webhook url",
+			modify: func(c *Config) {
+				c.Alerts.Enabled = true
+				c.Alerts.Notify.WebhookURL = "ftp://example.com/hook"
+			},
+			wantErr: true,
+			errMsg:  "alerts.notify.webhook_url must be an http or https URL",
+		},
+		{
+			name: "invalid backlight max daily on",
+			modify: func(c *Config) {
+				c.Backlight.Enabled = true
+				c.Backlight.MaxDailyOn = "forever"
+			},
+			wantErr: true,
+			errMsg:  "backlight.max_daily_on is not a valid duration",
+		},
+		{
+			name: "alert rule with unknown metric",
+			modify: func(c *Config) {
+				c.Alerts.Enabled = true
+				c.Alerts.Rules = []AlertRuleConfig{{Metric: "swap", Threshold: 50}}
+			},
+			wantErr: true,
+			errMsg:  "alerts.rules[0].metric must be one of",
+		},
+		{
+			name: "alert rule with invalid operator",
+			modify: func(c *Config) {
+				c.Alerts.Enabled = true
+				c.Alerts.Rules = []AlertRuleConfig{{Metric: "disk", Operator: "==", Threshold: 50}}
+			},
+			wantErr: true,
+			errMsg:  "alerts.rules[0].operator must be one of",
+		},
+		{
+			name: "temperature sensor without name",
+			modify: func(c *Config) {
+				c.SystemInfo.TemperatureSensors = []TemperatureSensorConfig{{Source: "vcgencmd"}}
+			},
+			wantErr: true,
+			errMsg:  "temperature_sensors[0].name cannot be empty",
+		},
+		{
+			name: "temperature sensor without source",
+			modify: func(c *Config) {
+				c.SystemInfo.TemperatureSensors = []TemperatureSensorConfig{{Name: "GPU"}}
+			},
+			wantErr: true,
+			errMsg:  "temperature_sensors[0].source cannot be empty",
 		},
 	}
 
@@ -176,6 +1282,26 @@ func TestGetIntervals(t *testing.T) {
 	}
 }
 
+func TestHistorySamples(t *testing.T) {
+	tests := []struct {
+		retention, refresh string
+		want               int
+	}{
+		{"", "1s", 301},
+		{"1h", "2s", 1801},
+		{"90s", "7s", 14},
+		{"720h", "1s", MaxHistorySamples},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.History.Retention = tt.retention
+		cfg.Pages.RefreshInterval = tt.refresh
+		if got := cfg.HistorySamples(); got != tt.want {
+			t.Errorf("HistorySamples() with retention %q every %s = %d, want %d", tt.retention, tt.refresh, got, tt.want)
+		}
+	}
+}
+
 func TestLoad(t *testing.T) {
 	// Create a temporary directory for test files
 	tmpDir := t.TempDir()
@@ -318,6 +1444,25 @@ func TestLoad(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:    "unknown key ignored",
+			content: `{"pages": {"rotaton_interval": "3s"}}`,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Pages.RotationInterval != Default().Pages.RotationInterval {
+					t.Errorf("expected the default rotation interval, got %s", cfg.Pages.RotationInterval)
+				}
+			},
+		},
+		{
+			name:    "unknown key in strict mode",
+			content: `{"strict": true, "pages": {"rotaton_interval": "3s"}}`,
+			wantErr: true,
+		},
+		{
+			name: "strict mode",
+			content: `{"strict": true, "display": {"i2c_address": ["0x3C", "0x3D"], "Width": 128},
+				"pages": {"exec": [{"title": "Uptime", "command": ["uptime"], "interval": "1m"}], "durations": {"system": "10s"}}}`,
+		},
 		{
 			name:    "invalid json",
 			content: invalidJSON,
@@ -445,7 +1590,14 @@ func TestIsI2CAndIsSPI(t *testing.T) {
 		{"ssd1306_128x32", true, false},
 		{"ssd1306_128x64", true, false},
 		{"ssd1306_96x16", true, false},
+		{"ssd1309", true, false},
+		{"ssd1309_128x64", true, false},
+		{"ssd1309_spi", false, true},
+		{"ssd1309_spi_128x64", false, true},
+		{"ssd1305_128x32", true, false},
 		{"sh1106", true, false},
+		{"sh1107_64x128", true, false},
+		{"sh1107_128x128", true, false},
 		{"sh1106_128x64", true, false},
 		{"ssd1327", true, false},
 		{"ssd1327_128x128", true, false},
@@ -455,7 +1607,20 @@ func TestIsI2CAndIsSPI(t *testing.T) {
 		{"st7735_128x160", false, true},
 		{"st7735_128x128", false, true},
 		{"st7735_160x80", false, true},
+		{"st7789", false, true},
+		{"st7789_240x320", false, true},
+		{"ili9341", false, true},
+		{"ili9341_240x320", false, true},
 		{"uctronics_colour", true, false},
+		{"terminal", false, false},
+		{"terminal_colour", false, false},
+		{"window", false, false},
+		{"window_colour", false, false},
+		{"fbdev", false, false},
+		{"hd44780_16x2", true, false},
+		{"hd44780_20x4", true, false},
+		{"ssd1680", false, true},
+		{"ssd1680_250x122", false, true},
 	}
 
 	for _, tt := range tests {
@@ -552,3 +1717,130 @@ func containsMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+func TestDisplayConfigI2CAddressList(t *testing.T) {
+	tests := []struct {
+		name       string
+		json       string
+		wantAddr   string
+		candidates []string
+		wantErr    bool
+	}{
+		{name: "single address", json: `{"i2c_address": "0x3D"}`, wantAddr: "0x3D", candidates: []string{"0x3D"}},
+		{name: "list", json: `{"i2c_address": ["0x3C", "0x3D"]}`, wantAddr: "0x3C", candidates: []string{"0x3C", "0x3D"}},
+		{name: "one-element list", json: `{"i2c_address": ["0x27"]}`, wantAddr: "0x27", candidates: []string{"0x27"}},
+		{name: "absent keeps default", json: `{"type": "ssd1306"}`, wantAddr: "0x3C", candidates: []string{"0x3C"}},
+		{name: "number", json: `{"i2c_address": 60}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := Default().Display
+			err := json.Unmarshal([]byte(tt.json), &dc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if dc.I2CAddress != tt.wantAddr {
+				t.Errorf("I2CAddress = %q, want %q", dc.I2CAddress, tt.wantAddr)
+			}
+			if got := dc.AddressCandidates(); !reflect.DeepEqual(got, tt.candidates) {
+				t.Errorf("AddressCandidates() = %v, want %v", got, tt.candidates)
+			}
+		})
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	data := `{"rotaton_interval": 1, "pages": {"exec": [{"title": "x"}, {"comand": ["ls"]}]},
+		"display": {"i2c_address": ["0x3C"], "i2c_adress": "0x3D"}}`
+	got, err := unknownKeys([]byte(data))
+	if err != nil {
+		t.Fatalf("unknownKeys() failed: %v", err)
+	}
+	want := []string{"display.i2c_adress", "pages.exec[1].comand", "rotaton_interval"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unknownKeys() = %q, want %q", got, want)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	data, err := json.Marshal(Schema())
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %v", err)
+	}
+	var schema struct {
+		Properties map[string]struct {
+			Type       string `json:"type"`
+			Properties map[string]struct {
+				Type    string `json:"type"`
+				Default any    `json:"default"`
+				OneOf   []any  `json:"oneOf"`
+			} `json:"properties"`
+			AdditionalProperties bool `json:"additionalProperties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("failed to decode schema: %v", err)
+	}
+
+	pages := schema.Properties["pages"]
+	if pages.Type != "object" || pages.AdditionalProperties {
+		t.Errorf("expected pages to be a closed object, got %+v", pages)
+	}
+	if p := pages.Properties["rotation_interval"]; p.Type != "string" || p.Default != Default().Pages.RotationInterval {
+		t.Errorf("unexpected rotation_interval schema %+v", p)
+	}
+	if p := schema.Properties["display"].Properties["i2c_address"]; len(p.OneOf) != 2 {
+		t.Errorf("expected i2c_address to take an address or a list, got %+v", p)
+	}
+	if _, ok := schema.Properties["display"].Properties["I2CAddresses"]; ok {
+		t.Error("expected fields not read from the file to be left out")
+	}
+}
+
+func TestSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"display": {"type": "ssd1306_128x32", "height": 64, "i2c_address": ["0x3C", "0x3D"]}, "pages": {"exec": [{"title": "Uptime", "command": ["uptime"], "interval": "1m"}]},
+		"mqtt": {"password": "hunter2"}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("I2C_DISPLAY_CONFIG_PATH", path)
+	cfg, err := LoadWithPriority("")
+	if err != nil {
+		t.Fatalf("LoadWithPriority() failed: %v", err)
+	}
+
+	settings := map[string]Setting{}
+	for _, s := range cfg.Settings() {
+		settings[s.Path] = s
+	}
+	fromFile := path + " ($I2C_DISPLAY_CONFIG_PATH)"
+	tests := []struct {
+		path, source string
+		value        any
+	}{
+		{"display.type", fromFile, "ssd1306_128x32"},
+		{"display.height", SourceDisplayType + " ssd1306_128x32", 32},
+		{"display.i2c_address", fromFile, []string{"0x3C", "0x3D"}},
+		{"pages.rotation_interval", SourceDefault, Default().Pages.RotationInterval},
+		{"pages.exec", fromFile, cfg.Pages.Exec},
+		{"mqtt.password", fromFile, maskedSecret},
+	}
+	for _, tt := range tests {
+		got, ok := settings[tt.path]
+		if !ok {
+			t.Errorf("missing setting %s", tt.path)
+			continue
+		}
+		if got.Source != tt.source || !reflect.DeepEqual(got.Value, tt.value) {
+			t.Errorf("%s = %v from %q, want %v from %q", tt.path, got.Value, got.Source, tt.value, tt.source)
+		}
+	}
+	if _, ok := settings["pages"]; ok {
+		t.Error("expected sections to be listed by their settings")
+	}
+}
@@ -1,6 +1,10 @@
 package config
 
-import "strings"
+import (
+	"maps"
+	"slices"
+	"strings"
+)
 
 // DisplaySpec holds the specifications for a display type
 type DisplaySpec struct {
@@ -8,42 +12,95 @@ type DisplaySpec struct {
 	Height int
 }
 
+// DisplayTypeAuto requests I2C display auto-detection at startup
+const DisplayTypeAuto = "auto"
+
+// DisplayTypeFBDev draws on a Linux framebuffer device such as an HDMI or
+// DSI screen
+const DisplayTypeFBDev = "fbdev"
+
+// displaySpecs are the dimensions of each display type
+var displaySpecs = map[string]DisplaySpec{
+	// SSD1306 family (fully supported via periph.io)
+	"ssd1306":        {Width: 128, Height: 64},
+	"ssd1306_128x64": {Width: 128, Height: 64},
+	"ssd1306_128x32": {Width: 128, Height: 32},
+	"ssd1306_96x16":  {Width: 96, Height: 16},
+
+	// SH1106 family (via third-party driver)
+	"sh1106":        {Width: 128, Height: 64},
+	"sh1106_128x64": {Width: 128, Height: 64},
+
+	// SSD1309 (2.42" modules, I2C or 4-wire SPI) and SSD1305
+	"ssd1309":            {Width: 128, Height: 64},
+	"ssd1309_128x64":     {Width: 128, Height: 64},
+	"ssd1309_spi":        {Width: 128, Height: 64},
+	"ssd1309_spi_128x64": {Width: 128, Height: 64},
+	"ssd1305_128x32":     {Width: 128, Height: 32},
+	"ssd1305_128x64":     {Width: 128, Height: 64},
+
+	// SH1107 portrait OLEDs (I2C)
+	"sh1107":         {Width: 64, Height: 128},
+	"sh1107_64x128":  {Width: 64, Height: 128},
+	"sh1107_128x128": {Width: 128, Height: 128},
+
+	// SSD1327 (grayscale) - Driver needed
+	"ssd1327":         {Width: 128, Height: 128},
+	"ssd1327_128x128": {Width: 128, Height: 128},
+	"ssd1327_96x96":   {Width: 96, Height: 96},
+
+	// SSD1331 (color OLED) - Driver needed
+	"ssd1331":       {Width: 96, Height: 64},
+	"ssd1331_96x64": {Width: 96, Height: 64},
+
+	// ST7735 (color TFT via SPI)
+	"st7735":         {Width: 128, Height: 160},
+	"st7735_128x160": {Width: 128, Height: 160},
+	"st7735_128x128": {Width: 128, Height: 128},
+	"st7735_160x80":  {Width: 160, Height: 80},
+
+	// ST7789 (IPS TFT via SPI)
+	"st7789":         {Width: 240, Height: 240},
+	"st7789_240x240": {Width: 240, Height: 240},
+	"st7789_240x320": {Width: 240, Height: 320},
+
+	// ILI9341 (TFT via SPI), landscape by default
+	"ili9341":         {Width: 320, Height: 240},
+	"ili9341_320x240": {Width: 320, Height: 240},
+	"ili9341_240x320": {Width: 240, Height: 320},
+
+	// SSD1680 e-paper via SPI (Waveshare 2.13"), landscape
+	"ssd1680":         {Width: 250, Height: 122},
+	"ssd1680_250x122": {Width: 250, Height: 122},
+
+	// UCTRONICS (I2C-bridged ST7735 via onboard MCU)
+	"uctronics_colour": {Width: 160, Height: 80},
+
+	// HD44780 character LCDs (PCF8574 I2C backpack); sized as 5x8 pixel cells
+	"hd44780_16x2": {Width: 80, Height: 16},
+	"hd44780_20x4": {Width: 100, Height: 32},
+
+	// Terminal and window previews (no hardware); dimensions can be overridden
+	"terminal":        {Width: 128, Height: 64},
+	"terminal_colour": {Width: 160, Height: 80},
+	"window":          {Width: 128, Height: 64},
+	"window_colour":   {Width: 160, Height: 80},
+
+	// Linux framebuffer; the frame is scaled up to fill the screen
+	"fbdev": {Width: 320, Height: 240},
+}
+
 // GetDisplaySpec returns the dimensions for a display type
 func GetDisplaySpec(displayType string) (DisplaySpec, bool) {
-	specs := map[string]DisplaySpec{
-		// SSD1306 family (fully supported via periph.io)
-		"ssd1306":        {Width: 128, Height: 64},
-		"ssd1306_128x64": {Width: 128, Height: 64},
-		"ssd1306_128x32": {Width: 128, Height: 32},
-		"ssd1306_96x16":  {Width: 96, Height: 16},
-
-		// SH1106 family (via third-party driver)
-		"sh1106":        {Width: 128, Height: 64},
-		"sh1106_128x64": {Width: 128, Height: 64},
-
-		// SSD1327 (grayscale) - Driver needed
-		"ssd1327":         {Width: 128, Height: 128},
-		"ssd1327_128x128": {Width: 128, Height: 128},
-		"ssd1327_96x96":   {Width: 96, Height: 96},
-
-		// SSD1331 (color OLED) - Driver needed
-		"ssd1331":       {Width: 96, Height: 64},
-		"ssd1331_96x64": {Width: 96, Height: 64},
-
-		// ST7735 (color TFT via SPI)
-		"st7735":         {Width: 128, Height: 160},
-		"st7735_128x160": {Width: 128, Height: 160},
-		"st7735_128x128": {Width: 128, Height: 128},
-		"st7735_160x80":  {Width: 160, Height: 80},
-
-		// UCTRONICS (I2C-bridged ST7735 via onboard MCU)
-		"uctronics_colour": {Width: 160, Height: 80},
-	}
-
-	spec, ok := specs[displayType]
+	spec, ok := displaySpecs[displayType]
 	return spec, ok
 }
 
+// DisplayTypes returns the known display types, sorted, without "auto"
+func DisplayTypes() []string {
+	return slices.Sorted(maps.Keys(displaySpecs))
+}
+
 // ApplyDisplayDefaults applies default width/height based on display type
 // The display type is authoritative - dimensions are always set to match the type
 func (c *DisplayConfig) ApplyDisplayDefaults() {
@@ -57,6 +114,19 @@ func (c *DisplayConfig) ApplyDisplayDefaults() {
 		return // Unknown type, let validation handle it
 	}
 
+	// Previews can emulate any panel size and framebuffers scale the frame
+	// to the screen, so only fill in dimensions that were not given
+	if c.IsResizable() {
+		if c.Width <= 0 || c.Height <= 0 {
+			c.Width = spec.Width
+			c.Height = spec.Height
+		}
+		if c.IsFramebuffer() && c.FBDevice == "" {
+			c.FBDevice = "/dev/fb0"
+		}
+		return
+	}
+
 	// Always set width and height to match the display type
 	// This makes the type authoritative over explicit dimension values
 	c.Width = spec.Width
@@ -64,7 +134,7 @@ func (c *DisplayConfig) ApplyDisplayDefaults() {
 
 	// UCTRONICS displays use an I2C bridge MCU at address 0x18
 	if strings.HasPrefix(strings.ToLower(c.Type), "uctronics") {
-		c.I2CAddress = "0x18"
+		c.I2CAddress, c.I2CAddresses = "0x18", nil
 		if c.I2CBus == "" {
 			c.I2CBus = "/dev/i2c-1"
 		}
@@ -38,6 +38,27 @@ func TestGetDisplaySpec(t *testing.T) {
 			wantHeight:  16,
 			wantOK:      true,
 		},
+		{
+			name:        "hd44780_20x4",
+			displayType: "hd44780_20x4",
+			wantWidth:   100,
+			wantHeight:  32,
+			wantOK:      true,
+		},
+		{
+			name:        "sh1107 default",
+			displayType: "sh1107",
+			wantWidth:   64,
+			wantHeight:  128,
+			wantOK:      true,
+		},
+		{
+			name:        "ssd1305_128x32",
+			displayType: "ssd1305_128x32",
+			wantWidth:   128,
+			wantHeight:  32,
+			wantOK:      true,
+		},
 		{
 			name:        "st7735 default",
 			displayType: "st7735",
@@ -66,6 +87,20 @@ func TestGetDisplaySpec(t *testing.T) {
 			wantHeight:  80,
 			wantOK:      true,
 		},
+		{
+			name:        "st7789_240x320",
+			displayType: "st7789_240x320",
+			wantWidth:   240,
+			wantHeight:  320,
+			wantOK:      true,
+		},
+		{
+			name:        "ili9341 default",
+			displayType: "ili9341",
+			wantWidth:   320,
+			wantHeight:  240,
+			wantOK:      true,
+		},
 		{
 			name:        "uctronics_colour",
 			displayType: "uctronics_colour",
@@ -158,6 +193,42 @@ func TestApplyDisplayDefaults(t *testing.T) {
 				Height: 32, // Corrected to match type
 			},
 		},
+		{
+			name: "terminal keeps explicit dimensions",
+			config: DisplayConfig{
+				Type:   "terminal",
+				Width:  128,
+				Height: 32,
+			},
+			want: DisplayConfig{
+				Type:   "terminal",
+				Width:  128,
+				Height: 32,
+			},
+		},
+		{
+			name: "terminal_colour fills missing dimensions",
+			config: DisplayConfig{
+				Type: "terminal_colour",
+			},
+			want: DisplayConfig{
+				Type:   "terminal_colour",
+				Width:  160,
+				Height: 80,
+			},
+		},
+		{
+			name: "fbdev fills size and device",
+			config: DisplayConfig{
+				Type: "fbdev",
+			},
+			want: DisplayConfig{
+				Type:     "fbdev",
+				Width:    320,
+				Height:   240,
+				FBDevice: "/dev/fb0",
+			},
+		},
 		{
 			name: "default type to ssd1306",
 			config: DisplayConfig{
@@ -186,6 +257,9 @@ func TestApplyDisplayDefaults(t *testing.T) {
 			if tt.config.Height != tt.want.Height {
 				t.Errorf("Height = %v, want %v", tt.config.Height, tt.want.Height)
 			}
+			if tt.config.FBDevice != tt.want.FBDevice {
+				t.Errorf("FBDevice = %v, want %v", tt.config.FBDevice, tt.want.FBDevice)
+			}
 		})
 	}
 }
@@ -0,0 +1,92 @@
+package config
+
+import "reflect"
+
+// Where settings not read from a config file got their values
+const (
+	SourceDefault     = "default"
+	SourceDisplayType = "display type"
+)
+
+// Setting is one setting of the effective configuration
+type Setting struct {
+	Path   string // dotted key, e.g. "pages.rotation_interval"
+	Value  any
+	Source string // SourceDefault, SourceDisplayType and the type, or the file that set it
+}
+
+// Settings lists every setting with its value and where the value came
+// from, in the order of the config structs. Lists and maps are single
+// settings. The MQTT password and http_json header values are masked so the
+// list can be shared.
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	leafSettings("", reflect.ValueOf(*c), func(path string, v reflect.Value) {
+		source := c.sources[path]
+		if source == "" {
+			source = SourceDefault
+		}
+		value := v.Interface()
+		switch {
+		case path == "display.i2c_address" && len(c.Display.I2CAddresses) > 0:
+			value = c.Display.I2CAddresses
+		case path == "mqtt.password" && c.MQTT.Password != "":
+			value = maskedSecret
+		case path == "pages.http_json":
+			value = maskHeaders(c.Pages.HTTPJSON)
+		}
+		settings = append(settings, Setting{Path: path, Value: value, Source: source})
+	})
+	return settings
+}
+
+// maskedSecret replaces secrets in Settings
+const maskedSecret = "********"
+
+// maskHeaders returns pages with their header values masked, as they often
+// carry API keys
+func maskHeaders(pages []HTTPJSONPageConfig) []HTTPJSONPageConfig {
+	if pages == nil {
+		return nil
+	}
+	masked := make([]HTTPJSONPageConfig, len(pages))
+	for i, p := range pages {
+		masked[i] = p
+		if len(p.Headers) > 0 {
+			masked[i].Headers = make(map[string]string, len(p.Headers))
+			for name := range p.Headers {
+				masked[i].Headers[name] = maskedSecret
+			}
+		}
+	}
+	return masked
+}
+
+// markDisplayDefaults records the display settings that ApplyDisplayDefaults
+// changed from configured as coming from the display type
+func (c *Config) markDisplayDefaults(configured DisplayConfig) {
+	before := map[string]any{}
+	leafSettings("display", reflect.ValueOf(configured), func(path string, v reflect.Value) {
+		before[path] = v.Interface()
+	})
+	leafSettings("display", reflect.ValueOf(c.Display), func(path string, v reflect.Value) {
+		if !reflect.DeepEqual(before[path], v.Interface()) {
+			if c.sources == nil {
+				c.sources = map[string]string{}
+			}
+			c.sources[path] = SourceDisplayType + " " + c.Display.Type
+		}
+	})
+}
+
+// leafSettings calls leaf with the path and value of every setting in v,
+// found at path, descending into nested sections
+func leafSettings(path string, v reflect.Value, leaf func(path string, v reflect.Value)) {
+	if v.Kind() != reflect.Struct {
+		leaf(path, v)
+		return
+	}
+	for _, f := range jsonFields(v.Type()) {
+		leafSettings(joinPath(path, f.name), v.FieldByIndex(f.Index), leaf)
+	}
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SaveCalibration writes display.contrast and display.gamma into the
+// configuration file at path. Only those values are changed: the rest of
+// the file keeps its layout, key order and unknown keys. The file is
+// replaced once the result loads as a valid configuration.
+func SaveCalibration(path string, contrast int, gamma float64) error {
+	data, err := os.ReadFile(path) // #nosec G304 -- the path the configuration was loaded from
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	out, err := setKeys(data, "display", []rawMember{
+		{"contrast", strconv.Itoa(contrast)},
+		{"gamma", strconv.FormatFloat(gamma, 'f', -1, 64)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update config file: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, out, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if _, err := Load(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// rawMember is an object member whose value is JSON text
+type rawMember struct {
+	key   string
+	value string
+}
+
+// span is a byte range of a JSON document
+type span struct {
+	start, end int
+}
+
+// jsonObject locates the members of an object within a document
+type jsonObject struct {
+	members map[string]span // values by key
+	count   int             // number of members
+	lastEnd int             // end of the last value, or just after the opening brace
+	indent  string          // whitespace before the first key on its line
+}
+
+// edit replaces a span of a document with text
+type edit struct {
+	span
+	text string
+}
+
+// setKeys sets members of the top-level object named section to the given
+// values, replacing existing values in place and adding the others after
+// the section's last member, indented like the first. The section is added
+// if the document has none.
+func setKeys(data []byte, section string, values []rawMember) ([]byte, error) {
+	root, err := parseObject(data, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []edit
+	if s, ok := root.members[section]; ok {
+		obj, err := parseObject(data, s.start)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", section, err)
+		}
+		var missing []rawMember
+		for _, v := range values {
+			if at, ok := obj.members[v.key]; ok {
+				edits = append(edits, edit{at, v.value})
+			} else {
+				missing = append(missing, v)
+			}
+		}
+		if len(missing) > 0 {
+			edits = append(edits, obj.append(missing))
+		}
+	} else {
+		members := make([]string, len(values))
+		for i, v := range values {
+			members[i] = strconv.Quote(v.key) + ": " + v.value
+		}
+		edits = append(edits, root.append([]rawMember{{section, "{" + strings.Join(members, ", ") + "}"}}))
+	}
+
+	// Apply from the end so earlier offsets stay valid
+	slices.SortFunc(edits, func(a, b edit) int { return b.start - a.start })
+	out := slices.Clone(data)
+	for _, e := range edits {
+		out = slices.Concat(out[:e.start], []byte(e.text), out[e.end:])
+	}
+	return out, nil
+}
+
+// append returns the edit adding members after the object's last one
+func (o *jsonObject) append(members []rawMember) edit {
+	var b strings.Builder
+	for i, m := range members {
+		if o.count > 0 || i > 0 {
+			b.WriteString(",")
+			if o.indent != "" {
+				b.WriteString("\n" + o.indent)
+			} else {
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(strconv.Quote(m.key) + ": " + m.value)
+	}
+	return edit{span{o.lastEnd, o.lastEnd}, b.String()}
+}
+
+// parseObject locates the members of the object starting at data[start],
+// after any whitespace
+func parseObject(data []byte, start int) (*jsonObject, error) {
+	dec := json.NewDecoder(bytes.NewReader(data[start:]))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("expected a JSON object")
+	}
+	obj := &jsonObject{members: map[string]span{}, lastEnd: start + int(dec.InputOffset())}
+	for dec.More() {
+		if obj.count == 0 {
+			obj.indent = lineIndent(data, skipSpace(data, obj.lastEnd, ""))
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		valueStart := skipSpace(data, start+int(dec.InputOffset()), ":")
+		if err := skipValue(dec); err != nil {
+			return nil, err
+		}
+		obj.lastEnd = start + int(dec.InputOffset())
+		obj.members[key] = span{valueStart, obj.lastEnd}
+		obj.count++
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// skipValue reads the next value from dec, however deeply nested
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// skipSpace returns the offset of the first byte from i on that is neither
+// JSON whitespace nor in extra
+func skipSpace(data []byte, i int, extra string) int {
+	for i < len(data) && strings.IndexByte(" \t\r\n"+extra, data[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// lineIndent returns the whitespace between the start of the line holding
+// data[i] and i, or "" if i does not start its line
+func lineIndent(data []byte, i int) string {
+	lineStart := bytes.LastIndexByte(data[:i], '\n') + 1
+	indent := data[lineStart:i]
+	if lineStart == 0 || len(bytes.TrimLeft(indent, " \t")) > 0 {
+		return ""
+	}
+	return string(indent)
+}
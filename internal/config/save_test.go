@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetKeys(t *testing.T) {
+	values := []rawMember{{"contrast", "200"}, {"gamma", "1.2"}}
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "adds missing keys after the last member",
+			in: `{
+  "display": {
+    "type": "st7735",
+    "rotation": 1
+  },
+  "pages": {"rotation_interval": "5s"}
+}
+`,
+			want: `{
+  "display": {
+    "type": "st7735",
+    "rotation": 1,
+    "contrast": 200,
+    "gamma": 1.2
+  },
+  "pages": {"rotation_interval": "5s"}
+}
+`,
+		},
+		{
+			name: "replaces existing values in place",
+			in:   `{"display": {"gamma": 2, "type": "st7735", "contrast": 10}}`,
+			want: `{"display": {"gamma": 1.2, "type": "st7735", "contrast": 200}}`,
+		},
+		{
+			name: "one line objects stay on one line",
+			in:   `{"display": {"type": "st7735"}}`,
+			want: `{"display": {"type": "st7735", "contrast": 200, "gamma": 1.2}}`,
+		},
+		{
+			name: "nested values are skipped",
+			in:   `{"display": {"gamma_positive": [1, [2]], "x": {"contrast": 5}}}`,
+			want: `{"display": {"gamma_positive": [1, [2]], "x": {"contrast": 5}, "contrast": 200, "gamma": 1.2}}`,
+		},
+		{
+			name: "adds the section",
+			in:   "{\n  \"logging\": {}\n}",
+			want: "{\n  \"logging\": {},\n  \"display\": {\"contrast\": 200, \"gamma\": 1.2}\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setKeys([]byte(tt.in), "display", values)
+			if err != nil {
+				t.Fatalf("setKeys failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	for _, in := range []string{`[]`, `{"display": 3}`, `{"display": {`} {
+		if _, err := setKeys([]byte(in), "display", values); err == nil {
+			t.Errorf("setKeys(%s) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestSaveCalibration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"display": {"type": "ssd1306"}}`), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveCalibration(path, 180, 1.4); err != nil {
+		t.Fatalf("SaveCalibration failed: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Display.Contrast != 180 || cfg.Display.Gamma != 1.4 {
+		t.Errorf("saved contrast %d and gamma %g, want 180 and 1.4", cfg.Display.Contrast, cfg.Display.Gamma)
+	}
+	if cfg.Path() != path {
+		t.Errorf("Path() = %q, want %q", cfg.Path(), path)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o640 {
+		t.Errorf("expected the file mode to be kept, got %v (%v)", info.Mode(), err)
+	}
+
+	// Values that do not validate leave the file alone
+	if err := SaveCalibration(path, 180, 9); err == nil {
+		t.Error("expected an invalid gamma to be rejected")
+	}
+	if cfg, err := Load(path); err != nil || cfg.Display.Gamma != 1.4 {
+		t.Errorf("expected the file to be unchanged after a failed save")
+	}
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// schemaOverrides replaces the schema derived from a field's Go type, for
+// fields decoded by hand
+var schemaOverrides = map[string]map[string]any{
+	// A single address or a list to try in order; see DisplayConfig.UnmarshalJSON
+	"display.i2c_address": {
+		"oneOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	},
+}
+
+// Schema returns a JSON schema (draft 2020-12) describing the config file,
+// with the defaults from Default
+func Schema() map[string]any {
+	schema := typeSchema("", reflect.TypeFor[Config](), reflect.ValueOf(*Default()))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "i2c-display configuration"
+	return schema
+}
+
+// typeSchema returns the schema of a value of type t found at path, with
+// def's value as the default of each setting that has one
+func typeSchema(path string, t reflect.Type, def reflect.Value) map[string]any {
+	if s, ok := schemaOverrides[path]; ok {
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		props := map[string]any{}
+		for _, f := range jsonFields(t) {
+			var fieldDef reflect.Value
+			if def.IsValid() {
+				fieldDef = def.FieldByIndex(f.Index)
+			}
+			props[f.name] = typeSchema(joinPath(path, f.name), f.Type, fieldDef)
+		}
+		return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	case reflect.Slice:
+		return withDefault(map[string]any{"type": "array", "items": typeSchema(path+"[]", t.Elem(), reflect.Value{})}, def)
+	case reflect.Map:
+		return withDefault(map[string]any{"type": "object", "additionalProperties": typeSchema(path+".*", t.Elem(), reflect.Value{})}, def)
+	case reflect.Bool:
+		return withDefault(map[string]any{"type": "boolean"}, def)
+	case reflect.String:
+		return withDefault(map[string]any{"type": "string"}, def)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return withDefault(map[string]any{"type": "integer"}, def)
+	case reflect.Float32, reflect.Float64:
+		return withDefault(map[string]any{"type": "number"}, def)
+	default:
+		return map[string]any{}
+	}
+}
+
+// withDefault adds def to schema as its default unless it is the zero value
+func withDefault(schema map[string]any, def reflect.Value) map[string]any {
+	if def.IsValid() && !def.IsZero() {
+		schema["default"] = def.Interface()
+	}
+	return schema
+}
+
+// jsonField is a struct field read from the config file
+type jsonField struct {
+	reflect.StructField
+	name string
+}
+
+// jsonFields returns the fields of t that encoding/json reads, by key
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{StructField: f, name: name})
+	}
+	return fields
+}
+
+// unknownKeys returns the paths of the keys in a config file that no
+// setting reads, e.g. "pages.rotaton_interval". Keys match settings
+// case-insensitively, as encoding/json does.
+func unknownKeys(data []byte) ([]string, error) {
+	var unknown []string
+	err := walkKeys(data, func(path string, known bool) {
+		if !known {
+			unknown = append(unknown, path)
+		}
+	})
+	slices.Sort(unknown)
+	return unknown, err
+}
+
+// walkKeys calls visit with the path of every key in a config file, by the
+// name of the setting it sets, and whether a setting reads it
+func walkKeys(data []byte, visit func(path string, known bool)) error {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	walkDoc("", reflect.TypeFor[Config](), doc, visit)
+	return nil
+}
+
+// walkDoc calls visit for the keys of doc, found at path and decoded into
+// type t
+func walkDoc(path string, t reflect.Type, doc any, visit func(path string, known bool)) {
+	if _, ok := schemaOverrides[path]; ok {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for key, value := range obj {
+			i := slices.IndexFunc(fields, func(f jsonField) bool { return f.name == key })
+			if i < 0 {
+				i = slices.IndexFunc(fields, func(f jsonField) bool { return strings.EqualFold(f.name, key) })
+			}
+			if i < 0 {
+				visit(joinPath(path, key), false)
+				continue
+			}
+			visit(joinPath(path, fields[i].name), true)
+			walkDoc(joinPath(path, fields[i].name), fields[i].Type, value, visit)
+		}
+	case reflect.Slice:
+		arr, _ := doc.([]any)
+		for i, value := range arr {
+			walkDoc(fmt.Sprintf("%s[%d]", path, i), t.Elem(), value, visit)
+		}
+	case reflect.Map:
+		obj, _ := doc.(map[string]any)
+		for key, value := range obj {
+			walkDoc(joinPath(path, key), t.Elem(), value, visit)
+		}
+	}
+}
+
+// joinPath appends key to a dotted config path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
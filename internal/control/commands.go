@@ -0,0 +1,55 @@
+package control
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultMessageDuration is how long a message is shown without a duration
+const defaultMessageDuration = 30 * time.Second
+
+// Commands served by the daemon
+const (
+	CommandStatus      = "status"
+	CommandNext        = "next"
+	CommandShowMessage = "show-message"
+	CommandReload      = "reload"
+)
+
+// Status is the result of the status command
+type Status struct {
+	Version   string `json:"version"`
+	Uptime    string `json:"uptime"`
+	Page      int    `json:"page"` // index of the rotation page on screen
+	PageCount int    `json:"page_count"`
+	PageTitle string `json:"page_title"`
+	PageType  string `json:"page_type"`
+	Paused    bool   `json:"paused"`
+	Health    string `json:"health,omitempty"` // overall health, when health checking is enabled
+}
+
+// MessageArgs are the arguments of the show-message command. They match
+// the HTTP message API: duration defaults to 30s, font_size is small,
+// normal or large, and color is a name or #rrggbb.
+type MessageArgs struct {
+	Text     string `json:"text"`
+	Duration string `json:"duration,omitempty"`
+	FontSize string `json:"font_size,omitempty"`
+	Color    string `json:"color,omitempty"`
+}
+
+// Validate checks the arguments and returns how long to show the message
+func (a MessageArgs) Validate() (time.Duration, error) {
+	if strings.TrimSpace(a.Text) == "" {
+		return 0, fmt.Errorf("text is required")
+	}
+	if a.Duration == "" {
+		return defaultMessageDuration, nil
+	}
+	d, err := time.ParseDuration(a.Duration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %w", err)
+	}
+	return d, nil
+}
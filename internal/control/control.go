@@ -0,0 +1,171 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/unixsock"
+)
+
+const (
+	// DefaultSocket is where the daemon listens unless control.socket says otherwise
+	DefaultSocket = "/run/i2c-display.sock"
+
+	// maxRequestSize bounds a request line
+	maxRequestSize = 64 * 1024
+	// connTimeout bounds how long a client may take to send its request and
+	// read the response
+	connTimeout = 10 * time.Second
+)
+
+// Request is one control command. Each connection carries a single request
+// line and receives a single response line, both JSON.
+type Request struct {
+	Command string          `json:"command"`
+	Args    json.RawMessage `json:"args,omitempty"`
+}
+
+// Response is the reply to a Request
+type Response struct {
+	OK     bool            `json:"ok"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// HandlerFunc runs a command. args is the raw args object, or nil if the
+// request had none. The returned value is sent as the response result.
+type HandlerFunc func(args json.RawMessage) (any, error)
+
+// Server accepts control requests on a Unix socket
+type Server struct {
+	path     string
+	group    string
+	log      *logger.Logger
+	handlers map[string]HandlerFunc
+
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// NewServer creates a server listening on path once started. If group is
+// set the socket is owned by that group; either way it is mode 0660.
+func NewServer(path, group string, log *logger.Logger) *Server {
+	return &Server{
+		path:     path,
+		group:    group,
+		log:      log,
+		handlers: make(map[string]HandlerFunc),
+	}
+}
+
+// Handle registers fn for command. Must be called before Start.
+func (s *Server) Handle(command string, fn HandlerFunc) {
+	s.handlers[command] = fn
+}
+
+// Start creates the socket and begins accepting connections. A stale
+// socket left by a previous run is replaced, but a socket another daemon is
+// listening on, or a path that is not a socket, is refused.
+func (s *Server) Start() error {
+	listener, err := unixsock.Listen(s.path, s.group)
+	if err != nil {
+		return fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	s.listener = listener
+
+	s.wg.Add(1)
+	go s.serve()
+	s.log.With().Str("socket", s.path).Logger().Info("Control socket listening")
+	return nil
+}
+
+// Stop closes the socket, waits for in-flight requests and removes the
+// socket file
+func (s *Server) Stop() error {
+	if s.listener == nil {
+		return nil
+	}
+	err := s.listener.Close()
+	s.wg.Wait()
+	if rmErr := unixsock.Remove(s.path); rmErr != nil && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// serve accepts connections until the listener is closed
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.log.ErrorWithErr(err, "Control socket accept failed")
+			}
+			return
+		}
+		s.wg.Add(1)
+		go s.handleConn(conn)
+	}
+}
+
+// handleConn reads one request from conn and writes its response
+func (s *Server) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Errorf("PANIC in control socket handler: %v", r)
+		}
+	}()
+	_ = conn.SetDeadline(time.Now().Add(connTimeout))
+
+	resp := s.dispatch(conn)
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(Response{Error: fmt.Sprintf("failed to encode result: %v", err)})
+	}
+	_, _ = conn.Write(append(data, '\n'))
+}
+
+// dispatch reads the request and runs its handler
+func (s *Server) dispatch(conn net.Conn) Response {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxRequestSize)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return Response{Error: fmt.Sprintf("failed to read request: %v", err)}
+		}
+		return Response{Error: "empty request"}
+	}
+
+	var req Request
+	if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+		return Response{Error: fmt.Sprintf("invalid request: %v", err)}
+	}
+	fn, ok := s.handlers[req.Command]
+	if !ok {
+		return Response{Error: fmt.Sprintf("unknown command %q", req.Command)}
+	}
+
+	s.log.With().Str("command", req.Command).Logger().Debug("Control command")
+	result, err := fn(req.Args)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	resp := Response{OK: true}
+	if result != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return Response{Error: fmt.Sprintf("failed to encode result: %v", err)}
+		}
+		resp.Result = data
+	}
+	return resp
+}
@@ -0,0 +1,137 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+func startServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "ctl.sock")
+	s := NewServer(socket, "", logger.NewDefault())
+	s.Handle(CommandStatus, func(json.RawMessage) (any, error) {
+		return Status{Version: "1.2.3", PageCount: 4, PageTitle: "System"}, nil
+	})
+	s.Handle(CommandShowMessage, func(raw json.RawMessage) (any, error) {
+		var args MessageArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, err
+		}
+		if _, err := args.Validate(); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+	s.Handle(CommandReload, func(json.RawMessage) (any, error) {
+		return nil, errors.New("config file missing")
+	})
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Stop() })
+	return s, socket
+}
+
+func TestControlRoundTrip(t *testing.T) {
+	_, socket := startServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := Call(ctx, socket, CommandStatus, nil)
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	var status Status
+	if err := json.Unmarshal(result, &status); err != nil {
+		t.Fatalf("invalid status: %v", err)
+	}
+	if status.Version != "1.2.3" || status.PageCount != 4 || status.PageTitle != "System" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	if _, err := Call(ctx, socket, CommandShowMessage, MessageArgs{Text: "hi", Duration: "5s"}); err != nil {
+		t.Errorf("show-message failed: %v", err)
+	}
+	if _, err := Call(ctx, socket, CommandShowMessage, MessageArgs{Text: " "}); err == nil || !strings.Contains(err.Error(), "text is required") {
+		t.Errorf("expected empty text rejected, got %v", err)
+	}
+	if _, err := Call(ctx, socket, CommandReload, nil); err == nil || err.Error() != "config file missing" {
+		t.Errorf("expected handler error returned, got %v", err)
+	}
+	if _, err := Call(ctx, socket, "explode", nil); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("expected unknown command error, got %v", err)
+	}
+}
+
+func TestControlSocketLifecycle(t *testing.T) {
+	s, socket := startServer(t)
+
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("socket not created: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o660 {
+		t.Errorf("socket mode = %o, want 660", perm)
+	}
+
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("expected socket removed on stop, got %v", err)
+	}
+
+	// A stale socket left by a crashed daemon is replaced on start
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+	s2 := NewServer(socket, "", logger.NewDefault())
+	s2.Handle(CommandStatus, func(json.RawMessage) (any, error) { return Status{}, nil })
+	if err := s2.Start(); err != nil {
+		t.Fatalf("Start() over stale socket failed: %v", err)
+	}
+
+	// A second daemon does not take over a live socket
+	s3 := NewServer(socket, "", logger.NewDefault())
+	if err := s3.Start(); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("expected a live socket refused, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := Call(ctx, socket, CommandStatus, nil); err != nil {
+		t.Errorf("expected the first server still listening, got %v", err)
+	}
+	_ = s2.Stop()
+
+	// Nor does it remove a file that is not a socket
+	if err := os.WriteFile(socket, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s3.Start(); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Errorf("expected a regular file refused, got %v", err)
+	}
+	if data, err := os.ReadFile(socket); err != nil || string(data) != "data" {
+		t.Errorf("expected the file left alone, got %q: %v", data, err)
+	}
+}
+
+func TestMessageArgsValidate(t *testing.T) {
+	if d, err := (MessageArgs{Text: "x"}).Validate(); err != nil || d != defaultMessageDuration {
+		t.Errorf("expected default duration, got %v, %v", d, err)
+	}
+	if _, err := (MessageArgs{Text: "x", Duration: "soon"}).Validate(); err == nil {
+		t.Error("expected invalid duration rejected")
+	}
+}
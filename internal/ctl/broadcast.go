@@ -0,0 +1,57 @@
+package ctl
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of running a command against one host
+type Result struct {
+	Host   Host
+	Output string
+	Err    error
+}
+
+// HostFunc runs a command against a single host
+type HostFunc func(ctx context.Context, h Host) (string, error)
+
+// Broadcast runs fn against every host with at most parallel concurrent
+// calls, giving each call timeout from when it starts, so hosts waiting for
+// a free slot do not use up their time. A timeout of 0 sets no limit.
+// Results are returned in the same order as hosts.
+func Broadcast(ctx context.Context, hosts []Host, parallel int, timeout time.Duration, fn HostFunc) []Result {
+	if parallel <= 0 {
+		parallel = 1
+	}
+
+	results := make([]Result, len(hosts))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, h := range hosts {
+		wg.Add(1)
+		go func(i int, h Host) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = Result{Host: h, Err: ctx.Err()}
+				return
+			}
+			defer func() { <-sem }()
+
+			hostCtx := ctx
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				hostCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			out, err := fn(hostCtx, h)
+			results[i] = Result{Host: h, Output: out, Err: err}
+		}(i, h)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,98 @@
+package ctl
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Client issues control requests to display daemons over HTTP
+type Client struct {
+	HTTP     *http.Client
+	HTTPS    bool   // connect with TLS; configure it on HTTP's transport
+	Token    string // bearer token sent with each request, when set
+	Username string // basic auth user sent with each request, when set
+	Password string
+}
+
+// NewClient creates a control client
+func NewClient() *Client {
+	return &Client{HTTP: &http.Client{}}
+}
+
+// Do sends a request with an optional body to path on h and returns the response body.
+// Non-2xx responses are reported as errors including the body text.
+func (c *Client) Do(ctx context.Context, h Host, method, path, body string) (string, error) {
+	var rdr io.Reader
+	if body != "" {
+		rdr = strings.NewReader(body)
+	}
+	scheme := "http://"
+	if c.HTTPS {
+		scheme = "https://"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, scheme+h.Addr+path, rdr)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case c.Username != "":
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	out := strings.TrimSpace(string(data))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return out, fmt.Errorf("%s: %s", resp.Status, out)
+	}
+	return out, nil
+}
+
+// TLSConfig returns the TLS settings for reaching daemons that serve HTTPS:
+// caFile, when set, replaces the system roots for verifying them, and
+// certFile and keyFile, when set, are presented to daemons that require a
+// client certificate
+func TLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile) // #nosec G304 -- path is from CLI flag
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in CA file")
+		}
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("a client certificate needs both a cert and a key file")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,171 @@
+package ctl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseHosts(t *testing.T) {
+	input := `# display hosts
+rack1-a   rack1 all
+rack1-b:9091 rack1
+
+10.0.0.5  # no groups
+`
+	hosts, err := ParseHosts(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHosts() failed: %v", err)
+	}
+	if len(hosts) != 3 {
+		t.Fatalf("expected 3 hosts, got %d", len(hosts))
+	}
+	if hosts[0].Addr != "rack1-a:9090" {
+		t.Errorf("expected default port, got %s", hosts[0].Addr)
+	}
+	if hosts[1].Addr != "rack1-b:9091" {
+		t.Errorf("expected explicit port preserved, got %s", hosts[1].Addr)
+	}
+	if len(hosts[2].Groups) != 0 {
+		t.Errorf("expected no groups, got %v", hosts[2].Groups)
+	}
+
+	rack1 := SelectHosts(hosts, "rack1")
+	if len(rack1) != 2 {
+		t.Errorf("expected 2 hosts in rack1, got %d", len(rack1))
+	}
+	if len(SelectHosts(hosts, "")) != 3 {
+		t.Error("expected empty group to select all hosts")
+	}
+}
+
+func TestHostsFromList(t *testing.T) {
+	hosts, err := HostsFromList("a, b:1234,,fe80::1")
+	if err != nil {
+		t.Fatalf("HostsFromList() failed: %v", err)
+	}
+	want := []string{"a:9090", "b:1234", "[fe80::1]:9090"}
+	if len(hosts) != len(want) {
+		t.Fatalf("expected %d hosts, got %d", len(want), len(hosts))
+	}
+	for i, w := range want {
+		if hosts[i].Addr != w {
+			t.Errorf("host %d: expected %s, got %s", i, w, hosts[i].Addr)
+		}
+	}
+}
+
+func TestBroadcastParallelAndOrdered(t *testing.T) {
+	hosts := []Host{{Addr: "a"}, {Addr: "b"}, {Addr: "c"}, {Addr: "d"}}
+
+	var inFlight, maxInFlight int32
+	results := Broadcast(context.Background(), hosts, 2, 0, func(_ context.Context, h Host) (string, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		if h.Addr == "c" {
+			return "", errors.New("boom")
+		}
+		return "ok-" + h.Addr, nil
+	})
+
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 concurrent calls, got %d", maxInFlight)
+	}
+	for i, r := range results {
+		if r.Host.Addr != hosts[i].Addr {
+			t.Errorf("result %d out of order: %s", i, r.Host.Addr)
+		}
+	}
+	if results[2].Err == nil {
+		t.Error("expected error result for host c")
+	}
+	if results[0].Output != "ok-a" {
+		t.Errorf("expected output ok-a, got %q", results[0].Output)
+	}
+}
+
+func TestClientDo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "nope", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(r.Method + " OK\n"))
+	}))
+	defer srv.Close()
+
+	h := Host{Addr: strings.TrimPrefix(srv.URL, "http://")}
+	c := NewClient()
+
+	out, err := c.Do(context.Background(), h, http.MethodPost, "/wake", "")
+	if err != nil {
+		t.Fatalf("Do() failed: %v", err)
+	}
+	if out != "POST OK" {
+		t.Errorf("expected 'POST OK', got %q", out)
+	}
+
+	if _, err := c.Do(context.Background(), h, http.MethodGet, "/fail", ""); err == nil {
+		t.Error("expected error for 503 response")
+	}
+}
+
+func TestClientDoTLSAuth(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("OK\n"))
+	}))
+	defer srv.Close()
+
+	h := Host{Addr: strings.TrimPrefix(srv.URL, "https://")}
+	c := &Client{HTTP: srv.Client(), HTTPS: true}
+	if _, err := c.Do(context.Background(), h, http.MethodGet, "/health/details", ""); err == nil {
+		t.Error("expected an error without the token")
+	}
+	c.Token = "s3cret"
+	if out, err := c.Do(context.Background(), h, http.MethodGet, "/health/details", ""); err != nil || out != "OK" {
+		t.Errorf("Do() with the token = %q, %v", out, err)
+	}
+}
+
+func TestBroadcastTimeoutPerHost(t *testing.T) {
+	// Each call takes most of the timeout, so hosts queued behind the first
+	// only succeed if their time starts when they are contacted
+	hosts := []Host{{Addr: "a"}, {Addr: "b"}, {Addr: "c"}}
+	results := Broadcast(context.Background(), hosts, 1, 200*time.Millisecond, func(ctx context.Context, h Host) (string, error) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			return "ok-" + h.Addr, nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	})
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("host %s: expected no timeout, got %v", r.Host.Addr, r.Err)
+		}
+	}
+
+	results = Broadcast(context.Background(), hosts[:1], 1, 10*time.Millisecond, func(ctx context.Context, _ Host) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Errorf("expected a slow host to time out, got %v", results[0].Err)
+	}
+}
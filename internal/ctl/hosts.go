@@ -0,0 +1,122 @@
+package ctl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// DefaultHostsFile is where i2c-displayctl looks for named display hosts.
+// Daemons are not discovered over mDNS, so this file, or -hosts, is the
+// only way to find them.
+const DefaultHostsFile = "/etc/i2c-display/hosts"
+
+// defaultPort is the metrics/control HTTP port used when a host has none
+const defaultPort = "9090"
+
+// Host is a display daemon reachable over HTTP
+type Host struct {
+	Addr   string   // host:port
+	Groups []string // group names this host belongs to
+}
+
+// InGroup reports whether the host is a member of group
+func (h Host) InGroup(group string) bool {
+	for _, g := range h.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseHosts reads a hosts file. Each non-empty line holds an address
+// followed by optional group names; '#' starts a comment:
+//
+//	# address          groups...
+//	rack1-node1        rack1 all
+//	10.0.0.12:9091     rack2
+func ParseHosts(r io.Reader) ([]Host, error) {
+	var hosts []Host
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		addr, err := normalizeAddr(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		hosts = append(hosts, Host{Addr: addr, Groups: fields[1:]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hosts: %w", err)
+	}
+	return hosts, nil
+}
+
+// LoadHostsFile parses the hosts file at path
+func LoadHostsFile(path string) ([]Host, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is from CLI flag or well-known location
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hosts file: %w", err)
+	}
+	defer f.Close()
+	return ParseHosts(f)
+}
+
+// SelectHosts returns hosts belonging to group, or all hosts if group is empty
+func SelectHosts(hosts []Host, group string) []Host {
+	if group == "" {
+		return hosts
+	}
+	var result []Host
+	for _, h := range hosts {
+		if h.InGroup(group) {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// HostsFromList builds hosts from a comma-separated address list
+func HostsFromList(list string) ([]Host, error) {
+	var hosts []Host
+	for _, a := range strings.Split(list, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		addr, err := normalizeAddr(a)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, Host{Addr: addr})
+	}
+	return hosts, nil
+}
+
+// normalizeAddr appends the default port when addr has none
+func normalizeAddr(addr string) (string, error) {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr, nil
+	}
+	if strings.Contains(addr, ":") && !strings.HasPrefix(addr, "[") {
+		// bare IPv6 address
+		return net.JoinHostPort(addr, defaultPort), nil
+	}
+	if addr == "" {
+		return "", fmt.Errorf("empty host address")
+	}
+	return net.JoinHostPort(addr, defaultPort), nil
+}
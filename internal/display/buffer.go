@@ -0,0 +1,84 @@
+package display
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// BufferFormat is the layout of the bytes returned by GetBuffer
+type BufferFormat int
+
+const (
+	// BufferFormatMonoPages holds one bit per pixel in SSD1306 page order:
+	// each byte is eight vertically stacked pixels, least significant bit
+	// at the top
+	BufferFormatMonoPages BufferFormat = iota
+	// BufferFormatRGB565 holds two bytes per pixel, big-endian RGB565, row
+	// by row
+	BufferFormatRGB565
+)
+
+// String returns the format's name
+func (f BufferFormat) String() string {
+	switch f {
+	case BufferFormatMonoPages:
+		return "mono-pages"
+	case BufferFormatRGB565:
+		return "rgb565"
+	default:
+		return fmt.Sprintf("BufferFormat(%d)", int(f))
+	}
+}
+
+// BufferSize returns the length of a width x height buffer in the format
+func (f BufferFormat) BufferSize(width, height int) int {
+	if f == BufferFormatRGB565 {
+		return width * height * 2
+	}
+	return width * ((height + 7) / 8)
+}
+
+// DecodeBuffer converts a width x height buffer in the given format into an
+// image. Lit mono pixels are white.
+func DecodeBuffer(buf []byte, format BufferFormat, width, height int) (*image.NRGBA, error) {
+	if format != BufferFormatMonoPages && format != BufferFormatRGB565 {
+		return nil, fmt.Errorf("unknown buffer format %v", format)
+	}
+	if width < 0 || height < 0 {
+		return nil, fmt.Errorf("invalid buffer size %dx%d", width, height)
+	}
+	if want := format.BufferSize(width, height); len(buf) != want {
+		return nil, fmt.Errorf("%v buffer for %dx%d is %d bytes, want %d", format, width, height, len(buf), want)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			if format == BufferFormatRGB565 {
+				i := (y*width + x) * 2
+				img.SetNRGBA(x, y, rgb565ToNRGBA(uint16(buf[i])<<8|uint16(buf[i+1])))
+			} else if buf[x+(y/8)*width]&(1<<(y%8)) != 0 {
+				img.SetNRGBA(x, y, fbWhite)
+			} else {
+				img.SetNRGBA(x, y, fbBlack)
+			}
+		}
+	}
+	return img, nil
+}
+
+// Snapshot returns what d's buffer holds as an image, whichever driver it is
+func Snapshot(d Display) (*image.NRGBA, error) {
+	b := d.GetBounds()
+	return DecodeBuffer(d.GetBuffer(), d.BufferFormat(), b.Dx(), b.Dy())
+}
+
+// rgb565ToNRGBA expands an RGB565 value to 8 bits per channel, repeating
+// the high bits so white stays white
+func rgb565ToNRGBA(v uint16) color.NRGBA {
+	r := uint8(v >> 11)       // #nosec G115 -- 5 bits
+	g := uint8(v >> 5 & 0x3F) // #nosec G115 -- 6 bits
+	b := uint8(v & 0x1F)      // #nosec G115 -- 5 bits
+	return color.NRGBA{R: r<<3 | r>>2, G: g<<2 | g>>4, B: b<<3 | b>>2, A: 255}
+}
@@ -0,0 +1,52 @@
+package display
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	tests := []struct {
+		name   string
+		disp   Display
+		format BufferFormat
+		want   color.NRGBA // colour of the drawn pixel
+	}{
+		{"mock", NewMockDisplay(8, 12), BufferFormatMonoPages, fbWhite},
+		{"mono", &OffscreenDisplay{Framebuffer: NewFramebuffer(8, 12, ColorModelMono)}, BufferFormatMonoPages, fbWhite},
+		{"rgb565", NewOffscreenDisplay(8, 12), BufferFormatRGB565, red},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.disp.BufferFormat(); got != tt.format {
+				t.Fatalf("BufferFormat() = %v, want %v", got, tt.format)
+			}
+			if err := AsColorDisplay(tt.disp).DrawPixelColor(3, 9, red); err != nil {
+				t.Fatalf("DrawPixelColor failed: %v", err)
+			}
+			img, err := Snapshot(tt.disp)
+			if err != nil {
+				t.Fatalf("Snapshot failed: %v", err)
+			}
+			if img.Bounds() != tt.disp.GetBounds() {
+				t.Fatalf("snapshot bounds = %v, want %v", img.Bounds(), tt.disp.GetBounds())
+			}
+			if got := img.NRGBAAt(3, 9); got != tt.want {
+				t.Errorf("drawn pixel = %v, want %v", got, tt.want)
+			}
+			if got := img.NRGBAAt(3, 8); got != fbBlack {
+				t.Errorf("undrawn pixel = %v, want black", got)
+			}
+		})
+	}
+}
+
+func TestDecodeBufferErrors(t *testing.T) {
+	if _, err := DecodeBuffer(make([]byte, 15), BufferFormatMonoPages, 8, 12); err == nil {
+		t.Error("expected an error for a short buffer")
+	}
+	if _, err := DecodeBuffer(make([]byte, 4), BufferFormat(7), 2, 1); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,161 @@
+package display
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+// Calibration evens out the differences between panels of the same type
+type Calibration struct {
+	// Contrast scales every brightness level sent to the panel; 255 leaves
+	// them as they are
+	Contrast uint8
+	// Gamma raises each colour channel, from 0 to 1, to this power: above 1
+	// darkens mid-tones and below 1 lightens them. Black and white are
+	// unchanged, so monochrome panels are unaffected.
+	Gamma float64
+}
+
+// NoCalibration leaves brightness and colours as drawn
+var NoCalibration = Calibration{Contrast: 255, Gamma: 1}
+
+// CalibrationFromConfig returns the calibration set in the display config,
+// with unset values left alone
+func CalibrationFromConfig(cfg *config.DisplayConfig) Calibration {
+	c := NoCalibration
+	if cfg.Contrast > 0 {
+		c.Contrast = uint8(cfg.Contrast) // #nosec G115 -- validated to 1-255
+	}
+	if cfg.Gamma > 0 {
+		c.Gamma = cfg.Gamma
+	}
+	return c
+}
+
+// CalibratedDisplay wraps a display, scaling brightness by the panel's
+// contrast and correcting the gamma of colours drawn. The calibration can
+// be changed while pages are shown, e.g. while the user tunes it.
+type CalibratedDisplay struct {
+	Display
+
+	mu    sync.RWMutex
+	cal   Calibration
+	level uint8       // brightness last requested
+	lut   *[256]uint8 // gamma curve, nil when Gamma is 1
+}
+
+// NewCalibratedDisplay wraps disp with cal. The brightness is assumed to be
+// full until SetBrightness is called.
+func NewCalibratedDisplay(disp Display, cal Calibration) *CalibratedDisplay {
+	c := &CalibratedDisplay{Display: disp, level: 255}
+	c.setCalibration(cal)
+	return c
+}
+
+// Calibration returns the calibration in use
+func (c *CalibratedDisplay) Calibration() Calibration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cal
+}
+
+// SetCalibration switches to cal and sends the last requested brightness
+// again with the new contrast. Colours already drawn keep the old gamma
+// until they are redrawn.
+func (c *CalibratedDisplay) SetCalibration(cal Calibration) error {
+	c.setCalibration(cal)
+	c.mu.RLock()
+	level := c.scaled(c.level)
+	c.mu.RUnlock()
+	return c.Display.SetBrightness(level)
+}
+
+func (c *CalibratedDisplay) setCalibration(cal Calibration) {
+	var lut *[256]uint8
+	if cal.Gamma > 0 && cal.Gamma != 1 {
+		lut = new([256]uint8)
+		for i := range lut {
+			lut[i] = uint8(math.Round(255 * math.Pow(float64(i)/255, cal.Gamma)))
+		}
+	}
+	c.mu.Lock()
+	c.cal, c.lut = cal, lut
+	c.mu.Unlock()
+}
+
+// scaled applies the contrast to level. Callers hold mu.
+func (c *CalibratedDisplay) scaled(level uint8) uint8 {
+	return uint8((int(level)*int(c.cal.Contrast) + 127) / 255) // #nosec G115 -- at most 255
+}
+
+// correct applies the gamma curve to col
+func (c *CalibratedDisplay) correct(col color.Color) color.Color {
+	c.mu.RLock()
+	lut := c.lut
+	c.mu.RUnlock()
+	if lut == nil {
+		return col
+	}
+	n := color.NRGBAModel.Convert(col).(color.NRGBA)
+	return color.NRGBA{R: lut[n.R], G: lut[n.G], B: lut[n.B], A: n.A}
+}
+
+// SetBrightness sets the wrapped display's brightness, scaled by the
+// contrast
+func (c *CalibratedDisplay) SetBrightness(level uint8) error {
+	c.mu.Lock()
+	c.level = level
+	scaled := c.scaled(level)
+	c.mu.Unlock()
+	return c.Display.SetBrightness(scaled)
+}
+
+// DrawImage draws img with its colours gamma corrected
+func (c *CalibratedDisplay) DrawImage(x, y int, img image.Image) error {
+	c.mu.RLock()
+	lut := c.lut
+	c.mu.RUnlock()
+	if lut != nil {
+		img = gammaImage{Image: img, lut: lut}
+	}
+	return c.Display.DrawImage(x, y, img)
+}
+
+// DrawPixelColor sets a gamma corrected pixel on the wrapped display
+func (c *CalibratedDisplay) DrawPixelColor(x, y int, col color.Color) error {
+	return AsColorDisplay(c.Display).DrawPixelColor(x, y, c.correct(col))
+}
+
+// FillRectColor fills a rectangle with a gamma corrected colour
+func (c *CalibratedDisplay) FillRectColor(x, y, width, height int, col color.Color) error {
+	return AsColorDisplay(c.Display).FillRectColor(x, y, width, height, c.correct(col))
+}
+
+// Capabilities reports the wrapped display's capabilities
+func (c *CalibratedDisplay) Capabilities() Capabilities {
+	return AsColorDisplay(c.Display).Capabilities()
+}
+
+// WriteLines passes text through; character displays have no colours
+func (c *CalibratedDisplay) WriteLines(lines []string) error {
+	return WriteLines(c.Display, lines)
+}
+
+// gammaImage is an image whose colours are read through a gamma curve
+type gammaImage struct {
+	image.Image
+	lut *[256]uint8
+}
+
+func (g gammaImage) ColorModel() color.Model {
+	return color.NRGBAModel
+}
+
+func (g gammaImage) At(x, y int) color.Color {
+	n := color.NRGBAModel.Convert(g.Image.At(x, y)).(color.NRGBA)
+	return color.NRGBA{R: g.lut[n.R], G: g.lut[n.G], B: g.lut[n.B], A: n.A}
+}
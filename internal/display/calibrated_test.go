@@ -0,0 +1,83 @@
+package display
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+func TestCalibrationFromConfig(t *testing.T) {
+	if got := CalibrationFromConfig(&config.DisplayConfig{}); got != NoCalibration {
+		t.Errorf("unset values = %+v, want %+v", got, NoCalibration)
+	}
+	want := Calibration{Contrast: 200, Gamma: 1.8}
+	if got := CalibrationFromConfig(&config.DisplayConfig{Contrast: 200, Gamma: 1.8}); got != want {
+		t.Errorf("CalibrationFromConfig() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCalibratedBrightness(t *testing.T) {
+	mock := NewMockDisplay(128, 64)
+	c := NewCalibratedDisplay(mock, Calibration{Contrast: 128, Gamma: 1})
+
+	if err := c.SetBrightness(255); err != nil {
+		t.Fatalf("SetBrightness() failed: %v", err)
+	}
+	if err := c.SetBrightness(100); err != nil {
+		t.Fatalf("SetBrightness() failed: %v", err)
+	}
+	// A new contrast is applied to the level last asked for
+	if err := c.SetCalibration(Calibration{Contrast: 255, Gamma: 1}); err != nil {
+		t.Fatalf("SetCalibration() failed: %v", err)
+	}
+	want := []string{"SetBrightness([128])", "SetBrightness([50])", "SetBrightness([100])"}
+	if got := mock.GetCalls(); !slices.Equal(got, want) {
+		t.Errorf("calls = %v, want %v", got, want)
+	}
+	if got := c.Calibration(); got.Contrast != 255 {
+		t.Errorf("Calibration().Contrast = %d, want 255", got.Contrast)
+	}
+}
+
+func TestCalibratedGamma(t *testing.T) {
+	fb := NewOffscreenDisplay(4, 4)
+	c := NewCalibratedDisplay(fb, Calibration{Contrast: 255, Gamma: 2})
+
+	if !c.Capabilities().Color() {
+		t.Error("expected capabilities of the wrapped display")
+	}
+	grey := color.NRGBA{R: 128, G: 128, B: 128, A: 255}
+	if err := c.FillRectColor(0, 0, 2, 2, grey); err != nil {
+		t.Fatalf("FillRectColor() failed: %v", err)
+	}
+	if got := fb.Image().NRGBAAt(0, 0); got.R != 64 || got.G != 64 || got.B != 64 {
+		t.Errorf("mid grey drawn as %v, want 64", got)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	img.SetNRGBA(1, 0, grey)
+	if err := c.DrawImage(2, 2, img); err != nil {
+		t.Fatalf("DrawImage() failed: %v", err)
+	}
+	if got := fb.Image().NRGBAAt(2, 2); got.R != 255 || got.G != 0 {
+		t.Errorf("full red drawn as %v, want unchanged", got)
+	}
+	if got := fb.Image().NRGBAAt(3, 2); got.R != 64 {
+		t.Errorf("image grey drawn as %v, want 64", got)
+	}
+
+	// Gamma 1 leaves colours alone
+	if err := c.SetCalibration(NoCalibration); err != nil {
+		t.Fatalf("SetCalibration() failed: %v", err)
+	}
+	if err := c.DrawPixelColor(1, 3, grey); err != nil {
+		t.Fatalf("DrawPixelColor() failed: %v", err)
+	}
+	if got := fb.Image().NRGBAAt(1, 3); got.R != 128 {
+		t.Errorf("uncorrected grey drawn as %v, want 128", got)
+	}
+}
@@ -0,0 +1,140 @@
+package display
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CapturedFrame is a frame kept by a CaptureDisplay, as GetBuffer returned
+// it when it was shown
+type CapturedFrame struct {
+	At     time.Time
+	Buffer []byte
+	Format BufferFormat
+	Bounds image.Rectangle
+}
+
+// CaptureDisplay wraps a display and keeps the last frames shown in memory,
+// so they can be written out as PNGs after a failure on a device nobody is
+// watching. Frames are kept in the panel's own encoding and only decoded
+// when dumped.
+type CaptureDisplay struct {
+	Display
+
+	mu     sync.Mutex
+	frames []CapturedFrame // ring buffer, oldest at next once full
+	next   int
+	full   bool
+}
+
+// NewCaptureDisplay wraps disp, keeping its last n frames
+func NewCaptureDisplay(disp Display, n int) *CaptureDisplay {
+	return &CaptureDisplay{Display: disp, frames: make([]CapturedFrame, max(n, 1))}
+}
+
+// Show keeps a copy of the frame and flushes the wrapped display. Frames
+// that fail to flush are kept too; they are often the interesting ones.
+func (c *CaptureDisplay) Show() error {
+	frame := CapturedFrame{
+		At:     time.Now(),
+		Buffer: c.Display.GetBuffer(),
+		Format: c.Display.BufferFormat(),
+		Bounds: c.Display.GetBounds(),
+	}
+	c.mu.Lock()
+	c.frames[c.next] = frame
+	c.next = (c.next + 1) % len(c.frames)
+	c.full = c.full || c.next == 0
+	c.mu.Unlock()
+	return c.Display.Show()
+}
+
+// Frames returns the kept frames, oldest first
+func (c *CaptureDisplay) Frames() []CapturedFrame {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.full {
+		return append([]CapturedFrame(nil), c.frames[:c.next]...)
+	}
+	return append(append([]CapturedFrame(nil), c.frames[c.next:]...), c.frames[:c.next]...)
+}
+
+// Dump writes the kept frames as numbered PNGs, oldest first, into a new
+// directory under dir named after the time and reason, and returns its
+// path. Frames are kept, so a later dump includes them again.
+func (c *CaptureDisplay) Dump(dir, reason string) (string, error) {
+	frames := c.Frames()
+	if len(frames) == 0 {
+		return "", fmt.Errorf("no frames captured")
+	}
+
+	out := filepath.Join(dir, time.Now().Format("20060102-150405")+"-"+dumpName(reason))
+	if err := os.MkdirAll(out, 0o750); err != nil {
+		return "", err
+	}
+	for i, f := range frames {
+		img, err := DecodeBuffer(f.Buffer, f.Format, f.Bounds.Dx(), f.Bounds.Dy())
+		if err != nil {
+			return out, fmt.Errorf("frame %d: %w", i, err)
+		}
+		name := fmt.Sprintf("%03d-%s.png", i, f.At.Format("150405.000"))
+		if err := writePNG(filepath.Join(out, name), img); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// writePNG encodes img to path
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path) // #nosec G304 -- path built from the configured crash directory
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// dumpName makes reason safe for a directory name
+func dumpName(reason string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '_'
+	}, reason)
+	if name == "" {
+		return "dump"
+	}
+	return name
+}
+
+// DrawPixelColor sets a coloured pixel on the wrapped display
+func (c *CaptureDisplay) DrawPixelColor(x, y int, col color.Color) error {
+	return AsColorDisplay(c.Display).DrawPixelColor(x, y, col)
+}
+
+// FillRectColor fills a rectangle on the wrapped display
+func (c *CaptureDisplay) FillRectColor(x, y, width, height int, col color.Color) error {
+	return AsColorDisplay(c.Display).FillRectColor(x, y, width, height, col)
+}
+
+// Capabilities reports the wrapped display's capabilities
+func (c *CaptureDisplay) Capabilities() Capabilities {
+	return AsColorDisplay(c.Display).Capabilities()
+}
+
+// WriteLines sets the wrapped display's text
+func (c *CaptureDisplay) WriteLines(lines []string) error {
+	return WriteLines(c.Display, lines)
+}
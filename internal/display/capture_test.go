@@ -0,0 +1,65 @@
+package display
+
+import (
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCaptureDisplay(t *testing.T) {
+	mock := NewMockDisplay(16, 8)
+	c := NewCaptureDisplay(mock, 2)
+
+	for x := range 3 {
+		_ = c.Clear()
+		_ = c.DrawPixel(x, 0, true)
+		if err := c.Show(); err != nil {
+			t.Fatalf("Show() failed: %v", err)
+		}
+	}
+	frames := c.Frames()
+	if len(frames) != 2 {
+		t.Fatalf("expected the last 2 frames, got %d", len(frames))
+	}
+
+	dir := t.TempDir()
+	out, err := c.Dump(dir, "display: i2c/failed")
+	if err != nil {
+		t.Fatalf("Dump() failed: %v", err)
+	}
+	if filepath.Dir(out) != dir || !strings.HasSuffix(out, "-display__i2c_failed") {
+		t.Errorf("unexpected dump directory %q", out)
+	}
+	entries, err := os.ReadDir(out)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 PNGs, got %v, %v", entries, err)
+	}
+
+	// Oldest first: the frames with pixels 1 and 2 lit
+	for i, e := range entries {
+		f, err := os.Open(filepath.Join(out, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", e.Name(), err)
+		}
+		if r, _, _, _ := img.At(i+1, 0).RGBA(); r == 0 {
+			t.Errorf("%s: expected pixel (%d, 0) lit", e.Name(), i+1)
+		}
+		if r, _, _, _ := img.At(i, 0).RGBA(); r != 0 {
+			t.Errorf("%s: expected pixel (%d, 0) dark", e.Name(), i)
+		}
+	}
+}
+
+func TestCaptureDisplayEmpty(t *testing.T) {
+	c := NewCaptureDisplay(NewOffscreenDisplay(16, 8), 4)
+	if _, err := c.Dump(t.TempDir(), "rotation"); err == nil {
+		t.Error("expected an error dumping before any frame was shown")
+	}
+}
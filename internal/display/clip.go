@@ -0,0 +1,62 @@
+package display
+
+// clipSpan returns the part of [start, start+length) inside [0, limit) as
+// [lo, hi), empty when they do not overlap. Extreme values do not overflow.
+func clipSpan(start, length, limit int) (lo, hi int) {
+	if length <= 0 || start >= limit {
+		return 0, 0
+	}
+	lo, hi = max(start, 0), limit
+	if endsWithin(start, length, limit) {
+		hi = start + length
+	}
+	if hi <= lo {
+		return 0, 0
+	}
+	return lo, hi
+}
+
+// endsWithin reports whether start+length <= limit, for start < limit and a
+// positive length, without overflowing
+func endsWithin(start, length, limit int) bool {
+	if start < 0 {
+		// Opposite signs cannot overflow
+		return start+length <= limit
+	}
+	return length <= limit-start
+}
+
+// rectPixels calls set for every pixel of the rectangle at (x, y) inside a
+// width x height frame: all of them when fill is set, otherwise those on
+// its edges. Edges outside the frame are clipped away, not moved inside.
+func rectPixels(x, y, w, h, width, height int, fill bool, set func(x, y int)) {
+	xlo, xhi := clipSpan(x, w, width)
+	ylo, yhi := clipSpan(y, h, height)
+	top, bottom := y >= 0, endsWithin(y, h, height)
+	left, right := x >= 0, endsWithin(x, w, width)
+	for py := ylo; py < yhi; py++ {
+		for px := xlo; px < xhi; px++ {
+			if fill || (top && py == ylo) || (bottom && py == yhi-1) || (left && px == xlo) || (right && px == xhi-1) {
+				set(px, py)
+			}
+		}
+	}
+}
+
+// textCells calls cell with the left edge of each of n character cells
+// charWidth apart from x, stopping at the first past the frame's width
+func textCells(x, n, charWidth, width int, cell func(x int)) {
+	if charWidth <= 0 {
+		return
+	}
+	for range n {
+		if x >= width {
+			return
+		}
+		cell(x)
+		if x >= 0 && charWidth >= width-x {
+			return
+		}
+		x += charWidth
+	}
+}
@@ -0,0 +1,125 @@
+package display
+
+import (
+	"image"
+	"image/color"
+	"sync"
+)
+
+// FrameCounts summarises the display traffic of one or more frames
+type FrameCounts struct {
+	DrawCalls    int // Clear and drawing primitives
+	Flushes      int // calls to Show
+	BytesFlushed int // framebuffer bytes sent by those calls
+}
+
+// CountingDisplay wraps a display and counts draw calls and flushed bytes,
+// for per-frame render reports. Counting adds no display traffic of its own.
+type CountingDisplay struct {
+	Display
+	frameBytes int
+
+	mu     sync.Mutex
+	counts FrameCounts
+}
+
+// NewCountingDisplay wraps disp with zeroed counters
+func NewCountingDisplay(disp Display) *CountingDisplay {
+	b := disp.GetBounds()
+	caps := AsColorDisplay(disp).Capabilities()
+	frameBytes := b.Dx() * b.Dy() * caps.ColorDepth / 8
+	if caps.Text() {
+		// One byte per character cell
+		frameBytes = caps.TextColumns * caps.TextRows
+	}
+	return &CountingDisplay{
+		Display:    disp,
+		frameBytes: frameBytes,
+	}
+}
+
+// TakeCounts returns the counts since the previous call and resets them
+func (c *CountingDisplay) TakeCounts() FrameCounts {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := c.counts
+	c.counts = FrameCounts{}
+	return counts
+}
+
+func (c *CountingDisplay) countDraw() {
+	c.mu.Lock()
+	c.counts.DrawCalls++
+	c.mu.Unlock()
+}
+
+// Clear clears the wrapped display's buffer
+func (c *CountingDisplay) Clear() error {
+	c.countDraw()
+	return c.Display.Clear()
+}
+
+// DrawText draws text on the wrapped display
+func (c *CountingDisplay) DrawText(x, y int, text string, size int) error {
+	c.countDraw()
+	return c.Display.DrawText(x, y, text, size)
+}
+
+// DrawLine draws a horizontal line on the wrapped display
+func (c *CountingDisplay) DrawLine(x, y, width int) error {
+	c.countDraw()
+	return c.Display.DrawLine(x, y, width)
+}
+
+// DrawPixel sets a pixel on the wrapped display
+func (c *CountingDisplay) DrawPixel(x, y int, on bool) error {
+	c.countDraw()
+	return c.Display.DrawPixel(x, y, on)
+}
+
+// DrawRect draws a rectangle on the wrapped display
+func (c *CountingDisplay) DrawRect(x, y, width, height int, fill bool) error {
+	c.countDraw()
+	return c.Display.DrawRect(x, y, width, height, fill)
+}
+
+// DrawImage draws an image on the wrapped display
+func (c *CountingDisplay) DrawImage(x, y int, img image.Image) error {
+	c.countDraw()
+	return c.Display.DrawImage(x, y, img)
+}
+
+// DrawPixelColor sets a coloured pixel on the wrapped display
+func (c *CountingDisplay) DrawPixelColor(x, y int, col color.Color) error {
+	c.countDraw()
+	return AsColorDisplay(c.Display).DrawPixelColor(x, y, col)
+}
+
+// FillRectColor fills a rectangle on the wrapped display
+func (c *CountingDisplay) FillRectColor(x, y, width, height int, col color.Color) error {
+	c.countDraw()
+	return AsColorDisplay(c.Display).FillRectColor(x, y, width, height, col)
+}
+
+// Capabilities reports the wrapped display's capabilities
+func (c *CountingDisplay) Capabilities() Capabilities {
+	return AsColorDisplay(c.Display).Capabilities()
+}
+
+// WriteLines sets the wrapped display's text
+func (c *CountingDisplay) WriteLines(lines []string) error {
+	c.countDraw()
+	return WriteLines(c.Display, lines)
+}
+
+// Show flushes the wrapped display, counting the framebuffer as sent
+func (c *CountingDisplay) Show() error {
+	err := c.Display.Show()
+	if err == nil {
+		c.mu.Lock()
+		c.counts.Flushes++
+		c.counts.BytesFlushed += c.frameBytes
+		c.mu.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package display
+
+import "testing"
+
+func TestCountingDisplay(t *testing.T) {
+	mock := NewMockDisplay(128, 64)
+	c := NewCountingDisplay(mock)
+
+	_ = c.Clear()
+	_ = c.DrawText(0, 0, "hi", FontSmall)
+	_ = c.FillRectColor(0, 0, 4, 4, ColorOn)
+	if err := c.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if !mock.GetPixel(1, 1) {
+		t.Error("expected drawing to reach the wrapped display")
+	}
+
+	got := c.TakeCounts()
+	want := FrameCounts{DrawCalls: 3, Flushes: 1, BytesFlushed: 128 * 64 / 8}
+	if got != want {
+		t.Errorf("TakeCounts() = %+v, want %+v", got, want)
+	}
+	if got := c.TakeCounts(); got != (FrameCounts{}) {
+		t.Errorf("expected counts reset after TakeCounts, got %+v", got)
+	}
+
+	// Failed flushes send nothing
+	mock.SetError(true, "bus error")
+	_ = c.Show()
+	if got := c.TakeCounts(); got.Flushes != 0 || got.BytesFlushed != 0 {
+		t.Errorf("expected no flushed bytes after a failed Show, got %+v", got)
+	}
+}
+
+func TestCountingDisplayColorDepth(t *testing.T) {
+	c := NewCountingDisplay(NewOffscreenDisplay(160, 80))
+	_ = c.Show()
+	if got := c.TakeCounts().BytesFlushed; got != 160*80*2 {
+		t.Errorf("expected an RGB565 frame of %d bytes, got %d", 160*80*2, got)
+	}
+}
@@ -0,0 +1,126 @@
+package display
+
+import (
+	"hash/fnv"
+	"image/color"
+	"sync"
+)
+
+// DedupDisplay wraps a display and skips Show when the frame is identical
+// to the last one flushed, as is common for pages redrawn between rotations
+// with nothing new to show. Skipping saves bus bandwidth and power.
+//
+// The frame is hashed from GetBuffer. Any failed Show, brightness change or
+// Init forgets the last frame, so a panel that was re-initialized or dims in
+// software is always sent the next frame. Character displays are passed
+// through untouched, as their text is not part of the buffer.
+type DedupDisplay struct {
+	Display
+	text bool // wrapped display is a character display
+
+	mu      sync.Mutex
+	last    uint64 // hash of the last frame flushed
+	valid   bool   // last holds a frame the panel is showing
+	skipped uint64
+	onSkip  func()
+}
+
+// NewDedupDisplay wraps disp with no frame remembered
+func NewDedupDisplay(disp Display) *DedupDisplay {
+	return &DedupDisplay{
+		Display: disp,
+		text:    AsColorDisplay(disp).Capabilities().Text(),
+	}
+}
+
+// SetOnSkipFunc sets a callback run for every skipped Show, e.g. to count
+// skipped frames in metrics
+func (d *DedupDisplay) SetOnSkipFunc(fn func()) {
+	d.mu.Lock()
+	d.onSkip = fn
+	d.mu.Unlock()
+}
+
+// Skipped returns how many Show calls were skipped
+func (d *DedupDisplay) Skipped() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.skipped
+}
+
+// forget makes the next Show flush whatever the frame
+func (d *DedupDisplay) forget() {
+	d.mu.Lock()
+	d.valid = false
+	d.mu.Unlock()
+}
+
+// Init initializes the wrapped display
+func (d *DedupDisplay) Init() error {
+	d.forget()
+	return d.Display.Init()
+}
+
+// Show flushes the frame unless it matches the last one flushed
+func (d *DedupDisplay) Show() error {
+	if d.text {
+		return d.Display.Show()
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write(d.Display.GetBuffer()) // #nosec G104 -- hash writes cannot fail
+	sum := h.Sum64()
+
+	d.mu.Lock()
+	if d.valid && sum == d.last {
+		d.skipped++
+		onSkip := d.onSkip
+		d.mu.Unlock()
+		if onSkip != nil {
+			onSkip()
+		}
+		return nil
+	}
+	d.mu.Unlock()
+
+	err := d.Display.Show()
+
+	d.mu.Lock()
+	d.last, d.valid = sum, err == nil
+	d.mu.Unlock()
+	return err
+}
+
+// SetBrightness sets the wrapped display's brightness. Panels dimmed in
+// software need the next frame re-sent to show the new level.
+func (d *DedupDisplay) SetBrightness(level uint8) error {
+	d.forget()
+	return d.Display.SetBrightness(level)
+}
+
+// Sleep puts the wrapped display to sleep. The first frame after it wakes
+// is always flushed.
+func (d *DedupDisplay) Sleep() error {
+	d.forget()
+	return d.Display.Sleep()
+}
+
+// DrawPixelColor sets a coloured pixel on the wrapped display
+func (d *DedupDisplay) DrawPixelColor(x, y int, c color.Color) error {
+	return AsColorDisplay(d.Display).DrawPixelColor(x, y, c)
+}
+
+// FillRectColor fills a rectangle on the wrapped display
+func (d *DedupDisplay) FillRectColor(x, y, width, height int, c color.Color) error {
+	return AsColorDisplay(d.Display).FillRectColor(x, y, width, height, c)
+}
+
+// Capabilities reports the wrapped display's capabilities
+func (d *DedupDisplay) Capabilities() Capabilities {
+	return AsColorDisplay(d.Display).Capabilities()
+}
+
+// WriteLines sets the text of a character display
+func (d *DedupDisplay) WriteLines(lines []string) error {
+	return WriteLines(d.Display, lines)
+}
@@ -0,0 +1,76 @@
+package display
+
+import (
+	"testing"
+)
+
+func showCount(m *MockDisplay) int {
+	n := 0
+	for _, c := range m.GetCalls() {
+		if c == "Show" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDedupDisplaySkipsUnchangedFrames(t *testing.T) {
+	inner := NewMockDisplay(128, 64)
+	d := NewDedupDisplay(inner)
+	skips := 0
+	d.SetOnSkipFunc(func() { skips++ })
+
+	for i := 0; i < 3; i++ {
+		if err := d.DrawPixel(1, 1, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Show(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := showCount(inner); got != 1 {
+		t.Errorf("expected 1 flush for 3 identical frames, got %d", got)
+	}
+	if d.Skipped() != 2 || skips != 2 {
+		t.Errorf("expected 2 skipped frames, got %d (callback %d)", d.Skipped(), skips)
+	}
+
+	// A changed frame is flushed
+	if err := d.DrawPixel(2, 2, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatal(err)
+	}
+	if got := showCount(inner); got != 2 {
+		t.Errorf("expected the changed frame to be flushed, got %d flushes", got)
+	}
+
+	// Brightness changes re-send the frame for panels dimmed in software
+	if err := d.SetBrightness(10); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatal(err)
+	}
+	if got := showCount(inner); got != 3 {
+		t.Errorf("expected a flush after SetBrightness, got %d flushes", got)
+	}
+}
+
+func TestDedupDisplayResendsAfterError(t *testing.T) {
+	inner := NewMockDisplay(128, 64)
+	d := NewDedupDisplay(inner)
+
+	inner.SetError(true, "i2c: remote I/O error")
+	if err := d.Show(); err == nil {
+		t.Fatal("expected Show to fail")
+	}
+	inner.SetError(false, "")
+	if err := d.Show(); err != nil {
+		t.Fatal(err)
+	}
+	if got := showCount(inner); got != 2 {
+		t.Errorf("expected the frame to be re-sent after a failed flush, got %d flushes", got)
+	}
+}
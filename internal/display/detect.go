@@ -0,0 +1,57 @@
+package display
+
+import (
+	"fmt"
+
+	"periph.io/x/conn/v3/i2c"
+)
+
+// Detection describes a display found on the I2C bus
+type Detection struct {
+	Type    string // display type understood by config/NewDisplay, e.g. "ssd1306"
+	Address string // I2C address in hex, e.g. "0x3C"
+}
+
+// oledProbeAddrs are the addresses used by SSD1306/SH1106 modules, in probe order
+var oledProbeAddrs = []uint16{0x3C, 0x3D}
+
+// sh1106StatusID is the low nibble of the SH1106 status register. SSD1306
+// controllers report a different value (typically 0x3 or 0x6), which is the
+// only cheap way to tell the otherwise command-compatible chips apart.
+const sh1106StatusID = 0x08
+
+// Detect probes the I2C bus for a supported display. OLED addresses are
+// probed first and the controller status byte is read to distinguish SH1106
+// from SSD1306; the UCTRONICS bridge MCU is probed last because 0x18 is
+// shared with common sensors.
+func Detect(i2cBus string) (Detection, error) {
+	bus, err := openI2C("detect", i2cBus, 0)
+	if err != nil {
+		return Detection{}, err
+	}
+	defer bus.Close() // #nosec G104 -- best-effort cleanup after probing
+
+	return detectOnBus(bus)
+}
+
+// detectOnBus runs the probe sequence against an open bus
+func detectOnBus(bus i2c.Bus) (Detection, error) {
+	for _, addr := range oledProbeAddrs {
+		status := make([]byte, 1)
+		if err := bus.Tx(addr, nil, status); err != nil {
+			continue // no ACK, nothing at this address
+		}
+		displayType := "ssd1306"
+		if status[0]&0x0F == sh1106StatusID {
+			displayType = "sh1106"
+		}
+		return Detection{Type: displayType, Address: fmt.Sprintf("0x%02X", addr)}, nil
+	}
+
+	probe := make([]byte, 1)
+	if err := bus.Tx(uctronicsDefaultAddr, nil, probe); err == nil {
+		return Detection{Type: "uctronics_colour", Address: fmt.Sprintf("0x%02X", uctronicsDefaultAddr)}, nil
+	}
+
+	return Detection{}, fmt.Errorf("no supported display found on I2C bus")
+}
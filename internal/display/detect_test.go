@@ -0,0 +1,71 @@
+package display
+
+import (
+	"errors"
+	"testing"
+
+	"periph.io/x/conn/v3/physic"
+)
+
+// fakeBus is an i2c.Bus where only the listed addresses ACK, returning the
+// configured status byte on reads and recording every write
+type fakeBus struct {
+	devices map[uint16]byte
+	probed  []uint16
+	writes  []fakeWrite
+}
+
+type fakeWrite struct {
+	addr uint16
+	data []byte
+}
+
+func (b *fakeBus) String() string { return "fake" }
+
+func (b *fakeBus) SetSpeed(f physic.Frequency) error { return nil }
+
+func (b *fakeBus) Close() error { return nil }
+
+func (b *fakeBus) Tx(addr uint16, w, r []byte) error {
+	b.probed = append(b.probed, addr)
+	status, ok := b.devices[addr]
+	if !ok {
+		return errors.New("no ACK")
+	}
+	if len(w) > 0 {
+		b.writes = append(b.writes, fakeWrite{addr: addr, data: append([]byte(nil), w...)})
+	}
+	if len(r) > 0 {
+		r[0] = status
+	}
+	return nil
+}
+
+func TestDetectOnBus(t *testing.T) {
+	tests := []struct {
+		name     string
+		devices  map[uint16]byte
+		wantType string
+		wantAddr string
+		wantErr  bool
+	}{
+		{name: "ssd1306 at 0x3C", devices: map[uint16]byte{0x3C: 0x43}, wantType: "ssd1306", wantAddr: "0x3C"},
+		{name: "ssd1306 at 0x3D", devices: map[uint16]byte{0x3D: 0x06}, wantType: "ssd1306", wantAddr: "0x3D"},
+		{name: "sh1106", devices: map[uint16]byte{0x3C: 0x08}, wantType: "sh1106", wantAddr: "0x3C"},
+		{name: "uctronics", devices: map[uint16]byte{0x18: 0x00}, wantType: "uctronics_colour", wantAddr: "0x18"},
+		{name: "oled preferred over 0x18", devices: map[uint16]byte{0x18: 0x00, 0x3C: 0x06}, wantType: "ssd1306", wantAddr: "0x3C"},
+		{name: "nothing found", devices: map[uint16]byte{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := detectOnBus(&fakeBus{devices: tt.devices})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("detectOnBus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Type != tt.wantType || got.Address != tt.wantAddr {
+				t.Errorf("detectOnBus() = %+v, want type %q at %q", got, tt.wantType, tt.wantAddr)
+			}
+		})
+	}
+}
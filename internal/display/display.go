@@ -1,8 +1,10 @@
 package display
 
 import (
+	"fmt"
 	"image"
 	"image/color"
+	"time"
 )
 
 // Display is the interface for OLED display operations
@@ -41,8 +43,138 @@ type Display interface {
 	// GetBuffer returns a copy of the current display buffer (for testing)
 	GetBuffer() []byte
 
+	// BufferFormat reports how GetBuffer lays out the pixels
+	BufferFormat() BufferFormat
+
 	// SetBrightness sets the display brightness (0-255)
 	SetBrightness(level uint8) error
+
+	// Sleep switches the panel off and puts its controller into its lowest
+	// power state. The frame buffer is kept; drawing and Show may still be
+	// called. Displays without a power-down mode do nothing.
+	Sleep() error
+
+	// Wake switches the panel back on after Sleep. The backlight stays off
+	// until the next SetBrightness.
+	Wake() error
+}
+
+// Capabilities describes what a display can show
+type Capabilities struct {
+	ColorDepth  int // bits per pixel: 1 for monochrome, 16 for RGB565
+	TextColumns int // characters per row on character displays, 0 otherwise
+	TextRows    int // rows of characters on character displays, 0 otherwise
+
+	// MinRefreshInterval is the shortest time between refreshes the panel
+	// tolerates, 0 when it has no limit. E-paper panels take seconds to
+	// redraw and wear with every update.
+	MinRefreshInterval time.Duration
+}
+
+// Color reports whether the display shows more than on/off pixels
+func (c Capabilities) Color() bool {
+	return c.ColorDepth > 1
+}
+
+// Text reports whether the display shows lines of characters rather than
+// pixels. Character displays still accept drawing calls, but only the text
+// given to WriteLines appears on the panel.
+func (c Capabilities) Text() bool {
+	return c.TextColumns > 0 && c.TextRows > 0
+}
+
+// ColorDisplay is an optional extension of Display for drawing coloured
+// primitives directly, without building an intermediate image. Monochrome
+// displays implement it too and threshold the colour.
+type ColorDisplay interface {
+	Display
+
+	// DrawPixelColor sets a single pixel to c
+	DrawPixelColor(x, y int, c color.Color) error
+
+	// FillRectColor fills a rectangle with c
+	FillRectColor(x, y, width, height int, c color.Color) error
+
+	// Capabilities reports the colour depth of the display
+	Capabilities() Capabilities
+}
+
+// AsColorDisplay returns d as a ColorDisplay. Displays that only implement
+// Display are adapted by thresholding colours to on/off pixels.
+func AsColorDisplay(d Display) ColorDisplay {
+	if cd, ok := d.(ColorDisplay); ok {
+		return cd
+	}
+	return monoAdapter{d}
+}
+
+// TextDisplay is an optional extension of Display for character displays
+// such as HD44780 LCDs
+type TextDisplay interface {
+	Display
+
+	// WriteLines sets the text shown by the next Show, one string per row.
+	// Missing rows are blank, extra rows are dropped and long lines are cut
+	// at the last column.
+	WriteLines(lines []string) error
+}
+
+// WriteLines sets the text shown by d's next Show. It fails for displays
+// that are not character displays.
+func WriteLines(d Display, lines []string) error {
+	td, ok := d.(TextDisplay)
+	if !ok {
+		return fmt.Errorf("display does not support text output")
+	}
+	return td.WriteLines(lines)
+}
+
+// Sleep blanks d and powers its panel down where the driver can, so no
+// stale frame stays on screen while the host is suspended or off. Init
+// wakes the panel; the caller redraws it.
+func Sleep(d Display) error {
+	if err := d.Clear(); err != nil {
+		return err
+	}
+	if err := d.Show(); err != nil {
+		return err
+	}
+	return d.Sleep()
+}
+
+// monoAdapter implements ColorDisplay on top of the on/off primitives
+type monoAdapter struct {
+	Display
+}
+
+func (m monoAdapter) DrawPixelColor(x, y int, c color.Color) error {
+	return m.DrawPixel(x, y, isLit(c))
+}
+
+func (m monoAdapter) FillRectColor(x, y, width, height int, c color.Color) error {
+	if isLit(c) {
+		return m.DrawRect(x, y, width, height, true)
+	}
+	var err error
+	b := m.GetBounds()
+	rectPixels(x, y, width, height, b.Dx(), b.Dy(), true, func(px, py int) {
+		if err == nil {
+			err = m.DrawPixel(px, py, false)
+		}
+	})
+	return err
+}
+
+func (m monoAdapter) Capabilities() Capabilities {
+	return Capabilities{ColorDepth: 1}
+}
+
+// isLit reports whether c shows as an on pixel on a monochrome panel: mostly
+// opaque with its brightest channel above half, so saturated colours such as
+// pure green still light up
+func isLit(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	return a > 32768 && max(r, g, b) > 32768
 }
 
 // Font sizes
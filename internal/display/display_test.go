@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"slices"
 	"testing"
 )
 
@@ -577,3 +578,25 @@ func TestMockDisplayCallTracking(t *testing.T) {
 		t.Errorf("expected 0 calls after ClearCalls, got %d", len(calls))
 	}
 }
+
+func TestSleep(t *testing.T) {
+	inner := NewMockDisplay(16, 8)
+	if err := inner.DrawRect(0, 0, 16, 8, true); err != nil {
+		t.Fatal(err)
+	}
+	inner.ClearCalls()
+	// Sleep and Wake reach the panel through the wrappers
+	disp := NewDedupDisplay(NewCaptureDisplay(NewShiftDisplay(NewCountingDisplay(inner)), 2))
+	if err := Sleep(disp); err != nil {
+		t.Fatalf("Sleep() failed: %v", err)
+	}
+	if inner.GetPixel(3, 3) {
+		t.Error("expected the display to be blanked before sleeping")
+	}
+	if err := disp.Wake(); err != nil {
+		t.Fatalf("Wake() failed: %v", err)
+	}
+	if calls := inner.GetCalls(); !slices.Equal(calls, []string{"Clear", "Show", "Sleep", "Wake"}) {
+		t.Errorf("calls = %v, want Clear, Show, Sleep and Wake", calls)
+	}
+}
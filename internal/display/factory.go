@@ -2,13 +2,67 @@ package display
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/ausil/i2c-display/internal/config"
+	"periph.io/x/conn/v3/physic"
 )
 
-// NewDisplay creates a display implementation based on configuration
+// defaultWindowScale is the pixel magnification of window previews
+const defaultWindowScale = 4
+
+// st7735Options converts the panel tuning settings for the ST7735 driver.
+// Config validation keeps the gamma values within a byte.
+func st7735Options(cfg *config.DisplayConfig) ST7735Options {
+	gamma := func(values []int) []byte {
+		if len(values) == 0 {
+			return nil
+		}
+		b := make([]byte, len(values))
+		for i, v := range values {
+			b[i] = byte(v) // #nosec G115 -- validated to 0-63
+		}
+		return b
+	}
+	return ST7735Options{
+		Variant:       cfg.Variant,
+		Invert:        cfg.InvertColors,
+		BGR:           cfg.BGROrder,
+		GammaPositive: gamma(cfg.GammaPositive),
+		GammaNegative: gamma(cfg.GammaNegative),
+	}
+}
+
+// mirrorer is implemented by every driver drawing into a Framebuffer
+type mirrorer interface {
+	SetMirror(x, y bool)
+}
+
+// NewDisplay creates a display implementation based on configuration,
+// mirrored if configured. When fault injection is configured the driver is wrapped so that hardware
+// operations fail at the configured rate.
 func NewDisplay(cfg *config.DisplayConfig) (Display, error) {
+	disp, err := newDriver(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MirrorX || cfg.MirrorY {
+		m, ok := disp.(mirrorer)
+		if !ok {
+			disp.Close() // #nosec G104 -- best-effort cleanup on error path
+			return nil, fmt.Errorf("display type %s cannot be mirrored", cfg.Type)
+		}
+		m.SetMirror(cfg.MirrorX, cfg.MirrorY)
+	}
+	if cfg.FaultInjectionRate > 0 {
+		return NewFaultyDisplay(disp, cfg.FaultInjectionRate), nil
+	}
+	return disp, nil
+}
+
+// newDriver creates the hardware driver for the configured display type
+func newDriver(cfg *config.DisplayConfig) (Display, error) {
 	displayType := strings.ToLower(cfg.Type)
 
 	// SSD1306 variants (official periph.io support)
@@ -16,6 +70,31 @@ func NewDisplay(cfg *config.DisplayConfig) (Display, error) {
 		return NewSSD1306Display(
 			cfg.I2CBus,
 			cfg.I2CAddress,
+			physic.Frequency(cfg.I2CSpeedHz)*physic.Hertz,
+			cfg.Width,
+			cfg.Height,
+			cfg.Rotation,
+		)
+	}
+
+	// SSD1309/SSD1305 and SH1107 page-addressed OLEDs
+	if oledControllerFor(displayType) != nil {
+		if strings.HasPrefix(displayType, "ssd1309_spi") {
+			return NewOLEDDisplaySPI(
+				cfg.SPIBus,
+				cfg.DCPin,
+				cfg.RSTPin,
+				displayType,
+				cfg.Width,
+				cfg.Height,
+				cfg.Rotation,
+			)
+		}
+		return NewOLEDDisplay(
+			cfg.I2CBus,
+			cfg.I2CAddress,
+			displayType,
+			physic.Frequency(cfg.I2CSpeedHz)*physic.Hertz,
 			cfg.Width,
 			cfg.Height,
 			cfg.Rotation,
@@ -28,10 +107,54 @@ func NewDisplay(cfg *config.DisplayConfig) (Display, error) {
 			cfg.SPIBus,
 			cfg.DCPin,
 			cfg.RSTPin,
+			cfg.BLPin,
+			physic.Frequency(cfg.SPISpeedHz)*physic.Hertz,
 			cfg.Width,
 			cfg.Height,
 			cfg.Rotation,
 			displayType,
+			st7735Options(cfg),
+		)
+	}
+
+	// ST7789 variants (SPI IPS TFT)
+	if strings.HasPrefix(displayType, "st7789") {
+		return NewST7789Display(
+			cfg.SPIBus,
+			cfg.DCPin,
+			cfg.RSTPin,
+			cfg.BLPin,
+			physic.Frequency(cfg.SPISpeedHz)*physic.Hertz,
+			cfg.Width,
+			cfg.Height,
+			cfg.Rotation,
+		)
+	}
+
+	// ILI9341 (SPI TFT, landscape or portrait)
+	if strings.HasPrefix(displayType, "ili9341") {
+		return NewILI9341Display(
+			cfg.SPIBus,
+			cfg.DCPin,
+			cfg.RSTPin,
+			cfg.BLPin,
+			physic.Frequency(cfg.SPISpeedHz)*physic.Hertz,
+			cfg.Width,
+			cfg.Height,
+			cfg.Rotation,
+		)
+	}
+
+	// SSD1680 e-paper (SPI)
+	if strings.HasPrefix(displayType, "ssd1680") {
+		return NewSSD1680Display(
+			cfg.SPIBus,
+			cfg.DCPin,
+			cfg.RSTPin,
+			cfg.BusyPin,
+			cfg.Width,
+			cfg.Height,
+			cfg.Rotation,
 		)
 	}
 
@@ -40,11 +163,43 @@ func NewDisplay(cfg *config.DisplayConfig) (Display, error) {
 		return NewUCTRONICSDisplay(
 			cfg.I2CBus,
 			cfg.I2CAddress,
+			physic.Frequency(cfg.I2CSpeedHz)*physic.Hertz,
 			cfg.Width,
 			cfg.Height,
 		)
 	}
 
+	// HD44780 character LCDs on a PCF8574 I2C backpack; the configured
+	// pixel size is the character grid in 5x8 cells
+	if strings.HasPrefix(displayType, "hd44780") {
+		return NewHD44780Display(
+			cfg.I2CBus,
+			cfg.I2CAddress,
+			physic.Frequency(cfg.I2CSpeedHz)*physic.Hertz,
+			cfg.Width/hd44780CellWidth,
+			cfg.Height/hd44780CellHeight,
+		)
+	}
+
+	// Linux framebuffer (HDMI/DSI screens)
+	if displayType == config.DisplayTypeFBDev {
+		return NewFBDevDisplay(cfg.FBDevice, cfg.Width, cfg.Height)
+	}
+
+	// Terminal preview, drawn on stdout instead of hardware
+	if strings.HasPrefix(displayType, "terminal") {
+		return NewTerminalDisplay(os.Stdout, cfg.Width, cfg.Height, displayType == "terminal_colour"), nil
+	}
+
+	// Desktop window preview, only in builds with -tags preview
+	if strings.HasPrefix(displayType, "window") {
+		scale := cfg.WindowScale
+		if scale == 0 {
+			scale = defaultWindowScale
+		}
+		return newWindow(cfg.Width, cfg.Height, scale, displayType == "window_colour")
+	}
+
 	// Other display types - Framework ready, awaiting drivers
 	supportedButNeedDrivers := map[string]string{
 		"sh1106":  "SH1106 (128x64 mono) - compatible with SSD1306, driver available at github.com/danielgatis/go-sh1106 (SPI)",
@@ -74,6 +74,17 @@ func TestNewDisplay(t *testing.T) {
 			},
 			wantErr: true, // Will fail without hardware
 		},
+		{
+			name: "st7789_240x320",
+			config: config.DisplayConfig{
+				Type:   "st7789_240x320",
+				SPIBus: "SPI0.0",
+				DCPin:  "GPIO24",
+				Width:  240,
+				Height: 320,
+			},
+			wantErr: true, // Will fail without hardware
+		},
 		{
 			name: "uctronics_colour",
 			config: config.DisplayConfig{
@@ -129,3 +140,43 @@ func TestNewDisplayMock(t *testing.T) {
 		t.Errorf("expected 128x64, got %dx%d", bounds.Dx(), bounds.Dy())
 	}
 }
+
+func TestNewDisplayTerminal(t *testing.T) {
+	for _, tt := range []struct {
+		displayType string
+		wantColor   bool
+	}{
+		{"terminal", false},
+		{"terminal_colour", true},
+	} {
+		t.Run(tt.displayType, func(t *testing.T) {
+			disp, err := NewDisplay(&config.DisplayConfig{Type: tt.displayType, Width: 96, Height: 16})
+			if err != nil {
+				t.Fatalf("NewDisplay() error = %v", err)
+			}
+			term, ok := disp.(*TerminalDisplay)
+			if !ok {
+				t.Fatalf("NewDisplay() = %T, want *TerminalDisplay", disp)
+			}
+			if b := term.GetBounds(); b.Dx() != 96 || b.Dy() != 16 {
+				t.Errorf("bounds = %v, want 96x16", b)
+			}
+			if term.Capabilities().Color() != tt.wantColor {
+				t.Errorf("Capabilities().Color() = %v, want %v", term.Capabilities().Color(), tt.wantColor)
+			}
+		})
+	}
+}
+
+func TestNewDisplayMirror(t *testing.T) {
+	disp, err := NewDisplay(&config.DisplayConfig{Type: "terminal", Width: 96, Height: 16, MirrorX: true})
+	if err != nil {
+		t.Fatalf("NewDisplay() error = %v", err)
+	}
+	if err := disp.DrawPixel(0, 0, true); err != nil {
+		t.Fatalf("DrawPixel() failed: %v", err)
+	}
+	if disp.(*TerminalDisplay).Image().NRGBAAt(95, 0) != fbWhite {
+		t.Error("expected the pixel drawn at the left edge to land on the right edge")
+	}
+}
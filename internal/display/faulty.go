@@ -0,0 +1,106 @@
+package display
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// ErrInjectedFault is the base error returned for simulated hardware failures
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultyDisplay wraps a display driver and makes hardware operations (Init,
+// Show, SetBrightness) randomly fail at a configured rate. It exists to
+// exercise retry, health and re-init logic on real hardware; drawing into the
+// in-memory buffer is never failed.
+type FaultyDisplay struct {
+	Display
+	rate     float64
+	randFunc func() float64
+	injected atomic.Int64
+}
+
+// NewFaultyDisplay wraps disp so that hardware operations fail with
+// probability rate (0-1)
+func NewFaultyDisplay(disp Display, rate float64) *FaultyDisplay {
+	return &FaultyDisplay{
+		Display:  disp,
+		rate:     rate,
+		randFunc: rand.Float64, // #nosec G404 -- fault injection does not need crypto randomness
+	}
+}
+
+// Injected returns how many faults have been injected so far
+func (f *FaultyDisplay) Injected() int64 {
+	return f.injected.Load()
+}
+
+func (f *FaultyDisplay) fault(op string) error {
+	if f.randFunc() >= f.rate {
+		return nil
+	}
+	f.injected.Add(1)
+	return fmt.Errorf("%s: %w", op, ErrInjectedFault)
+}
+
+// Init initializes the wrapped display unless a fault is injected
+func (f *FaultyDisplay) Init() error {
+	if err := f.fault("init"); err != nil {
+		return err
+	}
+	return f.Display.Init()
+}
+
+// Show flushes the buffer unless a fault is injected
+func (f *FaultyDisplay) Show() error {
+	if err := f.fault("show"); err != nil {
+		return err
+	}
+	return f.Display.Show()
+}
+
+// Sleep puts the wrapped display to sleep unless a fault is injected
+func (f *FaultyDisplay) Sleep() error {
+	if err := f.fault("sleep"); err != nil {
+		return err
+	}
+	return f.Display.Sleep()
+}
+
+// Wake wakes the wrapped display unless a fault is injected
+func (f *FaultyDisplay) Wake() error {
+	if err := f.fault("wake"); err != nil {
+		return err
+	}
+	return f.Display.Wake()
+}
+
+// SetBrightness sets the brightness unless a fault is injected
+func (f *FaultyDisplay) SetBrightness(level uint8) error {
+	if err := f.fault("set brightness"); err != nil {
+		return err
+	}
+	return f.Display.SetBrightness(level)
+}
+
+// DrawPixelColor draws on the wrapped display; drawing is never failed
+func (f *FaultyDisplay) DrawPixelColor(x, y int, c color.Color) error {
+	return AsColorDisplay(f.Display).DrawPixelColor(x, y, c)
+}
+
+// FillRectColor draws on the wrapped display; drawing is never failed
+func (f *FaultyDisplay) FillRectColor(x, y, width, height int, c color.Color) error {
+	return AsColorDisplay(f.Display).FillRectColor(x, y, width, height, c)
+}
+
+// Capabilities reports the wrapped display's capabilities
+func (f *FaultyDisplay) Capabilities() Capabilities {
+	return AsColorDisplay(f.Display).Capabilities()
+}
+
+// WriteLines sets the wrapped display's text; like drawing it is never failed
+func (f *FaultyDisplay) WriteLines(lines []string) error {
+	return WriteLines(f.Display, lines)
+}
@@ -0,0 +1,38 @@
+package display
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFaultyDisplay(t *testing.T) {
+	inner := NewMockDisplay(128, 64)
+	f := NewFaultyDisplay(inner, 0.5)
+
+	f.randFunc = func() float64 { return 0.9 }
+	if err := f.Show(); err != nil {
+		t.Errorf("expected Show to pass through above the rate, got %v", err)
+	}
+
+	f.randFunc = func() float64 { return 0.1 }
+	if err := f.Show(); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("expected injected fault, got %v", err)
+	}
+	if err := f.Init(); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("expected injected Init fault, got %v", err)
+	}
+	if err := f.SetBrightness(10); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("expected injected SetBrightness fault, got %v", err)
+	}
+	// Buffer operations are never failed
+	if err := f.Clear(); err != nil {
+		t.Errorf("expected Clear to succeed, got %v", err)
+	}
+
+	if f.Injected() != 3 {
+		t.Errorf("expected 3 injected faults, got %d", f.Injected())
+	}
+	if calls := inner.GetCalls(); len(calls) != 2 || calls[0] != "Show" || calls[1] != "Clear" {
+		t.Errorf("expected only passthrough calls to reach the driver, got %v", calls)
+	}
+}
@@ -0,0 +1,278 @@
+package display
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// Linux framebuffer ioctls (linux/fb.h)
+const (
+	fbioGetVScreenInfo = 0x4600
+	fbioGetFScreenInfo = 0x4602
+	fbioBlank          = 0x4611
+)
+
+// FBIOBLANK levels
+const (
+	fbBlankUnblank   = 0
+	fbBlankPowerdown = 4
+)
+
+// fbBitfield mirrors struct fb_bitfield
+type fbBitfield struct {
+	Offset   uint32
+	Length   uint32
+	MSBRight uint32
+}
+
+// fbVarScreenInfo mirrors struct fb_var_screeninfo
+type fbVarScreenInfo struct {
+	XRes, YRes               uint32
+	XResVirtual, YResVirtual uint32
+	XOffset, YOffset         uint32
+	BitsPerPixel             uint32
+	Grayscale                uint32
+	Red, Green, Blue, Transp fbBitfield
+	NonStd                   uint32
+	Activate                 uint32
+	Height, Width            uint32
+	AccelFlags               uint32
+	PixClock                 uint32
+	LeftMargin, RightMargin  uint32
+	UpperMargin, LowerMargin uint32
+	HSyncLen, VSyncLen       uint32
+	Sync, VMode, Rotate      uint32
+	Colorspace               uint32
+	Reserved                 [4]uint32
+}
+
+// fbFixScreenInfo mirrors struct fb_fix_screeninfo; unsigned long fields
+// are uintptr, which has the same size on Linux
+type fbFixScreenInfo struct {
+	ID                            [16]byte
+	SmemStart                     uintptr
+	SmemLen                       uint32
+	Type, TypeAux, Visual         uint32
+	XPanStep, YPanStep, YWrapStep uint16
+	LineLength                    uint32
+	MMIOStart                     uintptr
+	MMIOLen                       uint32
+	Accel                         uint32
+	Capabilities                  uint16
+	Reserved                      [2]uint16
+}
+
+// fbFormat describes how a screen pixel is laid out in memory
+type fbFormat struct {
+	bytesPerPixel    int
+	red, green, blue fbBitfield
+}
+
+// pack encodes c as a pixel value
+func (f fbFormat) pack(c color.NRGBA) uint32 {
+	return packChannel(c.R, f.red) | packChannel(c.G, f.green) | packChannel(c.B, f.blue)
+}
+
+// put stores pixel value v in dst in the CPU's byte order
+func (f fbFormat) put(dst []byte, v uint32) {
+	switch f.bytesPerPixel {
+	case 2:
+		binary.NativeEndian.PutUint16(dst, uint16(v)) // #nosec G115 -- 16 bpp values fit uint16
+	case 3:
+		// 24 bpp modes only exist on little-endian hardware in practice
+		dst[0], dst[1], dst[2] = byte(v), byte(v>>8), byte(v>>16)
+	default:
+		binary.NativeEndian.PutUint32(dst, v)
+	}
+}
+
+// packChannel reduces an 8-bit channel to the field's width and shifts it
+// into place
+func packChannel(v uint8, field fbBitfield) uint32 {
+	if field.Length == 0 {
+		return 0
+	}
+	return (uint32(v) >> (8 - min(field.Length, 8))) << field.Offset
+}
+
+// FBDevDisplay draws on a Linux framebuffer device (/dev/fb*), such as an
+// HDMI or DSI screen on a kiosk build. The frame keeps the configured size
+// and is magnified by the largest whole factor that fits the screen,
+// centred with black borders. Brightness is applied in software.
+type FBDevDisplay struct {
+	*Framebuffer
+	device string
+	file   *os.File
+
+	mem        []byte // mapped screen memory
+	format     fbFormat
+	lineLength int
+	screenW    int
+	screenH    int
+	scale      int
+	offsetX    int
+	offsetY    int
+
+	mu         sync.Mutex // protects brightness
+	brightness uint8
+}
+
+// NewFBDevDisplay opens device and maps its memory for a width x height
+// frame. 16, 24 and 32 bit per pixel truecolor modes are supported.
+func NewFBDevDisplay(device string, width, height int) (*FBDevDisplay, error) {
+	f, err := os.OpenFile(device, os.O_RDWR, 0) // #nosec G304 -- device path comes from configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to open framebuffer %s: %w", device, err)
+	}
+
+	var vinfo fbVarScreenInfo
+	var finfo fbFixScreenInfo
+	if err := fbIoctl(f, fbioGetVScreenInfo, unsafe.Pointer(&vinfo)); err != nil { // #nosec G103 -- ioctl needs the struct address
+		f.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, fmt.Errorf("failed to read framebuffer mode: %w", err)
+	}
+	if err := fbIoctl(f, fbioGetFScreenInfo, unsafe.Pointer(&finfo)); err != nil { // #nosec G103 -- ioctl needs the struct address
+		f.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, fmt.Errorf("failed to read framebuffer layout: %w", err)
+	}
+
+	format := fbFormat{
+		bytesPerPixel: int(vinfo.BitsPerPixel / 8),
+		red:           vinfo.Red,
+		green:         vinfo.Green,
+		blue:          vinfo.Blue,
+	}
+	switch vinfo.BitsPerPixel {
+	case 16, 24, 32:
+	default:
+		f.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, fmt.Errorf("unsupported framebuffer depth %d bits per pixel, need 16, 24 or 32", vinfo.BitsPerPixel)
+	}
+
+	size := int(finfo.LineLength) * int(vinfo.YRes)
+	mem, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		f.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, fmt.Errorf("failed to map framebuffer: %w", err)
+	}
+
+	d := newFBDevDisplay(mem, format, int(finfo.LineLength), int(vinfo.XRes), int(vinfo.YRes), width, height)
+	d.device = device
+	d.file = f
+	return d, nil
+}
+
+// newFBDevDisplay lays a width x height frame out on a screen backed by mem
+func newFBDevDisplay(mem []byte, format fbFormat, lineLength, screenW, screenH, width, height int) *FBDevDisplay {
+	scale := max(min(screenW/width, screenH/height), 1)
+	return &FBDevDisplay{
+		Framebuffer: NewFramebuffer(width, height, ColorModelRGB565),
+		mem:         mem,
+		format:      format,
+		lineLength:  lineLength,
+		screenW:     screenW,
+		screenH:     screenH,
+		scale:       scale,
+		offsetX:     max((screenW-width*scale)/2, 0),
+		offsetY:     max((screenH-height*scale)/2, 0),
+		brightness:  255,
+	}
+}
+
+// fbIoctl issues a framebuffer ioctl that fills the struct at arg
+func fbIoctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Init blanks the whole screen, including the borders around the frame
+func (d *FBDevDisplay) Init() error {
+	clear(d.mem)
+	return nil
+}
+
+// Show copies the frame to the screen, magnified and dimmed
+func (d *FBDevDisplay) Show() error {
+	d.mu.Lock()
+	level := d.brightness
+	d.mu.Unlock()
+
+	bpp := d.format.bytesPerPixel
+	var px [4]byte
+	for y := 0; y < d.height; y++ {
+		sy := d.offsetY + y*d.scale
+		if sy >= d.screenH {
+			break
+		}
+		// Build the first screen row of this frame row, then repeat it
+		rowStart := sy*d.lineLength + d.offsetX*bpp
+		row := d.mem[rowStart:]
+		for x := 0; x < d.width; x++ {
+			if d.offsetX+(x+1)*d.scale > d.screenW {
+				break
+			}
+			d.format.put(px[:], d.format.pack(dimColor(d.img.NRGBAAt(x, y), level)))
+			for s := 0; s < d.scale; s++ {
+				copy(row[(x*d.scale+s)*bpp:], px[:bpp])
+			}
+		}
+		rowLen := min(d.width*d.scale, d.screenW-d.offsetX) * bpp
+		for s := 1; s < d.scale && sy+s < d.screenH; s++ {
+			copy(d.mem[rowStart+s*d.lineLength:rowStart+s*d.lineLength+rowLen], row[:rowLen])
+		}
+	}
+	return nil
+}
+
+// Sleep powers the screen down with FBIOBLANK. Framebuffer drivers that
+// cannot blank leave it as it is.
+func (d *FBDevDisplay) Sleep() error {
+	return d.blank(fbBlankPowerdown)
+}
+
+// Wake unblanks the screen
+func (d *FBDevDisplay) Wake() error {
+	return d.blank(fbBlankUnblank)
+}
+
+// blank issues FBIOBLANK, ignoring drivers that do not support it
+func (d *FBDevDisplay) blank(level uintptr) error {
+	if d.file == nil {
+		return nil
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, d.file.Fd(), fbioBlank, level)
+	if errno != 0 && errno != syscall.EINVAL && errno != syscall.ENOTTY {
+		return fmt.Errorf("framebuffer blank failed: %w", errno)
+	}
+	return nil
+}
+
+// Close blanks the screen and unmaps the device
+func (d *FBDevDisplay) Close() error {
+	if d.file == nil {
+		return nil
+	}
+	clear(d.mem)
+	err := syscall.Munmap(d.mem)
+	if cerr := d.file.Close(); err == nil {
+		err = cerr
+	}
+	d.file = nil
+	return err
+}
+
+// SetBrightness dims the frame in software; framebuffers have no
+// brightness control of their own. Takes effect on the next Show.
+func (d *FBDevDisplay) SetBrightness(level uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.brightness = level
+	return nil
+}
@@ -0,0 +1,85 @@
+package display
+
+import (
+	"encoding/binary"
+	"image/color"
+	"testing"
+)
+
+var (
+	fbRGB565   = fbFormat{bytesPerPixel: 2, red: fbBitfield{Offset: 11, Length: 5}, green: fbBitfield{Offset: 5, Length: 6}, blue: fbBitfield{Offset: 0, Length: 5}}
+	fbXRGB8888 = fbFormat{bytesPerPixel: 4, red: fbBitfield{Offset: 16, Length: 8}, green: fbBitfield{Offset: 8, Length: 8}, blue: fbBitfield{Offset: 0, Length: 8}}
+	fbXBGR8888 = fbFormat{bytesPerPixel: 4, red: fbBitfield{Offset: 0, Length: 8}, green: fbBitfield{Offset: 8, Length: 8}, blue: fbBitfield{Offset: 16, Length: 8}}
+)
+
+func TestFBFormatPack(t *testing.T) {
+	c := color.NRGBA{R: 0xFF, G: 0x80, B: 0x10, A: 255}
+	tests := []struct {
+		name   string
+		format fbFormat
+		want   uint32
+	}{
+		{"rgb565", fbRGB565, 0xF800 | 0x20<<5 | 0x02},
+		{"xrgb8888", fbXRGB8888, 0xFF8010},
+		{"xbgr8888", fbXBGR8888, 0x1080FF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.pack(c); got != tt.want {
+				t.Errorf("pack() = %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFBDevDisplayScalesAndCentres(t *testing.T) {
+	// 10x5 screen, 4x2 frame: scale 2, one pixel border left and right
+	const screenW, screenH, lineLength = 10, 5, 10 * 4
+	mem := make([]byte, lineLength*screenH)
+	d := newFBDevDisplay(mem, fbXRGB8888, lineLength, screenW, screenH, 4, 2)
+
+	if err := d.DrawPixelColor(0, 0, color.NRGBA{R: 255, A: 255}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+
+	at := func(x, y int) uint32 {
+		return binary.NativeEndian.Uint32(mem[y*lineLength+x*4:])
+	}
+	// offsetY is (5-4)/2 = 0, offsetX is (10-8)/2 = 1
+	for _, p := range [][2]int{{1, 0}, {2, 0}, {1, 1}, {2, 1}} {
+		if got := at(p[0], p[1]); got != 0xFF0000 {
+			t.Errorf("screen pixel %v = %#x, want red", p, got)
+		}
+	}
+	for _, p := range [][2]int{{0, 0}, {3, 0}, {1, 2}, {9, 1}} {
+		if got := at(p[0], p[1]); got != 0 {
+			t.Errorf("screen pixel %v = %#x, want black", p, got)
+		}
+	}
+
+	// Software brightness halves the channel values
+	_ = d.SetBrightness(128)
+	_ = d.Show()
+	if got := at(1, 0); got != 0x800000 {
+		t.Errorf("dimmed pixel = %#x, want 0x800000", got)
+	}
+}
+
+func TestFBDevDisplayClipsLargeFrame(t *testing.T) {
+	// A frame larger than the screen is drawn at scale 1 and clipped
+	const screenW, screenH, lineLength = 3, 2, 3 * 2
+	mem := make([]byte, lineLength*screenH)
+	d := newFBDevDisplay(mem, fbRGB565, lineLength, screenW, screenH, 4, 4)
+	_ = d.FillRectColor(0, 0, 4, 4, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+	for i := 0; i < len(mem); i += 2 {
+		if got := binary.NativeEndian.Uint16(mem[i:]); got != 0xFFFF {
+			t.Fatalf("screen pixel %d = %#x, want white", i/2, got)
+		}
+	}
+}
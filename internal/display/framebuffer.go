@@ -0,0 +1,247 @@
+package display
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"unicode/utf8"
+)
+
+// ColorModel describes what a panel can show, which decides how drawn
+// colours are stored and how the frame is encoded for transfer
+type ColorModel int
+
+const (
+	// ColorModelMono is a 1-bit panel (SSD1306, SH1106). Colours are
+	// thresholded to white or black as they are drawn.
+	ColorModelMono ColorModel = iota
+	// ColorModelRGB565 is a 16-bit colour panel (ST7735, UCTRONICS).
+	// Colours are kept at full depth and quantized when encoded.
+	ColorModelRGB565
+)
+
+var (
+	fbWhite = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	fbBlack = color.NRGBA{A: 255}
+)
+
+// Framebuffer is the off-screen frame shared by all drivers. It implements
+// every drawing method of the Display and ColorDisplay interfaces, so a
+// driver embeds it and only has to provide the transport: Init, Show, Close
+// and SetBrightness.
+type Framebuffer struct {
+	img     *image.NRGBA
+	model   ColorModel
+	width   int
+	height  int
+	mirrorX bool // flip left to right as pixels are stored
+	mirrorY bool // flip top to bottom as pixels are stored
+}
+
+// NewFramebuffer creates a black frame of the given size and colour model
+func NewFramebuffer(width, height int, model ColorModel) *Framebuffer {
+	fb := &Framebuffer{
+		img:    image.NewNRGBA(image.Rect(0, 0, width, height)),
+		model:  model,
+		width:  width,
+		height: height,
+	}
+	_ = fb.Clear() // #nosec G104 -- Clear on an in-memory buffer cannot fail
+	return fb
+}
+
+// Image returns the frame. It is not copied; drivers pass it to the
+// transport when flushing.
+func (fb *Framebuffer) Image() *image.NRGBA {
+	return fb.img
+}
+
+// SetMirror flips everything drawn from now on left to right (x) and/or
+// top to bottom (y), for panels viewed through a mirror or mounted
+// reversed. The frame is stored as the panel shows it, so every driver's
+// flush sends the mirrored picture without changes.
+func (fb *Framebuffer) SetMirror(x, y bool) {
+	fb.mirrorX, fb.mirrorY = x, y
+}
+
+// ColorModel returns the colour model the frame was created with
+func (fb *Framebuffer) ColorModel() ColorModel {
+	return fb.model
+}
+
+// Clear fills the frame with black without flushing to the display
+func (fb *Framebuffer) Clear() error {
+	draw.Draw(fb.img, fb.img.Bounds(), &image.Uniform{fbBlack}, image.Point{}, draw.Src)
+	return nil
+}
+
+// DrawText draws text as simple character outlines. Real text is rendered
+// by the renderer package and arrives through DrawImage.
+func (fb *Framebuffer) DrawText(x, y int, text string, size int) error {
+	charWidth := size / 2
+	textCells(x, utf8.RuneCountInString(text), charWidth, fb.width, func(cx int) {
+		rectPixels(cx, y, charWidth-1, size, fb.width, fb.height, false, func(px, py int) {
+			fb.set(px, py, fbWhite)
+		})
+	})
+	return nil
+}
+
+// DrawLine draws a horizontal line
+func (fb *Framebuffer) DrawLine(x, y, width int) error {
+	return fb.DrawRect(x, y, width, 1, true)
+}
+
+// DrawPixel sets a single pixel (white if on, black if off)
+func (fb *Framebuffer) DrawPixel(x, y int, on bool) error {
+	if on {
+		fb.set(x, y, fbWhite)
+	} else {
+		fb.set(x, y, fbBlack)
+	}
+	return nil
+}
+
+// DrawRect draws a white rectangle outline or filled rectangle
+func (fb *Framebuffer) DrawRect(x, y, width, height int, fill bool) error {
+	rectPixels(x, y, width, height, fb.width, fb.height, fill, func(px, py int) {
+		fb.set(px, py, fbWhite)
+	})
+	return nil
+}
+
+// DrawImage composites an image at the specified position. Mostly
+// transparent source pixels become black. On colour panels source colours
+// are preserved; on mono panels a pixel is lit when its brightest channel is
+// above half, so saturated colours (e.g. pure green) still show as white.
+func (fb *Framebuffer) DrawImage(x, y int, src image.Image) error {
+	bounds := src.Bounds()
+	rectPixels(x, y, bounds.Dx(), bounds.Dy(), fb.width, fb.height, true, func(px, py int) {
+		fb.set(px, py, fb.convert(src.At(bounds.Min.X+px-x, bounds.Min.Y+py-y)))
+	})
+	return nil
+}
+
+// DrawPixelColor sets a single pixel, converted for the panel's colour model
+func (fb *Framebuffer) DrawPixelColor(x, y int, c color.Color) error {
+	fb.set(x, y, fb.convert(c))
+	return nil
+}
+
+// FillRectColor fills a rectangle, converted for the panel's colour model
+func (fb *Framebuffer) FillRectColor(x, y, width, height int, c color.Color) error {
+	fill := fb.convert(c)
+	xlo, xhi := clipSpan(x, width, fb.width)
+	ylo, yhi := clipSpan(y, height, fb.height)
+	if fb.mirrorX {
+		xlo, xhi = fb.width-xhi, fb.width-xlo
+	}
+	if fb.mirrorY {
+		ylo, yhi = fb.height-yhi, fb.height-ylo
+	}
+	draw.Draw(fb.img, image.Rect(xlo, ylo, xhi, yhi), &image.Uniform{fill}, image.Point{}, draw.Src)
+	return nil
+}
+
+// Capabilities reports the colour depth of the frame
+func (fb *Framebuffer) Capabilities() Capabilities {
+	if fb.model == ColorModelMono {
+		return Capabilities{ColorDepth: 1}
+	}
+	return Capabilities{ColorDepth: 16}
+}
+
+// convert maps a source colour onto what the panel can show
+func (fb *Framebuffer) convert(c color.Color) color.NRGBA {
+	if fb.model == ColorModelMono {
+		if isLit(c) {
+			return fbWhite
+		}
+		return fbBlack
+	}
+	r, g, b, a := c.RGBA()
+	if a <= 32768 {
+		return fbBlack
+	}
+	return color.NRGBA{
+		R: uint8(r >> 8), /* #nosec G115 -- RGBA() >> 8 always fits uint8 */
+		G: uint8(g >> 8), /* #nosec G115 -- RGBA() >> 8 always fits uint8 */
+		B: uint8(b >> 8), /* #nosec G115 -- RGBA() >> 8 always fits uint8 */
+		A: 255,
+	}
+}
+
+// set writes a pixel, ignoring coordinates outside the frame
+func (fb *Framebuffer) set(x, y int, c color.NRGBA) {
+	if x < 0 || x >= fb.width || y < 0 || y >= fb.height {
+		return
+	}
+	if fb.mirrorX {
+		x = fb.width - 1 - x
+	}
+	if fb.mirrorY {
+		y = fb.height - 1 - y
+	}
+	fb.img.SetNRGBA(x, y, c)
+}
+
+// GetBounds returns the frame dimensions
+func (fb *Framebuffer) GetBounds() image.Rectangle {
+	return fb.img.Bounds()
+}
+
+// GetBuffer returns the frame encoded for the panel: one bit per pixel in
+// SSD1306 page order for mono panels, big-endian RGB565 for colour panels
+func (fb *Framebuffer) GetBuffer() []byte {
+	if fb.model == ColorModelMono {
+		return fb.MonoPages()
+	}
+	return fb.RGB565()
+}
+
+// BufferFormat reports how GetBuffer encodes the frame
+func (fb *Framebuffer) BufferFormat() BufferFormat {
+	if fb.model == ColorModelMono {
+		return BufferFormatMonoPages
+	}
+	return BufferFormatRGB565
+}
+
+// MonoPages packs the frame into SSD1306 page order: each byte holds eight
+// vertically stacked pixels, least significant bit at the top
+func (fb *Framebuffer) MonoPages() []byte {
+	buf := make([]byte, BufferFormatMonoPages.BufferSize(fb.width, fb.height))
+	for y := 0; y < fb.height; y++ {
+		for x := 0; x < fb.width; x++ {
+			if fb.img.NRGBAAt(x, y).R > 128 {
+				byteIdx := x + (y/8)*fb.width
+				bitIdx := uint(y % 8) /* #nosec G115 -- modulo 8 is always 0–7 */
+				buf[byteIdx] |= 1 << bitIdx
+			}
+		}
+	}
+	return buf
+}
+
+// RGB565 encodes the frame as big-endian RGB565, row by row
+func (fb *Framebuffer) RGB565() []byte {
+	buf := make([]byte, BufferFormatRGB565.BufferSize(fb.width, fb.height))
+	idx := 0
+	for y := 0; y < fb.height; y++ {
+		for x := 0; x < fb.width; x++ {
+			rgb565 := nrgbaToRGB565(fb.img.NRGBAAt(x, y))
+			buf[idx] = byte(rgb565 >> 8) // #nosec G115 -- uint16 to byte truncation is intentional
+			buf[idx+1] = byte(rgb565)    // #nosec G115 -- uint16 to byte truncation is intentional
+			idx += 2
+		}
+	}
+	return buf
+}
+
+// nrgbaToRGB565 converts an NRGBA colour to a 16-bit RGB565 value.
+func nrgbaToRGB565(c color.NRGBA) uint16 {
+	r := uint16(c.R) >> 3
+	g := uint16(c.G) >> 2
+	b := uint16(c.B) >> 3
+	return (r << 11) | (g << 5) | b
+}
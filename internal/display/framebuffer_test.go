@@ -0,0 +1,173 @@
+package display
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func solidImage(w, h int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestFramebufferDrawImageColorModels(t *testing.T) {
+	green := color.NRGBA{G: 255, A: 255}
+	dimGrey := color.NRGBA{R: 60, G: 60, B: 60, A: 255}
+
+	tests := []struct {
+		name  string
+		model ColorModel
+		src   color.Color
+		want  color.NRGBA
+	}{
+		{"mono saturated colour lights pixel", ColorModelMono, green, fbWhite},
+		{"mono dark colour stays off", ColorModelMono, dimGrey, fbBlack},
+		{"colour preserved", ColorModelRGB565, green, green},
+		{"transparent becomes black", ColorModelRGB565, color.NRGBA{R: 255}, fbBlack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := NewFramebuffer(16, 8, tt.model)
+			if err := fb.DrawImage(2, 2, solidImage(2, 2, tt.src)); err != nil {
+				t.Fatalf("DrawImage() failed: %v", err)
+			}
+			if got := fb.Image().NRGBAAt(3, 3); got != tt.want {
+				t.Errorf("pixel = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFramebufferClipping(t *testing.T) {
+	fb := NewFramebuffer(8, 8, ColorModelMono)
+
+	// None of these may panic; the visible parts must still be drawn
+	if err := fb.DrawRect(-2, -2, 6, 6, false); err != nil {
+		t.Fatalf("DrawRect() failed: %v", err)
+	}
+	if err := fb.DrawLine(6, 7, 10); err != nil {
+		t.Fatalf("DrawLine() failed: %v", err)
+	}
+	if err := fb.DrawPixel(100, 100, true); err != nil {
+		t.Fatalf("DrawPixel() failed: %v", err)
+	}
+	if err := fb.DrawImage(-1, 6, solidImage(4, 4, color.White)); err != nil {
+		t.Fatalf("DrawImage() failed: %v", err)
+	}
+
+	lit := map[image.Point]bool{{3, 0}: true, {0, 3}: true, {7, 7}: true, {0, 7}: true}
+	for p := range lit {
+		if fb.Image().NRGBAAt(p.X, p.Y) != fbWhite {
+			t.Errorf("expected pixel %v to be lit", p)
+		}
+	}
+}
+
+func TestFramebufferEncoding(t *testing.T) {
+	mono := NewFramebuffer(8, 16, ColorModelMono)
+	mono.DrawPixel(1, 0, true)
+	mono.DrawPixel(1, 9, true)
+	buf := mono.GetBuffer()
+	if len(buf) != 16 {
+		t.Fatalf("mono buffer length = %d, want 16", len(buf))
+	}
+	if buf[1] != 0x01 || buf[8+1] != 0x02 {
+		t.Errorf("unexpected page packing: % X", buf)
+	}
+
+	colour := NewFramebuffer(2, 1, ColorModelRGB565)
+	colour.DrawImage(0, 0, solidImage(1, 1, color.NRGBA{R: 255, A: 255}))
+	colour.DrawImage(1, 0, solidImage(1, 1, color.NRGBA{B: 255, A: 255}))
+	if want := []byte{0xF8, 0x00, 0x00, 0x1F}; !bytes.Equal(colour.GetBuffer(), want) {
+		t.Errorf("RGB565 = % X, want % X", colour.GetBuffer(), want)
+	}
+}
+
+func TestFramebufferColorPrimitives(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+
+	colour := NewFramebuffer(8, 8, ColorModelRGB565)
+	if !colour.Capabilities().Color() {
+		t.Error("expected RGB565 frame to report colour")
+	}
+	colour.FillRectColor(-2, -2, 4, 4, red)
+	colour.DrawPixelColor(5, 5, ColorOn)
+	if colour.Image().NRGBAAt(1, 1) != red || colour.Image().NRGBAAt(2, 2) != fbBlack {
+		t.Error("expected clipped red fill in the top-left corner")
+	}
+	if colour.Image().NRGBAAt(5, 5) != fbWhite {
+		t.Error("expected white pixel at (5,5)")
+	}
+
+	mono := NewFramebuffer(8, 8, ColorModelMono)
+	if mono.Capabilities().ColorDepth != 1 {
+		t.Errorf("mono ColorDepth = %d, want 1", mono.Capabilities().ColorDepth)
+	}
+	mono.DrawPixelColor(1, 1, red)
+	if mono.Image().NRGBAAt(1, 1) != fbWhite {
+		t.Error("expected saturated colour to light a mono pixel")
+	}
+}
+
+func TestFramebufferMirror(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	tests := []struct {
+		name     string
+		mx, my   bool
+		px, rect image.Point // where the pixel at (1,2) and the 2x1 fill at (0,0) land
+	}{
+		{"none", false, false, image.Pt(1, 2), image.Pt(0, 0)},
+		{"x", true, false, image.Pt(6, 2), image.Pt(6, 0)},
+		{"y", false, true, image.Pt(1, 5), image.Pt(0, 7)},
+		{"both", true, true, image.Pt(6, 5), image.Pt(6, 7)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := NewFramebuffer(8, 8, ColorModelRGB565)
+			fb.SetMirror(tt.mx, tt.my)
+			fb.DrawPixel(1, 2, true)
+			fb.FillRectColor(0, 0, 2, 1, red)
+			if fb.Image().NRGBAAt(tt.px.X, tt.px.Y) != fbWhite {
+				t.Errorf("expected the pixel at %v", tt.px)
+			}
+			if fb.Image().NRGBAAt(tt.rect.X, tt.rect.Y) != red || fb.Image().NRGBAAt(tt.rect.X+1, tt.rect.Y) != red {
+				t.Errorf("expected the fill at %v", tt.rect)
+			}
+
+			// Images are flipped too, not just moved
+			img := NewFramebuffer(8, 8, ColorModelRGB565)
+			img.SetMirror(tt.mx, tt.my)
+			img.DrawImage(1, 2, solidImage(1, 1, color.White))
+			if img.Image().NRGBAAt(tt.px.X, tt.px.Y) != fbWhite {
+				t.Errorf("expected the image pixel at %v", tt.px)
+			}
+		})
+	}
+}
+
+func TestAsColorDisplayAdaptsMonoDisplays(t *testing.T) {
+	mock := NewMockDisplay(16, 16)
+	cd := AsColorDisplay(mock)
+
+	if cd.Capabilities().Color() {
+		t.Error("expected adapted display to report monochrome")
+	}
+	cd.FillRectColor(0, 0, 4, 4, color.NRGBA{G: 200, A: 255})
+	cd.FillRectColor(1, 1, 2, 2, color.Black)
+	if !mock.GetPixel(0, 0) || mock.GetPixel(1, 1) {
+		t.Error("expected colour fill to light pixels and black fill to clear them")
+	}
+
+	off := NewOffscreenDisplay(8, 8)
+	if AsColorDisplay(off) != ColorDisplay(off) {
+		t.Error("expected native ColorDisplay to be returned unchanged")
+	}
+}
@@ -0,0 +1,205 @@
+package display
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+	"unicode/utf8"
+)
+
+// fuzzWidth and fuzzHeight size the frames drawn on by the fuzz targets
+const (
+	fuzzWidth  = 32
+	fuzzHeight = 16
+)
+
+// fuzzTarget is a display under fuzzing and how to read back its pixels in
+// drawing coordinates
+type fuzzTarget struct {
+	name string
+	disp Display
+	lit  func(x, y int) bool
+}
+
+// fuzzTargets returns a fresh instance of every drawing implementation: the
+// mock and the shared driver framebuffer in each colour model, mirrored and
+// not
+func fuzzTargets() []fuzzTarget {
+	mock := NewMockDisplay(fuzzWidth, fuzzHeight)
+	targets := []fuzzTarget{{name: "mock", disp: mock, lit: mock.GetPixel}}
+	for _, tt := range []struct {
+		name             string
+		model            ColorModel
+		mirrorX, mirrorY bool
+	}{
+		{"mono", ColorModelMono, false, false},
+		{"rgb565", ColorModelRGB565, false, false},
+		{"mirrored", ColorModelMono, true, true},
+	} {
+		fb := NewFramebuffer(fuzzWidth, fuzzHeight, tt.model)
+		fb.SetMirror(tt.mirrorX, tt.mirrorY)
+		mirrorX, mirrorY := tt.mirrorX, tt.mirrorY
+		targets = append(targets, fuzzTarget{
+			name: tt.name,
+			disp: &OffscreenDisplay{Framebuffer: fb},
+			lit: func(x, y int) bool {
+				if mirrorX {
+					x = fuzzWidth - 1 - x
+				}
+				if mirrorY {
+					y = fuzzHeight - 1 - y
+				}
+				return fb.Image().NRGBAAt(x, y) != fbBlack
+			},
+		})
+	}
+	return targets
+}
+
+// within reports whether p lies in [start, start+length), without
+// overflowing for extreme values
+func within(p, start, length int) bool {
+	return length > 0 && p >= start && uint64(p)-uint64(start) < uint64(length) // #nosec G115 -- p >= start
+}
+
+// inCells reports whether p falls in one of n cells of width w from start,
+// without multiplying them out
+func inCells(p, start, w, n int) bool {
+	return w > 0 && within(p, start, int(^uint(0)>>1)) && (uint64(p)-uint64(start))/uint64(w) < uint64(n) // #nosec G115 -- p >= start
+}
+
+// onEdge reports whether p is the first or last position of
+// [start, start+length)
+func onEdge(p, start, length int) bool {
+	d := uint64(p) - uint64(start) // #nosec G115 -- callers check within first
+	return d == 0 || d == uint64(length)-1
+}
+
+func FuzzDrawRect(f *testing.F) {
+	f.Add(0, 0, fuzzWidth, fuzzHeight, false)
+	f.Add(-3, 5, 10, 0, false)
+	f.Add(30, 14, 5, 5, true)
+	f.Add(math.MinInt32, math.MinInt32, math.MaxInt32, math.MaxInt32, true)
+	f.Fuzz(func(t *testing.T, x, y, w, h int, fill bool) {
+		for _, target := range fuzzTargets() {
+			if err := target.disp.DrawRect(x, y, w, h, fill); err != nil {
+				t.Fatalf("%s: DrawRect failed: %v", target.name, err)
+			}
+			for py := range fuzzHeight {
+				for px := range fuzzWidth {
+					inside := within(px, x, w) && within(py, y, h)
+					edge := inside && (onEdge(px, x, w) || onEdge(py, y, h))
+					if lit := target.lit(px, py); lit != (inside && (fill || edge)) {
+						t.Fatalf("%s: DrawRect(%d, %d, %d, %d, %v): pixel (%d, %d) lit=%v", target.name, x, y, w, h, fill, px, py, lit)
+					}
+				}
+			}
+		}
+	})
+}
+
+func FuzzDrawLine(f *testing.F) {
+	f.Add(0, 0, fuzzWidth)
+	f.Add(-5, 3, 8)
+	f.Add(math.MaxInt32, 1, math.MaxInt32)
+	f.Fuzz(func(t *testing.T, x, y, w int) {
+		for _, target := range fuzzTargets() {
+			if err := target.disp.DrawLine(x, y, w); err != nil {
+				t.Fatalf("%s: DrawLine failed: %v", target.name, err)
+			}
+			for py := range fuzzHeight {
+				for px := range fuzzWidth {
+					want := py == y && within(px, x, w)
+					if lit := target.lit(px, py); lit != want {
+						t.Fatalf("%s: DrawLine(%d, %d, %d): pixel (%d, %d) lit=%v", target.name, x, y, w, px, py, lit)
+					}
+				}
+			}
+		}
+	})
+}
+
+func FuzzDrawImage(f *testing.F) {
+	f.Add(0, 0, 8, 8, 0, 0)
+	f.Add(-4, 12, 10, 6, 3, -2)
+	f.Add(math.MaxInt32, math.MinInt32, 1, 1, 0, 0)
+	f.Fuzz(func(t *testing.T, x, y, w, h, minX, minY int) {
+		// Keep the source small; its origin need not be (0, 0)
+		w, h = min(max(w, 0), 64), min(max(h, 0), 64)
+		minX, minY = minX%1000, minY%1000
+		src := image.NewNRGBA(image.Rect(minX, minY, minX+w, minY+h))
+		for i := range src.Pix {
+			src.Pix[i] = 255
+		}
+		for _, target := range fuzzTargets() {
+			if err := target.disp.DrawImage(x, y, src); err != nil {
+				t.Fatalf("%s: DrawImage failed: %v", target.name, err)
+			}
+			for py := range fuzzHeight {
+				for px := range fuzzWidth {
+					want := within(px, x, w) && within(py, y, h)
+					if lit := target.lit(px, py); lit != want {
+						t.Fatalf("%s: DrawImage(%d, %d) of %v: pixel (%d, %d) lit=%v", target.name, x, y, src.Bounds(), px, py, lit)
+					}
+				}
+			}
+		}
+	})
+}
+
+func FuzzDrawText(f *testing.F) {
+	f.Add(0, 0, "hello", 8)
+	f.Add(-7, 3, "héllo wörld", 1)
+	f.Add(math.MaxInt32, 0, "x", math.MaxInt32)
+	f.Fuzz(func(t *testing.T, x, y int, text string, size int) {
+		// Longer text only runs further off the frame, and makes the
+		// fuzzer slow to minimise
+		if !utf8.ValidString(text) || len(text) > 64 {
+			return
+		}
+		for _, target := range fuzzTargets() {
+			if err := target.disp.DrawText(x, y, text, size); err != nil {
+				t.Fatalf("%s: DrawText failed: %v", target.name, err)
+			}
+			// Text stays in its row of cells
+			for py := range fuzzHeight {
+				for px := range fuzzWidth {
+					if target.lit(px, py) && !(inCells(px, x, size/2, utf8.RuneCountInString(text)) && within(py, y, size)) {
+						t.Fatalf("%s: DrawText(%d, %d, %q, %d): pixel (%d, %d) outside the text", target.name, x, y, text, size, px, py)
+					}
+				}
+			}
+		}
+	})
+}
+
+// The colour primitives clip the same way
+func FuzzFillRectColor(f *testing.F) {
+	f.Add(0, 0, 4, 4, false)
+	f.Add(math.MinInt32, 3, math.MaxInt32, 2, true)
+	f.Fuzz(func(t *testing.T, x, y, w, h int, black bool) {
+		for _, target := range fuzzTargets() {
+			cd := AsColorDisplay(target.disp)
+			c := color.Color(color.White)
+			if black {
+				// Black shows against a white frame
+				if err := cd.FillRectColor(0, 0, fuzzWidth, fuzzHeight, color.White); err != nil {
+					t.Fatalf("%s: FillRectColor failed: %v", target.name, err)
+				}
+				c = color.Black
+			}
+			if err := cd.FillRectColor(x, y, w, h, c); err != nil {
+				t.Fatalf("%s: FillRectColor failed: %v", target.name, err)
+			}
+			for py := range fuzzHeight {
+				for px := range fuzzWidth {
+					want := (within(px, x, w) && within(py, y, h)) != black
+					if lit := target.lit(px, py); lit != want {
+						t.Fatalf("%s: FillRectColor(%d, %d, %d, %d, black=%v): pixel (%d, %d) lit=%v", target.name, x, y, w, h, black, px, py, lit)
+					}
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/physic"
+)
+
+// PCF8574 backpack pin mapping. The common I2C backpacks wire the expander's
+// P0-P2 to RS, RW and EN, P3 to the backlight transistor and P4-P7 to the
+// LCD's D4-D7, so the controller is driven in 4-bit mode.
+const (
+	hd44780RS        byte = 0x01
+	hd44780EN        byte = 0x04
+	hd44780Backlight byte = 0x08
+)
+
+// HD44780 commands
+const (
+	hd44780Clear        byte = 0x01
+	hd44780EntryMode    byte = 0x06 // cursor moves right, no display shift
+	hd44780DisplayOff   byte = 0x08
+	hd44780DisplayOn    byte = 0x0C // display on, cursor and blink off
+	hd44780FunctionSet  byte = 0x28 // 4-bit bus, 2 lines, 5x8 dots
+	hd44780SetDDRAMAddr byte = 0x80
+)
+
+// HD44780 character cell size in pixels, used to size the framebuffer
+const (
+	hd44780CellWidth  = 5
+	hd44780CellHeight = 8
+)
+
+// hd44780RowOffsets are the DDRAM addresses of the start of each row. Rows 2
+// and 3 of 4-line panels continue rows 0 and 1 in memory.
+var hd44780RowOffsets = [4]byte{0x00, 0x40, 0x14, 0x54}
+
+// hd44780Degree is the degree sign in the A00 character ROM
+const hd44780Degree byte = 0xDF
+
+// HD44780Display implements Display for HD44780 character LCDs (16x2, 20x4)
+// behind a PCF8574 I2C backpack. The panel only shows text set with
+// WriteLines; pixel drawing goes to an unused framebuffer so the display
+// still satisfies the Display interface.
+type HD44780Display struct {
+	*Framebuffer
+	conn       *i2c.Dev
+	bus        i2c.BusCloser // nil when the bus is owned by the caller
+	cols, rows int
+	delay      func(time.Duration) // time.Sleep, replaced in tests
+
+	mu        sync.Mutex
+	backlight byte
+	pending   [][]byte // rows to show on the next Show
+	shown     [][]byte // rows on the panel, nil until written
+}
+
+// NewHD44780Display creates a driver for a cols x rows character LCD on the
+// PCF8574 backpack at i2cAddr. speed is the I2C clock, 0 to keep the bus's
+// current speed; the PCF8574 is only rated for 100 kHz.
+func NewHD44780Display(i2cBus, i2cAddr string, speed physic.Frequency, cols, rows int) (*HD44780Display, error) {
+	bus, err := openI2C("hd44780", i2cBus, speed)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := parseI2CAddr(i2cAddr)
+	if err != nil {
+		bus.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, err
+	}
+
+	d, err := newHD44780OnBus(bus, addr, cols, rows)
+	if err != nil {
+		bus.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, err
+	}
+	d.bus = bus
+	return d, nil
+}
+
+// newHD44780OnBus creates the driver on an already opened bus
+func newHD44780OnBus(bus i2c.Bus, addr uint16, cols, rows int) (*HD44780Display, error) {
+	if cols <= 0 || rows <= 0 || rows > len(hd44780RowOffsets) {
+		return nil, fmt.Errorf("unsupported HD44780 geometry %dx%d", cols, rows)
+	}
+	d := &HD44780Display{
+		Framebuffer: NewFramebuffer(cols*hd44780CellWidth, rows*hd44780CellHeight, ColorModelMono),
+		conn:        &i2c.Dev{Bus: bus, Addr: addr},
+		cols:        cols,
+		rows:        rows,
+		delay:       time.Sleep,
+		backlight:   hd44780Backlight,
+	}
+	d.pending = d.blankRows()
+	return d, nil
+}
+
+// Init switches the controller to 4-bit mode and clears the panel. The
+// controller may power up in either 8-bit or 4-bit mode, so the function set
+// is first sent as three 8-bit nibbles (HD44780 datasheet, figure 24).
+func (d *HD44780Display) Init() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, nibble := range []byte{0x30, 0x30, 0x30, 0x20} {
+		if err := d.conn.Tx(d.pulse(nibble), nil); err != nil {
+			return fmt.Errorf("failed to initialize LCD: %w", err)
+		}
+		d.delay(5 * time.Millisecond)
+	}
+	for _, cmd := range []byte{hd44780FunctionSet, hd44780DisplayOn, hd44780Clear, hd44780EntryMode} {
+		if err := d.conn.Tx(d.byteFrames(cmd, 0), nil); err != nil {
+			return fmt.Errorf("failed to initialize LCD: %w", err)
+		}
+		if cmd == hd44780Clear {
+			d.delay(2 * time.Millisecond)
+		}
+	}
+	d.shown = d.blankRows()
+	return nil
+}
+
+// WriteLines sets the text shown by the next Show
+func (d *HD44780Display) WriteLines(lines []string) error {
+	rows := d.blankRows()
+	for i := range min(len(lines), d.rows) {
+		copy(rows[i], encodeHD44780(lines[i]))
+	}
+	d.mu.Lock()
+	d.pending = rows
+	d.mu.Unlock()
+	return nil
+}
+
+// Clear blanks the pending text as well as the framebuffer
+func (d *HD44780Display) Clear() error {
+	d.mu.Lock()
+	d.pending = d.blankRows()
+	d.mu.Unlock()
+	return d.Framebuffer.Clear()
+}
+
+// Show writes the rows that differ from what the panel shows
+func (d *HD44780Display) Show() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	full := d.shown == nil
+	if full {
+		d.shown = d.blankRows()
+	}
+	for i, row := range d.pending {
+		if !full && string(d.shown[i]) == string(row) {
+			continue
+		}
+		frames := d.byteFrames(hd44780SetDDRAMAddr|hd44780RowOffsets[i], 0)
+		for _, c := range row {
+			frames = append(frames, d.byteFrames(c, hd44780RS)...)
+		}
+		if err := d.conn.Tx(frames, nil); err != nil {
+			// The panel contents are unknown now, so rewrite every row next time
+			d.shown = nil
+			return fmt.Errorf("failed to write LCD row %d: %w", i, err)
+		}
+		copy(d.shown[i], row)
+	}
+	return nil
+}
+
+// Sleep switches the backlight and the display off. Wake or Init switches
+// the display on; the backlight follows the next SetBrightness.
+func (d *HD44780Display) Sleep() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.backlight = 0
+	if err := d.conn.Tx(d.byteFrames(hd44780DisplayOff, 0), nil); err != nil {
+		return fmt.Errorf("failed to switch display off: %w", err)
+	}
+	return nil
+}
+
+// Wake switches the display back on, showing the rows as they were last
+// written
+func (d *HD44780Display) Wake() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.conn.Tx(d.byteFrames(hd44780DisplayOn, 0), nil); err != nil {
+		return fmt.Errorf("failed to switch display on: %w", err)
+	}
+	return nil
+}
+
+// Close blanks the panel and switches off the backlight
+func (d *HD44780Display) Close() error {
+	d.mu.Lock()
+	d.backlight = 0
+	err := d.conn.Tx(d.byteFrames(hd44780Clear, 0), nil)
+	d.mu.Unlock()
+	if d.bus != nil {
+		if closeErr := d.bus.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// SetBrightness switches the backlight: the backpack can only turn it on or
+// off, so any non-zero level is on
+func (d *HD44780Display) SetBrightness(level uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.backlight = 0
+	if level > 0 {
+		d.backlight = hd44780Backlight
+	}
+	if err := d.conn.Tx([]byte{d.backlight}, nil); err != nil {
+		return fmt.Errorf("failed to set backlight: %w", err)
+	}
+	return nil
+}
+
+// Capabilities reports the character grid
+func (d *HD44780Display) Capabilities() Capabilities {
+	return Capabilities{ColorDepth: 1, TextColumns: d.cols, TextRows: d.rows}
+}
+
+// byteFrames returns the expander writes that send b as two nibbles, high
+// first, with flags (RS for character data) held throughout. Callers hold mu.
+func (d *HD44780Display) byteFrames(b, flags byte) []byte {
+	return append(d.pulse(b&0xF0|flags), d.pulse(b<<4|flags)...)
+}
+
+// pulse returns the expander writes that latch the high nibble of b: EN is
+// raised and then dropped, and the controller reads the data on the falling
+// edge. At I2C speeds each write lasts far longer than the minimum pulse
+// width. Callers hold mu.
+func (d *HD44780Display) pulse(b byte) []byte {
+	b = b&0xF0 | b&hd44780RS | d.backlight
+	return []byte{b | hd44780EN, b}
+}
+
+// blankRows returns rows of spaces
+func (d *HD44780Display) blankRows() [][]byte {
+	rows := make([][]byte, d.rows)
+	for i := range rows {
+		rows[i] = []byte(strings.Repeat(" ", d.cols))
+	}
+	return rows
+}
+
+// encodeHD44780 converts s to character ROM codes. Printable ASCII maps
+// directly and the degree sign has its own code; anything else becomes '?'.
+func encodeHD44780(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '°':
+			out = append(out, hd44780Degree)
+		case r >= 0x20 && r < 0x7F:
+			out = append(out, byte(r))
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
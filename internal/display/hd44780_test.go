@@ -0,0 +1,219 @@
+package display
+
+import (
+	"testing"
+	"time"
+)
+
+// lcdByte is a byte sent to the HD44780, with whether RS selected data
+type lcdByte struct {
+	data bool
+	b    byte
+}
+
+// decodeLCD reassembles the bytes latched by the expander writes: each EN
+// pulse carries one nibble, high nibble first
+func decodeLCD(t *testing.T, writes []fakeWrite) []lcdByte {
+	t.Helper()
+	var nibbles []byte
+	for _, w := range writes {
+		for _, b := range w.data {
+			if b&hd44780EN != 0 {
+				nibbles = append(nibbles, b)
+			}
+		}
+	}
+	if len(nibbles)%2 != 0 {
+		t.Fatalf("odd number of nibbles: % X", nibbles)
+	}
+	var out []lcdByte
+	for i := 0; i < len(nibbles); i += 2 {
+		out = append(out, lcdByte{
+			data: nibbles[i]&hd44780RS != 0,
+			b:    nibbles[i]&0xF0 | nibbles[i+1]>>4,
+		})
+	}
+	return out
+}
+
+// lcdText returns the data bytes as a string
+func lcdText(bytes []lcdByte) string {
+	var s []byte
+	for _, b := range bytes {
+		if b.data {
+			s = append(s, b.b)
+		}
+	}
+	return string(s)
+}
+
+func newTestHD44780(t *testing.T, cols, rows int) (*HD44780Display, *fakeBus) {
+	t.Helper()
+	bus := &fakeBus{devices: map[uint16]byte{0x27: 0}}
+	d, err := newHD44780OnBus(bus, 0x27, cols, rows)
+	if err != nil {
+		t.Fatalf("newHD44780OnBus() failed: %v", err)
+	}
+	d.delay = func(time.Duration) {}
+	if err := d.Init(); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	return d, bus
+}
+
+func TestHD44780Init(t *testing.T) {
+	_, bus := newTestHD44780(t, 16, 2)
+
+	// Four single-nibble resets, then four full commands
+	if len(bus.writes) != 8 {
+		t.Fatalf("expected 8 writes, got %d", len(bus.writes))
+	}
+	for i, want := range []byte{0x30, 0x30, 0x30, 0x20} {
+		w := bus.writes[i].data
+		if len(w) != 2 || w[0] != want|hd44780EN|hd44780Backlight || w[1] != want|hd44780Backlight {
+			t.Errorf("reset %d: got % X", i, w)
+		}
+	}
+	got := decodeLCD(t, bus.writes[4:])
+	want := []byte{hd44780FunctionSet, hd44780DisplayOn, hd44780Clear, hd44780EntryMode}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d commands, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].data || got[i].b != want[i] {
+			t.Errorf("command %d: got %+v, want 0x%02X", i, got[i], want[i])
+		}
+	}
+}
+
+func TestHD44780Show(t *testing.T) {
+	d, bus := newTestHD44780(t, 16, 2)
+	bus.writes = nil
+
+	if err := d.WriteLines([]string{"Hello", "CPU 45.0°C and more text"}); err != nil {
+		t.Fatalf("WriteLines() failed: %v", err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 2 {
+		t.Fatalf("expected one write per row, got %d", len(bus.writes))
+	}
+	row0 := decodeLCD(t, bus.writes[:1])
+	if row0[0].data || row0[0].b != hd44780SetDDRAMAddr {
+		t.Errorf("row 0 should start with the DDRAM address, got %+v", row0[0])
+	}
+	if got := lcdText(row0); got != "Hello           " {
+		t.Errorf("row 0: got %q", got)
+	}
+	row1 := decodeLCD(t, bus.writes[1:])
+	if row1[0].b != hd44780SetDDRAMAddr|0x40 {
+		t.Errorf("row 1 should start at 0x40, got 0x%02X", row1[0].b)
+	}
+	if got := lcdText(row1); got != "CPU 45.0\xDFC and m" {
+		t.Errorf("row 1: got %q", got)
+	}
+
+	// Only the changed row is rewritten
+	bus.writes = nil
+	if err := d.WriteLines([]string{"Hello", "Bye"}); err != nil {
+		t.Fatalf("WriteLines() failed: %v", err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 1 {
+		t.Fatalf("expected 1 write, got %d", len(bus.writes))
+	}
+	if got := lcdText(decodeLCD(t, bus.writes)); got != "Bye             " {
+		t.Errorf("row 1: got %q", got)
+	}
+
+	// Nothing changed, nothing written
+	bus.writes = nil
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 0 {
+		t.Errorf("expected no writes, got %d", len(bus.writes))
+	}
+}
+
+func TestHD44780ShowErrorRewritesAll(t *testing.T) {
+	d, bus := newTestHD44780(t, 16, 2)
+	if err := d.WriteLines([]string{"one", "two"}); err != nil {
+		t.Fatalf("WriteLines() failed: %v", err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+
+	// The blank second row must be rewritten too: the panel state is unknown
+	delete(bus.devices, 0x27)
+	if err := d.WriteLines([]string{"three"}); err != nil {
+		t.Fatalf("WriteLines() failed: %v", err)
+	}
+	if err := d.Show(); err == nil {
+		t.Fatal("expected error when the bus write fails")
+	}
+
+	bus.devices[0x27] = 0
+	bus.writes = nil
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 2 {
+		t.Errorf("expected both rows rewritten after a failure, got %d writes", len(bus.writes))
+	}
+}
+
+func TestHD44780SetBrightness(t *testing.T) {
+	d, bus := newTestHD44780(t, 20, 4)
+	bus.writes = nil
+
+	if err := d.SetBrightness(0); err != nil {
+		t.Fatalf("SetBrightness(0) failed: %v", err)
+	}
+	if err := d.SetBrightness(10); err != nil {
+		t.Fatalf("SetBrightness(10) failed: %v", err)
+	}
+	if len(bus.writes) != 2 {
+		t.Fatalf("expected 2 writes, got %d", len(bus.writes))
+	}
+	if got := bus.writes[0].data; len(got) != 1 || got[0]&hd44780Backlight != 0 {
+		t.Errorf("brightness 0 should switch the backlight off, got % X", got)
+	}
+	if got := bus.writes[1].data; len(got) != 1 || got[0]&hd44780Backlight == 0 {
+		t.Errorf("non-zero brightness should switch the backlight on, got % X", got)
+	}
+}
+
+func TestHD44780Capabilities(t *testing.T) {
+	d, _ := newTestHD44780(t, 20, 4)
+	caps := d.Capabilities()
+	if !caps.Text() || caps.TextColumns != 20 || caps.TextRows != 4 {
+		t.Errorf("unexpected capabilities %+v", caps)
+	}
+	if b := d.GetBounds(); b.Dx() != 100 || b.Dy() != 32 {
+		t.Errorf("expected 100x32 bounds, got %v", b)
+	}
+
+	// Wrappers forward the text grid and the text
+	shift := NewShiftDisplay(NewCountingDisplay(d))
+	if !AsColorDisplay(shift).Capabilities().Text() {
+		t.Error("wrapped display should report a text grid")
+	}
+	if err := WriteLines(shift, []string{"wrapped"}); err != nil {
+		t.Errorf("WriteLines() through wrappers failed: %v", err)
+	}
+	if err := WriteLines(NewMockDisplay(128, 64), []string{"x"}); err == nil {
+		t.Error("expected WriteLines to fail on a pixel display")
+	}
+}
+
+func TestHD44780InvalidGeometry(t *testing.T) {
+	bus := &fakeBus{devices: map[uint16]byte{0x27: 0}}
+	if _, err := newHD44780OnBus(bus, 0x27, 40, 8); err == nil {
+		t.Error("expected error for more than 4 rows")
+	}
+}
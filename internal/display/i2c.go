@@ -0,0 +1,60 @@
+package display
+
+import (
+	"fmt"
+	"log"
+
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/host/v3"
+)
+
+// openI2C opens an I2C bus and, when speed is non-zero, sets its clock.
+// periph can only change the clock on some hosts (the Raspberry Pi's
+// BCM283x); elsewhere the kernel's speed is kept and a warning is logged.
+// name prefixes log messages.
+func openI2C(name, i2cBus string, speed physic.Frequency) (i2c.BusCloser, error) {
+	if _, err := host.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize periph: %w", err)
+	}
+
+	bus, err := i2creg.Open(i2cBus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open I2C bus %s: %w", i2cBus, err)
+	}
+
+	if speed > 0 {
+		if err := bus.SetSpeed(speed); err != nil {
+			log.Printf("%s: cannot set I2C bus %s to %s, keeping the kernel's speed: %v", name, i2cBus, speed, err)
+		}
+	}
+	return bus, nil
+}
+
+// ProbeI2CAddress returns the first of addrs (hex strings such as "0x3C")
+// that acknowledges a read on the I2C bus, so one configuration can serve
+// panels strapped to different addresses.
+func ProbeI2CAddress(i2cBus string, addrs []string) (string, error) {
+	bus, err := openI2C("probe", i2cBus, 0)
+	if err != nil {
+		return "", err
+	}
+	defer bus.Close() // #nosec G104 -- best-effort cleanup after probing
+
+	return probeAddrsOnBus(bus, addrs)
+}
+
+// probeAddrsOnBus probes addrs in order on an open bus
+func probeAddrsOnBus(bus i2c.Bus, addrs []string) (string, error) {
+	for _, s := range addrs {
+		addr, err := parseI2CAddr(s)
+		if err != nil {
+			return "", err
+		}
+		if err := bus.Tx(addr, nil, make([]byte, 1)); err == nil {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("no device answered at %v", addrs)
+}
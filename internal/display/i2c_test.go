@@ -0,0 +1,25 @@
+package display
+
+import "testing"
+
+func TestProbeAddrsOnBus(t *testing.T) {
+	bus := &fakeBus{devices: map[uint16]byte{0x3D: 0, 0x27: 0}}
+
+	got, err := probeAddrsOnBus(bus, []string{"0x3C", "0x3D", "0x27"})
+	if err != nil {
+		t.Fatalf("probeAddrsOnBus() failed: %v", err)
+	}
+	if got != "0x3D" {
+		t.Errorf("probeAddrsOnBus() = %q, want the first answering address 0x3D", got)
+	}
+	if len(bus.probed) != 2 {
+		t.Errorf("expected probing to stop at the first answer, probed %v", bus.probed)
+	}
+
+	if _, err := probeAddrsOnBus(bus, []string{"0x3C", "0x3E"}); err == nil {
+		t.Error("expected error when no address answers")
+	}
+	if _, err := probeAddrsOnBus(bus, []string{"zz"}); err == nil {
+		t.Error("expected error for an invalid address")
+	}
+}
@@ -0,0 +1,185 @@
+package display
+
+import (
+	"fmt"
+	"time"
+
+	"periph.io/x/conn/v3/physic"
+)
+
+// ILI9341 command bytes; the DCS commands it shares with the ST77xx are in
+// tft.go
+const (
+	ili9341GAMMASET = 0x26
+	ili9341VSCRSADD = 0x37
+	ili9341FRMCTR1  = 0xB1
+	ili9341DFUNCTR  = 0xB6
+	ili9341PWCTR1   = 0xC0
+	ili9341PWCTR2   = 0xC1
+	ili9341VMCTR1   = 0xC5
+	ili9341VMCTR2   = 0xC7
+	ili9341PWCTRA   = 0xCB
+	ili9341PWCTRB   = 0xCF
+	ili9341GMCTRP1  = 0xE0
+	ili9341GMCTRN1  = 0xE1
+	ili9341DTCTRA   = 0xE8
+	ili9341DTCTRB   = 0xEA
+	ili9341PWRSEQ   = 0xED
+	ili9341EN3GAM   = 0xF2
+	ili9341PUMPRC   = 0xF7
+)
+
+// ILI9341Display implements Display for 2.4"-3.2" ILI9341 TFT panels
+// (240x320, driven as 320x240 landscape or 240x320 portrait) via SPI. It
+// shares the SPI transport with the ST7735 and ST7789; at 150 KiB a frame
+// is large, so it runs the bus faster by default and relies on the shared
+// path only sending the rows that changed.
+type ILI9341Display struct {
+	*Framebuffer
+	tftSPI
+}
+
+// NewILI9341Display creates a new ILI9341 display driver. speed is the SPI
+// clock, 0 for the default of 32 MHz. The panel is only mirrored in
+// hardware, so rotation must be 0 or 2; the frame size picks landscape or
+// portrait.
+func NewILI9341Display(spiBus, dcPin, rstPin, blPin string, speed physic.Frequency, width, height, rotation int) (*ILI9341Display, error) {
+	madctl, err := ili9341MADCTL(width, height, rotation)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := openTFTSPI("ili9341", spiBus, dcPin, rstPin, blPin, speed, 32*physic.MegaHertz)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &ILI9341Display{
+		tftSPI:      t,
+		Framebuffer: NewFramebuffer(width, height, ColorModelRGB565),
+	}
+
+	if err := d.hardwareReset(); err != nil {
+		d.closeOnError("ili9341")
+		return nil, err
+	}
+
+	if err := d.initSequence(madctl); err != nil {
+		d.closeOnError("ili9341")
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *ILI9341Display) initSequence(madctl byte) error {
+	seq := []func() error{
+		func() error { return d.sendCmd(tftSWRESET) },
+		func() error { time.Sleep(150 * time.Millisecond); return nil },
+		func() error { return d.sendCmdData(ili9341PWCTRB, 0x00, 0xC1, 0x30) },
+		func() error { return d.sendCmdData(ili9341PWRSEQ, 0x64, 0x03, 0x12, 0x81) },
+		func() error { return d.sendCmdData(ili9341DTCTRA, 0x85, 0x00, 0x78) },
+		func() error { return d.sendCmdData(ili9341PWCTRA, 0x39, 0x2C, 0x00, 0x34, 0x02) },
+		func() error { return d.sendCmdData(ili9341PUMPRC, 0x20) },
+		func() error { return d.sendCmdData(ili9341DTCTRB, 0x00, 0x00) },
+		func() error { return d.sendCmdData(ili9341PWCTR1, 0x23) },
+		func() error { return d.sendCmdData(ili9341PWCTR2, 0x10) },
+		func() error { return d.sendCmdData(ili9341VMCTR1, 0x3E, 0x28) },
+		func() error { return d.sendCmdData(ili9341VMCTR2, 0x86) },
+		func() error { return d.sendCmdData(tftMADCTL, madctl) },
+		func() error { return d.sendCmdData(ili9341VSCRSADD, 0x00) },
+		func() error { return d.sendCmdData(tftCOLMOD, 0x55) },            // RGB565
+		func() error { return d.sendCmdData(ili9341FRMCTR1, 0x00, 0x18) }, // 79Hz
+		func() error { return d.sendCmdData(ili9341DFUNCTR, 0x08, 0x82, 0x27) },
+		func() error { return d.sendCmdData(ili9341EN3GAM, 0x00) },
+		func() error { return d.sendCmdData(ili9341GAMMASET, 0x01) },
+		func() error {
+			return d.sendCmdData(ili9341GMCTRP1,
+				0x0F, 0x31, 0x2B, 0x0C, 0x0E, 0x08, 0x4E, 0xF1,
+				0x37, 0x07, 0x10, 0x03, 0x0E, 0x09, 0x00)
+		},
+		func() error {
+			return d.sendCmdData(ili9341GMCTRN1,
+				0x00, 0x0E, 0x14, 0x03, 0x11, 0x07, 0x31, 0xC1,
+				0x48, 0x08, 0x0F, 0x0C, 0x31, 0x36, 0x0F)
+		},
+		func() error { return d.sendCmd(tftSLPOUT) },
+		func() error { time.Sleep(120 * time.Millisecond); return nil },
+		func() error { return d.sendCmd(tftDISPON) },
+		func() error { time.Sleep(100 * time.Millisecond); return nil },
+	}
+
+	for _, step := range seq {
+		if err := step(); err != nil {
+			return fmt.Errorf("ILI9341 init sequence failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// ili9341MADCTL returns the MADCTL byte for the frame orientation and
+// rotation. The controller RAM is 240 columns by 320 rows, so landscape
+// frames exchange rows and columns (MV). The panels have BGR subpixels.
+func ili9341MADCTL(width, height, rotation int) (byte, error) {
+	if rotation != 0 && rotation != 2 {
+		return 0, fmt.Errorf("ILI9341 only supports rotation 0 (0°) and 2 (180°), got %d; use the %dx%d type for the other orientation",
+			rotation, height, width)
+	}
+	flipped := rotation == 2
+	switch {
+	case width == 320 && height == 240 && !flipped:
+		return madctlMV | madctlBGR, nil
+	case width == 320 && height == 240:
+		return madctlMX | madctlMY | madctlMV | madctlBGR, nil
+	case width == 240 && height == 320 && !flipped:
+		return madctlMX | madctlBGR, nil
+	case width == 240 && height == 320:
+		return madctlMY | madctlBGR, nil
+	}
+	return 0, fmt.Errorf("unsupported ILI9341 panel size %dx%d", width, height)
+}
+
+// Init clears the screen and switches the backlight on (the controller is
+// set up in the constructor).
+func (d *ILI9341Display) Init() error {
+	if err := d.wake(); err != nil {
+		return err
+	}
+	d.shown = nil
+	if err := d.Clear(); err != nil {
+		return err
+	}
+	if err := d.Show(); err != nil {
+		return err
+	}
+	return d.SetBrightness(255)
+}
+
+// Show sends the rows that changed since the last frame as RGB565.
+func (d *ILI9341Display) Show() error {
+	return d.writeFrame(d.Framebuffer)
+}
+
+// Sleep switches the backlight and panel off and puts the controller to
+// sleep (SLPIN). Wake or Init wakes it.
+func (d *ILI9341Display) Sleep() error {
+	return d.sleep()
+}
+
+// Wake takes the controller out of sleep (SLPOUT) and switches the panel
+// on, showing its RAM as it was last written. The backlight follows the
+// next SetBrightness.
+func (d *ILI9341Display) Wake() error {
+	return d.wake()
+}
+
+// Close switches the backlight and panel off and closes the SPI port.
+func (d *ILI9341Display) Close() error {
+	return d.close("ili9341")
+}
+
+// SetBrightness drives the backlight pin with PWM. It is a no-op when no
+// bl_pin is configured.
+func (d *ILI9341Display) SetBrightness(level uint8) error {
+	return d.setBrightness(level)
+}
@@ -0,0 +1,28 @@
+package display
+
+import "testing"
+
+func TestILI9341MADCTL(t *testing.T) {
+	tests := []struct {
+		width, height, rotation int
+		want                    byte
+		wantErr                 bool
+	}{
+		{320, 240, 0, madctlMV | madctlBGR, false},
+		{320, 240, 2, madctlMX | madctlMY | madctlMV | madctlBGR, false},
+		{240, 320, 0, madctlMX | madctlBGR, false},
+		{240, 320, 2, madctlMY | madctlBGR, false},
+		{320, 240, 1, 0, true},
+		{128, 160, 0, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ili9341MADCTL(tt.width, tt.height, tt.rotation)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%dx%d rotation %d: error = %v, wantErr %v", tt.width, tt.height, tt.rotation, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%dx%d rotation %d: got 0x%02X, want 0x%02X", tt.width, tt.height, tt.rotation, got, tt.want)
+		}
+	}
+}
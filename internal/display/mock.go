@@ -5,6 +5,7 @@ import (
 	"image"
 	"strings"
 	"sync"
+	"unicode/utf8"
 )
 
 // MockDisplay is a mock implementation for testing
@@ -24,7 +25,7 @@ func NewMockDisplay(width, height int) *MockDisplay {
 	return &MockDisplay{
 		width:  width,
 		height: height,
-		buffer: make([]byte, width*height/8),
+		buffer: make([]byte, BufferFormatMonoPages.BufferSize(width, height)),
 		calls:  make([]string, 0),
 	}
 }
@@ -106,21 +107,13 @@ func (m *MockDisplay) DrawText(x, y int, text string, size int) error {
 		return err
 	}
 
-	// Simulate text rendering by setting pixels in approximate area
+	// Simulate text rendering by filling each character's cell
 	charWidth := size / 2
-	for i, ch := range text {
-		startX := x + i*charWidth
-		if startX >= m.width {
-			break
-		}
-		// Set a few pixels to simulate the character
-		for dx := 0; dx < charWidth && startX+dx < m.width; dx++ {
-			for dy := 0; dy < size && y+dy < m.height; dy++ {
-				m.setPixel(startX+dx, y+dy, true)
-			}
-		}
-		_ = ch // Avoid unused variable
-	}
+	textCells(x, utf8.RuneCountInString(text), charWidth, m.width, func(cx int) {
+		rectPixels(cx, y, charWidth, size, m.width, m.height, true, func(px, py int) {
+			m.setPixel(px, py, true)
+		})
+	})
 
 	return nil
 }
@@ -135,9 +128,9 @@ func (m *MockDisplay) DrawLine(x, y, width int) error {
 		return err
 	}
 
-	for i := 0; i < width && x+i < m.width; i++ {
-		m.setPixel(x+i, y, true)
-	}
+	rectPixels(x, y, width, 1, m.width, m.height, true, func(px, py int) {
+		m.setPixel(px, py, true)
+	})
 	return nil
 }
 
@@ -165,27 +158,9 @@ func (m *MockDisplay) DrawRect(x, y, width, height int, fill bool) error {
 		return err
 	}
 
-	if fill {
-		for dy := 0; dy < height && y+dy < m.height; dy++ {
-			for dx := 0; dx < width && x+dx < m.width; dx++ {
-				m.setPixel(x+dx, y+dy, true)
-			}
-		}
-	} else {
-		// Draw outline
-		for i := 0; i < width && x+i < m.width; i++ {
-			m.setPixel(x+i, y, true)
-			if y+height-1 < m.height {
-				m.setPixel(x+i, y+height-1, true)
-			}
-		}
-		for i := 0; i < height && y+i < m.height; i++ {
-			m.setPixel(x, y+i, true)
-			if x+width-1 < m.width {
-				m.setPixel(x+width-1, y+i, true)
-			}
-		}
-	}
+	rectPixels(x, y, width, height, m.width, m.height, fill, func(px, py int) {
+		m.setPixel(px, py, true)
+	})
 	return nil
 }
 
@@ -200,15 +175,13 @@ func (m *MockDisplay) DrawImage(x, y int, img image.Image) error {
 	}
 
 	bounds := img.Bounds()
-	for dy := 0; dy < bounds.Dy() && y+dy < m.height; dy++ {
-		for dx := 0; dx < bounds.Dx() && x+dx < m.width; dx++ {
-			r, g, b, a := img.At(bounds.Min.X+dx, bounds.Min.Y+dy).RGBA()
-			// Simple threshold: if pixel is bright enough and not transparent, turn on
-			brightness := (r + g + b) / 3
-			on := brightness > 32768 && a > 32768
-			m.setPixel(x+dx, y+dy, on)
-		}
-	}
+	rectPixels(x, y, bounds.Dx(), bounds.Dy(), m.width, m.height, true, func(px, py int) {
+		r, g, b, a := img.At(bounds.Min.X+px-x, bounds.Min.Y+py-y).RGBA()
+		// Simple threshold: if pixel is bright enough and not transparent, turn on
+		brightness := (r + g + b) / 3
+		on := brightness > 32768 && a > 32768
+		m.setPixel(px, py, on)
+	})
 	return nil
 }
 
@@ -251,6 +224,11 @@ func (m *MockDisplay) GetBuffer() []byte {
 	return buf
 }
 
+// BufferFormat reports that the buffer holds one bit per pixel in page order
+func (m *MockDisplay) BufferFormat() BufferFormat {
+	return BufferFormatMonoPages
+}
+
 // GetPixel returns the state of a pixel (for testing)
 func (m *MockDisplay) GetPixel(x, y int) bool {
 	m.mu.Lock()
@@ -322,3 +300,19 @@ func (m *MockDisplay) SetBrightness(level uint8) error {
 	// Mock just records the call, no actual brightness control
 	return m.checkError()
 }
+
+// Sleep simulates powering the panel down
+func (m *MockDisplay) Sleep() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordCall("Sleep")
+	return m.checkError()
+}
+
+// Wake simulates powering the panel back up
+func (m *MockDisplay) Wake() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordCall("Wake")
+	return m.checkError()
+}
@@ -0,0 +1,44 @@
+package display
+
+// OffscreenDisplay is an in-memory Display with no hardware behind it.
+// Pages can be rendered into it to capture a frame, e.g. for compositing
+// page transitions before anything is sent to the panel. Colours are kept
+// at full depth; the target display converts them when the frame is drawn.
+type OffscreenDisplay struct {
+	*Framebuffer
+}
+
+// NewOffscreenDisplay creates a black offscreen frame of the given size
+func NewOffscreenDisplay(width, height int) *OffscreenDisplay {
+	return &OffscreenDisplay{Framebuffer: NewFramebuffer(width, height, ColorModelRGB565)}
+}
+
+// Init is a no-op
+func (o *OffscreenDisplay) Init() error {
+	return nil
+}
+
+// Show is a no-op; the frame stays in memory
+func (o *OffscreenDisplay) Show() error {
+	return nil
+}
+
+// Close is a no-op
+func (o *OffscreenDisplay) Close() error {
+	return nil
+}
+
+// SetBrightness is a no-op
+func (o *OffscreenDisplay) SetBrightness(_ uint8) error {
+	return nil
+}
+
+// Sleep is a no-op
+func (o *OffscreenDisplay) Sleep() error {
+	return nil
+}
+
+// Wake is a no-op
+func (o *OffscreenDisplay) Wake() error {
+	return nil
+}
@@ -0,0 +1,44 @@
+package display
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestOffscreenDisplay(t *testing.T) {
+	o := NewOffscreenDisplay(32, 16)
+
+	if b := o.GetBounds(); b.Dx() != 32 || b.Dy() != 16 {
+		t.Fatalf("GetBounds() = %v, want 32x16", b)
+	}
+	black := color.NRGBA{A: 255}
+	if got := o.Image().NRGBAAt(5, 5); got != black {
+		t.Errorf("new frame pixel = %v, want opaque black", got)
+	}
+
+	if err := o.DrawPixel(1, 1, true); err != nil {
+		t.Fatalf("DrawPixel() failed: %v", err)
+	}
+	if got := o.Image().NRGBAAt(1, 1); got.R != 255 {
+		t.Errorf("expected white pixel at (1,1), got %v", got)
+	}
+
+	red := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	for i := 0; i < len(red.Pix); i += 4 {
+		red.Pix[i], red.Pix[i+3] = 255, 255
+	}
+	if err := o.DrawImage(10, 10, red); err != nil {
+		t.Fatalf("DrawImage() failed: %v", err)
+	}
+	if got := o.Image().NRGBAAt(11, 11); got != (color.NRGBA{R: 255, A: 255}) {
+		t.Errorf("expected image colour preserved, got %v", got)
+	}
+
+	if err := o.Clear(); err != nil {
+		t.Fatalf("Clear() failed: %v", err)
+	}
+	if got := o.Image().NRGBAAt(1, 1); got != black {
+		t.Errorf("expected cleared pixel, got %v", got)
+	}
+}
@@ -0,0 +1,425 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spireg"
+	"periph.io/x/host/v3"
+)
+
+// Commands shared by the page-addressed OLED controllers
+const (
+	oledDisplayOff    byte = 0xAE
+	oledDisplayOn     byte = 0xAF
+	oledSetContrast   byte = 0x81
+	oledSetPage       byte = 0xB0 // | page
+	oledSetColumnLow  byte = 0x00 // | low nibble of the column
+	oledSetColumnHigh byte = 0x10 // | high nibble of the column
+	oledSegRemapOff   byte = 0xA0 // column 0 drives SEG0
+	oledSegRemapOn    byte = 0xA1 // column 0 drives the last SEG
+	oledCOMScanInc    byte = 0xC0
+	oledCOMScanDec    byte = 0xC8
+)
+
+// I2C control bytes announcing a command or a data stream
+const (
+	oledI2CCommand byte = 0x00
+	oledI2CData    byte = 0x40
+)
+
+// oledController describes a page-addressed monochrome OLED controller: the
+// RAM holds pages of eight pixel rows, one byte per column, and each page
+// is written after setting its page and start column
+type oledController struct {
+	name string
+	// sizes lists the supported panels as width x height
+	sizes [][2]int
+	// colOffset is the RAM column of the panel's first pixel column
+	colOffset func(width int) int
+	// init returns the power-on command sequence, ending with display on
+	init func(width, height int, flipped bool) []byte
+}
+
+// ssd1309Controller drives the SSD1309 found on 2.42" 128x64 modules. It is
+// command compatible with the SSD1306 but has no charge pump and wants
+// higher precharge and VCOMH levels.
+var ssd1309Controller = &oledController{
+	name:      "SSD1309",
+	sizes:     [][2]int{{128, 64}},
+	colOffset: func(int) int { return 0 },
+	init: func(width, height int, flipped bool) []byte {
+		seg, com := oledFlip(flipped, oledSegRemapOn, oledCOMScanDec)
+		return []byte{
+			oledDisplayOff,
+			0xD5, 0xA0, // clock divide and oscillator frequency
+			0xA8, byte(height - 1), // #nosec G115 -- multiplex ratio, height is at most 64
+			0xD3, 0x00, // display offset
+			0x40,       // start line 0
+			0x20, 0x02, // page addressing mode
+			seg, com,
+			0xDA, 0x12, // alternative COM pin configuration
+			oledSetContrast, 0xCF,
+			0xD9, 0xF1, // precharge
+			0xDB, 0x34, // VCOMH deselect level
+			0xA4, // display follows RAM
+			0xA6, // not inverted
+			oledDisplayOn,
+		}
+	},
+}
+
+// ssd1305Controller drives SSD1305 panels. Its RAM is 132 columns wide and
+// the 128 column modules are wired to the last 128, so page writes start at
+// column 4.
+var ssd1305Controller = &oledController{
+	name:      "SSD1305",
+	sizes:     [][2]int{{128, 32}, {128, 64}},
+	colOffset: func(int) int { return 4 },
+	init: func(width, height int, flipped bool) []byte {
+		seg, com := oledFlip(flipped, oledSegRemapOn, oledCOMScanDec)
+		return []byte{
+			oledDisplayOff,
+			0xD5, 0xF0, // clock divide and oscillator frequency
+			0xA8, byte(height - 1), // #nosec G115 -- multiplex ratio, height is at most 64
+			0xD3, 0x00, // display offset
+			0x40,       // start line 0
+			0xAD, 0x8E, // external VCC
+			0xD8, 0x05, // monochrome, low power
+			0x20, 0x02, // page addressing mode
+			seg, com,
+			0xDA, 0x12, // alternative COM pin configuration
+			0x91, 0x3F, 0x3F, 0x3F, 0x3F, // current drive pulse widths
+			oledSetContrast, 0x32,
+			0xD9, 0xD2, // precharge
+			0xDB, 0x34, // VCOMH deselect level
+			0xA4, // display follows RAM
+			0xA6, // not inverted
+			oledDisplayOn,
+		}
+	},
+}
+
+// sh1107Controller drives SH1107 portrait panels (64x128 and 128x128). The
+// controller has a 128x128 RAM whose pages run along the SEG lines; the
+// narrow panels only wire 64 COM lines, which the display offset moves to
+// the middle of the RAM. The segment remap is off in the normal
+// orientation, the opposite of the SSD130x parts.
+var sh1107Controller = &oledController{
+	name:      "SH1107",
+	sizes:     [][2]int{{64, 128}, {128, 128}},
+	colOffset: func(int) int { return 0 },
+	init: func(width, height int, flipped bool) []byte {
+		seg, com := oledFlip(flipped, oledSegRemapOff, oledCOMScanInc)
+		// The 64 driven COM lines are 0x60-0x9F scanning up and 0x20-0x5F
+		// scanning down
+		offset := byte(0x00)
+		if width == 64 {
+			offset = 0x60
+			if flipped {
+				offset = 0x20
+			}
+		}
+		return []byte{
+			oledDisplayOff,
+			0xD5, 0x51, // clock divide and oscillator frequency
+			0x20, // page addressing mode
+			oledSetContrast, 0x4F,
+			0xAD, 0x8A, // DC-DC converter on
+			seg, com,
+			0xDC, 0x00, // start line 0
+			0xD3, offset,
+			0xD9, 0x22, // precharge
+			0xDB, 0x35, // VCOMH deselect level
+			0xA8, byte(width - 1), // #nosec G115 -- multiplex ratio, width is at most 128
+			0xA4, // display follows RAM
+			0xA6, // not inverted
+			oledDisplayOn,
+		}
+	},
+}
+
+// oledFlip returns the segment remap and COM scan commands, swapped to the
+// opposite direction for a 180° rotation
+func oledFlip(flipped bool, seg, com byte) (byte, byte) {
+	if flipped {
+		seg ^= oledSegRemapOn ^ oledSegRemapOff
+		com ^= oledCOMScanDec ^ oledCOMScanInc
+	}
+	return seg, com
+}
+
+// oledControllerFor returns the controller for a display type, or nil if
+// the type is not a page-addressed OLED handled by OLEDDisplay
+func oledControllerFor(displayType string) *oledController {
+	switch {
+	case strings.HasPrefix(displayType, "ssd1309"):
+		return ssd1309Controller
+	case strings.HasPrefix(displayType, "ssd1305"):
+		return ssd1305Controller
+	case strings.HasPrefix(displayType, "sh1107"):
+		return sh1107Controller
+	}
+	return nil
+}
+
+// oledConn carries commands and display data to the controller
+type oledConn interface {
+	command(cmds ...byte) error
+	data(b []byte) error
+}
+
+// oledI2C prefixes each write with the I2C control byte
+type oledI2C struct {
+	dev *i2c.Dev
+}
+
+func (c oledI2C) command(cmds ...byte) error {
+	return c.dev.Tx(append([]byte{oledI2CCommand}, cmds...), nil)
+}
+
+func (c oledI2C) data(b []byte) error {
+	return c.dev.Tx(append([]byte{oledI2CData}, b...), nil)
+}
+
+// oledSPITx is the part of spi.Conn the SPI transport uses
+type oledSPITx interface {
+	Tx(w, r []byte) error
+}
+
+// oledSPI selects commands or data with the DC pin
+type oledSPI struct {
+	conn oledSPITx
+	dc   gpio.PinOut
+}
+
+func (c oledSPI) command(cmds ...byte) error {
+	if err := c.dc.Out(gpio.Low); err != nil {
+		return err
+	}
+	return c.conn.Tx(cmds, nil)
+}
+
+func (c oledSPI) data(b []byte) error {
+	if err := c.dc.Out(gpio.High); err != nil {
+		return err
+	}
+	return c.conn.Tx(b, nil)
+}
+
+// OLEDDisplay implements Display for the page-addressed monochrome OLED
+// controllers periph.io has no driver for: SSD1309 (I2C or SPI), SSD1305
+// and SH1107 (I2C). Show only rewrites the pages that changed.
+type OLEDDisplay struct {
+	*Framebuffer
+	ctrl    *oledController
+	conn    oledConn
+	closer  interface{ Close() error } // I2C bus or SPI port, nil when owned by the caller
+	rst     gpio.PinOut                // nil if not configured
+	flipped bool
+	sleep   func(time.Duration) // time.Sleep, replaced in tests
+
+	mu    sync.Mutex
+	shown []byte // pages on the panel, nil until written
+}
+
+// NewOLEDDisplay creates an I2C driver for displayType (ssd1309, ssd1305
+// or sh1107 variants). speed is the I2C clock, 0 to keep the bus's current
+// speed.
+func NewOLEDDisplay(i2cBus, i2cAddr, displayType string, speed physic.Frequency, width, height, rotation int) (*OLEDDisplay, error) {
+	ctrl := oledControllerFor(displayType)
+	if ctrl == nil {
+		return nil, fmt.Errorf("display type %s is not a page-addressed OLED", displayType)
+	}
+
+	bus, err := openI2C(displayType, i2cBus, speed)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := parseI2CAddr(i2cAddr)
+	if err != nil {
+		bus.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, err
+	}
+
+	d, err := newOLED(ctrl, oledI2C{dev: &i2c.Dev{Bus: bus, Addr: addr}}, nil, width, height, rotation)
+	if err != nil {
+		bus.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, err
+	}
+	d.closer = bus
+	return d, nil
+}
+
+// NewOLEDDisplaySPI creates a 4-wire SPI driver for displayType
+func NewOLEDDisplaySPI(spiBus, dcPin, rstPin, displayType string, width, height, rotation int) (*OLEDDisplay, error) {
+	ctrl := oledControllerFor(displayType)
+	if ctrl == nil {
+		return nil, fmt.Errorf("display type %s is not a page-addressed OLED", displayType)
+	}
+
+	if _, err := host.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize periph: %w", err)
+	}
+
+	dc := gpioreg.ByName(dcPin)
+	if dc == nil {
+		return nil, fmt.Errorf("DC pin %q not found", dcPin)
+	}
+	var rst gpio.PinOut
+	if rstPin != "" {
+		if rst = gpioreg.ByName(rstPin); rst == nil {
+			return nil, fmt.Errorf("RST pin %q not found", rstPin)
+		}
+	}
+
+	port, err := spireg.Open(spiBus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SPI bus %s: %w", spiBus, err)
+	}
+	conn, err := port.Connect(8*physic.MegaHertz, spi.Mode0, 8)
+	if err != nil {
+		port.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, fmt.Errorf("failed to connect on SPI bus %s: %w", spiBus, err)
+	}
+
+	d, err := newOLED(ctrl, oledSPI{conn: conn, dc: dc}, rst, width, height, rotation)
+	if err != nil {
+		port.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, err
+	}
+	d.closer = port
+	return d, nil
+}
+
+// newOLED creates the driver on an already opened connection
+func newOLED(ctrl *oledController, conn oledConn, rst gpio.PinOut, width, height, rotation int) (*OLEDDisplay, error) {
+	supported := false
+	for _, size := range ctrl.sizes {
+		supported = supported || (size[0] == width && size[1] == height)
+	}
+	if !supported {
+		return nil, fmt.Errorf("unsupported %s panel size %dx%d", ctrl.name, width, height)
+	}
+	// Like the SSD1306, these controllers can only mirror both axes
+	if rotation != 0 && rotation != 2 {
+		return nil, fmt.Errorf("%s only supports rotation 0 (0°) and 2 (180°), got %d", ctrl.name, rotation)
+	}
+	return &OLEDDisplay{
+		Framebuffer: NewFramebuffer(width, height, ColorModelMono),
+		ctrl:        ctrl,
+		conn:        conn,
+		rst:         rst,
+		flipped:     rotation == 2,
+		sleep:       time.Sleep,
+	}, nil
+}
+
+// Init resets the controller, if the reset line is wired, and sends the
+// power-on sequence. The next Show rewrites every page.
+func (d *OLEDDisplay) Init() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.rst != nil {
+		for _, level := range []gpio.Level{gpio.High, gpio.Low, gpio.High} {
+			if err := d.rst.Out(level); err != nil {
+				return fmt.Errorf("RST failed: %w", err)
+			}
+			d.sleep(10 * time.Millisecond)
+		}
+	}
+	b := d.GetBounds()
+	if err := d.conn.command(d.ctrl.init(b.Dx(), b.Dy(), d.flipped)...); err != nil {
+		return fmt.Errorf("%s init failed: %w", d.ctrl.name, err)
+	}
+	d.shown = nil
+	return d.Framebuffer.Clear()
+}
+
+// Show writes the pages that differ from what the panel shows
+func (d *OLEDDisplay) Show() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	width := d.GetBounds().Dx()
+	col := d.ctrl.colOffset(width)
+	pages := d.MonoPages()
+	full := d.shown == nil
+	if full {
+		d.shown = make([]byte, len(pages))
+	}
+	for p := 0; p*width < len(pages); p++ {
+		page := pages[p*width : (p+1)*width]
+		if !full && string(d.shown[p*width:(p+1)*width]) == string(page) {
+			continue
+		}
+		err := d.conn.command(
+			oledSetPage|byte(p),             // #nosec G115 -- at most 16 pages
+			oledSetColumnLow|byte(col&0x0F), // #nosec G115 -- masked to a nibble
+			oledSetColumnHigh|byte(col>>4),  // #nosec G115 -- columns are below 132
+		)
+		if err == nil {
+			err = d.conn.data(page)
+		}
+		if err != nil {
+			// The panel contents are unknown now, so rewrite every page next time
+			d.shown = nil
+			return fmt.Errorf("failed to write %s page %d: %w", d.ctrl.name, p, err)
+		}
+		copy(d.shown[p*width:], page)
+	}
+	return nil
+}
+
+// Sleep switches the panel off; Wake switches it on again, as does Init
+// by re-running the init sequence
+func (d *OLEDDisplay) Sleep() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.conn.command(oledDisplayOff); err != nil {
+		return fmt.Errorf("%s sleep failed: %w", d.ctrl.name, err)
+	}
+	return nil
+}
+
+// Wake switches the panel back on, showing the RAM as it was last written
+func (d *OLEDDisplay) Wake() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.conn.command(oledDisplayOn); err != nil {
+		return fmt.Errorf("%s wake failed: %w", d.ctrl.name, err)
+	}
+	return nil
+}
+
+// Close switches the panel off and releases the bus
+func (d *OLEDDisplay) Close() error {
+	d.mu.Lock()
+	err := d.conn.command(oledDisplayOff)
+	d.mu.Unlock()
+	if d.closer != nil {
+		if closeErr := d.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// SetBrightness sets the contrast (0-255)
+func (d *OLEDDisplay) SetBrightness(level uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.conn.command(oledSetContrast, level); err != nil {
+		return fmt.Errorf("failed to set contrast: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/i2c"
+)
+
+func newTestOLED(t *testing.T, ctrl *oledController, width, height, rotation int) (*OLEDDisplay, *fakeBus) {
+	t.Helper()
+	bus := &fakeBus{devices: map[uint16]byte{0x3C: 0}}
+	d, err := newOLED(ctrl, oledI2C{dev: &i2c.Dev{Bus: bus, Addr: 0x3C}}, nil, width, height, rotation)
+	if err != nil {
+		t.Fatalf("newOLED() failed: %v", err)
+	}
+	d.sleep = func(time.Duration) {}
+	if err := d.Init(); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	return d, bus
+}
+
+func TestOLEDInit(t *testing.T) {
+	tests := []struct {
+		name          string
+		ctrl          *oledController
+		width, height int
+		rotation      int
+		want          []byte // commands that must appear in the init sequence
+	}{
+		{"ssd1309", ssd1309Controller, 128, 64, 0, []byte{0x20, 0x02, oledSegRemapOn, oledCOMScanDec}},
+		{"ssd1309 rotated", ssd1309Controller, 128, 64, 2, []byte{oledSegRemapOff, oledCOMScanInc}},
+		{"ssd1305 128x32", ssd1305Controller, 128, 32, 0, []byte{0xA8, 0x1F}},
+		{"sh1107 64x128", sh1107Controller, 64, 128, 0, []byte{oledSegRemapOff, oledCOMScanInc, 0xDC, 0x00, 0xD3, 0x60, 0xD9, 0x22, 0xDB, 0x35, 0xA8, 0x3F}},
+		{"sh1107 64x128 rotated", sh1107Controller, 64, 128, 2, []byte{oledSegRemapOn, oledCOMScanDec, 0xDC, 0x00, 0xD3, 0x20}},
+		{"sh1107 128x128", sh1107Controller, 128, 128, 0, []byte{0xD3, 0x00, 0xD9, 0x22, 0xDB, 0x35, 0xA8, 0x7F}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, bus := newTestOLED(t, tt.ctrl, tt.width, tt.height, tt.rotation)
+			if len(bus.writes) != 1 {
+				t.Fatalf("expected the init sequence in one write, got %d", len(bus.writes))
+			}
+			seq := bus.writes[0].data
+			if seq[0] != oledI2CCommand || seq[1] != oledDisplayOff || seq[len(seq)-1] != oledDisplayOn {
+				t.Errorf("init sequence should switch the display off, then on: % X", seq)
+			}
+			if !bytes.Contains(seq, tt.want) {
+				t.Errorf("init sequence % X does not contain % X", seq, tt.want)
+			}
+		})
+	}
+}
+
+func TestOLEDShow(t *testing.T) {
+	d, bus := newTestOLED(t, ssd1305Controller, 128, 32, 0)
+	bus.writes = nil
+
+	// The first Show writes every page, each after a page and column address
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 8 {
+		t.Fatalf("expected a command and a data write for each of 4 pages, got %d", len(bus.writes))
+	}
+	for p := 0; p < 4; p++ {
+		// SSD1305 modules start at RAM column 4
+		if want := []byte{oledI2CCommand, 0xB0 | byte(p), 0x04, 0x10}; !bytes.Equal(bus.writes[2*p].data, want) {
+			t.Errorf("page %d: address % X, want % X", p, bus.writes[2*p].data, want)
+		}
+		if data := bus.writes[2*p+1].data; data[0] != oledI2CData || len(data) != 129 {
+			t.Errorf("page %d: expected 128 data bytes, got % X", p, data)
+		}
+	}
+
+	// Only the changed page is rewritten
+	bus.writes = nil
+	if err := d.DrawPixel(3, 17, true); err != nil {
+		t.Fatalf("DrawPixel() failed: %v", err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 2 || bus.writes[0].data[1] != 0xB2 {
+		t.Fatalf("expected page 2 alone to be rewritten, got %+v", bus.writes)
+	}
+	if got := bus.writes[1].data[1+3]; got != 1<<1 {
+		t.Errorf("expected column 3 to be 0x02, got 0x%02X", got)
+	}
+
+	// Nothing changed, nothing written
+	bus.writes = nil
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 0 {
+		t.Errorf("expected no writes, got %d", len(bus.writes))
+	}
+}
+
+func TestOLEDShowErrorRewritesAll(t *testing.T) {
+	d, bus := newTestOLED(t, sh1107Controller, 64, 128, 0)
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+
+	delete(bus.devices, 0x3C)
+	if err := d.DrawPixel(0, 0, true); err != nil {
+		t.Fatalf("DrawPixel() failed: %v", err)
+	}
+	if err := d.Show(); err == nil {
+		t.Fatal("expected error when the bus write fails")
+	}
+
+	bus.devices[0x3C] = 0
+	bus.writes = nil
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 2*16 {
+		t.Errorf("expected all 16 pages rewritten after a failure, got %d writes", len(bus.writes))
+	}
+}
+
+func TestOLEDSPI(t *testing.T) {
+	dc := &fakePin{}
+	conn := &fakeSPIConn{dc: dc}
+	rst := &fakePin{}
+	d, err := newOLED(ssd1309Controller, oledSPI{conn: conn, dc: dc}, rst, 128, 64, 0)
+	if err != nil {
+		t.Fatalf("newOLED() failed: %v", err)
+	}
+	d.sleep = func(time.Duration) {}
+	if err := d.Init(); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	if rst.level != gpio.High {
+		t.Error("reset line should be released after Init")
+	}
+	if len(conn.writes) != 1 || conn.writes[0].dc != gpio.Low || conn.writes[0].data[0] != oledDisplayOff {
+		t.Fatalf("expected the init sequence sent as commands, got %+v", conn.writes)
+	}
+
+	conn.writes = nil
+	if err := d.SetBrightness(0x40); err != nil {
+		t.Fatalf("SetBrightness() failed: %v", err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if want := []byte{oledSetContrast, 0x40}; !bytes.Equal(conn.writes[0].data, want) {
+		t.Errorf("expected contrast command % X, got % X", want, conn.writes[0].data)
+	}
+	// Page data goes out with DC high and no control byte
+	if w := conn.writes[2]; w.dc != gpio.High || len(w.data) != 128 {
+		t.Errorf("expected 128 data bytes with DC high, got %+v", w)
+	}
+}
+
+func TestOLEDInvalid(t *testing.T) {
+	bus := &fakeBus{devices: map[uint16]byte{0x3C: 0}}
+	conn := oledI2C{dev: &i2c.Dev{Bus: bus, Addr: 0x3C}}
+	if _, err := newOLED(sh1107Controller, conn, nil, 128, 64, 0); err == nil {
+		t.Error("expected error for a landscape SH1107 size")
+	}
+	if _, err := newOLED(ssd1309Controller, conn, nil, 128, 64, 1); err == nil {
+		t.Error("expected error for 90° rotation")
+	}
+	if oledControllerFor("ssd1306") != nil {
+		t.Error("ssd1306 is handled by periph.io's driver")
+	}
+	if oledControllerFor("ssd1309_spi_128x64") != ssd1309Controller {
+		t.Error("ssd1309_spi should use the SSD1309 controller")
+	}
+}
@@ -0,0 +1,207 @@
+package display
+
+import (
+	"image"
+	"sync"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// QueuedDisplay double-buffers a display: pages are drawn into an in-memory
+// back buffer and Show only queues a copy of it, which a background goroutine
+// sends to the wrapped display. Slow transports (a UCTRONICS frame is over a
+// hundred 700µs I2C chunks) then no longer hold up stats collection and
+// rotation. The queue holds one frame; a frame that is still waiting when the
+// next one is shown is replaced, so the panel always catches up to the latest
+// picture.
+//
+// Errors from a flush are returned by the following Show. Character displays
+// are not supported, as their text bypasses the frame.
+type QueuedDisplay struct {
+	*Framebuffer
+	inner Display
+	caps  Capabilities
+	rect  image.Rectangle // bounds of the queued frames
+	log   *logger.Logger
+
+	mu      sync.Mutex
+	next    *image.NRGBA // frame waiting for the flusher
+	queued  bool         // next holds a frame not yet flushed
+	err     error        // result of the last flush, returned by the next Show
+	dropped int          // frames replaced before they were flushed
+	closed  bool
+
+	innerMu sync.Mutex // serializes the flusher and callers on the wrapped display; taken before mu
+	wake    chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+	onFlush func(err error) // test hook
+}
+
+// NewQueuedDisplay wraps an initialized display and starts its flusher
+func NewQueuedDisplay(inner Display, log *logger.Logger) *QueuedDisplay {
+	b := inner.GetBounds()
+	rect := image.Rect(0, 0, b.Dx(), b.Dy())
+	caps := AsColorDisplay(inner).Capabilities()
+	model := ColorModelRGB565
+	if !caps.Color() {
+		model = ColorModelMono
+	}
+	q := &QueuedDisplay{
+		Framebuffer: NewFramebuffer(b.Dx(), b.Dy(), model),
+		inner:       inner,
+		caps:        caps,
+		rect:        rect,
+		log:         log,
+		next:        image.NewNRGBA(rect),
+		wake:        make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+	q.wg.Add(1)
+	go q.flushLoop()
+	return q
+}
+
+// Init initializes the wrapped display
+func (q *QueuedDisplay) Init() error {
+	q.innerMu.Lock()
+	defer q.innerMu.Unlock()
+	return q.inner.Init()
+}
+
+// Show queues a copy of the back buffer for the flusher and returns the
+// result of the previous flush without waiting for this one
+func (q *QueuedDisplay) Show() error {
+	q.mu.Lock()
+	copy(q.next.Pix, q.Framebuffer.Image().Pix)
+	if q.queued {
+		q.dropped++
+	}
+	q.queued = true
+	err := q.err
+	q.err = nil
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+		// The flusher is already due to run and will pick up this frame
+	}
+	return err
+}
+
+// Dropped returns how many frames were replaced by a newer one before the
+// flusher got to them
+func (q *QueuedDisplay) Dropped() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// flushLoop sends queued frames to the wrapped display until Close, then
+// flushes the last frame so the shutdown page still reaches the panel
+func (q *QueuedDisplay) flushLoop() {
+	defer q.wg.Done()
+	front := image.NewNRGBA(q.rect)
+	for {
+		select {
+		case <-q.wake:
+			front = q.flush(front)
+		case <-q.done:
+			q.flush(front)
+			return
+		}
+	}
+}
+
+// flush swaps the queued frame with front and sends it, returning the
+// buffer to reuse for the next flush
+func (q *QueuedDisplay) flush(front *image.NRGBA) *image.NRGBA {
+	q.innerMu.Lock()
+	defer q.innerMu.Unlock()
+	return q.flushLocked(front)
+}
+
+// flushLocked is flush with innerMu held. Taking the frame under innerMu
+// keeps frames reaching the wrapped display in the order they were queued,
+// whichever goroutine sends them.
+func (q *QueuedDisplay) flushLocked(front *image.NRGBA) *image.NRGBA {
+	q.mu.Lock()
+	if !q.queued {
+		q.mu.Unlock()
+		return front
+	}
+	front, q.next = q.next, front
+	q.queued = false
+	q.mu.Unlock()
+
+	err := q.inner.DrawImage(0, 0, front)
+	if err == nil {
+		err = q.inner.Show()
+	}
+
+	if err != nil {
+		q.log.With().Err(err).Logger().Debug("Queued frame flush failed")
+	}
+	q.mu.Lock()
+	q.err = err
+	hook := q.onFlush
+	q.mu.Unlock()
+	if hook != nil {
+		hook(err)
+	}
+	return front
+}
+
+// Capabilities reports the wrapped display's capabilities
+func (q *QueuedDisplay) Capabilities() Capabilities {
+	return q.caps
+}
+
+// SetBrightness sets the wrapped display's brightness, between flushes
+func (q *QueuedDisplay) SetBrightness(level uint8) error {
+	q.innerMu.Lock()
+	defer q.innerMu.Unlock()
+	return q.inner.SetBrightness(level)
+}
+
+// Sleep sends any queued frame, so the blank frame shown before sleeping
+// reaches the panel, then puts the wrapped display to sleep. The flusher is
+// held off throughout, so no older frame can land after the blank one.
+func (q *QueuedDisplay) Sleep() error {
+	q.innerMu.Lock()
+	defer q.innerMu.Unlock()
+
+	q.flushLocked(image.NewNRGBA(q.rect))
+	q.mu.Lock()
+	err := q.err
+	q.err = nil
+	q.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return q.inner.Sleep()
+}
+
+// Wake wakes the wrapped display, between flushes
+func (q *QueuedDisplay) Wake() error {
+	q.innerMu.Lock()
+	defer q.innerMu.Unlock()
+	return q.inner.Wake()
+}
+
+// Close flushes any queued frame, stops the flusher and closes the wrapped
+// display
+func (q *QueuedDisplay) Close() error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.done)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+
+	q.innerMu.Lock()
+	defer q.innerMu.Unlock()
+	return q.inner.Close()
+}
@@ -0,0 +1,236 @@
+package display
+
+import (
+	"errors"
+	"image/color"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// gatedDisplay is an offscreen display whose Show blocks until released,
+// standing in for a slow bus
+type gatedDisplay struct {
+	*OffscreenDisplay
+	release chan struct{}
+	err     error
+
+	mu     sync.Mutex
+	shows  int
+	closed bool
+}
+
+func newGatedDisplay() *gatedDisplay {
+	return &gatedDisplay{OffscreenDisplay: NewOffscreenDisplay(16, 8), release: make(chan struct{})}
+}
+
+func (g *gatedDisplay) Show() error {
+	<-g.release
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.shows++
+	return g.err
+}
+
+func (g *gatedDisplay) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.closed = true
+	return nil
+}
+
+func newTestQueued(inner Display) (*QueuedDisplay, chan error) {
+	q := NewQueuedDisplay(inner, logger.NewDefault())
+	flushed := make(chan error, 10)
+	q.mu.Lock()
+	q.onFlush = func(err error) { flushed <- err }
+	q.mu.Unlock()
+	return q, flushed
+}
+
+func waitFlush(t *testing.T, flushed chan error) error {
+	t.Helper()
+	select {
+	case err := <-flushed:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatal("frame was not flushed")
+		return nil
+	}
+}
+
+func TestQueuedDisplayShowDoesNotBlock(t *testing.T) {
+	inner := newGatedDisplay()
+	q, flushed := newTestQueued(inner)
+
+	red := color.RGBA{R: 255, A: 255}
+	if err := q.FillRectColor(0, 0, 4, 4, red); err != nil {
+		t.Fatal(err)
+	}
+	shown := make(chan error, 1)
+	go func() { shown <- q.Show() }()
+	select {
+	case err := <-shown:
+		if err != nil {
+			t.Fatalf("Show() failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Show blocked on the wrapped display")
+	}
+
+	close(inner.release)
+	if err := waitFlush(t, flushed); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if got := inner.Image().NRGBAAt(1, 1); got.R != 255 || got.G != 0 {
+		t.Errorf("expected the queued frame on the wrapped display, got %v", got)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueuedDisplayKeepsLatestFrame(t *testing.T) {
+	inner := newGatedDisplay()
+	q, flushed := newTestQueued(inner)
+
+	// The first frame occupies the flusher; the next two queue behind it
+	// and only the last survives
+	for _, c := range []color.RGBA{{R: 255, A: 255}, {G: 255, A: 255}, {B: 255, A: 255}} {
+		if err := q.FillRectColor(0, 0, 16, 8, c); err != nil {
+			t.Fatal(err)
+		}
+		if err := q.Show(); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(inner.release)
+	waitFlush(t, flushed)
+	waitFlush(t, flushed)
+
+	if got := inner.Image().NRGBAAt(0, 0); got.B != 255 {
+		t.Errorf("expected the latest (blue) frame, got %v", got)
+	}
+	if q.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", q.Dropped())
+	}
+	inner.mu.Lock()
+	shows := inner.shows
+	inner.mu.Unlock()
+	if shows != 2 {
+		t.Errorf("expected 2 flushes, got %d", shows)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueuedDisplayReportsFlushErrors(t *testing.T) {
+	inner := newGatedDisplay()
+	inner.err = errors.New("i2c: remote I/O error")
+	close(inner.release)
+	q, flushed := newTestQueued(inner)
+
+	if err := q.Show(); err != nil {
+		t.Fatalf("first Show() failed: %v", err)
+	}
+	waitFlush(t, flushed)
+	if err := q.Show(); err == nil {
+		t.Error("expected the failed flush to be reported by the next Show")
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueuedDisplayCloseFlushes(t *testing.T) {
+	inner := newGatedDisplay()
+	close(inner.release)
+	q := NewQueuedDisplay(inner, logger.NewDefault())
+
+	if err := q.FillRectColor(0, 0, 16, 8, color.RGBA{G: 255, A: 255}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Show(); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := inner.Image().NRGBAAt(15, 7); got.G != 255 {
+		t.Errorf("expected Close to flush the queued frame, got %v", got)
+	}
+	if !inner.closed {
+		t.Error("expected the wrapped display to be closed")
+	}
+	if caps := q.Capabilities(); caps.ColorDepth != 16 {
+		t.Errorf("expected the wrapped display's capabilities, got %+v", caps)
+	}
+}
+
+// sleepingGatedDisplay is a gated display that records Sleep after the
+// frames it showed
+type sleepingGatedDisplay struct {
+	*gatedDisplay
+	sleptAfter int // shows before Sleep, -1 until it is called
+}
+
+func (s *sleepingGatedDisplay) Sleep() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sleptAfter = s.shows
+	return nil
+}
+
+func TestQueuedDisplaySleepFlushesFirst(t *testing.T) {
+	inner := &sleepingGatedDisplay{gatedDisplay: newGatedDisplay(), sleptAfter: -1}
+	close(inner.release)
+	q := NewQueuedDisplay(inner, logger.NewDefault())
+	defer q.Close()
+
+	if err := Sleep(q); err != nil {
+		t.Fatalf("Sleep() failed: %v", err)
+	}
+	if inner.sleptAfter != 1 {
+		t.Errorf("panel slept after %d frames, want after the blank frame", inner.sleptAfter)
+	}
+}
+
+// blankAtSleepDisplay records whether the panel was blank when put to sleep
+type blankAtSleepDisplay struct {
+	*OffscreenDisplay
+	blank bool
+}
+
+func (b *blankAtSleepDisplay) Sleep() error {
+	b.blank = b.Image().NRGBAAt(0, 0).G == 0
+	return nil
+}
+
+func TestQueuedDisplaySleepAfterPendingFrame(t *testing.T) {
+	inner := &blankAtSleepDisplay{OffscreenDisplay: NewOffscreenDisplay(16, 8)}
+	q := NewQueuedDisplay(inner, logger.NewDefault())
+	defer q.Close()
+
+	// A page still being flushed must never land after the blank frame
+	for i := range 50 {
+		if err := q.FillRectColor(0, 0, 16, 8, color.RGBA{G: 255, A: 255}); err != nil {
+			t.Fatal(err)
+		}
+		if err := q.Show(); err != nil {
+			t.Fatal(err)
+		}
+		if err := Sleep(q); err != nil {
+			t.Fatalf("Sleep() failed: %v", err)
+		}
+		if !inner.blank {
+			t.Fatalf("iteration %d: panel slept showing the previous page", i)
+		}
+		if err := q.Wake(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
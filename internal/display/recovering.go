@@ -0,0 +1,336 @@
+package display
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/health"
+	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/retry"
+)
+
+// Factory creates a new, uninitialized display instance
+type Factory func() (Display, error)
+
+// RecoveringDisplay wraps a hardware display and transparently re-creates it
+// after repeated Show() failures, so a bus glitch or hot-unplug does not leave
+// the daemon blind until restart. Re-initialization runs in the background
+// with exponential backoff; Show() fails fast while it is in progress.
+type RecoveringDisplay struct {
+	mu           sync.Mutex
+	inner        Display
+	factory      Factory
+	threshold    int // consecutive Show failures before re-init
+	failures     int
+	reiniting    bool
+	showing      int        // Show calls in flight on inner
+	idle         *sync.Cond // signalled when showing drops to zero, on mu
+	brightness   *uint8     // last requested brightness, re-applied after re-init
+	retryConfig  retry.Config
+	checker      *health.Checker
+	log          *logger.Logger
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	onReinitDone func(err error) // test hook
+}
+
+// NewRecoveringDisplay wraps an initialized display. factory is used to
+// construct a replacement after threshold consecutive Show() failures.
+func NewRecoveringDisplay(inner Display, factory Factory, threshold int, log *logger.Logger) *RecoveringDisplay {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &RecoveringDisplay{
+		inner:     inner,
+		factory:   factory,
+		threshold: threshold,
+		retryConfig: retry.Config{
+			MaxAttempts:  6,
+			InitialDelay: time.Second,
+			MaxDelay:     30 * time.Second,
+			Multiplier:   2.0,
+		},
+		log:    log,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	d.idle = sync.NewCond(&d.mu)
+	return d
+}
+
+// SetHealthChecker attaches a health checker. A failed recovery marks the
+// display component unhealthy.
+func (d *RecoveringDisplay) SetHealthChecker(h *health.Checker) {
+	d.mu.Lock()
+	d.checker = h
+	d.mu.Unlock()
+}
+
+func (d *RecoveringDisplay) current() Display {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.inner
+}
+
+// Init initializes the wrapped display
+func (d *RecoveringDisplay) Init() error { return d.current().Init() }
+
+// Clear clears the display buffer
+func (d *RecoveringDisplay) Clear() error { return d.current().Clear() }
+
+// DrawText draws text at the given position
+func (d *RecoveringDisplay) DrawText(x, y int, text string, size int) error {
+	return d.current().DrawText(x, y, text, size)
+}
+
+// DrawLine draws a horizontal line
+func (d *RecoveringDisplay) DrawLine(x, y, width int) error {
+	return d.current().DrawLine(x, y, width)
+}
+
+// DrawPixel sets a single pixel
+func (d *RecoveringDisplay) DrawPixel(x, y int, on bool) error {
+	return d.current().DrawPixel(x, y, on)
+}
+
+// DrawRect draws a rectangle
+func (d *RecoveringDisplay) DrawRect(x, y, width, height int, fill bool) error {
+	return d.current().DrawRect(x, y, width, height, fill)
+}
+
+// DrawImage draws an image at the given position
+func (d *RecoveringDisplay) DrawImage(x, y int, img image.Image) error {
+	return d.current().DrawImage(x, y, img)
+}
+
+// DrawPixelColor sets a coloured pixel
+func (d *RecoveringDisplay) DrawPixelColor(x, y int, c color.Color) error {
+	return AsColorDisplay(d.current()).DrawPixelColor(x, y, c)
+}
+
+// FillRectColor fills a rectangle with a colour
+func (d *RecoveringDisplay) FillRectColor(x, y, width, height int, c color.Color) error {
+	return AsColorDisplay(d.current()).FillRectColor(x, y, width, height, c)
+}
+
+// Capabilities reports the wrapped display's capabilities
+func (d *RecoveringDisplay) Capabilities() Capabilities {
+	return AsColorDisplay(d.current()).Capabilities()
+}
+
+// WriteLines sets the text of a character display
+func (d *RecoveringDisplay) WriteLines(lines []string) error {
+	return WriteLines(d.current(), lines)
+}
+
+// GetBounds returns the display dimensions
+func (d *RecoveringDisplay) GetBounds() image.Rectangle { return d.current().GetBounds() }
+
+// GetBuffer returns the display buffer
+func (d *RecoveringDisplay) GetBuffer() []byte { return d.current().GetBuffer() }
+
+// BufferFormat reports the layout of the display buffer
+func (d *RecoveringDisplay) BufferFormat() BufferFormat { return d.current().BufferFormat() }
+
+// SetBrightness sets the brightness and remembers it for re-initialization
+func (d *RecoveringDisplay) SetBrightness(level uint8) error {
+	d.mu.Lock()
+	d.brightness = &level
+	inner := d.inner
+	d.mu.Unlock()
+	return inner.SetBrightness(level)
+}
+
+// Sleep puts the current display to sleep
+func (d *RecoveringDisplay) Sleep() error { return d.current().Sleep() }
+
+// Wake wakes the current display
+func (d *RecoveringDisplay) Wake() error { return d.current().Wake() }
+
+// Show pushes the buffer to the hardware, triggering a background
+// re-initialization once failures reach the threshold.
+func (d *RecoveringDisplay) Show() error {
+	d.mu.Lock()
+	if d.reiniting {
+		d.mu.Unlock()
+		return fmt.Errorf("display re-initialization in progress")
+	}
+	inner := d.inner
+	d.showing++
+	d.mu.Unlock()
+
+	err := inner.Show()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.showing--; d.showing == 0 {
+		d.idle.Broadcast()
+	}
+	if err == nil {
+		d.failures = 0
+		return nil
+	}
+
+	d.failures++
+	if d.failures >= d.threshold && !d.reiniting {
+		d.log.With().Int("failures", d.failures).Err(err).Logger().Warn("Persistent display errors, re-initializing display")
+		d.startReinit()
+	}
+	return err
+}
+
+// Reinit re-creates the display in the background, as after persistent
+// Show() failures, for failures Show() cannot see such as a flush that takes
+// far too long. It does nothing while a re-initialization is in progress.
+func (d *RecoveringDisplay) Reinit() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.reiniting {
+		return
+	}
+	d.log.Warn("Re-initializing display")
+	d.startReinit()
+}
+
+// startReinit starts the background re-initialization. Callers hold mu.
+func (d *RecoveringDisplay) startReinit() {
+	d.reiniting = true
+	d.wg.Add(1)
+	go d.reinit()
+}
+
+// reinit closes the current display and builds a fresh one with backoff.
+// The display is not closed under a transfer: a Show still in flight is
+// waited for first. One that never returns, blocked on a wedged bus, holds
+// up recovery until Close, leaving it to the systemd watchdog to restart
+// the service once the display is reported unhealthy.
+func (d *RecoveringDisplay) reinit() {
+	defer d.wg.Done()
+
+	d.mu.Lock()
+	for d.showing > 0 && d.ctx.Err() == nil {
+		d.idle.Wait()
+	}
+	if d.ctx.Err() != nil {
+		d.reiniting = false
+		d.mu.Unlock()
+		return
+	}
+	// The old display is closed first, as the replacement opens the same
+	// device; until there is one, a stub stands in so the closed device is
+	// never used or closed again
+	old := d.inner
+	d.inner = newClosedDisplay(old)
+	d.mu.Unlock()
+	if err := old.Close(); err != nil {
+		d.log.With().Err(err).Logger().Debug("Error closing failed display")
+	}
+
+	attempt := 0
+	fresh, err := retry.DoWithResult(d.ctx, d.retryConfig, func() (Display, error) {
+		attempt++
+		disp, err := d.factory()
+		if err != nil {
+			d.log.With().Int("attempt", attempt).Err(err).Logger().Warn("Display re-creation failed")
+			return nil, err
+		}
+		if err := disp.Init(); err != nil {
+			_ = disp.Close()
+			d.log.With().Int("attempt", attempt).Err(err).Logger().Warn("Display re-initialization failed")
+			return nil, err
+		}
+		return disp, nil
+	})
+
+	d.mu.Lock()
+	d.reiniting = false
+	d.failures = 0
+	checker := d.checker
+	if err == nil {
+		d.inner = fresh
+		if d.brightness != nil {
+			if bErr := fresh.SetBrightness(*d.brightness); bErr != nil {
+				d.log.With().Err(bErr).Logger().Warn("Failed to restore brightness after re-init")
+			}
+		}
+	}
+	hook := d.onReinitDone
+	d.mu.Unlock()
+
+	if err != nil {
+		d.log.ErrorWithErr(err, "Display recovery failed, will retry on further errors")
+		if checker != nil {
+			checker.MarkUnhealthy(health.ComponentDisplay, err)
+		}
+	} else {
+		d.log.With().Int("attempts", attempt).Logger().Info("Display re-initialized")
+	}
+	if hook != nil {
+		hook(err)
+	}
+}
+
+// Close stops any in-flight recovery and closes the wrapped display
+func (d *RecoveringDisplay) Close() error {
+	d.cancel()
+	d.mu.Lock()
+	d.idle.Broadcast() // wake a recovery waiting for Show
+	d.mu.Unlock()
+	d.wg.Wait()
+	return d.current().Close()
+}
+
+// errDisplayClosed is returned by hardware operations on a display closed
+// for recovery
+var errDisplayClosed = errors.New("display closed for recovery")
+
+// closedDisplay stands in for a display closed for recovery until a
+// replacement is built. Drawing goes to an in-memory frame of the same size;
+// everything touching the hardware fails, and Close does nothing.
+type closedDisplay struct {
+	*OffscreenDisplay
+	caps Capabilities
+}
+
+// newClosedDisplay creates a stand-in for old with its size and capabilities
+func newClosedDisplay(old Display) *closedDisplay {
+	b := old.GetBounds()
+	return &closedDisplay{
+		OffscreenDisplay: NewOffscreenDisplay(b.Dx(), b.Dy()),
+		caps:             AsColorDisplay(old).Capabilities(),
+	}
+}
+
+// Init fails, as there is no device
+func (c *closedDisplay) Init() error {
+	return errDisplayClosed
+}
+
+// Show fails, as there is no device
+func (c *closedDisplay) Show() error {
+	return errDisplayClosed
+}
+
+// SetBrightness fails, as there is no device
+func (c *closedDisplay) SetBrightness(uint8) error {
+	return errDisplayClosed
+}
+
+// Sleep fails, as there is no device
+func (c *closedDisplay) Sleep() error {
+	return errDisplayClosed
+}
+
+// Wake fails, as there is no device
+func (c *closedDisplay) Wake() error {
+	return errDisplayClosed
+}
+
+// Capabilities reports the closed display's capabilities
+func (c *closedDisplay) Capabilities() Capabilities {
+	return c.caps
+}
@@ -0,0 +1,250 @@
+package display
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/health"
+	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/retry"
+)
+
+func newTestRecovering(inner Display, factory Factory) (*RecoveringDisplay, chan error) {
+	d := NewRecoveringDisplay(inner, factory, 3, logger.NewDefault())
+	d.retryConfig = retry.Config{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 2}
+	done := make(chan error, 1)
+	d.onReinitDone = func(err error) { done <- err }
+	return d, done
+}
+
+func TestRecoveringDisplayReinit(t *testing.T) {
+	broken := NewMockDisplay(128, 64)
+	broken.SetError(true, "i2c: remote I/O error")
+	replacement := NewMockDisplay(128, 64)
+
+	created := 0
+	d, done := newTestRecovering(broken, func() (Display, error) {
+		created++
+		if created == 1 {
+			return nil, errors.New("device not present")
+		}
+		return replacement, nil
+	})
+	if err := d.SetBrightness(42); err == nil {
+		t.Fatal("expected SetBrightness on broken display to fail")
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := d.Show(); err == nil {
+			t.Fatal("expected Show to fail on broken display")
+		}
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected recovery to succeed, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("re-initialization did not complete")
+	}
+
+	if created != 2 {
+		t.Errorf("expected factory to be retried once, got %d calls", created)
+	}
+	if err := d.Show(); err != nil {
+		t.Errorf("expected Show to succeed after recovery, got %v", err)
+	}
+	calls := replacement.GetCalls()
+	if len(calls) < 2 || calls[0] != "Init" || calls[1] != "SetBrightness([42])" {
+		t.Errorf("expected Init then brightness restore, got %v", calls)
+	}
+}
+
+func TestRecoveringDisplayBelowThreshold(t *testing.T) {
+	inner := NewMockDisplay(128, 64)
+	d, _ := newTestRecovering(inner, func() (Display, error) {
+		t.Error("factory should not be called")
+		return nil, errors.New("unexpected")
+	})
+
+	inner.SetError(true, "glitch")
+	_ = d.Show()
+	_ = d.Show()
+	inner.SetError(false, "")
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	// Success resets the consecutive failure count
+	inner.SetError(true, "glitch")
+	_ = d.Show()
+	_ = d.Show()
+	inner.SetError(false, "")
+
+	if err := d.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+}
+
+func TestRecoveringDisplayFailedRecovery(t *testing.T) {
+	broken := NewMockDisplay(128, 64)
+	broken.SetError(true, "gone")
+	d, done := newTestRecovering(broken, func() (Display, error) {
+		return nil, errors.New("device not present")
+	})
+	checker := health.New()
+	checker.RegisterComponent(health.ComponentDisplay)
+	d.SetHealthChecker(checker)
+
+	for i := 0; i < 3; i++ {
+		_ = d.Show()
+	}
+	if err := d.Show(); err == nil {
+		t.Error("expected Show to fail fast during re-init")
+	}
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected recovery to fail")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("re-initialization did not complete")
+	}
+	if got := checker.GetComponentStatus(health.ComponentDisplay).Status; got != health.StatusUnhealthy {
+		t.Errorf("expected display unhealthy after failed recovery, got %s", got)
+	}
+
+	// The closed device is never used again: drawing still works, Show
+	// fails and a second recovery does not close it twice
+	if err := d.DrawPixel(0, 0, true); err != nil {
+		t.Errorf("expected drawing to work without a display, got %v", err)
+	}
+	if b := d.GetBounds(); b.Dx() != 128 || b.Dy() != 64 {
+		t.Errorf("expected the closed display's size, got %v", b)
+	}
+	for i := 0; i < 3; i++ {
+		if err := d.Show(); err == nil {
+			t.Fatal("expected Show to fail without a display")
+		}
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("second re-initialization did not complete")
+	}
+	if err := d.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+	closes := 0
+	for _, call := range broken.GetCalls() {
+		if call == "Close" {
+			closes++
+		}
+	}
+	if closes != 1 {
+		t.Errorf("expected the broken display closed once, got %d", closes)
+	}
+}
+
+func TestRecoveringDisplayReinitOnRequest(t *testing.T) {
+	inner := NewMockDisplay(128, 64)
+	replacement := NewMockDisplay(128, 64)
+	d, done := newTestRecovering(inner, func() (Display, error) {
+		return replacement, nil
+	})
+
+	// Without any Show failures, as for a flush that never returned
+	d.Reinit()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected re-initialization to succeed, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("re-initialization did not complete")
+	}
+	if d.current() != replacement {
+		t.Error("expected the replacement display after Reinit")
+	}
+	if calls := inner.GetCalls(); len(calls) != 1 || calls[0] != "Close" {
+		t.Errorf("expected the old display closed once, got %v", calls)
+	}
+}
+
+// blockingDisplay holds Show until release is closed
+type blockingDisplay struct {
+	*MockDisplay
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingDisplay) Show() error {
+	close(b.started)
+	<-b.release
+	return b.MockDisplay.Show()
+}
+
+func TestRecoveringDisplayReinitWaitsForShow(t *testing.T) {
+	inner := &blockingDisplay{MockDisplay: NewMockDisplay(128, 64), started: make(chan struct{}), release: make(chan struct{})}
+	replacement := NewMockDisplay(128, 64)
+	d, done := newTestRecovering(inner, func() (Display, error) {
+		return replacement, nil
+	})
+
+	shown := make(chan error, 1)
+	go func() { shown <- d.Show() }()
+	<-inner.started
+	d.Reinit()
+
+	// The display is not closed under the transfer
+	select {
+	case <-done:
+		t.Fatal("expected re-initialization to wait for the Show in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	for _, call := range inner.GetCalls() {
+		if call == "Close" {
+			t.Fatal("expected the display not to be closed during Show")
+		}
+	}
+
+	close(inner.release)
+	if err := <-shown; err != nil {
+		t.Errorf("Show() failed: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected re-initialization to succeed, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("re-initialization did not complete")
+	}
+	if d.current() != replacement {
+		t.Error("expected the replacement display after Reinit")
+	}
+}
+
+func TestRecoveringDisplayCloseDuringWedgedShow(t *testing.T) {
+	inner := &blockingDisplay{MockDisplay: NewMockDisplay(128, 64), started: make(chan struct{}), release: make(chan struct{})}
+	d, _ := newTestRecovering(inner, func() (Display, error) {
+		t.Error("factory should not be called")
+		return nil, errors.New("unexpected")
+	})
+
+	go func() { _ = d.Show() }()
+	<-inner.started
+	d.Reinit()
+
+	// Close gives up on the waiting recovery rather than hang on it
+	closed := make(chan error, 1)
+	go func() { closed <- d.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close() hung on a recovery waiting for Show")
+	}
+	close(inner.release)
+}
@@ -0,0 +1,203 @@
+package display
+
+import (
+	"context"
+	"image/color"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/health"
+	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/retry"
+)
+
+// ErrorClass tells bus errors worth retrying from ones that will not go
+// away by themselves
+type ErrorClass int
+
+const (
+	// ErrorUnknown is an error not recognised as either class; it is not
+	// retried
+	ErrorUnknown ErrorClass = iota
+	// ErrorTransient is a glitch on the bus, such as a NACK (EREMOTEIO) or
+	// a timeout (ETIMEDOUT), that a retry usually gets past
+	ErrorTransient
+	// ErrorPermanent is a configuration error, such as a missing bus or a
+	// device that is not at the configured address
+	ErrorPermanent
+)
+
+// String returns the class name used in logs
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorTransient:
+		return "transient"
+	case ErrorPermanent:
+		return "permanent"
+	default:
+		return "unknown"
+	}
+}
+
+// The drivers' bus libraries format errors with %v, losing the errno, so
+// errors are classified by the errno's message
+var (
+	transientErrors = []string{
+		"remote I/O error",                 // EREMOTEIO: no ACK, often a marginal bus
+		"connection timed out",             // ETIMEDOUT: clock stretching or a stuck bus
+		"input/output error",               // EIO
+		"resource temporarily unavailable", // EAGAIN: bus arbitration lost
+		ErrInjectedFault.Error(),           // fault injection stands in for glitches
+	}
+	permanentErrors = []string{
+		"no such device or address", // ENXIO: nothing at the address
+		"no such device",            // ENODEV: driver or adapter gone
+		"no such file or directory", // ENOENT: bus not enabled
+		"permission denied",         // EACCES: not in the i2c, spi or video group
+		"invalid argument",          // EINVAL: bad speed, address or mode
+	}
+)
+
+// ClassifyError reports whether err is a transient bus error, a permanent
+// configuration error or neither
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorUnknown
+	}
+	msg := err.Error()
+	for _, s := range transientErrors {
+		if strings.Contains(msg, s) {
+			return ErrorTransient
+		}
+	}
+	for _, s := range permanentErrors {
+		if strings.Contains(msg, s) {
+			return ErrorPermanent
+		}
+	}
+	return ErrorUnknown
+}
+
+// BusRetry is the retry policy shared by the hardware displays it wraps, so
+// a display re-created after recovery keeps the same hooks
+type BusRetry struct {
+	cfg retry.Config
+	log *logger.Logger
+
+	mu      sync.Mutex
+	checker *health.Checker
+	onError func(op string, retrying bool)
+}
+
+// NewBusRetry creates a policy retrying transient errors twice within a few
+// tens of milliseconds, short enough to stay within a refresh
+func NewBusRetry(log *logger.Logger) *BusRetry {
+	return &BusRetry{
+		cfg: retry.Config{
+			MaxAttempts:     3,
+			InitialDelay:    10 * time.Millisecond,
+			MaxDelay:        50 * time.Millisecond,
+			Multiplier:      2.0,
+			RetryableErrors: transientErrors,
+		},
+		log: log,
+	}
+}
+
+// SetHealthChecker attaches a health checker. A permanent error marks the
+// display component unhealthy, as it will not recover on its own.
+func (b *BusRetry) SetHealthChecker(h *health.Checker) {
+	b.mu.Lock()
+	b.checker = h
+	b.mu.Unlock()
+}
+
+// SetOnErrorFunc sets a function called for each failed attempt of an
+// operation, with whether it is about to be retried
+func (b *BusRetry) SetOnErrorFunc(f func(op string, retrying bool)) {
+	b.mu.Lock()
+	b.onError = f
+	b.mu.Unlock()
+}
+
+// Wrap returns disp with its Init and Show retried under this policy
+func (b *BusRetry) Wrap(disp Display) *RetryingDisplay {
+	return &RetryingDisplay{Display: disp, policy: b}
+}
+
+// do runs op, retrying it on transient errors
+func (b *BusRetry) do(op string, f func() error) error {
+	attempt := 0
+	err := retry.Do(context.Background(), b.cfg, func() error {
+		attempt++
+		err := f()
+		if err != nil {
+			b.failed(op, attempt, err)
+		}
+		return err
+	})
+	if err != nil && attempt > 1 {
+		b.log.With().Str("operation", op).Int("attempts", attempt).Err(err).Logger().Debug("Display operation failed after retries")
+	}
+	return err
+}
+
+// failed records a failed attempt of op
+func (b *BusRetry) failed(op string, attempt int, err error) {
+	class := ClassifyError(err)
+	retrying := class == ErrorTransient && attempt < b.cfg.MaxAttempts
+
+	b.mu.Lock()
+	checker, onError := b.checker, b.onError
+	b.mu.Unlock()
+
+	if onError != nil {
+		onError(op, retrying)
+	}
+	if retrying {
+		b.log.With().Str("operation", op).Int("attempt", attempt).Err(err).Logger().Debug("Transient display error, retrying")
+	}
+	if class == ErrorPermanent && checker != nil {
+		checker.MarkUnhealthy(health.ComponentDisplay, err)
+	}
+}
+
+// RetryingDisplay wraps a hardware display and retries Init and Show when
+// they fail with a transient bus error. Permanent and unrecognised errors
+// are returned straight away. Drawing only touches the in-memory buffer and
+// is passed through.
+type RetryingDisplay struct {
+	Display
+	policy *BusRetry
+}
+
+// Init initializes the wrapped display, retrying transient errors
+func (r *RetryingDisplay) Init() error {
+	return r.policy.do("init", r.Display.Init)
+}
+
+// Show flushes the wrapped display, retrying transient errors
+func (r *RetryingDisplay) Show() error {
+	return r.policy.do("show", r.Display.Show)
+}
+
+// DrawPixelColor draws on the wrapped display
+func (r *RetryingDisplay) DrawPixelColor(x, y int, c color.Color) error {
+	return AsColorDisplay(r.Display).DrawPixelColor(x, y, c)
+}
+
+// FillRectColor draws on the wrapped display
+func (r *RetryingDisplay) FillRectColor(x, y, width, height int, c color.Color) error {
+	return AsColorDisplay(r.Display).FillRectColor(x, y, width, height, c)
+}
+
+// Capabilities reports the wrapped display's capabilities
+func (r *RetryingDisplay) Capabilities() Capabilities {
+	return AsColorDisplay(r.Display).Capabilities()
+}
+
+// WriteLines sets the wrapped display's text
+func (r *RetryingDisplay) WriteLines(lines []string) error {
+	return WriteLines(r.Display, lines)
+}
@@ -0,0 +1,123 @@
+package display
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/health"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// flakyDisplay fails Show with the queued errors before passing through
+type flakyDisplay struct {
+	Display
+	errs  []error
+	shows int
+}
+
+func (f *flakyDisplay) Show() error {
+	f.shows++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return err
+	}
+	return f.Display.Show()
+}
+
+func newTestBusRetry() *BusRetry {
+	b := NewBusRetry(logger.NewDefault())
+	b.cfg.InitialDelay = time.Millisecond
+	b.cfg.MaxDelay = time.Millisecond
+	return b
+}
+
+func TestClassifyError(t *testing.T) {
+	// periph formats errnos with %v, so the Linux message is all that is left
+	sysfs := func(msg string) error { return errors.New("sysfs-i2c: " + msg) }
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{sysfs("remote I/O error"), ErrorTransient},
+		{sysfs("connection timed out"), ErrorTransient},
+		{sysfs("input/output error"), ErrorTransient},
+		{fmt.Errorf("show: %w", ErrInjectedFault), ErrorTransient},
+		{sysfs("no such device or address"), ErrorPermanent},
+		{sysfs("no such file or directory"), ErrorPermanent},
+		{sysfs("permission denied"), ErrorPermanent},
+		{errors.New("display type foo cannot be mirrored"), ErrorUnknown},
+		{nil, ErrorUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryingDisplayTransient(t *testing.T) {
+	b := newTestBusRetry()
+	var failures, retries int
+	b.SetOnErrorFunc(func(op string, retrying bool) {
+		if op != "show" {
+			t.Errorf("expected show, got %q", op)
+		}
+		failures++
+		if retrying {
+			retries++
+		}
+	})
+
+	inner := &flakyDisplay{Display: NewMockDisplay(128, 64), errs: []error{
+		errors.New("i2c: remote I/O error"),
+		errors.New("i2c: connection timed out"),
+	}}
+	if err := b.Wrap(inner).Show(); err != nil {
+		t.Fatalf("expected Show to succeed on the third attempt, got %v", err)
+	}
+	if inner.shows != 3 || failures != 2 || retries != 2 {
+		t.Errorf("expected 3 shows, 2 failures and 2 retries, got %d, %d and %d", inner.shows, failures, retries)
+	}
+
+	// The last failure is not retried
+	inner.errs = []error{ErrInjectedFault, ErrInjectedFault, ErrInjectedFault}
+	inner.shows, failures, retries = 0, 0, 0
+	if err := b.Wrap(inner).Show(); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("expected the fault after retries, got %v", err)
+	}
+	if inner.shows != 3 || failures != 3 || retries != 2 {
+		t.Errorf("expected 3 shows, 3 failures and 2 retries, got %d, %d and %d", inner.shows, failures, retries)
+	}
+}
+
+func TestRetryingDisplayPermanent(t *testing.T) {
+	b := newTestBusRetry()
+	checker := health.New()
+	checker.RegisterComponent(health.ComponentDisplay)
+	b.SetHealthChecker(checker)
+
+	permanent := errors.New("sysfs-i2c: no such device or address")
+	inner := &flakyDisplay{Display: NewMockDisplay(128, 64), errs: []error{permanent}}
+	if err := b.Wrap(inner).Show(); !errors.Is(err, permanent) {
+		t.Errorf("expected the permanent error as it is, got %v", err)
+	}
+	if inner.shows != 1 {
+		t.Errorf("expected no retries, got %d shows", inner.shows)
+	}
+	if status := checker.GetComponentStatus(health.ComponentDisplay).Status; status != health.StatusUnhealthy {
+		t.Errorf("expected the display unhealthy, got %s", status)
+	}
+
+	// Errors that are not recognised are neither retried nor marked
+	checker.RecordSuccess(health.ComponentDisplay)
+	inner.errs, inner.shows = []error{errors.New("something else")}, 0
+	if err := b.Wrap(inner).Show(); err == nil || inner.shows != 1 {
+		t.Errorf("expected one failed show, got %d: %v", inner.shows, err)
+	}
+	if status := checker.GetComponentStatus(health.ComponentDisplay).Status; status != health.StatusHealthy {
+		t.Errorf("expected the display healthy, got %s", status)
+	}
+}
@@ -0,0 +1,88 @@
+package display
+
+import (
+	"image"
+	"image/color"
+	"sync"
+)
+
+// ShiftDisplay wraps a display and translates all drawing by a small pixel
+// offset. The screensaver uses it to move content around periodically and
+// spread OLED wear. Content shifted past an edge is clipped by the driver.
+type ShiftDisplay struct {
+	Display
+	mu     sync.RWMutex
+	dx, dy int
+}
+
+// NewShiftDisplay wraps disp with a zero offset
+func NewShiftDisplay(disp Display) *ShiftDisplay {
+	return &ShiftDisplay{Display: disp}
+}
+
+// SetOffset sets the translation applied to subsequent draw calls
+func (s *ShiftDisplay) SetOffset(dx, dy int) {
+	s.mu.Lock()
+	s.dx, s.dy = dx, dy
+	s.mu.Unlock()
+}
+
+// Offset returns the current translation
+func (s *ShiftDisplay) Offset() (dx, dy int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dx, s.dy
+}
+
+// DrawText draws text at the shifted position
+func (s *ShiftDisplay) DrawText(x, y int, text string, size int) error {
+	dx, dy := s.Offset()
+	return s.Display.DrawText(x+dx, y+dy, text, size)
+}
+
+// DrawLine draws a horizontal line at the shifted position
+func (s *ShiftDisplay) DrawLine(x, y, width int) error {
+	dx, dy := s.Offset()
+	return s.Display.DrawLine(x+dx, y+dy, width)
+}
+
+// DrawPixel sets a pixel at the shifted position
+func (s *ShiftDisplay) DrawPixel(x, y int, on bool) error {
+	dx, dy := s.Offset()
+	return s.Display.DrawPixel(x+dx, y+dy, on)
+}
+
+// DrawRect draws a rectangle at the shifted position
+func (s *ShiftDisplay) DrawRect(x, y, width, height int, fill bool) error {
+	dx, dy := s.Offset()
+	return s.Display.DrawRect(x+dx, y+dy, width, height, fill)
+}
+
+// DrawImage draws an image at the shifted position
+func (s *ShiftDisplay) DrawImage(x, y int, img image.Image) error {
+	dx, dy := s.Offset()
+	return s.Display.DrawImage(x+dx, y+dy, img)
+}
+
+// DrawPixelColor sets a coloured pixel at the shifted position
+func (s *ShiftDisplay) DrawPixelColor(x, y int, c color.Color) error {
+	dx, dy := s.Offset()
+	return AsColorDisplay(s.Display).DrawPixelColor(x+dx, y+dy, c)
+}
+
+// FillRectColor fills a rectangle at the shifted position
+func (s *ShiftDisplay) FillRectColor(x, y, width, height int, c color.Color) error {
+	dx, dy := s.Offset()
+	return AsColorDisplay(s.Display).FillRectColor(x+dx, y+dy, width, height, c)
+}
+
+// Capabilities reports the wrapped display's capabilities
+func (s *ShiftDisplay) Capabilities() Capabilities {
+	return AsColorDisplay(s.Display).Capabilities()
+}
+
+// WriteLines passes text through unshifted; character displays have no
+// pixels to move
+func (s *ShiftDisplay) WriteLines(lines []string) error {
+	return WriteLines(s.Display, lines)
+}
@@ -0,0 +1,49 @@
+package display
+
+import "testing"
+
+func TestShiftDisplay(t *testing.T) {
+	mock := NewMockDisplay(128, 64)
+	s := NewShiftDisplay(mock)
+
+	if err := s.DrawPixel(10, 10, true); err != nil {
+		t.Fatalf("DrawPixel() failed: %v", err)
+	}
+	if !mock.GetPixel(10, 10) {
+		t.Error("expected unshifted pixel at (10,10)")
+	}
+
+	s.SetOffset(2, -1)
+	if dx, dy := s.Offset(); dx != 2 || dy != -1 {
+		t.Errorf("Offset() = (%d,%d), want (2,-1)", dx, dy)
+	}
+	if err := s.DrawPixel(20, 20, true); err != nil {
+		t.Fatalf("DrawPixel() failed: %v", err)
+	}
+	if !mock.GetPixel(22, 19) || mock.GetPixel(20, 20) {
+		t.Error("expected pixel drawn at shifted position (22,19)")
+	}
+
+	if err := s.DrawRect(0, 0, 4, 4, true); err != nil {
+		t.Fatalf("DrawRect() failed: %v", err)
+	}
+	if !mock.GetPixel(2, 0) || mock.GetPixel(0, 0) {
+		t.Error("expected rectangle shifted right")
+	}
+}
+
+func TestShiftDisplayColor(t *testing.T) {
+	fb := NewOffscreenDisplay(16, 16)
+	s := NewShiftDisplay(fb)
+	s.SetOffset(1, 2)
+
+	if !s.Capabilities().Color() {
+		t.Error("expected capabilities of the wrapped display")
+	}
+	if err := s.DrawPixelColor(3, 3, ColorOn); err != nil {
+		t.Fatalf("DrawPixelColor() failed: %v", err)
+	}
+	if fb.Image().NRGBAAt(4, 5) != fbWhite {
+		t.Error("expected coloured pixel at shifted position (4,5)")
+	}
+}
@@ -2,36 +2,59 @@ package display
 
 import (
 	"fmt"
-	"image"
-	"image/color"
-	"image/draw"
+	"sync"
 
-	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/physic"
 	"periph.io/x/devices/v3/ssd1306"
-	"periph.io/x/host/v3"
+)
+
+// SSD1306 I2C control bytes and commands
+const (
+	ssd1306CommandMode    byte = 0x00 // the rest of the transaction is commands
+	ssd1306SingleCommand  byte = 0x80 // one command byte follows, then another control byte
+	ssd1306DataMode       byte = 0x40 // the rest of the transaction is GDDRAM data
+	ssd1306SetContrast    byte = 0x81
+	ssd1306DisplayOff     byte = 0xAE
+	ssd1306DisplayOn      byte = 0xAF
+	ssd1306SetColumnRange byte = 0x21 // start and end column, horizontal addressing mode
+	ssd1306SetPageRange   byte = 0x22 // start and end page, horizontal addressing mode
 )
 
 // SSD1306Display implements Display interface for real SSD1306 hardware
 type SSD1306Display struct {
-	dev    *ssd1306.Dev
-	img    *image.Gray
-	width  int
-	height int
+	*Framebuffer
+	dev  *ssd1306.Dev // sends the init sequence and halts the panel on Close
+	conn *i2c.Dev     // raw connection for frames and commands periph's driver doesn't cover
+
+	mu    sync.Mutex
+	shown []byte // pages last written to the panel, nil when unknown
 }
 
-// NewSSD1306Display creates a new SSD1306 display driver
-func NewSSD1306Display(i2cBus, i2cAddr string, width, height, rotation int) (*SSD1306Display, error) {
-	// Initialize periph host
-	if _, err := host.Init(); err != nil {
-		return nil, fmt.Errorf("failed to initialize periph: %w", err)
+// NewSSD1306Display creates a new SSD1306 display driver. speed is the I2C
+// clock, 0 to keep the bus's current speed.
+func NewSSD1306Display(i2cBus, i2cAddr string, speed physic.Frequency, width, height, rotation int) (*SSD1306Display, error) {
+	bus, err := openI2C("ssd1306", i2cBus, speed)
+	if err != nil {
+		return nil, err
 	}
 
-	// Open I2C bus
-	bus, err := i2creg.Open(i2cBus)
+	addr, err := parseI2CAddr(i2cAddr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open I2C bus %s: %w", i2cBus, err)
+		bus.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, err
 	}
 
+	d, err := newSSD1306OnBus(bus, addr, width, height, rotation)
+	if err != nil {
+		bus.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, err
+	}
+	return d, nil
+}
+
+// newSSD1306OnBus creates the driver on an already opened bus
+func newSSD1306OnBus(bus i2c.Bus, addr uint16, width, height, rotation int) (*SSD1306Display, error) {
 	// SSD1306 only supports 0° (no rotation) and 180° (Rotated flag).
 	// Hardware-level 90°/270° rotation is not available on this chip.
 	if rotation != 0 && rotation != 2 {
@@ -54,140 +77,76 @@ func NewSSD1306Display(i2cBus, i2cAddr string, width, height, rotation int) (*SS
 	}
 
 	return &SSD1306Display{
-		dev:    dev,
-		img:    image.NewGray(image.Rect(0, 0, width, height)),
-		width:  width,
-		height: height,
+		Framebuffer: NewFramebuffer(width, height, ColorModelMono),
+		dev:         dev,
+		conn:        &i2c.Dev{Bus: bus, Addr: addr},
 	}, nil
 }
 
 // Init initializes the display
 func (d *SSD1306Display) Init() error {
-	// The device is initialized in NewSSD1306Display
-	// Clear the display to start fresh
+	// The device is initialized in NewSSD1306Display; switching it on only
+	// matters after Sleep. Clear the display to start fresh.
+	d.mu.Lock()
+	d.shown = nil
+	err := d.conn.Tx([]byte{ssd1306CommandMode, ssd1306DisplayOn}, nil)
+	d.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to switch display on: %w", err)
+	}
 	return d.Clear()
 }
 
-// Clear clears the display
-func (d *SSD1306Display) Clear() error {
-	// Clear the image buffer
-	draw.Draw(d.img, d.img.Bounds(), &image.Uniform{color.Gray{Y: 0}}, image.Point{}, draw.Src)
-	return nil
-}
-
-// DrawText draws text at the specified position
-// Note: This is a simple implementation. For real text rendering,
-// you would need a font library like golang.org/x/image/font
-func (d *SSD1306Display) DrawText(x, y int, text string, size int) error {
-	// For now, this is a placeholder that draws a rectangle
-	// In a full implementation, you would use a font library
-	charWidth := size / 2
-	for i := range text {
-		startX := x + i*charWidth
-		if startX >= d.width {
-			break
+// Show writes the pages that changed since the last flush. The page window
+// and the GDDRAM data go out in a single I2C transaction, rather than a
+// command and a data transaction for every page, which cuts the per-transfer
+// start, address and stop overhead that dominates on 100kHz buses.
+func (d *SSD1306Display) Show() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	width := d.GetBounds().Dx()
+	pages := d.MonoPages()
+	first, last := 0, len(pages)/width-1
+	if d.shown != nil {
+		for first <= last && string(d.shown[first*width:(first+1)*width]) == string(pages[first*width:(first+1)*width]) {
+			first++
 		}
-		// Draw a simple rectangle to represent each character
-		if err := d.DrawRect(startX, y, charWidth-1, size, false); err != nil {
-			return err
+		for last >= first && string(d.shown[last*width:(last+1)*width]) == string(pages[last*width:(last+1)*width]) {
+			last--
 		}
-	}
-	return nil
-}
-
-// DrawLine draws a horizontal line
-func (d *SSD1306Display) DrawLine(x, y, width int) error {
-	for i := 0; i < width && x+i < d.width; i++ {
-		if x+i >= 0 && y >= 0 && y < d.height {
-			d.img.SetGray(x+i, y, color.Gray{Y: 255})
+		if first > last {
+			return nil
 		}
 	}
-	return nil
-}
-
-// DrawPixel draws a single pixel
-func (d *SSD1306Display) DrawPixel(x, y int, on bool) error {
-	if x < 0 || x >= d.width || y < 0 || y >= d.height {
-		return nil
-	}
 
-	if on {
-		d.img.SetGray(x, y, color.Gray{Y: 255})
-	} else {
-		d.img.SetGray(x, y, color.Gray{Y: 0})
+	frame := ssd1306Frame(pages[first*width:(last+1)*width], width, first, last)
+	if err := d.conn.Tx(frame, nil); err != nil {
+		// The panel contents are unknown now, so rewrite every page next time
+		d.shown = nil
+		return fmt.Errorf("failed to draw to display: %w", err)
 	}
-	return nil
-}
-
-// DrawRect draws a rectangle
-//
-//nolint:gocyclo // drawing logic naturally has many conditional branches
-func (d *SSD1306Display) DrawRect(x, y, width, height int, fill bool) error {
-	if fill {
-		for dy := 0; dy < height && y+dy < d.height; dy++ {
-			for dx := 0; dx < width && x+dx < d.width; dx++ {
-				if x+dx >= 0 && y+dy >= 0 {
-					d.img.SetGray(x+dx, y+dy, color.Gray{Y: 255})
-				}
-			}
-		}
-	} else {
-		// Draw outline
-		for i := 0; i < width && x+i < d.width; i++ {
-			if x+i >= 0 && y >= 0 {
-				d.img.SetGray(x+i, y, color.Gray{Y: 255})
-			}
-			if x+i >= 0 && y+height-1 >= 0 && y+height-1 < d.height {
-				d.img.SetGray(x+i, y+height-1, color.Gray{Y: 255})
-			}
-		}
-		for i := 0; i < height && y+i < d.height; i++ {
-			if x >= 0 && y+i >= 0 {
-				d.img.SetGray(x, y+i, color.Gray{Y: 255})
-			}
-			if x+width-1 >= 0 && x+width-1 < d.width && y+i >= 0 {
-				d.img.SetGray(x+width-1, y+i, color.Gray{Y: 255})
-			}
-		}
+	if d.shown == nil {
+		d.shown = make([]byte, len(pages))
 	}
+	copy(d.shown, pages)
 	return nil
 }
 
-// DrawImage draws an image at the specified position
-func (d *SSD1306Display) DrawImage(x, y int, img image.Image) error {
-	bounds := img.Bounds()
-	for dy := 0; dy < bounds.Dy() && y+dy < d.height; dy++ {
-		for dx := 0; dx < bounds.Dx() && x+dx < d.width; dx++ {
-			if x+dx < 0 || y+dy < 0 {
-				continue
-			}
-			r, g, b, a := img.At(bounds.Min.X+dx, bounds.Min.Y+dy).RGBA()
-			// Use max channel so saturated colours (e.g. pure green)
-			// render as white on the monochrome display.
-			brightness := r
-			if g > brightness {
-				brightness = g
-			}
-			if b > brightness {
-				brightness = b
-			}
-			if brightness > 32768 && a > 32768 {
-				d.img.SetGray(x+dx, y+dy, color.Gray{Y: 255})
-			} else {
-				d.img.SetGray(x+dx, y+dy, color.Gray{Y: 0})
-			}
-		}
+// ssd1306Frame builds one I2C write that selects the full-width window
+// covering pages first to last and fills it with data. Each window command
+// byte is sent as a single command so the transaction can switch to data.
+func ssd1306Frame(data []byte, width, first, last int) []byte {
+	cmds := []byte{
+		ssd1306SetColumnRange, 0, byte(width - 1), // #nosec G115 -- widths are at most 128
+		ssd1306SetPageRange, byte(first), byte(last), // #nosec G115 -- at most 8 pages
 	}
-	return nil
-}
-
-// Show flushes the buffer to the display
-func (d *SSD1306Display) Show() error {
-	// Draw the image to the display
-	if err := d.dev.Draw(d.img.Bounds(), d.img, image.Point{}); err != nil {
-		return fmt.Errorf("failed to draw to display: %w", err)
+	frame := make([]byte, 0, 2*len(cmds)+1+len(data))
+	for _, c := range cmds {
+		frame = append(frame, ssd1306SingleCommand, c)
 	}
-	return nil
+	frame = append(frame, ssd1306DataMode)
+	return append(frame, data...)
 }
 
 // Close closes the display connection
@@ -196,40 +155,35 @@ func (d *SSD1306Display) Close() error {
 	return d.dev.Halt()
 }
 
-// GetBounds returns the display dimensions
-func (d *SSD1306Display) GetBounds() image.Rectangle {
-	return d.img.Bounds()
+// Sleep switches the panel off (DISPLAYOFF); the controller keeps its RAM
+// and draws almost no current. Wake or Init switches it back on.
+func (d *SSD1306Display) Sleep() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.conn.Tx([]byte{ssd1306CommandMode, ssd1306DisplayOff}, nil); err != nil {
+		return fmt.Errorf("failed to switch display off: %w", err)
+	}
+	return nil
 }
 
-// GetBuffer returns a copy of the current display buffer
-func (d *SSD1306Display) GetBuffer() []byte {
-	// Convert image to byte buffer
-	buf := make([]byte, d.width*d.height/8)
-	for y := 0; y < d.height; y++ {
-		for x := 0; x < d.width; x++ {
-			if d.img.GrayAt(x, y).Y > 128 {
-				byteIdx := x + (y/8)*d.width
-				bitIdx := uint(y % 8) /* #nosec G115 -- modulo 8 is always 0–7 */
-				buf[byteIdx] |= 1 << bitIdx
-			}
-		}
+// Wake switches the panel back on (DISPLAYON), showing the RAM as it was
+// last written
+func (d *SSD1306Display) Wake() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.conn.Tx([]byte{ssd1306CommandMode, ssd1306DisplayOn}, nil); err != nil {
+		return fmt.Errorf("failed to switch display on: %w", err)
 	}
-	return buf
+	return nil
 }
 
 // SetBrightness sets the display contrast/brightness (0-255)
-// For SSD1306, this maps directly to the contrast control command
+// For SSD1306, this maps directly to the 0x81 contrast control command
 func (d *SSD1306Display) SetBrightness(level uint8) error {
-	// SSD1306 contrast command: 0x81 followed by contrast value
-	// The periph.io driver exposes the underlying device
-	// We'll use the Halt/Init cycle approach for now as periph.io
-	// doesn't expose contrast control directly
-
-	// Note: A production implementation would send raw I2C commands
-	// For now, we'll accept the limitation that brightness control
-	// isn't fully supported in periph.io's high-level API
-
-	// This is a placeholder that returns success
-	// Full implementation would require direct I2C communication
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.conn.Tx([]byte{ssd1306CommandMode, ssd1306SetContrast, level}, nil); err != nil {
+		return fmt.Errorf("failed to set contrast: %w", err)
+	}
 	return nil
 }
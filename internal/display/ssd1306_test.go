@@ -0,0 +1,150 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSSD1306SetBrightness(t *testing.T) {
+	bus := &fakeBus{devices: map[uint16]byte{0x3C: 0x06}}
+	d, err := newSSD1306OnBus(bus, 0x3C, 128, 64, 0)
+	if err != nil {
+		t.Fatalf("newSSD1306OnBus() failed: %v", err)
+	}
+	bus.writes = nil // discard controller init sequence
+
+	if err := d.SetBrightness(0x7F); err != nil {
+		t.Fatalf("SetBrightness() failed: %v", err)
+	}
+	if len(bus.writes) != 1 {
+		t.Fatalf("expected 1 write, got %d", len(bus.writes))
+	}
+	w := bus.writes[0]
+	if w.addr != 0x3C {
+		t.Errorf("expected write to 0x3C, got 0x%02X", w.addr)
+	}
+	if want := []byte{0x00, 0x81, 0x7F}; !bytes.Equal(w.data, want) {
+		t.Errorf("expected contrast command % X, got % X", want, w.data)
+	}
+}
+
+func TestSSD1306SetBrightnessError(t *testing.T) {
+	bus := &fakeBus{devices: map[uint16]byte{0x3C: 0x06}}
+	d, err := newSSD1306OnBus(bus, 0x3C, 128, 64, 0)
+	if err != nil {
+		t.Fatalf("newSSD1306OnBus() failed: %v", err)
+	}
+	delete(bus.devices, 0x3C) // simulate the panel being unplugged
+
+	if err := d.SetBrightness(10); err == nil {
+		t.Error("expected error when the bus write fails")
+	}
+}
+
+func TestSSD1306SleepWake(t *testing.T) {
+	bus := &fakeBus{devices: map[uint16]byte{0x3C: 0x06}}
+	d, err := newSSD1306OnBus(bus, 0x3C, 128, 64, 0)
+	if err != nil {
+		t.Fatalf("newSSD1306OnBus() failed: %v", err)
+	}
+	bus.writes = nil
+
+	if err := d.Sleep(); err != nil {
+		t.Fatalf("Sleep() failed: %v", err)
+	}
+	if err := d.Wake(); err != nil {
+		t.Fatalf("Wake() failed: %v", err)
+	}
+	if len(bus.writes) != 2 {
+		t.Fatalf("expected 2 writes, got %d", len(bus.writes))
+	}
+	for i, want := range [][]byte{{0x00, 0xAE}, {0x00, 0xAF}} {
+		if !bytes.Equal(bus.writes[i].data, want) {
+			t.Errorf("write %d = % X, want % X", i, bus.writes[i].data, want)
+		}
+	}
+}
+
+func TestSSD1306InvalidRotation(t *testing.T) {
+	bus := &fakeBus{devices: map[uint16]byte{0x3C: 0x06}}
+	if _, err := newSSD1306OnBus(bus, 0x3C, 128, 64, 1); err == nil {
+		t.Error("expected error for 90° rotation")
+	}
+}
+
+func TestSSD1306ShowBatchesFrame(t *testing.T) {
+	bus := &fakeBus{devices: map[uint16]byte{0x3C: 0x06}}
+	d, err := newSSD1306OnBus(bus, 0x3C, 128, 32, 0)
+	if err != nil {
+		t.Fatalf("newSSD1306OnBus() failed: %v", err)
+	}
+	bus.writes = nil // discard controller init sequence
+
+	// The first frame is sent whole, in one transaction
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 1 {
+		t.Fatalf("expected 1 write for a full frame, got %d", len(bus.writes))
+	}
+	header := []byte{0x80, 0x21, 0x80, 0x00, 0x80, 0x7F, 0x80, 0x22, 0x80, 0x00, 0x80, 0x03, 0x40}
+	w := bus.writes[0].data
+	if !bytes.HasPrefix(w, header) || len(w) != len(header)+128*4 {
+		t.Errorf("unexpected full frame: header % X, %d bytes", w[:min(len(w), len(header))], len(w))
+	}
+
+	// An unchanged frame sends nothing
+	bus.writes = nil
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 0 {
+		t.Errorf("expected no writes for an unchanged frame, got %d", len(bus.writes))
+	}
+
+	// A change on the third page sends only that page
+	if err := d.DrawPixel(5, 17, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 1 {
+		t.Fatalf("expected 1 write, got %d", len(bus.writes))
+	}
+	w = bus.writes[0].data
+	if want := []byte{0x80, 0x22, 0x80, 0x02, 0x80, 0x02, 0x40}; !bytes.Equal(w[6:13], want) {
+		t.Errorf("expected page window 2-2, got % X", w[6:13])
+	}
+	if len(w) != len(header)+128 || w[len(header)+5] != 0x02 {
+		t.Errorf("expected page 2 with pixel (5,17) lit, got %d bytes", len(w))
+	}
+}
+
+func TestSSD1306ShowRewritesAfterError(t *testing.T) {
+	bus := &fakeBus{devices: map[uint16]byte{0x3C: 0x06}}
+	d, err := newSSD1306OnBus(bus, 0x3C, 128, 64, 0)
+	if err != nil {
+		t.Fatalf("newSSD1306OnBus() failed: %v", err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+
+	delete(bus.devices, 0x3C)
+	if err := d.DrawPixel(0, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Show(); err == nil {
+		t.Fatal("expected Show to fail when the bus write fails")
+	}
+
+	bus.devices[0x3C] = 0x06
+	bus.writes = nil
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(bus.writes) != 1 || len(bus.writes[0].data) != 13+128*8 {
+		t.Error("expected the whole frame to be rewritten after a failed write")
+	}
+}
@@ -0,0 +1,415 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spireg"
+	"periph.io/x/host/v3"
+)
+
+// SSD1680 command bytes
+const (
+	ssd1680DriverOutput   = 0x01
+	ssd1680DeepSleep      = 0x10
+	ssd1680DataEntry      = 0x11
+	ssd1680SWReset        = 0x12
+	ssd1680TempSensor     = 0x18
+	ssd1680MasterActivate = 0x20
+	ssd1680UpdateControl1 = 0x21
+	ssd1680UpdateControl2 = 0x22
+	ssd1680WriteBWRAM     = 0x24
+	ssd1680WriteRedRAM    = 0x26 // holds the previous frame for partial refresh
+	ssd1680BorderWaveform = 0x3C
+	ssd1680RAMXRange      = 0x44
+	ssd1680RAMYRange      = 0x45
+	ssd1680RAMXCounter    = 0x4E
+	ssd1680RAMYCounter    = 0x4F
+)
+
+// Display update sequences for ssd1680UpdateControl2
+const (
+	ssd1680FullUpdate    = 0xF7 // load temperature and LUT, full refresh
+	ssd1680PartialUpdate = 0xFF // partial refresh against the previous frame
+)
+
+// SSD1680 panel geometry of the 2.13" module: 122 source lines (RAM X,
+// packed 8 per byte) by 250 gate lines (RAM Y). Frames are landscape, so
+// frame X runs along the gate lines.
+const (
+	ssd1680Sources = 122
+	ssd1680Gates   = 250
+)
+
+// E-paper timing
+const (
+	// ssd1680MinRefresh is the shortest interval between refreshes. A
+	// partial refresh takes about half a second and visibly flickers, and
+	// frequent updates wear the panel, so pages change far less often than
+	// on OLEDs.
+	ssd1680MinRefresh = 15 * time.Second
+	// ssd1680FullRefreshEvery is how many partial refreshes run before a
+	// full refresh clears the ghosting they leave behind
+	ssd1680FullRefreshEvery = 20
+	// ssd1680BusyTimeout bounds a wait for the controller; a full refresh
+	// takes about 2s
+	ssd1680BusyTimeout = 10 * time.Second
+)
+
+// ssd1680Conn is the part of spi.Conn the driver uses
+type ssd1680Conn interface {
+	Tx(w, r []byte) error
+}
+
+// ssd1680BusyPin is the part of gpio.PinIn the driver uses
+type ssd1680BusyPin interface {
+	Read() gpio.Level
+}
+
+// SSD1680Display implements Display for SSD1680 e-paper panels such as the
+// Waveshare 2.13" module (250x122) over SPI. Lit pixels are drawn as black
+// ink on white. Refreshes only send the window of RAM that changed and run a
+// partial update; every ssd1680FullRefreshEvery updates a full refresh
+// clears ghosting. The panel has no light, so brightness is ignored.
+type SSD1680Display struct {
+	*Framebuffer
+	port     spi.PortCloser // nil in tests
+	conn     ssd1680Conn
+	dc       gpio.PinOut
+	rst      gpio.PinOut // nil if not configured
+	busy     ssd1680BusyPin
+	rotation int
+	sleep    func(time.Duration) // time.Sleep, replaced in tests
+
+	shown    []byte // RAM contents on the panel, nil before the first refresh
+	partials int    // partial refreshes since the last full one
+	asleep   bool   // in deep sleep; Show keeps frames until Wake
+}
+
+// NewSSD1680Display creates a driver for an SSD1680 e-paper panel
+func NewSSD1680Display(spiBus, dcPin, rstPin, busyPin string, width, height, rotation int) (*SSD1680Display, error) {
+	if _, err := host.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize periph: %w", err)
+	}
+
+	dc := gpioreg.ByName(dcPin)
+	if dc == nil {
+		return nil, fmt.Errorf("DC pin %q not found", dcPin)
+	}
+	busy := gpioreg.ByName(busyPin)
+	if busy == nil {
+		return nil, fmt.Errorf("BUSY pin %q not found", busyPin)
+	}
+	if err := busy.In(gpio.Float, gpio.NoEdge); err != nil {
+		return nil, fmt.Errorf("failed to configure BUSY pin: %w", err)
+	}
+	var rst gpio.PinOut
+	if rstPin != "" {
+		if rst = gpioreg.ByName(rstPin); rst == nil {
+			return nil, fmt.Errorf("RST pin %q not found", rstPin)
+		}
+	}
+
+	port, err := spireg.Open(spiBus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SPI bus %s: %w", spiBus, err)
+	}
+	conn, err := port.Connect(4*physic.MegaHertz, spi.Mode0, 8)
+	if err != nil {
+		port.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, fmt.Errorf("failed to connect on SPI bus %s: %w", spiBus, err)
+	}
+
+	d, err := newSSD1680(conn, dc, rst, busy, width, height, rotation)
+	if err != nil {
+		port.Close() // #nosec G104 -- best-effort cleanup on error path
+		return nil, err
+	}
+	d.port = port
+	return d, nil
+}
+
+// newSSD1680 creates the driver on already opened pins and connection
+func newSSD1680(conn ssd1680Conn, dc, rst gpio.PinOut, busy ssd1680BusyPin, width, height, rotation int) (*SSD1680Display, error) {
+	if width != ssd1680Gates || height != ssd1680Sources {
+		return nil, fmt.Errorf("SSD1680 panel is %dx%d, got %dx%d", ssd1680Gates, ssd1680Sources, width, height)
+	}
+	if rotation != 0 && rotation != 2 {
+		return nil, fmt.Errorf("SSD1680 only supports rotation 0 (0°) and 2 (180°), got %d", rotation)
+	}
+	return &SSD1680Display{
+		Framebuffer: NewFramebuffer(width, height, ColorModelMono),
+		conn:        conn,
+		dc:          dc,
+		rst:         rst,
+		busy:        busy,
+		rotation:    rotation,
+		sleep:       time.Sleep,
+	}, nil
+}
+
+// Init resets the controller and configures it for the panel. The first
+// Show then runs a full refresh.
+func (d *SSD1680Display) Init() error {
+	if err := d.reset(); err != nil {
+		return err
+	}
+	if err := d.command(ssd1680SWReset); err != nil {
+		return fmt.Errorf("SSD1680 init failed: %w", err)
+	}
+	if err := d.waitIdle(); err != nil {
+		return err
+	}
+
+	lastGate := byte(ssd1680Gates - 1)
+	seq := []struct {
+		cmd  byte
+		data []byte
+	}{
+		{ssd1680DriverOutput, []byte{lastGate, 0x00, 0x00}},
+		{ssd1680DataEntry, []byte{0x03}}, // X and Y increment
+		{ssd1680BorderWaveform, []byte{0x05}},
+		{ssd1680UpdateControl1, []byte{0x00, 0x80}},
+		{ssd1680TempSensor, []byte{0x80}}, // internal sensor
+	}
+	for _, step := range seq {
+		if err := d.command(step.cmd, step.data...); err != nil {
+			return fmt.Errorf("SSD1680 init failed: %w", err)
+		}
+	}
+	d.shown = nil
+	d.partials = 0
+	d.asleep = false
+	return d.waitIdle()
+}
+
+// Show refreshes the panel if the frame changed since the last refresh.
+// While the controller sleeps the frame is kept for the refresh after Wake.
+func (d *SSD1680Display) Show() error {
+	if d.asleep {
+		return nil
+	}
+	ram := d.ram()
+	if d.shown != nil && bytes.Equal(ram, d.shown) {
+		return nil
+	}
+
+	full := d.shown == nil || d.partials >= ssd1680FullRefreshEvery
+	if err := d.refresh(ram, full); err != nil {
+		// The panel contents are unknown now, so the next refresh is full
+		d.shown = nil
+		return err
+	}
+	d.shown = ram
+	if full {
+		d.partials = 0
+	} else {
+		d.partials++
+	}
+	return nil
+}
+
+// refresh sends ram to the panel. A partial refresh only sends the window
+// that differs from the frame on the panel.
+func (d *SSD1680Display) refresh(ram []byte, full bool) error {
+	x0, x1, y0, y1 := 0, ssd1680RowBytes-1, 0, ssd1680Gates-1
+	if !full {
+		x0, x1, y0, y1 = changedWindow(d.shown, ram)
+	}
+	window := cropRAM(ram, x0, x1, y0, y1)
+
+	if err := d.writeRAM(ssd1680WriteBWRAM, x0, x1, y0, y1, window); err != nil {
+		return err
+	}
+	if err := d.update(full); err != nil {
+		return err
+	}
+	// The previous-frame RAM must match the panel for the next partial
+	// refresh to drive only the pixels that change
+	return d.writeRAM(ssd1680WriteRedRAM, x0, x1, y0, y1, window)
+}
+
+// Sleep puts the controller into deep sleep. Only a reset wakes it, so
+// without a reset pin it stays awake; the panel keeps its image either way.
+func (d *SSD1680Display) Sleep() error {
+	if d.rst == nil || d.asleep {
+		return nil
+	}
+	if err := d.command(ssd1680DeepSleep, 0x01); err != nil {
+		return err
+	}
+	d.asleep = true
+	return nil
+}
+
+// Wake resets the controller out of deep sleep and configures it again.
+// The next Show runs a full refresh.
+func (d *SSD1680Display) Wake() error {
+	if !d.asleep {
+		return nil
+	}
+	return d.Init()
+}
+
+// Close puts the controller into deep sleep, which keeps the image on the
+// panel without power, and closes the SPI port
+func (d *SSD1680Display) Close() error {
+	err := d.command(ssd1680DeepSleep, 0x01)
+	if d.port != nil {
+		if closeErr := d.port.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// SetBrightness is a no-op: e-paper has no light to dim
+func (d *SSD1680Display) SetBrightness(level uint8) error {
+	return nil
+}
+
+// Capabilities reports a monochrome panel with a minimum refresh interval
+func (d *SSD1680Display) Capabilities() Capabilities {
+	return Capabilities{ColorDepth: 1, MinRefreshInterval: ssd1680MinRefresh}
+}
+
+// ssd1680RowBytes is the size of one RAM row: the source lines padded to
+// whole bytes
+const ssd1680RowBytes = (ssd1680Sources + 7) / 8
+
+// ram packs the frame in RAM order, one row per gate line, most significant
+// bit first. A set bit is white, so lit pixels become black ink.
+func (d *SSD1680Display) ram() []byte {
+	buf := bytes.Repeat([]byte{0xFF}, ssd1680RowBytes*ssd1680Gates)
+	img := d.Image()
+	for fy := 0; fy < ssd1680Sources; fy++ {
+		for fx := 0; fx < ssd1680Gates; fx++ {
+			if img.NRGBAAt(fx, fy).R <= 128 {
+				continue
+			}
+			// Frame X runs along the gates and frame Y across the sources
+			x, y := fy, ssd1680Gates-1-fx
+			if d.rotation == 2 {
+				x, y = ssd1680Sources-1-fy, fx
+			}
+			buf[y*ssd1680RowBytes+x/8] &^= 0x80 >> (x % 8)
+		}
+	}
+	return buf
+}
+
+// changedWindow returns the smallest window of RAM bytes, as inclusive byte
+// columns and gate rows, that holds every difference between old and new
+func changedWindow(old, new []byte) (x0, x1, y0, y1 int) {
+	x0, y0 = ssd1680RowBytes, ssd1680Gates
+	x1, y1 = -1, -1
+	for i := range new {
+		if new[i] == old[i] {
+			continue
+		}
+		x, y := i%ssd1680RowBytes, i/ssd1680RowBytes
+		x0, x1 = min(x0, x), max(x1, x)
+		y0, y1 = min(y0, y), max(y1, y)
+	}
+	return x0, x1, y0, y1
+}
+
+// cropRAM copies the window of RAM bytes out of ram, row by row
+func cropRAM(ram []byte, x0, x1, y0, y1 int) []byte {
+	out := make([]byte, 0, (x1-x0+1)*(y1-y0+1))
+	for y := y0; y <= y1; y++ {
+		out = append(out, ram[y*ssd1680RowBytes+x0:y*ssd1680RowBytes+x1+1]...)
+	}
+	return out
+}
+
+// writeRAM sets the RAM window and writes data into it through cmd
+func (d *SSD1680Display) writeRAM(cmd byte, x0, x1, y0, y1 int, data []byte) error {
+	steps := []struct {
+		cmd  byte
+		data []byte
+	}{
+		{ssd1680RAMXRange, []byte{byte(x0), byte(x1)}},                               // #nosec G115 -- byte columns are below 16
+		{ssd1680RAMYRange, []byte{byte(y0), byte(y0 >> 8), byte(y1), byte(y1 >> 8)}}, // #nosec G115 -- gate rows are below 250
+		{ssd1680RAMXCounter, []byte{byte(x0)}},                                       // #nosec G115 -- byte columns are below 16
+		{ssd1680RAMYCounter, []byte{byte(y0), byte(y0 >> 8)}},                        // #nosec G115 -- gate rows are below 250
+		{cmd, data},
+	}
+	for _, step := range steps {
+		if err := d.command(step.cmd, step.data...); err != nil {
+			return fmt.Errorf("failed to write e-paper RAM: %w", err)
+		}
+	}
+	return nil
+}
+
+// update runs a full or partial refresh and waits for it to finish
+func (d *SSD1680Display) update(full bool) error {
+	sequence := byte(ssd1680PartialUpdate)
+	if full {
+		sequence = ssd1680FullUpdate
+	}
+	if err := d.command(ssd1680UpdateControl2, sequence); err != nil {
+		return fmt.Errorf("failed to refresh e-paper: %w", err)
+	}
+	if err := d.command(ssd1680MasterActivate); err != nil {
+		return fmt.Errorf("failed to refresh e-paper: %w", err)
+	}
+	return d.waitIdle()
+}
+
+// reset pulses the reset line, if wired, which also wakes the controller
+// from deep sleep
+func (d *SSD1680Display) reset() error {
+	if d.rst == nil {
+		return nil
+	}
+	for _, level := range []gpio.Level{gpio.High, gpio.Low, gpio.High} {
+		if err := d.rst.Out(level); err != nil {
+			return fmt.Errorf("RST failed: %w", err)
+		}
+		d.sleep(10 * time.Millisecond)
+	}
+	return nil
+}
+
+// waitIdle waits for the controller to release BUSY
+func (d *SSD1680Display) waitIdle() error {
+	const poll = 10 * time.Millisecond
+	for waited := time.Duration(0); d.busy.Read() == gpio.High; waited += poll {
+		if waited >= ssd1680BusyTimeout {
+			return fmt.Errorf("e-paper controller busy for over %s", ssd1680BusyTimeout)
+		}
+		d.sleep(poll)
+	}
+	return nil
+}
+
+// command sends cmd with DC low, then its data with DC high
+func (d *SSD1680Display) command(cmd byte, data ...byte) error {
+	if err := d.dc.Out(gpio.Low); err != nil {
+		return err
+	}
+	if err := d.conn.Tx([]byte{cmd}, nil); err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if err := d.dc.Out(gpio.High); err != nil {
+		return err
+	}
+	for len(data) > 0 {
+		chunk := data[:min(len(data), spiMaxTx)]
+		if err := d.conn.Tx(chunk, nil); err != nil {
+			return err
+		}
+		data = data[len(chunk):]
+	}
+	return nil
+}
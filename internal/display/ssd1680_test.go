@@ -0,0 +1,180 @@
+package display
+
+import (
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+// epdCommand is a command sent to the controller with its data bytes
+type epdCommand struct {
+	cmd  byte
+	data []byte
+}
+
+// fakeEPDConn records commands, telling them from data by the DC pin level
+type fakeEPDConn struct {
+	dc   *fakePin
+	cmds []epdCommand
+}
+
+func (c *fakeEPDConn) Tx(w, r []byte) error {
+	if c.dc.level == gpio.Low {
+		c.cmds = append(c.cmds, epdCommand{cmd: w[0]})
+		return nil
+	}
+	last := &c.cmds[len(c.cmds)-1]
+	last.data = append(last.data, w...)
+	return nil
+}
+
+// find returns the commands sent with code cmd
+func (c *fakeEPDConn) find(cmd byte) []epdCommand {
+	var out []epdCommand
+	for _, e := range c.cmds {
+		if e.cmd == cmd {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+type fakeBusyPin struct {
+	level gpio.Level
+}
+
+func (p *fakeBusyPin) Read() gpio.Level { return p.level }
+
+func newTestSSD1680(t *testing.T, rotation int) (*SSD1680Display, *fakeEPDConn) {
+	t.Helper()
+	dc := &fakePin{}
+	conn := &fakeEPDConn{dc: dc}
+	d, err := newSSD1680(conn, dc, &fakePin{}, &fakeBusyPin{level: gpio.Low}, 250, 122, rotation)
+	if err != nil {
+		t.Fatalf("newSSD1680() failed: %v", err)
+	}
+	d.sleep = func(time.Duration) {}
+	if err := d.Init(); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	conn.cmds = nil
+	return d, conn
+}
+
+func TestSSD1680Refresh(t *testing.T) {
+	d, conn := newTestSSD1680(t, 0)
+
+	// The first refresh is full and fills both RAMs
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if got := conn.find(ssd1680UpdateControl2); len(got) != 1 || got[0].data[0] != ssd1680FullUpdate {
+		t.Fatalf("expected a full update, got %+v", got)
+	}
+	for _, cmd := range []byte{ssd1680WriteBWRAM, ssd1680WriteRedRAM} {
+		got := conn.find(cmd)
+		if len(got) != 1 || len(got[0].data) != ssd1680RowBytes*ssd1680Gates {
+			t.Fatalf("command 0x%02X: expected one full RAM write", cmd)
+		}
+	}
+
+	// Nothing changed, nothing sent
+	conn.cmds = nil
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(conn.cmds) != 0 {
+		t.Errorf("expected no commands for an unchanged frame, got %d", len(conn.cmds))
+	}
+
+	// One lit pixel at the top left: a one byte partial window on the last
+	// gate line, with the pixel drawn black
+	conn.cmds = nil
+	if err := d.DrawPixel(0, 0, true); err != nil {
+		t.Fatalf("DrawPixel() failed: %v", err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if got := conn.find(ssd1680UpdateControl2); len(got) != 1 || got[0].data[0] != ssd1680PartialUpdate {
+		t.Fatalf("expected a partial update, got %+v", got)
+	}
+	if got := conn.find(ssd1680RAMXRange); len(got) == 0 || got[0].data[0] != 0 || got[0].data[1] != 0 {
+		t.Errorf("unexpected X window %+v", got)
+	}
+	if got := conn.find(ssd1680RAMYRange); len(got) == 0 || got[0].data[0] != 249 || got[0].data[2] != 249 {
+		t.Errorf("unexpected Y window %+v", got)
+	}
+	bw := conn.find(ssd1680WriteBWRAM)
+	if len(bw) != 1 || len(bw[0].data) != 1 || bw[0].data[0] != 0x7F {
+		t.Errorf("expected a single byte 0x7F, got %+v", bw)
+	}
+}
+
+func TestSSD1680PeriodicFullRefresh(t *testing.T) {
+	d, conn := newTestSSD1680(t, 0)
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+
+	for i := 0; i <= ssd1680FullRefreshEvery; i++ {
+		conn.cmds = nil
+		if err := d.DrawPixel(i, 10, true); err != nil {
+			t.Fatalf("DrawPixel() failed: %v", err)
+		}
+		if err := d.Show(); err != nil {
+			t.Fatalf("Show() failed: %v", err)
+		}
+		want := byte(ssd1680PartialUpdate)
+		if i == ssd1680FullRefreshEvery {
+			want = ssd1680FullUpdate
+		}
+		if got := conn.find(ssd1680UpdateControl2); len(got) != 1 || got[0].data[0] != want {
+			t.Fatalf("refresh %d: expected update 0x%02X, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestSSD1680Rotation(t *testing.T) {
+	d, _ := newTestSSD1680(t, 2)
+	if err := d.DrawPixel(0, 0, true); err != nil {
+		t.Fatalf("DrawPixel() failed: %v", err)
+	}
+	ram := d.ram()
+	// Rotated 180°: the last source line of the first gate line
+	idx := (ssd1680Sources - 1) / 8
+	if want := ^byte(0x80 >> ((ssd1680Sources - 1) % 8)); ram[idx] != want {
+		t.Errorf("expected byte %d to be 0x%02X, got 0x%02X", idx, want, ram[idx])
+	}
+}
+
+func TestSSD1680BusyTimeout(t *testing.T) {
+	dc := &fakePin{}
+	d, err := newSSD1680(&fakeEPDConn{dc: dc}, dc, nil, &fakeBusyPin{level: gpio.High}, 250, 122, 0)
+	if err != nil {
+		t.Fatalf("newSSD1680() failed: %v", err)
+	}
+	d.sleep = func(time.Duration) {}
+	if err := d.Init(); err == nil {
+		t.Error("expected Init to fail while the controller stays busy")
+	}
+}
+
+func TestSSD1680Invalid(t *testing.T) {
+	dc := &fakePin{}
+	conn := &fakeEPDConn{dc: dc}
+	if _, err := newSSD1680(conn, dc, nil, &fakeBusyPin{}, 128, 64, 0); err == nil {
+		t.Error("expected error for the wrong panel size")
+	}
+	if _, err := newSSD1680(conn, dc, nil, &fakeBusyPin{}, 250, 122, 1); err == nil {
+		t.Error("expected error for 90° rotation")
+	}
+}
+
+func TestSSD1680Capabilities(t *testing.T) {
+	d, _ := newTestSSD1680(t, 0)
+	if got := AsColorDisplay(NewShiftDisplay(d)).Capabilities().MinRefreshInterval; got != ssd1680MinRefresh {
+		t.Errorf("MinRefreshInterval = %s, want %s", got, ssd1680MinRefresh)
+	}
+}
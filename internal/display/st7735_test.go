@@ -0,0 +1,118 @@
+package display
+
+import (
+	"errors"
+	"testing"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/pin"
+)
+
+// fakePin is a gpio.PinOut recording the last level and PWM settings
+type fakePin struct {
+	level   gpio.Level
+	duty    gpio.Duty
+	freq    physic.Frequency
+	pwmErr  error
+	pwmUsed bool
+}
+
+func (p *fakePin) String() string             { return "FAKE" }
+func (p *fakePin) Halt() error                { return nil }
+func (p *fakePin) Name() string               { return "FAKE" }
+func (p *fakePin) Number() int                { return 0 }
+func (p *fakePin) Function() string           { return "" }
+func (p *fakePin) Func() pin.Func             { return pin.FuncNone }
+func (p *fakePin) SupportedFuncs() []pin.Func { return nil }
+func (p *fakePin) SetFunc(f pin.Func) error   { return nil }
+func (p *fakePin) Out(l gpio.Level) error     { p.level = l; p.pwmUsed = false; return nil }
+func (p *fakePin) PWM(d gpio.Duty, f physic.Frequency) error {
+	if p.pwmErr != nil {
+		return p.pwmErr
+	}
+	p.duty, p.freq, p.pwmUsed = d, f, true
+	return nil
+}
+
+func TestDriveBacklight(t *testing.T) {
+	p := &fakePin{}
+
+	if err := driveBacklight(p, 255); err != nil {
+		t.Fatalf("driveBacklight(255) failed: %v", err)
+	}
+	if p.level != gpio.High || p.pwmUsed {
+		t.Errorf("expected plain high for full brightness, got level=%v pwm=%v", p.level, p.pwmUsed)
+	}
+
+	if err := driveBacklight(p, 0); err != nil {
+		t.Fatalf("driveBacklight(0) failed: %v", err)
+	}
+	if p.level != gpio.Low || p.pwmUsed {
+		t.Errorf("expected plain low when blanked, got level=%v pwm=%v", p.level, p.pwmUsed)
+	}
+
+	if err := driveBacklight(p, 51); err != nil {
+		t.Fatalf("driveBacklight(51) failed: %v", err)
+	}
+	if !p.pwmUsed || p.duty != gpio.DutyMax/5 || p.freq != tftBacklightFreq {
+		t.Errorf("expected 20%% duty PWM, got duty=%v freq=%v", p.duty, p.freq)
+	}
+}
+
+func TestDriveBacklightNoPWM(t *testing.T) {
+	p := &fakePin{pwmErr: errors.New("pwm not supported")}
+	if err := driveBacklight(p, 100); err != nil {
+		t.Fatalf("driveBacklight() failed: %v", err)
+	}
+	if p.level != gpio.High {
+		t.Errorf("expected fallback to on for pins without PWM, got %v", p.level)
+	}
+}
+
+func TestST7735SetBrightnessWithoutPin(t *testing.T) {
+	d := &ST7735Display{}
+	if err := d.SetBrightness(10); err != nil {
+		t.Errorf("expected no-op without bl_pin, got %v", err)
+	}
+}
+
+func TestST7735ApplyRotationVariants(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     ST7735Options
+		rotation int
+		madctl   byte
+		col, row int
+	}{
+		{"black tab", ST7735Options{}, 0, madctlMX | madctlMY, 0, 0},
+		{"bgr order", ST7735Options{BGR: true}, 0, madctlMX | madctlMY | madctlBGR, 0, 0},
+		{"red tab", ST7735Options{Variant: ST7735RedTab}, 2, madctlBGR, 0, 0},
+		{"green tab", ST7735Options{Variant: ST7735GreenTab}, 0, madctlMX | madctlMY | madctlBGR, 2, 1},
+		{"green tab rotated", ST7735Options{Variant: ST7735GreenTab}, 1, madctlMY | madctlMV | madctlBGR, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &fakePin{}
+			c := &fakeSPIConn{dc: dc}
+			d := &ST7735Display{
+				tftSPI:      tftSPI{conn: c, dc: dc},
+				panelWidth:  128,
+				panelHeight: 160,
+				opts:        tt.opts,
+			}
+			if err := d.applyRotation(tt.rotation); err != nil {
+				t.Fatalf("applyRotation() failed: %v", err)
+			}
+			if len(c.writes) != 2 || c.writes[0].data[0] != tftMADCTL {
+				t.Fatalf("expected a MADCTL command, got %+v", c.writes)
+			}
+			if got := c.writes[1].data[0]; got != tt.madctl {
+				t.Errorf("MADCTL = 0x%02X, want 0x%02X", got, tt.madctl)
+			}
+			if d.colOffset != tt.col || d.rowOffset != tt.row {
+				t.Errorf("offsets = (%d, %d), want (%d, %d)", d.colOffset, d.rowOffset, tt.col, tt.row)
+			}
+		})
+	}
+}
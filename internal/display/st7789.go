@@ -0,0 +1,181 @@
+package display
+
+import (
+	"fmt"
+	"time"
+
+	"periph.io/x/conn/v3/physic"
+)
+
+// ST7789 command bytes; the DCS commands it shares with the ST7735 are in
+// tft.go
+const (
+	st7789PORCTRL   = 0xB2
+	st7789GCTRL     = 0xB7
+	st7789VCOMS     = 0xBB
+	st7789LCMCTRL   = 0xC0
+	st7789VDVVRHEN  = 0xC2
+	st7789VRHS      = 0xC3
+	st7789VDVS      = 0xC4
+	st7789FRCTRL2   = 0xC6
+	st7789PWCTRL1   = 0xD0
+	st7789PVGAMCTRL = 0xE0
+	st7789NVGAMCTRL = 0xE1
+)
+
+// st7789RAMHeight is the number of rows of controller RAM. Panels shorter
+// than this, such as the 240x240 ones, only show part of it.
+const st7789RAMHeight = 320
+
+// ST7789Display implements Display for ST7789 IPS TFT panels (1.3" 240x240
+// and 2" 240x320) via SPI. It shares the SPI transport and RGB565 frame
+// writes with the ST7735.
+type ST7789Display struct {
+	*Framebuffer
+	tftSPI
+	panelWidth  int // physical panel width (before rotation)
+	panelHeight int // physical panel height (before rotation)
+}
+
+// NewST7789Display creates a new ST7789 display driver. speed is the SPI
+// clock, 0 for the default of 40 MHz.
+func NewST7789Display(spiBus, dcPin, rstPin, blPin string, speed physic.Frequency, width, height, rotation int) (*ST7789Display, error) {
+	if rotation < 0 || rotation > 3 {
+		return nil, fmt.Errorf("ST7789 rotation must be 0-3, got %d", rotation)
+	}
+
+	t, err := openTFTSPI("st7789", spiBus, dcPin, rstPin, blPin, speed, 40*physic.MegaHertz)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &ST7789Display{
+		tftSPI:      t,
+		Framebuffer: NewFramebuffer(width, height, ColorModelRGB565),
+		panelWidth:  width,
+		panelHeight: height,
+	}
+
+	if err := d.hardwareReset(); err != nil {
+		d.closeOnError("st7789")
+		return nil, err
+	}
+
+	if err := d.initSequence(); err != nil {
+		d.closeOnError("st7789")
+		return nil, err
+	}
+
+	madctl, colOff, rowOff := st7789RotationParams(rotation, height)
+	d.colOffset, d.rowOffset = colOff, rowOff
+	if err := d.sendCmdData(tftMADCTL, madctl); err != nil {
+		d.closeOnError("st7789")
+		return nil, fmt.Errorf("ST7789 rotation failed: %w", err)
+	}
+
+	return d, nil
+}
+
+func (d *ST7789Display) initSequence() error {
+	seq := []func() error{
+		func() error { return d.sendCmd(tftSWRESET) },
+		func() error { time.Sleep(150 * time.Millisecond); return nil },
+		func() error { return d.sendCmd(tftSLPOUT) },
+		func() error { time.Sleep(120 * time.Millisecond); return nil },
+		func() error { return d.sendCmdData(tftCOLMOD, 0x55) }, // RGB565
+		func() error { return d.sendCmdData(st7789PORCTRL, 0x0C, 0x0C, 0x00, 0x33, 0x33) },
+		func() error { return d.sendCmdData(st7789GCTRL, 0x35) },
+		func() error { return d.sendCmdData(st7789VCOMS, 0x19) },
+		func() error { return d.sendCmdData(st7789LCMCTRL, 0x2C) },
+		func() error { return d.sendCmdData(st7789VDVVRHEN, 0x01) },
+		func() error { return d.sendCmdData(st7789VRHS, 0x12) },
+		func() error { return d.sendCmdData(st7789VDVS, 0x20) },
+		func() error { return d.sendCmdData(st7789FRCTRL2, 0x0F) }, // 60Hz
+		func() error { return d.sendCmdData(st7789PWCTRL1, 0xA4, 0xA1) },
+		func() error {
+			return d.sendCmdData(st7789PVGAMCTRL,
+				0xD0, 0x04, 0x0D, 0x11, 0x13, 0x2B, 0x3F,
+				0x54, 0x4C, 0x18, 0x0D, 0x0B, 0x1F, 0x23)
+		},
+		func() error {
+			return d.sendCmdData(st7789NVGAMCTRL,
+				0xD0, 0x04, 0x0C, 0x11, 0x13, 0x2C, 0x3F,
+				0x44, 0x51, 0x2F, 0x1F, 0x1F, 0x20, 0x23)
+		},
+		// IPS panels are built inverted, so inversion on shows true colours
+		func() error { return d.sendCmd(tftINVON) },
+		func() error { return d.sendCmd(tftNORON) },
+		func() error { return d.sendCmd(tftDISPON) },
+		func() error { time.Sleep(100 * time.Millisecond); return nil },
+	}
+
+	for _, step := range seq {
+		if err := step(); err != nil {
+			return fmt.Errorf("ST7789 init sequence failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// st7789RotationParams returns the MADCTL byte and RAM offsets for a given
+// rotation. The controller RAM is 240x320; a 240x240 panel is wired to the
+// first 240 rows, so the orientations that mirror the row axis (MY) have to
+// skip the 80 unused rows at the other end.
+func st7789RotationParams(rotation, panelHeight int) (madctl byte, colOffset, rowOffset int) {
+	unused := st7789RAMHeight - panelHeight
+	switch rotation {
+	case 0:
+		return madctlMX | madctlMY, 0, unused
+	case 1:
+		return madctlMY | madctlMV, unused, 0
+	case 2:
+		return 0x00, 0, 0
+	default:
+		return madctlMX | madctlMV, 0, 0
+	}
+}
+
+// Init clears the screen and switches the backlight on (the controller is
+// set up in the constructor).
+func (d *ST7789Display) Init() error {
+	if err := d.wake(); err != nil {
+		return err
+	}
+	d.shown = nil
+	if err := d.Clear(); err != nil {
+		return err
+	}
+	if err := d.Show(); err != nil {
+		return err
+	}
+	return d.SetBrightness(255)
+}
+
+// Show sends the rows that changed since the last frame as RGB565.
+func (d *ST7789Display) Show() error {
+	return d.writeFrame(d.Framebuffer)
+}
+
+// Sleep switches the backlight and panel off and puts the controller to
+// sleep (SLPIN). Wake or Init wakes it.
+func (d *ST7789Display) Sleep() error {
+	return d.sleep()
+}
+
+// Wake takes the controller out of sleep (SLPOUT) and switches the panel
+// on, showing its RAM as it was last written. The backlight follows the
+// next SetBrightness.
+func (d *ST7789Display) Wake() error {
+	return d.wake()
+}
+
+// Close switches the backlight and panel off and closes the SPI port.
+func (d *ST7789Display) Close() error {
+	return d.close("st7789")
+}
+
+// SetBrightness drives the backlight pin with PWM. It is a no-op when no
+// bl_pin is configured.
+func (d *ST7789Display) SetBrightness(level uint8) error {
+	return d.setBrightness(level)
+}
@@ -0,0 +1,107 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/spi"
+)
+
+// spiWrite is an SPI write with the DC level it was sent at
+type spiWrite struct {
+	dc   gpio.Level
+	data []byte
+}
+
+// fakeSPIConn is an spi.Conn recording writes
+type fakeSPIConn struct {
+	dc     *fakePin
+	writes []spiWrite
+}
+
+func (c *fakeSPIConn) String() string { return "fake" }
+
+func (c *fakeSPIConn) Tx(w, r []byte) error {
+	c.writes = append(c.writes, spiWrite{dc: c.dc.level, data: append([]byte(nil), w...)})
+	return nil
+}
+
+func (c *fakeSPIConn) Duplex() conn.Duplex { return conn.Full }
+
+func (c *fakeSPIConn) TxPackets(p []spi.Packet) error { return nil }
+
+func TestST7789RotationParams(t *testing.T) {
+	tests := []struct {
+		rotation, height int
+		madctl           byte
+		col, row         int
+	}{
+		{0, 240, madctlMX | madctlMY, 0, 80},
+		{1, 240, madctlMY | madctlMV, 80, 0},
+		{2, 240, 0x00, 0, 0},
+		{3, 240, madctlMX | madctlMV, 0, 0},
+		{0, 320, madctlMX | madctlMY, 0, 0},
+		{1, 320, madctlMY | madctlMV, 0, 0},
+	}
+	for _, tt := range tests {
+		madctl, col, row := st7789RotationParams(tt.rotation, tt.height)
+		if madctl != tt.madctl || col != tt.col || row != tt.row {
+			t.Errorf("rotation %d, height %d: got (0x%02X, %d, %d), want (0x%02X, %d, %d)",
+				tt.rotation, tt.height, madctl, col, row, tt.madctl, tt.col, tt.row)
+		}
+	}
+}
+
+func TestST7789Show(t *testing.T) {
+	dc := &fakePin{}
+	c := &fakeSPIConn{dc: dc}
+	d := &ST7789Display{
+		Framebuffer: NewFramebuffer(240, 320, ColorModelRGB565),
+		tftSPI:      tftSPI{conn: c, dc: dc},
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+
+	// Window coordinates past 255 need both bytes
+	want := []spiWrite{
+		{gpio.Low, []byte{tftCASET}},
+		{gpio.High, []byte{0x00, 0x00, 0x00, 0xEF}},
+		{gpio.Low, []byte{tftRASET}},
+		{gpio.High, []byte{0x00, 0x00, 0x01, 0x3F}},
+		{gpio.Low, []byte{tftRAMWR}},
+	}
+	for i, w := range want {
+		if c.writes[i].dc != w.dc || !bytes.Equal(c.writes[i].data, w.data) {
+			t.Errorf("write %d: got %+v, want %+v", i, c.writes[i], w)
+		}
+	}
+	var pixels int
+	for _, w := range c.writes[len(want):] {
+		if w.dc != gpio.High || len(w.data) > spiMaxTx {
+			t.Fatalf("pixel data should be sent as data in chunks of at most %d bytes", spiMaxTx)
+		}
+		pixels += len(w.data)
+	}
+	if pixels != 240*320*2 {
+		t.Errorf("expected %d bytes of RGB565, got %d", 240*320*2, pixels)
+	}
+}
+
+func TestST7789SetBrightness(t *testing.T) {
+	d := &ST7789Display{}
+	if err := d.SetBrightness(10); err != nil {
+		t.Errorf("expected no-op without bl_pin, got %v", err)
+	}
+
+	bl := &fakePin{}
+	d.bl = bl
+	if err := d.SetBrightness(0); err != nil {
+		t.Fatalf("SetBrightness(0) failed: %v", err)
+	}
+	if bl.level != gpio.Low {
+		t.Error("expected the backlight off at brightness 0")
+	}
+}
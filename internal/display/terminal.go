@@ -0,0 +1,165 @@
+package display
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ANSI escape sequences used by the terminal display
+const (
+	ansiClearScreen = "\x1b[2J"
+	ansiHome        = "\x1b[H"
+	ansiHideCursor  = "\x1b[?25l"
+	ansiShowCursor  = "\x1b[?25h"
+	ansiReset       = "\x1b[0m"
+)
+
+// TerminalDisplay draws the frame in a terminal, for developing page
+// layouts without hardware. Each character cell shows two vertically
+// stacked pixels using Unicode half blocks. Mono frames use plain block
+// characters; colour frames use 24-bit ANSI foreground and background
+// colours, so the terminal must support truecolor.
+type TerminalDisplay struct {
+	*Framebuffer
+	out io.Writer
+
+	mu         sync.Mutex // protects brightness and last
+	brightness uint8
+	last       string // last frame written, to skip unchanged redraws
+}
+
+// NewTerminalDisplay creates a terminal display writing to out. colour
+// selects an RGB565 frame drawn with ANSI colours instead of a mono one.
+func NewTerminalDisplay(out io.Writer, width, height int, colour bool) *TerminalDisplay {
+	model := ColorModelMono
+	if colour {
+		model = ColorModelRGB565
+	}
+	return &TerminalDisplay{
+		Framebuffer: NewFramebuffer(width, height, model),
+		out:         out,
+		brightness:  255,
+	}
+}
+
+// Init clears the terminal and hides the cursor
+func (t *TerminalDisplay) Init() error {
+	_, err := io.WriteString(t.out, ansiClearScreen+ansiHideCursor)
+	return err
+}
+
+// Show redraws the frame at the top of the terminal if it has changed
+func (t *TerminalDisplay) Show() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	frame := t.render()
+	if frame == t.last {
+		return nil
+	}
+	if _, err := io.WriteString(t.out, ansiHome+frame); err != nil {
+		return err
+	}
+	t.last = frame
+	return nil
+}
+
+// Close restores the cursor and colours
+func (t *TerminalDisplay) Close() error {
+	_, err := io.WriteString(t.out, ansiReset+ansiShowCursor+"\n")
+	return err
+}
+
+// SetBrightness scales colours by level; on mono frames 0 blanks the
+// display and any other level shows it normally. The next Show redraws.
+func (t *TerminalDisplay) SetBrightness(level uint8) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.brightness = level
+	t.last = ""
+	return nil
+}
+
+// Sleep is a no-op; SetBrightness(0) blanks the terminal
+func (t *TerminalDisplay) Sleep() error {
+	return nil
+}
+
+// Wake is a no-op
+func (t *TerminalDisplay) Wake() error {
+	return nil
+}
+
+// render returns the frame as terminal text. Callers hold mu.
+func (t *TerminalDisplay) render() string {
+	var b strings.Builder
+	for y := 0; y < t.height; y += 2 {
+		if t.model == ColorModelMono {
+			t.renderMonoRow(&b, y)
+		} else {
+			t.renderColorRow(&b, y)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// renderMonoRow writes the pixel rows y and y+1 as half block characters
+func (t *TerminalDisplay) renderMonoRow(b *strings.Builder, y int) {
+	for x := 0; x < t.width; x++ {
+		top := t.brightness > 0 && t.img.NRGBAAt(x, y).R > 128
+		bottom := t.brightness > 0 && y+1 < t.height && t.img.NRGBAAt(x, y+1).R > 128
+		switch {
+		case top && bottom:
+			b.WriteRune('█')
+		case top:
+			b.WriteRune('▀')
+		case bottom:
+			b.WriteRune('▄')
+		default:
+			b.WriteByte(' ')
+		}
+	}
+}
+
+// renderColorRow writes the pixel rows y and y+1 as upper half blocks with
+// the top pixel as foreground and the bottom pixel as background. Escape
+// sequences are only emitted when a colour changes.
+func (t *TerminalDisplay) renderColorRow(b *strings.Builder, y int) {
+	var fg, bg color.NRGBA
+	for x := 0; x < t.width; x++ {
+		top := dimColor(t.img.NRGBAAt(x, y), t.brightness)
+		bottom := fbBlack
+		if y+1 < t.height {
+			bottom = dimColor(t.img.NRGBAAt(x, y+1), t.brightness)
+		}
+		if x == 0 || top != fg {
+			fmt.Fprintf(b, "\x1b[38;2;%d;%d;%dm", top.R, top.G, top.B)
+			fg = top
+		}
+		if x == 0 || bottom != bg {
+			fmt.Fprintf(b, "\x1b[48;2;%d;%d;%dm", bottom.R, bottom.G, bottom.B)
+			bg = bottom
+		}
+		b.WriteRune('▀')
+	}
+	b.WriteString(ansiReset)
+}
+
+// dimColor scales c by a brightness level, for simulated displays that
+// show brightness changes
+func dimColor(c color.NRGBA, level uint8) color.NRGBA {
+	if level == 255 {
+		return c
+	}
+	l := uint16(level)
+	return color.NRGBA{
+		R: uint8(uint16(c.R) * l / 255), // #nosec G115 -- product / 255 fits uint8
+		G: uint8(uint16(c.G) * l / 255), // #nosec G115 -- product / 255 fits uint8
+		B: uint8(uint16(c.B) * l / 255), // #nosec G115 -- product / 255 fits uint8
+		A: 255,
+	}
+}
@@ -0,0 +1,77 @@
+package display
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+func TestTerminalDisplayMono(t *testing.T) {
+	var out bytes.Buffer
+	d := NewTerminalDisplay(&out, 4, 3, false)
+	if err := d.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	out.Reset()
+
+	_ = d.DrawPixel(0, 0, true) // top only
+	_ = d.DrawPixel(1, 1, true) // bottom only
+	_ = d.DrawPixel(2, 0, true) // both
+	_ = d.DrawPixel(2, 1, true)
+	_ = d.DrawPixel(3, 2, true) // odd last row, top half
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+
+	want := ansiHome + "▀▄█ \n   ▀\n"
+	if got := out.String(); got != want {
+		t.Errorf("Show() wrote %q, want %q", got, want)
+	}
+}
+
+func TestTerminalDisplaySkipsUnchangedFrames(t *testing.T) {
+	var out bytes.Buffer
+	d := NewTerminalDisplay(&out, 4, 2, false)
+	_ = d.DrawPixel(0, 0, true)
+	_ = d.Show()
+	out.Reset()
+
+	_ = d.Show()
+	if out.Len() != 0 {
+		t.Errorf("unchanged frame was redrawn: %q", out.String())
+	}
+
+	// A brightness change forces a redraw; 0 blanks a mono frame
+	_ = d.SetBrightness(0)
+	_ = d.Show()
+	if want := ansiHome + "    \n"; out.String() != want {
+		t.Errorf("Show() at brightness 0 wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestTerminalDisplayColour(t *testing.T) {
+	var out bytes.Buffer
+	d := NewTerminalDisplay(&out, 2, 2, true)
+	_ = d.DrawPixelColor(0, 0, color.NRGBA{R: 255, A: 255})
+	_ = d.DrawPixelColor(1, 0, color.NRGBA{R: 255, A: 255})
+	_ = d.DrawPixelColor(1, 1, color.NRGBA{B: 255, A: 255})
+	_ = d.SetBrightness(128)
+	_ = d.Show()
+
+	// The shared red foreground is only set once; colours are dimmed by half
+	want := ansiHome +
+		"\x1b[38;2;128;0;0m\x1b[48;2;0;0;0m▀" +
+		"\x1b[48;2;0;0;128m▀" + ansiReset + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("Show() wrote %q, want %q", got, want)
+	}
+}
+
+func TestTerminalDisplayCapabilities(t *testing.T) {
+	if NewTerminalDisplay(&bytes.Buffer{}, 8, 8, false).Capabilities().Color() {
+		t.Error("mono terminal reports colour")
+	}
+	if !NewTerminalDisplay(&bytes.Buffer{}, 8, 8, true).Capabilities().Color() {
+		t.Error("colour terminal reports mono")
+	}
+}
@@ -0,0 +1,301 @@
+package display
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"time"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spireg"
+	"periph.io/x/host/v3"
+)
+
+// MIPI DCS commands shared by the ST77xx and ILI9341 TFT controllers
+const (
+	tftSWRESET = 0x01
+	tftSLPIN   = 0x10
+	tftSLPOUT  = 0x11
+	tftNORON   = 0x13
+	tftINVOFF  = 0x20
+	tftINVON   = 0x21
+	tftDISPOFF = 0x28
+	tftDISPON  = 0x29
+	tftCASET   = 0x2A
+	tftRASET   = 0x2B
+	tftRAMWR   = 0x2C
+	tftMADCTL  = 0x36
+	tftCOLMOD  = 0x3A
+)
+
+// tftSPI is the SPI transport shared by the ST7735, ST7789 and ILI9341
+// drivers: a DC line selecting commands or data, optional reset and
+// backlight pins, and an address window with the panel's RAM offsets
+type tftSPI struct {
+	port      spi.PortCloser
+	conn      spi.Conn
+	dc        gpio.PinOut
+	rst       gpio.PinOut // nil if not configured
+	bl        gpio.PinOut // backlight pin, nil if not configured
+	maxTx     int         // largest transfer the SPI driver accepts, 0 for spiMaxTx
+	colOffset int
+	rowOffset int
+	shown     []byte // RGB565 frame on the panel, nil until written
+	asleep    bool   // sleep sent SLPIN; the next init sends SLPOUT
+}
+
+// openTFTSPI opens the SPI port at speed, or defaultSpeed when speed is 0,
+// and looks up the pins. name prefixes cleanup log messages.
+func openTFTSPI(name, spiBus, dcPin, rstPin, blPin string, speed, defaultSpeed physic.Frequency) (tftSPI, error) {
+	if _, err := host.Init(); err != nil {
+		return tftSPI{}, fmt.Errorf("failed to initialize periph: %w", err)
+	}
+
+	port, err := spireg.Open(spiBus)
+	if err != nil {
+		return tftSPI{}, fmt.Errorf("failed to open SPI bus %s: %w", spiBus, err)
+	}
+	fail := func(err error) (tftSPI, error) {
+		if cerr := port.Close(); cerr != nil {
+			log.Printf("%s: failed to close SPI port during cleanup: %v", name, cerr)
+		}
+		return tftSPI{}, err
+	}
+
+	c, err := port.Connect(cmp.Or(speed, defaultSpeed), spi.Mode0, 8)
+	if err != nil {
+		return fail(fmt.Errorf("failed to connect on SPI bus %s: %w", spiBus, err))
+	}
+	// spidev's buffer size (the bufsiz module parameter) bounds each
+	// transfer; larger buffers mean fewer transfers per frame
+	maxTx := 0
+	if l, ok := c.(conn.Limits); ok {
+		maxTx = l.MaxTxSize()
+	}
+
+	dc := gpioreg.ByName(dcPin)
+	if dc == nil {
+		return fail(fmt.Errorf("DC pin %q not found", dcPin))
+	}
+
+	var rst gpio.PinOut
+	if rstPin != "" {
+		if rst = gpioreg.ByName(rstPin); rst == nil {
+			return fail(fmt.Errorf("RST pin %q not found", rstPin))
+		}
+	}
+
+	var bl gpio.PinOut
+	if blPin != "" {
+		if bl = gpioreg.ByName(blPin); bl == nil {
+			return fail(fmt.Errorf("backlight pin %q not found", blPin))
+		}
+	}
+
+	return tftSPI{port: port, conn: c, dc: dc, rst: rst, bl: bl, maxTx: maxTx}, nil
+}
+
+// closeOnError closes the port after a failed set-up
+func (t *tftSPI) closeOnError(name string) {
+	if cerr := t.port.Close(); cerr != nil {
+		log.Printf("%s: failed to close SPI port during cleanup: %v", name, cerr)
+	}
+}
+
+func (t *tftSPI) hardwareReset() error {
+	if t.rst == nil {
+		return nil
+	}
+	if err := t.rst.Out(gpio.High); err != nil {
+		return fmt.Errorf("RST high failed: %w", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := t.rst.Out(gpio.Low); err != nil {
+		return fmt.Errorf("RST low failed: %w", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := t.rst.Out(gpio.High); err != nil {
+		return fmt.Errorf("RST high failed: %w", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	return nil
+}
+
+// sendCmd asserts DC low and transmits a single command byte.
+func (t *tftSPI) sendCmd(cmd byte) error {
+	if err := t.dc.Out(gpio.Low); err != nil {
+		return err
+	}
+	return t.conn.Tx([]byte{cmd}, nil)
+}
+
+// spiMaxTx is the maximum number of bytes per SPI transaction on sysfs.
+const spiMaxTx = 4096
+
+// sendData asserts DC high and transmits data bytes, chunking as needed
+// to respect the SPI driver's per-transaction limit (spidev's default is
+// 4096 bytes).
+func (t *tftSPI) sendData(data ...byte) error {
+	if err := t.dc.Out(gpio.High); err != nil {
+		return err
+	}
+	limit := t.maxTx
+	if limit <= 0 {
+		limit = spiMaxTx
+	}
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > limit {
+			chunk = data[:limit]
+		}
+		if err := t.conn.Tx(chunk, nil); err != nil {
+			return err
+		}
+		data = data[len(chunk):]
+	}
+	return nil
+}
+
+// sendCmdData sends a command followed by data bytes.
+func (t *tftSPI) sendCmdData(cmd byte, data ...byte) error {
+	if err := t.sendCmd(cmd); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		return t.sendData(data...)
+	}
+	return nil
+}
+
+// setWindow sets the address window for subsequent RAMWR pixel data.
+// Coordinates are 16-bit, big-endian, with the RAM offsets added.
+func (t *tftSPI) setWindow(x0, y0, x1, y1 int) error {
+	cx0, cx1 := x0+t.colOffset, x1+t.colOffset
+	ry0, ry1 := y0+t.rowOffset, y1+t.rowOffset
+	// #nosec G115 -- controller RAM coordinates are below 320
+	if err := t.sendCmdData(tftCASET, byte(cx0>>8), byte(cx0), byte(cx1>>8), byte(cx1)); err != nil {
+		return err
+	}
+	// #nosec G115 -- controller RAM coordinates are below 320
+	if err := t.sendCmdData(tftRASET, byte(ry0>>8), byte(ry0), byte(ry1>>8), byte(ry1)); err != nil {
+		return err
+	}
+	return t.sendCmd(tftRAMWR)
+}
+
+// writeFrame sends the frame as RGB565. After the first frame only the
+// band of rows that changed is sent, which keeps a 320x240 panel from
+// resending 150 KiB when just the clock ticks.
+func (t *tftSPI) writeFrame(fb *Framebuffer) error {
+	frame := fb.RGB565()
+	rowBytes := fb.width * 2
+	y0, y1 := 0, fb.height-1
+	if len(t.shown) == len(frame) {
+		if y0, y1 = changedRows(t.shown, frame, rowBytes); y0 > y1 {
+			return nil
+		}
+	}
+
+	err := t.setWindow(0, y0, fb.width-1, y1)
+	if err == nil {
+		err = t.sendData(frame[y0*rowBytes : (y1+1)*rowBytes]...)
+	}
+	if err != nil {
+		// The panel contents are unknown now, so send the whole frame next time
+		t.shown = nil
+		return err
+	}
+	t.shown = frame
+	return nil
+}
+
+// changedRows returns the first and last rows that differ between old and
+// new, or y0 > y1 if none do
+func changedRows(old, new []byte, rowBytes int) (y0, y1 int) {
+	y0, y1 = len(new)/rowBytes, -1
+	for y := 0; y*rowBytes < len(new); y++ {
+		row := y * rowBytes
+		if string(old[row:row+rowBytes]) != string(new[row:row+rowBytes]) {
+			y0, y1 = min(y0, y), y
+		}
+	}
+	return y0, y1
+}
+
+// setBrightness drives the backlight pin with PWM. It is a no-op when no
+// bl_pin is configured.
+func (t *tftSPI) setBrightness(level uint8) error {
+	if t.bl == nil {
+		return nil
+	}
+	return driveBacklight(t.bl, level)
+}
+
+// sleep switches the backlight and the panel off (DISPOFF) and puts the
+// controller to sleep (SLPIN)
+func (t *tftSPI) sleep() error {
+	if t.bl != nil {
+		if err := t.bl.Out(gpio.Low); err != nil {
+			return fmt.Errorf("failed to turn off backlight: %w", err)
+		}
+	}
+	for _, cmd := range []byte{tftDISPOFF, tftSLPIN} {
+		if err := t.sendCmd(cmd); err != nil {
+			return err
+		}
+	}
+	t.asleep = true
+	return nil
+}
+
+// wake takes the controller out of sleep and switches the panel on, if
+// sleep put it to sleep
+func (t *tftSPI) wake() error {
+	if !t.asleep {
+		return nil
+	}
+	if err := t.sendCmd(tftSLPOUT); err != nil {
+		return err
+	}
+	// The controller accepts no commands for 120ms after SLPOUT
+	time.Sleep(120 * time.Millisecond)
+	if err := t.sendCmd(tftDISPON); err != nil {
+		return err
+	}
+	t.asleep = false
+	return nil
+}
+
+// close puts the panel to sleep and closes the SPI port
+func (t *tftSPI) close(name string) error {
+	if err := t.sleep(); err != nil {
+		log.Printf("%s: failed to put the panel to sleep: %v", name, err)
+	}
+	return t.port.Close()
+}
+
+// tftBacklightFreq is the PWM frequency used to dim the backlight LED,
+// high enough to avoid visible flicker
+const tftBacklightFreq = 1 * physic.KiloHertz
+
+// driveBacklight sets a backlight pin to the given level (0-255). Full on and
+// off use plain GPIO levels; intermediate levels use PWM, falling back to
+// on/off for pins without PWM support.
+func driveBacklight(pin gpio.PinOut, level uint8) error {
+	switch level {
+	case 0:
+		return pin.Out(gpio.Low)
+	case 255:
+		return pin.Out(gpio.High)
+	}
+
+	duty := gpio.DutyMax * gpio.Duty(level) / 255
+	if err := pin.PWM(duty, tftBacklightFreq); err != nil {
+		return pin.Out(gpio.High)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+func newTestTFT(width, height, maxTx int) (*ILI9341Display, *fakeSPIConn) {
+	dc := &fakePin{}
+	c := &fakeSPIConn{dc: dc}
+	return &ILI9341Display{
+		Framebuffer: NewFramebuffer(width, height, ColorModelRGB565),
+		tftSPI:      tftSPI{conn: c, dc: dc, maxTx: maxTx},
+	}, c
+}
+
+// dataBytes returns the number of bytes sent with DC high after the RAMWR
+// command, and the size of the largest transfer
+func dataBytes(writes []spiWrite) (total, largest int) {
+	for i, w := range writes {
+		if w.dc == gpio.Low && bytes.Equal(w.data, []byte{tftRAMWR}) {
+			for _, d := range writes[i+1:] {
+				total += len(d.data)
+				largest = max(largest, len(d.data))
+			}
+			return total, largest
+		}
+	}
+	return 0, 0
+}
+
+func TestTFTWriteFrameChangedRows(t *testing.T) {
+	d, c := newTestTFT(320, 240, 16384)
+
+	// The first frame is sent whole, in transfers as large as the driver allows
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if total, largest := dataBytes(c.writes); total != 320*240*2 || largest != 16384 {
+		t.Errorf("first frame: sent %d bytes in transfers of up to %d, want %d in 16384", total, largest, 320*240*2)
+	}
+
+	// An unchanged frame sends nothing
+	c.writes = nil
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if len(c.writes) != 0 {
+		t.Errorf("expected no writes for an unchanged frame, got %d", len(c.writes))
+	}
+
+	// Changing rows 10 and 12 sends rows 10-12
+	c.writes = nil
+	if err := d.DrawPixel(5, 10, true); err != nil {
+		t.Fatalf("DrawPixel() failed: %v", err)
+	}
+	if err := d.DrawPixel(300, 12, true); err != nil {
+		t.Fatalf("DrawPixel() failed: %v", err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if want := []byte{0x00, 10, 0x00, 12}; !bytes.Equal(c.writes[3].data, want) {
+		t.Errorf("row window: got % X, want % X", c.writes[3].data, want)
+	}
+	if total, _ := dataBytes(c.writes); total != 3*320*2 {
+		t.Errorf("expected 3 rows of pixels, got %d bytes", total)
+	}
+
+	// Forgetting the panel contents, as Init does, sends the whole frame
+	c.writes = nil
+	d.tftSPI.shown = nil
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if total, _ := dataBytes(c.writes); total != 320*240*2 {
+		t.Errorf("expected a full frame after the panel state was reset, got %d bytes", total)
+	}
+}
+
+func TestTFTSendDataDefaultLimit(t *testing.T) {
+	d, c := newTestTFT(240, 320, 0)
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if _, largest := dataBytes(c.writes); largest != spiMaxTx {
+		t.Errorf("expected transfers of %d bytes without a driver limit, got %d", spiMaxTx, largest)
+	}
+}
+
+// commands returns the command bytes among writes
+func commands(writes []spiWrite) []byte {
+	var cmds []byte
+	for _, w := range writes {
+		if w.dc == gpio.Low {
+			cmds = append(cmds, w.data...)
+		}
+	}
+	return cmds
+}
+
+func TestTFTSleepWake(t *testing.T) {
+	d, c := newTestTFT(32, 16, 0)
+	if err := d.Sleep(); err != nil {
+		t.Fatalf("Sleep() failed: %v", err)
+	}
+	if got := commands(c.writes); !bytes.Equal(got, []byte{tftDISPOFF, tftSLPIN}) {
+		t.Errorf("Sleep sent %x, want DISPOFF and SLPIN", got)
+	}
+
+	// Init wakes the panel before redrawing it
+	c.writes = nil
+	if err := d.Init(); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	if got := commands(c.writes); len(got) < 2 || got[0] != tftSLPOUT || got[1] != tftDISPON {
+		t.Errorf("Init after Sleep sent %x, want SLPOUT and DISPON first", got)
+	}
+
+	// Once awake, Init does not repeat them
+	c.writes = nil
+	if err := d.Init(); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	if got := commands(c.writes); bytes.IndexByte(got, tftSLPOUT) >= 0 {
+		t.Errorf("Init when awake sent %x, want no SLPOUT", got)
+	}
+}
+
+func TestTFTWake(t *testing.T) {
+	d, c := newTestTFT(32, 16, 0)
+	if err := d.Sleep(); err != nil {
+		t.Fatalf("Sleep() failed: %v", err)
+	}
+	c.writes = nil
+	if err := d.Wake(); err != nil {
+		t.Fatalf("Wake() failed: %v", err)
+	}
+	if got := commands(c.writes); !bytes.Equal(got, []byte{tftSLPOUT, tftDISPON}) {
+		t.Errorf("Wake sent %x, want SLPOUT and DISPON", got)
+	}
+
+	// Waking an awake panel sends nothing
+	c.writes = nil
+	if err := d.Wake(); err != nil {
+		t.Fatalf("Wake() failed: %v", err)
+	}
+	if len(c.writes) != 0 {
+		t.Errorf("Wake when awake sent %x", commands(c.writes))
+	}
+}
@@ -2,15 +2,13 @@ package display
 
 import (
 	"fmt"
-	"image"
-	"image/color"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"periph.io/x/conn/v3/i2c"
-	"periph.io/x/conn/v3/i2c/i2creg"
-	"periph.io/x/host/v3"
+	"periph.io/x/conn/v3/physic"
 )
 
 // UCTRONICS MCU I2C bridge protocol constants.
@@ -39,23 +37,26 @@ const (
 )
 
 // UCTRONICSDisplay implements Display for UCTRONICS I2C-bridged ST7735 displays.
+//
+// The bridge firmware only accepts pixel writes, burst control, sync and the
+// forwarded ST7735 window commands; the backlight is wired to the supply and
+// there is no register to dim it. Brightness is therefore applied to the
+// pixel colours, as on framebuffer displays.
 type UCTRONICSDisplay struct {
-	bus    i2c.BusCloser
-	addr   uint16
-	img    *image.NRGBA
-	width  int
-	height int
-}
+	*Framebuffer
+	bus  i2c.BusCloser
+	addr uint16
 
-// NewUCTRONICSDisplay creates a new UCTRONICS display driver.
-func NewUCTRONICSDisplay(i2cBus, i2cAddr string, width, height int) (*UCTRONICSDisplay, error) {
-	if _, err := host.Init(); err != nil {
-		return nil, fmt.Errorf("failed to initialize periph: %w", err)
-	}
+	mu         sync.Mutex // protects brightness
+	brightness uint8
+}
 
-	bus, err := i2creg.Open(i2cBus)
+// NewUCTRONICSDisplay creates a new UCTRONICS display driver. speed is the
+// I2C clock, 0 to keep the bus's current speed.
+func NewUCTRONICSDisplay(i2cBus, i2cAddr string, speed physic.Frequency, width, height int) (*UCTRONICSDisplay, error) {
+	bus, err := openI2C("uctronics", i2cBus, speed)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open I2C bus %s: %w", i2cBus, err)
+		return nil, err
 	}
 
 	addr, err := parseI2CAddr(i2cAddr)
@@ -68,11 +69,10 @@ func NewUCTRONICSDisplay(i2cBus, i2cAddr string, width, height int) (*UCTRONICSD
 	}
 
 	return &UCTRONICSDisplay{
-		bus:    bus,
-		addr:   addr,
-		img:    image.NewNRGBA(image.Rect(0, 0, width, height)),
-		width:  width,
-		height: height,
+		Framebuffer: NewFramebuffer(width, height, ColorModelRGB565),
+		bus:         bus,
+		addr:        addr,
+		brightness:  255,
 	}, nil
 }
 
@@ -144,115 +144,59 @@ func (d *UCTRONICSDisplay) Init() error {
 	return d.Show()
 }
 
-// Clear fills the image buffer with black without flushing to the display.
-func (d *UCTRONICSDisplay) Clear() error {
-	bounds := d.img.Bounds()
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			d.img.SetNRGBA(x, y, color.NRGBA{A: 255})
-		}
-	}
-	return nil
-}
+// Show flushes the frame to the display as RGB565 via I2C burst transfer,
+// dimmed to the brightness level.
+func (d *UCTRONICSDisplay) Show() error {
+	d.mu.Lock()
+	level := d.brightness
+	d.mu.Unlock()
 
-// DrawPixel sets a single pixel (white if on, black if off).
-func (d *UCTRONICSDisplay) DrawPixel(x, y int, on bool) error {
-	if x < 0 || x >= d.width || y < 0 || y >= d.height {
-		return nil
-	}
-	if on {
-		d.img.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
-	} else {
-		d.img.SetNRGBA(x, y, color.NRGBA{A: 255})
+	if err := d.setAddressWindow(0, 0, byte(d.width-1), byte(d.height-1)); err != nil { // #nosec G115 -- display dimensions bounded by ≤255
+		return err
 	}
-	return nil
+	frame := d.RGB565()
+	dimRGB565(frame, level)
+	return d.burstTransfer(frame)
 }
 
-// DrawLine draws a horizontal line.
-func (d *UCTRONICSDisplay) DrawLine(x, y, width int) error {
-	for i := 0; i < width && x+i < d.width; i++ {
-		if x+i >= 0 && y >= 0 && y < d.height {
-			d.img.SetNRGBA(x+i, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
-		}
-	}
-	return nil
+// Close closes the I2C bus.
+func (d *UCTRONICSDisplay) Close() error {
+	return d.bus.Close()
 }
 
-// DrawText draws text as simple character outlines.
-func (d *UCTRONICSDisplay) DrawText(x, y int, text string, size int) error {
-	charWidth := size / 2
-	for i := range text {
-		startX := x + i*charWidth
-		if startX >= d.width {
-			break
-		}
-		if err := d.DrawRect(startX, y, charWidth-1, size, false); err != nil {
-			return err
-		}
-	}
+// SetBrightness dims the frame in software, down to black at 0; the MCU
+// has no backlight control. Takes effect on the next Show.
+func (d *UCTRONICSDisplay) SetBrightness(level uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.brightness = level
 	return nil
 }
 
-// DrawRect draws a rectangle outline or filled rectangle.
-func (d *UCTRONICSDisplay) DrawRect(x, y, width, height int, fill bool) error {
-	drawRectNRGBA(d.img, x, y, width, height, d.width, d.height, fill)
+// Sleep is a no-op: the MCU has no power-down command
+func (d *UCTRONICSDisplay) Sleep() error {
 	return nil
 }
 
-// DrawImage draws an image at the specified position, preserving source colours.
-func (d *UCTRONICSDisplay) DrawImage(x, y int, img image.Image) error {
-	drawImageNRGBA(d.img, x, y, d.width, d.height, img)
+// Wake is a no-op
+func (d *UCTRONICSDisplay) Wake() error {
 	return nil
 }
 
-// Show flushes the NRGBA buffer to the display as RGB565 via I2C burst transfer.
-func (d *UCTRONICSDisplay) Show() error {
-	if err := d.setAddressWindow(0, 0, byte(d.width-1), byte(d.height-1)); err != nil { // #nosec G115 -- display dimensions bounded by ≤255
-		return err
+// dimRGB565 scales each channel of a big-endian RGB565 frame by level/255
+// in place
+func dimRGB565(frame []byte, level uint8) {
+	if level == 255 {
+		return
 	}
-
-	buf := make([]byte, d.width*d.height*2)
-	idx := 0
-	for y := 0; y < d.height; y++ {
-		for x := 0; x < d.width; x++ {
-			c := d.img.NRGBAAt(x, y)
-			rgb565 := nrgbaToRGB565(c)
-			buf[idx] = byte(rgb565 >> 8) // #nosec G115 -- uint16 to byte truncation is intentional
-			buf[idx+1] = byte(rgb565)    // #nosec G115 -- uint16 to byte truncation is intentional
-			idx += 2
-		}
+	l := uint32(level)
+	for i := 0; i+1 < len(frame); i += 2 {
+		v := uint32(frame[i])<<8 | uint32(frame[i+1])
+		r := (v >> 11) * l / 255
+		g := (v >> 5 & 0x3F) * l / 255
+		b := (v & 0x1F) * l / 255
+		v = r<<11 | g<<5 | b
+		frame[i] = byte(v >> 8) // #nosec G115 -- uint16 to byte truncation is intentional
+		frame[i+1] = byte(v)    // #nosec G115 -- uint16 to byte truncation is intentional
 	}
-
-	return d.burstTransfer(buf)
-}
-
-// Close closes the I2C bus.
-func (d *UCTRONICSDisplay) Close() error {
-	return d.bus.Close()
-}
-
-// GetBounds returns the display dimensions.
-func (d *UCTRONICSDisplay) GetBounds() image.Rectangle {
-	return d.img.Bounds()
-}
-
-// GetBuffer returns the current frame as RGB565-encoded bytes.
-func (d *UCTRONICSDisplay) GetBuffer() []byte {
-	buf := make([]byte, d.width*d.height*2)
-	idx := 0
-	for y := 0; y < d.height; y++ {
-		for x := 0; x < d.width; x++ {
-			c := d.img.NRGBAAt(x, y)
-			rgb565 := nrgbaToRGB565(c)
-			buf[idx] = byte(rgb565 >> 8) // #nosec G115 -- uint16 to byte truncation is intentional
-			buf[idx+1] = byte(rgb565)    // #nosec G115 -- uint16 to byte truncation is intentional
-			idx += 2
-		}
-	}
-	return buf
-}
-
-// SetBrightness is a no-op (UCTRONICS MCU does not expose brightness control).
-func (d *UCTRONICSDisplay) SetBrightness(_ uint8) error {
-	return nil
 }
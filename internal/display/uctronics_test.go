@@ -0,0 +1,56 @@
+package display
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+func TestDimRGB565(t *testing.T) {
+	white := []byte{0xFF, 0xFF}
+	dimRGB565(white, 255)
+	if !bytes.Equal(white, []byte{0xFF, 0xFF}) {
+		t.Errorf("full brightness should leave the frame alone, got % X", white)
+	}
+
+	frame := []byte{0xFF, 0xFF, 0xF8, 0x00}
+	dimRGB565(frame, 0)
+	if !bytes.Equal(frame, []byte{0, 0, 0, 0}) {
+		t.Errorf("brightness 0 should blank the frame, got % X", frame)
+	}
+
+	// Half of full red (31) is 15, of full green (63) is 31
+	frame = []byte{0xFF, 0xE0}
+	dimRGB565(frame, 128)
+	if want := []byte{15<<3 | 31>>3, (31 & 0x07) << 5}; !bytes.Equal(frame, want) {
+		t.Errorf("half brightness: got % X, want % X", frame, want)
+	}
+}
+
+func TestUCTRONICSSetBrightness(t *testing.T) {
+	bus := &fakeBus{devices: map[uint16]byte{uctronicsDefaultAddr: 0}}
+	d := &UCTRONICSDisplay{
+		Framebuffer: NewFramebuffer(8, 2, ColorModelRGB565),
+		bus:         bus,
+		addr:        uctronicsDefaultAddr,
+		brightness:  255,
+	}
+	if err := d.FillRectColor(0, 0, 8, 2, color.White); err != nil {
+		t.Fatalf("FillRectColor() failed: %v", err)
+	}
+	if err := d.SetBrightness(0); err != nil {
+		t.Fatalf("SetBrightness() failed: %v", err)
+	}
+	if err := d.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	var frame []byte
+	for _, w := range bus.writes {
+		if len(w.data) == 8*2*2 {
+			frame = w.data
+		}
+	}
+	if !bytes.Equal(frame, make([]byte, 8*2*2)) {
+		t.Errorf("expected a black frame at brightness 0, got % X", frame)
+	}
+}
@@ -0,0 +1,86 @@
+package display
+
+import (
+	"image/color"
+	"sync"
+	"time"
+)
+
+// WatchedDisplay wraps a display and records when a frame last reached it,
+// for the render loop watchdog. It sits below any QueuedDisplay, so a flush
+// blocked on a wedged bus shows up even though queued Show calls return at
+// once. A display put to sleep is not expected to flush, so while it sleeps
+// it counts as flushing.
+type WatchedDisplay struct {
+	Display
+
+	mu        sync.Mutex
+	lastFlush time.Time
+	asleep    bool
+	now       func() time.Time
+}
+
+// NewWatchedDisplay wraps disp, counting from now as its last flush
+func NewWatchedDisplay(disp Display) *WatchedDisplay {
+	return &WatchedDisplay{Display: disp, lastFlush: time.Now(), now: time.Now}
+}
+
+// LastFlush returns when Show last succeeded, or the current time while
+// the display sleeps
+func (w *WatchedDisplay) LastFlush() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.asleep {
+		return w.now()
+	}
+	return w.lastFlush
+}
+
+// Show flushes the wrapped display, recording the time when it succeeds
+func (w *WatchedDisplay) Show() error {
+	err := w.Display.Show()
+	if err == nil {
+		w.mu.Lock()
+		w.lastFlush = w.now()
+		w.mu.Unlock()
+	}
+	return err
+}
+
+// Sleep puts the wrapped display to sleep, pausing the watch
+func (w *WatchedDisplay) Sleep() error {
+	err := w.Display.Sleep()
+	w.mu.Lock()
+	w.asleep = true
+	w.mu.Unlock()
+	return err
+}
+
+// Wake wakes the wrapped display, restarting the watch from now
+func (w *WatchedDisplay) Wake() error {
+	w.mu.Lock()
+	w.asleep = false
+	w.lastFlush = w.now()
+	w.mu.Unlock()
+	return w.Display.Wake()
+}
+
+// DrawPixelColor draws on the wrapped display
+func (w *WatchedDisplay) DrawPixelColor(x, y int, c color.Color) error {
+	return AsColorDisplay(w.Display).DrawPixelColor(x, y, c)
+}
+
+// FillRectColor draws on the wrapped display
+func (w *WatchedDisplay) FillRectColor(x, y, width, height int, c color.Color) error {
+	return AsColorDisplay(w.Display).FillRectColor(x, y, width, height, c)
+}
+
+// Capabilities reports the wrapped display's capabilities
+func (w *WatchedDisplay) Capabilities() Capabilities {
+	return AsColorDisplay(w.Display).Capabilities()
+}
+
+// WriteLines sets the wrapped display's text
+func (w *WatchedDisplay) WriteLines(lines []string) error {
+	return WriteLines(w.Display, lines)
+}
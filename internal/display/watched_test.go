@@ -0,0 +1,50 @@
+package display
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchedDisplay(t *testing.T) {
+	inner := NewMockDisplay(128, 64)
+	w := NewWatchedDisplay(inner)
+	now := time.Unix(1000, 0)
+	w.now = func() time.Time { return now }
+
+	now = now.Add(time.Second)
+	if err := w.Show(); err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+	if got := w.LastFlush(); !got.Equal(now) {
+		t.Errorf("LastFlush() = %v, want %v", got, now)
+	}
+	flushed := now
+
+	// A failed flush does not count
+	inner.SetError(true, "remote I/O error")
+	now = now.Add(time.Second)
+	if err := w.Show(); err == nil {
+		t.Fatal("expected Show to fail")
+	}
+	if got := w.LastFlush(); !got.Equal(flushed) {
+		t.Errorf("LastFlush() = %v after a failed flush, want %v", got, flushed)
+	}
+	inner.SetError(false, "")
+
+	// Asleep, the display counts as flushing
+	if err := w.Sleep(); err != nil {
+		t.Fatalf("Sleep() failed: %v", err)
+	}
+	now = now.Add(time.Hour)
+	if got := w.LastFlush(); !got.Equal(now) {
+		t.Errorf("LastFlush() = %v while asleep, want %v", got, now)
+	}
+	if err := w.Wake(); err != nil {
+		t.Fatalf("Wake() failed: %v", err)
+	}
+	woke := now
+	now = now.Add(time.Minute)
+	if got := w.LastFlush(); !got.Equal(woke) {
+		t.Errorf("LastFlush() = %v after waking, want %v", got, woke)
+	}
+}
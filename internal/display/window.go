@@ -0,0 +1,271 @@
+//go:build preview
+
+package display
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// putImageHeader is the size of a PutImage request without pixel data
+const putImageHeader = 24
+
+// WindowDisplay shows the frame in a desktop window, scaled up so each
+// pixel is easy to see. It speaks the X11 protocol directly, so it works on
+// X11 and XWayland desktops and over ssh -X, without cgo or system
+// libraries. Closing the window stops the daemon like Ctrl+C.
+type WindowDisplay struct {
+	*Framebuffer
+	scale int
+
+	conn         *xgb.Conn
+	window       xproto.Window
+	gc           xproto.Gcontext
+	depth        byte
+	msbOrder     bool // server expects XRGB rather than BGRX byte order
+	maxRows      int  // scaled rows per PutImage request
+	deleteWindow xproto.Atom
+
+	mu         sync.Mutex // protects brightness and pixels, serializes drawing
+	brightness uint8
+	pixels     []byte // last frame sent, redrawn on expose
+}
+
+// newWindow creates a window display for the factory
+func newWindow(width, height, scale int, colour bool) (Display, error) {
+	return NewWindowDisplay(width, height, scale, colour)
+}
+
+// NewWindowDisplay connects to the X server named by $DISPLAY and creates a
+// window for a width x height frame magnified by scale. colour selects an
+// RGB565 frame instead of a mono one.
+func NewWindowDisplay(width, height, scale int, colour bool) (*WindowDisplay, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to X server (is DISPLAY set?): %w", err)
+	}
+	d, err := newWindowDisplay(conn, width, height, scale, colour)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+func newWindowDisplay(conn *xgb.Conn, width, height, scale int, colour bool) (*WindowDisplay, error) {
+	setup := xproto.Setup(conn)
+	screen := setup.DefaultScreen(conn)
+	if !hasPixmapFormat(setup, screen.RootDepth, 32) {
+		return nil, fmt.Errorf("unsupported X visual: depth %d without 32 bits per pixel", screen.RootDepth)
+	}
+
+	model := ColorModelMono
+	if colour {
+		model = ColorModelRGB565
+	}
+	d := &WindowDisplay{
+		Framebuffer: NewFramebuffer(width, height, model),
+		scale:       scale,
+		conn:        conn,
+		depth:       screen.RootDepth,
+		msbOrder:    setup.ImageByteOrder == xproto.ImageOrderMSBFirst,
+		brightness:  255,
+	}
+	rowBytes := width * scale * 4
+	d.maxRows = max((int(setup.MaximumRequestLength)*4-putImageHeader)/rowBytes, 1)
+
+	var err error
+	if d.window, err = xproto.NewWindowId(conn); err != nil {
+		return nil, err
+	}
+	w, h := uint16(width*scale), uint16(height*scale) // #nosec G115 -- dimensions are validated to fit
+	if err := xproto.CreateWindowChecked(conn, screen.RootDepth, d.window, screen.Root,
+		0, 0, w, h, 0, xproto.WindowClassInputOutput, screen.RootVisual,
+		xproto.CwBackPixel|xproto.CwEventMask,
+		[]uint32{screen.BlackPixel, xproto.EventMaskExposure}).Check(); err != nil {
+		return nil, fmt.Errorf("failed to create window: %w", err)
+	}
+
+	if d.gc, err = xproto.NewGcontextId(conn); err != nil {
+		return nil, err
+	}
+	if err := xproto.CreateGCChecked(conn, d.gc, xproto.Drawable(d.window), 0, nil).Check(); err != nil {
+		return nil, fmt.Errorf("failed to create graphics context: %w", err)
+	}
+
+	title := fmt.Sprintf("i2c-display %dx%d", width, height)
+	xproto.ChangeProperty(conn, xproto.PropModeReplace, d.window, xproto.AtomWmName,
+		xproto.AtomString, 8, uint32(len(title)), []byte(title)) // #nosec G115 -- short title
+
+	// Keep the window at its exact size so the scale stays integral
+	hints := make([]byte, 18*4)
+	xgb.Put32(hints[0:], 16|32) // PMinSize | PMaxSize
+	for i, v := range []uint16{w, h, w, h} {
+		xgb.Put32(hints[(5+i)*4:], uint32(v))
+	}
+	xproto.ChangeProperty(conn, xproto.PropModeReplace, d.window, xproto.AtomWmNormalHints,
+		xproto.AtomWmSizeHints, 32, 18, hints)
+
+	// Ask the window manager to tell us about the close button instead of
+	// dropping the connection
+	protocols, err := internAtom(conn, "WM_PROTOCOLS")
+	if err != nil {
+		return nil, err
+	}
+	if d.deleteWindow, err = internAtom(conn, "WM_DELETE_WINDOW"); err != nil {
+		return nil, err
+	}
+	data := make([]byte, 4)
+	xgb.Put32(data, uint32(d.deleteWindow))
+	xproto.ChangeProperty(conn, xproto.PropModeReplace, d.window, protocols, xproto.AtomAtom, 32, 1, data)
+
+	return d, nil
+}
+
+// hasPixmapFormat reports whether the server stores depth with bpp bits
+// per pixel
+func hasPixmapFormat(setup *xproto.SetupInfo, depth, bpp byte) bool {
+	for _, f := range setup.PixmapFormats {
+		if f.Depth == depth && f.BitsPerPixel == bpp {
+			return true
+		}
+	}
+	return false
+}
+
+func internAtom(conn *xgb.Conn, name string) (xproto.Atom, error) {
+	reply, err := xproto.InternAtom(conn, false, uint16(len(name)), name).Reply() // #nosec G115 -- short atom name
+	if err != nil {
+		return 0, fmt.Errorf("failed to intern %s: %w", name, err)
+	}
+	return reply.Atom, nil
+}
+
+// Init shows the window and starts handling its events
+func (d *WindowDisplay) Init() error {
+	if err := xproto.MapWindowChecked(d.conn, d.window).Check(); err != nil {
+		return fmt.Errorf("failed to map window: %w", err)
+	}
+	go d.handleEvents()
+	return nil
+}
+
+// handleEvents redraws the window when it is exposed and stops the daemon
+// when it is closed
+func (d *WindowDisplay) handleEvents() {
+	for {
+		ev, err := d.conn.WaitForEvent()
+		if ev == nil && err == nil {
+			return // connection closed
+		}
+		switch e := ev.(type) {
+		case xproto.ExposeEvent:
+			if e.Count == 0 {
+				d.mu.Lock()
+				d.put()
+				d.mu.Unlock()
+			}
+		case xproto.ClientMessageEvent:
+			if e.Format == 32 && xproto.Atom(e.Data.Data32[0]) == d.deleteWindow {
+				_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+			}
+		}
+	}
+}
+
+// Show draws the frame in the window
+func (d *WindowDisplay) Show() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pixels = windowPixels(d.img, d.scale, d.brightness, d.model == ColorModelMono, d.msbOrder)
+	return d.put()
+}
+
+// put sends the last frame to the server in strips that fit a request.
+// Callers hold mu.
+func (d *WindowDisplay) put() error {
+	if d.pixels == nil {
+		return nil
+	}
+	w := d.width * d.scale
+	h := d.height * d.scale
+	rowBytes := w * 4
+	for y := 0; y < h; y += d.maxRows {
+		rows := min(d.maxRows, h-y)
+		xproto.PutImage(d.conn, xproto.ImageFormatZPixmap, xproto.Drawable(d.window), d.gc,
+			uint16(w), uint16(rows), 0, int16(y), 0, d.depth, // #nosec G115 -- dimensions are validated to fit
+			d.pixels[y*rowBytes:(y+rows)*rowBytes])
+	}
+	// Round trip so errors such as a closed connection surface here
+	if _, err := xproto.GetInputFocus(d.conn).Reply(); err != nil {
+		return fmt.Errorf("failed to draw window: %w", err)
+	}
+	return nil
+}
+
+// Close destroys the window and disconnects from the X server
+func (d *WindowDisplay) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err := xproto.DestroyWindowChecked(d.conn, d.window).Check()
+	d.conn.Close()
+	return err
+}
+
+// SetBrightness scales colours by level; on mono frames 0 blanks the
+// window and any other level shows it normally. Takes effect on the next
+// Show.
+func (d *WindowDisplay) SetBrightness(level uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.brightness = level
+	return nil
+}
+
+// Sleep is a no-op; SetBrightness(0) blanks the window
+func (d *WindowDisplay) Sleep() error {
+	return nil
+}
+
+// Wake is a no-op
+func (d *WindowDisplay) Wake() error {
+	return nil
+}
+
+// windowPixels converts img to 32-bit pixels magnified by scale, in BGRX
+// order or XRGB when msb is set
+func windowPixels(img *image.NRGBA, scale int, brightness uint8, mono, msb bool) []byte {
+	b := img.Bounds()
+	w := b.Dx() * scale
+	out := make([]byte, w*b.Dy()*scale*4)
+	for y := 0; y < b.Dy(); y++ {
+		row := out[y*scale*w*4 : (y*scale+1)*w*4]
+		for x := 0; x < b.Dx(); x++ {
+			c := img.NRGBAAt(b.Min.X+x, b.Min.Y+y)
+			if mono && brightness == 0 {
+				c = fbBlack
+			} else if !mono {
+				c = dimColor(c, brightness)
+			}
+			px := [4]byte{c.B, c.G, c.R, 0}
+			if msb {
+				px = [4]byte{0, c.R, c.G, c.B}
+			}
+			for sx := 0; sx < scale; sx++ {
+				copy(row[(x*scale+sx)*4:], px[:])
+			}
+		}
+		// The remaining rows of the magnified pixel repeat the first
+		for sy := 1; sy < scale; sy++ {
+			copy(out[(y*scale+sy)*w*4:], row)
+		}
+	}
+	return out
+}
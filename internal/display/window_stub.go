@@ -0,0 +1,11 @@
+//go:build !preview
+
+package display
+
+import "errors"
+
+// newWindow reports that window previews are not built in; they need a
+// build with -tags preview
+func newWindow(_, _, _ int, _ bool) (Display, error) {
+	return nil, errors.New("window display support is not built in; rebuild with -tags preview (make build-preview)")
+}
@@ -0,0 +1,17 @@
+//go:build !preview
+
+package display
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+func TestNewDisplayWindowNotBuilt(t *testing.T) {
+	_, err := NewDisplay(&config.DisplayConfig{Type: "window", Width: 128, Height: 64})
+	if err == nil || !strings.Contains(err.Error(), "-tags preview") {
+		t.Errorf("NewDisplay(window) error = %v, want a hint to rebuild with -tags preview", err)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build preview
+
+package display
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestWindowPixels(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+	img.SetNRGBA(1, 0, fbBlack)
+
+	got := windowPixels(img, 2, 255, false, false)
+	red := []byte{50, 100, 200, 0}
+	black := []byte{0, 0, 0, 0}
+	// Each source pixel becomes a 2x2 block of BGRX pixels
+	row := bytes.Join([][]byte{red, red, black, black}, nil)
+	if want := append(append([]byte{}, row...), row...); !bytes.Equal(got, want) {
+		t.Errorf("windowPixels() = %v, want %v", got, want)
+	}
+
+	if got := windowPixels(img, 1, 255, false, true)[:4]; !bytes.Equal(got, []byte{0, 200, 100, 50}) {
+		t.Errorf("MSB first pixel = %v, want XRGB", got)
+	}
+	if got := windowPixels(img, 1, 128, false, false)[:4]; !bytes.Equal(got, []byte{25, 50, 100, 0}) {
+		t.Errorf("dimmed pixel = %v, want half brightness", got)
+	}
+	if got := windowPixels(img, 1, 0, true, false)[:4]; !bytes.Equal(got, black) {
+		t.Errorf("mono pixel at brightness 0 = %v, want black", got)
+	}
+}
@@ -0,0 +1,139 @@
+package fan
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/host/v3"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// pwmFreq is the PWM frequency for variable fan speed; 25 kHz is the PC fan
+// standard and above hearing, so transistor-switched fans do not whine
+const pwmFreq = 25 * physic.KiloHertz
+
+// Output drives the fan at a speed in percent
+type Output interface {
+	SetDuty(percent int) error
+}
+
+// gpioOutput switches a fan through a transistor on a GPIO pin
+type gpioOutput struct {
+	pin gpio.PinOut
+}
+
+// OpenGPIO returns an output driving the named GPIO pin
+func OpenGPIO(name string) (Output, error) {
+	if _, err := host.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize periph: %w", err)
+	}
+	pin := gpioreg.ByName(name)
+	if pin == nil {
+		return nil, fmt.Errorf("fan pin %q not found", name)
+	}
+	return gpioOutput{pin: pin}, nil
+}
+
+// SetDuty switches the pin fully off or on at the ends of the range and uses
+// PWM in between, falling back to full speed on pins without PWM support
+func (o gpioOutput) SetDuty(percent int) error {
+	switch {
+	case percent <= 0:
+		return o.pin.Out(gpio.Low)
+	case percent >= 100:
+		return o.pin.Out(gpio.High)
+	}
+	duty := gpio.DutyMax * gpio.Duty(percent) / 100
+	if err := o.pin.PWM(duty, pwmFreq); err != nil {
+		return o.pin.Out(gpio.High)
+	}
+	return nil
+}
+
+// Controller sets the fan speed from CPU temperature samples along a curve.
+// The rotation manager feeds it every refresh, so it needs no goroutine of
+// its own.
+type Controller struct {
+	curve      []config.FanPoint
+	hysteresis float64
+	out        Output
+	log        *logger.Logger
+
+	mu      sync.Mutex
+	duty    int  // speed last applied
+	applied bool // false until the first successful SetDuty
+}
+
+// New creates a fan controller from config. Config must already be
+// validated.
+func New(cfg config.FanConfig, out Output, log *logger.Logger) *Controller {
+	return &Controller{
+		curve:      cfg.Curve,
+		hysteresis: cfg.Hysteresis,
+		out:        out,
+		log:        log,
+	}
+}
+
+// Observe records a temperature sample, adjusts the fan and returns its
+// speed in percent. The fan speeds up as soon as the curve says so but only
+// slows down once the temperature is Hysteresis below the point, so it does
+// not hunt around a threshold.
+func (c *Controller) Observe(temp float64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	duty := DutyFor(c.curve, temp)
+	if c.applied && duty < c.duty {
+		duty = min(c.duty, DutyFor(c.curve, temp+c.hysteresis))
+	}
+	if c.applied && duty == c.duty {
+		return c.duty
+	}
+
+	if err := c.out.SetDuty(duty); err != nil {
+		c.log.With().Int("duty", duty).Err(err).Logger().Warn("Failed to set fan speed")
+		return c.duty
+	}
+	c.log.With().Float64("temp", temp).Int("duty", duty).Logger().Debug("Fan speed changed")
+	c.duty, c.applied = duty, true
+	return duty
+}
+
+// Duty returns the speed last applied
+func (c *Controller) Duty() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.duty
+}
+
+// Close runs the fan at full speed, so the board stays cooled while the
+// service is not running
+func (c *Controller) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.duty = 100
+	return c.out.SetDuty(100)
+}
+
+// DutyFor returns the fan speed for temp: off below the first point,
+// interpolated between points and the last point's speed above it
+func DutyFor(curve []config.FanPoint, temp float64) int {
+	if len(curve) == 0 || temp < curve[0].Temp {
+		return 0
+	}
+	for i := 1; i < len(curve); i++ {
+		lo, hi := curve[i-1], curve[i]
+		if temp < hi.Temp {
+			frac := (temp - lo.Temp) / (hi.Temp - lo.Temp)
+			return lo.Duty + int(math.Round(frac*float64(hi.Duty-lo.Duty)))
+		}
+	}
+	return curve[len(curve)-1].Duty
+}
@@ -0,0 +1,107 @@
+package fan
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// fakeOutput records the speeds set
+type fakeOutput struct {
+	duties []int
+	err    error
+}
+
+func (o *fakeOutput) SetDuty(percent int) error {
+	if o.err != nil {
+		return o.err
+	}
+	o.duties = append(o.duties, percent)
+	return nil
+}
+
+var testCurve = []config.FanPoint{{Temp: 50, Duty: 30}, {Temp: 60, Duty: 60}, {Temp: 70, Duty: 100}}
+
+func TestDutyFor(t *testing.T) {
+	tests := []struct {
+		temp float64
+		want int
+	}{
+		{40, 0},
+		{50, 30},
+		{55, 45},
+		{60, 60},
+		{65, 80},
+		{70, 100},
+		{90, 100},
+	}
+	for _, tt := range tests {
+		if got := DutyFor(testCurve, tt.temp); got != tt.want {
+			t.Errorf("DutyFor(%g) = %d, want %d", tt.temp, got, tt.want)
+		}
+	}
+	if got := DutyFor(nil, 90); got != 0 {
+		t.Errorf("DutyFor with no curve = %d, want 0", got)
+	}
+}
+
+func TestControllerHysteresis(t *testing.T) {
+	out := &fakeOutput{}
+	c := New(config.FanConfig{Curve: testCurve, Hysteresis: 3}, out, logger.NewDefault())
+
+	steps := []struct {
+		temp float64
+		want int
+	}{
+		{45, 0},    // off below the curve
+		{60, 60},   // speeds up at once
+		{58, 60},   // within the hysteresis band: holds
+		{56, 57},   // slows to the speed 3 degrees higher up the curve
+		{49, 36},   // below the first point, but 52 is not
+		{40, 0},    // well below: off
+		{100, 100}, // full speed
+	}
+	for i, s := range steps {
+		if got := c.Observe(s.temp); got != s.want {
+			t.Errorf("step %d: Observe(%g) = %d, want %d", i, s.temp, got, s.want)
+		}
+	}
+	if c.Duty() != 100 {
+		t.Errorf("Duty() = %d, want 100", c.Duty())
+	}
+}
+
+func TestControllerOnlyWritesChanges(t *testing.T) {
+	out := &fakeOutput{}
+	c := New(config.FanConfig{Curve: testCurve}, out, logger.NewDefault())
+	c.Observe(40)
+	c.Observe(41)
+	c.Observe(65)
+	c.Observe(65)
+	if len(out.duties) != 2 {
+		t.Errorf("expected the off state and one change written, got %v", out.duties)
+	}
+}
+
+func TestControllerFailures(t *testing.T) {
+	out := &fakeOutput{err: errors.New("gpio busy")}
+	c := New(config.FanConfig{Curve: testCurve}, out, logger.NewDefault())
+	if got := c.Observe(65); got != 0 {
+		t.Errorf("expected the previous speed after a failed write, got %d", got)
+	}
+
+	// The write is retried on the next sample
+	out.err = nil
+	if got := c.Observe(65); got != 80 {
+		t.Errorf("Observe() = %d after recovery, want 80", got)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if out.duties[len(out.duties)-1] != 100 {
+		t.Error("expected Close to leave the fan at full speed")
+	}
+}
@@ -17,20 +17,31 @@ const (
 	StatusUnhealthy Status = "unhealthy"
 )
 
+// Standard component names registered by the display service
+const (
+	ComponentDisplay   = "display"
+	ComponentCollector = "collector"
+	ComponentRenderer  = "renderer"
+	ComponentRotation  = "rotation"
+)
+
 // Component represents a system component with health status
 type Component struct {
 	Name         string    `json:"name"`
 	Status       Status    `json:"status"`
 	Message      string    `json:"message,omitempty"`
 	LastCheck    time.Time `json:"last_check"`
-	ErrorCount   int       `json:"error_count"`
+	ErrorCount   int       `json:"error_count"` // recent errors; decays on success
 	SuccessCount int       `json:"success_count"`
+	TotalErrors  int       `json:"total_errors"`       // all errors since startup
+	Optional     bool      `json:"optional,omitempty"` // errors degrade it but never make it unhealthy
 }
 
 // Checker tracks health status of system components
 type Checker struct {
-	mu         sync.RWMutex
-	components map[string]*Component
+	mu          sync.RWMutex
+	components  map[string]*Component
+	onUnhealthy []func(name string, err error)
 }
 
 // New creates a new health checker
@@ -53,6 +64,21 @@ func (h *Checker) RegisterComponent(name string) {
 	}
 }
 
+// RegisterOptionalComponent registers a component the service carries on
+// without, such as a stats source that keeps its previous reading when a
+// read fails. Repeated errors leave it degraded rather than unhealthy.
+func (h *Checker) RegisterOptionalComponent(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.components[name] = &Component{
+		Name:      name,
+		Status:    StatusHealthy,
+		LastCheck: time.Now(),
+		Optional:  true,
+	}
+}
+
 // RecordSuccess records a successful operation for a component
 func (h *Checker) RecordSuccess(name string) {
 	h.mu.Lock()
@@ -76,19 +102,54 @@ func (h *Checker) RecordSuccess(name string) {
 
 // RecordError records an error for a component
 func (h *Checker) RecordError(name string, err error) {
+	h.recordError(name, err, false)
+}
+
+// MarkUnhealthy immediately marks a component unhealthy, for failures that
+// will not recover on their own
+func (h *Checker) MarkUnhealthy(name string, err error) {
+	h.recordError(name, err, true)
+}
+
+// OnUnhealthy registers f to be called each time a component becomes
+// unhealthy. f runs on the goroutine that reported the error, after the
+// checker is unlocked.
+func (h *Checker) OnUnhealthy(f func(name string, err error)) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	h.onUnhealthy = append(h.onUnhealthy, f)
+}
 
-	if comp, exists := h.components[name]; exists {
-		comp.ErrorCount++
-		comp.LastCheck = time.Now()
-		comp.Message = err.Error()
+// recordError records an error for a component, marking it unhealthy
+// straight away when unhealthy is set, and calls the OnUnhealthy functions
+// if it was not unhealthy before
+func (h *Checker) recordError(name string, err error, unhealthy bool) {
+	h.mu.Lock()
+	comp, exists := h.components[name]
+	if !exists {
+		h.mu.Unlock()
+		return
+	}
+	was := comp.Status
+	comp.ErrorCount++
+	comp.TotalErrors++
+	comp.LastCheck = time.Now()
+	comp.Message = err.Error()
+
+	// Determine status based on error count
+	switch {
+	case unhealthy, comp.ErrorCount >= 10 && !comp.Optional:
+		comp.Status = StatusUnhealthy
+	case comp.ErrorCount >= 3:
+		comp.Status = StatusDegraded
+	}
+	became := was != StatusUnhealthy && comp.Status == StatusUnhealthy
+	hooks := h.onUnhealthy
+	h.mu.Unlock()
 
-		// Determine status based on error count
-		if comp.ErrorCount >= 10 {
-			comp.Status = StatusUnhealthy
-		} else if comp.ErrorCount >= 3 {
-			comp.Status = StatusDegraded
+	if became {
+		for _, f := range hooks {
+			f(name, err)
 		}
 	}
 }
@@ -151,3 +212,19 @@ func (h *Checker) GetAllComponents() map[string]*Component {
 func (h *Checker) IsHealthy() bool {
 	return h.GetOverallStatus() == StatusHealthy
 }
+
+// Report is a point-in-time snapshot of overall and per-component health
+type Report struct {
+	Status     Status                `json:"status"`
+	Components map[string]*Component `json:"components"`
+	Timestamp  time.Time             `json:"timestamp"`
+}
+
+// Snapshot returns a report of the current health state
+func (h *Checker) Snapshot() Report {
+	return Report{
+		Status:     h.GetOverallStatus(),
+		Components: h.GetAllComponents(),
+		Timestamp:  time.Now(),
+	}
+}
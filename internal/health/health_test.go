@@ -101,6 +101,11 @@ func TestStatusTransitions(t *testing.T) {
 	if checker.GetComponentStatus("test").Status != StatusHealthy {
 		t.Errorf("expected status to recover to healthy, got %s", checker.GetComponentStatus("test").Status)
 	}
+
+	// The running total is not reset by recovery
+	if got := checker.GetComponentStatus("test").TotalErrors; got != 10 {
+		t.Errorf("expected 10 total errors after recovery, got %d", got)
+	}
 }
 
 func TestGetOverallStatus(t *testing.T) {
@@ -200,3 +205,80 @@ func TestLastCheckTimestamp(t *testing.T) {
 		t.Error("LastCheck timestamp not in expected range")
 	}
 }
+
+func TestMarkUnhealthy(t *testing.T) {
+	checker := New()
+	checker.RegisterComponent("test")
+
+	checker.MarkUnhealthy("test", errors.New("fatal"))
+	comp := checker.GetComponentStatus("test")
+	if comp.Status != StatusUnhealthy {
+		t.Errorf("expected unhealthy, got %s", comp.Status)
+	}
+	if comp.Message != "fatal" {
+		t.Errorf("expected message 'fatal', got %q", comp.Message)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	checker := New()
+	checker.RegisterComponent(ComponentDisplay)
+	checker.RegisterComponent(ComponentCollector)
+	for i := 0; i < 3; i++ {
+		checker.RecordError(ComponentDisplay, errors.New("i2c write failed"))
+	}
+
+	report := checker.Snapshot()
+	if report.Status != StatusDegraded {
+		t.Errorf("expected degraded, got %s", report.Status)
+	}
+	if len(report.Components) != 2 {
+		t.Errorf("expected 2 components, got %d", len(report.Components))
+	}
+	if report.Timestamp.IsZero() {
+		t.Error("expected timestamp to be set")
+	}
+}
+
+func TestOptionalComponent(t *testing.T) {
+	checker := New()
+	checker.RegisterOptionalComponent("collector.disk")
+
+	for range 20 {
+		checker.RecordError("collector.disk", errors.New("read timed out"))
+	}
+	if got := checker.GetComponentStatus("collector.disk").Status; got != StatusDegraded {
+		t.Errorf("expected an optional component to stop at degraded, got %s", got)
+	}
+	if got := checker.GetOverallStatus(); got != StatusDegraded {
+		t.Errorf("expected overall status degraded, got %s", got)
+	}
+}
+
+func TestOnUnhealthy(t *testing.T) {
+	checker := New()
+	checker.RegisterComponent(ComponentDisplay)
+	var calls []string
+	checker.OnUnhealthy(func(name string, err error) {
+		// The checker is unlocked while hooks run
+		_ = checker.GetComponentStatus(name)
+		calls = append(calls, name+": "+err.Error())
+	})
+
+	for range 15 {
+		checker.RecordError(ComponentDisplay, errors.New("i2c write failed"))
+	}
+	checker.MarkUnhealthy(ComponentDisplay, errors.New("gone"))
+	if len(calls) != 1 || calls[0] != "display: i2c write failed" {
+		t.Fatalf("expected one call when the display became unhealthy, got %q", calls)
+	}
+
+	// Recovering and failing again reports again
+	for range 16 {
+		checker.RecordSuccess(ComponentDisplay)
+	}
+	checker.MarkUnhealthy(ComponentDisplay, errors.New("gone"))
+	if len(calls) != 2 || calls[1] != "display: gone" {
+		t.Errorf("expected a second call after recovering, got %q", calls)
+	}
+}
@@ -0,0 +1,119 @@
+// Package i18n translates the labels drawn on pages and formats their
+// numbers for a locale. Translations are bundled with the binary; labels
+// without one are shown in English.
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Locale is a language's page labels and number format
+type Locale struct {
+	name    string
+	decimal string            // decimal separator
+	labels  map[string]string // translations keyed by the English label
+}
+
+// English is the default locale, leaving labels and numbers as written
+var English = &Locale{name: "en", decimal: "."}
+
+// locales are the bundled locales by name
+var locales = map[string]*Locale{
+	"en": English,
+	"de": {name: "de", decimal: ",", labels: german},
+	"es": {name: "es", decimal: ",", labels: spanish},
+	"fr": {name: "fr", decimal: ",", labels: french},
+	"nl": {name: "nl", decimal: ",", labels: dutch},
+}
+
+// Names returns the names of the bundled locales, sorted
+func Names() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Lookup returns the bundled locale for a language code such as "de",
+// ignoring any region and encoding, as in "de_AT.UTF-8". An empty name
+// is English.
+func Lookup(name string) (*Locale, error) {
+	if name == "" {
+		return English, nil
+	}
+	lang := strings.ToLower(name)
+	if i := strings.IndexAny(lang, "_-."); i >= 0 {
+		lang = lang[:i]
+	}
+	if l, ok := locales[lang]; ok {
+		return l, nil
+	}
+	return nil, fmt.Errorf("unknown locale %q, expected one of %s", name, strings.Join(Names(), ", "))
+}
+
+// Name returns the locale's language code
+func (l *Locale) Name() string {
+	return l.name
+}
+
+// T returns the translation of an English label, or the label itself when
+// the locale has none
+func (l *Locale) T(label string) string {
+	if t, ok := l.labels[label]; ok {
+		return t
+	}
+	return label
+}
+
+// ASCII reports whether every translation is plain ASCII, which the
+// built-in fonts can draw without a fallback font
+func (l *Locale) ASCII() bool {
+	for _, t := range l.labels {
+		for _, r := range t {
+			if r > unicode.MaxASCII {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Sprintf translates format, then formats it like fmt.Sprintf with
+// floating-point arguments written with the locale's decimal separator.
+// Other arguments, such as addresses passed as strings, are left alone.
+func (l *Locale) Sprintf(format string, args ...any) string {
+	format = l.T(format)
+	if l.decimal == "." {
+		return fmt.Sprintf(format, args...)
+	}
+	local := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case float64:
+			local[i] = number{v, l.decimal}
+		case float32:
+			local[i] = number{float64(v), l.decimal}
+		default:
+			local[i] = arg
+		}
+	}
+	return fmt.Sprintf(format, local...)
+}
+
+// number is a float formatted with a decimal separator other than "."
+type number struct {
+	v       float64
+	decimal string
+}
+
+// Format formats the number as its verb, width and precision ask, then
+// swaps the decimal point
+func (n number) Format(f fmt.State, verb rune) {
+	s := fmt.Sprintf(fmt.FormatString(f, verb), n.v)
+	_, _ = f.Write([]byte(strings.Replace(s, ".", n.decimal, 1)))
+}
@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	for name, want := range map[string]string{"": "en", "en": "en", "de": "de", "de_AT.UTF-8": "de", "FR-ca": "fr"} {
+		l, err := Lookup(name)
+		if err != nil {
+			t.Errorf("Lookup(%q) failed: %v", name, err)
+			continue
+		}
+		if l.Name() != want {
+			t.Errorf("Lookup(%q) = %s, want %s", name, l.Name(), want)
+		}
+	}
+	if _, err := Lookup("xx"); err == nil {
+		t.Error("expected an error for an unknown locale")
+	}
+	if got := Names(); !slices.Equal(got, []string{"de", "en", "es", "fr", "nl"}) {
+		t.Errorf("Names() = %v", got)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	de, _ := Lookup("de")
+	if got := de.T("Memory"); got != "Speicher" {
+		t.Errorf("T(Memory) = %q, want Speicher", got)
+	}
+	if got := de.T("Frobnicator"); got != "Frobnicator" {
+		t.Errorf("untranslated label = %q, want it unchanged", got)
+	}
+	if got := English.T("Memory"); got != "Memory" {
+		t.Errorf("English T(Memory) = %q", got)
+	}
+	if !English.ASCII() || de.ASCII() {
+		t.Errorf("ASCII() = %v for English and %v for German, want true and false", English.ASCII(), de.ASCII())
+	}
+}
+
+func TestSprintf(t *testing.T) {
+	de, _ := Lookup("de")
+	tests := []struct {
+		l      *Locale
+		format string
+		args   []any
+		want   string
+	}{
+		{English, "%.1f/%.1fG", []any{1.25, 29.0}, "1.2/29.0G"},
+		{de, "%.1f/%.1fG", []any{1.25, 29.0}, "1,2/29,0G"},
+		{de, "%5.2f|%-6.1f|%.0f%%", []any{float32(0.5), 2.0, 45.6}, " 0,50|2,0   |46%"},
+		{de, "%s %d", []any{"192.168.1.2", 3}, "192.168.1.2 3"},
+		{de, "Page %d/%d", []any{1, 2}, "Seite 1/2"},
+	}
+	for _, tt := range tests {
+		if got := tt.l.Sprintf(tt.format, tt.args...); got != tt.want {
+			t.Errorf("%s Sprintf(%q) = %q, want %q", tt.l.Name(), tt.format, got, tt.want)
+		}
+	}
+}
+
+// verbs matches formatting verbs
+var verbs = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogs(t *testing.T) {
+	for _, name := range Names() {
+		l, _ := Lookup(name)
+		for label, translation := range l.labels {
+			if translation == "" {
+				t.Errorf("%s: empty translation of %q", name, label)
+			}
+			if want, got := verbs.FindAllString(label, -1), verbs.FindAllString(translation, -1); !slices.Equal(got, want) {
+				t.Errorf("%s: %q has verbs %v, want %v as in %q", name, translation, got, want, label)
+			}
+		}
+	}
+}
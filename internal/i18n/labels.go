@@ -0,0 +1,137 @@
+package i18n
+
+// The bundled translations, keyed by the English label or format. Formats
+// keep their verbs in the same order. Translations are kept short enough
+// for a 128 pixel wide display where the English is.
+
+var german = map[string]string{
+	// Page titles
+	"System":       "System",
+	"Disk":         "Datenträger",
+	"Memory":       "Speicher",
+	"Temperatures": "Temperaturen",
+	"Load":         "Last",
+	"Power":        "Stromversorgung",
+	"Storage":      "Speichermedien",
+	"Clock":        "Uhr",
+	"Message":      "Nachricht",
+	"Calibration":  "Kalibrierung",
+	"First boot":   "Erster Start",
+	"Latency":      "Latenz",
+	"Top memory":   "Top Speicher",
+	"Shutdown":     "Abschaltung",
+
+	// Labels
+	"N/A":                    "k.A.",
+	"Page %d/%d":             "Seite %d/%d",
+	"DISK READ-ONLY":         "NUR LESBAR",
+	"Reboot required":        "Neustart nötig",
+	"Reboot, %d upd":         "Neustart, %d Upd",
+	"1 update":               "1 Update",
+	"%d updates":             "%d Updates",
+	"Updates: error":         "Updates: Fehler",
+	"Up to date":             "Aktuell",
+	"Shutdown in %ds":        "Abschaltung in %ds",
+	"Shutting down...":       "Fährt herunter...",
+	"Waiting for network...": "Warte auf Netz...",
+	"No network":             "Kein Netz",
+}
+
+var spanish = map[string]string{
+	// Page titles
+	"System":       "Sistema",
+	"Disk":         "Disco",
+	"Memory":       "Memoria",
+	"Temperatures": "Temperaturas",
+	"Load":         "Carga",
+	"Power":        "Alimentación",
+	"Storage":      "Almacenamiento",
+	"Clock":        "Reloj",
+	"Message":      "Mensaje",
+	"Calibration":  "Calibración",
+	"First boot":   "Primer arranque",
+	"Latency":      "Latencia",
+	"Top memory":   "Top memoria",
+	"Shutdown":     "Apagado",
+
+	// Labels
+	"N/A":                    "N/D",
+	"Page %d/%d":             "Pág. %d/%d",
+	"DISK READ-ONLY":         "SOLO LECTURA",
+	"Reboot required":        "Reiniciar",
+	"Reboot, %d upd":         "Reiniciar, %d act",
+	"1 update":               "1 actualización",
+	"%d updates":             "%d actualiz.",
+	"Updates: error":         "Actualiz.: error",
+	"Up to date":             "Actualizado",
+	"Shutdown in %ds":        "Apagado en %ds",
+	"Shutting down...":       "Apagando...",
+	"Waiting for network...": "Esperando red...",
+	"No network":             "Sin red",
+}
+
+var french = map[string]string{
+	// Page titles
+	"System":       "Système",
+	"Disk":         "Disque",
+	"Memory":       "Mémoire",
+	"Temperatures": "Températures",
+	"Load":         "Charge",
+	"Power":        "Alimentation",
+	"Storage":      "Stockage",
+	"Clock":        "Horloge",
+	"Message":      "Message",
+	"Calibration":  "Calibrage",
+	"First boot":   "Premier démarrage",
+	"Latency":      "Latence",
+	"Top memory":   "Top mémoire",
+	"Shutdown":     "Arrêt",
+
+	// Labels
+	"N/A":                    "N/D",
+	"Page %d/%d":             "Page %d/%d",
+	"DISK READ-ONLY":         "LECTURE SEULE",
+	"Reboot required":        "Redémarrage requis",
+	"Reboot, %d upd":         "Redémarrer, %d maj",
+	"1 update":               "1 mise à jour",
+	"%d updates":             "%d mises à jour",
+	"Updates: error":         "MàJ : erreur",
+	"Up to date":             "À jour",
+	"Shutdown in %ds":        "Arrêt dans %ds",
+	"Shutting down...":       "Arrêt en cours...",
+	"Waiting for network...": "Attente du réseau...",
+	"No network":             "Pas de réseau",
+}
+
+var dutch = map[string]string{
+	// Page titles
+	"System":       "Systeem",
+	"Disk":         "Schijf",
+	"Memory":       "Geheugen",
+	"Temperatures": "Temperaturen",
+	"Load":         "Belasting",
+	"Power":        "Voeding",
+	"Storage":      "Opslag",
+	"Clock":        "Klok",
+	"Message":      "Bericht",
+	"Calibration":  "Kalibratie",
+	"First boot":   "Eerste start",
+	"Latency":      "Latentie",
+	"Top memory":   "Top geheugen",
+	"Shutdown":     "Uitschakelen",
+
+	// Labels
+	"N/A":                    "n.v.t.",
+	"Page %d/%d":             "Pagina %d/%d",
+	"DISK READ-ONLY":         "ALLEEN-LEZEN",
+	"Reboot required":        "Herstart nodig",
+	"Reboot, %d upd":         "Herstart, %d upd",
+	"1 update":               "1 update",
+	"%d updates":             "%d updates",
+	"Updates: error":         "Updates: fout",
+	"Up to date":             "Bijgewerkt",
+	"Shutdown in %ds":        "Uit over %ds",
+	"Shutting down...":       "Afsluiten...",
+	"Waiting for network...": "Wacht op netwerk...",
+	"No network":             "Geen netwerk",
+}
@@ -0,0 +1,96 @@
+// Package jsonpath extracts values from decoded JSON with a small subset of
+// JSONPath: dotted member names and bracketed indexes or quoted names, e.g.
+// "$.queries.blocked", "printers[0].state" or "$['dns-queries'].today".
+// Negative indexes count from the end of an array.
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path is a parsed path, one step per member name or array index
+type Path []step
+
+type step struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// Parse parses a path. The leading "$" is optional, as is the dot before the
+// first member name.
+func Parse(s string) (Path, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(s), "$")
+	if rest == "" {
+		return Path{}, nil
+	}
+	var path Path
+	first := true
+	for rest != "" {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", s)
+			}
+			inner := rest[1:end]
+			if n := len(inner); n >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[n-1] == inner[0] {
+				path = append(path, step{key: inner[1 : n-1]})
+			} else {
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index [%s] in %q", inner, s)
+				}
+				path = append(path, step{index: i, isIndex: true})
+			}
+			rest = rest[end+1:]
+		case rest[0] == '.' || first:
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[]")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty member name in %q", s)
+			}
+			path = append(path, step{key: rest[:end]})
+			rest = rest[end:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", rest[0], s)
+		}
+		first = false
+	}
+	return path, nil
+}
+
+// Lookup returns the value at the path in v, as decoded by encoding/json
+// into an any. ok is false when a member or index is missing.
+func (p Path) Lookup(v any) (value any, ok bool) {
+	for _, st := range p {
+		if st.isIndex {
+			arr, isArr := v.([]any)
+			if !isArr {
+				return nil, false
+			}
+			i := st.index
+			if i < 0 {
+				i += len(arr)
+			}
+			if i < 0 || i >= len(arr) {
+				return nil, false
+			}
+			v = arr[i]
+			continue
+		}
+		obj, isObj := v.(map[string]any)
+		if !isObj {
+			return nil, false
+		}
+		if v, ok = obj[st.key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,61 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const document = `{
+	"queries": {"blocked": 1234, "percent": 12.5},
+	"dns-queries": {"today": 9000},
+	"printers": [{"state": "Printing"}, {"state": "Idle"}],
+	"version": "2.1"
+}`
+
+func TestLookup(t *testing.T) {
+	var v any
+	if err := json.Unmarshal([]byte(document), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"$.queries.blocked", 1234.0},
+		{"queries.percent", 12.5},
+		{"$['dns-queries'].today", 9000.0},
+		{`$["dns-queries"]["today"]`, 9000.0},
+		{"printers[0].state", "Printing"},
+		{"$.printers[-1].state", "Idle"},
+		{"version", "2.1"},
+	}
+	for _, tt := range tests {
+		path, err := Parse(tt.path)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.path, err)
+			continue
+		}
+		if got, ok := path.Lookup(v); !ok || got != tt.want {
+			t.Errorf("Lookup(%q) = %v, %v; want %v", tt.path, got, ok, tt.want)
+		}
+	}
+
+	for _, missing := range []string{"queries.allowed", "printers[2]", "version.major", "queries[0]"} {
+		path, err := Parse(missing)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", missing, err)
+		}
+		if got, ok := path.Lookup(v); ok {
+			t.Errorf("Lookup(%q) = %v, want not found", missing, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, path := range []string{"queries..blocked", "printers[0", "printers[x]", "$.queries]", "a.b."} {
+		if _, err := Parse(path); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", path)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -69,6 +70,27 @@ func NewDefault() *Logger {
 	})
 }
 
+// SetLevel changes the level of all loggers at runtime. Unlike New it
+// rejects unknown level names.
+func SetLevel(level string) error {
+	switch strings.ToLower(level) {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		return fmt.Errorf("unknown log level %q, expected debug, info, warn or error", level)
+	}
+	globalLoggerMu.Lock()
+	defer globalLoggerMu.Unlock()
+	zerolog.SetGlobalLevel(parseLevel(level))
+	return nil
+}
+
+// Level returns the current level of all loggers
+func Level() string {
+	globalLoggerMu.RLock()
+	defer globalLoggerMu.RUnlock()
+	return zerolog.GlobalLevel().String()
+}
+
 // parseLevel converts string level to zerolog level
 func parseLevel(level string) zerolog.Level {
 	switch strings.ToLower(level) {
@@ -150,6 +172,15 @@ func (l *Logger) With() *Event {
 	return &Event{event: l.logger.With()}
 }
 
+// Sampled returns a logger that writes only every nth message, for events
+// that would otherwise flood the log. n <= 1 logs every message.
+func (l *Logger) Sampled(n int) *Logger {
+	if n <= 1 {
+		return l
+	}
+	return &Logger{logger: l.logger.Sample(&zerolog.BasicSampler{N: uint32(n)})}
+}
+
 // Event wraps zerolog context for fluent API
 type Event struct {
 	event zerolog.Context
@@ -173,6 +204,12 @@ func (e *Event) Float64(key string, value float64) *Event {
 	return e
 }
 
+// Dur adds a duration field
+func (e *Event) Dur(key string, d time.Duration) *Event {
+	e.event = e.event.Dur(key, d)
+	return e
+}
+
 // Bool adds a boolean field
 func (e *Event) Bool(key string, value bool) *Event {
 	e.event = e.event.Bool(key, value)
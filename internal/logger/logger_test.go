@@ -3,6 +3,7 @@ package logger
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 )
@@ -118,3 +119,44 @@ func TestLoggerWith(t *testing.T) {
 		t.Error("expected context field 'component' in output")
 	}
 }
+
+func TestSampled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &Logger{logger: zerolog.New(&buf)}
+
+	sampled := logger.Sampled(5)
+	for i := 0; i < 10; i++ {
+		sampled.With().Dur("duration", time.Millisecond).Logger().Error("frame")
+	}
+	if got := bytes.Count(buf.Bytes(), []byte("frame")); got != 2 {
+		t.Errorf("expected 2 of 10 messages with 1-in-5 sampling, got %d", got)
+	}
+
+	if logger.Sampled(1) != logger {
+		t.Error("expected no sampling for n <= 1")
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel(debug) failed: %v", err)
+	}
+	if got := Level(); got != "debug" {
+		t.Errorf("Level() = %q, want debug", got)
+	}
+	if err := SetLevel("WARNING"); err != nil {
+		t.Fatalf("SetLevel(WARNING) failed: %v", err)
+	}
+	if got := Level(); got != "warn" {
+		t.Errorf("Level() = %q, want warn", got)
+	}
+
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	if got := Level(); got != "warn" {
+		t.Errorf("expected level unchanged after a rejected change, got %q", got)
+	}
+}
@@ -0,0 +1,35 @@
+// Package logind tells the daemon when the host is about to suspend or
+// power off, and when it resumes, so the panel can be powered down instead
+// of freezing on a stale frame. It holds a systemd-logind "delay" inhibitor
+// lock, which holds off suspend and shutdown for a few seconds until the
+// display is off, and talks to logind over the D-Bus system bus without a
+// D-Bus library.
+package logind
+
+// Event is a power state change of the host
+type Event int
+
+// Events passed to the handler given to Watch
+const (
+	// EventSuspend is sent before the host suspends or hibernates
+	EventSuspend Event = iota
+	// EventResume is sent after the host resumes, or when a shutdown is
+	// cancelled
+	EventResume
+	// EventShutdown is sent before the host powers off or reboots
+	EventShutdown
+)
+
+// String returns the event's name, for logs
+func (e Event) String() string {
+	switch e {
+	case EventSuspend:
+		return "suspend"
+	case EventResume:
+		return "resume"
+	case EventShutdown:
+		return "shutdown"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,294 @@
+package logind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// systemBus is the D-Bus system bus socket
+const systemBus = "/run/dbus/system_bus_socket"
+
+// D-Bus names of the bus itself and of logind
+const (
+	busName      = "org.freedesktop.DBus"
+	busPath      = "/org/freedesktop/DBus"
+	login1Name   = "org.freedesktop.login1"
+	login1Path   = "/org/freedesktop/login1"
+	managerIface = "org.freedesktop.login1.Manager"
+)
+
+// Watch takes a delay inhibitor lock from logind and calls handle from a
+// background goroutine when the host is about to suspend or power off, and
+// after it resumes, until ctx is done. Suspend and shutdown wait, up to
+// logind's InhibitDelayMaxSec, for handle to return. Watch fails if the
+// system bus or logind cannot be reached or the lock is refused.
+func Watch(ctx context.Context, handle func(Event), log *logger.Logger) error {
+	uc, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: systemBus, Net: "unix"})
+	if err != nil {
+		return fmt.Errorf("system bus: %w", err)
+	}
+	return watch(ctx, uc, handle, log)
+}
+
+// watch is Watch on an open connection to the bus
+func watch(ctx context.Context, uc *net.UnixConn, handle func(Event), log *logger.Logger) error {
+	c := &conn{uc: uc}
+	lock, err := c.subscribe()
+	if err != nil {
+		_ = uc.Close()
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		_ = uc.Close()
+	}()
+	go c.run(lock, handle, log)
+	return nil
+}
+
+// conn is a connection to the D-Bus system bus
+type conn struct {
+	uc     *net.UnixConn
+	serial uint32
+	buf    []byte     // received bytes not yet parsed
+	fds    []int      // received descriptors not yet given to a message
+	queue  []*message // signals received while waiting for a reply
+}
+
+// subscribe authenticates, asks for logind's PrepareForSleep and
+// PrepareForShutdown signals and takes the inhibitor lock
+func (c *conn) subscribe() (int, error) {
+	if err := c.auth(); err != nil {
+		return -1, err
+	}
+	if _, err := c.call(busName, busPath, busName, "Hello", ""); err != nil {
+		return -1, fmt.Errorf("D-Bus hello: %w", err)
+	}
+	for _, member := range []string{"PrepareForSleep", "PrepareForShutdown"} {
+		rule := fmt.Sprintf("type='signal',sender='%s',interface='%s',member='%s',path='%s'", login1Name, managerIface, member, login1Path)
+		if _, err := c.call(busName, busPath, busName, "AddMatch", "s", rule); err != nil {
+			return -1, fmt.Errorf("subscribe to %s: %w", member, err)
+		}
+	}
+	return c.inhibit()
+}
+
+// auth authenticates as the process's user with the EXTERNAL mechanism
+// and enables descriptor passing, which the inhibitor lock needs
+func (c *conn) auth() error {
+	uid := strconv.Itoa(os.Getuid())
+	for _, step := range []struct{ send, want string }{
+		{fmt.Sprintf("\x00AUTH EXTERNAL %x\r\n", uid), "OK "},
+		{"NEGOTIATE_UNIX_FD\r\n", "AGREE_UNIX_FD"},
+	} {
+		if _, err := c.uc.Write([]byte(step.send)); err != nil {
+			return fmt.Errorf("D-Bus auth: %w", err)
+		}
+		line, err := c.readLine()
+		if err != nil {
+			return fmt.Errorf("D-Bus auth: %w", err)
+		}
+		if !strings.HasPrefix(line, step.want) {
+			return fmt.Errorf("D-Bus auth rejected: %q", line)
+		}
+	}
+	if _, err := c.uc.Write([]byte("BEGIN\r\n")); err != nil {
+		return fmt.Errorf("D-Bus auth: %w", err)
+	}
+	return nil
+}
+
+// readLine reads an authentication reply a byte at a time, so no message
+// bytes are read ahead
+func (c *conn) readLine() (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for !strings.HasSuffix(string(line), "\r\n") {
+		if len(line) > 512 {
+			return "", errors.New("auth reply too long")
+		}
+		if _, err := c.uc.Read(b); err != nil {
+			return "", err
+		}
+		line = append(line, b[0])
+	}
+	return strings.TrimSuffix(string(line), "\r\n"), nil
+}
+
+// inhibit takes a delay lock on sleep and shutdown and returns its
+// descriptor; closing it releases the lock
+func (c *conn) inhibit() (int, error) {
+	reply, err := c.call(login1Name, login1Path, managerIface, "Inhibit", "ssss",
+		"sleep:shutdown", "i2c-display", "Power down the display", "delay")
+	if err != nil {
+		return -1, fmt.Errorf("inhibitor lock: %w", err)
+	}
+	idx := reply.args().uint32()
+	if reply.signature != "h" || int(idx) >= len(reply.fds) {
+		closeFDs(reply.fds)
+		return -1, errors.New("inhibitor lock: reply without a descriptor")
+	}
+	lock := reply.fds[idx]
+	closeFDs(append(reply.fds[:idx:idx], reply.fds[idx+1:]...))
+	return lock, nil
+}
+
+// call sends a method call with string arguments and waits for its reply.
+// Signals that arrive meanwhile are queued for next.
+func (c *conn) call(dest, path, iface, member, sig string, args ...string) (*message, error) {
+	c.serial++
+	m := &message{
+		typ:         msgMethodCall,
+		serial:      c.serial,
+		destination: dest,
+		path:        path,
+		iface:       iface,
+		member:      member,
+		signature:   sig,
+		body:        stringArgs(args...),
+	}
+	if _, err := c.uc.Write(m.marshal()); err != nil {
+		return nil, err
+	}
+	for {
+		r, err := c.read()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case r.typ == msgSignal:
+			c.queue = append(c.queue, r)
+		case r.replySerial != m.serial:
+			closeFDs(r.fds)
+		case r.typ == msgError:
+			closeFDs(r.fds)
+			return nil, r.asError()
+		default:
+			return r, nil
+		}
+	}
+}
+
+// next returns the next signal or other message that is not a reply
+func (c *conn) next() (*message, error) {
+	if len(c.queue) > 0 {
+		m := c.queue[0]
+		c.queue = c.queue[1:]
+		return m, nil
+	}
+	return c.read()
+}
+
+// read returns the next message from the bus, with the descriptors sent
+// with it
+func (c *conn) read() (*message, error) {
+	for {
+		m, n, err := parseMessage(c.buf)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			m.body = append([]byte(nil), m.body...)
+			c.buf = c.buf[n:]
+			if k := min(int(m.unixFDs), len(c.fds)); k > 0 {
+				m.fds = c.fds[:k:k]
+				c.fds = c.fds[k:]
+			}
+			return m, nil
+		}
+
+		buf := make([]byte, 4096)
+		oob := make([]byte, syscall.CmsgSpace(16*4))
+		n, oobn, _, _, err := c.uc.ReadMsgUnix(buf, oob)
+		if err != nil {
+			return nil, err
+		}
+		if oobn > 0 {
+			c.fds = append(c.fds, parseRights(oob[:oobn])...)
+		}
+		c.buf = append(c.buf, buf[:n]...)
+	}
+}
+
+// parseRights returns the descriptors passed in control messages
+func parseRights(oob []byte) []int {
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil
+	}
+	var fds []int
+	for _, msg := range msgs {
+		if rights, err := syscall.ParseUnixRights(&msg); err == nil {
+			fds = append(fds, rights...)
+		}
+	}
+	return fds
+}
+
+// run calls handle for logind's signals until the connection is closed.
+// lock is the inhibitor lock, released once handle has powered the display
+// down and taken again on resume.
+func (c *conn) run(lock int, handle func(Event), log *logger.Logger) {
+	defer func() {
+		if lock >= 0 {
+			_ = syscall.Close(lock)
+		}
+		_ = c.uc.Close()
+		closeFDs(c.fds)
+	}()
+
+	for {
+		m, err := c.next()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.With().Err(err).Logger().Warn("Lost the system bus; suspend and shutdown no longer power the display down")
+			}
+			return
+		}
+		closeFDs(m.fds)
+		if m.typ != msgSignal || m.iface != managerIface || m.signature != "b" {
+			continue
+		}
+		start := m.args().bool()
+
+		var event Event
+		switch {
+		case m.member == "PrepareForSleep" && start:
+			event = EventSuspend
+		case m.member == "PrepareForShutdown" && start:
+			event = EventShutdown
+		case m.member == "PrepareForSleep", m.member == "PrepareForShutdown":
+			// Resumed, or the shutdown was cancelled: hold off the next
+			// one again before waking the display
+			event = EventResume
+			if lock < 0 {
+				if lock, err = c.inhibit(); err != nil {
+					log.With().Err(err).Logger().Warn("Failed to take the inhibitor lock again; the next suspend may not wait for the display")
+				}
+			}
+		default:
+			continue
+		}
+
+		handle(event)
+		if event != EventResume && lock >= 0 {
+			_ = syscall.Close(lock)
+			lock = -1
+		}
+	}
+}
+
+// closeFDs closes descriptors received but not needed
+func closeFDs(fds []int) {
+	for _, fd := range fds {
+		_ = syscall.Close(fd)
+	}
+}
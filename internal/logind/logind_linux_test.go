@@ -0,0 +1,198 @@
+package logind
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// socketPair returns both ends of a connected Unix stream socket
+func socketPair(t *testing.T) (*net.UnixConn, *net.UnixConn) {
+	t.Helper()
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conns := make([]*net.UnixConn, 2)
+	for i, fd := range fds {
+		f := os.NewFile(uintptr(fd), "socketpair")
+		c, err := net.FileConn(f)
+		_ = f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns[i] = c.(*net.UnixConn)
+	}
+	return conns[0], conns[1]
+}
+
+// fakeBus plays the system bus and logind on the far end of a socket pair.
+// Each inhibitor lock it hands out is the write end of a pipe, so the test
+// sees the lock released when reading the other end returns EOF.
+type fakeBus struct {
+	t     *testing.T
+	c     *conn
+	locks chan *os.File // read ends of the locks handed out
+}
+
+// serveAuth answers the client's authentication
+func (b *fakeBus) serveAuth() {
+	for _, reply := range []string{"OK 0123456789abcdef\r\n", "AGREE_UNIX_FD\r\n", ""} {
+		line, err := b.c.readLine()
+		if err != nil {
+			b.t.Errorf("fake bus: %v", err)
+			return
+		}
+		if reply == "" {
+			if line != "BEGIN" {
+				b.t.Errorf("fake bus: got %q, want BEGIN", line)
+			}
+			return
+		}
+		if _, err := b.c.uc.Write([]byte(reply)); err != nil {
+			b.t.Errorf("fake bus: %v", err)
+		}
+	}
+}
+
+// serveCalls answers n method calls
+func (b *fakeBus) serveCalls(n int) {
+	for range n {
+		m, err := b.c.read()
+		if err != nil {
+			b.t.Errorf("fake bus: %v", err)
+			return
+		}
+		reply := &message{typ: msgMethodReturn, serial: 100 + m.serial, replySerial: m.serial}
+		var rights []byte
+		switch m.member {
+		case "Hello":
+			reply.signature, reply.body = "s", stringArgs(":1.42")
+		case "Inhibit":
+			if args := m.args(); args.string() != "sleep:shutdown" || args.string() != "i2c-display" {
+				b.t.Errorf("fake bus: unexpected Inhibit arguments")
+			}
+			r, w, err := os.Pipe()
+			if err != nil {
+				b.t.Errorf("fake bus: %v", err)
+				return
+			}
+			defer w.Close()
+			b.locks <- r
+			var body encoder
+			body.uint32(0)
+			reply.signature, reply.body, reply.unixFDs = "h", body.b, 1
+			rights = syscall.UnixRights(int(w.Fd()))
+		}
+		if _, _, err := b.c.uc.WriteMsgUnix(reply.marshal(), rights, nil); err != nil {
+			b.t.Errorf("fake bus: %v", err)
+		}
+	}
+}
+
+// signal sends one of logind's Prepare signals
+func (b *fakeBus) signal(member string, start bool) {
+	var body encoder
+	body.uint32(0)
+	if start {
+		body.b[0] = 1
+	}
+	m := &message{typ: msgSignal, serial: 500, sender: login1Name, path: login1Path, iface: managerIface, member: member, signature: "b", body: body.b}
+	if _, err := b.c.uc.Write(m.marshal()); err != nil {
+		b.t.Errorf("fake bus: %v", err)
+	}
+}
+
+// released waits for the lock to be released
+func released(t *testing.T, lock *os.File) {
+	t.Helper()
+	_ = lock.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := lock.Read(make([]byte, 1)); err == nil || !strings.Contains(err.Error(), "EOF") {
+		t.Errorf("expected the inhibitor lock to be released, got %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	client, server := socketPair(t)
+	bus := &fakeBus{t: t, c: &conn{uc: server}, locks: make(chan *os.File, 2)}
+	defer server.Close()
+	go func() {
+		bus.serveAuth()
+		bus.serveCalls(4) // Hello, two AddMatch and Inhibit
+	}()
+
+	events := make(chan Event, 4)
+	handle := func(e Event) { events <- e }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := watch(ctx, client, handle, logger.NewDefault()); err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+	lock := <-bus.locks
+	defer lock.Close()
+
+	expect := func(want Event) {
+		t.Helper()
+		select {
+		case got := <-events:
+			if got != want {
+				t.Errorf("event = %v, want %v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %v event", want)
+		}
+	}
+
+	// Suspend: the handler runs, then the lock is released
+	bus.signal("PrepareForSleep", true)
+	expect(EventSuspend)
+	released(t, lock)
+
+	// Resume: the lock is taken again before the handler runs
+	bus.signal("PrepareForSleep", false)
+	bus.serveCalls(1)
+	expect(EventResume)
+	lock2 := <-bus.locks
+	defer lock2.Close()
+
+	bus.signal("PrepareForShutdown", true)
+	expect(EventShutdown)
+	released(t, lock2)
+
+	// Other signals are ignored
+	bus.signal("SessionNew", true)
+	cancel()
+	select {
+	case e := <-events:
+		t.Errorf("unexpected event %v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWatchAuthRejected(t *testing.T) {
+	client, server := socketPair(t)
+	defer server.Close()
+	go func() {
+		c := &conn{uc: server}
+		if _, err := c.readLine(); err == nil {
+			_, _ = server.Write([]byte("REJECTED EXTERNAL\r\n"))
+		}
+	}()
+	if err := watch(context.Background(), client, func(Event) {}, logger.NewDefault()); err == nil {
+		t.Error("expected an error when authentication is rejected")
+	}
+}
+
+func TestEventString(t *testing.T) {
+	for e, want := range map[Event]string{EventSuspend: "suspend", EventResume: "resume", EventShutdown: "shutdown", Event(9): "unknown"} {
+		if got := e.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", e, got, want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package logind
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// Watch is only supported on Linux, which has logind
+func Watch(ctx context.Context, handle func(Event), log *logger.Logger) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,285 @@
+package logind
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// D-Bus message types
+const (
+	msgMethodCall   byte = 1
+	msgMethodReturn byte = 2
+	msgError        byte = 3
+	msgSignal       byte = 4
+)
+
+// D-Bus header field codes
+const (
+	fieldPath        byte = 1
+	fieldInterface   byte = 2
+	fieldMember      byte = 3
+	fieldErrorName   byte = 4
+	fieldReplySerial byte = 5
+	fieldDestination byte = 6
+	fieldSender      byte = 7
+	fieldSignature   byte = 8
+	fieldUnixFDs     byte = 9
+)
+
+// maxMessageSize is the largest message D-Bus allows
+const maxMessageSize = 128 << 20
+
+// message is a D-Bus message. Only the argument types logind's calls and
+// signals use are supported: strings, booleans and file descriptors.
+type message struct {
+	typ         byte
+	serial      uint32
+	path        string
+	iface       string
+	member      string
+	errorName   string
+	replySerial uint32
+	destination string
+	sender      string
+	signature   string
+	unixFDs     uint32
+	body        []byte
+	order       binary.ByteOrder // of body
+	fds         []int            // descriptors received with the message
+}
+
+// marshal encodes m in little-endian byte order
+func (m *message) marshal() []byte {
+	// Header fields start 16 bytes in, so aligning them from 0 aligns them
+	// within the message
+	var f encoder
+	field := func(code byte, sig string, put func()) {
+		f.align(8)
+		f.b = append(f.b, code)
+		f.signature(sig)
+		put()
+	}
+	for _, s := range []struct {
+		code  byte
+		sig   string
+		value string
+	}{
+		{fieldPath, "o", m.path},
+		{fieldInterface, "s", m.iface},
+		{fieldMember, "s", m.member},
+		{fieldErrorName, "s", m.errorName},
+		{fieldDestination, "s", m.destination},
+		{fieldSender, "s", m.sender},
+	} {
+		if s.value != "" {
+			field(s.code, s.sig, func() { f.string(s.value) })
+		}
+	}
+	if m.replySerial != 0 {
+		field(fieldReplySerial, "u", func() { f.uint32(m.replySerial) })
+	}
+	if m.signature != "" {
+		field(fieldSignature, "g", func() { f.signature(m.signature) })
+	}
+	if m.unixFDs != 0 {
+		field(fieldUnixFDs, "u", func() { f.uint32(m.unixFDs) })
+	}
+
+	e := encoder{b: []byte{'l', m.typ, 0, 1}}
+	e.uint32(uint32(len(m.body))) // #nosec G115 -- bodies are a few strings
+	e.uint32(m.serial)
+	e.uint32(uint32(len(f.b))) // #nosec G115 -- a handful of header fields
+	e.b = append(e.b, f.b...)
+	e.align(8)
+	return append(e.b, m.body...)
+}
+
+// parseMessage decodes the message at the start of b. It returns a nil
+// message while b holds only part of one, and the message's length.
+func parseMessage(b []byte) (*message, int, error) {
+	if len(b) < 16 {
+		return nil, 0, nil
+	}
+	var order binary.ByteOrder
+	switch b[0] {
+	case 'l':
+		order = binary.LittleEndian
+	case 'B':
+		order = binary.BigEndian
+	default:
+		return nil, 0, fmt.Errorf("bad D-Bus byte order %q", b[0])
+	}
+	bodyLen := int(order.Uint32(b[4:8]))
+	fieldsEnd := 16 + int(order.Uint32(b[12:16]))
+	bodyStart := (fieldsEnd + 7) &^ 7
+	if bodyLen > maxMessageSize || fieldsEnd > maxMessageSize {
+		return nil, 0, errors.New("D-Bus message too large")
+	}
+	total := bodyStart + bodyLen
+	if len(b) < total {
+		return nil, 0, nil
+	}
+
+	m := &message{typ: b[1], serial: order.Uint32(b[8:12]), order: order}
+	d := decoder{b: b[:fieldsEnd], off: 16, order: order}
+	for d.off < fieldsEnd && d.err == nil {
+		d.align(8)
+		code := d.byte()
+		switch sig := d.signature(); sig {
+		case "s", "o":
+			s := d.string()
+			switch code {
+			case fieldPath:
+				m.path = s
+			case fieldInterface:
+				m.iface = s
+			case fieldMember:
+				m.member = s
+			case fieldErrorName:
+				m.errorName = s
+			case fieldDestination:
+				m.destination = s
+			case fieldSender:
+				m.sender = s
+			}
+		case "g":
+			if s := d.signature(); code == fieldSignature {
+				m.signature = s
+			}
+		case "u":
+			v := d.uint32()
+			switch code {
+			case fieldReplySerial:
+				m.replySerial = v
+			case fieldUnixFDs:
+				m.unixFDs = v
+			}
+		default:
+			return nil, 0, fmt.Errorf("unsupported D-Bus header field type %q", sig)
+		}
+	}
+	if d.err != nil {
+		return nil, 0, d.err
+	}
+	m.body = b[bodyStart:total]
+	return m, total, nil
+}
+
+// args returns a decoder for m's body
+func (m *message) args() *decoder {
+	return &decoder{b: m.body, order: m.order}
+}
+
+// asError returns the D-Bus error a reply of type msgError carries
+func (m *message) asError() error {
+	if m.signature != "" && m.signature[0] == 's' {
+		if text := m.args().string(); text != "" {
+			return fmt.Errorf("%s: %s", m.errorName, text)
+		}
+	}
+	return errors.New(m.errorName)
+}
+
+// stringArgs encodes a body of string arguments
+func stringArgs(args ...string) []byte {
+	var e encoder
+	for _, a := range args {
+		e.string(a)
+	}
+	return e.b
+}
+
+// encoder appends D-Bus values to b, little-endian, aligned from the start
+// of b
+type encoder struct {
+	b []byte
+}
+
+func (e *encoder) align(n int) {
+	for len(e.b)%n != 0 {
+		e.b = append(e.b, 0)
+	}
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.align(4)
+	e.b = binary.LittleEndian.AppendUint32(e.b, v)
+}
+
+func (e *encoder) string(s string) {
+	e.uint32(uint32(len(s))) // #nosec G115 -- names and match rules
+	e.b = append(append(e.b, s...), 0)
+}
+
+func (e *encoder) signature(s string) {
+	e.b = append(append(append(e.b, byte(len(s))), s...), 0)
+}
+
+// decoder reads D-Bus values from b, aligned from the start of b. The
+// first error is kept in err and later reads return zero values.
+type decoder struct {
+	b     []byte
+	off   int
+	order binary.ByteOrder
+	err   error
+}
+
+func (d *decoder) align(n int) {
+	d.off = (d.off + n - 1) &^ (n - 1)
+}
+
+// take returns the next n bytes
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.off+n > len(d.b) {
+		d.err = errors.New("truncated D-Bus message")
+		return nil
+	}
+	v := d.b[d.off : d.off+n]
+	d.off += n
+	return v
+}
+
+func (d *decoder) byte() byte {
+	if v := d.take(1); v != nil {
+		return v[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint32() uint32 {
+	d.align(4)
+	if v := d.take(4); v != nil {
+		return d.order.Uint32(v)
+	}
+	return 0
+}
+
+func (d *decoder) bool() bool {
+	return d.uint32() != 0
+}
+
+func (d *decoder) string() string {
+	n := d.uint32()
+	if n > maxMessageSize {
+		d.err = errors.New("D-Bus string too long")
+		return ""
+	}
+	v := d.take(int(n) + 1)
+	if v == nil {
+		return ""
+	}
+	return string(v[:n])
+}
+
+func (d *decoder) signature() string {
+	n := d.byte()
+	v := d.take(int(n) + 1)
+	if v == nil {
+		return ""
+	}
+	return string(v[:n])
+}
@@ -0,0 +1,103 @@
+package logind
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	m := &message{
+		typ:         msgMethodCall,
+		serial:      7,
+		destination: "org.freedesktop.login1",
+		path:        "/org/freedesktop/login1",
+		iface:       "org.freedesktop.login1.Manager",
+		member:      "Inhibit",
+		signature:   "ssss",
+		body:        stringArgs("sleep", "who", "why", "delay"),
+	}
+	b := m.marshal()
+
+	got, n, err := parseMessage(append(b, 0xAA, 0xBB))
+	if err != nil {
+		t.Fatalf("parseMessage failed: %v", err)
+	}
+	if n != len(b) {
+		t.Errorf("message length = %d, want %d", n, len(b))
+	}
+	if got.typ != m.typ || got.serial != m.serial || got.destination != m.destination ||
+		got.path != m.path || got.iface != m.iface || got.member != m.member || got.signature != m.signature {
+		t.Errorf("parsed %+v, want %+v", got, m)
+	}
+	args := got.args()
+	for _, want := range []string{"sleep", "who", "why", "delay"} {
+		if s := args.string(); s != want {
+			t.Errorf("argument = %q, want %q", s, want)
+		}
+	}
+	if args.err != nil {
+		t.Errorf("decoding arguments: %v", args.err)
+	}
+
+	// Partial messages wait for more data
+	for _, cut := range []int{0, 10, 16, len(b) - 1} {
+		if m, _, err := parseMessage(b[:cut]); m != nil || err != nil {
+			t.Errorf("parseMessage of %d bytes = %v, %v, want neither", cut, m, err)
+		}
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	var body encoder
+	body.uint32(1)
+	m := &message{
+		typ:       msgSignal,
+		serial:    3,
+		sender:    ":1.4",
+		path:      "/org/freedesktop/login1",
+		iface:     "org.freedesktop.login1.Manager",
+		member:    "PrepareForSleep",
+		signature: "b",
+		body:      body.b,
+	}
+	got, _, err := parseMessage(m.marshal())
+	if err != nil {
+		t.Fatalf("parseMessage failed: %v", err)
+	}
+	if got.sender != ":1.4" || got.member != "PrepareForSleep" || !got.args().bool() {
+		t.Errorf("parsed %+v", got)
+	}
+}
+
+func TestParseBigEndian(t *testing.T) {
+	// A reply from a big-endian peer with a reply serial and no body
+	b := []byte{'B', msgMethodReturn, 0, 1, 0, 0, 0, 0, 0, 0, 0, 9, 0, 0, 0, 8}
+	b = append(b, fieldReplySerial, 1, 'u', 0)
+	b = binary.BigEndian.AppendUint32(b, 42)
+	got, n, err := parseMessage(b)
+	if err != nil {
+		t.Fatalf("parseMessage failed: %v", err)
+	}
+	if n != len(b) || got.serial != 9 || got.replySerial != 42 {
+		t.Errorf("parsed %+v (%d bytes)", got, n)
+	}
+}
+
+func TestParseMessageErrors(t *testing.T) {
+	for name, b := range map[string][]byte{
+		"byte order": append([]byte{'x'}, make([]byte, 15)...),
+		"bad field":  {'l', msgSignal, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 4, 0, 0, 0, fieldPath, 1, 'v', 0, 0, 0, 0, 0},
+		"truncated":  {'l', msgSignal, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 8, 0, 0, 0, fieldPath, 1, 'o', 0, 200, 0, 0, 0},
+	} {
+		if _, _, err := parseMessage(b); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestAsError(t *testing.T) {
+	m := &message{typ: msgError, errorName: "org.freedesktop.DBus.Error.AccessDenied", signature: "s", body: stringArgs("denied"), order: binary.LittleEndian}
+	if got := m.asError().Error(); got != "org.freedesktop.DBus.Error.AccessDenied: denied" {
+		t.Errorf("asError() = %q", got)
+	}
+}
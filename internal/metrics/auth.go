@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// tlsConfig loads the server certificate, and the client CA when client
+// certificates are required
+func (cfg Config) tlsConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metrics server certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read metrics client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in metrics client CA file")
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// requireAuth wraps next so requests must carry the configured basic auth
+// credentials or bearer token. GET /health stays open for liveness probes,
+// as it reveals nothing. Without credentials configured next is returned
+// as it is.
+func (cfg Config) requireAuth(next http.Handler) http.Handler {
+	if cfg.Username == "" && cfg.Token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" || cfg.authorized(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if cfg.Username != "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="i2c-display"`)
+		}
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// authorized reports whether r carries the configured bearer token or
+// basic auth credentials, compared in constant time
+func (cfg Config) authorized(r *http.Request) bool {
+	if cfg.Token != "" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok &&
+			subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) == 1 {
+			return true
+		}
+	}
+	if cfg.Username != "" {
+		user, pass, ok := r.BasicAuth()
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte(cfg.Username))
+		passOK := subtle.ConstantTimeCompare([]byte(pass), []byte(cfg.Password))
+		if ok && userOK&passOK == 1 {
+			return true
+		}
+	}
+	return false
+}
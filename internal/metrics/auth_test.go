@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+func TestRequireAuth(t *testing.T) {
+	log := logger.NewDefault()
+	server := NewServer(Config{Address: ":0", Username: "admin", Password: "hunter2", Token: "s3cret"}, New(log), log)
+	get := func(path string, auth func(*http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+		if auth != nil {
+			auth(req)
+		}
+		rec := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/metrics", nil)
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("expected 401 with a basic auth challenge, got %d", rec.Code)
+	}
+	if rec := get("/health", nil); rec.Code != http.StatusOK {
+		t.Errorf("expected /health to stay open, got %d", rec.Code)
+	}
+
+	tests := []struct {
+		name string
+		auth func(*http.Request)
+		want int
+	}{
+		{"basic", func(r *http.Request) { r.SetBasicAuth("admin", "hunter2") }, http.StatusOK},
+		{"wrong password", func(r *http.Request) { r.SetBasicAuth("admin", "hunter3") }, http.StatusUnauthorized},
+		{"wrong user", func(r *http.Request) { r.SetBasicAuth("root", "hunter2") }, http.StatusUnauthorized},
+		{"token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }, http.StatusOK},
+		{"wrong token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer guess") }, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if rec := get("/metrics", tt.auth); rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, rec.Code)
+		}
+	}
+}
+
+// testCert is a certificate and key written as PEM files
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// newTestCert creates a certificate signed by parent, or self-signed when
+// parent is nil, and writes it to dir
+func newTestCert(t *testing.T, dir, name string, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &testCert{cert: cert, key: key, certFile: filepath.Join(dir, name+".crt"), keyFile: filepath.Join(dir, name+".key")}
+	if err := os.WriteFile(c.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestServerMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, dir, "ca", nil, true)
+	serverCert := newTestCert(t, dir, "server", ca, false)
+	clientCert := newTestCert(t, dir, "client", ca, false)
+
+	// Find a free port, as the server does not report the one it bound
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	log := logger.NewDefault()
+	server := NewServer(Config{
+		Address:      addr,
+		CertFile:     serverCert.certFile,
+		KeyFile:      serverCert.keyFile,
+		ClientCAFile: ca.certFile,
+	}, New(log), log)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer func() { _ = server.Stop(context.Background()) }()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(certs []tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs, MinVersion: tls.VersionTLS12},
+		}}
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://"+addr+"/health", http.NoBody)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	if err := get(nil); err == nil {
+		t.Error("expected a client without a certificate to be refused")
+	}
+	pair, err := tls.LoadX509KeyPair(clientCert.certFile, clientCert.keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get([]tls.Certificate{pair}); err != nil {
+		t.Errorf("expected a client certificate signed by the CA to be accepted: %v", err)
+	}
+}
+
+func TestServerTLSBadCert(t *testing.T) {
+	log := logger.NewDefault()
+	server := NewServer(Config{
+		Address:  "127.0.0.1:0",
+		CertFile: filepath.Join(t.TempDir(), "missing.crt"),
+		KeyFile:  filepath.Join(t.TempDir(), "missing.key"),
+	}, New(log), log)
+	if err := server.Start(); err == nil {
+		_ = server.Stop(context.Background())
+		t.Error("expected Start() to fail without the certificate")
+	}
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ausil/i2c-display/internal/health"
+)
+
+// healthCollector exports health.Checker component state at scrape time, so
+// the metrics are never staler than /health/details
+type healthCollector struct {
+	checker *health.Checker
+	status  *prometheus.Desc
+	errors  *prometheus.Desc
+}
+
+// healthValue maps a status to the component health gauge value
+func healthValue(s health.Status) float64 {
+	switch s {
+	case health.StatusDegraded:
+		return 1
+	case health.StatusUnhealthy:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func newHealthCollector(checker *health.Checker) *healthCollector {
+	return &healthCollector{
+		checker: checker,
+		status: prometheus.NewDesc(
+			"i2c_display_component_health",
+			"Component health: 0 = healthy, 1 = degraded, 2 = unhealthy",
+			[]string{"component"}, nil,
+		),
+		errors: prometheus.NewDesc(
+			"i2c_display_component_errors_total",
+			"Total number of errors recorded for a component",
+			[]string{"component"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (h *healthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- h.status
+	ch <- h.errors
+}
+
+// Collect implements prometheus.Collector
+func (h *healthCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, comp := range h.checker.GetAllComponents() {
+		ch <- prometheus.MustNewConstMetric(h.status, prometheus.GaugeValue, healthValue(comp.Status), name)
+		ch <- prometheus.MustNewConstMetric(h.errors, prometheus.CounterValue, float64(comp.TotalErrors), name)
+	}
+}
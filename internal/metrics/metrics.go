@@ -2,16 +2,27 @@ package metrics
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/ausil/i2c-display/internal/health"
 	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/timeseries"
+	"github.com/ausil/i2c-display/internal/unixsock"
 )
 
 // Collector holds all Prometheus metrics for the application
@@ -20,9 +31,15 @@ type Collector struct {
 	DisplayRefreshTotal   *prometheus.CounterVec
 	DisplayRefreshErrors  *prometheus.CounterVec
 	DisplayRefreshLatency *prometheus.HistogramVec
+	FramesSkippedTotal    prometheus.Counter
+
+	// Render and collection timing metrics
+	PageRenderDuration *prometheus.HistogramVec
+	CollectDuration    *prometheus.HistogramVec
 
 	// I2C metrics
-	I2CErrorsTotal *prometheus.CounterVec
+	I2CErrorsTotal  *prometheus.CounterVec
+	I2CRetriesTotal *prometheus.CounterVec
 
 	// System metrics
 	CPUTemperature    prometheus.Gauge
@@ -34,14 +51,38 @@ type Collector struct {
 	CurrentPage       prometheus.Gauge
 	PageRotationTotal prometheus.Counter
 
+	// Panel metrics
+	BacklightOnHours prometheus.Gauge
+
+	// Build metadata
+	BuildInfo *prometheus.GaugeVec
+
 	registry *prometheus.Registry
 	log      *logger.Logger
 }
 
+// UnixPrefix marks a metrics server address as a Unix socket path
+const UnixPrefix = "unix:"
+
 // Config holds metrics server configuration
 type Config struct {
 	Enabled bool   `json:"enabled"`
-	Address string `json:"address"` // e.g., "127.0.0.1:9090"
+	Address string `json:"address"` // e.g., "127.0.0.1:9090", or "unix:/run/i2c-display/http.sock"
+	// SocketGroup is given access to a Unix socket address; empty keeps
+	// the daemon's group
+	SocketGroup string `json:"socket_group,omitempty"`
+
+	// TLS serves HTTPS with this certificate and key; with a client CA,
+	// clients must present a certificate it signed
+	CertFile     string `json:"cert_file,omitempty"`
+	KeyFile      string `json:"key_file,omitempty"`
+	ClientCAFile string `json:"client_ca_file,omitempty"`
+
+	// Requests other than GET /health must carry these basic auth
+	// credentials or bearer token, when set
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
 }
 
 // New creates a new metrics collector
@@ -69,7 +110,29 @@ func New(log *logger.Logger) *Collector {
 				Help:    "Histogram of display refresh latencies in seconds",
 				Buckets: prometheus.DefBuckets,
 			},
-			[]string{"page_type"}, // system or network
+			[]string{"page_type"}, // e.g. system, network, load or alert
+		),
+		FramesSkippedTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "i2c_display_frames_skipped_total",
+				Help: "Total number of display flushes skipped because the frame was unchanged",
+			},
+		),
+		PageRenderDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "i2c_display_page_render_duration_seconds",
+				Help:    "Histogram of time spent drawing and flushing a page, by page title",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"page"},
+		),
+		CollectDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "i2c_display_collect_duration_seconds",
+				Help:    "Histogram of time spent reading each stats source",
+				Buckets: prometheus.ExponentialBuckets(0.0001, 4, 8), // 100µs to ~1.6s
+			},
+			[]string{"source"}, // temperature, memory, disk, load or network
 		),
 		I2CErrorsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -78,6 +141,13 @@ func New(log *logger.Logger) *Collector {
 			},
 			[]string{"operation"}, // init, show, etc.
 		),
+		I2CRetriesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "i2c_display_i2c_retries_total",
+				Help: "Total number of display operations retried after a transient bus error",
+			},
+			[]string{"operation"}, // init or show
+		),
 		CPUTemperature: prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "i2c_display_cpu_temperature_celsius",
@@ -114,6 +184,19 @@ func New(log *logger.Logger) *Collector {
 				Help: "Total number of page rotations",
 			},
 		),
+		BacklightOnHours: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "i2c_display_backlight_on_hours",
+				Help: "Cumulative hours the panel backlight has been on",
+			},
+		),
+		BuildInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "i2c_display_build_info",
+				Help: "Build information; always 1",
+			},
+			[]string{"version", "commit"},
+		),
 		registry: registry,
 		log:      log,
 	}
@@ -123,13 +206,19 @@ func New(log *logger.Logger) *Collector {
 		c.DisplayRefreshTotal,
 		c.DisplayRefreshErrors,
 		c.DisplayRefreshLatency,
+		c.FramesSkippedTotal,
+		c.PageRenderDuration,
+		c.CollectDuration,
 		c.I2CErrorsTotal,
+		c.I2CRetriesTotal,
 		c.CPUTemperature,
 		c.MemoryUsedPercent,
 		c.DiskUsedPercent,
 		c.NetworkInterfaces,
 		c.CurrentPage,
 		c.PageRotationTotal,
+		c.BacklightOnHours,
+		c.BuildInfo,
 	)
 
 	return c
@@ -145,6 +234,27 @@ func (c *Collector) RecordDisplayRefresh(success bool, duration time.Duration, p
 	c.DisplayRefreshLatency.WithLabelValues(pageType).Observe(duration.Seconds())
 }
 
+// RecordFrameSkipped records a flush skipped because the frame was unchanged
+func (c *Collector) RecordFrameSkipped() {
+	c.FramesSkippedTotal.Inc()
+}
+
+// RecordPageRender records how long drawing a page took
+func (c *Collector) RecordPageRender(page string, duration time.Duration) {
+	c.PageRenderDuration.WithLabelValues(page).Observe(duration.Seconds())
+}
+
+// RecordCollectDuration records how long reading a stats source took
+func (c *Collector) RecordCollectDuration(source string, duration time.Duration) {
+	c.CollectDuration.WithLabelValues(source).Observe(duration.Seconds())
+}
+
+// SetBuildInfo publishes the running version and commit
+func (c *Collector) SetBuildInfo(version, commit string) {
+	c.BuildInfo.Reset()
+	c.BuildInfo.WithLabelValues(version, commit).Set(1)
+}
+
 // RecordDisplayError records a display error
 func (c *Collector) RecordDisplayError(errorType string) {
 	c.DisplayRefreshErrors.WithLabelValues(errorType).Inc()
@@ -155,6 +265,15 @@ func (c *Collector) RecordI2CError(operation string) {
 	c.I2CErrorsTotal.WithLabelValues(operation).Inc()
 }
 
+// RecordI2CAttemptFailed records a failed attempt of a display operation,
+// and whether it is retried
+func (c *Collector) RecordI2CAttemptFailed(operation string, retrying bool) {
+	c.RecordI2CError(operation)
+	if retrying {
+		c.I2CRetriesTotal.WithLabelValues(operation).Inc()
+	}
+}
+
 // UpdateSystemMetrics updates system stat metrics
 func (c *Collector) UpdateSystemMetrics(cpuTemp, memPercent, diskPercent float64, interfaceCount int) {
 	if cpuTemp > 0 {
@@ -171,12 +290,86 @@ func (c *Collector) RecordPageRotation(pageNum int) {
 	c.CurrentPage.Set(float64(pageNum))
 }
 
+// SetBacklightOnHours records cumulative backlight on-time
+func (c *Collector) SetBacklightOnHours(hours float64) {
+	c.BacklightOnHours.Set(hours)
+}
+
+// RegisterHealthChecker exports the checker's component statuses and error
+// totals. Must be called at most once.
+func (c *Collector) RegisterHealthChecker(h *health.Checker) {
+	c.registry.MustRegister(newHealthCollector(h))
+}
+
 // Server wraps the HTTP server for metrics
 type Server struct {
 	httpServer *http.Server
+	cfg        Config
 	log        *logger.Logger
 	mu         sync.Mutex
 	wakeFunc   func()
+	checker    *health.Checker
+	rotation   RotationControl
+	calibrate  CalibrationControl
+	history    HistorySource
+	showMsg    func(text, size, color string, d time.Duration) error
+}
+
+// SetMessageHandler registers a function to call when POST /api/message is
+// received. Errors it returns are reported to the client as bad requests.
+func (s *Server) SetMessageHandler(fn func(text, size, color string, d time.Duration) error) {
+	s.mu.Lock()
+	s.showMsg = fn
+	s.mu.Unlock()
+}
+
+// RotationControl is the page rotation control served by POST /pause,
+// /resume and /hold (implemented by rotation.Manager)
+type RotationControl interface {
+	Pause()
+	Resume()
+	HoldPage(idx int, d time.Duration) error
+	PageIndex(pageType string) int
+}
+
+// SetRotationControl registers the rotation manager served by POST /pause,
+// /resume and /hold.
+func (s *Server) SetRotationControl(rc RotationControl) {
+	s.mu.Lock()
+	s.rotation = rc
+	s.mu.Unlock()
+}
+
+// CalibrationControl is the contrast and gamma calibration served by
+// /api/calibration (implemented by calibration.Calibrator)
+type CalibrationControl interface {
+	Start()
+	Adjust(contrast int, gamma float64) error
+	Save() error
+	Cancel() error
+	Active() bool
+	Values() (int, float64)
+}
+
+// SetCalibrationControl registers the calibrator served by /api/calibration.
+func (s *Server) SetCalibrationControl(cc CalibrationControl) {
+	s.mu.Lock()
+	s.calibrate = cc
+	s.mu.Unlock()
+}
+
+// HistorySource is the store of recent metric samples served by
+// GET /api/history (implemented by timeseries.Store)
+type HistorySource interface {
+	Metrics() []string
+	Samples(metric string) []timeseries.Sample
+}
+
+// SetHistory registers the metric history served by GET /api/history.
+func (s *Server) SetHistory(h HistorySource) {
+	s.mu.Lock()
+	s.history = h
+	s.mu.Unlock()
 }
 
 // SetWakeHandler registers a function to call when POST /wake is received.
@@ -186,9 +379,16 @@ func (s *Server) SetWakeHandler(fn func()) {
 	s.mu.Unlock()
 }
 
+// SetHealthChecker registers the checker served by GET /health/details.
+func (s *Server) SetHealthChecker(h *health.Checker) {
+	s.mu.Lock()
+	s.checker = h
+	s.mu.Unlock()
+}
+
 // NewServer creates a new metrics HTTP server
 func NewServer(cfg Config, collector *Collector, log *logger.Logger) *Server {
-	s := &Server{log: log}
+	s := &Server{log: log, cfg: cfg}
 
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.HandlerFor(collector.registry, promhttp.HandlerOpts{}))
@@ -196,6 +396,15 @@ func NewServer(cfg Config, collector *Collector, log *logger.Logger) *Server {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("OK\n"))
 	})
+	mux.HandleFunc("/health/details", s.handleHealthDetails)
+	mux.HandleFunc("/pause", s.handlePause)
+	mux.HandleFunc("/resume", s.handleResume)
+	mux.HandleFunc("/hold", s.handleHold)
+	mux.HandleFunc("/api/loglevel", s.handleLogLevel)
+	mux.HandleFunc("/api/message", s.handleMessage)
+	mux.HandleFunc("/api/history", s.handleHistory)
+	mux.HandleFunc("/api/calibration", s.handleCalibration)
+	mux.HandleFunc("/api/calibration/{action}", s.handleCalibrationAction)
 	mux.HandleFunc("/wake", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
@@ -215,7 +424,7 @@ func NewServer(cfg Config, collector *Collector, log *logger.Logger) *Server {
 
 	s.httpServer = &http.Server{
 		Addr:         cfg.Address,
-		Handler:      mux,
+		Handler:      cfg.requireAuth(mux),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -223,16 +432,369 @@ func NewServer(cfg Config, collector *Collector, log *logger.Logger) *Server {
 	return s
 }
 
+// handleHealthDetails serves the component health snapshot as JSON. The
+// status code is 503 when the service is unhealthy, 200 otherwise.
+func (s *Server) handleHealthDetails(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.Lock()
+	checker := s.checker
+	s.mu.Unlock()
+	if checker == nil {
+		http.Error(w, "health checking not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	report := checker.Snapshot()
+	status := http.StatusOK
+	if report.Status == health.StatusUnhealthy {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		s.log.ErrorWithErr(err, "Failed to encode health report")
+	}
+}
+
+// History is the body of GET /api/history: each metric's samples, oldest
+// first
+type History struct {
+	Metrics map[string][]timeseries.Sample `json:"metrics"`
+}
+
+// handleHistory serves the recent samples of every metric, or of the one
+// named by ?metric=. ?points=N averages each metric down to at most N
+// samples, e.g. one per pixel of a chart.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.Lock()
+	history := s.history
+	s.mu.Unlock()
+	if history == nil {
+		http.Error(w, "history not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	points := 0
+	if v := r.URL.Query().Get("points"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, fmt.Sprintf("points must be a positive integer, got %q", v), http.StatusBadRequest)
+			return
+		}
+		points = n
+	}
+	names := history.Metrics()
+	if metric := r.URL.Query().Get("metric"); metric != "" {
+		if !slices.Contains(names, metric) {
+			http.Error(w, fmt.Sprintf("no history of metric %q", metric), http.StatusNotFound)
+			return
+		}
+		names = []string{metric}
+	}
+
+	resp := History{Metrics: make(map[string][]timeseries.Sample, len(names))}
+	for _, name := range names {
+		resp.Metrics[name] = timeseries.Downsample(history.Samples(name), points)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		s.log.ErrorWithErr(err, "Failed to encode history")
+	}
+}
+
+// rotationControl returns the registered rotation control, replying 405 or
+// 503 and returning nil when the request cannot be served
+func (s *Server) rotationControl(w http.ResponseWriter, r *http.Request) RotationControl {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return nil
+	}
+	s.mu.Lock()
+	rc := s.rotation
+	s.mu.Unlock()
+	if rc == nil {
+		http.Error(w, "rotation control not available", http.StatusServiceUnavailable)
+		return nil
+	}
+	return rc
+}
+
+// handlePause stops page rotation on the current page
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	rc := s.rotationControl(w, r)
+	if rc == nil {
+		return
+	}
+	rc.Pause()
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK\n"))
+}
+
+// handleResume restarts page rotation
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	rc := s.rotationControl(w, r)
+	if rc == nil {
+		return
+	}
+	rc.Resume()
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK\n"))
+}
+
+// HoldRequest is the JSON body of POST /hold. The page is chosen by index or,
+// when Type is set, as the first page of that type ("system", "network", ...).
+// Duration is optional; without it the page is held until POST /resume.
+type HoldRequest struct {
+	Page     int    `json:"page"`
+	Type     string `json:"type,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// handleHold switches to a page and holds it
+func (s *Server) handleHold(w http.ResponseWriter, r *http.Request) {
+	rc := s.rotationControl(w, r)
+	if rc == nil {
+		return
+	}
+
+	var req HoldRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	var d time.Duration
+	if req.Duration != "" {
+		var err error
+		if d, err = time.ParseDuration(req.Duration); err != nil {
+			http.Error(w, fmt.Sprintf("invalid duration: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	idx := req.Page
+	if req.Type != "" {
+		if idx = rc.PageIndex(req.Type); idx < 0 {
+			http.Error(w, fmt.Sprintf("no %q page in rotation", req.Type), http.StatusNotFound)
+			return
+		}
+	}
+	if err := rc.HoldPage(idx, d); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK\n"))
+}
+
+// defaultMessageDuration is how long a message is shown without a duration
+const defaultMessageDuration = 30 * time.Second
+
+// MessageRequest is the JSON body of POST /api/message. FontSize is "small",
+// "normal" (the default) or "large"; Color is a name such as "red" or a
+// "#rrggbb" value, white by default.
+type MessageRequest struct {
+	Text     string `json:"text"`
+	Duration string `json:"duration,omitempty"` // default 30s
+	FontSize string `json:"font_size,omitempty"`
+	Color    string `json:"color,omitempty"`
+}
+
+// handleMessage shows a temporary message in place of page rotation
+func (s *Server) handleMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.Lock()
+	fn := s.showMsg
+	s.mu.Unlock()
+	if fn == nil {
+		http.Error(w, "messages not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req MessageRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		http.Error(w, "text is required", http.StatusBadRequest)
+		return
+	}
+	d := defaultMessageDuration
+	if req.Duration != "" {
+		var err error
+		if d, err = time.ParseDuration(req.Duration); err != nil {
+			http.Error(w, fmt.Sprintf("invalid duration: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := fn(req.Text, req.FontSize, req.Color, d); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK\n"))
+}
+
+// Calibration is the JSON body of GET and PUT /api/calibration. A PUT may
+// leave out either value to keep it; Active is ignored.
+type Calibration struct {
+	Active   bool    `json:"active"`
+	Contrast int     `json:"contrast"`
+	Gamma    float64 `json:"gamma"`
+}
+
+// calibrationControl returns the registered calibrator, replying 503 and
+// returning nil when there is none
+func (s *Server) calibrationControl(w http.ResponseWriter) CalibrationControl {
+	s.mu.Lock()
+	cc := s.calibrate
+	s.mu.Unlock()
+	if cc == nil {
+		http.Error(w, "calibration not available", http.StatusServiceUnavailable)
+	}
+	return cc
+}
+
+// handleCalibration reports the calibration on GET. PUT changes it,
+// starting calibration if it is not running, so the test image is shown
+// while the values are tuned.
+func (s *Server) handleCalibration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cc := s.calibrationControl(w)
+	if cc == nil {
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		var req Calibration
+		req.Contrast, req.Gamma = cc.Values()
+		if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		cc.Start()
+		if err := cc.Adjust(req.Contrast, req.Gamma); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp := Calibration{Active: cc.Active()}
+	resp.Contrast, resp.Gamma = cc.Values()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		s.log.ErrorWithErr(err, "Failed to encode calibration")
+	}
+}
+
+// handleCalibrationAction serves POST /api/calibration/start, which shows
+// the test image, /save, which writes the values into the configuration
+// file, and /cancel, which restores the values calibration started from
+func (s *Server) handleCalibrationAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cc := s.calibrationControl(w)
+	if cc == nil {
+		return
+	}
+
+	action := r.PathValue("action")
+	var err error
+	switch action {
+	case "start":
+		cc.Start()
+	case "save", "cancel":
+		if !cc.Active() {
+			http.Error(w, "calibration is not active", http.StatusConflict)
+			return
+		}
+		if action == "save" {
+			err = cc.Save()
+		} else {
+			err = cc.Cancel()
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK\n"))
+}
+
+// LogLevel is the JSON body of GET and PUT /api/loglevel
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// handleLogLevel reports the log level on GET and changes it on PUT. The
+// change lasts until restart or a SIGHUP that changes the logging config.
+func (s *Server) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req LogLevel
+		if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		old := logger.Level()
+		if err := logger.SetLevel(req.Level); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.log.With().Str("from", old).Str("to", logger.Level()).Logger().Warn("Log level changed over HTTP")
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(LogLevel{Level: logger.Level()}); err != nil {
+		s.log.ErrorWithErr(err, "Failed to encode log level")
+	}
+}
+
 // Start starts the metrics server. It binds the listening socket synchronously
 // so that any address/port errors are returned immediately rather than being
 // silently swallowed inside a goroutine.
 func (s *Server) Start() error {
-	ln, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", s.httpServer.Addr)
+	ln, err := s.listen()
 	if err != nil {
 		return fmt.Errorf("metrics server failed to bind %s: %w", s.httpServer.Addr, err)
 	}
+	if s.cfg.CertFile != "" {
+		tlsConfig, err := s.cfg.tlsConfig()
+		if err != nil {
+			_ = ln.Close()
+			return err
+		}
+		ln = tls.NewListener(ln, tlsConfig)
+	}
 
-	s.log.With().Str("address", s.httpServer.Addr).Logger().Info("Starting metrics server")
+	s.log.With().Str("address", s.httpServer.Addr).
+		Bool("tls", s.cfg.CertFile != "").
+		Bool("client_certs", s.cfg.ClientCAFile != "").
+		Bool("auth", s.cfg.Username != "" || s.cfg.Token != "").
+		Logger().Info("Starting metrics server")
 
 	go func() {
 		if err := s.httpServer.Serve(ln); err != nil && err != http.ErrServerClosed {
@@ -243,10 +805,31 @@ func (s *Server) Start() error {
 	return nil
 }
 
-// Stop gracefully stops the metrics server
+// listen opens the server's TCP port, or its Unix socket for an address of
+// the form "unix:/path", restricted to its owner and SocketGroup
+func (s *Server) listen() (net.Listener, error) {
+	path, ok := strings.CutPrefix(s.httpServer.Addr, UnixPrefix)
+	if !ok {
+		return (&net.ListenConfig{}).Listen(context.Background(), "tcp", s.httpServer.Addr)
+	}
+
+	// Reverse proxies running as another user must reach the socket
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { // #nosec G301 -- the socket itself is restricted
+		return nil, err
+	}
+	return unixsock.Listen(path, s.cfg.SocketGroup)
+}
+
+// Stop gracefully stops the metrics server and removes its Unix socket
 func (s *Server) Stop(ctx context.Context) error {
 	s.log.Info("Stopping metrics server")
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+	if path, ok := strings.CutPrefix(s.httpServer.Addr, UnixPrefix); ok {
+		if rmErr := unixsock.Remove(path); rmErr != nil && err == nil {
+			err = rmErr
+		}
+	}
+	return err
 }
 
 // StartMetricsServer starts the Prometheus metrics server if enabled
@@ -2,13 +2,23 @@ package metrics
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ausil/i2c-display/internal/health"
 	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/timeseries"
 )
 
 func TestNew(t *testing.T) {
@@ -136,6 +146,21 @@ func TestRecordI2CError(t *testing.T) {
 	}
 }
 
+func TestRecordI2CAttemptFailed(t *testing.T) {
+	log := logger.NewDefault()
+	collector := New(log)
+
+	collector.RecordI2CAttemptFailed("show", true)
+	collector.RecordI2CAttemptFailed("show", false)
+
+	if got := testutil.ToFloat64(collector.I2CErrorsTotal.WithLabelValues("show")); got != 2 {
+		t.Errorf("expected 2 errors, got %v", got)
+	}
+	if got := testutil.ToFloat64(collector.I2CRetriesTotal.WithLabelValues("show")); got != 1 {
+		t.Errorf("expected 1 retry, got %v", got)
+	}
+}
+
 func TestUpdateSystemMetrics(t *testing.T) {
 	log := logger.NewDefault()
 	collector := New(log)
@@ -199,6 +224,35 @@ func TestRecordPageRotation(t *testing.T) {
 	}
 }
 
+func TestRecordTimings(t *testing.T) {
+	collector := New(logger.NewDefault())
+	collector.RecordPageRender("System", 20*time.Millisecond)
+	collector.RecordCollectDuration("disk", time.Millisecond)
+	collector.RecordCollectDuration("disk", 2*time.Millisecond)
+
+	if got := testutil.CollectAndCount(collector.PageRenderDuration, "i2c_display_page_render_duration_seconds"); got != 1 {
+		t.Errorf("expected 1 page render series, got %d", got)
+	}
+	if got := testutil.CollectAndCount(collector.CollectDuration, "i2c_display_collect_duration_seconds"); got != 1 {
+		t.Errorf("expected 1 collect duration series, got %d", got)
+	}
+}
+
+func TestSetBuildInfo(t *testing.T) {
+	collector := New(logger.NewDefault())
+	collector.SetBuildInfo("0.1.0", "abc123")
+	collector.SetBuildInfo("0.2.0", "def456")
+
+	expected := `
+# HELP i2c_display_build_info Build information; always 1
+# TYPE i2c_display_build_info gauge
+i2c_display_build_info{commit="def456",version="0.2.0"} 1
+`
+	if err := testutil.CollectAndCompare(collector.BuildInfo, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestNewServer(t *testing.T) {
 	log := logger.NewDefault()
 	collector := New(log)
@@ -279,6 +333,74 @@ func TestServerStartStop(t *testing.T) {
 	}
 }
 
+func TestServerUnixSocket(t *testing.T) {
+	log := logger.NewDefault()
+	path := filepath.Join(t.TempDir(), "run", "http.sock")
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	// A regular file at the path is never removed
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(Config{Enabled: true, Address: UnixPrefix + path}, New(log), log)
+	if err := server.Start(); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Fatalf("expected a regular file refused, got %v", err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// A socket left by a killed daemon is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	// One still being listened on is not
+	other := NewServer(Config{Enabled: true, Address: UnixPrefix + path}, New(log), log)
+	if err := other.Start(); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("expected a live socket refused, got %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != 0o660 {
+		t.Errorf("expected a 0660 socket at %s, got %v (%v)", path, info.Mode(), err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://i2c-display/health", http.NoBody)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to GET /health over the socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Stop(ctx); err != nil {
+		t.Fatalf("Failed to stop server: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected Stop to remove the socket, got %v", err)
+	}
+}
+
 func TestMetricsEndpoint(t *testing.T) {
 	log := logger.NewDefault()
 	collector := New(log)
@@ -530,3 +652,425 @@ func TestMultipleMetricRecords(t *testing.T) {
 
 	// If no panics occurred, the test passes
 }
+
+func TestSetBacklightOnHours(t *testing.T) {
+	collector := New(logger.NewDefault())
+	collector.SetBacklightOnHours(12.5)
+
+	if got := testutil.ToFloat64(collector.BacklightOnHours); got != 12.5 {
+		t.Errorf("expected 12.5 backlight hours, got %f", got)
+	}
+}
+
+func TestRecordFrameSkipped(t *testing.T) {
+	collector := New(logger.NewDefault())
+	collector.RecordFrameSkipped()
+	collector.RecordFrameSkipped()
+
+	if got := testutil.ToFloat64(collector.FramesSkippedTotal); got != 2 {
+		t.Errorf("expected 2 skipped frames, got %f", got)
+	}
+}
+
+func TestRegisterHealthChecker(t *testing.T) {
+	collector := New(logger.NewDefault())
+	checker := health.New()
+	checker.RegisterComponent(health.ComponentDisplay)
+	checker.RegisterComponent(health.ComponentCollector)
+	collector.RegisterHealthChecker(checker)
+
+	for i := 0; i < 3; i++ {
+		checker.RecordError(health.ComponentDisplay, errors.New("i2c write failed"))
+	}
+	checker.MarkUnhealthy(health.ComponentCollector, errors.New("no sensors"))
+
+	expected := `
+# HELP i2c_display_component_errors_total Total number of errors recorded for a component
+# TYPE i2c_display_component_errors_total counter
+i2c_display_component_errors_total{component="collector"} 1
+i2c_display_component_errors_total{component="display"} 3
+# HELP i2c_display_component_health Component health: 0 = healthy, 1 = degraded, 2 = unhealthy
+# TYPE i2c_display_component_health gauge
+i2c_display_component_health{component="collector"} 2
+i2c_display_component_health{component="display"} 1
+`
+	if err := testutil.GatherAndCompare(collector.registry, strings.NewReader(expected),
+		"i2c_display_component_health", "i2c_display_component_errors_total"); err != nil {
+		t.Error(err)
+	}
+
+	// Recovery lowers the gauge but never the error total
+	for i := 0; i < 3; i++ {
+		checker.RecordSuccess(health.ComponentDisplay)
+	}
+	expected = strings.Replace(expected, `{component="display"} 1`, `{component="display"} 0`, 1)
+	if err := testutil.GatherAndCompare(collector.registry, strings.NewReader(expected),
+		"i2c_display_component_health", "i2c_display_component_errors_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHealthDetailsEndpoint(t *testing.T) {
+	log := logger.NewDefault()
+	server := NewServer(Config{Address: ":0"}, New(log), log)
+
+	// Without a checker the endpoint is unavailable
+	rec := httptest.NewRecorder()
+	server.handleHealthDetails(rec, httptest.NewRequest(http.MethodGet, "/health/details", http.NoBody))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without checker, got %d", rec.Code)
+	}
+
+	checker := health.New()
+	checker.RegisterComponent(health.ComponentDisplay)
+	server.SetHealthChecker(checker)
+
+	rec = httptest.NewRecorder()
+	server.handleHealthDetails(rec, httptest.NewRequest(http.MethodGet, "/health/details", http.NoBody))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 when healthy, got %d", rec.Code)
+	}
+	var report health.Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if report.Status != health.StatusHealthy || report.Components[health.ComponentDisplay] == nil {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	checker.MarkUnhealthy(health.ComponentDisplay, errors.New("display gone"))
+	rec = httptest.NewRecorder()
+	server.handleHealthDetails(rec, httptest.NewRequest(http.MethodGet, "/health/details", http.NoBody))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 when unhealthy, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleHealthDetails(rec, httptest.NewRequest(http.MethodPost, "/health/details", http.NoBody))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", rec.Code)
+	}
+}
+
+func TestHistoryEndpoint(t *testing.T) {
+	log := logger.NewDefault()
+	server := NewServer(Config{Address: ":0"}, New(log), log)
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, http.NoBody))
+		return rec
+	}
+
+	if rec := get("/api/history"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without a history, got %d", rec.Code)
+	}
+
+	store := timeseries.New(10, 0)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 4 {
+		store.Add(timeseries.MetricLoad, start.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	store.Add(timeseries.MetricCPUTemp, start, 45)
+	server.SetHistory(store)
+
+	rec := get("/api/history")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var all History
+	if err := json.Unmarshal(rec.Body.Bytes(), &all); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if len(all.Metrics) != 2 || len(all.Metrics[timeseries.MetricLoad]) != 4 {
+		t.Errorf("unexpected history %+v", all)
+	}
+
+	rec = get("/api/history?metric=load&points=2")
+	var load History
+	if err := json.Unmarshal(rec.Body.Bytes(), &load); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	samples := load.Metrics[timeseries.MetricLoad]
+	if len(load.Metrics) != 1 || len(samples) != 2 || samples[0].Value != 0.5 || samples[1].Value != 2.5 {
+		t.Errorf("expected load averaged to two points, got %+v", load)
+	}
+
+	for target, want := range map[string]int{
+		"/api/history?metric=gpu_temp": http.StatusNotFound,
+		"/api/history?points=0":        http.StatusBadRequest,
+	} {
+		if rec := get(target); rec.Code != want {
+			t.Errorf("GET %s: expected %d, got %d", target, want, rec.Code)
+		}
+	}
+}
+
+// fakeRotation records rotation control calls
+type fakeRotation struct {
+	paused   bool
+	heldPage int
+	heldFor  time.Duration
+}
+
+func (f *fakeRotation) Pause()  { f.paused = true }
+func (f *fakeRotation) Resume() { f.paused = false }
+
+func (f *fakeRotation) HoldPage(idx int, d time.Duration) error {
+	if idx > 3 {
+		return errors.New("invalid page index")
+	}
+	f.heldPage, f.heldFor = idx, d
+	return nil
+}
+
+func (f *fakeRotation) PageIndex(pageType string) int {
+	if pageType == "network" {
+		return 2
+	}
+	return -1
+}
+
+func TestRotationControlEndpoints(t *testing.T) {
+	log := logger.NewDefault()
+	server := NewServer(Config{Address: ":0"}, New(log), log)
+
+	post := func(handler http.HandlerFunc, body string) int {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	// Without a manager the endpoints are unavailable
+	if code := post(server.handlePause, ""); code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without rotation control, got %d", code)
+	}
+
+	rot := &fakeRotation{heldPage: -1}
+	server.SetRotationControl(rot)
+
+	if code := post(server.handlePause, ""); code != http.StatusOK || !rot.paused {
+		t.Errorf("POST /pause: code %d, paused %v", code, rot.paused)
+	}
+	if code := post(server.handleResume, ""); code != http.StatusOK || rot.paused {
+		t.Errorf("POST /resume: code %d, paused %v", code, rot.paused)
+	}
+
+	if code := post(server.handleHold, `{"type": "network", "duration": "5m"}`); code != http.StatusOK {
+		t.Errorf("POST /hold by type: expected 200, got %d", code)
+	}
+	if rot.heldPage != 2 || rot.heldFor != 5*time.Minute {
+		t.Errorf("expected hold on page 2 for 5m, got page %d for %s", rot.heldPage, rot.heldFor)
+	}
+	if code := post(server.handleHold, `{"page": 1}`); code != http.StatusOK || rot.heldPage != 1 || rot.heldFor != 0 {
+		t.Errorf("POST /hold by index: code %d, page %d, duration %s", code, rot.heldPage, rot.heldFor)
+	}
+
+	tests := []struct {
+		body string
+		code int
+	}{
+		{`not json`, http.StatusBadRequest},
+		{`{"page": 1, "duration": "soon"}`, http.StatusBadRequest},
+		{`{"type": "weather"}`, http.StatusNotFound},
+		{`{"page": 9}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if code := post(server.handleHold, tt.body); code != tt.code {
+			t.Errorf("POST /hold %s: expected %d, got %d", tt.body, tt.code, code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleHold(rec, httptest.NewRequest(http.MethodGet, "/hold", http.NoBody))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET /hold, got %d", rec.Code)
+	}
+}
+
+func TestLogLevelEndpoint(t *testing.T) {
+	log := logger.NewDefault()
+	server := NewServer(Config{Address: ":0"}, New(log), log)
+	defer func() { _ = logger.SetLevel("info") }()
+
+	do := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.handleLogLevel(rec, httptest.NewRequest(method, "/api/loglevel", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPut, `{"level": "debug"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT /api/loglevel: expected 200, got %d", rec.Code)
+	}
+	var got LogLevel
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Level != "debug" || logger.Level() != "debug" {
+		t.Errorf("expected level debug, response %q, logger %q", got.Level, logger.Level())
+	}
+
+	if rec := do(http.MethodGet, ""); !strings.Contains(rec.Body.String(), `"debug"`) {
+		t.Errorf("GET /api/loglevel: expected debug, got %s", rec.Body.String())
+	}
+
+	tests := []struct {
+		method, body string
+		code         int
+	}{
+		{http.MethodPut, `{"level": "chatty"}`, http.StatusBadRequest},
+		{http.MethodPut, `not json`, http.StatusBadRequest},
+		{http.MethodPost, `{"level": "info"}`, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		if rec := do(tt.method, tt.body); rec.Code != tt.code {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.body, tt.code, rec.Code)
+		}
+	}
+	if logger.Level() != "debug" {
+		t.Errorf("expected rejected requests to leave the level at debug, got %s", logger.Level())
+	}
+}
+
+func TestMessageEndpoint(t *testing.T) {
+	log := logger.NewDefault()
+	server := NewServer(Config{Address: ":0"}, New(log), log)
+
+	post := func(body string) int {
+		rec := httptest.NewRecorder()
+		server.handleMessage(rec, httptest.NewRequest(http.MethodPost, "/api/message", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	// Without a handler messages are unavailable
+	if code := post(`{"text": "hi"}`); code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without handler, got %d", code)
+	}
+
+	var gotText, gotSize, gotColor string
+	var gotDuration time.Duration
+	server.SetMessageHandler(func(text, size, color string, d time.Duration) error {
+		if color == "mauve" {
+			return errors.New("invalid colour")
+		}
+		gotText, gotSize, gotColor, gotDuration = text, size, color, d
+		return nil
+	})
+
+	if code := post(`{"text": "backup done"}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if gotText != "backup done" || gotDuration != defaultMessageDuration {
+		t.Errorf("expected default duration, got %q for %s", gotText, gotDuration)
+	}
+	if code := post(`{"text": "disk full", "duration": "2m", "font_size": "large", "color": "red"}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if gotSize != "large" || gotColor != "red" || gotDuration != 2*time.Minute {
+		t.Errorf("expected large red for 2m, got %q %q for %s", gotSize, gotColor, gotDuration)
+	}
+
+	tests := []struct {
+		body string
+		code int
+	}{
+		{`not json`, http.StatusBadRequest},
+		{`{"text": "  "}`, http.StatusBadRequest},
+		{`{"text": "hi", "duration": "later"}`, http.StatusBadRequest},
+		{`{"text": "hi", "color": "mauve"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if code := post(tt.body); code != tt.code {
+			t.Errorf("POST /api/message %s: expected %d, got %d", tt.body, tt.code, code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleMessage(rec, httptest.NewRequest(http.MethodGet, "/api/message", http.NoBody))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET /api/message, got %d", rec.Code)
+	}
+}
+
+// fakeCalibration records calibration requests
+type fakeCalibration struct {
+	active         bool
+	contrast       int
+	gamma          float64
+	saved, stopped bool
+}
+
+func (c *fakeCalibration) Start()                 { c.active = true }
+func (c *fakeCalibration) Active() bool           { return c.active }
+func (c *fakeCalibration) Values() (int, float64) { return c.contrast, c.gamma }
+func (c *fakeCalibration) Save() error            { c.active, c.saved = false, true; return nil }
+func (c *fakeCalibration) Cancel() error          { c.active, c.stopped = false, true; return nil }
+
+func (c *fakeCalibration) Adjust(contrast int, gamma float64) error {
+	if contrast > 255 {
+		return errors.New("contrast out of range")
+	}
+	c.contrast, c.gamma = contrast, gamma
+	return nil
+}
+
+func TestCalibrationEndpoints(t *testing.T) {
+	log := logger.NewDefault()
+	server := NewServer(Config{Address: ":0"}, New(log), log)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	// Without a calibrator calibration is unavailable
+	if rec := do(http.MethodGet, "/api/calibration", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without calibrator, got %d", rec.Code)
+	}
+
+	cal := &fakeCalibration{contrast: 255, gamma: 1}
+	server.SetCalibrationControl(cal)
+
+	// A PUT starts calibration and keeps values left out
+	rec := do(http.MethodPut, "/api/calibration", `{"gamma": 1.8}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT /api/calibration: expected 200, got %d", rec.Code)
+	}
+	var got Calibration
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got != (Calibration{Active: true, Contrast: 255, Gamma: 1.8}) {
+		t.Errorf("PUT /api/calibration = %+v", got)
+	}
+
+	if rec := do(http.MethodPost, "/api/calibration/save", ""); rec.Code != http.StatusOK || !cal.saved {
+		t.Errorf("POST /api/calibration/save: code %d, saved %v", rec.Code, cal.saved)
+	}
+	if rec := do(http.MethodPost, "/api/calibration/cancel", ""); rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 cancelling while not calibrating, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/calibration/start", ""); rec.Code != http.StatusOK || !cal.active {
+		t.Errorf("POST /api/calibration/start: code %d, active %v", rec.Code, cal.active)
+	}
+	if rec := do(http.MethodPost, "/api/calibration/cancel", ""); rec.Code != http.StatusOK || !cal.stopped {
+		t.Errorf("POST /api/calibration/cancel: code %d, cancelled %v", rec.Code, cal.stopped)
+	}
+
+	tests := []struct {
+		method, path, body string
+		code               int
+	}{
+		{http.MethodPut, "/api/calibration", `not json`, http.StatusBadRequest},
+		{http.MethodPut, "/api/calibration", `{"contrast": 300}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/calibration", ``, http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/calibration/save", ``, http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/calibration/reset", ``, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rec := do(tt.method, tt.path, tt.body); rec.Code != tt.code {
+			t.Errorf("%s %s %s: expected %d, got %d", tt.method, tt.path, tt.body, tt.code, rec.Code)
+		}
+	}
+}
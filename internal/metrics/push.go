@@ -0,0 +1,346 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// Push protocols
+const (
+	PushRemoteWrite = "remote_write" // Prometheus remote write to URL
+	PushStatsd      = "statsd"       // statsd gauges with DogStatsD tags to Address over UDP
+	PushInflux      = "influx"       // InfluxDB line protocol to URL
+)
+
+// statsdPacketSize keeps statsd packets within an Ethernet frame
+const statsdPacketSize = 1432
+
+// PushConfig configures pushing the metrics to a collector
+type PushConfig struct {
+	Protocol string
+	URL      string            // remote_write and influx endpoint
+	Address  string            // statsd host:port
+	Interval time.Duration     // time between pushes
+	Headers  map[string]string // sent with HTTP pushes
+	Labels   map[string]string // added to every metric that lacks them
+}
+
+// Pusher periodically sends the collector's metrics to a remote_write,
+// statsd or InfluxDB endpoint, for deployments without a scraping
+// Prometheus
+type Pusher struct {
+	cfg      PushConfig
+	gatherer prometheus.Gatherer
+	client   *http.Client
+	log      *logger.Logger
+	failing  bool // the last push failed; only Run touches it
+}
+
+// NewPusher creates a pusher of the collector's metrics
+func NewPusher(cfg PushConfig, collector *Collector, log *logger.Logger) *Pusher {
+	return &Pusher{
+		cfg:      cfg,
+		gatherer: collector.registry,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		log:      log,
+	}
+}
+
+// Run pushes the metrics every interval until ctx is done. Failures are
+// logged once, and again when pushing recovers.
+func (p *Pusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := p.Push(ctx)
+			switch {
+			case err != nil && !p.failing:
+				p.log.With().Err(err).Str("protocol", p.cfg.Protocol).Logger().Warn("Failed to push metrics")
+			case err == nil && p.failing:
+				p.log.With().Str("protocol", p.cfg.Protocol).Logger().Info("Metrics push recovered")
+			}
+			p.failing = err != nil
+		}
+	}
+}
+
+// Push sends the current metrics once
+func (p *Pusher) Push(ctx context.Context) error {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("failed to gather metrics: %w", err)
+	}
+	samples := flatten(families, p.cfg.Labels)
+	now := time.Now()
+
+	switch p.cfg.Protocol {
+	case PushRemoteWrite:
+		return p.post(ctx, encodeRemoteWrite(samples, now), map[string]string{
+			"Content-Type":                      "application/x-protobuf",
+			"Content-Encoding":                  "snappy",
+			"X-Prometheus-Remote-Write-Version": "0.1.0",
+		})
+	case PushInflux:
+		return p.post(ctx, encodeInflux(samples, now), map[string]string{
+			"Content-Type": "text/plain; charset=utf-8",
+		})
+	case PushStatsd:
+		return p.sendStatsd(ctx, encodeStatsd(samples))
+	default:
+		return fmt.Errorf("unknown push protocol %q", p.cfg.Protocol)
+	}
+}
+
+// post sends body to the configured URL with the protocol's headers and
+// the configured ones
+func (p *Pusher) post(ctx context.Context, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range p.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned status %d", p.cfg.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// sendStatsd writes the lines to the configured address in as few packets
+// as fit them
+func (p *Pusher) sendStatsd(ctx context.Context, lines []string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", p.cfg.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var packet []byte
+	for _, line := range lines {
+		if len(packet) > 0 && len(packet)+len(line) > statsdPacketSize {
+			if _, err := conn.Write(packet); err != nil {
+				return err
+			}
+			packet = packet[:0]
+		}
+		packet = append(packet, line...)
+	}
+	if len(packet) > 0 {
+		_, err = conn.Write(packet)
+	}
+	return err
+}
+
+// label is a metric label
+type label struct {
+	name, value string
+}
+
+// sample is a single value of a metric, its labels sorted by name
+type sample struct {
+	name   string
+	labels []label
+	value  float64
+}
+
+// flatten turns metric families into samples as the text exposition format
+// lists them: histograms and summaries become their buckets or quantiles,
+// sum and count. Extra labels are added to metrics that lack them.
+func flatten(families []*dto.MetricFamily, extra map[string]string) []sample {
+	var samples []sample
+	for _, mf := range families {
+		name := mf.GetName()
+		for _, m := range mf.GetMetric() {
+			labels := make([]label, 0, len(m.GetLabel())+len(extra))
+			for _, l := range m.GetLabel() {
+				labels = append(labels, label{l.GetName(), l.GetValue()})
+			}
+			for k, v := range extra {
+				if !slices.ContainsFunc(labels, func(l label) bool { return l.name == k }) {
+					labels = append(labels, label{k, v})
+				}
+			}
+			sortLabels(labels)
+
+			add := func(suffix string, value float64, more ...label) {
+				ls := labels
+				if len(more) > 0 {
+					ls = append(slices.Clone(labels), more...)
+					sortLabels(ls)
+				}
+				samples = append(samples, sample{name + suffix, ls, value})
+			}
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add("", m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add("", m.GetGauge().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, b := range h.GetBucket() {
+					add("_bucket", float64(b.GetCumulativeCount()), label{"le", formatFloat(b.GetUpperBound())})
+				}
+				add("_bucket", float64(h.GetSampleCount()), label{"le", "+Inf"})
+				add("_sum", h.GetSampleSum())
+				add("_count", float64(h.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				sm := m.GetSummary()
+				for _, q := range sm.GetQuantile() {
+					add("", q.GetValue(), label{"quantile", formatFloat(q.GetQuantile())})
+				}
+				add("_sum", sm.GetSampleSum())
+				add("_count", float64(sm.GetSampleCount()))
+			default:
+				add("", m.GetUntyped().GetValue())
+			}
+		}
+	}
+	return samples
+}
+
+// sortLabels sorts labels by name, as remote write requires
+func sortLabels(labels []label) {
+	slices.SortFunc(labels, func(a, b label) int { return strings.Compare(a.name, b.name) })
+}
+
+// formatFloat writes a bucket bound or quantile as Prometheus does
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeRemoteWrite encodes samples as a snappy-compressed remote write
+// WriteRequest, all taken at now
+func encodeRemoteWrite(samples []sample, now time.Time) []byte {
+	var req []byte
+	ts := now.UnixMilli()
+	for _, s := range samples {
+		labels := append([]label{{"__name__", s.name}}, s.labels...)
+		sortLabels(labels)
+
+		var series []byte
+		for _, l := range labels {
+			var lb []byte
+			lb = appendProtoBytes(lb, 1, []byte(l.name))
+			lb = appendProtoBytes(lb, 2, []byte(l.value))
+			series = appendProtoBytes(series, 1, lb)
+		}
+		var sb []byte
+		sb = binary.AppendUvarint(sb, 1<<3|1) // value, fixed64
+		sb = binary.LittleEndian.AppendUint64(sb, math.Float64bits(s.value))
+		sb = binary.AppendUvarint(sb, 2<<3|0)     // timestamp, varint
+		sb = binary.AppendUvarint(sb, uint64(ts)) // #nosec G115 -- milliseconds since 1970 are positive
+		series = appendProtoBytes(series, 2, sb)
+		req = appendProtoBytes(req, 1, series)
+	}
+	return snappyEncode(req)
+}
+
+// appendProtoBytes appends a length-delimited protobuf field
+func appendProtoBytes(b []byte, field int, value []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2) // #nosec G115 -- small field numbers
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+// snappyEncode wraps src in the snappy block format remote write expects.
+// It stores src as literals without compressing it: pushes are small and
+// infrequent, and this needs no compression library.
+func snappyEncode(src []byte) []byte {
+	dst := binary.AppendUvarint(make([]byte, 0, len(src)+len(src)/65536*3+16), uint64(len(src)))
+	for len(src) > 0 {
+		n := min(len(src), 65536)
+		switch {
+		case n <= 60:
+			dst = append(dst, byte(n-1)<<2)
+		case n <= 256:
+			dst = append(dst, 60<<2, byte(n-1))
+		default:
+			dst = append(dst, 61<<2, byte(n-1), byte((n-1)>>8))
+		}
+		dst = append(dst, src[:n]...)
+		src = src[n:]
+	}
+	return dst
+}
+
+// influxEscaper escapes measurement names, tag keys and tag values
+var influxEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// encodeInflux writes samples in InfluxDB line protocol, one measurement
+// per metric with its labels as tags and a value field, all taken at now.
+// NaN and infinite values, which InfluxDB rejects, are left out.
+func encodeInflux(samples []sample, now time.Time) []byte {
+	var b bytes.Buffer
+	for _, s := range samples {
+		if math.IsNaN(s.value) || math.IsInf(s.value, 0) {
+			continue
+		}
+		b.WriteString(influxEscaper.Replace(s.name))
+		for _, l := range s.labels {
+			if l.value == "" {
+				continue // InfluxDB rejects empty tag values
+			}
+			fmt.Fprintf(&b, ",%s=%s", influxEscaper.Replace(l.name), influxEscaper.Replace(l.value))
+		}
+		fmt.Fprintf(&b, " value=%s %d\n", formatFloat(s.value), now.UnixNano())
+	}
+	return b.Bytes()
+}
+
+// statsdEscaper replaces the characters that delimit statsd lines and tags
+var statsdEscaper = strings.NewReplacer(":", "_", "|", "_", ",", "_", "#", "_", "\n", "_")
+
+// encodeStatsd writes samples as statsd gauges, with their labels as
+// DogStatsD tags. Counters are sent as gauges too, since statsd counters
+// are increments. A leading minus sign would make a gauge a decrement, so
+// negative values are sent after resetting the gauge to zero.
+func encodeStatsd(samples []sample) []string {
+	lines := make([]string, 0, len(samples))
+	for _, s := range samples {
+		if math.IsNaN(s.value) || math.IsInf(s.value, 0) {
+			continue
+		}
+		tags := ""
+		if len(s.labels) > 0 {
+			parts := make([]string, len(s.labels))
+			for i, l := range s.labels {
+				parts[i] = statsdEscaper.Replace(l.name) + ":" + statsdEscaper.Replace(l.value)
+			}
+			tags = "|#" + strings.Join(parts, ",")
+		}
+		if s.value < 0 {
+			lines = append(lines, s.name+":0|g"+tags+"\n")
+		}
+		lines = append(lines, s.name+":"+formatFloat(s.value)+"|g"+tags+"\n")
+	}
+	return lines
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// newTestPusher returns a pusher of a collector with a known CPU temperature
+func newTestPusher(cfg PushConfig) *Pusher {
+	log := logger.NewDefault()
+	c := New(log)
+	c.CPUTemperature.Set(48.5)
+	return NewPusher(cfg, c, log)
+}
+
+func TestPushRemoteWrite(t *testing.T) {
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	p := newTestPusher(PushConfig{
+		Protocol: PushRemoteWrite,
+		URL:      srv.URL,
+		Headers:  map[string]string{"Authorization": "Bearer secret"},
+		Labels:   map[string]string{"instance": "kitchen-pi"},
+	})
+	if err := p.Push(context.Background()); err != nil {
+		t.Fatalf("Push() failed: %v", err)
+	}
+
+	if header.Get("Content-Encoding") != "snappy" || header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("unexpected headers %v", header)
+	}
+	// The body is literal snappy: its decoded length, then chunks of the
+	// protobuf with their tags
+	n, size := binary.Uvarint(body)
+	if size <= 0 || n == 0 {
+		t.Fatalf("invalid snappy header in %d bytes", len(body))
+	}
+	for _, want := range []string{"__name__", "i2c_display_cpu_temperature_celsius", "instance", "kitchen-pi"} {
+		if !bytes.Contains(body, []byte(want)) {
+			t.Errorf("expected %q in the write request", want)
+		}
+	}
+}
+
+func TestPushInflux(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	p := newTestPusher(PushConfig{Protocol: PushInflux, URL: srv.URL, Labels: map[string]string{"host": "pi 4"}})
+	if err := p.Push(context.Background()); err != nil {
+		t.Fatalf("Push() failed: %v", err)
+	}
+	if !strings.Contains(body, `i2c_display_cpu_temperature_celsius,host=pi\ 4 value=48.5 `) {
+		t.Errorf("expected an escaped temperature line, got:\n%s", body)
+	}
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+	if err := p.Push(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected a 401 error, got %v", err)
+	}
+}
+
+func TestPushStatsd(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on UDP: %v", err)
+	}
+	defer conn.Close()
+
+	p := newTestPusher(PushConfig{Protocol: PushStatsd, Address: conn.LocalAddr().String()})
+	if err := p.Push(context.Background()); err != nil {
+		t.Fatalf("Push() failed: %v", err)
+	}
+
+	var got strings.Builder
+	buf := make([]byte, 2*statsdPacketSize)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+		if n > statsdPacketSize {
+			t.Errorf("packet of %d bytes exceeds %d", n, statsdPacketSize)
+		}
+		got.Write(buf[:n])
+	}
+	if !strings.Contains(got.String(), "i2c_display_cpu_temperature_celsius:48.5|g\n") {
+		t.Errorf("expected the temperature gauge, got:\n%s", got.String())
+	}
+}
+
+func TestEncodeStatsdNegative(t *testing.T) {
+	lines := encodeStatsd([]sample{{name: "offset", labels: []label{{"page", "a:b"}}, value: -2}})
+	want := []string{"offset:0|g|#page:a_b\n", "offset:-2|g|#page:a_b\n"}
+	if strings.Join(lines, "") != strings.Join(want, "") {
+		t.Errorf("encodeStatsd() = %q, want %q", lines, want)
+	}
+}
+
+func TestSnappyEncode(t *testing.T) {
+	for _, n := range []int{0, 1, 60, 61, 256, 257, 70000} {
+		src := bytes.Repeat([]byte{'x'}, n)
+		dst := snappyEncode(src)
+		length, size := binary.Uvarint(dst)
+		if int(length) != n {
+			t.Errorf("%d bytes: encoded length %d", n, length)
+			continue
+		}
+		// Walk the literal chunks back into the source
+		var out []byte
+		for rest := dst[size:]; len(rest) > 0; {
+			tag := int(rest[0] >> 2)
+			switch {
+			case tag < 60:
+				out, rest = append(out, rest[1:1+tag+1]...), rest[1+tag+1:]
+			case tag == 60:
+				l := int(rest[1]) + 1
+				out, rest = append(out, rest[2:2+l]...), rest[2+l:]
+			default:
+				l := (int(rest[1]) | int(rest[2])<<8) + 1
+				out, rest = append(out, rest[3:3+l]...), rest[3+l:]
+			}
+		}
+		if !bytes.Equal(out, src) {
+			t.Errorf("%d bytes: literals decode to %d bytes", n, len(out))
+		}
+	}
+}
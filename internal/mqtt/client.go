@@ -0,0 +1,367 @@
+// Package mqtt is a minimal MQTT 3.1.1 client, enough to publish state and
+// receive commands at QoS 0, and the Home Assistant bridge built on it.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Control packet types, already shifted into the fixed header's high nibble
+const (
+	packetConnect     = 1 << 4
+	packetConnAck     = 2 << 4
+	packetPublish     = 3 << 4
+	packetSubscribe   = 8 << 4
+	packetSubAck      = 9 << 4
+	packetPingReq     = 12 << 4
+	packetPingResp    = 13 << 4
+	packetDisconnect  = 14 << 4
+	subscribeFlags    = 0x02 // SUBSCRIBE's reserved flags
+	publishRetainFlag = 0x01
+)
+
+// CONNECT flags
+const (
+	connectCleanSession = 0x02
+	connectWill         = 0x04
+	connectWillRetain   = 0x20
+	connectPassword     = 0x40
+	connectUsername     = 0x80
+)
+
+// maxPacketSize bounds the packets accepted from the broker; commands are
+// short and nothing subscribed to carries more
+const maxPacketSize = 64 * 1024
+
+// defaultKeepAlive is used when Options.KeepAlive is zero
+const defaultKeepAlive = 30 * time.Second
+
+// connAckErrors are the CONNACK return codes refusing a connection
+var connAckErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// Message is a PUBLISH packet's topic and payload
+type Message struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// Options configure a connection
+type Options struct {
+	Broker    string // tcp://host:port, or ssl://host:port for TLS
+	ClientID  string
+	Username  string // optional
+	Password  string // optional
+	KeepAlive time.Duration
+	Will      *Message // published by the broker if the connection is lost
+}
+
+// Client is a connection to an MQTT broker. Publish and Subscribe may be
+// called from any goroutine while Run reads from the broker.
+type Client struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	keepAlive time.Duration
+
+	wmu    sync.Mutex // serialises writes
+	nextID uint16     // last packet identifier used
+}
+
+// Dial connects to the broker and completes the MQTT handshake
+func Dial(ctx context.Context, opts Options) (*Client, error) {
+	u, err := url.Parse(opts.Broker)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker URL: %w", err)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		port := "1883"
+		if isTLSScheme(u.Scheme) {
+			port = "8883"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if isTLSScheme(u.Scheme) {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	keepAlive := opts.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = defaultKeepAlive
+	}
+	c := &Client{conn: conn, reader: bufio.NewReader(conn), keepAlive: keepAlive}
+	if err := c.connect(ctx, opts); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// isTLSScheme reports whether a broker URL scheme asks for TLS
+func isTLSScheme(scheme string) bool {
+	return scheme == "ssl" || scheme == "tls" || scheme == "mqtts"
+}
+
+// connect sends CONNECT and waits for the broker to accept it
+func (c *Client) connect(ctx context.Context, opts Options) error {
+	flags := byte(connectCleanSession)
+	var payload []byte
+	payload = appendString(payload, opts.ClientID)
+	if opts.Will != nil {
+		flags |= connectWill
+		if opts.Will.Retain {
+			flags |= connectWillRetain
+		}
+		payload = appendString(payload, opts.Will.Topic)
+		payload = appendBytes(payload, opts.Will.Payload)
+	}
+	if opts.Username != "" {
+		flags |= connectUsername
+		payload = appendString(payload, opts.Username)
+	}
+	if opts.Password != "" {
+		flags |= connectPassword
+		payload = appendString(payload, opts.Password)
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags) // protocol level 4 is MQTT 3.1.1
+	body = binary.BigEndian.AppendUint16(body, uint16(c.keepAlive/time.Second))
+	body = append(body, payload...)
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(c.keepAlive)
+	}
+	_ = c.conn.SetDeadline(deadline)
+	defer func() { _ = c.conn.SetDeadline(time.Time{}) }()
+
+	if err := c.write(packetConnect, body); err != nil {
+		return err
+	}
+	header, ack, err := readPacket(c.reader)
+	if err != nil {
+		return fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if header&0xF0 != packetConnAck || len(ack) != 2 {
+		return fmt.Errorf("expected CONNACK, got packet type %d", header>>4)
+	}
+	if code := ack[1]; code != 0 {
+		if reason, ok := connAckErrors[code]; ok {
+			return fmt.Errorf("broker refused connection: %s", reason)
+		}
+		return fmt.Errorf("broker refused connection with code %d", code)
+	}
+	return nil
+}
+
+// Publish sends a message at QoS 0
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	var flags byte
+	if retain {
+		flags = publishRetainFlag
+	}
+	body := appendString(nil, topic)
+	body = append(body, payload...)
+	return c.write(packetPublish|flags, body)
+}
+
+// Subscribe asks for messages on the topics at QoS 0
+func (c *Client) Subscribe(topics ...string) error {
+	c.wmu.Lock()
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID = 1 // zero is not a valid packet identifier
+	}
+	id := c.nextID
+	c.wmu.Unlock()
+
+	body := binary.BigEndian.AppendUint16(nil, id)
+	for _, topic := range topics {
+		body = appendString(body, topic)
+		body = append(body, 0) // QoS 0
+	}
+	return c.write(packetSubscribe|subscribeFlags, body)
+}
+
+// Run reads from the broker, passing each message to handle, and keeps the
+// connection alive. It returns when the connection fails or ctx is done.
+func (c *Client) Run(ctx context.Context, handle func(Message)) error {
+	// Unblock the read below when ctx is done
+	stop := context.AfterFunc(ctx, func() { _ = c.conn.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	pingDone := make(chan struct{})
+	defer close(pingDone)
+	go func() {
+		ticker := time.NewTicker(c.keepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pingDone:
+				return
+			case <-ticker.C:
+				if err := c.write(packetPingReq, nil); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		// A broker that stays silent past a ping round trip is gone
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		header, body, err := readPacket(c.reader)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		switch header & 0xF0 {
+		case packetPublish:
+			msg, err := parsePublish(header, body)
+			if err != nil {
+				return err
+			}
+			handle(msg)
+		case packetSubAck:
+			if n := len(body); n > 2 && body[n-1] == 0x80 {
+				return errors.New("broker refused subscription")
+			}
+		case packetPingResp:
+		default:
+			return fmt.Errorf("unexpected packet type %d", header>>4)
+		}
+	}
+}
+
+// Close sends DISCONNECT, so the broker discards the will, and closes the
+// connection
+func (c *Client) Close() error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	_ = c.write(packetDisconnect, nil)
+	return c.conn.Close()
+}
+
+// write sends a packet with the given fixed header byte and body
+func (c *Client) write(header byte, body []byte) error {
+	packet := append([]byte{header}, appendLength(nil, len(body))...)
+	packet = append(packet, body...)
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+// readPacket reads a packet's fixed header byte and body
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	if length > maxPacketSize {
+		return 0, nil, fmt.Errorf("packet of %d bytes exceeds the %d byte limit", length, maxPacketSize)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// parsePublish decodes a PUBLISH packet's topic and payload. Messages above
+// QoS 0 carry a packet identifier, which is skipped since nothing is
+// subscribed to at a higher QoS.
+func parsePublish(header byte, body []byte) (Message, error) {
+	if len(body) < 2 {
+		return Message{}, errors.New("malformed PUBLISH packet")
+	}
+	n := int(binary.BigEndian.Uint16(body))
+	rest := body[2:]
+	if n > len(rest) {
+		return Message{}, errors.New("malformed PUBLISH topic")
+	}
+	msg := Message{Topic: string(rest[:n]), Retain: header&publishRetainFlag != 0}
+	rest = rest[n:]
+	if header&0x06 != 0 {
+		if len(rest) < 2 {
+			return Message{}, errors.New("malformed PUBLISH packet identifier")
+		}
+		rest = rest[2:]
+	}
+	msg.Payload = rest
+	return msg, nil
+}
+
+// appendLength appends the variable-length remaining length encoding of n
+func appendLength(b []byte, n int) []byte {
+	for {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+// appendString appends a length-prefixed UTF-8 string
+func appendString(b []byte, s string) []byte {
+	return appendBytes(b, []byte(s))
+}
+
+// appendBytes appends length-prefixed binary data
+func appendBytes(b, data []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(data))) // #nosec G115 -- topics and payloads here are far below 64 KiB
+	return append(b, data...)
+}
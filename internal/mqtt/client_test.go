@@ -0,0 +1,203 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBroker accepts one client, answers its CONNECT with returnCode and
+// passes every later packet to packets
+type fakeBroker struct {
+	t        *testing.T
+	listener net.Listener
+	connect  chan []byte // the CONNECT body
+	packets  chan packet
+	conn     chan net.Conn
+}
+
+type packet struct {
+	header byte
+	body   []byte
+}
+
+func newFakeBroker(t *testing.T, returnCode byte) *fakeBroker {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeBroker{
+		t:        t,
+		listener: listener,
+		connect:  make(chan []byte, 1),
+		packets:  make(chan packet, 100),
+		conn:     make(chan net.Conn, 1),
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		r := bufio.NewReader(conn)
+		_, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		b.connect <- body
+		if _, err := conn.Write([]byte{packetConnAck, 2, 0, returnCode}); err != nil {
+			return
+		}
+		b.conn <- conn
+		for {
+			header, body, err := readPacket(r)
+			if err != nil {
+				close(b.packets)
+				return
+			}
+			b.packets <- packet{header, body}
+		}
+	}()
+	return b
+}
+
+// url returns the broker URL to dial
+func (b *fakeBroker) url() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+// next returns the next packet of the given type, skipping pings
+func (b *fakeBroker) next(packetType byte) packet {
+	b.t.Helper()
+	for {
+		select {
+		case p, ok := <-b.packets:
+			if !ok {
+				b.t.Fatalf("connection closed waiting for packet type %d", packetType>>4)
+			}
+			if p.header&0xF0 == packetPingReq {
+				continue
+			}
+			if p.header&0xF0 != packetType {
+				b.t.Fatalf("expected packet type %d, got %d", packetType>>4, p.header>>4)
+			}
+			return p
+		case <-time.After(5 * time.Second):
+			b.t.Fatalf("timed out waiting for packet type %d", packetType>>4)
+		}
+	}
+}
+
+// send writes a PUBLISH to the client
+func (b *fakeBroker) send(conn net.Conn, topic, payload string) {
+	b.t.Helper()
+	body := appendString(nil, topic)
+	body = append(body, payload...)
+	packet := append([]byte{packetPublish}, appendLength(nil, len(body))...)
+	if _, err := conn.Write(append(packet, body...)); err != nil {
+		b.t.Fatal(err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	broker := newFakeBroker(t, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := Dial(ctx, Options{
+		Broker:   broker.url(),
+		ClientID: "display",
+		Username: "user",
+		Password: "secret",
+		Will:     &Message{Topic: "display/status", Payload: []byte("offline"), Retain: true},
+	})
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+
+	connect := <-broker.connect
+	if !strings.HasPrefix(string(connect), "\x00\x04MQTT\x04") {
+		t.Errorf("expected an MQTT 3.1.1 CONNECT, got %q", connect)
+	}
+	wantFlags := byte(connectCleanSession | connectWill | connectWillRetain | connectUsername | connectPassword)
+	if connect[7] != wantFlags {
+		t.Errorf("CONNECT flags = %#x, want %#x", connect[7], wantFlags)
+	}
+	if keepAlive := binary.BigEndian.Uint16(connect[8:]); keepAlive != 30 {
+		t.Errorf("keep alive = %d, want 30", keepAlive)
+	}
+	for _, want := range []string{"display", "display/status", "offline", "user", "secret"} {
+		if !strings.Contains(string(connect[10:]), want) {
+			t.Errorf("expected %q in the CONNECT payload %q", want, connect[10:])
+		}
+	}
+
+	if err := c.Publish("display/page", []byte("System"), true); err != nil {
+		t.Fatalf("Publish() failed: %v", err)
+	}
+	p := broker.next(packetPublish)
+	msg, err := parsePublish(p.header, p.body)
+	if err != nil || msg.Topic != "display/page" || string(msg.Payload) != "System" || !msg.Retain {
+		t.Errorf("broker got %+v, %v", msg, err)
+	}
+
+	if err := c.Subscribe("display/page/set"); err != nil {
+		t.Fatalf("Subscribe() failed: %v", err)
+	}
+	p = broker.next(packetSubscribe)
+	if p.header != packetSubscribe|subscribeFlags || !strings.Contains(string(p.body), "display/page/set") {
+		t.Errorf("unexpected SUBSCRIBE %#x %q", p.header, p.body)
+	}
+
+	received := make(chan Message, 1)
+	runErr := make(chan error, 1)
+	go func() { runErr <- c.Run(ctx, func(m Message) { received <- m }) }()
+	broker.send(<-broker.conn, "display/page/set", "Network")
+	select {
+	case m := <-received:
+		if m.Topic != "display/page/set" || string(m.Payload) != "Network" {
+			t.Errorf("received %+v", m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not received")
+	}
+
+	cancel()
+	select {
+	case err := <-runErr:
+		if err != context.Canceled {
+			t.Errorf("Run() = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return when cancelled")
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+	broker.next(packetDisconnect)
+}
+
+func TestDialRefused(t *testing.T) {
+	broker := newFakeBroker(t, 4)
+	_, err := Dial(context.Background(), Options{Broker: broker.url(), ClientID: "display"})
+	if err == nil || !strings.Contains(err.Error(), "bad user name or password") {
+		t.Errorf("Dial() = %v, want the refusal reason", err)
+	}
+}
+
+func TestRemainingLength(t *testing.T) {
+	for _, n := range []int{0, 127, 128, 16383, 16384, maxPacketSize} {
+		encoded := appendLength(nil, n)
+		header, body, err := readPacket(bufio.NewReader(strings.NewReader(string(append(append([]byte{packetPingResp}, encoded...), make([]byte, n)...)))))
+		if err != nil || header != packetPingResp || len(body) != n {
+			t.Errorf("length %d: got %d, %v", n, len(body), err)
+		}
+	}
+}
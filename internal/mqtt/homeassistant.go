@@ -0,0 +1,369 @@
+package mqtt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/control"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+const (
+	// stateInterval is how often the page and brightness are checked for
+	// changes to publish
+	stateInterval = 2 * time.Second
+	// minBackoff and maxBackoff bound the wait between reconnection attempts
+	minBackoff = 5 * time.Second
+	maxBackoff = 5 * time.Minute
+	// dialTimeout bounds connecting and the MQTT handshake
+	dialTimeout = 15 * time.Second
+
+	payloadOnline  = "online"
+	payloadOffline = "offline"
+)
+
+// nodeIDInvalid matches characters Home Assistant does not allow in a
+// discovery node ID
+var nodeIDInvalid = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// Controls are the parts of the daemon the bridge drives. Every field is
+// required.
+type Controls struct {
+	// ShowMessage shows text in place of rotation, with the same arguments
+	// as the HTTP message API
+	ShowMessage   func(text, size, color string, d time.Duration) error
+	SetBrightness func(level uint8)
+	Brightness    func() uint8 // the normal brightness level
+	PageTitles    func() []string
+	CurrentPage   func() int
+	ShowPage      func(idx int) error
+}
+
+// Device describes the display to Home Assistant
+type Device struct {
+	Hostname string
+	Model    string // display type
+	Version  string
+}
+
+// Bridge makes the display a Home Assistant device over MQTT: a text entity
+// showing messages, a number setting the brightness and a select switching
+// pages. It reconnects on its own when the broker goes away.
+type Bridge struct {
+	cfg    config.MQTTConfig
+	device Device
+	ctl    Controls
+	log    *logger.Logger
+	dial   func(ctx context.Context, opts Options) (*Client, error)
+
+	base   string // topic prefix of the display's own topics
+	nodeID string // identifies the display in discovery topics
+
+	mu    sync.Mutex
+	pages []string // select options last announced, for page/set
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// bridgeState is what was last published, to publish only changes
+type bridgeState struct {
+	pages      []string
+	page       string
+	brightness int // -1 before the first publish
+}
+
+// NewBridge creates a bridge for the MQTT config
+func NewBridge(cfg config.MQTTConfig, device Device, ctl Controls, log *logger.Logger) *Bridge {
+	if cfg.ClientID == "" {
+		cfg.ClientID = "i2c-display-" + device.Hostname
+	}
+	base := cfg.TopicPrefix
+	if base == "" {
+		base = "i2c-display/" + device.Hostname
+	}
+	return &Bridge{
+		cfg:    cfg,
+		device: device,
+		ctl:    ctl,
+		log:    log,
+		dial:   Dial,
+		base:   base,
+		nodeID: nodeIDInvalid.ReplaceAllString(cfg.ClientID, "_"),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start connects to the broker in the background
+func (b *Bridge) Start(ctx context.Context) {
+	ctx, b.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(b.done)
+		defer func() {
+			if r := recover(); r != nil {
+				b.log.Errorf("PANIC in MQTT bridge: %v", r)
+			}
+		}()
+		b.run(ctx)
+	}()
+}
+
+// Stop marks the display offline and disconnects
+func (b *Bridge) Stop() {
+	b.once.Do(func() {
+		if b.cancel == nil {
+			return
+		}
+		b.cancel()
+		select {
+		case <-b.done:
+		case <-time.After(5 * time.Second):
+			b.log.Warn("MQTT bridge stop timed out")
+		}
+	})
+}
+
+// run keeps a session with the broker, backing off between failed attempts
+func (b *Bridge) run(ctx context.Context) {
+	backoff := minBackoff
+	for {
+		start := time.Now()
+		err := b.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		// A session that lasted a while was a working connection
+		if time.Since(start) > maxBackoff {
+			backoff = minBackoff
+		}
+		b.log.With().Str("broker", b.cfg.Broker).Str("retry_in", backoff.String()).Err(err).Logger().Warn("MQTT connection lost")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// session connects, announces the entities and serves commands until the
+// connection fails or ctx is done
+func (b *Bridge) session(ctx context.Context) error {
+	dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+	c, err := b.dial(dialCtx, Options{
+		Broker:   b.cfg.Broker,
+		ClientID: b.cfg.ClientID,
+		Username: b.cfg.Username,
+		Password: b.cfg.Password,
+		Will:     &Message{Topic: b.topic("status"), Payload: []byte(payloadOffline), Retain: true},
+	})
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if err := c.Subscribe(b.topic("message/set"), b.topic("brightness/set"), b.topic("page/set")); err != nil {
+		return err
+	}
+	last := bridgeState{brightness: -1}
+	if err := b.publishState(c, &last); err != nil {
+		return err
+	}
+	if err := c.Publish(b.topic("status"), []byte(payloadOnline), true); err != nil {
+		return err
+	}
+	b.log.With().Str("broker", b.cfg.Broker).Str("topic", b.base).Logger().Info("Connected to MQTT broker")
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- c.Run(ctx, b.handle) }()
+
+	ticker := time.NewTicker(stateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-runErr:
+			if ctx.Err() != nil {
+				// Shutting down: say so rather than leave it to the will
+				_ = c.Publish(b.topic("status"), []byte(payloadOffline), true)
+			}
+			return err
+		case <-ticker.C:
+			if err := b.publishState(c, &last); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// topic returns one of the display's own topics
+func (b *Bridge) topic(name string) string {
+	return b.base + "/" + name
+}
+
+// publishState publishes the discovery payloads when the pages have changed,
+// and the page and brightness when they have
+func (b *Bridge) publishState(c *Client, last *bridgeState) error {
+	pages := pageOptions(b.ctl.PageTitles())
+	if !slices.Equal(pages, last.pages) {
+		if b.cfg.Discovery {
+			if err := b.publishDiscovery(c, pages); err != nil {
+				return err
+			}
+		}
+		last.pages = pages
+		last.page = "" // the options changed under the selected page
+		b.mu.Lock()
+		b.pages = pages
+		b.mu.Unlock()
+	}
+
+	if idx := b.ctl.CurrentPage(); idx >= 0 && idx < len(pages) && pages[idx] != last.page {
+		if err := c.Publish(b.topic("page"), []byte(pages[idx]), true); err != nil {
+			return err
+		}
+		last.page = pages[idx]
+	}
+
+	if level := int(b.ctl.Brightness()); level != last.brightness {
+		if err := c.Publish(b.topic("brightness"), []byte(strconv.Itoa(level)), true); err != nil {
+			return err
+		}
+		last.brightness = level
+	}
+	return nil
+}
+
+// publishDiscovery announces the entities to Home Assistant, retained so it
+// finds them after restarting
+func (b *Bridge) publishDiscovery(c *Client, pages []string) error {
+	device := map[string]any{
+		"identifiers":  []string{b.nodeID},
+		"name":         b.device.Hostname,
+		"manufacturer": "i2c-display",
+		"model":        b.device.Model,
+		"sw_version":   b.device.Version,
+	}
+	entities := []struct {
+		component, object string
+		config            map[string]any
+	}{
+		{"text", "message", map[string]any{
+			"name":          "Message",
+			"icon":          "mdi:message-text",
+			"command_topic": b.topic("message/set"),
+			"max":           255,
+		}},
+		{"number", "brightness", map[string]any{
+			"name":          "Brightness",
+			"icon":          "mdi:brightness-6",
+			"command_topic": b.topic("brightness/set"),
+			"state_topic":   b.topic("brightness"),
+			"min":           0,
+			"max":           255,
+			"step":          1,
+			"mode":          "slider",
+		}},
+		{"select", "page", map[string]any{
+			"name":          "Page",
+			"icon":          "mdi:monitor",
+			"command_topic": b.topic("page/set"),
+			"state_topic":   b.topic("page"),
+			"options":       pages,
+		}},
+	}
+
+	for _, e := range entities {
+		e.config["unique_id"] = b.nodeID + "_" + e.object
+		e.config["availability_topic"] = b.topic("status")
+		e.config["device"] = device
+		payload, err := json.Marshal(e.config)
+		if err != nil {
+			return err
+		}
+		topic := fmt.Sprintf("%s/%s/%s/%s/config", b.cfg.DiscoveryPrefix, e.component, b.nodeID, e.object)
+		if err := c.Publish(topic, payload, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handle runs a command received from Home Assistant
+func (b *Bridge) handle(msg Message) {
+	var err error
+	switch strings.TrimPrefix(msg.Topic, b.base+"/") {
+	case "message/set":
+		err = b.showMessage(msg.Payload)
+	case "brightness/set":
+		err = b.setBrightness(msg.Payload)
+	case "page/set":
+		b.mu.Lock()
+		idx := slices.Index(b.pages, string(msg.Payload))
+		b.mu.Unlock()
+		if idx < 0 {
+			err = fmt.Errorf("unknown page %q", msg.Payload)
+		} else {
+			err = b.ctl.ShowPage(idx)
+		}
+	default:
+		return
+	}
+	if err != nil {
+		b.log.With().Str("topic", msg.Topic).Err(err).Logger().Warn("Ignoring MQTT command")
+	}
+}
+
+// showMessage shows a message: plain text, or a JSON object with the show
+// message arguments (text, duration, font_size, color)
+func (b *Bridge) showMessage(payload []byte) error {
+	args := control.MessageArgs{Text: string(payload)}
+	if bytes.HasPrefix(bytes.TrimSpace(payload), []byte("{")) {
+		args = control.MessageArgs{}
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+	}
+	d, err := args.Validate()
+	if err != nil {
+		return err
+	}
+	return b.ctl.ShowMessage(args.Text, args.FontSize, args.Color, d)
+}
+
+// setBrightness sets the normal brightness from a number 0-255; Home
+// Assistant may send it with a fraction
+func (b *Bridge) setBrightness(payload []byte) error {
+	level, err := strconv.ParseFloat(strings.TrimSpace(string(payload)), 64)
+	if err != nil || level < 0 || level > 255 {
+		return fmt.Errorf("brightness must be 0-255, got %q", payload)
+	}
+	b.ctl.SetBrightness(uint8(level))
+	return nil
+}
+
+// pageOptions turns page titles into select options, which must be unique:
+// repeated titles get a number, "Network", "Network 2"
+func pageOptions(titles []string) []string {
+	options := make([]string, len(titles))
+	seen := make(map[string]int, len(titles))
+	for i, title := range titles {
+		seen[title]++
+		options[i] = title
+		if n := seen[title]; n > 1 {
+			options[i] = fmt.Sprintf("%s %d", title, n)
+		}
+	}
+	return options
+}
@@ -0,0 +1,185 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// fakeControls records the commands the bridge runs
+type fakeControls struct {
+	mu         sync.Mutex
+	brightness uint8
+	page       int
+	message    string
+	duration   time.Duration
+}
+
+func (f *fakeControls) controls() Controls {
+	return Controls{
+		ShowMessage: func(text, _, _ string, d time.Duration) error {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.message, f.duration = text, d
+			return nil
+		},
+		SetBrightness: func(level uint8) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.brightness = level
+		},
+		Brightness: func() uint8 {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return f.brightness
+		},
+		PageTitles: func() []string { return []string{"System", "Network", "Network"} },
+		CurrentPage: func() int {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return f.page
+		},
+		ShowPage: func(idx int) error {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.page = idx
+			return nil
+		},
+	}
+}
+
+// waitFor polls cond until it holds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestBridge(t *testing.T) {
+	broker := newFakeBroker(t, 0)
+	cfg := config.Default().MQTT
+	cfg.Enabled = true
+	cfg.Broker = broker.url()
+
+	ctl := &fakeControls{brightness: 200}
+	b := NewBridge(cfg, Device{Hostname: "pi.local", Model: "ssd1306", Version: "1.2.3"}, ctl.controls(), logger.NewDefault())
+	b.Start(context.Background())
+	defer b.Stop()
+
+	sub := string(broker.next(packetSubscribe).body)
+	for _, topic := range []string{"message/set", "brightness/set", "page/set"} {
+		if !strings.Contains(sub, "i2c-display/pi.local/"+topic) {
+			t.Errorf("expected a subscription to %s in %q", topic, sub)
+		}
+	}
+
+	// Discovery, then the state, then online
+	published := make(map[string]string)
+	for range 6 {
+		p := broker.next(packetPublish)
+		msg, err := parsePublish(p.header, p.body)
+		if err != nil || !msg.Retain {
+			t.Fatalf("expected a retained message, got %+v, %v", msg, err)
+		}
+		published[msg.Topic] = string(msg.Payload)
+	}
+
+	var sel struct {
+		UniqueID     string   `json:"unique_id"`
+		CommandTopic string   `json:"command_topic"`
+		Options      []string `json:"options"`
+		Device       struct {
+			Identifiers []string `json:"identifiers"`
+			Name        string   `json:"name"`
+		} `json:"device"`
+	}
+	if err := json.Unmarshal([]byte(published["homeassistant/select/i2c-display-pi_local/page/config"]), &sel); err != nil {
+		t.Fatalf("select discovery payload: %v in %v", err, published)
+	}
+	if sel.UniqueID != "i2c-display-pi_local_page" || sel.CommandTopic != "i2c-display/pi.local/page/set" || sel.Device.Name != "pi.local" {
+		t.Errorf("unexpected select entity %+v", sel)
+	}
+	if want := []string{"System", "Network", "Network 2"}; !slices.Equal(sel.Options, want) {
+		t.Errorf("options = %q, want %q", sel.Options, want)
+	}
+	for _, topic := range []string{"homeassistant/text/i2c-display-pi_local/message/config", "homeassistant/number/i2c-display-pi_local/brightness/config"} {
+		if _, ok := published[topic]; !ok {
+			t.Errorf("expected discovery on %s", topic)
+		}
+	}
+	if published["i2c-display/pi.local/page"] != "System" || published["i2c-display/pi.local/brightness"] != "200" {
+		t.Errorf("unexpected state %v", published)
+	}
+
+	conn := <-broker.conn
+	broker.send(conn, "i2c-display/pi.local/page/set", "Network 2")
+	broker.send(conn, "i2c-display/pi.local/brightness/set", "128.0")
+	broker.send(conn, "i2c-display/pi.local/message/set", `{"text":"Door open","duration":"5s"}`)
+	waitFor(t, "the commands", func() bool {
+		ctl.mu.Lock()
+		defer ctl.mu.Unlock()
+		return ctl.page == 2 && ctl.brightness == 128 && ctl.message == "Door open" && ctl.duration == 5*time.Second
+	})
+
+	// The page change is published back
+	for {
+		p := broker.next(packetPublish)
+		if msg, _ := parsePublish(p.header, p.body); msg.Topic == "i2c-display/pi.local/page" {
+			if string(msg.Payload) != "Network 2" {
+				t.Errorf("page state = %q, want Network 2", msg.Payload)
+			}
+			break
+		}
+	}
+
+	// Stopping goes offline before disconnecting
+	b.Stop()
+	for {
+		p := <-broker.packets
+		if p.header&0xF0 == packetDisconnect {
+			t.Fatal("disconnected without going offline")
+		}
+		if p.header&0xF0 != packetPublish {
+			continue
+		}
+		if msg, _ := parsePublish(p.header, p.body); msg.Topic == "i2c-display/pi.local/status" {
+			if string(msg.Payload) != payloadOffline {
+				t.Errorf("status = %q, want offline", msg.Payload)
+			}
+			break
+		}
+	}
+	broker.next(packetDisconnect)
+}
+
+func TestBridgeIgnoresBadCommands(t *testing.T) {
+	ctl := &fakeControls{brightness: 200}
+	b := NewBridge(config.MQTTConfig{TopicPrefix: "display"}, Device{Hostname: "pi"}, ctl.controls(), logger.NewDefault())
+	b.pages = []string{"System"}
+
+	b.handle(Message{Topic: "display/brightness/set", Payload: []byte("300")})
+	b.handle(Message{Topic: "display/page/set", Payload: []byte("Missing")})
+	b.handle(Message{Topic: "display/message/set", Payload: []byte("  ")})
+	if ctl.brightness != 200 || ctl.page != 0 || ctl.message != "" {
+		t.Errorf("expected the commands to be ignored, got %+v", ctl)
+	}
+
+	// Plain text shows for the default duration
+	b.handle(Message{Topic: "display/message/set", Payload: []byte("Hello")})
+	if ctl.message != "Hello" || ctl.duration != 30*time.Second {
+		t.Errorf("message = %q for %v", ctl.message, ctl.duration)
+	}
+}
@@ -0,0 +1,15 @@
+// Package netwatch reports network interface and address changes from the
+// kernel as they happen, so a new DHCP lease reaches the display at once
+// instead of on the next poll of the interfaces.
+package netwatch
+
+import "time"
+
+const (
+	// settleDelay coalesces the burst of notifications one change causes
+	// (link up, then an address per family) into a single call
+	settleDelay = 200 * time.Millisecond
+	// pollInterval bounds how long the watcher blocks reading before it
+	// checks whether it should stop or a pending change has settled
+	pollInterval = 100 * time.Millisecond
+)
@@ -0,0 +1,123 @@
+package netwatch
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// rtnetlink multicast groups, from linux/rtnetlink.h
+const (
+	rtmgrpLink       = 0x1
+	rtmgrpIPv4IfAddr = 0x10
+	rtmgrpIPv6IfAddr = 0x100
+)
+
+// Watch subscribes to rtnetlink link and address notifications and calls
+// changed from a background goroutine once a change has settled, until ctx
+// is done. It fails if the subscription cannot be made.
+func Watch(ctx context.Context, changed func()) error {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return fmt.Errorf("netlink socket: %w", err)
+	}
+	addr := &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK, Groups: rtmgrpLink | rtmgrpIPv4IfAddr | rtmgrpIPv6IfAddr}
+	if err := syscall.Bind(fd, addr); err != nil {
+		_ = syscall.Close(fd)
+		return fmt.Errorf("netlink bind: %w", err)
+	}
+	// A read timeout lets the loop notice ctx ending and changes settling
+	tv := syscall.NsecToTimeval(pollInterval.Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		_ = syscall.Close(fd)
+		return fmt.Errorf("netlink timeout: %w", err)
+	}
+
+	w := &watcher{up: make(map[int32]bool)}
+	go w.run(ctx, fd, changed)
+	return nil
+}
+
+// watcher tracks which links are up, since links also report changes that
+// do not matter to the display, such as wireless scan results
+type watcher struct {
+	up map[int32]bool // by interface index
+}
+
+// run reads notifications from fd until ctx is done
+func (w *watcher) run(ctx context.Context, fd int, changed func()) {
+	defer syscall.Close(fd)
+
+	buf := make([]byte, 1<<16)
+	var pending time.Time // when the first unreported change arrived
+	for ctx.Err() == nil {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		switch {
+		case err == nil:
+			if w.hasChange(buf[:n]) && pending.IsZero() {
+				pending = time.Now()
+			}
+		case errors.Is(err, syscall.ENOBUFS):
+			// Notifications were dropped; assume one of them was a change
+			if pending.IsZero() {
+				pending = time.Now()
+			}
+		case errors.Is(err, syscall.EAGAIN), errors.Is(err, syscall.EINTR):
+			// Read timeout
+		default:
+			return
+		}
+
+		if !pending.IsZero() && time.Since(pending) >= settleDelay {
+			pending = time.Time{}
+			changed()
+		}
+	}
+}
+
+// hasChange reports whether a batch of rtnetlink messages includes an
+// address being added or removed, a link being removed, or a link going up
+// or down
+func (w *watcher) hasChange(b []byte) bool {
+	msgs, err := syscall.ParseNetlinkMessage(b)
+	if err != nil {
+		return false
+	}
+	change := false
+	for _, msg := range msgs {
+		switch msg.Header.Type {
+		case syscall.RTM_NEWADDR, syscall.RTM_DELADDR:
+			change = true
+		case syscall.RTM_DELLINK:
+			if index, _, ok := linkState(msg.Data); ok {
+				delete(w.up, index)
+			}
+			change = true
+		case syscall.RTM_NEWLINK:
+			index, up, ok := linkState(msg.Data)
+			if !ok {
+				change = true
+				continue
+			}
+			if was, known := w.up[index]; !known || was != up {
+				w.up[index] = up
+				change = true
+			}
+		}
+	}
+	return change
+}
+
+// linkState decodes the interface index and whether the link is up from the
+// ifinfomsg header of a link message
+func linkState(data []byte) (index int32, up bool, ok bool) {
+	if len(data) < syscall.SizeofIfInfomsg {
+		return 0, false, false
+	}
+	index = int32(binary.NativeEndian.Uint32(data[4:8])) // #nosec G115 -- ifi_index is an int in the kernel
+	flags := binary.NativeEndian.Uint32(data[8:12])
+	return index, flags&syscall.IFF_UP != 0, true
+}
@@ -0,0 +1,55 @@
+package netwatch
+
+import (
+	"context"
+	"encoding/binary"
+	"syscall"
+	"testing"
+)
+
+// netlinkMessage builds a netlink message of type typ with data as its body
+func netlinkMessage(typ uint16, data []byte) []byte {
+	b := make([]byte, syscall.NLMSG_HDRLEN, syscall.NLMSG_HDRLEN+len(data))
+	binary.NativeEndian.PutUint32(b[0:4], uint32(syscall.NLMSG_HDRLEN+len(data)))
+	binary.NativeEndian.PutUint16(b[4:6], typ)
+	return append(b, data...)
+}
+
+// linkMessage builds an RTM_NEWLINK message for interface index
+func linkMessage(index int32, flags uint32) []byte {
+	data := make([]byte, syscall.SizeofIfInfomsg)
+	binary.NativeEndian.PutUint32(data[4:8], uint32(index))
+	binary.NativeEndian.PutUint32(data[8:12], flags)
+	return netlinkMessage(syscall.RTM_NEWLINK, data)
+}
+
+func TestHasChange(t *testing.T) {
+	w := &watcher{up: make(map[int32]bool)}
+	tests := []struct {
+		name string
+		msg  []byte
+		want bool
+	}{
+		{"new address", netlinkMessage(syscall.RTM_NEWADDR, nil), true},
+		{"route", netlinkMessage(syscall.RTM_NEWROUTE, nil), false},
+		{"new link", linkMessage(2, syscall.IFF_UP), true},
+		{"link unchanged", linkMessage(2, syscall.IFF_UP|syscall.IFF_RUNNING), false},
+		{"link down", linkMessage(2, 0), true},
+		{"link removed", netlinkMessage(syscall.RTM_DELLINK, nil), true},
+		{"truncated", []byte{1, 2, 3}, false},
+	}
+	for _, tt := range tests {
+		if got := w.hasChange(tt.msg); got != tt.want {
+			t.Errorf("%s: hasChange() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := Watch(ctx, func() {}); err != nil {
+		cancel()
+		t.Skipf("netlink unavailable: %v", err)
+	}
+	cancel()
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package netwatch
+
+import (
+	"context"
+	"errors"
+)
+
+// Watch is only supported on Linux, which has netlink
+func Watch(ctx context.Context, changed func()) error {
+	return errors.ErrUnsupported
+}
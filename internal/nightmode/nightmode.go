@@ -0,0 +1,141 @@
+package nightmode
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// checkInterval is how often the schedule is evaluated
+const checkInterval = 30 * time.Second
+
+// Config holds the night schedule
+type Config struct {
+	Start     string // "HH:MM" (24-hour) when night begins
+	End       string // "HH:MM" (24-hour) when night ends; may be earlier than Start
+	ClockOnly bool   // replace rotation with the clock during the night
+}
+
+// Controller switches night mode on and off on a daily schedule. It is
+// independent of the idle-based screensaver: apply is called on every
+// transition and is expected to route the brightness change through the
+// screensaver so the two never fight.
+type Controller struct {
+	cfg   Config
+	apply func(night bool)
+	log   *logger.Logger
+	now   func() time.Time
+
+	mu       sync.RWMutex
+	night    bool
+	applied  bool
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a controller that calls apply when night begins or ends
+func New(cfg Config, apply func(night bool), log *logger.Logger) *Controller {
+	return &Controller{
+		cfg:      cfg,
+		apply:    apply,
+		log:      log,
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start evaluates the schedule immediately and then every checkInterval
+func (c *Controller) Start(ctx context.Context) {
+	c.check()
+
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		defer func() {
+			if r := recover(); r != nil {
+				c.log.Errorf("PANIC in night mode: %v", r)
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.stopChan:
+				return
+			case <-ticker.C:
+				c.check()
+			}
+		}
+	}()
+}
+
+// Stop stops evaluating the schedule
+func (c *Controller) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+	})
+}
+
+// Active reports whether it is currently night
+func (c *Controller) Active() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.night
+}
+
+// ShowClock reports whether the clock should replace normal rotation, i.e.
+// it is night and clock_only is set
+func (c *Controller) ShowClock() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.night && c.cfg.ClockOnly
+}
+
+// check applies the schedule for the current time
+func (c *Controller) check() {
+	night := c.inNight(c.now())
+
+	c.mu.Lock()
+	changed := !c.applied || night != c.night
+	c.night, c.applied = night, true
+	c.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if night {
+		c.log.With().Str("until", c.cfg.End).Logger().Info("Night mode on")
+	} else {
+		c.log.With().Str("from", c.cfg.Start).Logger().Info("Night mode off")
+	}
+	c.apply(night)
+}
+
+// inNight reports whether t falls within the night window
+func (c *Controller) inNight(t time.Time) bool {
+	startH, startM := parseHHMM(c.cfg.Start)
+	endH, endM := parseHHMM(c.cfg.End)
+
+	startMins := startH*60 + startM
+	endMins := endH*60 + endM
+	nowMins := t.Hour()*60 + t.Minute()
+
+	if startMins < endMins {
+		// Same-day range e.g. 01:00-06:00
+		return nowMins >= startMins && nowMins < endMins
+	}
+	// Overnight range e.g. 22:00-07:00
+	return nowMins >= startMins || nowMins < endMins
+}
+
+// parseHHMM parses "HH:MM" into hour and minute.
+// Input is assumed valid (validated at config load time).
+func parseHHMM(s string) (hour, minute int) {
+	var h, m int
+	_, _ = fmt.Sscanf(s, "%d:%d", &h, &m)
+	return h, m
+}
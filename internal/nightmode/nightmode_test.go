@@ -0,0 +1,66 @@
+package nightmode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+func at(hour, minute int) time.Time {
+	return time.Date(2026, 1, 15, hour, minute, 0, 0, time.Local)
+}
+
+func TestInNight(t *testing.T) {
+	tests := []struct {
+		start, end string
+		t          time.Time
+		want       bool
+	}{
+		{"22:00", "07:00", at(23, 30), true},
+		{"22:00", "07:00", at(3, 0), true},
+		{"22:00", "07:00", at(7, 0), false},
+		{"22:00", "07:00", at(12, 0), false},
+		{"01:00", "06:00", at(2, 15), true},
+		{"01:00", "06:00", at(0, 59), false},
+	}
+	for _, tt := range tests {
+		c := New(Config{Start: tt.start, End: tt.end}, func(bool) {}, logger.NewDefault())
+		if got := c.inNight(tt.t); got != tt.want {
+			t.Errorf("%s-%s at %s: got %v, want %v", tt.start, tt.end, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestCheckAppliesTransitions(t *testing.T) {
+	var applied []bool
+	c := New(Config{Start: "22:00", End: "07:00", ClockOnly: true}, func(night bool) {
+		applied = append(applied, night)
+	}, logger.NewDefault())
+
+	now := at(21, 59)
+	c.now = func() time.Time { return now }
+
+	c.check() // initial state is always applied
+	c.check() // unchanged
+	if len(applied) != 1 || applied[0] {
+		t.Fatalf("expected a single day transition, got %v", applied)
+	}
+	if c.ShowClock() {
+		t.Error("expected no clock during the day")
+	}
+
+	now = at(22, 0)
+	c.check()
+	if len(applied) != 2 || !applied[1] {
+		t.Fatalf("expected night to begin at 22:00, got %v", applied)
+	}
+	if !c.Active() || !c.ShowClock() {
+		t.Error("expected night mode active with the clock shown")
+	}
+
+	c.cfg.ClockOnly = false
+	if c.ShowClock() {
+		t.Error("expected no clock at night without clock_only")
+	}
+}
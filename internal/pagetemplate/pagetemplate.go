@@ -0,0 +1,104 @@
+// Package pagetemplate parses the Go text/template lines of template pages,
+// with a few functions for formatting stats compactly.
+package pagetemplate
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Funcs are the functions available to page templates besides the
+// text/template built-ins
+var Funcs = template.FuncMap{
+	"percent":  percent,
+	"bytes":    formatBytes,
+	"duration": formatDuration,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+}
+
+// Parse parses a template line. name identifies it in errors.
+func Parse(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(Funcs).Option("missingkey=error").Parse(text)
+}
+
+// percent returns used as a percentage of total, or 0 when total is 0
+func percent(used, total any) (float64, error) {
+	u, err := toFloat(used)
+	if err != nil {
+		return 0, err
+	}
+	t, err := toFloat(total)
+	if err != nil {
+		return 0, err
+	}
+	if t == 0 {
+		return 0, nil
+	}
+	return u / t * 100, nil
+}
+
+// formatBytes formats a byte count with a binary unit: "512B", "1.5K",
+// "3.8G"
+func formatBytes(n any) (string, error) {
+	v, err := toFloat(n)
+	if err != nil {
+		return "", err
+	}
+	const units = "KMGTPE"
+	if v < 1024 {
+		return fmt.Sprintf("%.0fB", v), nil
+	}
+	unit := -1
+	for v >= 1024 && unit < len(units)-1 {
+		v /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f%c", v, units[unit]), nil
+}
+
+// formatDuration formats a duration in its two largest units: "3d 4h",
+// "2h 5m", "45s"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := d / (24 * time.Hour)
+	hours := d % (24 * time.Hour) / time.Hour
+	minutes := d % time.Hour / time.Minute
+	seconds := d % time.Minute / time.Second
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
+
+// toFloat converts the numeric types found in the stats to float64
+func toFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	case uint32:
+		return float64(n), nil
+	case uint8:
+		return float64(n), nil
+	case time.Duration:
+		return float64(n), nil
+	default:
+		return 0, fmt.Errorf("expected a number, got %T", v)
+	}
+}
@@ -0,0 +1,48 @@
+package pagetemplate
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFuncs(t *testing.T) {
+	data := struct {
+		Used, Total uint64
+		Uptime      time.Duration
+		Temp        float64
+	}{Used: 3 << 29, Total: 4 << 30, Uptime: 76*time.Hour + 5*time.Minute, Temp: 48.6}
+
+	tests := []struct {
+		text, want string
+	}{
+		{`{{printf "%.0f" (percent .Used .Total)}}%`, "38%"},
+		{`{{bytes .Used}}/{{bytes .Total}}`, "1.5G/4.0G"},
+		{`{{bytes 512}}`, "512B"},
+		{`up {{duration .Uptime}}`, "up 3d 4h"},
+		{`{{if gt .Temp 45.0}}{{upper "hot"}}{{end}}`, "HOT"},
+	}
+	for _, tt := range tests {
+		tmpl, err := Parse("line", tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.text, err)
+			continue
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			t.Errorf("Execute(%q) failed: %v", tt.text, err)
+			continue
+		}
+		if b.String() != tt.want {
+			t.Errorf("%q = %q, want %q", tt.text, b.String(), tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{"{{.Hostname", "{{unknown .CPUTemp}}"} {
+		if _, err := Parse("line", text); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", text)
+		}
+	}
+}
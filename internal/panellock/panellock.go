@@ -0,0 +1,151 @@
+// Package panellock keeps two daemons from driving the same display. Each
+// panel has a lock file under /run/lock holding an flock and the owner's PID.
+package panellock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+// DefaultDir is where lock files are created
+const DefaultDir = "/run/lock"
+
+// pollInterval is how often Takeover retries while the old owner exits
+const pollInterval = 100 * time.Millisecond
+
+// HeldError is returned when another process holds the lock
+type HeldError struct {
+	Path string
+	PID  int // 0 if the owner's PID could not be read
+}
+
+func (e *HeldError) Error() string {
+	if e.PID > 0 {
+		return fmt.Sprintf("display is in use by process %d (lock %s)", e.PID, e.Path)
+	}
+	return fmt.Sprintf("display is in use by another process (lock %s)", e.Path)
+}
+
+// Lock is a held panel lock
+type Lock struct {
+	file *os.File
+}
+
+// Path returns the lock file path for the panel described by d in dir.
+// I2C panels are identified by bus and address, so several panels can share
+// a bus; SPI panels by their bus and framebuffers by their device.
+func Path(dir string, d *config.DisplayConfig) string {
+	id := d.I2CBus + "-" + d.I2CAddress
+	switch {
+	case d.IsSPI():
+		id = d.SPIBus
+	case d.IsFramebuffer():
+		id = d.FBDevice
+	}
+	return filepath.Join(dir, "i2c-display-"+sanitize(id)+".lock")
+}
+
+// sanitize turns a bus or address into a file name component
+func sanitize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, s)
+	return strings.Trim(s, "-")
+}
+
+// Acquire takes the lock at path without waiting. If another process holds
+// it a *HeldError is returned.
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { // #nosec G301 -- standard lock directory permissions
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644) // #nosec G302 G304 -- lock file readable so others can see the owner
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, &HeldError{Path: path, PID: readPID(path)}
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	// Record our PID for error messages and -takeover
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &Lock{file: f}, nil
+}
+
+// Takeover acquires the lock at path, asking a current owner to exit with
+// SIGTERM and waiting up to timeout for it to let go
+func Takeover(path string, timeout time.Duration) (*Lock, error) {
+	return takeover(path, timeout, func(pid int) error {
+		return syscall.Kill(pid, syscall.SIGTERM)
+	})
+}
+
+// takeover implements Takeover with a replaceable signal function
+func takeover(path string, timeout time.Duration, signal func(pid int) error) (*Lock, error) {
+	lock, err := Acquire(path)
+	var held *HeldError
+	if !errors.As(err, &held) {
+		return lock, err
+	}
+	if held.PID <= 0 || held.PID == os.Getpid() {
+		return nil, err
+	}
+	if err := signal(held.PID); err != nil {
+		return nil, fmt.Errorf("failed to signal process %d: %w", held.PID, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(pollInterval)
+		lock, err = Acquire(path)
+		if !errors.As(err, &held) {
+			return lock, err
+		}
+	}
+	return nil, fmt.Errorf("process %d did not release the display within %s", held.PID, timeout)
+}
+
+// Release drops the lock. The file is left in place: removing it would let
+// a process that already opened it lock a file nobody else can see.
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	_ = l.file.Truncate(0)
+	err := l.file.Close() // closing the descriptor drops the flock
+	l.file = nil
+	return err
+}
+
+// readPID returns the PID recorded in the lock file, or 0
+func readPID(path string) int {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is our own lock file
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
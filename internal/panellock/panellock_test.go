@@ -0,0 +1,102 @@
+package panellock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.DisplayConfig
+		want string
+	}{
+		{"i2c", config.DisplayConfig{Type: "ssd1306", I2CBus: "/dev/i2c-1", I2CAddress: "0x3C"}, "i2c-display-dev-i2c-1-0x3c.lock"},
+		{"spi", config.DisplayConfig{Type: "st7735", SPIBus: "/dev/spidev0.0", I2CBus: "/dev/i2c-1"}, "i2c-display-dev-spidev0.0.lock"},
+		{"fbdev", config.DisplayConfig{Type: "fbdev", FBDevice: "/dev/fb1", I2CBus: "/dev/i2c-1"}, "i2c-display-dev-fb1.lock"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Path("/run/lock", &tt.cfg); got != filepath.Join("/run/lock", tt.want) {
+				t.Errorf("Path() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcquireHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "panel.lock")
+
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	// flock is per open file, so a second open in this process conflicts
+	_, err = Acquire(path)
+	var held *HeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("second Acquire() error = %v, want HeldError", err)
+	}
+	if held.PID != os.Getpid() {
+		t.Errorf("HeldError.PID = %d, want %d", held.PID, os.Getpid())
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	lock, err = Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() after Release error = %v", err)
+	}
+	_ = lock.Release()
+}
+
+func TestTakeover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "panel.lock")
+	old, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	// Pretend another process owns the lock
+	if err := os.WriteFile(path, []byte("4242\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var signalled int
+	lock, err := takeover(path, time.Second, func(pid int) error {
+		signalled = pid
+		// The old owner exits shortly after being signalled
+		time.AfterFunc(150*time.Millisecond, func() { _ = old.Release() })
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("takeover() error = %v", err)
+	}
+	defer lock.Release()
+	if signalled != 4242 {
+		t.Errorf("signalled pid %d, want 4242", signalled)
+	}
+}
+
+func TestTakeoverTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "panel.lock")
+	old, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer old.Release()
+	if err := os.WriteFile(path, []byte("4242\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = takeover(path, 250*time.Millisecond, func(int) error { return nil })
+	if err == nil {
+		t.Fatal("takeover() succeeded while the lock was still held")
+	}
+}
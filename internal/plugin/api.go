@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// defaultColor is used when a drawing call gives no colour
+const defaultColor = "white"
+
+// newScreen exposes canvas to a script as the screen argument of render:
+//
+//	screen.width, screen.height, screen.line_height, screen.small_line_height
+//	screen.text(x, y, text, color="white", small=False)
+//	screen.centered(y, text, color="white", small=False)
+//	screen.measure(text, small=False) -> width in pixels
+//	screen.rect(x, y, width, height, color="white", fill=False)
+//	screen.pixel(x, y, color="white")
+//
+// Colours are names or "#rrggbb" values.
+func newScreen(canvas Canvas) starlark.Value {
+	width, height := canvas.Size()
+
+	text := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var x, y int
+		var s string
+		colorName, small := defaultColor, false
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "x", &x, "y", &y, "text", &s, "color?", &colorName, "small?", &small); err != nil {
+			return nil, err
+		}
+		c, err := canvas.ParseColor(colorName)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.None, canvas.Text(x, y, s, c, small)
+	}
+
+	centered := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var y int
+		var s string
+		colorName, small := defaultColor, false
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "y", &y, "text", &s, "color?", &colorName, "small?", &small); err != nil {
+			return nil, err
+		}
+		c, err := canvas.ParseColor(colorName)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.None, canvas.CenteredText(y, s, c, small)
+	}
+
+	measure := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var s string
+		small := false
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "text", &s, "small?", &small); err != nil {
+			return nil, err
+		}
+		return starlark.MakeInt(canvas.Measure(s, small)), nil
+	}
+
+	rect := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var x, y, w, h int
+		colorName, fill := defaultColor, false
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "x", &x, "y", &y, "width", &w, "height", &h, "color?", &colorName, "fill?", &fill); err != nil {
+			return nil, err
+		}
+		c, err := canvas.ParseColor(colorName)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.None, canvas.Rect(x, y, w, h, c, fill)
+	}
+
+	pixel := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var x, y int
+		colorName := defaultColor
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "x", &x, "y", &y, "color?", &colorName); err != nil {
+			return nil, err
+		}
+		c, err := canvas.ParseColor(colorName)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.None, canvas.Pixel(x, y, c)
+	}
+
+	return starlarkstruct.FromStringDict(starlark.String("screen"), starlark.StringDict{
+		"width":             starlark.MakeInt(width),
+		"height":            starlark.MakeInt(height),
+		"line_height":       starlark.MakeInt(canvas.LineHeight(false)),
+		"small_line_height": starlark.MakeInt(canvas.LineHeight(true)),
+		"text":              starlark.NewBuiltin("text", text),
+		"centered":          starlark.NewBuiltin("centered", centered),
+		"measure":           starlark.NewBuiltin("measure", measure),
+		"rect":              starlark.NewBuiltin("rect", rect),
+		"pixel":             starlark.NewBuiltin("pixel", pixel),
+	})
+}
+
+// statsValue exposes the collected statistics to a script as the stats
+// argument of render. Values are copied, so scripts cannot modify them.
+func statsValue(s *stats.SystemStats) starlark.Value {
+	interfaces := make([]starlark.Value, 0, len(s.Interfaces))
+	for _, iface := range s.Interfaces {
+		interfaces = append(interfaces, starlarkstruct.FromStringDict(starlark.String("interface"), starlark.StringDict{
+			"name": starlark.String(iface.Name),
+			"ipv4": stringList(iface.IPv4Addrs),
+			"ipv6": stringList(iface.IPv6Addrs),
+		}))
+	}
+
+	temperatures := starlark.NewDict(len(s.Temperatures))
+	for _, t := range s.Temperatures {
+		_ = temperatures.SetKey(starlark.String(t.Name), starlark.Float(t.Value))
+	}
+	temperatures.Freeze()
+
+	return starlarkstruct.FromStringDict(starlark.String("stats"), starlark.StringDict{
+		"hostname":            starlark.String(s.Hostname),
+		"cpu_temp":            starlark.Float(s.CPUTemp),
+		"memory_used":         starlark.MakeUint64(s.MemoryUsed),
+		"memory_total":        starlark.MakeUint64(s.MemoryTotal),
+		"memory_percent":      starlark.Float(s.MemoryPercent()),
+		"disk_used":           starlark.MakeUint64(s.DiskUsed),
+		"disk_total":          starlark.MakeUint64(s.DiskTotal),
+		"disk_percent":        starlark.Float(s.DiskPercent()),
+		"disk_inodes_percent": starlark.Float(s.DiskInodePercent()),
+		"disk_read_only":      starlark.Bool(s.DiskReadOnly),
+		"load1":               starlark.Float(s.LoadAvg1),
+		"load5":               starlark.Float(s.LoadAvg5),
+		"load15":              starlark.Float(s.LoadAvg15),
+		"num_cpu":             starlark.MakeInt(s.NumCPU),
+		"interfaces":          frozenList(interfaces),
+		"temperatures":        temperatures,
+	})
+}
+
+func stringList(values []string) *starlark.List {
+	elems := make([]starlark.Value, 0, len(values))
+	for _, v := range values {
+		elems = append(elems, starlark.String(v))
+	}
+	return frozenList(elems)
+}
+
+func frozenList(elems []starlark.Value) *starlark.List {
+	l := starlark.NewList(elems)
+	l.Freeze()
+	return l
+}
@@ -0,0 +1,200 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+const (
+	// ScriptExt is the file extension of page scripts
+	ScriptExt = ".star"
+
+	// maxSteps bounds the Starlark execution steps of a single load or
+	// render call, so a runaway loop cannot stall the display
+	maxSteps = 1_000_000
+	// callTimeout cancels a load or render call that runs longer than this
+	callTimeout = 250 * time.Millisecond
+)
+
+// Canvas is the drawing surface a script renders to. The renderer supplies
+// an implementation backed by the display.
+type Canvas interface {
+	Size() (width, height int)
+	LineHeight(small bool) int
+	Measure(text string, small bool) int
+	Text(x, y int, text string, c color.NRGBA, small bool) error
+	CenteredText(y int, text string, c color.NRGBA, small bool) error
+	Rect(x, y, width, height int, c color.NRGBA, fill bool) error
+	Pixel(x, y int, c color.NRGBA) error
+	ParseColor(s string) (color.NRGBA, error)
+}
+
+// Script is a loaded page script. Scripts are Starlark programs that define
+// render(screen, stats) and optionally a title. They run sandboxed: there
+// is no load(), file, network or process access, and every call is bounded
+// by maxSteps and callTimeout. A failing script only affects its own page.
+type Script struct {
+	name   string // file name, for logs
+	title  string
+	render starlark.Callable
+	log    *logger.Logger
+
+	mu      sync.Mutex // serializes render calls and protects lastErr
+	lastErr string
+}
+
+// LoadDir loads every script in dir, in file name order. A missing
+// directory yields no scripts. Scripts that fail to load are logged and
+// skipped so one broken file does not take the others down.
+func LoadDir(dir string, log *logger.Logger) ([]*Script, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin directory %s: %w", dir, err)
+	}
+
+	var scripts []*Script
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ScriptExt {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		script, err := Load(path, log)
+		if err != nil {
+			log.With().Str("script", path).Err(err).Logger().Warn("Skipping page script that failed to load")
+			continue
+		}
+		log.With().Str("script", path).Str("title", script.title).Logger().Info("Loaded page script")
+		scripts = append(scripts, script)
+	}
+	return scripts, nil
+}
+
+// Load loads a single script from path
+func Load(path string, log *logger.Logger) (*Script, error) {
+	src, err := os.ReadFile(path) // #nosec G304 -- path is under the configured plugin directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	name := filepath.Base(path)
+	thread, done := newThread(name, log)
+	defer done()
+
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, name, src, nil)
+	if err != nil {
+		return nil, describe(err)
+	}
+
+	render, ok := globals["render"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("script must define render(screen, stats)")
+	}
+
+	title := strings.TrimSuffix(name, ScriptExt)
+	if v, ok := globals["title"]; ok {
+		s, ok := starlark.AsString(v)
+		if !ok || s == "" {
+			return nil, fmt.Errorf("title must be a non-empty string")
+		}
+		title = s
+	}
+
+	return &Script{name: name, title: title, render: render, log: log}, nil
+}
+
+// Title returns the page title, from the script's title global or else
+// its file name
+func (s *Script) Title() string {
+	return s.title
+}
+
+// Render runs the script's render function against canvas. Errors, step
+// limit and timeout overruns and panics in drawing callbacks are all
+// returned as errors; the first occurrence of each distinct error is logged.
+func (s *Script) Render(canvas Canvas, st *stats.SystemStats) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		s.report(err)
+	}()
+
+	thread, done := newThread(s.name, s.log)
+	defer done()
+
+	_, err = starlark.Call(thread, s.render, starlark.Tuple{newScreen(canvas), statsValue(st)}, nil)
+	return describe(err)
+}
+
+// report logs err when it differs from the previous call's outcome.
+// Callers hold mu.
+func (s *Script) report(err error) {
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	if msg == s.lastErr {
+		return
+	}
+	switch {
+	case err != nil:
+		s.log.With().Str("script", s.name).Err(err).Logger().Warn("Page script failed")
+	case s.lastErr != "":
+		s.log.With().Str("script", s.name).Logger().Info("Page script recovered")
+	}
+	s.lastErr = msg
+}
+
+// newThread creates a sandboxed thread for one call. The returned func
+// must be called when the call finishes to stop its timeout.
+func newThread(name string, log *logger.Logger) (*starlark.Thread, func()) {
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(_ *starlark.Thread, msg string) {
+			log.With().Str("script", name).Logger().Debug(msg)
+		},
+		// Load is left nil, so load() statements fail
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+	timer := time.AfterFunc(callTimeout, func() {
+		thread.Cancel("timed out after " + callTimeout.String())
+	})
+	return thread, func() { timer.Stop() }
+}
+
+// describe trims Starlark evaluation errors to their message and location,
+// dropping the backtrace
+func describe(err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		// Report the innermost position in the script, skipping builtins
+		for i := range evalErr.CallStack {
+			if pos := evalErr.CallStack.At(i).Pos; pos.Line > 0 {
+				return fmt.Errorf("%s:%d: %s", pos.Filename(), pos.Line, evalErr.Msg)
+			}
+		}
+		return errors.New(evalErr.Msg)
+	}
+	return err
+}
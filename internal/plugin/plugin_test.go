@@ -0,0 +1,155 @@
+package plugin
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// recordingCanvas records drawing calls as strings
+type recordingCanvas struct {
+	calls []string
+}
+
+func (c *recordingCanvas) Size() (int, int)                { return 128, 64 }
+func (c *recordingCanvas) LineHeight(small bool) int       { return 13 }
+func (c *recordingCanvas) Measure(text string, _ bool) int { return 7 * len(text) }
+
+func (c *recordingCanvas) Text(x, y int, text string, col color.NRGBA, small bool) error {
+	c.calls = append(c.calls, fmt.Sprintf("text %d,%d %q %v", x, y, text, small))
+	return nil
+}
+
+func (c *recordingCanvas) CenteredText(y int, text string, col color.NRGBA, small bool) error {
+	c.calls = append(c.calls, fmt.Sprintf("centered %d %q", y, text))
+	return nil
+}
+
+func (c *recordingCanvas) Rect(x, y, w, h int, col color.NRGBA, fill bool) error {
+	c.calls = append(c.calls, fmt.Sprintf("rect %d,%d %dx%d %v", x, y, w, h, fill))
+	return nil
+}
+
+func (c *recordingCanvas) Pixel(x, y int, col color.NRGBA) error {
+	c.calls = append(c.calls, fmt.Sprintf("pixel %d,%d", x, y))
+	return nil
+}
+
+func (c *recordingCanvas) ParseColor(s string) (color.NRGBA, error) {
+	if s != "white" && s != "red" {
+		return color.NRGBA{}, fmt.Errorf("invalid colour %q", s)
+	}
+	return color.NRGBA{A: 255}, nil
+}
+
+func writeScript(t *testing.T, dir, name, src string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScriptRender(t *testing.T) {
+	path := writeScript(t, t.TempDir(), "host.star", `
+title = "Host"
+
+def render(screen, stats):
+    screen.centered(0, stats.hostname)
+    screen.text(2, screen.line_height, "load " + str(stats.load1), small=True)
+    screen.rect(0, 30, screen.width, 4, fill=True, color="red")
+    for name, value in stats.temperatures.items():
+        screen.text(2, 40, "%s %d" % (name, value))
+`)
+	script, err := Load(path, logger.NewDefault())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if script.Title() != "Host" {
+		t.Errorf("Title() = %q, want %q", script.Title(), "Host")
+	}
+
+	canvas := &recordingCanvas{}
+	err = script.Render(canvas, &stats.SystemStats{
+		Hostname:     "pi",
+		LoadAvg1:     0.25,
+		Temperatures: []stats.TempReading{{Name: "nvme", Value: 41}},
+	})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want := []string{
+		`centered 0 "pi"`,
+		`text 2,13 "load 0.25" true`,
+		`rect 0,30 128x4 true`,
+		`text 2,40 "nvme 41" false`,
+	}
+	if strings.Join(canvas.calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls =\n%s\nwant\n%s", strings.Join(canvas.calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestScriptTitleDefaultsToFileName(t *testing.T) {
+	path := writeScript(t, t.TempDir(), "docker.star", "def render(screen, stats):\n    pass\n")
+	script, err := Load(path, logger.NewDefault())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if script.Title() != "docker" {
+		t.Errorf("Title() = %q, want %q", script.Title(), "docker")
+	}
+}
+
+func TestScriptSandbox(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{"no render", "title = 'x'\n", "must define render"},
+		{"load", "load('other.star', 'x')\ndef render(screen, stats):\n    pass\n", "load"},
+		{"runaway loop", "def render(screen, stats):\n    for i in range(100000000):\n        pass\n", "too many steps"},
+		{"frozen stats", "def render(screen, stats):\n    stats.interfaces.append(1)\n", "s3.star:2: append: cannot append to frozen list"},
+		{"bad colour", "def render(screen, stats):\n    screen.text(0, 0, 'x', color='plaid')\n", "invalid colour"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScript(t, dir, fmt.Sprintf("s%d.star", i), tt.src)
+			script, err := Load(path, logger.NewDefault())
+			if err == nil {
+				err = script.Render(&recordingCanvas{}, &stats.SystemStats{})
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadDirSkipsBrokenScripts(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "a.star", "def render(screen, stats):\n    pass\n")
+	writeScript(t, dir, "b.star", "def render(screen, stats)\n")
+	writeScript(t, dir, "c.txt", "not a script")
+
+	scripts, err := LoadDir(dir, logger.NewDefault())
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if len(scripts) != 1 || scripts[0].Title() != "a" {
+		t.Errorf("expected only a.star loaded, got %d scripts", len(scripts))
+	}
+
+	scripts, err = LoadDir(filepath.Join(dir, "missing"), logger.NewDefault())
+	if err != nil || scripts != nil {
+		t.Errorf("expected no scripts and no error for a missing directory, got %v, %v", scripts, err)
+	}
+}
@@ -0,0 +1,413 @@
+// Package qrcode encodes short byte strings, such as URLs, as QR codes. It
+// covers what a status display needs: byte mode at error correction level M
+// in versions 1 to 10, up to 213 bytes.
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxLen is the longest input Encode accepts, in bytes
+const MaxLen = 213
+
+// Code is an encoded QR symbol
+type Code struct {
+	Size    int // modules per side, without a quiet zone
+	Version int
+	modules []bool
+}
+
+// Dark reports whether the module at column x, row y is dark. Coordinates
+// outside the symbol are light, as the quiet zone around it must be.
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y*c.Size+x]
+}
+
+// version describes the level M block structure of one QR version
+type version struct {
+	groups    [][2]int // blocks and data codewords per block, per group
+	ecPer     int      // error correction codewords per block
+	align     []int    // alignment pattern centre coordinates
+	remainder int      // bits left over after the codewords
+}
+
+var versions = []version{
+	1:  {[][2]int{{1, 16}}, 10, nil, 0},
+	2:  {[][2]int{{1, 28}}, 16, []int{6, 18}, 7},
+	3:  {[][2]int{{1, 44}}, 26, []int{6, 22}, 7},
+	4:  {[][2]int{{2, 32}}, 18, []int{6, 26}, 7},
+	5:  {[][2]int{{2, 43}}, 24, []int{6, 30}, 7},
+	6:  {[][2]int{{4, 27}}, 16, []int{6, 34}, 7},
+	7:  {[][2]int{{4, 31}}, 18, []int{6, 22, 38}, 0},
+	8:  {[][2]int{{2, 38}, {2, 39}}, 22, []int{6, 24, 42}, 0},
+	9:  {[][2]int{{3, 36}, {2, 37}}, 22, []int{6, 26, 46}, 0},
+	10: {[][2]int{{4, 43}, {1, 44}}, 26, []int{6, 28, 50}, 0},
+}
+
+// dataCodewords returns the number of data codewords the version holds
+func (v version) dataCodewords() int {
+	n := 0
+	for _, g := range v.groups {
+		n += g[0] * g[1]
+	}
+	return n
+}
+
+// capacity returns the most bytes the version holds in byte mode
+func (v version) capacity(ver int) int {
+	return (v.dataCodewords()*8 - 4 - countBits(ver)) / 8
+}
+
+// countBits returns the width of the byte mode character count
+func countBits(ver int) int {
+	if ver < 10 {
+		return 8
+	}
+	return 16
+}
+
+// Encode returns the smallest QR code holding data, with the mask that
+// scores best against the specification's penalty rules
+func Encode(data []byte) (*Code, error) {
+	if len(data) == 0 {
+		return nil, errors.New("qrcode: nothing to encode")
+	}
+	for ver := 1; ver < len(versions); ver++ {
+		if len(data) <= versions[ver].capacity(ver) {
+			var best *Code
+			bestScore := 0
+			for mask := 0; mask < 8; mask++ {
+				c := encode(data, ver, mask)
+				if score := c.penalty(); best == nil || score < bestScore {
+					best, bestScore = c, score
+				}
+			}
+			return best, nil
+		}
+	}
+	return nil, fmt.Errorf("qrcode: %d bytes is more than the %d a code can hold", len(data), MaxLen)
+}
+
+// encode builds the symbol for data in version ver with the given mask
+func encode(data []byte, ver, mask int) *Code {
+	v := versions[ver]
+	size := 17 + 4*ver
+	c := &Code{Size: size, Version: ver, modules: make([]bool, size*size)}
+	function := make([]bool, size*size)
+	set := func(x, y int, dark bool) {
+		c.modules[y*size+x] = dark
+		function[y*size+x] = true
+	}
+
+	c.drawFunctionPatterns(v, set)
+	c.drawFormat(mask, set)
+	if ver >= 7 {
+		c.drawVersion(set)
+	}
+
+	codewords := interleave(v, dataCodewords(data, ver, v.dataCodewords()))
+	c.drawCodewords(codewords, function)
+	c.applyMask(mask, function)
+	return c
+}
+
+// dataCodewords packs data in byte mode and pads it to n codewords
+func dataCodewords(data []byte, ver, n int) []byte {
+	var b bitBuffer
+	b.append(0b0100, 4)
+	b.append(len(data), countBits(ver))
+	for _, d := range data {
+		b.append(int(d), 8)
+	}
+	b.append(0, min(4, n*8-b.len())) // terminator
+	b.append(0, (8-b.len()%8)%8)
+	for pad := 0xEC; b.len() < n*8; pad ^= 0xEC ^ 0x11 {
+		b.append(pad, 8)
+	}
+	return b.bytes
+}
+
+// interleave splits data into the version's blocks, adds each block's
+// error correction and interleaves the result
+func interleave(v version, data []byte) []byte {
+	var blocks, ecBlocks [][]byte
+	for _, g := range v.groups {
+		for i := 0; i < g[0]; i++ {
+			block := data[:g[1]]
+			data = data[g[1]:]
+			blocks = append(blocks, block)
+			ecBlocks = append(ecBlocks, reedSolomon(block, v.ecPer))
+		}
+	}
+
+	var out []byte
+	longest := v.groups[len(v.groups)-1][1]
+	for i := 0; i < longest; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := 0; i < v.ecPer; i++ {
+		for _, ec := range ecBlocks {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+// drawFunctionPatterns draws the finder, separator, timing and alignment
+// patterns and the dark module
+func (c *Code) drawFunctionPatterns(v version, set func(x, y int, dark bool)) {
+	size := c.Size
+	for i := 0; i < size; i++ {
+		set(6, i, i%2 == 0)
+		set(i, 6, i%2 == 0)
+	}
+
+	for _, corner := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				if x < 0 || y < 0 || x >= size || y >= size {
+					continue
+				}
+				d := max(abs(dx), abs(dy))
+				set(x, y, d != 2 && d != 4)
+			}
+		}
+	}
+
+	n := len(v.align)
+	for i, ax := range v.align {
+		for j, ay := range v.align {
+			if (i == 0 && j == 0) || (i == 0 && j == n-1) || (i == n-1 && j == 0) {
+				continue // overlaps a finder pattern
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					set(ax+dx, ay+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+}
+
+// drawFormat draws both copies of the format information for level M and
+// mask, and the dark module beside the lower copy
+func (c *Code) drawFormat(mask int, set func(x, y int, dark bool)) {
+	const levelM = 0b00
+	data := levelM<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	size := c.Size
+	for i := 0; i <= 5; i++ {
+		set(8, i, bit(i))
+	}
+	set(8, 7, bit(6))
+	set(8, 8, bit(7))
+	set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		set(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		set(size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		set(8, size-15+i, bit(i))
+	}
+	set(8, size-8, true)
+}
+
+// drawVersion draws both copies of the version information, present from
+// version 7
+func (c *Code) drawVersion(set func(x, y int, dark bool)) {
+	rem := c.Version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	bits := c.Version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 == 1
+		a, b := c.Size-11+i%3, i/3
+		set(a, b, dark)
+		set(b, a, dark)
+	}
+}
+
+// drawCodewords places the codewords in the zigzag order, two columns at a
+// time from the bottom right, skipping function modules and the vertical
+// timing pattern. Remainder bits stay light.
+func (c *Code) drawCodewords(codewords []byte, function []bool) {
+	size := c.Size
+	i := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < size; vert++ {
+			y := vert
+			if upward {
+				y = size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if function[y*size+x] || i >= len(codewords)*8 {
+					continue
+				}
+				c.modules[y*size+x] = codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// applyMask inverts the non-function modules selected by the mask pattern
+func (c *Code) applyMask(mask int, function []bool) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if function[y*c.Size+x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				c.modules[y*c.Size+x] = !c.modules[y*c.Size+x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol by the specification's four mask evaluation
+// rules; lower is easier to scan
+func (c *Code) penalty() int {
+	size := c.Size
+	score := 0
+	finder := []bool{true, false, true, true, true, false, true}
+
+	for _, rows := range []bool{true, false} {
+		at := func(i, j int) bool {
+			if rows {
+				return c.Dark(j, i)
+			}
+			return c.Dark(i, j)
+		}
+		for i := 0; i < size; i++ {
+			// Runs of five or more modules of one colour
+			run := 1
+			for j := 1; j <= size; j++ {
+				if j < size && at(i, j) == at(i, j-1) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += 3 + run - 5
+				}
+				run = 1
+			}
+			// Finder-like 1:1:3:1:1 patterns with four light modules on
+			// either side
+			for j := 0; j+len(finder) <= size; j++ {
+				match := true
+				for k, dark := range finder {
+					if at(i, j+k) != dark {
+						match = false
+						break
+					}
+				}
+				if match && (c.lightRun(at, i, j-4, j) || c.lightRun(at, i, j+7, j+11)) {
+					score += 40
+				}
+			}
+		}
+	}
+
+	// 2x2 blocks of one colour
+	dark := 0
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if c.Dark(x, y) {
+				dark++
+			}
+			if x+1 < size && y+1 < size {
+				v := c.Dark(x, y)
+				if c.Dark(x+1, y) == v && c.Dark(x, y+1) == v && c.Dark(x+1, y+1) == v {
+					score += 3
+				}
+			}
+		}
+	}
+
+	// Balance of dark and light modules, in 5% steps away from half
+	total := size * size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return score + max(k, 0)*10
+}
+
+// lightRun reports whether modules from to to (exclusive) along line i are
+// all light; those outside the symbol count as the light quiet zone
+func (c *Code) lightRun(at func(i, j int) bool, i, from, to int) bool {
+	for j := from; j < to; j++ {
+		if at(i, j) {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// bitBuffer accumulates bits most significant first
+type bitBuffer struct {
+	bytes []byte
+	n     int
+}
+
+func (b *bitBuffer) len() int { return b.n }
+
+// append adds the low n bits of v
+func (b *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if b.n%8 == 0 {
+			b.bytes = append(b.bytes, 0)
+		}
+		if v>>i&1 == 1 {
+			b.bytes[b.n/8] |= 0x80 >> (b.n % 8)
+		}
+		b.n++
+	}
+}
@@ -0,0 +1,95 @@
+package qrcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// Worked example from ISO/IEC 18004 annex I: "01234567" as 1-M
+	data := []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	want := []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55}
+	if got := reedSolomon(data, 10); !bytes.Equal(got, want) {
+		t.Errorf("reedSolomon() = % X, want % X", got, want)
+	}
+}
+
+func TestEncodeVersions(t *testing.T) {
+	tests := []struct {
+		n       int
+		version int
+	}{
+		{1, 1},
+		{14, 1},
+		{15, 2},
+		{26, 2},
+		{27, 3},
+		{122, 7},
+		{213, 10},
+	}
+	for _, tt := range tests {
+		c, err := Encode([]byte(strings.Repeat("a", tt.n)))
+		if err != nil {
+			t.Fatalf("Encode(%d bytes) failed: %v", tt.n, err)
+		}
+		if c.Version != tt.version || c.Size != 17+4*tt.version {
+			t.Errorf("Encode(%d bytes) = version %d size %d, want version %d", tt.n, c.Version, c.Size, tt.version)
+		}
+	}
+
+	if _, err := Encode([]byte(strings.Repeat("a", MaxLen+1))); err == nil {
+		t.Error("expected an error for input longer than MaxLen")
+	}
+	if _, err := Encode(nil); err == nil {
+		t.Error("expected an error for empty input")
+	}
+}
+
+func TestEncodeFunctionPatterns(t *testing.T) {
+	c, err := Encode([]byte("http://192.168.1.20:8080"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := c.Size
+
+	// Finder patterns: dark ring, light ring, dark 3x3 centre, light separator
+	for _, corner := range [][2]int{{0, 0}, {n - 7, 0}, {0, n - 7}} {
+		x, y := corner[0], corner[1]
+		if !c.Dark(x, y) || !c.Dark(x+6, y+6) || c.Dark(x+1, y+1) || !c.Dark(x+3, y+3) {
+			t.Errorf("finder pattern at %v is wrong", corner)
+		}
+	}
+	if c.Dark(7, 0) || c.Dark(0, 7) || c.Dark(n-8, 0) {
+		t.Error("expected light separators beside the finder patterns")
+	}
+	// Timing patterns alternate
+	for i := 8; i < n-8; i++ {
+		if c.Dark(i, 6) != (i%2 == 0) || c.Dark(6, i) != (i%2 == 0) {
+			t.Fatalf("timing pattern wrong at %d", i)
+		}
+	}
+	// The dark module and the quiet zone
+	if !c.Dark(8, n-8) {
+		t.Error("expected the dark module")
+	}
+	if c.Dark(-1, 0) || c.Dark(0, n) {
+		t.Error("expected modules outside the symbol to be light")
+	}
+}
+
+func TestEncodeVersionInformation(t *testing.T) {
+	c := encode(bytes.Repeat([]byte("a"), 100), 7, 0)
+	// Version 7's information is 000111 110010 010100, least significant
+	// bit first from the top left of the upper right block
+	const want = 0x07C94
+	got := 0
+	for i := 0; i < 18; i++ {
+		if c.Dark(c.Size-11+i%3, i/3) {
+			got |= 1 << i
+		}
+	}
+	if got != want {
+		t.Errorf("version information = %05X, want %05X", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package renderer
+
+import (
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// AlertPage shows active alert messages with a flashing border.
+// It is rendered in place of normal rotation while any alert is firing.
+type AlertPage struct {
+	messages []string
+	lines    int  // configured line count (0=auto, 2=default, 4=compact)
+	flash    bool // toggled on every render to blink the border
+}
+
+// NewAlertPage creates an alert page
+func NewAlertPage(lines int) *AlertPage {
+	return &AlertPage{lines: lines}
+}
+
+// SetMessages replaces the alert messages to display
+func (p *AlertPage) SetMessages(messages []string) {
+	p.messages = messages
+}
+
+// Title returns the page title
+func (p *AlertPage) Title() string {
+	return "Alert"
+}
+
+// Render draws the alert messages in red, blinking a border on alternate frames
+func (p *AlertPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	maxWidth := bounds.Dx() - 2*MarginLeft - 2
+
+	p.flash = !p.flash
+	if p.flash {
+		if err := disp.DrawRect(0, 0, bounds.Dx(), bounds.Dy(), false); err != nil {
+			return err
+		}
+	}
+
+	if layout.ShowHeader {
+		if err := DrawTextCenteredColorScaled(disp, layout.HeaderY, "! ALERT !", ColorRed, layout.TextScale); err != nil {
+			return err
+		}
+	}
+
+	small := layout.TextScale > 0 && layout.TextScale < 1
+	for i, msg := range p.messages {
+		if i >= len(layout.ContentLines) {
+			break
+		}
+		if small {
+			msg = TruncateTextSmall(msg, maxWidth)
+		} else {
+			msg = TruncateText(msg, maxWidth)
+		}
+		if err := DrawTextColorScaled(disp, MarginLeft+1, layout.ContentLines[i], msg, ColorRed, layout.TextScale); err != nil {
+			return err
+		}
+	}
+
+	return disp.Show()
+}
+
+// TextLines shows the alert messages under a banner
+func (p *AlertPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	return append([]string{centerText("! ALERT !", cols)}, p.messages...)
+}
@@ -0,0 +1,87 @@
+package renderer
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// Bar gauge sizing on the system page
+const (
+	barGap         = 4  // space between a metric's text and its bar
+	barMinWidth    = 24 // narrower bars are too coarse to read
+	barWideDisplay = 160
+)
+
+// DrawBar draws a horizontal gauge: a one-pixel outline in c, filled with c
+// from the left in proportion to percent (clamped to 0-100) and black for the
+// rest. Monochrome displays show the outline and fill as lit pixels.
+func DrawBar(disp display.Display, x, y, w, h int, percent float64, c color.Color) error {
+	cd := display.AsColorDisplay(disp)
+	if w < 3 || h < 3 {
+		// Too small for an outline; show the level alone
+		if err := cd.FillRectColor(x, y, w, h, color.Black); err != nil {
+			return err
+		}
+		return cd.FillRectColor(x, y, barFill(w, percent), h, c)
+	}
+
+	for _, edge := range [][4]int{
+		{x, y, w, 1}, {x, y + h - 1, w, 1}, // top and bottom
+		{x, y + 1, 1, h - 2}, {x + w - 1, y + 1, 1, h - 2}, // left and right
+	} {
+		if err := cd.FillRectColor(edge[0], edge[1], edge[2], edge[3], c); err != nil {
+			return err
+		}
+	}
+	inner := w - 2
+	filled := barFill(inner, percent)
+	if err := cd.FillRectColor(x+1, y+1, filled, h-2, c); err != nil {
+		return err
+	}
+	return cd.FillRectColor(x+1+filled, y+1, inner-filled, h-2, color.Black)
+}
+
+// barFill returns how many of width pixels percent fills
+func barFill(width int, percent float64) int {
+	percent = math.Max(0, math.Min(100, percent))
+	return int(math.Round(float64(width) * percent / 100))
+}
+
+// barWidget is a bar gauge computed from the stats. It is only redrawn when
+// the filled length or colour changes.
+type barWidget struct {
+	x, y, w, h int
+	value      func(s *stats.SystemStats) float64 // percent
+	levels     levels                             // colours by value
+	filled     int
+	c          color.NRGBA
+}
+
+func (b *barWidget) draw(disp display.Display, s *stats.SystemStats, force bool) (bool, error) {
+	percent := b.value(s)
+	filled, c := barFill(b.w-2, percent), b.levels.color(percent)
+	if !force && filled == b.filled && c == b.c {
+		return false, nil
+	}
+	b.filled, b.c = filled, c
+	return true, DrawBar(disp, b.x, b.y, b.w, b.h, percent, c)
+}
+
+// barWidth returns the width of the bars beside the disk and memory lines,
+// or 0 when the line has no room for one. Wide displays fit a bar after the
+// full text; small displays showing one metric per page fit one after the
+// short used/total text.
+func barWidth(layout *Layout) int {
+	switch {
+	case layout.Width >= barWideDisplay:
+		return layout.Width / 4
+	case layout.Height <= 32:
+		if w := layout.Width - 2*MarginLeft - IconWidth - IconGap - MeasureText("999.9/999.9G") - barGap; w >= barMinWidth {
+			return w
+		}
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package renderer
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestDrawBar(t *testing.T) {
+	disp := display.NewOffscreenDisplay(40, 10)
+	if err := disp.FillRectColor(0, 0, 40, 10, ColorRed); err != nil {
+		t.Fatal(err)
+	}
+	if err := DrawBar(disp, 0, 0, 22, 6, 50, ColorGreen); err != nil {
+		t.Fatalf("DrawBar() failed: %v", err)
+	}
+
+	img := disp.Image()
+	green := func(x, y int) bool { return img.NRGBAAt(x, y).G == 255 && img.NRGBAAt(x, y).R == 0 }
+	black := func(x, y int) bool { c := img.NRGBAAt(x, y); return c.R == 0 && c.G == 0 && c.B == 0 }
+
+	// Outline
+	for _, p := range [][2]int{{0, 0}, {21, 0}, {0, 5}, {21, 5}, {0, 3}, {21, 3}} {
+		if !green(p[0], p[1]) {
+			t.Errorf("expected outline at %v, got %v", p, img.NRGBAAt(p[0], p[1]))
+		}
+	}
+	// Half of the 20 inner columns filled, the rest cleared
+	if !green(10, 2) || !black(11, 2) || !black(20, 3) {
+		t.Errorf("expected 10 filled columns, got %v then %v", img.NRGBAAt(10, 2), img.NRGBAAt(11, 2))
+	}
+	// Nothing drawn outside the bar
+	if img.NRGBAAt(22, 2) != (color.NRGBA{R: 255, A: 255}) {
+		t.Error("expected the bar not to draw past its width")
+	}
+
+	// Out of range levels are clamped
+	if err := DrawBar(disp, 0, 0, 22, 6, 150, ColorRed); err != nil {
+		t.Fatal(err)
+	}
+	if img.NRGBAAt(20, 3).R != 255 {
+		t.Error("expected a level above 100% to fill the bar")
+	}
+}
+
+func TestSystemPageBars(t *testing.T) {
+	s := &stats.SystemStats{
+		Hostname:    "testhost",
+		MemoryUsed:  1 << 30,
+		MemoryTotal: 4 << 30,
+		DiskUsed:    50 << 30,
+		DiskTotal:   100 << 30,
+	}
+
+	// Wide displays show a bar after the disk and memory lines
+	disp := display.NewOffscreenDisplay(160, 80)
+	page := NewSystemPage(0)
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	layout := NewLayout(disp.GetBounds(), 0)
+	barX := 160 - MarginRight - barWidth(layout)
+	y := layout.ContentLines[0] + 1
+	if disp.Image().NRGBAAt(barX, y) != MetricColor(50) {
+		t.Errorf("expected a disk bar outline at (%d,%d), got %v", barX, y, disp.Image().NRGBAAt(barX, y))
+	}
+
+	// Redrawing a changed line leaves the bar alone
+	s.DiskUsed = 51 << 30
+	if _, err := page.update(disp, s, time.Now()); err != nil {
+		t.Fatalf("update() failed: %v", err)
+	}
+	if disp.Image().NRGBAAt(barX, y) != MetricColor(50) {
+		t.Error("expected the bar to survive a redraw of its line")
+	}
+
+	// 128x64 panels have no room for bars beside the full text
+	if w := barWidth(NewLayout(display.NewOffscreenDisplay(128, 64).GetBounds(), 0)); w != 0 {
+		t.Errorf("expected no bars on 128x64, got width %d", w)
+	}
+	if w := barWidth(NewLayout(display.NewOffscreenDisplay(128, 32).GetBounds(), 0)); w < barMinWidth {
+		t.Errorf("expected bars on 128x32 metric pages, got width %d", w)
+	}
+}
@@ -0,0 +1,74 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+// byteFormat writes byte counts in IEC units, powers of 1024 such as GiB,
+// or SI units, powers of 1000 such as GB, as pages.byte_units chooses. The
+// zero value writes IEC units.
+type byteFormat struct {
+	si bool
+}
+
+// newByteFormat returns the format for a pages.byte_units value
+func newByteFormat(units string) byteFormat {
+	return byteFormat{si: units == config.ByteUnitsSI}
+}
+
+// unit returns the divisor and prefix letter of the largest unit, from kilo
+// to tera, in which n is at least one. Counts under a kilobyte are written
+// in kilobytes.
+func (f byteFormat) unit(n uint64) (float64, string) {
+	base := 1024.0
+	if f.si {
+		base = 1000
+	}
+	div, prefix := base, "K"
+	for _, p := range []string{"M", "G", "T"} {
+		if float64(n) < div*base {
+			break
+		}
+		div, prefix = div*base, p
+	}
+	return div, prefix
+}
+
+// suffix returns the symbol of the unit with prefix: the bare letter when
+// short, for compact layouts, and e.g. "GiB" or "GB" otherwise
+func (f byteFormat) suffix(prefix string, short bool) string {
+	switch {
+	case short:
+		return prefix
+	case !f.si:
+		return prefix + "iB"
+	case prefix == "K":
+		return "kB"
+	default:
+		return prefix + "B"
+	}
+}
+
+// usage writes used and total in the unit of total, e.g. "1.5/32.0GiB".
+// Boards with less than a gigabyte, such as RAM on a Pi Zero, are written in
+// whole megabytes, e.g. "120/512MiB".
+func (f byteFormat) usage(used, total uint64, short bool) string {
+	div, prefix := f.unit(total)
+	format := "%.1f/%.1f%s"
+	if prefix == "K" || prefix == "M" {
+		format = "%.0f/%.0f%s"
+	}
+	return sprintf(format, float64(used)/div, float64(total)/div, f.suffix(prefix, short))
+}
+
+// size writes a byte count in at most five characters, e.g. "1.2G", "512M"
+// or "64K"
+func (f byteFormat) size(n uint64) string {
+	div, prefix := f.unit(n)
+	if prefix == "K" || prefix == "M" {
+		return fmt.Sprintf("%d%s", uint64(float64(n)/div), prefix)
+	}
+	return sprintf("%.1f%s", float64(n)/div, prefix)
+}
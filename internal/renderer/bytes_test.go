@@ -0,0 +1,51 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+func TestByteFormat(t *testing.T) {
+	iec, si := newByteFormat(""), newByteFormat(config.ByteUnitsSI)
+
+	tests := []struct {
+		name        string
+		f           byteFormat
+		used, total uint64
+		short       bool
+		want        string
+	}{
+		{"iec gigabytes", iec, 3 << 29, 32 << 30, false, "1.5/32.0GiB"},
+		{"iec short", iec, 3 << 29, 32 << 30, true, "1.5/32.0G"},
+		{"si gigabytes", si, 1_500_000_000, 32_000_000_000, false, "1.5/32.0GB"},
+		{"iec small board", iec, 120 << 20, 512 << 20, false, "120/512MiB"},
+		{"si small board", si, 120_000_000, 512_000_000, false, "120/512MB"},
+		{"si kilobytes", si, 20_000, 64_000, false, "20/64kB"},
+		{"iec terabytes", iec, 1 << 40, 2 << 40, false, "1.0/2.0TiB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.usage(tt.used, tt.total, tt.short); got != tt.want {
+				t.Errorf("usage(%d, %d) = %q, want %q", tt.used, tt.total, got, tt.want)
+			}
+		})
+	}
+
+	sizes := []struct {
+		f    byteFormat
+		n    uint64
+		want string
+	}{
+		{iec, 3 << 29, "1.5G"},
+		{iec, 512 << 20, "512M"},
+		{iec, 64 << 10, "64K"},
+		{si, 512_000_000, "512M"},
+		{si, 1_200_000_000, "1.2G"},
+	}
+	for _, tt := range sizes {
+		if got := tt.f.size(tt.n); got != tt.want {
+			t.Errorf("size(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// CalibrationSetting is a value adjusted on the calibration page
+type CalibrationSetting int
+
+// Calibration settings, in the order buttons step through them
+const (
+	SettingContrast CalibrationSetting = iota
+	SettingGamma
+)
+
+// calibrationBars are the colour bars of the calibration image
+var calibrationBars = []color.NRGBA{
+	{R: 255, G: 255, B: 255, A: 255},
+	{R: 255, G: 255, A: 255},
+	{G: 255, B: 255, A: 255},
+	{G: 255, A: 255},
+	{R: 255, B: 255, A: 255},
+	{R: 255, A: 255},
+	{B: 255, A: 255},
+}
+
+// CalibrationPage shows a test image under the contrast and gamma being
+// tuned, with the current values along the top. Colour displays get colour
+// bars above a 16-step grey ramp, whose steps should all be told apart
+// once the gamma is right; monochrome displays get solid, checkered and
+// striped blocks to judge the contrast by.
+type CalibrationPage struct {
+	mu       sync.Mutex
+	contrast int
+	gamma    float64
+	selected CalibrationSetting
+	shown    string // values line last drawn
+}
+
+// NewCalibrationPage creates a calibration page showing the given values
+func NewCalibrationPage(contrast int, gamma float64) *CalibrationPage {
+	return &CalibrationPage{contrast: contrast, gamma: gamma}
+}
+
+// Title returns the page title
+func (p *CalibrationPage) Title() string {
+	return "Calibration"
+}
+
+// Set changes the values shown and which of them is marked as selected
+func (p *CalibrationPage) Set(contrast int, gamma float64, selected CalibrationSetting) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.contrast, p.gamma, p.selected = contrast, gamma, selected
+}
+
+// marks returns the prefixes of the contrast and gamma values, ">" for
+// the selected one. Callers hold mu.
+func (p *CalibrationPage) marks() (string, string) {
+	if p.selected == SettingGamma {
+		return " ", ">"
+	}
+	return ">", " "
+}
+
+// values returns the values line, marking the selected setting, in the
+// short form when the long one is wider than maxWidth
+func (p *CalibrationPage) values(maxWidth int) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, g := p.marks()
+	text := fmt.Sprintf("%sContrast %d %sGamma %g", c, p.contrast, g, p.gamma)
+	if MeasureTextSmall(text) > maxWidth {
+		text = fmt.Sprintf("%sC%d %sG%g", c, p.contrast, g, p.gamma)
+	}
+	return text
+}
+
+// Render draws the test image and the values
+func (p *CalibrationPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := p.draw(disp); err != nil {
+		return err
+	}
+	return disp.Show()
+}
+
+// update redraws the page when the values have changed. The whole image is
+// drawn again so a new gamma applies to it.
+func (p *CalibrationPage) update(disp display.Display, s *stats.SystemStats, _ time.Time) (bool, error) {
+	if p.values(disp.GetBounds().Dx()-MarginLeft-MarginRight) == p.shown {
+		return false, nil
+	}
+	return true, p.draw(disp)
+}
+
+// draw renders the page without flushing
+func (p *CalibrationPage) draw(disp display.Display) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+	bounds := disp.GetBounds()
+	w := bounds.Dx()
+	p.shown = p.values(w - MarginLeft - MarginRight)
+	if err := DrawTextColorScaled(disp, MarginLeft, 0, p.shown, color.White, 0.5); err != nil {
+		return err
+	}
+
+	top := ScaledTextHeight(0.5) + 2
+	h := bounds.Dy() - top
+	if h <= 0 {
+		return nil
+	}
+	if cd := display.AsColorDisplay(disp); cd.Capabilities().Color() {
+		return drawColorCalibration(cd, top, w, h)
+	}
+	return drawMonoCalibration(disp, top, w, h)
+}
+
+// drawColorCalibration draws colour bars over a grey ramp
+func drawColorCalibration(cd display.ColorDisplay, top, w, h int) error {
+	barH := h / 2
+	for i, c := range calibrationBars {
+		x0, x1 := i*w/len(calibrationBars), (i+1)*w/len(calibrationBars)
+		if err := cd.FillRectColor(x0, top, x1-x0, barH, c); err != nil {
+			return err
+		}
+	}
+	for i := range 16 {
+		v := uint8(i * 17) // #nosec G115 -- 0-255
+		x0, x1 := i*w/16, (i+1)*w/16
+		if err := cd.FillRectColor(x0, top+barH, x1-x0, h-barH, color.NRGBA{R: v, G: v, B: v, A: 255}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drawMonoCalibration draws solid, checkered and striped blocks side by side
+func drawMonoCalibration(disp display.Display, top, w, h int) error {
+	third := w / 3
+	if err := disp.DrawRect(0, top, third, h, true); err != nil {
+		return err
+	}
+	for y := top; y < top+h; y++ {
+		for x := third; x < w; x++ {
+			var on bool
+			if x < 2*third {
+				on = (x+y)%2 == 0
+			} else {
+				on = y%2 == 0
+			}
+			if on {
+				if err := disp.DrawPixel(x, y, true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// TextLines shows the values, under a title when there is room for one.
+// Character displays have no image to tune against, only the contrast.
+func (p *CalibrationPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, g := p.marks()
+	lines := []string{
+		fitText(fmt.Sprintf("%sContrast %d", c, p.contrast), fmt.Sprintf("%sC%d", c, p.contrast), cols),
+		fitText(fmt.Sprintf("%sGamma %g", g, p.gamma), fmt.Sprintf("%sG%g", g, p.gamma), cols),
+	}
+	if rows > len(lines) {
+		lines = append([]string{"Calibration"}, lines...)
+	}
+	return lines[:min(len(lines), rows)]
+}
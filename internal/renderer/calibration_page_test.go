@@ -0,0 +1,61 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestCalibrationPage(t *testing.T) {
+	for _, disp := range []display.Display{display.NewMockDisplay(128, 64), display.NewOffscreenDisplay(160, 80)} {
+		page := NewCalibrationPage(255, 1)
+		if page.Title() != "Calibration" {
+			t.Errorf("expected title 'Calibration', got %q", page.Title())
+		}
+		if err := page.Render(disp, &stats.SystemStats{}); err != nil {
+			t.Fatalf("Render() failed: %v", err)
+		}
+		if !strings.HasPrefix(page.shown, ">C") {
+			t.Errorf("expected contrast selected, got %q", page.shown)
+		}
+
+		if changed, err := page.update(disp, nil, time.Now()); err != nil || changed {
+			t.Errorf("expected no redraw while the values are unchanged, got %v, %v", changed, err)
+		}
+		page.Set(200, 1.8, SettingGamma)
+		if changed, err := page.update(disp, nil, time.Now()); err != nil || !changed {
+			t.Errorf("expected a redraw for new values, got %v, %v", changed, err)
+		}
+		if !strings.Contains(page.shown, "200") || !strings.Contains(page.shown, ">G") {
+			t.Errorf("values line = %q, want contrast 200 and gamma selected", page.shown)
+		}
+	}
+
+	// The solid block of the monochrome image is lit
+	mock := display.NewMockDisplay(128, 64)
+	if err := NewCalibrationPage(255, 1).Render(mock, nil); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if !mock.GetPixel(10, 40) {
+		t.Error("expected the solid block to be drawn")
+	}
+}
+
+func TestCalibrationPageTextLines(t *testing.T) {
+	page := NewCalibrationPage(180, 2.2)
+	got := page.TextLines(nil, 16, 4)
+	want := []string{"Calibration", ">Contrast 180", " Gamma 2.2"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("TextLines(16, 4) = %q, want %q", got, want)
+	}
+
+	page.Set(180, 2.2, SettingGamma)
+	got = page.TextLines(nil, 8, 2)
+	want = []string{" C180", ">G2.2"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("TextLines(8, 2) = %q, want %q", got, want)
+	}
+}
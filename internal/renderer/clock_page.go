@@ -0,0 +1,291 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// ClockPage shows a large clock, centred at a fixed position. The
+// screensaver's clock mode renders it in place of normal rotation: lighting
+// far fewer pixels than the stats pages, it ages the panel less while still
+// being useful at a glance.
+//
+// With the time sync check enabled, an "NTP" line below the date shows a
+// green tick or a red cross for whether the clock is synchronised, and how
+// far it is off. Displays too short for the line show just the mark in the
+// top-right corner.
+type ClockPage struct {
+	lines int
+	now   func() time.Time
+	shown string // time, date and sync status last drawn
+}
+
+// NewClockPage creates a clock page
+func NewClockPage(lines int) *ClockPage {
+	return &ClockPage{lines: lines, now: time.Now}
+}
+
+// Title returns the page title
+func (p *ClockPage) Title() string {
+	return "Clock"
+}
+
+// Render draws the time as large as the display allows, with the date below
+// when there is room for it
+func (p *ClockPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := p.draw(disp, s, p.now()); err != nil {
+		return err
+	}
+	return disp.Show()
+}
+
+// update redraws the clock only when the minute or the sync status has
+// changed
+func (p *ClockPage) update(disp display.Display, s *stats.SystemStats, _ time.Time) (bool, error) {
+	now := p.now()
+	if now.Format(clockShownFormat)+timeSyncText(s) == p.shown {
+		return false, nil
+	}
+	return true, p.draw(disp, s, now)
+}
+
+// clockShownFormat identifies what the clock shows, to detect when it changes
+const clockShownFormat = "15:04 Mon 02 Jan"
+
+// draw renders the clock for now without flushing. s may be nil.
+func (p *ClockPage) draw(disp display.Display, s *stats.SystemStats, now time.Time) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+	p.shown = now.Format(clockShownFormat) + timeSyncText(s)
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	var sync *stats.TimeSyncStatus
+	if s != nil {
+		sync = s.TimeSync
+	}
+
+	// Reserve a line for the date, and one for the sync status, on displays
+	// tall enough to show them
+	dateHeight, syncHeight := 0, 0
+	if bounds.Dy() > 32 {
+		dateHeight = ScaledTextHeight(layout.TextScale) + 2
+		if sync != nil {
+			syncHeight = dateHeight
+		}
+	}
+
+	timeText := now.Format("15:04")
+	face := basicfont.Face7x13
+	glyphW := font.MeasureString(face, timeText).Ceil()
+	glyphH := face.Metrics().Ascent.Ceil() + face.Metrics().Descent.Ceil()
+
+	factor := min((bounds.Dx()-MarginLeft-MarginRight)/glyphW, (bounds.Dy()-dateHeight-syncHeight)/glyphH)
+	if factor < 1 {
+		factor = 1
+	}
+
+	timeY := (bounds.Dy() - dateHeight - syncHeight - glyphH*factor) / 2
+	if err := drawTextCenteredEnlarged(disp, timeY, timeText, color.White, factor); err != nil {
+		return err
+	}
+
+	if dateHeight > 0 {
+		dateY := timeY + glyphH*factor + 2
+		if err := DrawTextCenteredColorScaled(disp, dateY, now.Format("Mon 02 Jan"), color.White, layout.TextScale); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case sync == nil:
+	case syncHeight > 0:
+		return drawTimeSync(disp, timeY+glyphH*factor+2+dateHeight, sync, layout.TextScale)
+	default:
+		return drawSyncMark(disp, bounds.Dx()-MarginRight-syncMarkSize, 0, sync)
+	}
+	return nil
+}
+
+// syncMarkSize is the width and height of the tick and cross
+const syncMarkSize = 7
+
+// The marks drawn for a synchronised clock and an unsynchronised one
+var (
+	syncTick = [syncMarkSize]string{
+		"      #",
+		"     ##",
+		"     # ",
+		"#   ## ",
+		"## ##  ",
+		" ###   ",
+		"  #    ",
+	}
+	syncCross = [syncMarkSize]string{
+		"##   ##",
+		" ## ## ",
+		"  ###  ",
+		"   #   ",
+		"  ###  ",
+		" ## ## ",
+		"##   ##",
+	}
+)
+
+// drawSyncMark draws a green tick when the clock is synchronised, a red
+// cross when it is not, and a yellow cross when the check failed
+func drawSyncMark(disp display.Display, x, y int, sync *stats.TimeSyncStatus) error {
+	mark, c := syncCross, ColorRed
+	switch {
+	case sync.Err != nil:
+		c = ColorYellow
+	case sync.Synced:
+		mark, c = syncTick, ColorGreen
+	}
+
+	cd := display.AsColorDisplay(disp)
+	if err := cd.FillRectColor(x, y, syncMarkSize, syncMarkSize, color.Black); err != nil {
+		return err
+	}
+	for row, line := range mark {
+		for col, ch := range line {
+			if ch != '#' {
+				continue
+			}
+			if err := cd.DrawPixelColor(x+col, y+row, c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// drawTimeSync centres "NTP", the sync mark and the clock offset on a line
+func drawTimeSync(disp display.Display, y int, sync *stats.TimeSyncStatus, scale float64) error {
+	measure := MeasureText
+	if scale > 0 && scale < 1 {
+		measure = MeasureTextSmall
+	}
+	const label = "NTP "
+	offset, offsetColor := "", color.Color(color.White)
+	if sync.Err == nil && sync.OffsetKnown {
+		offset = " " + offsetText(sync.Offset)
+		offsetColor = offsetColorFor(sync.Offset)
+	}
+
+	width := measure(label) + syncMarkSize + measure(offset)
+	if width > disp.GetBounds().Dx()-MarginLeft-MarginRight {
+		offset, width = "", measure(label)+syncMarkSize
+	}
+	x := (disp.GetBounds().Dx() - width) / 2
+	textHeight := ScaledTextHeight(scale)
+
+	if err := DrawTextColorScaled(disp, x, y, label, color.White, scale); err != nil {
+		return err
+	}
+	x += measure(label)
+	if err := drawSyncMark(disp, x, y+(textHeight-syncMarkSize)/2, sync); err != nil {
+		return err
+	}
+	if offset == "" {
+		return nil
+	}
+	return DrawTextColorScaled(disp, x+syncMarkSize, y, offset, offsetColor, scale)
+}
+
+// offsetText formats a clock offset compactly with its sign, in the largest
+// unit that keeps it readable: "+42us", "-3.1ms", "+1.2s"
+func offsetText(d time.Duration) string {
+	switch abs := d.Abs(); {
+	case abs < time.Millisecond:
+		return fmt.Sprintf("%+dus", d.Microseconds())
+	case abs < time.Second:
+		return fmt.Sprintf("%+.1fms", float64(d)/float64(time.Millisecond))
+	default:
+		return fmt.Sprintf("%+.1fs", d.Seconds())
+	}
+}
+
+// offsetColorFor is white for offsets too small to notice, yellow from 100ms
+// and red from a second
+func offsetColorFor(d time.Duration) color.Color {
+	switch abs := d.Abs(); {
+	case abs >= time.Second:
+		return ColorRed
+	case abs >= 100*time.Millisecond:
+		return ColorYellow
+	default:
+		return color.White
+	}
+}
+
+// timeSyncText describes the sync status as text: "NTP ok +42us", "NTP x"
+// when not synchronised, "NTP ?" when the check failed, and "" when it is
+// disabled or has not finished yet
+func timeSyncText(s *stats.SystemStats) string {
+	if s == nil || s.TimeSync == nil {
+		return ""
+	}
+	sync := s.TimeSync
+	switch {
+	case sync.Err != nil:
+		return "NTP ?"
+	case !sync.Synced:
+		return "NTP x"
+	case sync.OffsetKnown:
+		return "NTP ok " + offsetText(sync.Offset)
+	default:
+		return "NTP ok"
+	}
+}
+
+// drawTextCenteredEnlarged renders text with the standard font and scales it up
+// by an integer factor using nearest-neighbour sampling, which keeps the
+// bitmap glyph edges crisp
+func drawTextCenteredEnlarged(disp display.Display, y int, text string, c color.Color, factor int) error {
+	face := textFace
+	width := font.MeasureString(face, text).Ceil()
+	height := face.Metrics().Ascent.Ceil() + face.Metrics().Descent.Ceil()
+
+	src := image.NewNRGBA(image.Rect(0, 0, width, height))
+	drawer := &font.Drawer{
+		Dst:  src,
+		Src:  &image.Uniform{c},
+		Face: face,
+		Dot:  fixed.P(0, face.Metrics().Ascent.Ceil()),
+	}
+	drawer.DrawString(text)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width*factor, height*factor))
+	for py := 0; py < height*factor; py++ {
+		for px := 0; px < width*factor; px++ {
+			dst.Set(px, py, src.At(px/factor, py/factor))
+		}
+	}
+
+	x := (disp.GetBounds().Dx() - width*factor) / 2
+	return disp.DrawImage(x, y, dst)
+}
+
+// TextLines centres the time, the date below it and the sync status when
+// there is a row for it, on the display
+func (p *ClockPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	now := p.now()
+	sync := timeSyncText(s)
+	if sync == "" || rows < 3 {
+		lines := make([]string, max((rows-2)/2, 0), rows)
+		return append(lines, centerText(now.Format("15:04"), cols), centerText(now.Format("Mon 02 Jan"), cols))
+	}
+	lines := make([]string, (rows-3)/2, rows)
+	return append(lines, centerText(now.Format("15:04"), cols), centerText(now.Format("Mon 02 Jan"), cols), centerText(sync, cols))
+}
@@ -0,0 +1,130 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// connectivityWaitingText is shown until the first reachability check finishes
+const connectivityWaitingText = "Checking..."
+
+// ConnectivityPage shows whether the internet is reachable, how long the
+// check took and, when looked up, the public IP
+type ConnectivityPage struct {
+	target  string
+	lines   int // configured line count (0=auto, 2=default, 4=compact)
+	widgets widgetSet
+}
+
+// NewConnectivityPage creates a page for checks against target
+func NewConnectivityPage(target string, lines int) *ConnectivityPage {
+	return &ConnectivityPage{target: target, lines: lines}
+}
+
+// Title returns the page title
+func (p *ConnectivityPage) Title() string {
+	return "Internet"
+}
+
+// Render draws the reachability status under the hostname header
+func (p *ConnectivityPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	maxWidth := bounds.Dx() - 2*MarginLeft
+
+	if err := drawPageHeader(disp, layout, s.Hostname); err != nil {
+		return err
+	}
+
+	// One widget per row; the rows only change when a check finishes
+	p.widgets.reset()
+	rows := len(layout.ContentLines)
+	for row, y := range layout.ContentLines {
+		p.widgets.add(&lineWidget{
+			x:     MarginLeft,
+			y:     y,
+			scale: layout.TextScale,
+			content: func(s *stats.SystemStats) []textSpan {
+				text, c := p.row(s, row, rows)
+				if text == "" {
+					return nil
+				}
+				if layout.TextScale > 0 && layout.TextScale < 1 {
+					return span(TruncateTextSmall(text, maxWidth), c)
+				}
+				return span(TruncateText(text, maxWidth), c)
+			},
+		}, 0)
+	}
+	if err := p.widgets.render(disp, s, time.Now()); err != nil {
+		return err
+	}
+
+	return disp.Show()
+}
+
+// update redraws the rows whose status changed
+func (p *ConnectivityPage) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	return p.widgets.update(disp, s, now)
+}
+
+// row returns the text and colour of content row i of n: the status, then
+// the latency or the error, the public IP and the target checked. A single
+// row shows the status and latency together.
+func (p *ConnectivityPage) row(s *stats.SystemStats, i, n int) (string, color.NRGBA) {
+	status := s.Connectivity
+	if status == nil {
+		if i == 0 {
+			return connectivityWaitingText, ColorYellow
+		}
+		return "", ColorGreen
+	}
+
+	var rows []string
+	var colors []color.NRGBA
+	switch {
+	case status.Up && n == 1:
+		rows, colors = []string{"Internet up " + formatLatency(status.Latency)}, []color.NRGBA{ColorGreen}
+	case status.Up:
+		rows = []string{"Internet up", "Latency " + formatLatency(status.Latency)}
+		colors = []color.NRGBA{ColorGreen, ColorGreen}
+	default:
+		rows = []string{"Internet down", status.Err.Error()}
+		colors = []color.NRGBA{ColorRed, ColorRed}
+	}
+	if status.PublicIP != "" {
+		rows, colors = append(rows, "Public "+status.PublicIP), append(colors, ColorGreen)
+	}
+	rows, colors = append(rows, "Via "+p.target), append(colors, ColorGreen)
+
+	if i >= len(rows) {
+		return "", ColorGreen
+	}
+	return rows[i], colors[i]
+}
+
+// formatLatency formats a check's duration in whole milliseconds
+func formatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return "<1 ms"
+	}
+	return fmt.Sprintf("%d ms", d.Milliseconds())
+}
+
+// TextLines shows the status rows
+func (p *ConnectivityPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	var lines []string
+	for i := range rows {
+		text, _ := p.row(s, i, rows)
+		lines = append(lines, text)
+	}
+	return lines
+}
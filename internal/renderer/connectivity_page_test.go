@@ -0,0 +1,65 @@
+package renderer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestConnectivityPageRows(t *testing.T) {
+	page := NewConnectivityPage("1.1.1.1:53", 0)
+	rows := func(s *stats.SystemStats, n int) []string {
+		var got []string
+		for i := range n {
+			text, _ := page.row(s, i, n)
+			got = append(got, text)
+		}
+		return got
+	}
+	check := func(got, want []string) {
+		t.Helper()
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("row %d = %q, want %q", i, got[i], want[i])
+			}
+		}
+	}
+
+	s := &stats.SystemStats{Hostname: "pi"}
+	check(rows(s, 2), []string{connectivityWaitingText, ""})
+
+	s.Connectivity = &stats.ConnectivityStatus{Up: true, Latency: 23 * time.Millisecond, PublicIP: "203.0.113.9"}
+	check(rows(s, 4), []string{"Internet up", "Latency 23 ms", "Public 203.0.113.9", "Via 1.1.1.1:53"})
+	check(rows(s, 1), []string{"Internet up 23 ms"})
+
+	s.Connectivity = &stats.ConnectivityStatus{Err: errors.New("connect 1.1.1.1:53: timeout")}
+	if text, c := page.row(s, 0, 4); text != "Internet down" || c != ColorRed {
+		t.Errorf("row 0 = %q in %v, want the down status in red", text, c)
+	}
+	check(rows(s, 3), []string{"Internet down", "connect 1.1.1.1:53: timeout", "Via 1.1.1.1:53"})
+
+	if err := page.Render(display.NewOffscreenDisplay(128, 64), s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+}
+
+func TestBuildPagesConnectivity(t *testing.T) {
+	cfg := config.Default()
+	cfg.Connectivity.Enabled = true
+	r := NewRenderer(display.NewOffscreenDisplay(128, 64), cfg)
+	r.BuildPages(&stats.SystemStats{Hostname: "pi"})
+
+	found := false
+	for i := 0; i < r.PageCount(); i++ {
+		if r.PageType(i) == config.PageConnectivity {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a connectivity page when the check is enabled")
+	}
+}
@@ -0,0 +1,153 @@
+package renderer
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// execWaitingText is shown until the page's command has finished once
+const execWaitingText = "Waiting for output..."
+
+// ExecPage shows the stdout of a pages.exec command, one line per content
+// row, under the page title. Output longer than the display is shown a
+// screenful at a time.
+type ExecPage struct {
+	title   string
+	lines   int // configured line count (0=auto, 2=default, 4=compact)
+	output  func(s *stats.SystemStats) (stats.ExecOutput, bool)
+	scroll  rowScroller
+	widgets widgetSet
+}
+
+// NewExecPage creates a page for the exec command with the given title
+func NewExecPage(title string, lines int) *ExecPage {
+	return &ExecPage{
+		title: title,
+		lines: lines,
+		output: func(s *stats.SystemStats) (stats.ExecOutput, bool) {
+			out, ok := s.Exec[title]
+			return out, ok
+		},
+	}
+}
+
+// HTTPJSONPage shows the fields of a pages.http_json endpoint, one per
+// content row, drawn like an exec page's output
+type HTTPJSONPage struct {
+	*ExecPage
+}
+
+// NewHTTPJSONPage creates a page for the http_json endpoint with the given
+// title
+func NewHTTPJSONPage(title string, lines int) *HTTPJSONPage {
+	p := &HTTPJSONPage{ExecPage: NewExecPage(title, lines)}
+	p.output = func(s *stats.SystemStats) (stats.ExecOutput, bool) {
+		out, ok := s.HTTPJSON[title]
+		return out, ok
+	}
+	return p
+}
+
+// Title returns the page title
+func (p *ExecPage) Title() string {
+	return p.title
+}
+
+// Render draws the command output
+func (p *ExecPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	maxWidth := bounds.Dx() - 2*MarginLeft
+
+	if err := drawPageHeader(disp, layout, p.title); err != nil {
+		return err
+	}
+
+	// One widget per row, so only changed output lines are redrawn
+	now := time.Now()
+	p.scroll.reset(now, len(layout.ContentLines))
+	p.scroll.advance(now, p.rowCount(s))
+	p.widgets.reset()
+	for row, y := range layout.ContentLines {
+		p.widgets.add(&lineWidget{
+			x:     MarginLeft,
+			y:     y,
+			scale: layout.TextScale,
+			content: func(s *stats.SystemStats) []textSpan {
+				text, c := p.row(s, p.scroll.row(row))
+				if text == "" {
+					return nil
+				}
+				if layout.TextScale > 0 && layout.TextScale < 1 {
+					return span(TruncateTextSmall(text, maxWidth), c)
+				}
+				return span(TruncateText(text, maxWidth), c)
+			},
+		}, 0)
+	}
+	p.widgets.addScrollIndicator(&p.scroll, layout)
+	if err := p.widgets.render(disp, s, now); err != nil {
+		return err
+	}
+
+	return disp.Show()
+}
+
+// update redraws the rows whose output changed or scrolled
+func (p *ExecPage) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	p.scroll.advance(now, p.rowCount(s))
+	return p.widgets.update(disp, s, now)
+}
+
+// rowCount returns how many rows the output takes, with its error
+func (p *ExecPage) rowCount(s *stats.SystemStats) int {
+	out, ok := p.output(s)
+	switch {
+	case !ok:
+		return 1
+	case out.Err != nil:
+		return len(out.Lines) + 1
+	default:
+		return len(out.Lines)
+	}
+}
+
+// row returns the text and colour of output row i. A failed run adds a row
+// in red, below whatever output the previous run left.
+func (p *ExecPage) row(s *stats.SystemStats, i int) (string, color.NRGBA) {
+	out, ok := p.output(s)
+	switch {
+	case !ok:
+		if i == 0 {
+			return execWaitingText, ColorGreen
+		}
+		return "", ColorGreen
+	case i < len(out.Lines):
+		return out.Lines[i], ColorGreen
+	case out.Err != nil && i == len(out.Lines):
+		return out.Err.Error(), ColorRed
+	default:
+		return "", ColorGreen
+	}
+}
+
+// TextLines shows the title above as much output as fits. An error takes
+// the last row when the output fills the others.
+func (p *ExecPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	lines := []string{centerText(p.title, cols)}
+	for i := range rows - 1 {
+		text, _ := p.row(s, i)
+		lines = append(lines, text)
+	}
+	if out, ok := p.output(s); ok && out.Err != nil && len(out.Lines) >= rows-1 && rows > 1 {
+		lines[rows-1] = out.Err.Error()
+	}
+	return lines
+}
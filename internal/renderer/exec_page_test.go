@@ -0,0 +1,105 @@
+package renderer
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestExecPageRows(t *testing.T) {
+	p := NewExecPage("Mail", 0)
+
+	s := &stats.SystemStats{}
+	if text, _ := p.row(s, 0); text != execWaitingText {
+		t.Errorf("expected %q before the first run, got %q", execWaitingText, text)
+	}
+
+	s.Exec = map[string]stats.ExecOutput{"Mail": {Lines: []string{"queue: 3", "deferred: 1"}}}
+	if text, c := p.row(s, 1); text != "deferred: 1" || c != ColorGreen {
+		t.Errorf("row 1 = %q, want %q in green", text, "deferred: 1")
+	}
+	if text, _ := p.row(s, 2); text != "" {
+		t.Errorf("expected row 2 empty, got %q", text)
+	}
+
+	// A failure keeps the old output and reports the error below it
+	s.Exec["Mail"] = stats.ExecOutput{Lines: []string{"queue: 3"}, Err: errors.New("mailq: exit status 1")}
+	if text, _ := p.row(s, 0); text != "queue: 3" {
+		t.Errorf("expected previous output kept, got %q", text)
+	}
+	if text, c := p.row(s, 1); text != "mailq: exit status 1" || c != ColorRed {
+		t.Errorf("row 1 = %q, want the error in red", text)
+	}
+}
+
+func TestExecPageRedrawsOnNewOutput(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	p := NewExecPage("Mail", 0)
+	s := &stats.SystemStats{Exec: map[string]stats.ExecOutput{"Mail": {Lines: []string{"queue: 3"}}}}
+
+	if err := p.Render(disp, s); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if changed, err := p.update(disp, s, time.Now()); err != nil || changed {
+		t.Errorf("expected no redraw for unchanged output, got changed=%v err=%v", changed, err)
+	}
+
+	s.Exec["Mail"] = stats.ExecOutput{Lines: []string{"queue: 4"}}
+	if changed, err := p.update(disp, s, time.Now()); err != nil || !changed {
+		t.Errorf("expected a redraw for new output, got changed=%v err=%v", changed, err)
+	}
+}
+
+func TestBuildPagesAddsExecPages(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.Exec = []config.ExecPageConfig{{Title: "Mail", Command: []string{"mailq"}, Interval: "1m"}}
+
+	r := NewRenderer(display.NewMockDisplay(128, 64), cfg)
+	r.BuildPages(&stats.SystemStats{Hostname: "testhost"})
+
+	last := r.PageCount() - 1
+	if got := r.PageType(last); got != config.PageExec {
+		t.Fatalf("PageType(%d) = %q, want %q", last, got, config.PageExec)
+	}
+	if got := r.PageTitle(last); got != "Mail" {
+		t.Errorf("PageTitle(%d) = %q, want %q", last, got, "Mail")
+	}
+
+	cfg.Pages.Disabled = []string{config.PageExec}
+	r.BuildPages(&stats.SystemStats{Hostname: "testhost"})
+	if got := r.PageType(r.PageCount() - 1); got == config.PageExec {
+		t.Error("expected exec pages left out when disabled")
+	}
+}
+
+func TestHTTPJSONPage(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.Exec = []config.ExecPageConfig{{Title: "Pi-hole", Command: []string{"true"}, Interval: "1m"}}
+	cfg.Pages.HTTPJSON = []config.HTTPJSONPageConfig{{Title: "Pi-hole", URL: "http://pi.hole/api", Interval: "1m"}}
+
+	r := NewRenderer(display.NewMockDisplay(128, 64), cfg)
+	r.BuildPages(&stats.SystemStats{Hostname: "testhost"})
+	last := r.PageCount() - 1
+	if got := r.PageType(last); got != config.PageHTTPJSON {
+		t.Fatalf("PageType(%d) = %q, want %q", last, got, config.PageHTTPJSON)
+	}
+
+	// The page reads its own output, not the exec page's of the same title
+	p := NewHTTPJSONPage("Pi-hole", 0)
+	s := &stats.SystemStats{
+		Exec:     map[string]stats.ExecOutput{"Pi-hole": {Lines: []string{"from exec"}}},
+		HTTPJSON: map[string]stats.ExecOutput{"Pi-hole": {Lines: []string{"Blocked 3071", "12.5%"}}},
+	}
+	want := []string{centerText("Pi-hole", 16), "Blocked 3071", "12.5%"}
+	if got := p.TextLines(s, 16, 3); !slices.Equal(got, want) {
+		t.Errorf("TextLines() = %q, want %q", got, want)
+	}
+	if err := p.Render(display.NewMockDisplay(128, 64), s); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package renderer
+
+import (
+	"image/color"
+	"strings"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// firstBootNoAddress is shown in place of the IP before the network is up
+const firstBootNoAddress = "No network"
+
+// FirstBootPage helps find a freshly installed device on the network: it
+// shows the host name, the primary IP address in the largest type that fits,
+// and the mDNS name. The renderer puts it first in the rotation until the
+// system has been up for pages.first_boot.until_uptime.
+type FirstBootPage struct {
+	lines int
+	shown string // host name and address last drawn
+}
+
+// NewFirstBootPage creates a first-boot page
+func NewFirstBootPage(lines int) *FirstBootPage {
+	return &FirstBootPage{lines: lines}
+}
+
+// Title returns the page title
+func (p *FirstBootPage) Title() string {
+	return "First boot"
+}
+
+// Render draws the page
+func (p *FirstBootPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := p.draw(disp, s); err != nil {
+		return err
+	}
+	return disp.Show()
+}
+
+// update redraws the page only when the host name or address changed
+func (p *FirstBootPage) update(disp display.Display, s *stats.SystemStats, _ time.Time) (bool, error) {
+	if firstBootKey(s) == p.shown {
+		return false, nil
+	}
+	return true, p.draw(disp, s)
+}
+
+// firstBootKey identifies what the page shows, to detect when it changes
+func firstBootKey(s *stats.SystemStats) string {
+	return s.Hostname + "|" + primaryAddress(s)
+}
+
+// draw renders the page without flushing. The mDNS name is at the top; the
+// host name, when it says something the mDNS name does not, below it and the
+// address fills the rest. Small displays show the mDNS name and address only.
+func (p *FirstBootPage) draw(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+	p.shown = firstBootKey(s)
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	lineHeight := ScaledTextHeight(layout.TextScale) + 2
+	maxWidth := bounds.Dx() - MarginLeft - MarginRight
+	measure := MeasureText
+	if layout.TextScale > 0 && layout.TextScale < 1 {
+		measure = MeasureTextSmall
+	}
+
+	y := 0
+	labels := []string{mdnsName(s.Hostname)}
+	if bounds.Dy() > 32 && s.Hostname != shortHostname(s.Hostname) {
+		labels = append(labels, s.Hostname)
+	}
+	for i, label := range labels {
+		c := color.Color(ColorGreen)
+		if i > 0 {
+			c = color.White
+		}
+		if measure(label) > maxWidth {
+			label = TruncateText(label, maxWidth)
+		}
+		if err := DrawTextCenteredColorScaled(disp, y, label, c, layout.TextScale); err != nil {
+			return err
+		}
+		y += lineHeight
+	}
+
+	addr, c := primaryAddress(s), color.Color(color.White)
+	if addr == "" {
+		addr, c = tr(firstBootNoAddress), ColorYellow
+	}
+	face := basicfont.Face7x13
+	glyphW := font.MeasureString(face, addr).Ceil()
+	glyphH := face.Metrics().Ascent.Ceil() + face.Metrics().Descent.Ceil()
+	factor := min(maxWidth/glyphW, (bounds.Dy()-y)/glyphH)
+	if factor < 1 {
+		// Long IPv6 addresses may not fit even at normal size
+		return DrawTextCenteredColor(disp, y, TruncateText(addr, maxWidth), c)
+	}
+	return drawTextCenteredEnlarged(disp, y+(bounds.Dy()-y-glyphH*factor)/2, addr, c, factor)
+}
+
+// TextLines shows the mDNS name above the address, with the host name
+// between them when there is a row for it
+func (p *FirstBootPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	addr := primaryAddress(s)
+	if addr == "" {
+		addr = tr(firstBootNoAddress)
+	}
+	lines := []string{centerText(mdnsName(s.Hostname), cols)}
+	if rows > 2 {
+		lines = append(lines, centerText(s.Hostname, cols))
+	}
+	return append(lines, centerText(addr, cols))
+}
+
+// primaryAddress returns the device's first IPv4 address, or its first IPv6
+// address when it has none, in interface order. It is "" when there is none.
+func primaryAddress(s *stats.SystemStats) string {
+	ipv4, ipv6 := firstAddresses(s)
+	if ipv4 != "" {
+		return ipv4
+	}
+	return ipv6
+}
+
+// firstAddresses returns the first IPv4 and IPv6 address of the device's
+// interfaces, in interface order; either is "" when there is none
+func firstAddresses(s *stats.SystemStats) (ipv4, ipv6 string) {
+	for _, iface := range s.Interfaces {
+		if ipv4 == "" && len(iface.IPv4Addrs) > 0 {
+			ipv4 = iface.IPv4Addrs[0]
+		}
+		if ipv6 == "" && len(iface.IPv6Addrs) > 0 {
+			ipv6 = iface.IPv6Addrs[0]
+		}
+	}
+	return ipv4, ipv6
+}
+
+// shortHostname returns the first label of hostname
+func shortHostname(hostname string) string {
+	short, _, _ := strings.Cut(hostname, ".")
+	return short
+}
+
+// mdnsName returns the name the device answers to over mDNS (e.g. Avahi)
+func mdnsName(hostname string) string {
+	return shortHostname(hostname) + ".local"
+}
@@ -0,0 +1,90 @@
+package renderer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestFirstBootPageRender(t *testing.T) {
+	s := &stats.SystemStats{
+		Hostname:   "pi.example.com",
+		Interfaces: []stats.NetInterface{{Name: "eth0", IPv4Addrs: []string{"10.0.0.7"}}},
+	}
+	page := NewFirstBootPage(0)
+
+	disp := display.NewOffscreenDisplay(128, 64)
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	// The address is enlarged to fill the space below the two name lines, so
+	// the bottom rows of the panel are lit
+	img := disp.Image()
+	lit := false
+	for x := 0; x < 128 && !lit; x++ {
+		for y := 50; y < 64; y++ {
+			if img.NRGBAAt(x, y).R != 0 {
+				lit = true
+				break
+			}
+		}
+	}
+	if !lit {
+		t.Error("expected the enlarged address to reach the bottom of the panel")
+	}
+
+	// Unchanged stats are not redrawn; a new address is
+	if changed, err := page.update(disp, s, time.Now()); err != nil || changed {
+		t.Errorf("update() = %v, %v; want no redraw", changed, err)
+	}
+	s.Interfaces[0].IPv4Addrs = []string{"10.0.0.8"}
+	if changed, err := page.update(disp, s, time.Now()); err != nil || !changed {
+		t.Errorf("update() = %v, %v; want a redraw", changed, err)
+	}
+}
+
+func TestFirstBootPageTextLines(t *testing.T) {
+	page := NewFirstBootPage(0)
+	s := &stats.SystemStats{Hostname: "pi.example.com"}
+
+	lines := page.TextLines(s, 16, 2)
+	want := []string{"    pi.local", "   No network"}
+	if len(lines) != len(want) || lines[0] != want[0] || lines[1] != want[1] {
+		t.Errorf("TextLines() = %q, want %q", lines, want)
+	}
+
+	s.Interfaces = []stats.NetInterface{{Name: "wlan0", IPv6Addrs: []string{"fd00::7"}}}
+	lines = page.TextLines(s, 20, 4)
+	if len(lines) != 3 || lines[2] != centerText("fd00::7", 20) {
+		t.Errorf("TextLines() = %q, want the IPv6 address on the third row", lines)
+	}
+}
+
+func TestBuildPagesFirstBoot(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.FirstBoot.UntilUptime = "10m"
+	r := NewRenderer(display.NewOffscreenDisplay(128, 64), cfg)
+
+	s := &stats.SystemStats{Hostname: "pi", Uptime: 2 * time.Minute}
+	r.BuildPages(s)
+	if r.PageType(0) != config.PageFirstBoot {
+		t.Fatalf("PageType(0) = %q, want the first-boot page first", r.PageType(0))
+	}
+	if r.FirstBootChanged(s) {
+		t.Error("expected no rebuild while still within the first-boot period")
+	}
+
+	s.Uptime = 11 * time.Minute
+	if !r.FirstBootChanged(s) {
+		t.Fatal("expected a rebuild once the first-boot period is over")
+	}
+	r.BuildPages(s)
+	for i := 0; i < r.PageCount(); i++ {
+		if r.PageType(i) == config.PageFirstBoot {
+			t.Error("expected no first-boot page after the first-boot period")
+		}
+	}
+}
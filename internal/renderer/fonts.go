@@ -0,0 +1,139 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"os"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+// The faces text is drawn with. They start as the built-in bitmap fonts,
+// which cover printable ASCII only; LoadFont adds a fallback font for the
+// other characters.
+var (
+	textFace      font.Face = basicfont.Face7x13
+	smallTextFace font.Face = Face5x7
+)
+
+// faceForScale returns the face for a text scale: the compact 5x7 font for
+// scales in (0, 1), the standard 7x13 font otherwise
+func faceForScale(scale float64) font.Face {
+	if scale > 0 && scale < 1 {
+		return smallTextFace
+	}
+	return textFace
+}
+
+// LoadFont sets the font used for characters the built-in fonts lack, such
+// as accented letters, Cyrillic or CJK in hostnames and messages. spec is
+// config.FontGo for the embedded Go Mono font or the path of a TrueType or
+// OpenType font (the first font of a collection is used). An empty spec
+// draws such characters as boxes, as without a fallback font. It must be
+// called before anything is drawn.
+func LoadFont(spec string) error {
+	if spec == "" {
+		textFace, smallTextFace = basicfont.Face7x13, Face5x7
+		return nil
+	}
+
+	data := gomono.TTF
+	if spec != config.FontGo {
+		var err error
+		if data, err = os.ReadFile(spec); err != nil { // #nosec G304 -- font path from trusted config
+			return err
+		}
+	}
+	f, err := parseFont(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", spec, err)
+	}
+
+	regular, err := fittedFace(f, basicfont.Face7x13)
+	if err != nil {
+		return err
+	}
+	small, err := fittedFace(f, Face5x7)
+	if err != nil {
+		return err
+	}
+	textFace = &fallbackFace{primary: basicfont.Face7x13, fallback: regular}
+	smallTextFace = &fallbackFace{primary: Face5x7, fallback: small}
+	return nil
+}
+
+// parseFont parses a TrueType or OpenType font or collection
+func parseFont(data []byte) (*opentype.Font, error) {
+	if f, err := opentype.Parse(data); err == nil {
+		return f, nil
+	}
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Font(0)
+}
+
+// fittedFace returns a face of f sized so its glyphs fit within the line
+// height of primary, the bitmap font it stands in for
+func fittedFace(f *opentype.Font, primary font.Face) (font.Face, error) {
+	m := primary.Metrics()
+	height := float64((m.Ascent + m.Descent).Ceil())
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: height, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	// Fonts leave room above and below their glyphs; shrink by the overshoot
+	fm := face.Metrics()
+	if got := float64((fm.Ascent + fm.Descent).Ceil()); got > height {
+		_ = face.Close()
+		return opentype.NewFace(f, &opentype.FaceOptions{Size: height * height / got, DPI: 72, Hinting: font.HintingFull})
+	}
+	return face, nil
+}
+
+// fallbackFace draws each rune with primary when it has the glyph and with
+// fallback otherwise, on the same baseline. Metrics are primary's, so line
+// positions do not change.
+type fallbackFace struct {
+	primary, fallback font.Face
+}
+
+// faceFor returns the face drawing r
+func (f *fallbackFace) faceFor(r rune) font.Face {
+	if _, ok := f.primary.GlyphAdvance(r); ok {
+		return f.primary
+	}
+	return f.fallback
+}
+
+func (f *fallbackFace) Close() error { return nil }
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.faceFor(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.faceFor(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.faceFor(r).GlyphAdvance(r)
+}
+
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	if face := f.faceFor(r0); face == f.faceFor(r1) {
+		return face.Kern(r0, r1)
+	}
+	return 0
+}
+
+func (f *fallbackFace) Metrics() font.Metrics {
+	return f.primary.Metrics()
+}
@@ -0,0 +1,125 @@
+package renderer
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+)
+
+func TestTruncateTextKeepsRunesWhole(t *testing.T) {
+	for _, text := range []string{"Überwachungsstation-Küche", "сервер-гостиная-01", "家庭服务器家庭服务器家庭服务器"} {
+		got := TruncateText(text, 60)
+		if !utf8.ValidString(got) {
+			t.Errorf("TruncateText(%q) = %q, not valid UTF-8", text, got)
+		}
+		if MeasureText(got) > 60 {
+			t.Errorf("TruncateText(%q) = %q, wider than 60 px", text, got)
+		}
+		if got := TruncateTextSmall(text, 40); !utf8.ValidString(got) {
+			t.Errorf("TruncateTextSmall(%q) = %q, not valid UTF-8", text, got)
+		}
+	}
+}
+
+// Truncated text is a whole-rune prefix of the text plus as much of "..."
+// as fits, and never wider than asked
+func FuzzTruncateText(f *testing.F) {
+	f.Add("Überwachungsstation-Küche", 60)
+	f.Add("家庭服务器", 3)
+	f.Add("host", -1)
+	f.Fuzz(func(t *testing.T, text string, maxWidth int) {
+		if !utf8.ValidString(text) {
+			return
+		}
+		for _, tt := range []struct {
+			name     string
+			truncate func(string, int) string
+			measure  func(string) int
+		}{
+			{"TruncateText", TruncateText, MeasureText},
+			{"TruncateTextSmall", TruncateTextSmall, MeasureTextSmall},
+		} {
+			got := tt.truncate(text, maxWidth)
+			if got == text {
+				continue
+			}
+			if w := tt.measure(got); w > max(maxWidth, 0) {
+				t.Fatalf("%s(%q, %d) = %q, %d px wide", tt.name, text, maxWidth, got, w)
+			}
+			if !utf8.ValidString(got) || !truncatedFrom(text, got) {
+				t.Fatalf("%s(%q, %d) = %q, not a prefix with an ellipsis", tt.name, text, maxWidth, got)
+			}
+		}
+	})
+}
+
+// truncatedFrom reports whether got is a prefix of text followed by up to
+// three dots
+func truncatedFrom(text, got string) bool {
+	for dots := range 4 {
+		if strings.HasSuffix(got, strings.Repeat(".", dots)) && strings.HasPrefix(text, got[:len(got)-dots]) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoadFont(t *testing.T) {
+	t.Cleanup(func() { _ = LoadFont("") })
+
+	// The built-in fonts draw every missing character as the same box
+	if err := LoadFont(""); err != nil {
+		t.Fatalf("LoadFont(\"\") failed: %v", err)
+	}
+	before := drawnPixels(t, "Дж")
+	if drawnPixels(t, "Жд") != before {
+		t.Fatal("expected the built-in font to draw Cyrillic as boxes")
+	}
+
+	if err := LoadFont(config.FontGo); err != nil {
+		t.Fatalf("LoadFont(%q) failed: %v", config.FontGo, err)
+	}
+	if drawnPixels(t, "Дж") == drawnPixels(t, "Жд") {
+		t.Error("expected Cyrillic letters drawn with their own glyphs")
+	}
+	if m := textFace.Metrics(); m.Ascent.Ceil()+m.Descent.Ceil() != ScaledTextHeight(1) {
+		t.Errorf("expected the line height kept at %d px with a fallback font", ScaledTextHeight(1))
+	}
+	// ASCII still uses the bitmap font
+	if MeasureText("abc") != 21 {
+		t.Errorf("expected ASCII at 7 px per character, got %d px for 3", MeasureText("abc"))
+	}
+
+	if err := LoadFont(filepath.Join(t.TempDir(), "missing.ttf")); err == nil {
+		t.Error("expected an error for a missing font file")
+	}
+}
+
+// drawnPixels returns the lit pixels of text drawn on a blank display
+func drawnPixels(t *testing.T, text string) string {
+	t.Helper()
+	disp := display.NewMockDisplay(64, 16)
+	if err := DrawText(disp, 0, 0, text); err != nil {
+		t.Fatalf("DrawText failed: %v", err)
+	}
+	return disp.String()
+}
+
+func TestWrapTextBreaksCJK(t *testing.T) {
+	lines := wrapText("家庭服务器家庭服务器 ok", 40, MeasureText)
+	if len(lines) < 2 {
+		t.Fatalf("expected the CJK text broken over lines, got %q", lines)
+	}
+	for _, line := range lines {
+		if MeasureText(line) > 40 {
+			t.Errorf("line %q wider than 40 px", line)
+		}
+	}
+	if lines := wrapText("supercalifragilistic", 40, MeasureText); len(lines) != 1 {
+		t.Errorf("expected a long Latin word kept whole, got %q", lines)
+	}
+}
@@ -0,0 +1,153 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// Dial geometry: the scale sweeps 270° clockwise from the bottom left to the
+// bottom right, leaving the gap at the bottom for nothing to collide with
+const (
+	dialStart = -135.0
+	dialSweep = 270.0
+
+	// CPU temperature range shown on the dial, in degrees Celsius
+	dialTempMin = 20.0
+	dialTempMax = 100.0
+
+	// dialMinRadius is the smallest dial worth drawing; smaller ones cannot
+	// fit the reading inside
+	dialMinRadius = 24
+)
+
+// dialTrack is the colour of the unfilled part of a dial
+var dialTrack = color.NRGBA{R: 48, G: 48, B: 48, A: 255}
+
+// DrawArc draws the part of a ring centred on (cx, cy) between radius r and
+// r-thickness+1, from angle start to end in degrees clockwise from 12
+// o'clock. An arc from 0 to 360 is a full ring.
+func DrawArc(disp display.Display, cx, cy, r, thickness int, start, end float64, c color.Color) error {
+	if r <= 0 || thickness <= 0 || end <= start {
+		return nil
+	}
+	cd := display.AsColorDisplay(disp)
+	outer := float64(r) + 0.5
+	inner := math.Max(float64(r-thickness)+0.5, 0)
+	full := end-start >= 360
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			d := math.Hypot(float64(x), float64(y))
+			if d > outer || d <= inner {
+				continue
+			}
+			if !full && !angleWithin(clockAngle(x, y), start, end) {
+				continue
+			}
+			if err := cd.DrawPixelColor(cx+x, cy+y, c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DrawCircle draws a one pixel circle outline of radius r centred on (cx, cy)
+func DrawCircle(disp display.Display, cx, cy, r int, c color.Color) error {
+	return DrawArc(disp, cx, cy, r, 1, 0, 360, c)
+}
+
+// clockAngle returns the angle of (x, y) in degrees clockwise from 12
+// o'clock, in (-180, 180]
+func clockAngle(x, y int) float64 {
+	return math.Atan2(float64(x), float64(-y)) * 180 / math.Pi
+}
+
+// angleWithin reports whether a lies between start and end, both in degrees
+// and possibly outside (-180, 180]
+func angleWithin(a, start, end float64) bool {
+	for a < start {
+		a += 360
+	}
+	return a <= end
+}
+
+// DrawGauge draws a dial of radius r centred on (cx, cy): a 270° track with
+// the part up to percent (clamped to 0-100) in c
+func DrawGauge(disp display.Display, cx, cy, r int, percent float64, c color.Color) error {
+	thickness := max(r/5, 2)
+	percent = math.Max(0, math.Min(100, percent))
+	value := dialStart + dialSweep*percent/100
+	if err := DrawArc(disp, cx, cy, r, thickness, value, dialStart+dialSweep, dialTrack); err != nil {
+		return err
+	}
+	return DrawArc(disp, cx, cy, r, thickness, dialStart, value, c)
+}
+
+// gaugeRadius returns the radius of the largest dial that fits area, or 0
+// when it is too small for one
+func gaugeRadius(area image.Rectangle) int {
+	r := min(area.Dx(), area.Dy())/2 - MarginLeft
+	if r < dialMinRadius {
+		return 0
+	}
+	return r
+}
+
+// tempGaugeWidget shows the CPU temperature as a dial with the reading, and
+// the fan speed when fan control is enabled, inside it. It is redrawn when
+// the reading or the dial's fill changes.
+type tempGaugeWidget struct {
+	area image.Rectangle
+	unit tempUnit
+	last string // reading and fill last drawn
+}
+
+func (g *tempGaugeWidget) draw(disp display.Display, s *stats.SystemStats, force bool) (bool, error) {
+	r := gaugeRadius(g.area)
+	cx := g.area.Min.X + g.area.Dx()/2
+	cy := g.area.Min.Y + g.area.Dy()/2
+
+	text, c, percent := "N/A", ColorGreen, 0.0
+	if s.CPUTemp > 0 {
+		celsius := g.unit.celsius(s.CPUTemp)
+		text, c = sprintf("%.1f%s", g.unit.value(s.CPUTemp), g.unit.symbol(0)), TempColor(celsius)
+		percent = (celsius - dialTempMin) / (dialTempMax - dialTempMin) * 100
+	}
+	fan := fanText(s, true)
+	// Fill is quantized to whole degrees of sweep; finer changes are invisible
+	key := fmt.Sprintf("%s|%s|%.0f", text, fan, dialSweep*math.Max(0, math.Min(100, percent))/100)
+	if !force && key == g.last {
+		return false, nil
+	}
+	g.last = key
+
+	if err := display.AsColorDisplay(disp).FillRectColor(cx-r, cy-r, 2*r+1, 2*r+1, color.Black); err != nil {
+		return false, err
+	}
+	if err := DrawGauge(disp, cx, cy, r, percent, c); err != nil {
+		return false, err
+	}
+	textY := cy - FontHeight
+	if fan != "" {
+		textY -= FontHeight / 2
+	}
+	if err := drawTextCenteredIn(disp, cx, textY, text, c); err != nil {
+		return false, err
+	}
+	if fan != "" {
+		if err := drawTextCenteredIn(disp, cx, textY+FontHeight+4, fan[1:], ColorGreen); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// drawTextCenteredIn draws text centred horizontally on x
+func drawTextCenteredIn(disp display.Display, x, y int, text string, c color.Color) error {
+	return DrawTextColor(disp, x-MeasureText(text)/2, y, text, c)
+}
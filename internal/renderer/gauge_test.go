@@ -0,0 +1,95 @@
+package renderer
+
+import (
+	"image"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestDrawArc(t *testing.T) {
+	disp := display.NewOffscreenDisplay(41, 41)
+	// Right half of a ring, 3 pixels thick
+	if err := DrawArc(disp, 20, 20, 15, 3, 0, 180, ColorRed); err != nil {
+		t.Fatalf("DrawArc() failed: %v", err)
+	}
+	img := disp.Image()
+	lit := func(x, y int) bool { return img.NRGBAAt(x, y).R == 255 }
+
+	for _, p := range [][2]int{{20, 5}, {35, 20}, {20, 35}, {33, 20}} {
+		if !lit(p[0], p[1]) {
+			t.Errorf("expected (%d,%d) on the arc", p[0], p[1])
+		}
+	}
+	for _, p := range [][2]int{{5, 20}, {20, 20}, {32, 20}, {36, 20}} {
+		if lit(p[0], p[1]) {
+			t.Errorf("expected (%d,%d) off the arc", p[0], p[1])
+		}
+	}
+
+	if err := DrawCircle(disp, 20, 20, 10, ColorGreen); err != nil {
+		t.Fatalf("DrawCircle() failed: %v", err)
+	}
+	if img.NRGBAAt(10, 20).G != 255 || img.NRGBAAt(30, 20).G != 255 {
+		t.Error("expected a full circle")
+	}
+}
+
+func TestSystemPageGauge(t *testing.T) {
+	s := &stats.SystemStats{
+		Hostname:    "testhost",
+		CPUTemp:     60,
+		MemoryUsed:  1 << 30,
+		MemoryTotal: 4 << 30,
+		DiskUsed:    10 << 30,
+		DiskTotal:   100 << 30,
+	}
+
+	layout := NewLayout(image.Rect(0, 0, 128, 128), 0)
+	if layout.GaugeArea.Empty() {
+		t.Fatal("expected room for a dial on 128x128")
+	}
+	if !NewLayout(image.Rect(0, 0, 128, 64), 0).GaugeArea.Empty() {
+		t.Error("expected no dial on 128x64")
+	}
+
+	// Colour panels show the temperature as a dial: at 60C (half the
+	// scale) the dial is filled up to 12 o'clock
+	disp := display.NewOffscreenDisplay(128, 128)
+	page := NewSystemPage(0)
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	area := layout.GaugeArea
+	cx, cy, r := area.Dx()/2, area.Min.Y+area.Dy()/2, gaugeRadius(area)
+	if got := disp.Image().NRGBAAt(cx-r+1, cy); got != TempColor(60) {
+		t.Errorf("expected the filled dial left of centre, got %v", got)
+	}
+	if got := disp.Image().NRGBAAt(cx+r-1, cy); got != dialTrack {
+		t.Errorf("expected the unfilled track right of centre, got %v", got)
+	}
+
+	// An unchanged reading is not redrawn
+	if changed, err := page.update(disp, s, page.widgets.items[0].next); err != nil || changed {
+		t.Errorf("update() = %v, %v; want no change", changed, err)
+	}
+
+	// Monochrome panels keep the text line
+	monoPage := NewSystemPage(0)
+	if err := monoPage.Render(display.NewMockDisplay(128, 128), s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	lines := 0
+	for _, item := range monoPage.widgets.items {
+		if _, ok := item.w.(*tempGaugeWidget); ok {
+			t.Error("expected no dial on a monochrome panel")
+		}
+		if _, ok := item.w.(*lineWidget); ok {
+			lines++
+		}
+	}
+	if lines != 3 {
+		t.Errorf("expected 3 metric lines, got %d", lines)
+	}
+}
@@ -0,0 +1,123 @@
+package renderer
+
+import (
+	"container/list"
+	"image"
+	"image/color"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// textCacheSize bounds how many rasterized strings are kept. Pages redraw
+// mostly the same labels every refresh, and values that change (IPs, usage
+// percentages) cycle through a small set, so a few hundred entries cover a
+// full rotation comfortably.
+const textCacheSize = 256
+
+// textRaster is a rendered string, ready to draw without touching the font
+type textRaster struct {
+	img *image.NRGBA  // text in colour on a transparent background
+	lit []image.Point // pixels covered by glyphs, relative to the top-left corner
+}
+
+type textKey struct {
+	face font.Face
+	text string
+	c    color.NRGBA
+}
+
+type textEntry struct {
+	key    textKey
+	raster *textRaster
+}
+
+// textRasterCache is an LRU cache of rasterized strings keyed by face, text
+// and colour. It removes the per-call image allocation and font drawing on
+// every refresh.
+type textRasterCache struct {
+	mu      sync.Mutex
+	max     int
+	ll      *list.List // front = most recently used
+	entries map[textKey]*list.Element
+	hits    uint64
+	misses  uint64
+}
+
+func newTextRasterCache(size int) *textRasterCache {
+	return &textRasterCache{
+		max:     size,
+		ll:      list.New(),
+		entries: make(map[textKey]*list.Element),
+	}
+}
+
+// textCache is shared by all text drawing helpers
+var textCache = newTextRasterCache(textCacheSize)
+
+// get returns the raster for text, rendering and caching it on a miss
+func (tc *textRasterCache) get(face font.Face, text string, c color.Color) *textRaster {
+	key := textKey{face: face, text: text, c: color.NRGBAModel.Convert(c).(color.NRGBA)}
+
+	tc.mu.Lock()
+	if el, ok := tc.entries[key]; ok {
+		tc.ll.MoveToFront(el)
+		tc.hits++
+		tc.mu.Unlock()
+		return el.Value.(*textEntry).raster
+	}
+	tc.misses++
+	tc.mu.Unlock()
+
+	// Render outside the lock; a concurrent miss for the same key just
+	// produces an identical raster
+	raster := rasterize(face, text, key.c)
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if el, ok := tc.entries[key]; ok {
+		tc.ll.MoveToFront(el)
+		return el.Value.(*textEntry).raster
+	}
+	tc.entries[key] = tc.ll.PushFront(&textEntry{key: key, raster: raster})
+	if tc.ll.Len() > tc.max {
+		oldest := tc.ll.Back()
+		tc.ll.Remove(oldest)
+		delete(tc.entries, oldest.Value.(*textEntry).key)
+	}
+	return raster
+}
+
+// stats returns cache hits, misses and current size
+func (tc *textRasterCache) stats() (hits, misses uint64, size int) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.hits, tc.misses, tc.ll.Len()
+}
+
+// rasterize draws text with face into a new image and records its lit pixels
+func rasterize(face font.Face, text string, c color.NRGBA) *textRaster {
+	width := font.MeasureString(face, text).Ceil()
+	height := face.Metrics().Ascent.Ceil() + face.Metrics().Descent.Ceil()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  &image.Uniform{c},
+		Face: face,
+		Dot:  fixed.P(0, face.Metrics().Ascent.Ceil()),
+	}
+	drawer.DrawString(text)
+
+	var lit []image.Point
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// Same opacity threshold the drivers apply in DrawImage
+			if img.NRGBAAt(x, y).A >= 128 {
+				lit = append(lit, image.Pt(x, y))
+			}
+		}
+	}
+	return &textRaster{img: img, lit: lit}
+}
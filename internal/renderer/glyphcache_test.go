@@ -0,0 +1,55 @@
+package renderer
+
+import (
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+func TestTextRasterCacheHits(t *testing.T) {
+	tc := newTextRasterCache(8)
+	face := basicfont.Face7x13
+
+	first := tc.get(face, "CPU 42%", color.White)
+	second := tc.get(face, "CPU 42%", color.White)
+	if first != second {
+		t.Error("expected the same raster for a repeated string")
+	}
+	if len(first.lit) == 0 {
+		t.Error("expected lit pixels for non-empty text")
+	}
+
+	// A different colour is a different raster
+	red := tc.get(face, "CPU 42%", color.NRGBA{R: 255, A: 255})
+	if red == first {
+		t.Error("expected a separate raster for a different colour")
+	}
+
+	hits, misses, size := tc.stats()
+	if hits != 1 || misses != 2 || size != 2 {
+		t.Errorf("stats = %d hits, %d misses, size %d; want 1, 2, 2", hits, misses, size)
+	}
+}
+
+func TestTextRasterCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	tc := newTextRasterCache(2)
+	face := basicfont.Face7x13
+
+	a := tc.get(face, "a", color.White)
+	tc.get(face, "b", color.White)
+	tc.get(face, "a", color.White) // a is now the most recently used
+	tc.get(face, "c", color.White) // evicts b
+
+	if _, _, size := tc.stats(); size != 2 {
+		t.Errorf("expected cache bounded at 2 entries, got %d", size)
+	}
+	if got := tc.get(face, "a", color.White); got != a {
+		t.Error("expected recently used entry to survive eviction")
+	}
+	_, missesBefore, _ := tc.stats()
+	tc.get(face, "b", color.White)
+	if _, misses, _ := tc.stats(); misses != missesBefore+1 {
+		t.Error("expected evicted entry to be rendered again")
+	}
+}
@@ -105,12 +105,102 @@ var cpuTempBitmap = [IconHeight][IconWidth]byte{
 	{0, 0, 255, 255, 255, 255, 255, 255, 0, 0},
 }
 
+// voltageBitmap is a lightning bolt (supply voltage).
+//
+//	_____XXX__
+//	____XXX___
+//	___XXX____
+//	__XXX_____
+//	_XXXXXXXX_
+//	_XXXXXXXX_
+//	_____XXX__
+//	____XXX___
+//	___XXX____
+//	__XX______
+//	_X________
+//
+//nolint:dupl // bitmap pixel data — not a logic duplicate
+var voltageBitmap = [IconHeight][IconWidth]byte{
+	{0, 0, 0, 0, 0, 255, 255, 255, 0, 0},
+	{0, 0, 0, 0, 255, 255, 255, 0, 0, 0},
+	{0, 0, 0, 255, 255, 255, 0, 0, 0, 0},
+	{0, 0, 255, 255, 255, 0, 0, 0, 0, 0},
+	{0, 255, 255, 255, 255, 255, 255, 255, 255, 0},
+	{0, 255, 255, 255, 255, 255, 255, 255, 255, 0},
+	{0, 0, 0, 0, 0, 255, 255, 255, 0, 0},
+	{0, 0, 0, 0, 255, 255, 255, 0, 0, 0},
+	{0, 0, 0, 255, 255, 255, 0, 0, 0, 0},
+	{0, 0, 255, 255, 0, 0, 0, 0, 0, 0},
+	{0, 255, 0, 0, 0, 0, 0, 0, 0, 0},
+}
+
+// frequencyBitmap is a processor die with pins on every side (clock frequency).
+//
+//	__X_XX_X__
+//	_XXXXXXXX_
+//	XX______XX
+//	_X_XXXX_X_
+//	XX_X__X_XX
+//	_X_X__X_X_
+//	XX_X__X_XX
+//	_X_XXXX_X_
+//	XX______XX
+//	_XXXXXXXX_
+//	__X_XX_X__
+//
+//nolint:dupl // bitmap pixel data — not a logic duplicate
+var frequencyBitmap = [IconHeight][IconWidth]byte{
+	{0, 0, 255, 0, 255, 255, 0, 255, 0, 0},
+	{0, 255, 255, 255, 255, 255, 255, 255, 255, 0},
+	{255, 255, 0, 0, 0, 0, 0, 0, 255, 255},
+	{0, 255, 0, 255, 255, 255, 255, 0, 255, 0},
+	{255, 255, 0, 255, 0, 0, 255, 0, 255, 255},
+	{0, 255, 0, 255, 0, 0, 255, 0, 255, 0},
+	{255, 255, 0, 255, 0, 0, 255, 0, 255, 255},
+	{0, 255, 0, 255, 255, 255, 255, 0, 255, 0},
+	{255, 255, 0, 0, 0, 0, 0, 0, 255, 255},
+	{0, 255, 255, 255, 255, 255, 255, 255, 255, 0},
+	{0, 0, 255, 0, 255, 255, 0, 255, 0, 0},
+}
+
+// throttleBitmap is a downward arrow (reduced speed).
+//
+//	___XXXX___
+//	___XXXX___
+//	___XXXX___
+//	___XXXX___
+//	___XXXX___
+//	XXXXXXXXXX
+//	_XXXXXXXX_
+//	__XXXXXX__
+//	___XXXX___
+//	____XX____
+//	__________
+//
+//nolint:dupl // bitmap pixel data — not a logic duplicate
+var throttleBitmap = [IconHeight][IconWidth]byte{
+	{0, 0, 0, 255, 255, 255, 255, 0, 0, 0},
+	{0, 0, 0, 255, 255, 255, 255, 0, 0, 0},
+	{0, 0, 0, 255, 255, 255, 255, 0, 0, 0},
+	{0, 0, 0, 255, 255, 255, 255, 0, 0, 0},
+	{0, 0, 0, 255, 255, 255, 255, 0, 0, 0},
+	{255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+	{0, 255, 255, 255, 255, 255, 255, 255, 255, 0},
+	{0, 0, 255, 255, 255, 255, 255, 255, 0, 0},
+	{0, 0, 0, 255, 255, 255, 255, 0, 0, 0},
+	{0, 0, 0, 0, 255, 255, 0, 0, 0, 0},
+	{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+}
+
 // Cached icon images, initialized lazily via sync.Once.
 var (
-	iconDisk   *image.Gray
-	iconMemory *image.Gray
-	iconCPU    *image.Gray
-	iconsOnce  sync.Once
+	iconDisk      *image.Gray
+	iconMemory    *image.Gray
+	iconCPU       *image.Gray
+	iconVoltage   *image.Gray
+	iconFrequency *image.Gray
+	iconThrottle  *image.Gray
+	iconsOnce     sync.Once
 )
 
 // initIcons lazily creates the Gray images from bitmap data.
@@ -119,6 +209,9 @@ func initIcons() {
 		iconDisk = bitmapToGray(&diskBitmap)
 		iconMemory = bitmapToGray(&memoryBitmap)
 		iconCPU = bitmapToGray(&cpuTempBitmap)
+		iconVoltage = bitmapToGray(&voltageBitmap)
+		iconFrequency = bitmapToGray(&frequencyBitmap)
+		iconThrottle = bitmapToGray(&throttleBitmap)
 	})
 }
 
@@ -11,9 +11,12 @@ func TestIconDimensions(t *testing.T) {
 	initIcons()
 
 	icons := map[string]*iconInfo{
-		"disk":   {iconDisk},
-		"memory": {iconMemory},
-		"cpu":    {iconCPU},
+		"disk":      {iconDisk},
+		"memory":    {iconMemory},
+		"cpu":       {iconCPU},
+		"voltage":   {iconVoltage},
+		"frequency": {iconFrequency},
+		"throttle":  {iconThrottle},
 	}
 
 	for name, ic := range icons {
@@ -33,9 +36,12 @@ func TestIconsNotBlank(t *testing.T) {
 	initIcons()
 
 	icons := map[string]*iconInfo{
-		"disk":   {iconDisk},
-		"memory": {iconMemory},
-		"cpu":    {iconCPU},
+		"disk":      {iconDisk},
+		"memory":    {iconMemory},
+		"cpu":       {iconCPU},
+		"voltage":   {iconVoltage},
+		"frequency": {iconFrequency},
+		"throttle":  {iconThrottle},
 	}
 
 	for name, ic := range icons {
@@ -0,0 +1,54 @@
+package renderer
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// ipv6Display is how the network pages show IPv6 addresses
+type ipv6Display struct {
+	format string // one of config.IPv6Formats; "" is the full address
+	labels bool   // prefix addresses with their scope, e.g. "ULA"
+}
+
+// address formats addr for display. IPv4 addresses and text that is not an
+// address are returned unchanged.
+func (d ipv6Display) address(addr string) string {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil || !ip.Is6() || ip.Is4In6() {
+		return addr
+	}
+	zone := ip.Zone()
+	ip = ip.WithZone("")
+
+	var text string
+	switch d.format {
+	case config.IPv6FormatPrefix:
+		// The network has no zone
+		text, zone = netip.PrefixFrom(ip, 64).Masked().String(), ""
+	case config.IPv6FormatSuffix:
+		b := ip.As16()
+		clear(b[:8])
+		text = netip.AddrFrom16(b).String()
+	case config.IPv6FormatShort:
+		groups := strings.Split(ip.StringExpanded(), ":")
+		for i, g := range groups {
+			if groups[i] = strings.TrimLeft(g, "0"); groups[i] == "" {
+				groups[i] = "0"
+			}
+		}
+		text = groups[0] + ":" + groups[1] + ".." + groups[7]
+	default:
+		text = ip.String()
+	}
+	if zone != "" {
+		text += "%" + zone
+	}
+	if scope := stats.IPv6Scope(addr); d.labels && scope != "" {
+		text = scope + " " + text
+	}
+	return text
+}
@@ -0,0 +1,32 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+func TestIPv6DisplayAddress(t *testing.T) {
+	tests := []struct {
+		format string
+		labels bool
+		addr   string
+		want   string
+	}{
+		{config.IPv6FormatFull, false, "2001:db8:85a3::8a2e:370:7334", "2001:db8:85a3::8a2e:370:7334"},
+		{config.IPv6FormatPrefix, false, "2001:db8:85a3::8a2e:370:7334", "2001:db8:85a3::/64"},
+		{config.IPv6FormatSuffix, false, "2001:db8:85a3::8a2e:370:7334", "::8a2e:370:7334"},
+		{config.IPv6FormatShort, false, "2001:db8:85a3::8a2e:370:7334", "2001:db8..7334"},
+		{config.IPv6FormatFull, true, "fd12:3456:789a:1::2", "ULA fd12:3456:789a:1::2"},
+		{config.IPv6FormatSuffix, true, "fe80::1%eth0", "LL ::1%eth0"},
+		{config.IPv6FormatPrefix, false, "fe80::1%eth0", "fe80::/64"},
+		{config.IPv6FormatShort, true, "192.168.1.2", "192.168.1.2"},
+		{"", false, "no addr", "no addr"},
+	}
+	for _, tt := range tests {
+		d := ipv6Display{format: tt.format, labels: tt.labels}
+		if got := d.address(tt.addr); got != tt.want {
+			t.Errorf("address(%q) with format %q, labels %v = %q, want %q", tt.addr, tt.format, tt.labels, got, tt.want)
+		}
+	}
+}
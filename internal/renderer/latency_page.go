@@ -0,0 +1,175 @@
+package renderer
+
+import (
+	"image/color"
+	"slices"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// latencyWaitingText is shown until the first round of pings finishes
+const latencyWaitingText = "Pinging..."
+
+// LatencyPage shows the round trip time to each latency monitor host, one
+// host per row, coloured by the warn and critical thresholds. Wide displays
+// add a graph of recent round trips beside each.
+type LatencyPage struct {
+	warn, critical time.Duration
+	lines          int // configured line count (0=auto, 2=default, 4=compact)
+	widgets        widgetSet
+}
+
+// NewLatencyPage creates a latency page with the given colour thresholds
+func NewLatencyPage(warn, critical time.Duration, lines int) *LatencyPage {
+	return &LatencyPage{warn: warn, critical: critical, lines: lines}
+}
+
+// Title returns the page title
+func (p *LatencyPage) Title() string {
+	return "Latency"
+}
+
+// Render draws a row per host under the hostname header. Hosts beyond the
+// content rows are left out.
+func (p *LatencyPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	maxWidth := bounds.Dx() - 2*MarginLeft
+
+	if err := drawPageHeader(disp, layout, s.Hostname); err != nil {
+		return err
+	}
+
+	graphWidth := 0
+	if layout.Width >= barWideDisplay {
+		graphWidth = layout.Width / 3
+	}
+	textWidth := maxWidth
+	if graphWidth > 0 {
+		textWidth -= graphWidth + barGap
+	}
+	rowHeight := ScaledTextHeight(layout.TextScale)
+
+	// One widget per row; the rows only change when a round of pings finishes
+	p.widgets.reset()
+	for row, y := range layout.ContentLines {
+		p.widgets.add(&lineWidget{
+			x:     MarginLeft,
+			y:     y,
+			width: textWidth,
+			scale: layout.TextScale,
+			content: func(s *stats.SystemStats) []textSpan {
+				text, c := p.row(s, row)
+				if text == "" {
+					return nil
+				}
+				if layout.TextScale > 0 && layout.TextScale < 1 {
+					return span(TruncateTextSmall(text, textWidth), c)
+				}
+				return span(TruncateText(text, textWidth), c)
+			},
+		}, 0)
+		if graphWidth > 0 {
+			p.widgets.add(&latencyGraphWidget{
+				x: bounds.Dx() - MarginRight - graphWidth, y: y + 1, w: graphWidth, h: rowHeight - 2,
+				host: row, page: p,
+			}, 0)
+		}
+	}
+	if err := p.widgets.render(disp, s, time.Now()); err != nil {
+		return err
+	}
+
+	return disp.Show()
+}
+
+// update redraws the rows whose readings changed
+func (p *LatencyPage) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	return p.widgets.update(disp, s, now)
+}
+
+// row returns the text and colour of host i's row
+func (p *LatencyPage) row(s *stats.SystemStats, i int) (string, color.NRGBA) {
+	if s.Latency == nil {
+		if i == 0 {
+			return latencyWaitingText, ColorYellow
+		}
+		return "", ColorGreen
+	}
+	if i >= len(s.Latency) {
+		return "", ColorGreen
+	}
+	r := s.Latency[i]
+	if r.Err != nil {
+		return r.Name + " lost", ColorRed
+	}
+	return r.Name + " " + formatLatency(r.RTT), p.color(r.RTT)
+}
+
+// color returns the colour of a round trip time: green, yellow from the warn
+// threshold and red from the critical one. Lost pings (0) are red.
+func (p *LatencyPage) color(rtt time.Duration) color.NRGBA {
+	switch {
+	case rtt == 0 || rtt >= p.critical:
+		return ColorRed
+	case rtt >= p.warn:
+		return ColorYellow
+	default:
+		return ColorGreen
+	}
+}
+
+// TextLines shows a row per host
+func (p *LatencyPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	var lines []string
+	for i := range rows {
+		text, _ := p.row(s, i)
+		lines = append(lines, text)
+	}
+	return lines
+}
+
+// latencyGraphWidget graphs a host's recent round trip times as bars, newest
+// on the right, scaled to the critical threshold or the slowest round trip
+// if higher. Lost pings are full-height red bars.
+type latencyGraphWidget struct {
+	x, y, w, h int
+	host       int // index in SystemStats.Latency
+	page       *LatencyPage
+	last       []time.Duration
+}
+
+func (g *latencyGraphWidget) draw(disp display.Display, s *stats.SystemStats, force bool) (bool, error) {
+	var history []time.Duration
+	if g.host < len(s.Latency) {
+		history = s.Latency[g.host].History
+	}
+	history = history[max(len(history)-g.w, 0):]
+	if !force && slices.Equal(history, g.last) {
+		return false, nil
+	}
+	g.last = history
+
+	cd := display.AsColorDisplay(disp)
+	if err := cd.FillRectColor(g.x, g.y, g.w, g.h, color.Black); err != nil {
+		return false, err
+	}
+	top := max(g.page.critical, slices.Max(append([]time.Duration{1}, history...)))
+	x := g.x + g.w - len(history)
+	for i, rtt := range history {
+		h := g.h
+		if rtt > 0 {
+			h = max(int(int64(g.h)*int64(rtt)/int64(top)), 1)
+		}
+		if err := cd.FillRectColor(x+i, g.y+g.h-h, 1, h, g.page.color(rtt)); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,85 @@
+package renderer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestLatencyPageRows(t *testing.T) {
+	page := NewLatencyPage(50*time.Millisecond, 200*time.Millisecond, 0)
+	s := &stats.SystemStats{Hostname: "router"}
+	if text, c := page.row(s, 0); text != latencyWaitingText || c != ColorYellow {
+		t.Errorf("row 0 = %q, want the waiting text", text)
+	}
+
+	s.Latency = []stats.LatencyReading{
+		{Name: "gw", RTT: 2 * time.Millisecond},
+		{Name: "isp", RTT: 80 * time.Millisecond},
+		{Name: "dns", RTT: 250 * time.Millisecond},
+		{Name: "vpn", Err: errors.New("no reply from vpn")},
+	}
+	tests := []struct {
+		text string
+		c    any
+	}{
+		{"gw 2 ms", ColorGreen},
+		{"isp 80 ms", ColorYellow},
+		{"dns 250 ms", ColorRed},
+		{"vpn lost", ColorRed},
+	}
+	for i, tt := range tests {
+		if text, c := page.row(s, i); text != tt.text || c != tt.c {
+			t.Errorf("row %d = %q in %v, want %q in %v", i, text, c, tt.text, tt.c)
+		}
+	}
+}
+
+func TestLatencyGraph(t *testing.T) {
+	page := NewLatencyPage(50*time.Millisecond, 100*time.Millisecond, 0)
+	s := &stats.SystemStats{
+		Hostname: "router",
+		Latency:  []stats.LatencyReading{{Name: "gw", RTT: 50 * time.Millisecond, History: []time.Duration{0, 50 * time.Millisecond}}},
+	}
+
+	disp := display.NewOffscreenDisplay(320, 240)
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	g := page.widgets.items[1].w.(*latencyGraphWidget)
+	img := disp.Image()
+	// The newest sample is the rightmost bar, half the height of the scale;
+	// the lost ping before it is a full-height red bar
+	right := g.x + g.w - 1
+	if c := img.NRGBAAt(right, g.y+g.h-1); c != ColorYellow {
+		t.Errorf("expected a yellow bar at the bottom right, got %v", c)
+	}
+	if c := img.NRGBAAt(right, g.y); c.R|c.G|c.B != 0 {
+		t.Errorf("expected the top of the half-height bar to be clear, got %v", c)
+	}
+	if c := img.NRGBAAt(right-1, g.y); c != ColorRed {
+		t.Errorf("expected a full-height red bar for the lost ping, got %v", c)
+	}
+}
+
+func TestBuildPagesLatency(t *testing.T) {
+	cfg := config.Default()
+	cfg.Latency.Enabled = true
+	cfg.Latency.Hosts = []config.LatencyTarget{{Host: "192.0.2.1"}}
+	r := NewRenderer(display.NewOffscreenDisplay(128, 64), cfg)
+	r.BuildPages(&stats.SystemStats{Hostname: "router"})
+
+	found := false
+	for i := 0; i < r.PageCount(); i++ {
+		if r.PageType(i) == config.PageLatency {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a latency page when the monitor is enabled")
+	}
+}
@@ -1,6 +1,9 @@
 package renderer
 
-import "image"
+import (
+	"image"
+	"unicode/utf8"
+)
 
 // Layout constants
 const (
@@ -36,6 +39,10 @@ type Layout struct {
 	ShowSeparator   bool    // Whether to show separator line
 	MaxContentLines int     // Maximum content lines available
 	TextScale       float64 // 0 or 1 = full-size font; 0.5 = half-height (128x32 acting as 128x64)
+	// GaugeArea is the room below the first two content lines where large
+	// displays can show a metric as a dial instead of a third line; empty
+	// on smaller displays
+	GaugeArea image.Rectangle
 }
 
 // NewLayout creates an adaptive layout based on display bounds and the
@@ -97,6 +104,9 @@ func NewLayout(bounds image.Rectangle, lines int) *Layout {
 		layout.ContentLines = []int{20, 36, 52, 68, 84, 100}
 		layout.FooterY = 116
 		layout.MaxContentLines = 6
+		if width >= 128 && height >= 128 {
+			layout.GaugeArea = image.Rect(0, layout.ContentLines[2], width, height)
+		}
 	}
 
 	return layout
@@ -104,6 +114,6 @@ func NewLayout(bounds image.Rectangle, lines int) *Layout {
 
 // CenterText calculates the X coordinate to center text
 func CenterText(text string, displayWidth int) int {
-	textWidth := len(text) * FontWidth
+	textWidth := utf8.RuneCountInString(text) * FontWidth
 	return (displayWidth - textWidth) / 2
 }
@@ -7,23 +7,24 @@ import (
 
 	"github.com/ausil/i2c-display/internal/display"
 	"github.com/ausil/i2c-display/internal/stats"
+	"github.com/ausil/i2c-display/internal/timeseries"
 )
 
 const loadHistorySize = 300 // 5 minutes at 1s refresh
 
 // LoadGraphPage displays a rolling graph of system load average
 type LoadGraphPage struct {
-	history []float64 // ring buffer, max loadHistorySize entries
-	head    int       // next write position
-	count   int       // number of valid entries
-	numCPU  int       // cached CPU count for scaling
-	lines   int       // configured line count (0=auto, 2=default, 4=compact)
+	history *timeseries.Store // load samples graphed, recorded by the rotation manager
+	numCPU  int               // cached CPU count for scaling
+	lines   int               // configured line count (0=auto, 2=default, 4=compact)
+	perCore bool              // show the averages divided by the CPU count
 }
 
-// NewLoadGraphPage creates a new load graph page
+// NewLoadGraphPage creates a new load graph page with a history of its own;
+// BuildPages points it at the renderer's
 func NewLoadGraphPage(lines int) *LoadGraphPage {
 	return &LoadGraphPage{
-		history: make([]float64, loadHistorySize),
+		history: timeseries.New(loadHistorySize, 0),
 		lines:   lines,
 	}
 }
@@ -35,13 +36,6 @@ func (p *LoadGraphPage) Title() string {
 
 // Render draws the load graph page
 func (p *LoadGraphPage) Render(disp display.Display, s *stats.SystemStats) error {
-	// Record current load into ring buffer
-	p.history[p.head] = s.LoadAvg1
-	p.head = (p.head + 1) % loadHistorySize
-	if p.count < loadHistorySize {
-		p.count++
-	}
-
 	// Cache CPU count
 	if s.NumCPU > 0 {
 		p.numCPU = s.NumCPU
@@ -79,13 +73,50 @@ func (p *LoadGraphPage) Render(disp display.Display, s *stats.SystemStats) error
 	return p.renderGraph(disp, s, layout, bounds)
 }
 
+// TextLines shows the load averages under the hostname; the graph has no
+// text form
+func (p *LoadGraphPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	load1, load5, load15 := p.averages(s)
+	return []string{
+		centerText(s.Hostname, cols),
+		fitText(
+			sprintf(tr("Load")+" %.2f %.2f %.2f", load1, load5, load15),
+			sprintf("L:%.2f %.2f %.2f", load1, load5, load15),
+			cols),
+	}
+}
+
+// cpus returns the CPU count the load is spread over, the last one seen
+// when s has none
+func (p *LoadGraphPage) cpus(s *stats.SystemStats) int {
+	switch {
+	case s.NumCPU > 0:
+		return s.NumCPU
+	case p.numCPU > 0:
+		return p.numCPU
+	default:
+		return 1
+	}
+}
+
+// averages returns the 1, 5 and 15 minute load averages as shown: as read,
+// or divided by the CPU count when shown per core
+func (p *LoadGraphPage) averages(s *stats.SystemStats) (load1, load5, load15 float64) {
+	if !p.perCore {
+		return s.LoadAvg1, s.LoadAvg5, s.LoadAvg15
+	}
+	n := float64(p.cpus(s))
+	return s.LoadAvg1 / n, s.LoadAvg5 / n, s.LoadAvg15 / n
+}
+
 // renderSmall renders text-only output for small displays (height <= 32)
 func (p *LoadGraphPage) renderSmall(disp display.Display, s *stats.SystemStats, layout *Layout) error {
 	if len(layout.ContentLines) == 0 {
 		return disp.Show()
 	}
 
-	text := fmt.Sprintf("L:%.2f %.2f %.2f", s.LoadAvg1, s.LoadAvg5, s.LoadAvg15)
+	load1, load5, load15 := p.averages(s)
+	text := sprintf("L:%.2f %.2f %.2f", load1, load5, load15)
 	maxWidth := layout.Width - 2*MarginLeft
 	if layout.TextScale > 0 && layout.TextScale < 1 {
 		text = TruncateTextSmall(text, maxWidth)
@@ -107,9 +138,18 @@ func (p *LoadGraphPage) renderGraph(disp display.Display, s *stats.SystemStats,
 		return disp.Show()
 	}
 
-	// Text label on first content line
-	label := fmt.Sprintf("1m:%.2f 5m:%.2f 15m:%.2f", s.LoadAvg1, s.LoadAvg5, s.LoadAvg15)
+	// Text label on first content line, with the CPU count the load is
+	// spread over when there is room for it
+	load1, load5, load15 := p.averages(s)
+	label := sprintf("1m:%.2f 5m:%.2f 15m:%.2f", load1, load5, load15)
 	maxWidth := bounds.Dx() - 2*MarginLeft
+	cpus := fmt.Sprintf(" (%d CPUs)", p.numCPU)
+	if p.numCPU == 1 {
+		cpus = " (1 CPU)"
+	}
+	if MeasureText(label+cpus) <= maxWidth {
+		label += cpus
+	}
 	label = TruncateText(label, maxWidth)
 	c := LoadColor(s.LoadAvg1, p.numCPU)
 
@@ -148,11 +188,9 @@ func (p *LoadGraphPage) buildGraphImage(width, height int) *image.NRGBA {
 
 	// Determine Y-axis max: at least numCPU, or the max observed load
 	yMax := numCPU
-	samples := p.getSamples()
-	for _, v := range samples {
-		if v > yMax {
-			yMax = v
-		}
+	samples := p.history.Samples(timeseries.MetricLoad)
+	for _, sample := range samples {
+		yMax = max(yMax, sample.Value)
 	}
 	// Add 10% headroom
 	yMax *= 1.1
@@ -161,8 +199,8 @@ func (p *LoadGraphPage) buildGraphImage(width, height int) *image.NRGBA {
 	}
 
 	// Draw threshold lines (dotted)
-	yellowThresh := 0.7 * numCPU
-	redThresh := 1.0 * numCPU
+	yellowThresh := loadLevels.warn * numCPU
+	redThresh := loadLevels.crit * numCPU
 
 	yellowY := height - 1 - int(yellowThresh/yMax*float64(height-1))
 	redY := height - 1 - int(redThresh/yMax*float64(height-1))
@@ -182,39 +220,13 @@ func (p *LoadGraphPage) buildGraphImage(width, height int) *image.NRGBA {
 		return img
 	}
 
-	// Downsample or right-align
-	var bars []float64
-	if len(samples) > width {
-		// Average groups of samples
-		bars = make([]float64, width)
-		samplesPerBar := float64(len(samples)) / float64(width)
-		for col := 0; col < width; col++ {
-			startF := float64(col) * samplesPerBar
-			endF := float64(col+1) * samplesPerBar
-			start := int(startF)
-			end := int(endF)
-			if end > len(samples) {
-				end = len(samples)
-			}
-			if start >= end {
-				if start < len(samples) {
-					bars[col] = samples[start]
-				}
-				continue
-			}
-			sum := 0.0
-			for i := start; i < end; i++ {
-				sum += samples[i]
-			}
-			bars[col] = sum / float64(end-start)
-		}
-	} else {
-		// Right-align: newest samples at the right edge
-		bars = make([]float64, width)
-		offset := width - len(samples)
-		for i, v := range samples {
-			bars[offset+i] = v
-		}
+	// Average groups of samples when there are more than columns, with
+	// the newest at the right edge
+	bars := make([]float64, width)
+	samples = timeseries.Downsample(samples, width)
+	offset := width - len(samples)
+	for i, sample := range samples {
+		bars[offset+i] = sample.Value
 	}
 
 	// Draw bars
@@ -236,37 +248,9 @@ func (p *LoadGraphPage) buildGraphImage(width, height int) *image.NRGBA {
 			y := height - 1 - row
 			// Color based on the Y value (what load level this pixel represents)
 			pixelLoad := float64(row) / float64(height-1) * yMax
-			var clr color.NRGBA
-			perCore := pixelLoad / numCPU
-			switch {
-			case perCore > 1.0:
-				clr = ColorRed
-			case perCore >= 0.7:
-				clr = ColorYellow
-			default:
-				clr = ColorGreen
-			}
-			img.SetNRGBA(col, y, clr)
+			img.SetNRGBA(col, y, loadLevels.color(pixelLoad/numCPU))
 		}
 	}
 
 	return img
 }
-
-// getSamples returns the history samples in chronological order
-func (p *LoadGraphPage) getSamples() []float64 {
-	if p.count == 0 {
-		return nil
-	}
-
-	samples := make([]float64, p.count)
-	if p.count < loadHistorySize {
-		// Buffer hasn't wrapped yet: samples are 0..count-1
-		copy(samples, p.history[:p.count])
-	} else {
-		// Buffer has wrapped: oldest is at head, newest at head-1
-		n := copy(samples, p.history[p.head:])
-		copy(samples[n:], p.history[:p.head])
-	}
-	return samples
-}
@@ -1,10 +1,14 @@
 package renderer
 
 import (
+	"image"
 	"testing"
+	"time"
 
+	"github.com/ausil/i2c-display/internal/config"
 	"github.com/ausil/i2c-display/internal/display"
 	"github.com/ausil/i2c-display/internal/stats"
+	"github.com/ausil/i2c-display/internal/timeseries"
 )
 
 func TestLoadGraphPageTitle(t *testing.T) {
@@ -73,45 +77,29 @@ func TestLoadGraphPageRenderSmall(t *testing.T) {
 }
 
 func TestLoadGraphPageHistory(t *testing.T) {
-	disp := display.NewMockDisplay(128, 64)
-	page := NewLoadGraphPage(0)
-
-	// Render multiple times to accumulate history
-	for i := 0; i < 10; i++ {
-		testStats := &stats.SystemStats{
-			Hostname:  "testhost",
-			LoadAvg1:  float64(i) * 0.1,
-			LoadAvg5:  0.20,
-			LoadAvg15: 0.30,
-			NumCPU:    4,
-		}
-		if err := page.Render(disp, testStats); err != nil {
-			t.Fatalf("Render() iteration %d failed: %v", i, err)
+	s := &stats.SystemStats{Hostname: "testhost", LoadAvg1: 0.5, NumCPU: 4}
+	rend := NewRenderer(display.NewMockDisplay(128, 64), config.Default())
+
+	// The graph draws the renderer's history, which survives rebuilds
+	start := time.Now()
+	for i := range 10 {
+		rend.History().Record(start.Add(time.Duration(i)*time.Second), s)
+	}
+	for range 2 {
+		rend.BuildPages(s)
+		var page *LoadGraphPage
+		for _, p := range rend.pages {
+			if lp, ok := p.(*LoadGraphPage); ok {
+				page = lp
+			}
 		}
-	}
-
-	if page.count != 10 {
-		t.Errorf("expected count=10, got %d", page.count)
-	}
-
-	// Verify ring buffer wrapping: fill beyond capacity
-	for i := 0; i < loadHistorySize+5; i++ {
-		testStats := &stats.SystemStats{
-			Hostname:  "testhost",
-			LoadAvg1:  1.0,
-			LoadAvg5:  0.50,
-			LoadAvg15: 0.40,
-			NumCPU:    4,
+		if page == nil {
+			t.Fatal("expected a load graph page")
 		}
-		if err := page.Render(disp, testStats); err != nil {
-			t.Fatalf("Render() wrap iteration %d failed: %v", i, err)
+		if got := len(page.history.Values(timeseries.MetricLoad)); page.history != rend.History() || got != 10 {
+			t.Errorf("expected the page to graph the renderer's 10 samples, got %d", got)
 		}
 	}
-
-	// Count should be capped at loadHistorySize
-	if page.count != loadHistorySize {
-		t.Errorf("expected count=%d after wrapping, got %d", loadHistorySize, page.count)
-	}
 }
 
 func TestLoadGraphPageZeroLoad(t *testing.T) {
@@ -131,27 +119,79 @@ func TestLoadGraphPageZeroLoad(t *testing.T) {
 	}
 }
 
-func TestLoadGraphPageGetSamples(t *testing.T) {
+func TestLoadGraphPageBars(t *testing.T) {
 	page := NewLoadGraphPage(0)
+	page.numCPU = 1
+	start := time.Now()
+	add := func(n int) {
+		for i := range n {
+			page.history.Add(timeseries.MetricLoad, start.Add(time.Duration(i)*time.Second), 0.5)
+		}
+	}
+	lit := func(img *image.NRGBA, x int) bool {
+		return img.NRGBAAt(x, img.Bounds().Dy()-1).A != 0
+	}
 
-	// Empty history
-	samples := page.getSamples()
-	if len(samples) != 0 {
-		t.Errorf("expected 0 samples from empty history, got %d", len(samples))
+	// Fewer samples than columns are drawn at the right edge
+	add(5)
+	img := page.buildGraphImage(20, 10)
+	if lit(img, 14) || !lit(img, 15) || !lit(img, 19) {
+		t.Error("expected 5 samples in the rightmost 5 columns")
 	}
 
-	// Add a few samples
-	page.history[0] = 1.0
-	page.history[1] = 2.0
-	page.history[2] = 3.0
-	page.head = 3
-	page.count = 3
+	// More are averaged down to fill every column
+	add(40)
+	img = page.buildGraphImage(20, 10)
+	if !lit(img, 0) {
+		t.Error("expected 45 samples to fill all 20 columns")
+	}
+}
+
+func TestLoadGraphPagePerCore(t *testing.T) {
+	t.Cleanup(func() { SetThresholds(config.ThresholdsConfig{}) })
+	s := &stats.SystemStats{Hostname: "testhost", LoadAvg1: 2, LoadAvg5: 1, LoadAvg15: 0.5, NumCPU: 4}
 
-	samples = page.getSamples()
-	if len(samples) != 3 {
-		t.Fatalf("expected 3 samples, got %d", len(samples))
+	page := NewLoadGraphPage(0)
+	page.perCore = true
+	if got := page.TextLines(s, 20, 2)[1]; got != "Load 0.50 0.25 0.12" {
+		t.Errorf("TextLines() = %q, want the averages per CPU", got)
+	}
+
+	// Half the cores busy is green by default, red with lowered levels
+	if got := LoadColor(s.LoadAvg1, s.NumCPU); got != ColorGreen {
+		t.Errorf("LoadColor() = %v, want green below 0.7 per CPU", got)
+	}
+	SetThresholds(config.ThresholdsConfig{Load: config.LevelsConfig{Warn: 0.25, Crit: 0.4}})
+	if got := LoadColor(s.LoadAvg1, s.NumCPU); got != ColorRed {
+		t.Errorf("LoadColor() = %v, want red above the configured 0.4 per CPU", got)
+	}
+}
+
+func TestLoadGraphPageCPUCount(t *testing.T) {
+	s := &stats.SystemStats{Hostname: "testhost", LoadAvg1: 0.5, LoadAvg5: 0.4, LoadAvg15: 0.35, NumCPU: 4}
+	label := "1m:0.50 5m:0.40 15m:0.35"
+
+	// lit reports whether anything is drawn after the averages
+	lit := func(width int) bool {
+		disp := display.NewOffscreenDisplay(width, 128)
+		if err := NewLoadGraphPage(0).Render(disp, s); err != nil {
+			t.Fatalf("Render() failed: %v", err)
+		}
+		y := NewLayout(disp.GetBounds(), 0).ContentLines[0]
+		img := disp.Image()
+		for x := MarginLeft + MeasureText(label); x < width; x++ {
+			for dy := range FontHeight {
+				if c := img.NRGBAAt(x, y+dy); c.R|c.G|c.B != 0 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if !lit(320) {
+		t.Error("expected the CPU count after the averages on a wide display")
 	}
-	if samples[0] != 1.0 || samples[1] != 2.0 || samples[2] != 3.0 {
-		t.Errorf("unexpected samples: %v", samples)
+	if lit(200) {
+		t.Error("expected no CPU count where it does not fit")
 	}
 }
@@ -0,0 +1,24 @@
+package renderer
+
+import "github.com/ausil/i2c-display/internal/i18n"
+
+// locale translates the labels and writes the numbers of every page
+var locale = i18n.English
+
+// SetLocale sets the language of page labels and numbers. Like LoadFont it
+// applies to every renderer and is meant to be called before pages are
+// built. Page titles stay in English where they name pages, e.g. in
+// metrics and logs, and are translated only where drawn.
+func SetLocale(l *i18n.Locale) {
+	locale = l
+}
+
+// tr translates an English label
+func tr(label string) string {
+	return locale.T(label)
+}
+
+// sprintf translates format and formats it with localised numbers
+func sprintf(format string, args ...any) string {
+	return locale.Sprintf(format, args...)
+}
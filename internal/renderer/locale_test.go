@@ -0,0 +1,53 @@
+package renderer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/i18n"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestSetLocale(t *testing.T) {
+	de, err := i18n.Lookup("de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetLocale(de)
+	t.Cleanup(func() { SetLocale(i18n.English) })
+
+	s := &stats.SystemStats{
+		Hostname:    "testhost",
+		DiskUsed:    3 << 29, // 1.5G
+		DiskTotal:   32 << 30,
+		MemoryUsed:  1 << 29,
+		MemoryTotal: 4 << 30,
+		CPUTemp:     48.25,
+		Updates:     &stats.UpdateStatus{Count: 3},
+	}
+	page := NewSystemPage(0)
+	page.updates = true
+	got := page.TextLines(s, 40, 6)
+	want := []string{
+		"testhost",
+		"Datenträger 5% 1,5/32,0G",
+		"RAM 12% 0,5/4,0G",
+		"CPU 48,2°C",
+		"3 Updates",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("TextLines() = %q, want %q", got, want)
+	}
+	got[0] = want[0] // centred
+	if !slices.Equal(got, want) {
+		t.Errorf("TextLines() = %q, want %q", got, want)
+	}
+
+	// Page titles name pages in metrics and logs; only drawn titles change
+	if title := NewSystemPageForMetric(SystemMetricMemory, 0).Title(); title != "Memory" {
+		t.Errorf("Title() = %q, want it untranslated", title)
+	}
+	if header := NewPowerPage(0).TextLines(&stats.SystemStats{Throttled: new(stats.ThrottleFlags)}, 20, 4)[0]; header != centerText("Stromversorgung", 20) {
+		t.Errorf("power page header = %q, want the translated title", header)
+	}
+}
@@ -0,0 +1,95 @@
+package renderer
+
+import (
+	"image/color"
+
+	"golang.org/x/image/font"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+const (
+	// marqueeStep is how far scrolling text moves per refresh, in pixels
+	marqueeStep = 4
+	// marqueeGap separates the end of scrolling text from its start coming
+	// round again
+	marqueeGap = "   "
+)
+
+// marqueeWidget is a line of text in one colour that, when wider than its
+// space, scrolls left a few pixels per refresh and wraps round instead of
+// being cut off with "...". Text that fits is drawn in place, centred when
+// center is set.
+type marqueeWidget struct {
+	x, y    int
+	width   int // space for the text, cleared before every draw
+	scale   float64
+	center  bool
+	content func(s *stats.SystemStats) textSpan
+	last    textSpan
+	offset  int // pixels scrolled past the start of the text
+}
+
+func (w *marqueeWidget) draw(disp display.Display, s *stats.SystemStats, force bool) (bool, error) {
+	line := w.content(s)
+	textWidth := w.measure(line.text)
+	scrolling := textWidth > w.width
+	switch {
+	case line != w.last:
+		// New text starts again from its beginning
+		w.last = line
+		w.offset = 0
+	case !scrolling && !force:
+		return false, nil
+	case scrolling && !force:
+		w.offset = (w.offset + marqueeStep) % w.measure(line.text+marqueeGap)
+	}
+
+	cd := display.AsColorDisplay(disp)
+	if err := cd.FillRectColor(w.x, w.y, w.width, ScaledTextHeight(w.scale), color.Black); err != nil {
+		return false, err
+	}
+
+	text, offset := line.text, w.offset
+	if scrolling {
+		// Follow the text with its start so the gap scrolls in after it
+		text += marqueeGap + line.text
+	} else if w.center {
+		offset = -(w.width - textWidth) / 2
+	}
+	raster := textCache.get(w.face(), text, line.c)
+	for _, p := range raster.lit {
+		if p.X < offset || p.X >= offset+w.width {
+			continue
+		}
+		if err := cd.DrawPixelColor(w.x+p.X-offset, w.y+p.Y, line.c); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// face returns the font for the widget's scale
+func (w *marqueeWidget) face() font.Face {
+	return faceForScale(w.scale)
+}
+
+// measure returns the width of text in the widget's font
+func (w *marqueeWidget) measure(text string) int {
+	return font.MeasureString(w.face(), text).Ceil()
+}
+
+// headerMarquee returns a widget drawing the hostname header, scrolling it
+// when it is too long for the display
+func headerMarquee(layout *Layout) *marqueeWidget {
+	return &marqueeWidget{
+		y:      layout.HeaderY,
+		width:  layout.Width,
+		scale:  layout.TextScale,
+		center: true,
+		content: func(s *stats.SystemStats) textSpan {
+			return textSpan{text: s.Hostname, c: ColorGreen}
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// litColumns returns the columns of disp with a lit pixel in rows y to y+13
+func litColumns(disp *display.MockDisplay, y int) []int {
+	var cols []int
+	for x := range disp.GetBounds().Dx() {
+		for dy := range 13 {
+			if disp.GetPixel(x, y+dy) {
+				cols = append(cols, x)
+				break
+			}
+		}
+	}
+	return cols
+}
+
+func TestMarqueeWidget(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	text := "eth0: fd12:3456:789a:1:2:3:4:5"
+	w := &marqueeWidget{
+		x:     10,
+		y:     20,
+		width: 60,
+		content: func(s *stats.SystemStats) textSpan {
+			return textSpan{text: text, c: ColorGreen}
+		},
+	}
+
+	if _, err := w.draw(disp, nil, true); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	cols := litColumns(disp, 20)
+	if len(cols) == 0 || cols[0] < 10 || cols[len(cols)-1] >= 70 {
+		t.Fatalf("expected the text clipped to columns 10-69, got %v", cols)
+	}
+
+	// Each refresh moves the text along, wrapping round after the gap
+	period := w.measure(text + marqueeGap)
+	for i := 1; i <= period/marqueeStep+1; i++ {
+		changed, err := w.draw(disp, nil, false)
+		if err != nil || !changed {
+			t.Fatalf("refresh %d: expected a redraw, got changed=%v err=%v", i, changed, err)
+		}
+		if want := i * marqueeStep % period; w.offset != want {
+			t.Fatalf("refresh %d: offset = %d, want %d", i, w.offset, want)
+		}
+	}
+	if cols := litColumns(disp, 20); cols[len(cols)-1] >= 70 {
+		t.Errorf("expected scrolled text kept within its width, got %v", cols)
+	}
+
+	// New text starts from its beginning; text that fits stays put
+	text = "eth0: 10.0.0.2"
+	if changed, _ := w.draw(disp, nil, false); !changed || w.offset != 0 {
+		t.Errorf("expected new text drawn from the start, got changed=%v offset=%d", changed, w.offset)
+	}
+	text = "lo"
+	w.draw(disp, nil, false)
+	if changed, _ := w.draw(disp, nil, false); changed {
+		t.Error("expected text that fits not to be redrawn")
+	}
+}
+
+func TestHeaderMarqueeCentersShortHostnames(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	layout := NewLayout(disp.GetBounds(), 0)
+	w := headerMarquee(layout)
+	if _, err := w.draw(disp, &stats.SystemStats{Hostname: "pi"}, true); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	cols := litColumns(disp, layout.HeaderY)
+	if len(cols) == 0 {
+		t.Fatal("expected the hostname drawn")
+	}
+	if left, right := cols[0], 127-cols[len(cols)-1]; left-right > 7 || right-left > 7 {
+		t.Errorf("expected the hostname centred, got columns %d-%d", cols[0], cols[len(cols)-1])
+	}
+}
+
+func TestNetworkPageMarquee(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.Marquee = true
+	disp := display.NewMockDisplay(128, 64)
+	r := NewRenderer(disp, cfg)
+	s := &stats.SystemStats{
+		Hostname:   "testhost",
+		Interfaces: []stats.NetInterface{{Name: "wlan0", IPv6Addrs: []string{"2001:db8:85a3::8a2e:370:7334"}}},
+	}
+	r.BuildPages(s)
+
+	idx := -1
+	for i := range r.PageCount() {
+		if r.PageType(i) == config.PageNetwork {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		t.Fatal("expected a network page")
+	}
+	if err := r.RefreshPage(idx, s); err != nil {
+		t.Fatalf("RefreshPage failed: %v", err)
+	}
+
+	// The address is too long for the display, so it moves on every refresh
+	for range 2 {
+		disp.ClearCalls()
+		if err := r.RefreshPage(idx, s); err != nil {
+			t.Fatalf("RefreshPage failed: %v", err)
+		}
+		if countCalls(disp, "Clear") != 0 || countCalls(disp, "Show") != 1 {
+			t.Errorf("expected the scrolling line redrawn in place, got %v", disp.GetCalls())
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// Message font sizes
+const (
+	MessageSizeSmall  = "small"  // compact 5x7 font
+	MessageSizeNormal = "normal" // standard 7x13 font
+	MessageSizeLarge  = "large"  // 7x13 font enlarged 2x
+)
+
+// messageColors are the colour names accepted by ParseColor
+var messageColors = map[string]color.NRGBA{
+	"white":   {R: 255, G: 255, B: 255, A: 255},
+	"red":     ColorRed,
+	"green":   ColorGreen,
+	"yellow":  ColorYellow,
+	"blue":    {R: 0, G: 0, B: 255, A: 255},
+	"cyan":    {R: 0, G: 255, B: 255, A: 255},
+	"magenta": {R: 255, G: 0, B: 255, A: 255},
+	"orange":  {R: 255, G: 165, B: 0, A: 255},
+	"black":   {A: 255},
+}
+
+// ParseColor parses a colour name (white, red, green, yellow, blue, cyan,
+// magenta, orange, black) or a "#rrggbb" hex value
+func ParseColor(s string) (color.NRGBA, error) {
+	if c, ok := messageColors[strings.ToLower(s)]; ok {
+		return c, nil
+	}
+	var r, g, b uint8
+	if len(s) == 7 {
+		if n, err := fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b); err == nil && n == 3 {
+			return color.NRGBA{R: r, G: g, B: b, A: 255}, nil
+		}
+	}
+	return color.NRGBA{}, fmt.Errorf("invalid colour %q, expected a name or #rrggbb", s)
+}
+
+// MessagePage shows a pushed text message, word-wrapped and centred. It is
+// rendered in place of normal rotation until the message expires.
+type MessagePage struct {
+	text string
+	size string
+	c    color.NRGBA
+}
+
+// NewMessagePage creates a message page. An empty size means normal.
+func NewMessagePage(text, size string, c color.NRGBA) (*MessagePage, error) {
+	switch size {
+	case "":
+		size = MessageSizeNormal
+	case MessageSizeSmall, MessageSizeNormal, MessageSizeLarge:
+	default:
+		return nil, fmt.Errorf("invalid font size %q, expected small, normal or large", size)
+	}
+	return &MessagePage{text: text, size: size, c: c}, nil
+}
+
+// Title returns the page title
+func (p *MessagePage) Title() string {
+	return "Message"
+}
+
+// Text returns the message text
+func (p *MessagePage) Text() string {
+	return p.text
+}
+
+// Render draws the message as large as its font size allows, centred on
+// the display. Text that does not fit is truncated with "...".
+func (p *MessagePage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	maxWidth := bounds.Dx() - MarginLeft - MarginRight
+
+	scale, factor := 1.0, 1
+	measure, truncate := MeasureText, TruncateText
+	switch p.size {
+	case MessageSizeSmall:
+		scale = 0.5
+		measure, truncate = MeasureTextSmall, TruncateTextSmall
+	case MessageSizeLarge:
+		factor = 2
+	}
+	maxWidth /= factor
+
+	lineHeight := ScaledTextHeight(scale)*factor + 1
+	lines := wrapText(p.text, maxWidth, measure)
+	if maxLines := max(bounds.Dy()/lineHeight, 1); len(lines) > maxLines {
+		// The rest of the text runs on from the last visible line
+		rest := strings.Join(lines[maxLines-1:], " ")
+		lines = append(lines[:maxLines-1], truncate(rest, maxWidth))
+	}
+
+	y := (bounds.Dy() - len(lines)*lineHeight) / 2
+	for _, line := range lines {
+		line = truncate(line, maxWidth)
+		var err error
+		if factor > 1 {
+			err = drawTextCenteredEnlarged(disp, y, line, p.c, factor)
+		} else {
+			err = DrawTextCenteredColorScaled(disp, y, line, p.c, scale)
+		}
+		if err != nil {
+			return err
+		}
+		y += lineHeight
+	}
+	return disp.Show()
+}
+
+// TextLines word-wraps the message to the display width and centres it.
+// The font size and colour do not apply to character displays.
+func (p *MessagePage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	lines := wrapText(p.text, cols, utf8.RuneCountInString)
+	if len(lines) > rows {
+		// Text past the last row is dropped
+		lines = lines[:rows]
+	}
+	pad := make([]string, (rows-len(lines))/2, rows)
+	for _, line := range lines {
+		pad = append(pad, centerText(line, cols))
+	}
+	return pad
+}
+
+// update leaves the message as drawn; it never changes while shown
+func (p *MessagePage) update(disp display.Display, s *stats.SystemStats, _ time.Time) (bool, error) {
+	return false, nil
+}
+
+// wrapText splits text into lines no wider than maxWidth, breaking at
+// spaces and at explicit newlines. Words wider than maxWidth get a line of
+// their own and are left for the caller to truncate, except in Chinese,
+// Japanese and Korean, which are broken between characters.
+func wrapText(text string, maxWidth int, measure func(string) int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, field := range strings.Fields(paragraph) {
+			for _, word := range breakWord(field, maxWidth, measure) {
+				if line != "" && measure(line+" "+word) > maxWidth {
+					lines = append(lines, line)
+					line = ""
+				}
+				if line == "" {
+					line = word
+				} else {
+					line += " " + word
+				}
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// breakWord splits a word wider than maxWidth into parts that fit when it
+// is in a script written without spaces between words; other words are
+// kept whole
+func breakWord(word string, maxWidth int, measure func(string) int) []string {
+	if measure(word) <= maxWidth || !strings.ContainsFunc(word, isCJK) {
+		return []string{word}
+	}
+	var parts []string
+	part := ""
+	for _, r := range word {
+		if part != "" && measure(part+string(r)) > maxWidth {
+			parts = append(parts, part)
+			part = ""
+		}
+		part += string(r)
+	}
+	return append(parts, part)
+}
+
+// isCJK reports whether r is a Chinese, Japanese or Korean character
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
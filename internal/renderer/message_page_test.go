@@ -0,0 +1,79 @@
+package renderer
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    color.NRGBA
+		wantErr bool
+	}{
+		{"red", ColorRed, false},
+		{"Yellow", ColorYellow, false},
+		{"#00ff80", color.NRGBA{G: 255, B: 128, A: 255}, false},
+		{"#0f8", color.NRGBA{}, true},
+		{"#gg0000", color.NRGBA{}, true},
+		{"purple-ish", color.NRGBA{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseColor(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseColor(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseColor(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	// 7 px per character with the standard font
+	lines := wrapText("backup finished\nall ok", 10*7, MeasureText)
+	want := []string{"backup", "finished", "all ok"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("wrapText() = %q, want %q", lines, want)
+	}
+
+	// Overlong words keep a line of their own
+	lines = wrapText("a supercalifragilistic b", 10*7, MeasureText)
+	if len(lines) != 3 || lines[1] != "supercalifragilistic" {
+		t.Errorf("expected the long word on its own line, got %q", lines)
+	}
+}
+
+func TestMessagePage(t *testing.T) {
+	if _, err := NewMessagePage("hi", "huge", ColorRed); err == nil {
+		t.Error("expected an error for an unknown font size")
+	}
+
+	for _, size := range []string{"", MessageSizeSmall, MessageSizeNormal, MessageSizeLarge} {
+		disp := display.NewMockDisplay(128, 32)
+		page, err := NewMessagePage("Deploy finished on all twelve nodes without errors", size, ColorGreen)
+		if err != nil {
+			t.Fatalf("NewMessagePage(%q) failed: %v", size, err)
+		}
+		if page.Title() != "Message" {
+			t.Errorf("expected title 'Message', got %q", page.Title())
+		}
+		if err := page.Render(disp, &stats.SystemStats{}); err != nil {
+			t.Fatalf("Render() with size %q failed: %v", size, err)
+		}
+		if countCalls(disp, "Show") != 1 {
+			t.Errorf("size %q: expected one flush, got %v", size, disp.GetCalls())
+		}
+
+		// Nothing changes while the message is shown
+		if changed, err := page.update(disp, nil, time.Now()); err != nil || changed {
+			t.Errorf("size %q: expected update to leave the message alone, got %v, %v", size, changed, err)
+		}
+	}
+}
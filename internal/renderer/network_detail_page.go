@@ -0,0 +1,163 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// NetworkDetailPage shows one interface in full: whether its address came
+// from DHCP or is static, the address, the default gateway and the name
+// servers
+type NetworkDetailPage struct {
+	idx       int // interface index in SystemStats.Interfaces
+	total     int
+	lines     int                      // configured line count (0=auto, 2=default, 4=compact)
+	intervals map[string]time.Duration // per-source widget refresh intervals
+	marquee   bool                     // scroll rows too wide for the display
+	ipv6      ipv6Display
+	widgets   widgetSet
+}
+
+// NewNetworkDetailPage creates a detail page for interface idx of total
+func NewNetworkDetailPage(idx, total, lines int) *NetworkDetailPage {
+	return &NetworkDetailPage{idx: idx, total: total, lines: lines}
+}
+
+// Title returns the page title
+func (p *NetworkDetailPage) Title() string {
+	return fmt.Sprintf("Network detail %d/%d", p.idx+1, p.total)
+}
+
+// SetRefreshIntervals sets how often the rows are refreshed, keyed by data
+// source (see config.PagesConfig.RefreshIntervals)
+func (p *NetworkDetailPage) SetRefreshIntervals(intervals map[string]time.Duration) {
+	p.intervals = intervals
+}
+
+// SetMarquee sets whether the hostname and rows scroll when too wide for the
+// display instead of being truncated
+func (p *NetworkDetailPage) SetMarquee(enabled bool) {
+	p.marquee = enabled
+}
+
+// SetIPv6Display sets how IPv6 addresses are shown: format is one of
+// config.IPv6Formats and labels prefixes them with their scope
+func (p *NetworkDetailPage) SetIPv6Display(format string, labels bool) {
+	p.ipv6 = ipv6Display{format: format, labels: labels}
+}
+
+// Render draws the interface details under the hostname header
+func (p *NetworkDetailPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	maxWidth := bounds.Dx() - 2*MarginLeft
+
+	if err := drawPageHeader(disp, layout, s.Hostname); err != nil {
+		return err
+	}
+
+	// One widget per row, so only changed details are redrawn. Scrolling
+	// rows move on every refresh.
+	p.widgets.reset()
+	if layout.ShowHeader && p.marquee {
+		p.widgets.add(headerMarquee(layout), 0)
+	}
+	interval := p.intervals[config.SourceNetwork]
+	rows := len(layout.ContentLines)
+	for row, y := range layout.ContentLines {
+		if p.marquee {
+			p.widgets.add(&marqueeWidget{
+				x:     MarginLeft,
+				y:     y,
+				width: maxWidth,
+				scale: layout.TextScale,
+				content: func(s *stats.SystemStats) textSpan {
+					details := p.details(s, rows)
+					if row >= len(details) {
+						return textSpan{}
+					}
+					return textSpan{text: details[row], c: ColorGreen}
+				},
+			}, 0)
+			continue
+		}
+		p.widgets.add(&lineWidget{
+			x:     MarginLeft,
+			y:     y,
+			scale: layout.TextScale,
+			content: func(s *stats.SystemStats) []textSpan {
+				details := p.details(s, rows)
+				if row >= len(details) {
+					return nil
+				}
+				if layout.TextScale > 0 && layout.TextScale < 1 {
+					return span(TruncateTextSmall(details[row], maxWidth), ColorGreen)
+				}
+				return span(TruncateText(details[row], maxWidth), ColorGreen)
+			},
+		}, interval)
+	}
+	if err := p.widgets.render(disp, s, time.Now()); err != nil {
+		return err
+	}
+
+	return disp.Show()
+}
+
+// update redraws the rows whose details changed
+func (p *NetworkDetailPage) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	return p.widgets.update(disp, s, now)
+}
+
+// details returns the page's rows for a display with room for rows of them:
+// the interface and how it is addressed, then its address, gateway and name
+// servers. With fewer than four rows the address is left out, as the network
+// pages show it already.
+func (p *NetworkDetailPage) details(s *stats.SystemStats, rows int) []string {
+	if p.idx >= len(s.Interfaces) {
+		return nil
+	}
+	iface := s.Interfaces[p.idx]
+
+	name := iface.Name
+	switch iface.Addressing {
+	case stats.AddressingDHCP:
+		name += ": DHCP"
+	case stats.AddressingStatic:
+		name += ": static"
+	}
+	addr := "no addr"
+	if len(iface.IPv4Addrs) > 0 {
+		addr = iface.IPv4Addrs[0]
+	} else if len(iface.IPv6Addrs) > 0 {
+		addr = p.ipv6.address(iface.IPv6Addrs[0])
+	}
+	gateway := iface.Gateway
+	if gateway == "" {
+		gateway = "none"
+	}
+	dns := strings.Join(s.DNSServers, " ")
+	if dns == "" {
+		dns = "none"
+	}
+
+	details := []string{name, "IP " + addr, "GW " + gateway, "DNS " + dns}
+	if rows < len(details) {
+		details = append(details[:1], details[2:]...)
+	}
+	return details
+}
+
+// TextLines shows the details one per row
+func (p *NetworkDetailPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	return p.details(s, rows)
+}
@@ -0,0 +1,75 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestNetworkDetailPageDetails(t *testing.T) {
+	s := &stats.SystemStats{
+		Hostname: "pi",
+		Interfaces: []stats.NetInterface{
+			{Name: "eth0", IPv4Addrs: []string{"192.168.1.20"}, Gateway: "192.168.1.1", Addressing: stats.AddressingDHCP},
+			{Name: "wlan0", IPv6Addrs: []string{"fd00::7"}},
+		},
+		DNSServers: []string{"1.1.1.1", "9.9.9.9"},
+	}
+
+	got := NewNetworkDetailPage(0, 2, 0).details(s, 4)
+	want := []string{"eth0: DHCP", "IP 192.168.1.20", "GW 192.168.1.1", "DNS 1.1.1.1 9.9.9.9"}
+	if len(got) != len(want) {
+		t.Fatalf("details() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("row %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	// Short displays leave out the address shown on the network pages
+	got = NewNetworkDetailPage(1, 2, 0).details(s, 2)
+	if len(got) != 3 || got[0] != "wlan0" || got[1] != "GW none" {
+		t.Errorf("details() = %q, want the name then the gateway", got)
+	}
+
+	// An interface that has gone away shows nothing
+	if got := NewNetworkDetailPage(2, 2, 0).details(s, 4); got != nil {
+		t.Errorf("details() = %q, want nil", got)
+	}
+
+	if err := NewNetworkDetailPage(0, 2, 0).Render(display.NewOffscreenDisplay(128, 64), s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+}
+
+func TestBuildPagesNetworkDetail(t *testing.T) {
+	cfg := config.Default()
+	s := &stats.SystemStats{
+		Hostname:   "pi",
+		Interfaces: []stats.NetInterface{{Name: "eth0", IPv4Addrs: []string{"192.168.1.20"}}, {Name: "wlan0", IPv4Addrs: []string{"10.0.0.7"}}},
+	}
+	count := func(r *Renderer) int {
+		n := 0
+		for i := 0; i < r.PageCount(); i++ {
+			if r.PageType(i) == config.PageNetworkDetail {
+				n++
+			}
+		}
+		return n
+	}
+
+	r := NewRenderer(display.NewOffscreenDisplay(128, 64), cfg)
+	r.BuildPages(s)
+	if n := count(r); n != 0 {
+		t.Errorf("expected no detail pages by default, got %d", n)
+	}
+
+	cfg.Network.DetailPage = true
+	r.BuildPages(s)
+	if n := count(r); n != 2 {
+		t.Errorf("expected a detail page per interface, got %d", n)
+	}
+}
@@ -2,7 +2,9 @@ package renderer
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/ausil/i2c-display/internal/config"
 	"github.com/ausil/i2c-display/internal/display"
 	"github.com/ausil/i2c-display/internal/stats"
 )
@@ -14,7 +16,11 @@ type NetworkPage struct {
 	totalPages        int
 	interfaceStartIdx int
 	interfaceEndIdx   int
-	lines             int // configured line count (0=auto, 2=default, 4=compact)
+	lines             int                      // configured line count (0=auto, 2=default, 4=compact)
+	intervals         map[string]time.Duration // per-source widget refresh intervals
+	marquee           bool                     // scroll lines too wide for the display
+	ipv6              ipv6Display
+	widgets           widgetSet
 }
 
 // NewNetworkPage creates a new network page
@@ -42,9 +48,25 @@ func (p *NetworkPage) Title() string {
 	return fmt.Sprintf("Network %d/%d", p.pageNum, p.totalPages)
 }
 
+// SetRefreshIntervals sets how often interface lines are refreshed, keyed by
+// data source (see config.PagesConfig.RefreshIntervals)
+func (p *NetworkPage) SetRefreshIntervals(intervals map[string]time.Duration) {
+	p.intervals = intervals
+}
+
+// SetMarquee sets whether the hostname and interface lines scroll when too
+// wide for the display instead of being truncated
+func (p *NetworkPage) SetMarquee(enabled bool) {
+	p.marquee = enabled
+}
+
+// SetIPv6Display sets how IPv6 addresses are shown: format is one of
+// config.IPv6Formats and labels prefixes them with their scope
+func (p *NetworkPage) SetIPv6Display(format string, labels bool) {
+	p.ipv6 = ipv6Display{format: format, labels: labels}
+}
+
 // Render draws the network page
-//
-//nolint:gocyclo // rendering logic naturally has many conditional branches for different display sizes
 func (p *NetworkPage) Render(disp display.Display, s *stats.SystemStats) error {
 	// Clear display
 	if err := disp.Clear(); err != nil {
@@ -56,8 +78,12 @@ func (p *NetworkPage) Render(disp display.Display, s *stats.SystemStats) error {
 	layout := NewLayout(bounds, p.lines)
 	maxWidth := bounds.Dx() - 2*MarginLeft
 
-	// Optional: Hostname header (green on colour displays)
-	if layout.ShowHeader {
+	// Optional: Hostname header (green on colour displays); a scrolling
+	// header is a widget
+	p.widgets.reset()
+	if layout.ShowHeader && p.marquee {
+		p.widgets.add(headerMarquee(layout), 0)
+	} else if layout.ShowHeader {
 		if err := DrawTextCenteredColorScaled(disp, layout.HeaderY, s.Hostname, ColorGreen, layout.TextScale); err != nil {
 			return err
 		}
@@ -70,53 +96,45 @@ func (p *NetworkPage) Render(disp display.Display, s *stats.SystemStats) error {
 		}
 	}
 
-	// Render interfaces for this page
-	interfaceCount := 0
-
-	for i := p.interfaceStartIdx; i < p.interfaceEndIdx && i < len(s.Interfaces); i++ {
-		if interfaceCount >= len(layout.ContentLines) {
-			break
-		}
-
-		iface := s.Interfaces[i]
-		y := layout.ContentLines[interfaceCount]
-
-		// Determine which address to show
-		var addr string
-		if len(iface.IPv4Addrs) > 0 {
-			addr = iface.IPv4Addrs[0]
-		} else if len(iface.IPv6Addrs) > 0 {
-			addr = iface.IPv6Addrs[0]
-		} else {
-			addr = "no addr"
-		}
-
-		// Format based on display size
-		var text string
-		if layout.Height <= 32 {
-			// Compact format for small displays: "name:IP"
-			// Use shorter separator to save space
-			text = fmt.Sprintf("%s:%s", iface.Name, addr)
-		} else {
-			// Standard format: "interface: IP"
-			text = fmt.Sprintf("%s: %s", iface.Name, addr)
+	// One widget per interface line, so changed addresses are redrawn alone.
+	// Scrolling lines move on every refresh.
+	interval := p.intervals[config.SourceNetwork]
+	for n := 0; n < p.interfaceEndIdx-p.interfaceStartIdx && n < len(layout.ContentLines); n++ {
+		idx := p.interfaceStartIdx + n
+		if p.marquee {
+			p.widgets.add(&marqueeWidget{
+				x:     MarginLeft,
+				y:     layout.ContentLines[n],
+				width: maxWidth,
+				scale: layout.TextScale,
+				content: func(s *stats.SystemStats) textSpan {
+					if idx >= len(s.Interfaces) {
+						return textSpan{}
+					}
+					return textSpan{text: p.interfaceText(s.Interfaces[idx], layout), c: ColorGreen}
+				},
+			}, 0)
+			continue
 		}
-
-		if layout.TextScale > 0 && layout.TextScale < 1 {
-			text = TruncateTextSmall(text, maxWidth)
-		} else {
-			text = TruncateText(text, maxWidth)
-		}
-		if err := DrawTextColorScaled(disp, MarginLeft, y, text, ColorGreen, layout.TextScale); err != nil {
-			return err
-		}
-
-		interfaceCount++
+		p.widgets.add(&lineWidget{
+			x:     MarginLeft,
+			y:     layout.ContentLines[n],
+			scale: layout.TextScale,
+			content: func(s *stats.SystemStats) []textSpan {
+				if idx >= len(s.Interfaces) {
+					return nil
+				}
+				return span(p.interfaceLine(s.Interfaces[idx], layout, maxWidth), ColorGreen)
+			},
+		}, interval)
+	}
+	if err := p.widgets.render(disp, s, time.Now()); err != nil {
+		return err
 	}
 
 	// Footer: Page indicator (if space available and multiple pages)
 	if p.totalPages > 1 && layout.FooterY >= 0 {
-		pageIndicator := fmt.Sprintf("Page %d/%d", p.pageNum, p.totalPages)
+		pageIndicator := sprintf("Page %d/%d", p.pageNum, p.totalPages)
 		indicatorWidth := MeasureText(pageIndicator)
 		x := bounds.Dx() - indicatorWidth - MarginRight
 		if err := DrawTextColorScaled(disp, x, layout.FooterY, pageIndicator, ColorGreen, layout.TextScale); err != nil {
@@ -127,3 +145,57 @@ func (p *NetworkPage) Render(disp display.Display, s *stats.SystemStats) error {
 	// Show the display
 	return disp.Show()
 }
+
+// update redraws the interface lines that are due
+func (p *NetworkPage) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	return p.widgets.update(disp, s, now)
+}
+
+// interfaceLine formats an interface and its first address for the layout,
+// truncated to maxWidth
+func (p *NetworkPage) interfaceLine(iface stats.NetInterface, layout *Layout, maxWidth int) string {
+	text := p.interfaceText(iface, layout)
+	if layout.TextScale > 0 && layout.TextScale < 1 {
+		return TruncateTextSmall(text, maxWidth)
+	}
+	return TruncateText(text, maxWidth)
+}
+
+// interfaceText formats an interface and its first address for the layout
+func (p *NetworkPage) interfaceText(iface stats.NetInterface, layout *Layout) string {
+	// Determine which address to show
+	var addr string
+	if len(iface.IPv4Addrs) > 0 {
+		addr = iface.IPv4Addrs[0]
+	} else if len(iface.IPv6Addrs) > 0 {
+		addr = p.ipv6.address(iface.IPv6Addrs[0])
+	} else {
+		addr = "no addr"
+	}
+
+	// Format based on display size
+	if layout.Height <= 32 {
+		// Compact format for small displays: "name:IP"
+		// Use shorter separator to save space
+		return fmt.Sprintf("%s:%s", iface.Name, addr)
+	}
+	// Standard format: "interface: IP"
+	return fmt.Sprintf("%s: %s", iface.Name, addr)
+}
+
+// TextLines shows each interface on the page as its name on one row and
+// its first address on the next
+func (p *NetworkPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	var lines []string
+	for idx := p.interfaceStartIdx; idx < p.interfaceEndIdx && idx < len(s.Interfaces); idx++ {
+		iface := s.Interfaces[idx]
+		addr := "no addr"
+		if len(iface.IPv4Addrs) > 0 {
+			addr = iface.IPv4Addrs[0]
+		} else if len(iface.IPv6Addrs) > 0 {
+			addr = p.ipv6.address(iface.IPv6Addrs[0])
+		}
+		lines = append(lines, iface.Name, addr)
+	}
+	return lines
+}
@@ -1,6 +1,9 @@
 package renderer
 
 import (
+	"strings"
+	"unicode/utf8"
+
 	"github.com/ausil/i2c-display/internal/display"
 	"github.com/ausil/i2c-display/internal/stats"
 )
@@ -13,3 +16,47 @@ type Page interface {
 	// Title returns a short title for the page
 	Title() string
 }
+
+// TextPage is implemented by pages that can be shown on character displays.
+// On a display whose capabilities report a text grid the renderer asks the
+// page for lines of text instead of calling Render. Pages without a text
+// form are left out of the rotation there.
+type TextPage interface {
+	Page
+
+	// TextLines returns the page as at most rows lines of cols characters.
+	// Longer lines are cut by the display.
+	TextLines(stats *stats.SystemStats, cols, rows int) []string
+}
+
+// centerText pads text with leading spaces to centre it in cols characters
+func centerText(text string, cols int) string {
+	n := utf8.RuneCountInString(text)
+	if n >= cols {
+		return text
+	}
+	return strings.Repeat(" ", (cols-n)/2) + text
+}
+
+// fitText returns long when it fits in cols characters, otherwise short
+func fitText(long, short string, cols int) string {
+	if utf8.RuneCountInString(long) <= cols {
+		return long
+	}
+	return short
+}
+
+// drawPageHeader draws the hostname header and separator line when the layout has room for them.
+func drawPageHeader(disp display.Display, layout *Layout, hostname string) error {
+	if layout.ShowHeader {
+		if err := DrawTextCenteredColorScaled(disp, layout.HeaderY, hostname, ColorGreen, layout.TextScale); err != nil {
+			return err
+		}
+	}
+	if layout.ShowSeparator {
+		if err := DrawLine(disp, layout.SeparatorY); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/plugin"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// PluginPage renders a Starlark page script. If the script fails, its
+// error is shown in place of the page so the rest of the rotation carries on.
+type PluginPage struct {
+	script *plugin.Script
+}
+
+// NewPluginPage creates a page for a loaded script
+func NewPluginPage(script *plugin.Script) *PluginPage {
+	return &PluginPage{script: script}
+}
+
+// Title returns the script's title
+func (p *PluginPage) Title() string {
+	return p.script.Title()
+}
+
+// Render runs the script on a cleared display
+func (p *PluginPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+	if err := p.script.Render(pluginCanvas{disp}, s); err != nil {
+		if err := p.renderError(disp, err); err != nil {
+			return err
+		}
+	}
+	return disp.Show()
+}
+
+// renderError replaces whatever the script drew with the title and the
+// error, wrapped in the small font
+func (p *PluginPage) renderError(disp display.Display, scriptErr error) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+	bounds := disp.GetBounds()
+	maxWidth := bounds.Dx() - MarginLeft - MarginRight
+	lineHeight := ScaledTextHeight(0.5) + 1
+
+	y := 0
+	lines := append([]string{p.Title() + ":"}, wrapText(scriptErr.Error(), maxWidth, MeasureTextSmall)...)
+	for _, line := range lines {
+		if y+lineHeight > bounds.Dy() {
+			break
+		}
+		if err := DrawTextColorScaled(disp, MarginLeft, y, TruncateTextSmall(line, maxWidth), ColorRed, 0.5); err != nil {
+			return err
+		}
+		y += lineHeight
+	}
+	return nil
+}
+
+// pluginCanvas adapts a display to the drawing API scripts use
+type pluginCanvas struct {
+	disp display.Display
+}
+
+func (c pluginCanvas) Size() (int, int) {
+	bounds := c.disp.GetBounds()
+	return bounds.Dx(), bounds.Dy()
+}
+
+func (c pluginCanvas) LineHeight(small bool) int {
+	return ScaledTextHeight(fontScale(small))
+}
+
+func (c pluginCanvas) Measure(text string, small bool) int {
+	if small {
+		return MeasureTextSmall(text)
+	}
+	return MeasureText(text)
+}
+
+func (c pluginCanvas) Text(x, y int, text string, col color.NRGBA, small bool) error {
+	return DrawTextColorScaled(c.disp, x, y, text, col, fontScale(small))
+}
+
+func (c pluginCanvas) CenteredText(y int, text string, col color.NRGBA, small bool) error {
+	return DrawTextCenteredColorScaled(c.disp, y, text, col, fontScale(small))
+}
+
+func (c pluginCanvas) Rect(x, y, width, height int, col color.NRGBA, fill bool) error {
+	if fill {
+		return c.fill(image.Rect(x, y, x+width, y+height), col)
+	}
+	// Outline as four one-pixel edges
+	for _, edge := range []image.Rectangle{
+		image.Rect(x, y, x+width, y+1),
+		image.Rect(x, y+height-1, x+width, y+height),
+		image.Rect(x, y, x+1, y+height),
+		image.Rect(x+width-1, y, x+width, y+height),
+	} {
+		if err := c.fill(edge, col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fill fills r clipped to the display, so oversized script rectangles
+// cost no more than a full-screen fill
+func (c pluginCanvas) fill(r image.Rectangle, col color.NRGBA) error {
+	r = r.Intersect(c.disp.GetBounds())
+	if r.Empty() {
+		return nil
+	}
+	return display.AsColorDisplay(c.disp).FillRectColor(r.Min.X, r.Min.Y, r.Dx(), r.Dy(), col)
+}
+
+func (c pluginCanvas) Pixel(x, y int, col color.NRGBA) error {
+	return display.AsColorDisplay(c.disp).DrawPixelColor(x, y, col)
+}
+
+func (c pluginCanvas) ParseColor(s string) (color.NRGBA, error) {
+	return ParseColor(s)
+}
+
+// fontScale maps the scripts' small flag to a text scale
+func fontScale(small bool) float64 {
+	if small {
+		return 0.5
+	}
+	return 1
+}
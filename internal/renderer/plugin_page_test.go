@@ -0,0 +1,75 @@
+package renderer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/plugin"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func loadTestScript(t *testing.T, name, src string) *plugin.Script {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	script, err := plugin.Load(path, logger.NewDefault())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return script
+}
+
+func TestPluginPageDrawsScript(t *testing.T) {
+	script := loadTestScript(t, "box.star", `
+def render(screen, stats):
+    screen.rect(-10, -10, 1000, 1000, fill=True)
+    screen.rect(0, 0, screen.width, screen.height, color="black")
+`)
+	disp := display.NewMockDisplay(128, 64)
+	if err := NewPluginPage(script).Render(disp, &stats.SystemStats{}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !disp.GetPixel(10, 10) {
+		t.Error("expected the filled area lit")
+	}
+	if disp.GetPixel(0, 0) || disp.GetPixel(127, 63) {
+		t.Error("expected the outline drawn in black at the display edges")
+	}
+	if countCalls(disp, "Show") != 1 {
+		t.Errorf("expected one Show, got %d", countCalls(disp, "Show"))
+	}
+}
+
+func TestPluginPageShowsScriptErrors(t *testing.T) {
+	script := loadTestScript(t, "broken.star", "def render(screen, stats):\n    fail('boom')\n")
+	disp := display.NewMockDisplay(128, 64)
+
+	// A failing script is not a display error; the page shows the failure
+	if err := NewPluginPage(script).Render(disp, &stats.SystemStats{}); err != nil {
+		t.Fatalf("expected script errors contained in the page, got %v", err)
+	}
+	if countCalls(disp, "Show") != 1 {
+		t.Errorf("expected the error page shown, got %d Show calls", countCalls(disp, "Show"))
+	}
+}
+
+func TestBuildPagesAddsPluginPages(t *testing.T) {
+	cfg := config.Default()
+	r := NewRenderer(display.NewMockDisplay(128, 64), cfg)
+	r.SetPlugins([]*plugin.Script{loadTestScript(t, "docker.star", "def render(screen, stats):\n    pass\n")})
+	r.BuildPages(&stats.SystemStats{Hostname: "testhost"})
+
+	last := r.PageCount() - 1
+	if got := r.PageType(last); got != config.PagePlugin {
+		t.Fatalf("PageType(%d) = %q, want %q", last, got, config.PagePlugin)
+	}
+	if got := r.PageTitle(last); got != "docker" {
+		t.Errorf("PageTitle(%d) = %q, want %q", last, got, "docker")
+	}
+}
@@ -0,0 +1,182 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// powerOKText summarises the page on a single row when nothing is wrong
+const powerOKText = "Power OK"
+
+// powerCondition is a row of the power page: a throttling condition with the
+// text shown while it is active, after it has occurred, and otherwise
+type powerCondition struct {
+	icon               func() *image.Gray
+	flags              stats.ThrottleFlags
+	ok, active, before string
+}
+
+// Each throttling condition on its own row
+var powerConditions = []powerCondition{
+	{func() *image.Gray { return iconVoltage }, stats.ThrottleUnderVoltage, "Voltage OK", "UNDER-VOLTAGE", "Low voltage seen"},
+	{func() *image.Gray { return iconFrequency }, stats.ThrottleFreqCapped, "Freq OK", "FREQ CAPPED", "Freq capped seen"},
+	{func() *image.Gray { return iconThrottle }, stats.ThrottleThrottled, "Full speed", "THROTTLED", "Throttled seen"},
+	{func() *image.Gray { return iconCPU }, stats.ThrottleSoftTemp, "Temp OK", "TEMP LIMIT", "Temp limit seen"},
+}
+
+// The conditions on three rows, frequency capping counting as throttling
+var powerConditionsMerged = []powerCondition{
+	powerConditions[0],
+	{func() *image.Gray { return iconThrottle }, stats.ThrottleFreqCapped | stats.ThrottleThrottled, "Full speed", "THROTTLED", "Throttled seen"},
+	powerConditions[3],
+}
+
+// PowerPage decodes the Raspberry Pi's throttling flags: under-voltage,
+// frequency capping, throttling and the soft temperature limit, each red
+// while active and yellow once it has occurred since boot. Displays with
+// fewer than four rows merge capping into throttling, and a single row shows
+// the worst condition.
+type PowerPage struct {
+	lines     int // configured line count (0=auto, 2=default, 4=compact)
+	intervals map[string]time.Duration
+	widgets   widgetSet
+}
+
+// NewPowerPage creates a new throttling flags page
+func NewPowerPage(lines int) *PowerPage {
+	return &PowerPage{lines: lines}
+}
+
+// Title returns the page title
+func (p *PowerPage) Title() string {
+	return "Power"
+}
+
+// SetRefreshIntervals sets how often the rows are refreshed, keyed by data
+// source (see config.PagesConfig.RefreshIntervals). The flags are read with
+// the temperature.
+func (p *PowerPage) SetRefreshIntervals(intervals map[string]time.Duration) {
+	p.intervals = intervals
+}
+
+// Render draws a row per condition, with its icon where the text is full size
+func (p *PowerPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	maxWidth := bounds.Dx() - 2*MarginLeft
+
+	if err := drawPageHeader(disp, layout, tr(p.Title())); err != nil {
+		return err
+	}
+
+	initIcons()
+	small := layout.TextScale > 0 && layout.TextScale < 1
+	conditions := powerRows(len(layout.ContentLines))
+	rows := max(len(conditions), 1) // the summary needs one
+	interval := p.intervals[config.SourceTemperature]
+	p.widgets.reset()
+	for row, y := range layout.ContentLines[:min(rows, len(layout.ContentLines))] {
+		w := &lineWidget{x: MarginLeft, y: y, scale: layout.TextScale}
+		switch {
+		case conditions == nil:
+			w.content = func(s *stats.SystemStats) []textSpan {
+				text, c := powerSummary(s.Throttled)
+				return span(TruncateText(text, maxWidth), c)
+			}
+		case small:
+			cond := conditions[row]
+			w.content = func(s *stats.SystemStats) []textSpan {
+				text, c := cond.state(s.Throttled)
+				return span(TruncateTextSmall(text, maxWidth), c)
+			}
+		default:
+			cond := conditions[row]
+			w.icon = cond.icon()
+			w.content = func(s *stats.SystemStats) []textSpan {
+				text, c := cond.state(s.Throttled)
+				return span(TruncateText(text, maxWidth-IconWidth-IconGap), c)
+			}
+		}
+		p.widgets.add(w, interval)
+	}
+	if err := p.widgets.render(disp, s, time.Now()); err != nil {
+		return err
+	}
+
+	return disp.Show()
+}
+
+// update redraws the rows whose condition changed
+func (p *PowerPage) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	return p.widgets.update(disp, s, now)
+}
+
+// powerRows returns the conditions to show on the given number of rows, or
+// nil for a single summary row
+func powerRows(rows int) []powerCondition {
+	switch {
+	case rows >= len(powerConditions):
+		return powerConditions
+	case rows >= len(powerConditionsMerged):
+		return powerConditionsMerged
+	default:
+		return nil
+	}
+}
+
+// state returns the condition's text and colour for the flags
+func (c powerCondition) state(flags *stats.ThrottleFlags) (string, color.NRGBA) {
+	switch {
+	case flags == nil:
+		return c.ok, ColorGreen
+	case flags.Active(c.flags):
+		return c.active, ColorRed
+	case flags.Occurred(c.flags):
+		return c.before, ColorYellow
+	default:
+		return c.ok, ColorGreen
+	}
+}
+
+// powerSummary returns the most serious condition: the first active one,
+// else the first that has occurred, else that all is well
+func powerSummary(flags *stats.ThrottleFlags) (string, color.NRGBA) {
+	if flags == nil {
+		return powerOKText, ColorGreen
+	}
+	for _, c := range powerConditions {
+		if flags.Active(c.flags) {
+			return c.active, ColorRed
+		}
+	}
+	for _, c := range powerConditions {
+		if flags.Occurred(c.flags) {
+			return c.before, ColorYellow
+		}
+	}
+	return powerOKText, ColorGreen
+}
+
+// TextLines shows the title above a row per condition, as many as fit
+func (p *PowerPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	lines := []string{centerText(tr(p.Title()), cols)}
+	conditions := powerRows(rows - 1)
+	if conditions == nil {
+		text, _ := powerSummary(s.Throttled)
+		return append(lines, text)
+	}
+	for _, c := range conditions {
+		text, _ := c.state(s.Throttled)
+		lines = append(lines, text)
+	}
+	return lines
+}
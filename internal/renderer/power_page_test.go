@@ -0,0 +1,91 @@
+package renderer
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestPowerPageRows(t *testing.T) {
+	page := NewPowerPage(0)
+	// Under-voltage now, throttling earlier
+	flags := stats.ThrottleUnderVoltage | stats.ThrottleUnderVoltage<<stats.ThrottleOccurred |
+		stats.ThrottleThrottled<<stats.ThrottleOccurred
+	s := &stats.SystemStats{Hostname: "pi", Throttled: &flags}
+	title := centerText("Power", 16)
+
+	want := []string{title, "UNDER-VOLTAGE", "Freq OK", "Throttled seen", "Temp OK"}
+	if got := page.TextLines(s, 16, 5); !slices.Equal(got, want) {
+		t.Errorf("5 rows: got %q, want %q", got, want)
+	}
+	// Three rows merge capping into throttling
+	want = []string{title, "UNDER-VOLTAGE", "Throttled seen", "Temp OK"}
+	if got := page.TextLines(s, 16, 4); !slices.Equal(got, want) {
+		t.Errorf("4 rows: got %q, want %q", got, want)
+	}
+	// A single row shows the worst condition
+	want = []string{title, "UNDER-VOLTAGE"}
+	if got := page.TextLines(s, 16, 2); !slices.Equal(got, want) {
+		t.Errorf("2 rows: got %q, want %q", got, want)
+	}
+
+	flags = stats.ThrottleSoftTemp << stats.ThrottleOccurred
+	if text, c := powerSummary(&flags); text != "Temp limit seen" || c != ColorYellow {
+		t.Errorf("summary = %q %v, want the temperature limit in yellow", text, c)
+	}
+	flags = 0
+	if text, c := powerSummary(&flags); text != powerOKText || c != ColorGreen {
+		t.Errorf("summary = %q %v, want %q in green", text, c, powerOKText)
+	}
+}
+
+func TestPowerPageRender(t *testing.T) {
+	flags := stats.ThrottleFreqCapped
+	s := &stats.SystemStats{Hostname: "pi", Throttled: &flags}
+
+	for _, size := range [][2]int{{128, 32}, {128, 64}, {128, 128}} {
+		page := NewPowerPage(0)
+		disp := display.NewOffscreenDisplay(size[0], size[1])
+		if err := page.Render(disp, s); err != nil {
+			t.Fatalf("%dx%d: Render() failed: %v", size[0], size[1], err)
+		}
+		if changed, err := page.update(disp, s, time.Now()); err != nil || changed {
+			t.Errorf("%dx%d: update() = %v, %v; want no change", size[0], size[1], changed, err)
+		}
+	}
+
+	// The condition clearing redraws its row
+	page := NewPowerPage(0)
+	disp := display.NewOffscreenDisplay(128, 128)
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	flags = stats.ThrottleFreqCapped << stats.ThrottleOccurred
+	if changed, err := page.update(disp, s, time.Now()); err != nil || !changed {
+		t.Errorf("update() = %v, %v; want a redraw", changed, err)
+	}
+}
+
+func TestBuildPagesPower(t *testing.T) {
+	r := NewRenderer(display.NewOffscreenDisplay(128, 64), config.Default())
+	r.BuildPages(&stats.SystemStats{Hostname: "pi"})
+	for i := 0; i < r.PageCount(); i++ {
+		if r.PageType(i) == config.PagePower {
+			t.Fatal("expected no power page without throttling flags")
+		}
+	}
+
+	var flags stats.ThrottleFlags
+	r.BuildPages(&stats.SystemStats{Hostname: "pi", Throttled: &flags})
+	found := false
+	for i := 0; i < r.PageCount(); i++ {
+		found = found || r.PageType(i) == config.PagePower
+	}
+	if !found {
+		t.Error("expected a power page on a Raspberry Pi")
+	}
+}
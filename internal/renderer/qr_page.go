@@ -0,0 +1,214 @@
+package renderer
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/qrcode"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+const (
+	// qrDefaultTitle is shown beside the code when pages.qr.title is unset
+	qrDefaultTitle = "Scan to connect"
+	// qrWaitingText is shown while a placeholder in the URL has no value,
+	// e.g. before the network is up
+	qrWaitingText = "Waiting for network..."
+
+	// qrQuietZone is the light margin around the code, in modules. The
+	// specification asks for 4; 2 scans reliably on a panel's dark
+	// surround and leaves room for bigger modules.
+	qrQuietZone = 2
+	// qrMinTextWidth is the room beside the code needed to show the title
+	// and URL; narrower panels show the code alone, centred
+	qrMinTextWidth = 40
+	// qrLineHeight is the spacing of the text lines beside the code
+	qrLineHeight = 12
+)
+
+// QRPage shows a QR code of a URL built from a template, so the device can
+// be reached by scanning the panel with a phone. The title and URL are shown
+// beside the code when there is room.
+type QRPage struct {
+	template string
+	title    string
+	lines    int // configured line count (0=auto, 2=default, 4=compact)
+
+	// Last encoded URL; encoding is only repeated when it changes
+	text string
+	code *qrcode.Code
+	err  error
+}
+
+// NewQRPage creates a page for the URL template, in which {hostname},
+// {ipv4} and {ipv6} are replaced with the device's
+func NewQRPage(template, title string, lines int) *QRPage {
+	if title == "" {
+		title = qrDefaultTitle
+	}
+	return &QRPage{template: template, title: title, lines: lines}
+}
+
+// Title returns the page title
+func (p *QRPage) Title() string {
+	return p.title
+}
+
+// URL returns the template with the placeholders filled from s. It returns
+// false when a placeholder has no value yet.
+func (p *QRPage) URL(s *stats.SystemStats) (string, bool) {
+	ipv4, ipv6 := firstAddresses(s)
+
+	url := p.template
+	for placeholder, value := range map[string]string{"{hostname}": s.Hostname, "{ipv4}": ipv4, "{ipv6}": ipv6} {
+		if !strings.Contains(url, placeholder) {
+			continue
+		}
+		if value == "" {
+			return "", false
+		}
+		url = strings.ReplaceAll(url, placeholder, value)
+	}
+	return url, true
+}
+
+// Render draws the code, with the title and URL beside it when they fit
+func (p *QRPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	url, ok := p.URL(s)
+	if !ok {
+		return p.renderNotice(disp, tr(qrWaitingText), ColorYellow)
+	}
+	code, err := p.encode(url)
+	if err != nil {
+		return p.renderNotice(disp, err.Error(), ColorRed)
+	}
+
+	bounds := disp.GetBounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	modules := code.Size + 2*qrQuietZone
+	scale := min(width, height) / modules
+	if scale == 0 {
+		return p.renderNotice(disp, "Display too small for the code", ColorRed)
+	}
+	side := modules * scale
+
+	x, y := (width-side)/2, (height-side)/2
+	textWidth := width - side - 2*MarginLeft
+	if textWidth >= qrMinTextWidth {
+		x = 0
+	}
+	if err := drawQRCode(disp, x, y, scale, code); err != nil {
+		return err
+	}
+
+	if textWidth >= qrMinTextWidth {
+		if err := p.drawText(disp, side+MarginLeft, textWidth, height, url); err != nil {
+			return err
+		}
+	}
+	return disp.Show()
+}
+
+// encode returns the code for url, re-encoding only when it changed
+func (p *QRPage) encode(url string) (*qrcode.Code, error) {
+	if url != p.text || (p.code == nil && p.err == nil) {
+		p.text = url
+		p.code, p.err = qrcode.Encode([]byte(url))
+	}
+	return p.code, p.err
+}
+
+// drawQRCode draws the code with its quiet zone at (x, y), scale pixels per
+// module. Light modules are lit so the code reads the right way round on
+// panels with a dark background.
+func drawQRCode(disp display.Display, x, y, scale int, code *qrcode.Code) error {
+	cd := display.AsColorDisplay(disp)
+	side := (code.Size + 2*qrQuietZone) * scale
+	if err := cd.FillRectColor(x, y, side, side, color.White); err != nil {
+		return err
+	}
+	origin := qrQuietZone * scale
+	for my := 0; my < code.Size; my++ {
+		for mx := 0; mx < code.Size; mx++ {
+			if !code.Dark(mx, my) {
+				continue
+			}
+			if err := cd.FillRectColor(x+origin+mx*scale, y+origin+my*scale, scale, scale, color.Black); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// drawText draws the title and then the URL, broken to the width, in the
+// column at x
+func (p *QRPage) drawText(disp display.Display, x, width, height int, url string) error {
+	type line struct {
+		text string
+		c    color.Color
+	}
+	var lines []line
+	for _, t := range wrapText(p.title, width, MeasureText) {
+		lines = append(lines, line{TruncateText(t, width), ColorGreen})
+	}
+	for _, t := range breakText(url, width) {
+		lines = append(lines, line{t, color.White})
+	}
+
+	for i, l := range lines {
+		y := i * qrLineHeight
+		if y+qrLineHeight > height+2 {
+			break
+		}
+		if err := DrawTextColor(disp, x, y, l.text, l.c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderNotice shows text under the page header in place of the code
+func (p *QRPage) renderNotice(disp display.Display, text string, c color.Color) error {
+	layout := NewLayout(disp.GetBounds(), p.lines)
+	if err := drawPageHeader(disp, layout, p.title); err != nil {
+		return err
+	}
+	maxWidth := layout.Width - 2*MarginLeft
+	measure := MeasureText
+	if layout.TextScale > 0 && layout.TextScale < 1 {
+		measure = MeasureTextSmall
+	}
+	for i, t := range wrapText(text, maxWidth, measure) {
+		if i >= len(layout.ContentLines) {
+			break
+		}
+		if err := DrawTextColorScaled(disp, MarginLeft, layout.ContentLines[i], t, c, layout.TextScale); err != nil {
+			return err
+		}
+	}
+	return disp.Show()
+}
+
+// breakText splits text without spaces, such as a URL, into lines no wider
+// than maxWidth
+func breakText(text string, maxWidth int) []string {
+	var lines []string
+	line := ""
+	for _, r := range text {
+		if line != "" && MeasureText(line+string(r)) > maxWidth {
+			lines = append(lines, line)
+			line = ""
+		}
+		line += string(r)
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
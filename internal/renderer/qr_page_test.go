@@ -0,0 +1,103 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestQRPageURL(t *testing.T) {
+	s := &stats.SystemStats{
+		Hostname: "pi",
+		Interfaces: []stats.NetInterface{
+			{Name: "wlan0", IPv6Addrs: []string{"fd00::2"}},
+			{Name: "eth0", IPv4Addrs: []string{"192.168.1.20"}},
+		},
+	}
+	tests := []struct {
+		template string
+		want     string
+		ok       bool
+	}{
+		{"http://{ipv4}:8080", "http://192.168.1.20:8080", true},
+		{"ssh://{hostname}", "ssh://pi", true},
+		{"http://[{ipv6}]/", "http://[fd00::2]/", true},
+		{"https://example.com", "https://example.com", true},
+	}
+	for _, tt := range tests {
+		got, ok := NewQRPage(tt.template, "", 0).URL(s)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("URL(%q) = %q, %v; want %q, %v", tt.template, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if _, ok := NewQRPage("http://{ipv4}/", "", 0).URL(&stats.SystemStats{Hostname: "pi"}); ok {
+		t.Error("expected no URL before an IPv4 address is known")
+	}
+}
+
+func TestQRPageRender(t *testing.T) {
+	s := &stats.SystemStats{
+		Hostname:   "pi",
+		Interfaces: []stats.NetInterface{{Name: "eth0", IPv4Addrs: []string{"192.168.1.20"}}},
+	}
+	page := NewQRPage("http://{ipv4}:8080", "", 0)
+	if page.Title() != qrDefaultTitle {
+		t.Errorf("Title() = %q, want the default", page.Title())
+	}
+
+	disp := display.NewOffscreenDisplay(128, 64)
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	img := disp.Image()
+	// A version 2 code (25 modules plus the quiet zone) at 2 pixels per
+	// module fills the left of the panel; the quiet zone is lit and the
+	// finder pattern's corner dark
+	if img.NRGBAAt(1, 4).R != 255 {
+		t.Error("expected a lit quiet zone")
+	}
+	if img.NRGBAAt(4, 3+4).R != 0 {
+		t.Error("expected the top left finder pattern to be dark")
+	}
+	if page.code == nil || page.code.Version != 2 {
+		t.Fatalf("expected a version 2 code, got %+v", page.code)
+	}
+
+	// Without an address the page says it is waiting
+	waiting := NewQRPage("http://{ipv4}:8080", "", 0)
+	if err := waiting.Render(display.NewOffscreenDisplay(128, 64), &stats.SystemStats{Hostname: "pi"}); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if waiting.code != nil {
+		t.Error("expected no code to be encoded without an address")
+	}
+}
+
+func TestBuildPagesQR(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.QR.URL = "ssh://{hostname}"
+	s := &stats.SystemStats{Hostname: "pi"}
+
+	r := NewRenderer(display.NewOffscreenDisplay(128, 64), cfg)
+	r.BuildPages(s)
+	found := false
+	for i := 0; i < r.PageCount(); i++ {
+		if r.PageType(i) == config.PageQR {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a QR page when pages.qr.url is set")
+	}
+
+	cfg.Pages.Disabled = []string{config.PageQR}
+	r.BuildPages(s)
+	for i := 0; i < r.PageCount(); i++ {
+		if r.PageType(i) == config.PageQR {
+			t.Error("expected no QR page when disabled")
+		}
+	}
+}
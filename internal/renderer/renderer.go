@@ -3,27 +3,105 @@ package renderer
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/ausil/i2c-display/internal/config"
 	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/plugin"
 	"github.com/ausil/i2c-display/internal/stats"
+	"github.com/ausil/i2c-display/internal/timeseries"
 )
 
 // Renderer manages page rendering
 type Renderer struct {
-	display       display.Display
-	pages         []Page
-	mu            sync.RWMutex // Protects pages slice
-	config        *config.Config
-	loadGraphPage *LoadGraphPage // persistent across rebuilds to preserve history
+	display        display.Display
+	pages          []Page
+	mu             sync.RWMutex // Protects pages, plugins and custom
+	config         *config.Config
+	history        *timeseries.Store // recent metric samples graphed by pages; outlives rebuilds
+	qrPage         *QRPage           // persistent across rebuilds to keep the encoded code
+	firstBootUntil time.Duration     // uptime until which the first-boot page leads; 0 disables it
+	firstBoot      bool              // whether the built pages lead with the first-boot page
+	transition     *transitioner     // nil when page transitions are disabled
+	intervals      map[string]time.Duration
+	plugins        []*plugin.Script // loaded page scripts, one page each
+	custom         []Page           // pages registered with RegisterPage
+	shown          Page             // page currently on the display, for in-place refreshes
+	textCols       int              // characters per row of text displays, 0 for pixel displays
+	textRows       int              // rows of text displays, 0 for pixel displays
+	minRefresh     time.Duration    // shortest interval between refreshes the display tolerates
+	marquee        bool             // scroll long network lines; off on slow panels
+	asleep         bool             // Sleep powered the display down; nothing is drawn until Wake
+	drawMu         sync.Mutex       // Serializes drawing; protects transition frame state and shown
 }
 
 // NewRenderer creates a new renderer
 func NewRenderer(disp display.Display, cfg *config.Config) *Renderer {
-	return &Renderer{
-		display: disp,
-		config:  cfg,
+	// Intervals are validated at config load time
+	intervals, _ := cfg.Pages.GetSourceIntervals()
+	firstBootUntil, _ := cfg.Pages.GetFirstBootUptime()
+	retention, _ := cfg.History.GetRetention()
+	r := &Renderer{
+		display:        disp,
+		config:         cfg,
+		history:        timeseries.New(cfg.HistorySamples(), retention),
+		intervals:      intervals,
+		firstBootUntil: firstBootUntil,
 	}
+	caps := display.AsColorDisplay(disp).Capabilities()
+	r.minRefresh = caps.MinRefreshInterval
+	// Scrolling redraws lines on every refresh, which slow panels such as
+	// e-paper cannot keep up with
+	r.marquee = cfg.Pages.Marquee && r.minRefresh == 0
+	if caps.Text() {
+		// Character displays show pages as text; there are no pixels to animate
+		r.textCols, r.textRows = caps.TextColumns, caps.TextRows
+		return r
+	}
+	// Slow panels such as e-paper cannot show the frames of an animation
+	if cfg.Transitions.Enabled && r.minRefresh == 0 {
+		// Duration is validated at config load time
+		d, _ := time.ParseDuration(cfg.Transitions.Duration)
+		r.transition = newTransitioner(cfg.Transitions.Type, d, disp.GetBounds())
+	}
+	return r
+}
+
+// History returns the store of recent metric samples the graph pages draw.
+// The rotation manager records every collection into it, so graphs keep
+// filling while other pages are shown.
+func (r *Renderer) History() *timeseries.Store {
+	return r.history
+}
+
+// SetBrightnessControl provides the brightness setter and current level used
+// by the fade transition. Routing these through the screensaver's display
+// keeps backlight limits in force. Without it fades degrade to a cut.
+func (r *Renderer) SetBrightnessControl(set func(level uint8) error, current func() uint8) {
+	r.drawMu.Lock()
+	defer r.drawMu.Unlock()
+	if r.transition != nil {
+		r.transition.setBrightness = set
+		r.transition.brightness = current
+	}
+}
+
+// SetPlugins sets the page scripts added to the rotation by BuildPages
+func (r *Renderer) SetPlugins(scripts []*plugin.Script) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.plugins = scripts
+}
+
+// RegisterPage adds a custom page to the rotation, after the built-in, exec
+// and script pages. It takes effect at the next BuildPages. Pages that also
+// implement the unexported partial-refresh interface are not detected; custom
+// pages are fully re-rendered on every refresh. On character displays only
+// pages implementing TextPage are shown.
+func (r *Renderer) RegisterPage(p Page) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.custom = append(r.custom, p)
 }
 
 // BuildPages creates pages based on current statistics
@@ -32,41 +110,196 @@ func (r *Renderer) BuildPages(s *stats.SystemStats) {
 
 	lines := r.config.Display.Lines
 	bounds := r.display.GetBounds()
+	pagesCfg := &r.config.Pages
+
+	// On a freshly booted device, lead with its address so it can be found
+	firstBoot := r.showFirstBoot(s)
+	if firstBoot {
+		pages = append(pages, NewFirstBootPage(lines))
+	}
+
+	perMetric := bounds.Dy() <= 32 && lines != 4
+	if r.textMode() {
+		// Character displays fit all metrics under the hostname from 4 rows
+		perMetric = r.textRows < 4
+	}
 
-	if bounds.Dy() <= 32 && lines != 4 {
+	switch {
+	case pagesCfg.IsDisabled(config.PageSystem):
+		// Left out of the rotation
+	case perMetric:
 		// Small display, default 2-line mode: one metric per page for readability.
-		pages = append(pages, NewSystemPageForMetric(SystemMetricDisk, lines), NewSystemPageForMetric(SystemMetricMemory, lines))
+		systemPages := []*SystemPage{NewSystemPageForMetric(SystemMetricDisk, lines), NewSystemPageForMetric(SystemMetricMemory, lines)}
 		if s.CPUTemp > 0 {
-			pages = append(pages, NewSystemPageForMetric(SystemMetricCPU, lines))
+			systemPages = append(systemPages, NewSystemPageForMetric(SystemMetricCPU, lines))
 		}
-	} else {
+		for _, p := range systemPages {
+			p.SetRefreshIntervals(r.intervals)
+			p.unit = r.temperatureUnit(config.PageSystem)
+			p.bytes = newByteFormat(pagesCfg.ByteUnits)
+			pages = append(pages, p)
+		}
+	default:
 		// Standard displays and 4-line scaled mode both use a single system page.
-		pages = append(pages, NewSystemPage(lines))
+		p := NewSystemPage(lines)
+		p.SetRefreshIntervals(r.intervals)
+		p.updates = r.config.Updates.Enabled
+		p.unit = r.temperatureUnit(config.PageSystem)
+		p.bytes = newByteFormat(pagesCfg.ByteUnits)
+		pages = append(pages, p)
+	}
+
+	// Add temperatures page when named sensors are configured and readable.
+	if len(s.Temperatures) > 0 && !pagesCfg.IsDisabled(config.PageTemperatures) {
+		p := NewTemperaturesPage(lines)
+		p.unit = r.temperatureUnit(config.PageTemperatures)
+		pages = append(pages, p)
+	}
+
+	// Add the throttling page on a Raspberry Pi, where the flags are readable
+	if s.Throttled != nil && !pagesCfg.IsDisabled(config.PagePower) {
+		p := NewPowerPage(lines)
+		p.SetRefreshIntervals(r.intervals)
+		pages = append(pages, p)
 	}
 
 	// Add load graph page if load data is available.
-	if s.LoadAvg1 > 0 || s.LoadAvg5 > 0 || s.LoadAvg15 > 0 {
-		if r.loadGraphPage == nil {
-			r.loadGraphPage = NewLoadGraphPage(lines)
-		}
-		pages = append(pages, r.loadGraphPage)
+	if (s.LoadAvg1 > 0 || s.LoadAvg5 > 0 || s.LoadAvg15 > 0) && !pagesCfg.IsDisabled(config.PageLoad) {
+		p := NewLoadGraphPage(lines)
+		p.history = r.history
+		p.perCore = pagesCfg.Load.PerCore
+		pages = append(pages, p)
 	}
 
 	// Add network pages based on interface count
-	if len(s.Interfaces) > 0 {
+	if len(s.Interfaces) > 0 && !pagesCfg.IsDisabled(config.PageNetwork) {
 		maxPerPage := r.config.Network.MaxInterfacesPerPage
+		if r.textMode() {
+			// Each interface takes a row for its name and one for its address
+			maxPerPage = min(maxPerPage, max(r.textRows/2, 1))
+		} else {
+			// Interfaces beyond the layout's content rows would not be
+			// drawn; spread them over more pages instead
+			rows := NewLayout(r.display.GetBounds(), lines).MaxContentLines
+			maxPerPage = min(maxPerPage, max(rows, 1))
+		}
 		totalPages := (len(s.Interfaces) + maxPerPage - 1) / maxPerPage
 
 		for i := 0; i < totalPages; i++ {
-			pages = append(pages, NewNetworkPage(i+1, maxPerPage, len(s.Interfaces), lines))
+			p := NewNetworkPage(i+1, maxPerPage, len(s.Interfaces), lines)
+			p.SetRefreshIntervals(r.intervals)
+			p.SetMarquee(r.marquee)
+			p.SetIPv6Display(r.config.Network.IPv6Format, r.config.Network.IPv6Labels)
+			pages = append(pages, p)
 		}
 	}
 
+	// Add a detail page per interface when enabled
+	if r.config.Network.DetailPage && !pagesCfg.IsDisabled(config.PageNetworkDetail) {
+		for i := range s.Interfaces {
+			p := NewNetworkDetailPage(i, len(s.Interfaces), lines)
+			p.SetRefreshIntervals(r.intervals)
+			p.SetMarquee(r.marquee)
+			p.SetIPv6Display(r.config.Network.IPv6Format, r.config.Network.IPv6Labels)
+			pages = append(pages, p)
+		}
+	}
+
+	// Add the internet reachability page when the check is enabled
+	if r.config.Connectivity.Enabled && !pagesCfg.IsDisabled(config.PageConnectivity) {
+		pages = append(pages, NewConnectivityPage(r.config.Connectivity.Target, lines))
+	}
+
+	// Add the ping latency page when the monitor is enabled
+	if r.config.Latency.Enabled && !pagesCfg.IsDisabled(config.PageLatency) {
+		// Thresholds are validated at config load time
+		warn, _ := time.ParseDuration(r.config.Latency.Warn)
+		critical, _ := time.ParseDuration(r.config.Latency.Critical)
+		pages = append(pages, NewLatencyPage(warn, critical, lines))
+	}
+
+	// Add the busiest process pages, by CPU and by memory
+	if pagesCfg.Top.Count > 0 && !pagesCfg.IsDisabled(config.PageTop) {
+		for _, memory := range []bool{false, true} {
+			p := NewTopPage(memory, lines)
+			p.bytes = newByteFormat(pagesCfg.ByteUnits)
+			p.SetRefreshIntervals(r.intervals)
+			pages = append(pages, p)
+		}
+	}
+
+	// Add the flash storage wear page
+	if pagesCfg.Storage.Enabled && !pagesCfg.IsDisabled(config.PageStorage) {
+		p := NewStoragePage(pagesCfg.Storage.Warn, pagesCfg.Storage.Critical, lines)
+		p.SetRefreshIntervals(r.intervals)
+		pages = append(pages, p)
+	}
+
+	// Add one page per configured exec command; the output may still be
+	// pending, in which case the page says so
+	if !pagesCfg.IsDisabled(config.PageExec) {
+		for _, e := range pagesCfg.Exec {
+			pages = append(pages, NewExecPage(e.Title, lines))
+		}
+	}
+	if !pagesCfg.IsDisabled(config.PageHTTPJSON) {
+		for _, h := range pagesCfg.HTTPJSON {
+			pages = append(pages, NewHTTPJSONPage(h.Title, lines))
+		}
+	}
+	if !pagesCfg.IsDisabled(config.PageTemplate) {
+		for _, t := range pagesCfg.Templates {
+			pages = append(pages, NewTemplatePage(t, lines))
+		}
+	}
+
+	// Add the QR code page; it has no text form
+	if pagesCfg.QR.URL != "" && !pagesCfg.IsDisabled(config.PageQR) && !r.textMode() {
+		if r.qrPage == nil {
+			r.qrPage = NewQRPage(pagesCfg.QR.URL, pagesCfg.QR.Title, lines)
+		}
+		pages = append(pages, r.qrPage)
+	}
+
+	r.mu.RLock()
+	// Add one page per loaded script. Scripts draw pixels, so they have no
+	// text form.
+	if !pagesCfg.IsDisabled(config.PagePlugin) && !r.textMode() {
+		for _, script := range r.plugins {
+			pages = append(pages, NewPluginPage(script))
+		}
+	}
+	// Add pages registered by programs embedding the renderer
+	if !pagesCfg.IsDisabled(config.PageCustom) {
+		for _, p := range r.custom {
+			if _, ok := p.(TextPage); ok || !r.textMode() {
+				pages = append(pages, p)
+			}
+		}
+	}
+	r.mu.RUnlock()
+
 	r.mu.Lock()
 	r.pages = pages
+	r.firstBoot = firstBoot
 	r.mu.Unlock()
 }
 
+// showFirstBoot reports whether the first-boot page belongs in the rotation.
+// An unknown uptime counts as long past first boot.
+func (r *Renderer) showFirstBoot(s *stats.SystemStats) bool {
+	return r.firstBootUntil > 0 && !r.config.Pages.IsDisabled(config.PageFirstBoot) &&
+		s.Uptime > 0 && s.Uptime < r.firstBootUntil
+}
+
+// FirstBootChanged reports whether the first-boot page should join or leave
+// the rotation, so the pages need rebuilding
+func (r *Renderer) FirstBootChanged(s *stats.SystemStats) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.showFirstBoot(s) != r.firstBoot
+}
+
 // GetPages returns the current pages
 func (r *Renderer) GetPages() []Page {
 	r.mu.RLock()
@@ -79,16 +312,125 @@ func (r *Renderer) GetPages() []Page {
 
 // RenderPage renders a specific page by index
 func (r *Renderer) RenderPage(pageIdx int, s *stats.SystemStats) error {
+	page, err := r.page(pageIdx)
+	if err != nil {
+		return err
+	}
+
+	r.drawMu.Lock()
+	defer r.drawMu.Unlock()
+	if r.asleep {
+		return nil
+	}
+	return r.renderPage(page, pageIdx, s)
+}
+
+// RefreshPage brings a page up to date. When the page is already on the
+// display and is built from widgets, only the widgets that are due and
+// changed are redrawn, and nothing is flushed if none did. Otherwise the page
+// is rendered in full, as with RenderPage.
+func (r *Renderer) RefreshPage(pageIdx int, s *stats.SystemStats) error {
+	page, err := r.page(pageIdx)
+	if err != nil {
+		return err
+	}
+
+	r.drawMu.Lock()
+	defer r.drawMu.Unlock()
+	if r.asleep {
+		return nil
+	}
+	if r.textMode() {
+		// The display only rewrites rows whose text changed
+		return r.renderText(page, s)
+	}
+	wp, ok := page.(widgetPage)
+	if !ok || r.shown != page {
+		return r.renderPage(page, pageIdx, s)
+	}
+
+	if r.transition == nil {
+		return r.updateWidgets(r.display, wp, s)
+	}
+	// Update the transition frame so the next animation starts from what is
+	// on screen, then copy it to the display
+	frame := r.transition.frames[r.transition.current]
+	changed, err := wp.update(frame, s, time.Now())
+	if err == nil && changed {
+		err = blit(r.display, frame.Image())
+	}
+	r.markShown(page, err)
+	return err
+}
+
+// page returns the rotation page at pageIdx
+func (r *Renderer) page(pageIdx int) (Page, error) {
 	r.mu.RLock()
+	defer r.mu.RUnlock()
 	if pageIdx < 0 || pageIdx >= len(r.pages) {
-		pageCount := len(r.pages)
-		r.mu.RUnlock()
-		return fmt.Errorf("invalid page index %d (have %d pages)", pageIdx, pageCount)
+		return nil, fmt.Errorf("invalid page index %d (have %d pages)", pageIdx, len(r.pages))
 	}
-	page := r.pages[pageIdx]
-	r.mu.RUnlock()
+	return r.pages[pageIdx], nil
+}
+
+// renderPage fully renders a rotation page, animating the change when
+// transitions are enabled. Callers hold drawMu.
+func (r *Renderer) renderPage(page Page, pageIdx int, s *stats.SystemStats) error {
+	var err error
+	switch {
+	case r.textMode():
+		return r.renderText(page, s)
+	case r.transition != nil:
+		err = r.transition.render(r.display, page, pageIdx, s)
+	default:
+		err = page.Render(r.display, s)
+	}
+	r.markShown(page, err)
+	return err
+}
 
-	return page.Render(r.display, s)
+// textMode reports whether the display is a character display
+func (r *Renderer) textMode() bool {
+	return r.textRows > 0
+}
+
+// renderText shows a page on a character display. Pages without a text form
+// show their title. Callers hold drawMu.
+func (r *Renderer) renderText(page Page, s *stats.SystemStats) error {
+	var lines []string
+	if tp, ok := page.(TextPage); ok {
+		lines = tp.TextLines(s, r.textCols, r.textRows)
+	} else {
+		lines = []string{centerText(tr(page.Title()), r.textCols)}
+	}
+	err := display.WriteLines(r.display, lines)
+	if err == nil {
+		err = r.display.Show()
+	}
+	r.markShown(page, err)
+	return err
+}
+
+// markShown records which page is on the display. After a failed draw the
+// display contents are unknown (it may also have been re-initialized), so the
+// next refresh renders in full. Callers hold drawMu.
+func (r *Renderer) markShown(page Page, err error) {
+	if err != nil {
+		r.shown = nil
+		return
+	}
+	r.shown = page
+}
+
+// updateWidgets redraws the due widgets of a page directly on disp and
+// flushes it if anything changed. Callers hold drawMu.
+func (r *Renderer) updateWidgets(disp display.Display, wp widgetPage, s *stats.SystemStats) error {
+	changed, err := wp.update(disp, s, time.Now())
+	if err == nil && changed {
+		err = disp.Show()
+	}
+	r.markShown(wp, err)
+	return err
 }
 
 // PageCount returns the number of pages
@@ -110,3 +452,173 @@ func (r *Renderer) PageTitle(idx int) string {
 	}
 	return r.pages[idx].Title()
 }
+
+// PageTitles returns the titles of all pages in rotation order
+func (r *Renderer) PageTitles() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	titles := make([]string, len(r.pages))
+	for i, page := range r.pages {
+		titles[i] = page.Title()
+	}
+	return titles
+}
+
+// PageType returns the config page type (config.PageSystem etc.) of the page
+// at the given index, or "unknown" if out of range.
+func (r *Renderer) PageType(idx int) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if idx < 0 || idx >= len(r.pages) {
+		return unknownPageTitle
+	}
+	return pageType(r.pages[idx])
+}
+
+// Types of the pages shown over the rotation, for metrics labels
+const (
+	overlayAlert       = "alert"
+	overlayShutdown    = "shutdown"
+	overlayMessage     = "message"
+	overlayCalibration = "calibration"
+	overlayClock       = "clock"
+)
+
+// PageTypeOf returns the type of a page for metrics labels: its config page
+// type for rotation pages, or e.g. "alert" or "clock" for the pages shown
+// over the rotation. Unlike titles, types do not vary with the page's
+// position or content.
+func PageTypeOf(p Page) string {
+	switch p.(type) {
+	case *AlertPage:
+		return overlayAlert
+	case *ShutdownPage:
+		return overlayShutdown
+	case *MessagePage:
+		return overlayMessage
+	case *CalibrationPage:
+		return overlayCalibration
+	case *ClockPage:
+		return overlayClock
+	default:
+		return pageType(p)
+	}
+}
+
+// pageType maps a rotation page to its config page type
+func pageType(p Page) string {
+	switch p.(type) {
+	case *SystemPage:
+		return config.PageSystem
+	case *TemperaturesPage:
+		return config.PageTemperatures
+	case *LoadGraphPage:
+		return config.PageLoad
+	case *NetworkPage:
+		return config.PageNetwork
+	case *NetworkDetailPage:
+		return config.PageNetworkDetail
+	case *ConnectivityPage:
+		return config.PageConnectivity
+	case *LatencyPage:
+		return config.PageLatency
+	case *TopPage:
+		return config.PageTop
+	case *PowerPage:
+		return config.PagePower
+	case *StoragePage:
+		return config.PageStorage
+	case *ExecPage:
+		return config.PageExec
+	case *HTTPJSONPage:
+		return config.PageHTTPJSON
+	case *TemplatePage:
+		return config.PageTemplate
+	case *QRPage:
+		return config.PageQR
+	case *FirstBootPage:
+		return config.PageFirstBoot
+	case *PluginPage:
+		return config.PagePlugin
+	default:
+		// Anything else in the rotation came from RegisterPage
+		return config.PageCustom
+	}
+}
+
+// RenderTransient renders a page that is not part of the rotation (e.g. an
+// alert or message overlay) using the renderer's display.
+func (r *Renderer) RenderTransient(page Page, s *stats.SystemStats) error {
+	r.drawMu.Lock()
+	defer r.drawMu.Unlock()
+	if r.asleep {
+		return nil
+	}
+	return r.renderTransient(page, s)
+}
+
+// RefreshTransient is RenderTransient for pages shown on every refresh, such
+// as the screensaver clock: while the page stays on the display only its
+// changed widgets are redrawn.
+func (r *Renderer) RefreshTransient(page Page, s *stats.SystemStats) error {
+	r.drawMu.Lock()
+	defer r.drawMu.Unlock()
+	if r.asleep {
+		return nil
+	}
+	if r.textMode() {
+		return r.renderText(page, s)
+	}
+	if wp, ok := page.(widgetPage); ok && r.shown == page {
+		return r.updateWidgets(r.display, wp, s)
+	}
+	return r.renderTransient(page, s)
+}
+
+// renderTransient fully renders a transient page. Callers hold drawMu.
+func (r *Renderer) renderTransient(page Page, s *stats.SystemStats) error {
+	if r.textMode() {
+		return r.renderText(page, s)
+	}
+	if r.transition != nil {
+		r.transition.reset()
+	}
+	err := page.Render(r.display, s)
+	r.markShown(page, err)
+	return err
+}
+
+// Sleep blanks the display and powers its panel down once the frame being
+// drawn is done, e.g. before the host suspends. Pages are not drawn until
+// Wake.
+func (r *Renderer) Sleep() error {
+	r.drawMu.Lock()
+	defer r.drawMu.Unlock()
+	r.asleep = true
+	r.shown = nil
+	if r.transition != nil {
+		r.transition.reset()
+	}
+	return display.Sleep(r.display)
+}
+
+// Wake re-initializes the display after Sleep and lets pages be drawn
+// again. The next refresh renders the page in full.
+func (r *Renderer) Wake() error {
+	r.drawMu.Lock()
+	defer r.drawMu.Unlock()
+	r.asleep = false
+	r.shown = nil
+	return r.display.Init()
+}
+
+// MinRefreshInterval returns the shortest interval between refreshes the
+// display tolerates, 0 when it has no limit
+func (r *Renderer) MinRefreshInterval() time.Duration {
+	return r.minRefresh
+}
+
+// Lines returns the configured content line mode, for constructing transient pages.
+func (r *Renderer) Lines() int {
+	return r.config.Display.Lines
+}
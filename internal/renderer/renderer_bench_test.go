@@ -56,3 +56,15 @@ func BenchmarkBuildPages(b *testing.B) {
 		rend.BuildPages(testStats)
 	}
 }
+
+func BenchmarkDrawTextColor(b *testing.B) {
+	disp := display.NewMockDisplay(128, 64)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := DrawTextColor(disp, 0, 0, "CPU: 42.5C", ColorGreen); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package renderer
 
 import (
+	"errors"
+	"image/color"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ausil/i2c-display/internal/config"
 	"github.com/ausil/i2c-display/internal/display"
@@ -159,6 +164,44 @@ func TestRenderer(t *testing.T) {
 	}
 }
 
+func TestRendererSleepWake(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	renderer := NewRenderer(disp, config.Default())
+	testStats := &stats.SystemStats{Hostname: "testhost"}
+	renderer.BuildPages(testStats)
+
+	if err := renderer.RenderPage(0, testStats); err != nil {
+		t.Fatalf("RenderPage(0) failed: %v", err)
+	}
+	if err := renderer.Sleep(); err != nil {
+		t.Fatalf("Sleep() failed: %v", err)
+	}
+	if disp.GetPixel(10, 4) {
+		t.Error("expected Sleep to blank the display")
+	}
+
+	// Nothing is drawn while asleep
+	disp.ClearCalls()
+	if err := renderer.RefreshPage(0, testStats); err != nil {
+		t.Fatalf("RefreshPage(0) failed: %v", err)
+	}
+	if calls := disp.GetCalls(); len(calls) != 0 {
+		t.Errorf("expected no drawing while asleep, got %v", calls)
+	}
+
+	// Wake re-initializes the display and the page is drawn in full again
+	if err := renderer.Wake(); err != nil {
+		t.Fatalf("Wake() failed: %v", err)
+	}
+	if err := renderer.RefreshPage(0, testStats); err != nil {
+		t.Fatalf("RefreshPage(0) failed: %v", err)
+	}
+	calls := disp.GetCalls()
+	if len(calls) < 3 || calls[0] != "Init" || calls[1] != "Clear" || calls[len(calls)-1] != "Show" {
+		t.Errorf("calls after Wake = %v, want Init, then a full render", calls)
+	}
+}
+
 func TestRendererNoInterfaces(t *testing.T) {
 	disp := display.NewMockDisplay(128, 64)
 	cfg := config.Default()
@@ -374,6 +417,26 @@ func TestPageTitle(t *testing.T) {
 	}
 }
 
+func TestPageTypeOf(t *testing.T) {
+	tests := []struct {
+		page Page
+		want string
+	}{
+		{NewSystemPage(0), config.PageSystem},
+		{NewNetworkPage(1, 3, 9, 0), config.PageNetwork},
+		{NewNetworkPage(2, 3, 9, 0), config.PageNetwork},
+		{NewAlertPage(0), "alert"},
+		{NewShutdownPage(0, 0), "shutdown"},
+		{NewClockPage(0), "clock"},
+		{NewCalibrationPage(0, 1), "calibration"},
+	}
+	for _, tt := range tests {
+		if got := PageTypeOf(tt.page); got != tt.want {
+			t.Errorf("PageTypeOf(%q) = %q, want %q", tt.page.Title(), got, tt.want)
+		}
+	}
+}
+
 func TestGetPages(t *testing.T) {
 	disp := display.NewMockDisplay(128, 64)
 	cfg := config.Default()
@@ -446,6 +509,150 @@ func TestSystemPageZeroCPUTemp(t *testing.T) {
 	}
 }
 
+func TestSystemPageFan(t *testing.T) {
+	testStats := &stats.SystemStats{Hostname: "testhost", CPUTemp: 55.5}
+	page := NewSystemPageForMetric(SystemMetricCPU, 0)
+
+	if got := page.TextLines(testStats, 20, 4)[1]; got != "CPU 55.5°C" {
+		t.Errorf("without fan control: got %q", got)
+	}
+
+	testStats.Fan = &stats.FanStatus{Duty: 45}
+	if got := page.TextLines(testStats, 20, 4)[1]; got != "CPU 55.5°C fan 45%" {
+		t.Errorf("20 columns: got %q", got)
+	}
+	if got := page.TextLines(testStats, 16, 2)[1]; got != "CPU 55.5°C F:45%" {
+		t.Errorf("16 columns: got %q", got)
+	}
+
+	testStats.Fan.Duty = 0
+	if got := page.TextLines(testStats, 20, 4)[1]; got != "CPU 55.5°C fan off" {
+		t.Errorf("fan off: got %q", got)
+	}
+
+	if err := NewSystemPage(0).Render(display.NewMockDisplay(128, 64), testStats); err != nil {
+		t.Errorf("Render with fan status failed: %v", err)
+	}
+}
+
+func TestSystemPageDiskReadOnly(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	s := &stats.SystemStats{Hostname: "testhost", DiskUsed: 10 << 30, DiskTotal: 100 << 30}
+	page := NewSystemPage(0)
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if changed, err := page.update(disp, s, time.Now()); err != nil || changed {
+		t.Fatalf("update() = %v, %v; want no change", changed, err)
+	}
+
+	// The filesystem flips to read-only: the header becomes the warning
+	s.DiskReadOnly = true
+	if changed, err := page.update(disp, s, time.Now()); err != nil || !changed {
+		t.Fatalf("update() = %v, %v; want a redraw", changed, err)
+	}
+	if diskColor(s) != ColorRed {
+		t.Errorf("read-only disk colour = %v, want red", diskColor(s))
+	}
+	lines := page.TextLines(s, 20, 4)
+	if strings.TrimSpace(lines[0]) != diskReadOnlyText {
+		t.Errorf("header = %q, want %q", lines[0], diskReadOnlyText)
+	}
+
+	// And back once it recovers
+	s.DiskReadOnly = false
+	if changed, err := page.update(disp, s, time.Now()); err != nil || !changed {
+		t.Fatalf("update() = %v, %v; want a redraw", changed, err)
+	}
+	if lines := page.TextLines(s, 20, 4); strings.TrimSpace(lines[0]) != "testhost" {
+		t.Errorf("header = %q, want the hostname", lines[0])
+	}
+}
+
+func TestSystemPageInodes(t *testing.T) {
+	s := &stats.SystemStats{Hostname: "testhost", DiskUsed: 10 << 30, DiskTotal: 100 << 30, DiskInodesUsed: 10, DiskInodesTotal: 100}
+	if got := inodeText(s); got != "" {
+		t.Errorf("inodeText at 10%% = %q, want none", got)
+	}
+	if diskColor(s) != ColorGreen {
+		t.Errorf("disk colour = %v, want green", diskColor(s))
+	}
+
+	// Inodes running out colour the disk even with plenty of space left
+	s.DiskInodesUsed = 90
+	if got := inodeText(s); got != " i:90%" {
+		t.Errorf("inodeText at 90%% = %q, want %q", got, " i:90%")
+	}
+	if diskColor(s) != ColorRed {
+		t.Errorf("disk colour = %v, want red", diskColor(s))
+	}
+	if got := NewSystemPageForMetric(SystemMetricDisk, 0).TextLines(s, 30, 2)[1]; got != "Disk 10% 10.0/100.0G i:90%" {
+		t.Errorf("disk line = %q", got)
+	}
+}
+
+func TestSystemPageUpdatesFooter(t *testing.T) {
+	tests := []struct {
+		status *stats.UpdateStatus
+		text   string
+		c      color.NRGBA
+	}{
+		{nil, "", ColorGreen},
+		{&stats.UpdateStatus{}, "Up to date", ColorGreen},
+		{&stats.UpdateStatus{Count: 1}, "1 update", ColorYellow},
+		{&stats.UpdateStatus{Count: 12}, "12 updates", ColorYellow},
+		{&stats.UpdateStatus{RebootRequired: true}, "Reboot required", ColorRed},
+		{&stats.UpdateStatus{Count: 3, RebootRequired: true}, "Reboot, 3 upd", ColorRed},
+		{&stats.UpdateStatus{Err: errors.New("apt-get: not found")}, "Updates: error", ColorYellow},
+	}
+	for _, tt := range tests {
+		text, c := updatesText(&stats.SystemStats{Updates: tt.status})
+		if text != tt.text || c != tt.c {
+			t.Errorf("%+v: got %q %v, want %q %v", tt.status, text, c, tt.text, tt.c)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Updates.Enabled = true
+	disp := display.NewMockDisplay(128, 64)
+	r := NewRenderer(disp, cfg)
+	s := &stats.SystemStats{Hostname: "testhost", DiskTotal: 100 << 30}
+	r.BuildPages(s)
+	page := r.pages[0].(*SystemPage)
+	if !page.updates {
+		t.Fatal("expected the system page to show updates when enabled")
+	}
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	// The first check finishing redraws the footer
+	s.Updates = &stats.UpdateStatus{Count: 4}
+	if changed, err := page.update(disp, s, time.Now()); err != nil || !changed {
+		t.Errorf("update() = %v, %v; want a redraw", changed, err)
+	}
+	if lines := page.TextLines(s, 20, 5); lines[len(lines)-1] != "4 updates" {
+		t.Errorf("expected the updates last, got %q", lines)
+	}
+
+	// The dial makes room for the footer on large colour displays
+	s.CPUTemp = 50
+	if err := page.Render(display.NewOffscreenDisplay(128, 128), s); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	dial := false
+	for _, item := range page.widgets.items {
+		if g, ok := item.w.(*tempGaugeWidget); ok {
+			dial = true
+			if g.area.Max.Y > 116 {
+				t.Errorf("dial area %v overlaps the footer", g.area)
+			}
+		}
+	}
+	if !dial {
+		t.Error("expected the dial on a 128x128 colour display")
+	}
+}
+
 func TestNetworkPageIPv6(t *testing.T) {
 	disp := display.NewMockDisplay(128, 64)
 
@@ -551,3 +758,375 @@ func TestMeasureTextEmpty(t *testing.T) {
 		t.Errorf("expected width 0 for empty string, got %d", width)
 	}
 }
+
+func TestTemperaturesPage(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	page := NewTemperaturesPage(0)
+
+	if page.Title() != "Temperatures" {
+		t.Errorf("expected title 'Temperatures', got %q", page.Title())
+	}
+
+	s := &stats.SystemStats{
+		Hostname: "testhost",
+		Temperatures: []stats.TempReading{
+			{Name: "CPU", Value: 45.0},
+			{Name: "GPU", Value: 50.5},
+		},
+	}
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+
+	calls := disp.GetCalls()
+	if calls[len(calls)-1] != "Show" {
+		t.Errorf("expected last call to be Show, got %s", calls[len(calls)-1])
+	}
+}
+
+func TestRendererBuildsTemperaturesPage(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	rend := NewRenderer(disp, config.Default())
+
+	rend.BuildPages(&stats.SystemStats{
+		Hostname:     "testhost",
+		Temperatures: []stats.TempReading{{Name: "GPU", Value: 50}},
+	})
+
+	found := false
+	for _, p := range rend.GetPages() {
+		if p.Title() == "Temperatures" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected Temperatures page when sensors are present")
+	}
+}
+
+func TestAlertPageFlashes(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	page := NewAlertPage(0)
+	page.SetMessages([]string{"Disk: 95.0"})
+	s := &stats.SystemStats{Hostname: "testhost"}
+
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if !disp.GetPixel(0, 0) {
+		t.Error("expected border pixel on first frame")
+	}
+
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if disp.GetPixel(0, 63) {
+		t.Error("expected border to be off on second frame")
+	}
+}
+
+func TestShutdownPage(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	page := NewShutdownPage(0, 85)
+	page.SetRemaining(25 * time.Second)
+
+	if page.Title() != "Shutdown" {
+		t.Errorf("expected title 'Shutdown', got %q", page.Title())
+	}
+	if err := page.Render(disp, &stats.SystemStats{CPUTemp: 91.2}); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if !disp.GetPixel(0, 0) || !disp.GetPixel(127, 63) {
+		t.Error("expected border to be drawn")
+	}
+}
+
+func TestClockPage(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+	}{
+		{"128x64", 128, 64},
+		{"128x32", 128, 32},
+		{"160x80", 160, 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disp := display.NewMockDisplay(tt.width, tt.height)
+			page := NewClockPage(0)
+			page.now = func() time.Time { return time.Date(2024, 3, 9, 18, 8, 0, 0, time.UTC) }
+
+			if page.Title() != "Clock" {
+				t.Errorf("expected title 'Clock', got %q", page.Title())
+			}
+			if err := page.Render(disp, &stats.SystemStats{}); err != nil {
+				t.Fatalf("Render() failed: %v", err)
+			}
+
+			lit := 0
+			for y := 0; y < tt.height; y++ {
+				for x := 0; x < tt.width; x++ {
+					if disp.GetPixel(x, y) {
+						lit++
+					}
+				}
+			}
+			if lit == 0 {
+				t.Error("expected clock to draw pixels")
+			}
+		})
+	}
+}
+
+func TestClockPageEnlargesTime(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	if err := drawTextCenteredEnlarged(disp, 0, "8", color.White, 3); err != nil {
+		t.Fatalf("drawTextCenteredEnlarged() failed: %v", err)
+	}
+
+	// Each source pixel becomes a 3x3 block, so lit rows come in runs of 3
+	minY, maxY := -1, -1
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 128; x++ {
+			if disp.GetPixel(x, y) {
+				if minY < 0 {
+					minY = y
+				}
+				maxY = y
+			}
+		}
+	}
+	if minY < 0 {
+		t.Fatal("expected enlarged glyph to draw pixels")
+	}
+	if (maxY-minY+1)%3 != 0 {
+		t.Errorf("expected glyph height to be a multiple of 3, got %d", maxY-minY+1)
+	}
+}
+
+func TestClockPageTimeSync(t *testing.T) {
+	// countColor counts the pixels mostly of the given primary
+	countColor := func(disp *display.OffscreenDisplay, red bool) int {
+		n := 0
+		img := disp.Image()
+		for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+			for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+				c := img.NRGBAAt(x, y)
+				if (red && c.R > 200 && c.G < 50) || (!red && c.G > 200 && c.R < 50) {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	at := func() time.Time { return time.Date(2024, 3, 9, 18, 8, 0, 0, time.UTC) }
+	s := &stats.SystemStats{TimeSync: &stats.TimeSyncStatus{Synced: true, Offset: 300 * time.Microsecond, OffsetKnown: true}}
+
+	for _, size := range [][2]int{{128, 32}, {128, 64}} {
+		page := NewClockPage(0)
+		page.now = at
+		disp := display.NewOffscreenDisplay(size[0], size[1])
+		if err := page.Render(disp, s); err != nil {
+			t.Fatalf("%dx%d: Render() failed: %v", size[0], size[1], err)
+		}
+		if countColor(disp, false) == 0 || countColor(disp, true) != 0 {
+			t.Errorf("%dx%d: expected a green tick", size[0], size[1])
+		}
+		if changed, err := page.update(disp, s, time.Now()); err != nil || changed {
+			t.Errorf("%dx%d: update() = %v, %v; want no change", size[0], size[1], changed, err)
+		}
+	}
+
+	// Losing synchronisation redraws a red cross
+	page := NewClockPage(0)
+	page.now = at
+	disp := display.NewOffscreenDisplay(128, 64)
+	if err := page.Render(disp, s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	lost := &stats.SystemStats{TimeSync: &stats.TimeSyncStatus{}}
+	if changed, err := page.update(disp, lost, time.Now()); err != nil || !changed {
+		t.Fatalf("update() = %v, %v; want a redraw", changed, err)
+	}
+	if countColor(disp, true) == 0 || countColor(disp, false) != 0 {
+		t.Error("expected a red cross")
+	}
+
+	want := []string{centerText("18:08", 16), centerText("Sat 09 Mar", 16), centerText("NTP ok +300us", 16)}
+	if got := page.TextLines(s, 16, 3); !slices.Equal(got, want) {
+		t.Errorf("TextLines() = %q, want %q", got, want)
+	}
+	if got := page.TextLines(lost, 16, 2); len(got) != 2 {
+		t.Errorf("expected the status to be dropped on 2 rows, got %q", got)
+	}
+}
+
+func TestOffsetText(t *testing.T) {
+	tests := []struct {
+		offset time.Duration
+		want   string
+	}{
+		{42 * time.Microsecond, "+42us"},
+		{-3100 * time.Microsecond, "-3.1ms"},
+		{1200 * time.Millisecond, "+1.2s"},
+		{0, "+0us"},
+	}
+	for _, tt := range tests {
+		if got := offsetText(tt.offset); got != tt.want {
+			t.Errorf("offsetText(%v) = %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestBuildPagesSkipsDisabledTypes(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.Disabled = []string{config.PageLoad, config.PageNetwork}
+
+	r := NewRenderer(display.NewMockDisplay(128, 64), cfg)
+	r.BuildPages(&stats.SystemStats{
+		Hostname:   "testhost",
+		LoadAvg1:   0.5,
+		Interfaces: []stats.NetInterface{{Name: "eth0", IPv4Addrs: []string{"192.168.1.100"}}},
+	})
+
+	if r.PageCount() != 1 {
+		t.Fatalf("expected only the system page, got %d pages", r.PageCount())
+	}
+	if got := r.PageType(0); got != config.PageSystem {
+		t.Errorf("PageType(0) = %q, want %q", got, config.PageSystem)
+	}
+	if got := r.PageType(5); got != unknownPageTitle {
+		t.Errorf("PageType(5) = %q, want %q", got, unknownPageTitle)
+	}
+}
+
+// textDisplay is a mock character display recording the lines it shows
+type textDisplay struct {
+	*display.MockDisplay
+	cols, rows int
+	pending    []string
+	shown      []string
+}
+
+func newTextDisplay(cols, rows int) *textDisplay {
+	return &textDisplay{MockDisplay: display.NewMockDisplay(cols*5, rows*8), cols: cols, rows: rows}
+}
+
+func (d *textDisplay) WriteLines(lines []string) error {
+	d.pending = lines
+	return nil
+}
+
+func (d *textDisplay) Show() error {
+	d.shown = d.pending
+	return d.MockDisplay.Show()
+}
+
+func (d *textDisplay) DrawPixelColor(x, y int, c color.Color) error { return nil }
+
+func (d *textDisplay) FillRectColor(x, y, width, height int, c color.Color) error { return nil }
+
+func (d *textDisplay) Capabilities() display.Capabilities {
+	return display.Capabilities{ColorDepth: 1, TextColumns: d.cols, TextRows: d.rows}
+}
+
+// stubPage is a custom page with no text form
+type stubPage struct {
+	title string
+}
+
+func (p *stubPage) Render(disp display.Display, s *stats.SystemStats) error { return disp.Show() }
+
+func (p *stubPage) Title() string { return p.title }
+
+func TestRendererTextMode(t *testing.T) {
+	testStats := &stats.SystemStats{
+		Hostname:    "pi",
+		CPUTemp:     45.5,
+		MemoryUsed:  2 * 1024 * 1024 * 1024,
+		MemoryTotal: 4 * 1024 * 1024 * 1024,
+		DiskUsed:    50 * 1024 * 1024 * 1024,
+		DiskTotal:   100 * 1024 * 1024 * 1024,
+		LoadAvg1:    0.5,
+		Interfaces: []stats.NetInterface{
+			{Name: "eth0", IPv4Addrs: []string{"192.168.1.100"}},
+			{Name: "wlan0", IPv4Addrs: []string{"10.0.0.50"}},
+		},
+	}
+
+	t.Run("16x2", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Transitions.Enabled = true
+		disp := newTextDisplay(16, 2)
+		r := NewRenderer(disp, cfg)
+		if r.transition != nil {
+			t.Error("transitions should be disabled on text displays")
+		}
+		r.RegisterPage(&stubPage{title: "pixels only"})
+		r.BuildPages(testStats)
+
+		// Disk, memory, CPU, load and one page per interface; the custom page
+		// has no text form
+		if got := r.PageCount(); got != 6 {
+			t.Fatalf("expected 6 pages, got %d", got)
+		}
+		if err := r.RenderPage(0, testStats); err != nil {
+			t.Fatalf("RenderPage(0) failed: %v", err)
+		}
+		want := []string{"       pi", "D:50% 50.0/100.0G"}
+		if len(disp.shown) != 2 || disp.shown[0] != want[0] || disp.shown[1] != want[1] {
+			t.Errorf("system page: got %q, want %q", disp.shown, want)
+		}
+		if err := r.RefreshPage(4, testStats); err != nil {
+			t.Fatalf("RefreshPage(4) failed: %v", err)
+		}
+		if len(disp.shown) != 2 || disp.shown[0] != "eth0" || disp.shown[1] != "192.168.1.100" {
+			t.Errorf("network page: got %q", disp.shown)
+		}
+	})
+
+	t.Run("20x4", func(t *testing.T) {
+		disp := newTextDisplay(20, 4)
+		r := NewRenderer(disp, config.Default())
+		r.BuildPages(testStats)
+
+		// System, load and one page for both interfaces
+		if got := r.PageCount(); got != 3 {
+			t.Fatalf("expected 3 pages, got %d", got)
+		}
+		if err := r.RenderPage(0, testStats); err != nil {
+			t.Fatalf("RenderPage(0) failed: %v", err)
+		}
+		want := []string{"         pi", "Disk 50% 50.0/100.0G", "RAM 50% 2.0/4.0G", "CPU 45.5°C"}
+		if len(disp.shown) != len(want) {
+			t.Fatalf("system page: got %q, want %q", disp.shown, want)
+		}
+		for i := range want {
+			if disp.shown[i] != want[i] {
+				t.Errorf("system page row %d: got %q, want %q", i, disp.shown[i], want[i])
+			}
+		}
+
+		alert := NewAlertPage(0)
+		alert.SetMessages([]string{"CPU hot"})
+		if err := r.RenderTransient(alert, testStats); err != nil {
+			t.Fatalf("RenderTransient() failed: %v", err)
+		}
+		if len(disp.shown) != 2 || disp.shown[1] != "CPU hot" {
+			t.Errorf("alert page: got %q", disp.shown)
+		}
+	})
+}
+
+func TestMessagePageTextLines(t *testing.T) {
+	page, err := NewMessagePage("Backup finished without errors", "", ColorGreen)
+	if err != nil {
+		t.Fatalf("NewMessagePage() failed: %v", err)
+	}
+	got := page.TextLines(&stats.SystemStats{}, 16, 2)
+	want := []string{"Backup finished", "without errors"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != " "+want[1] {
+		t.Errorf("TextLines() = %q, want %q centred", got, want)
+	}
+}
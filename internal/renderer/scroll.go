@@ -0,0 +1,88 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// scrollInterval is how long each screenful of a page with more rows than
+// fit is shown before the next
+const scrollInterval = 3 * time.Second
+
+// rowScroller shows the rows of a page with more rows than fit a screenful
+// at a time, moving on every scrollInterval and starting over after the
+// last, so no row is dropped
+type rowScroller struct {
+	start   time.Time // when the page was rendered
+	visible int       // rows that fit on the display
+	first   int       // index of the first row shown
+	screen  int       // screenful shown, from 0
+	screens int       // screenfuls needed for all the rows
+}
+
+// reset starts again from the first row, with room for visible rows
+func (sc *rowScroller) reset(now time.Time, visible int) {
+	sc.start = now
+	sc.visible = visible
+	sc.first, sc.screen, sc.screens = 0, 0, 1
+}
+
+// advance moves to the screenful due at now for a page of total rows
+func (sc *rowScroller) advance(now time.Time, total int) {
+	sc.screens = 1
+	if sc.visible > 0 && total > sc.visible {
+		sc.screens = (total + sc.visible - 1) / sc.visible
+	}
+	sc.screen = int(now.Sub(sc.start)/scrollInterval) % sc.screens
+	sc.first = sc.screen * sc.visible
+}
+
+// row returns the index of the row shown on content row i
+func (sc *rowScroller) row(i int) int {
+	return sc.first + i
+}
+
+// scrollIndicatorWidget shows which screenful of a scrolling page is on the
+// display, e.g. "1/2", in the footer's right corner
+type scrollIndicatorWidget struct {
+	sc     *rowScroller
+	layout *Layout
+	last   string
+}
+
+func (w *scrollIndicatorWidget) draw(disp display.Display, s *stats.SystemStats, force bool) (bool, error) {
+	var text string
+	if w.sc.screens > 1 {
+		text = fmt.Sprintf("%d/%d", w.sc.screen+1, w.sc.screens)
+	}
+	if !force && text == w.last {
+		return false, nil
+	}
+	previous := w.last
+	w.last = text
+
+	if !force && previous != "" {
+		width := MeasureText(previous)
+		x := w.layout.Width - width - MarginRight
+		if err := display.AsColorDisplay(disp).FillRectColor(x, w.layout.FooterY, width, ScaledTextHeight(w.layout.TextScale), color.Black); err != nil {
+			return false, err
+		}
+	}
+	if text == "" {
+		return !force && previous != "", nil
+	}
+	x := w.layout.Width - MeasureText(text) - MarginRight
+	return true, DrawTextColorScaled(disp, x, w.layout.FooterY, text, ColorGreen, w.layout.TextScale)
+}
+
+// addScrollIndicator adds the scroll position to the footer of layouts that
+// have one
+func (ws *widgetSet) addScrollIndicator(sc *rowScroller, layout *Layout) {
+	if layout.FooterY >= 0 {
+		ws.add(&scrollIndicatorWidget{sc: sc, layout: layout}, 0)
+	}
+}
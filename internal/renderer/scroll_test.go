@@ -0,0 +1,88 @@
+package renderer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestRowScroller(t *testing.T) {
+	start := time.Now()
+	var sc rowScroller
+	sc.reset(start, 3)
+
+	tests := []struct {
+		elapsed       time.Duration
+		total         int
+		first         int
+		screen, outOf int
+	}{
+		{0, 2, 0, 0, 1},
+		{10 * scrollInterval, 3, 0, 0, 1},
+		{0, 7, 0, 0, 3},
+		{scrollInterval, 7, 3, 1, 3},
+		{2*scrollInterval + time.Second, 7, 6, 2, 3},
+		{3 * scrollInterval, 7, 0, 0, 3},
+	}
+	for _, tt := range tests {
+		sc.advance(start.Add(tt.elapsed), tt.total)
+		if sc.first != tt.first || sc.screen != tt.screen || sc.screens != tt.outOf {
+			t.Errorf("after %v with %d rows: first=%d screen=%d/%d, want first=%d screen=%d/%d",
+				tt.elapsed, tt.total, sc.first, sc.screen, sc.screens, tt.first, tt.screen, tt.outOf)
+		}
+	}
+}
+
+func TestExecPageScrollsLongOutput(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	p := NewExecPage("Jails", 0)
+	s := &stats.SystemStats{Exec: map[string]stats.ExecOutput{
+		"Jails": {Lines: []string{"sshd: 3", "nginx: 0", "postfix: 1", "dovecot: 2"}},
+	}}
+
+	if err := p.Render(disp, s); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if text, _ := p.row(s, p.scroll.row(0)); text != "sshd: 3" {
+		t.Errorf("expected the first screenful shown first, got %q", text)
+	}
+
+	changed, err := p.update(disp, s, p.scroll.start.Add(scrollInterval))
+	if err != nil || !changed {
+		t.Fatalf("expected the next screenful drawn, got changed=%v err=%v", changed, err)
+	}
+	if text, _ := p.row(s, p.scroll.row(0)); text != "dovecot: 2" {
+		t.Errorf("expected the fourth line at the top of the second screenful, got %q", text)
+	}
+	if p.scroll.screens != 2 {
+		t.Errorf("expected 2 screenfuls, got %d", p.scroll.screens)
+	}
+}
+
+func TestBuildPagesSpreadsInterfacesOverPages(t *testing.T) {
+	cfg := config.Default()
+	cfg.Display.Lines = 2
+	r := NewRenderer(display.NewMockDisplay(128, 32), cfg)
+	r.BuildPages(&stats.SystemStats{
+		Hostname: "testhost",
+		Interfaces: []stats.NetInterface{
+			{Name: "eth0", IPv4Addrs: []string{"10.0.0.2"}},
+			{Name: "wlan0", IPv4Addrs: []string{"10.0.1.2"}},
+			{Name: "wg0", IPv4Addrs: []string{"10.0.2.2"}},
+		},
+	})
+
+	// A 128x32 display has one content row, so each interface gets a page
+	var network int
+	for i := range r.PageCount() {
+		if r.PageType(i) == config.PageNetwork {
+			network++
+		}
+	}
+	if network != 3 {
+		t.Errorf("expected 3 network pages, got %d", network)
+	}
+}
@@ -0,0 +1,100 @@
+package renderer
+
+import (
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// ShutdownPage shows the thermal shutdown countdown. It is rendered in place
+// of normal rotation and alerts while a critical temperature shutdown is pending.
+type ShutdownPage struct {
+	remaining time.Duration
+	threshold float64
+	unit      tempUnit
+	lines     int
+}
+
+// NewShutdownPage creates a shutdown countdown page
+func NewShutdownPage(lines int, threshold float64) *ShutdownPage {
+	return &ShutdownPage{lines: lines, threshold: threshold}
+}
+
+// SetRemaining sets the time left before the shutdown command runs
+func (p *ShutdownPage) SetRemaining(d time.Duration) {
+	p.remaining = d
+}
+
+// SetTemperatureUnit sets the unit the temperature is collected and shown
+// in, system_info.temperature_unit
+func (p *ShutdownPage) SetTemperatureUnit(unit string) {
+	p.unit = tempUnit{from: unit, to: unit}
+}
+
+// shownThreshold returns the threshold, configured in Celsius, in the unit
+// the temperature is shown in
+func (p *ShutdownPage) shownThreshold() float64 {
+	return stats.FromCelsius(p.threshold, p.unit.to)
+}
+
+// Title returns the page title
+func (p *ShutdownPage) Title() string {
+	return "Shutdown"
+}
+
+// Render draws a bordered countdown with the current and critical temperature
+func (p *ShutdownPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+
+	if err := disp.DrawRect(0, 0, bounds.Dx(), bounds.Dy(), false); err != nil {
+		return err
+	}
+
+	if layout.ShowHeader {
+		if err := DrawTextCenteredColorScaled(disp, layout.HeaderY, "! OVERHEAT !", ColorRed, layout.TextScale); err != nil {
+			return err
+		}
+	}
+
+	var countdown string
+	if p.remaining > 0 {
+		countdown = sprintf("Shutdown in %ds", int(p.remaining.Round(time.Second).Seconds()))
+	} else {
+		countdown = tr("Shutting down...")
+	}
+
+	symbol := p.unit.symbol(layout.TextScale)
+	lines := []string{
+		countdown,
+		sprintf("%.1f%s >= %.0f%s", s.CPUTemp, symbol, p.shownThreshold(), symbol),
+	}
+	for i, line := range lines {
+		if i >= len(layout.ContentLines) {
+			break
+		}
+		if err := DrawTextCenteredColorScaled(disp, layout.ContentLines[i], line, ColorRed, layout.TextScale); err != nil {
+			return err
+		}
+	}
+
+	return disp.Show()
+}
+
+// TextLines shows the countdown and temperatures under a banner
+func (p *ShutdownPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	countdown := tr("Shutting down...")
+	if p.remaining > 0 {
+		countdown = sprintf("Shutdown in %ds", int(p.remaining.Round(time.Second).Seconds()))
+	}
+	return []string{
+		centerText("! OVERHEAT !", cols),
+		centerText(countdown, cols),
+		centerText(sprintf("%.1f%s >= %.0f%s", s.CPUTemp, p.unit.textSymbol(), p.shownThreshold(), p.unit.textSymbol()), cols),
+	}
+}
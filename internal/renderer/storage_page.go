@@ -0,0 +1,170 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// Storage page texts shown before the first read and without flash storage
+const (
+	storageWaitingText = "Reading..."
+	storageNoneText    = "No SD or eMMC"
+)
+
+// StoragePage shows each SD card and eMMC device with its wear: the life
+// time estimate and reserved block state eMMC devices report, or the
+// manufacturing date of SD cards, which report no wear. Wear is coloured by
+// the warn and critical thresholds.
+type StoragePage struct {
+	warn, critical int // percent of rated life used; 0 disables the level
+	lines          int // configured line count (0=auto, 2=default, 4=compact)
+	intervals      map[string]time.Duration
+	widgets        widgetSet
+}
+
+// NewStoragePage creates a storage page with the given wear thresholds
+func NewStoragePage(warn, critical, lines int) *StoragePage {
+	return &StoragePage{warn: warn, critical: critical, lines: lines}
+}
+
+// Title returns the page title
+func (p *StoragePage) Title() string {
+	return "Storage"
+}
+
+// SetRefreshIntervals sets how often the rows are refreshed, keyed by data
+// source (see config.PagesConfig.RefreshIntervals)
+func (p *StoragePage) SetRefreshIntervals(intervals map[string]time.Duration) {
+	p.intervals = intervals
+}
+
+// Render draws the devices under the page title. A single content row shows
+// each device on one line.
+func (p *StoragePage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	maxWidth := bounds.Dx() - 2*MarginLeft
+
+	if err := drawPageHeader(disp, layout, tr(p.Title())); err != nil {
+		return err
+	}
+
+	compact := len(layout.ContentLines) < 2
+	p.widgets.reset()
+	interval := p.intervals[config.SourceStorage]
+	for row, y := range layout.ContentLines {
+		p.widgets.add(&lineWidget{
+			x:     MarginLeft,
+			y:     y,
+			scale: layout.TextScale,
+			content: func(s *stats.SystemStats) []textSpan {
+				rows := p.rows(s, compact)
+				if row >= len(rows) {
+					return nil
+				}
+				if layout.TextScale > 0 && layout.TextScale < 1 {
+					return span(TruncateTextSmall(rows[row].text, maxWidth), rows[row].c)
+				}
+				return span(TruncateText(rows[row].text, maxWidth), rows[row].c)
+			},
+		}, interval)
+	}
+	if err := p.widgets.render(disp, s, time.Now()); err != nil {
+		return err
+	}
+
+	return disp.Show()
+}
+
+// update redraws the rows that are due and changed
+func (p *StoragePage) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	return p.widgets.update(disp, s, now)
+}
+
+// rows returns the page's rows: per device its name, then its wear and
+// reserved blocks for eMMC or its manufacturing date for SD cards. Compact
+// rows fit each device on one line.
+func (p *StoragePage) rows(s *stats.SystemStats, compact bool) []textSpan {
+	switch {
+	case s.Flash == nil:
+		return []textSpan{{storageWaitingText, ColorYellow}}
+	case len(s.Flash) == 0:
+		return []textSpan{{storageNoneText, ColorGreen}}
+	}
+
+	var rows []textSpan
+	for _, f := range s.Flash {
+		kind := f.Type
+		if kind == "MMC" {
+			kind = "eMMC"
+		}
+		if compact {
+			if f.LifeUsed > 0 {
+				rows = append(rows, textSpan{f.Device + " wear " + lifeText(f.LifeUsed), p.color(f)})
+			} else {
+				rows = append(rows, textSpan{fmt.Sprintf("%s %s %s", f.Device, kind, f.Date), ColorGreen})
+			}
+			continue
+		}
+
+		rows = append(rows, textSpan{fmt.Sprintf("%s %s %s", f.Device, kind, f.Name), ColorGreen})
+		if f.LifeUsed > 0 {
+			rows = append(rows, textSpan{"Wear " + lifeText(f.LifeUsed), p.color(f)})
+		} else if f.Date != "" {
+			rows = append(rows, textSpan{"Made " + f.Date, ColorGreen})
+		}
+		switch f.PreEOL {
+		case stats.PreEOLNormal:
+			rows = append(rows, textSpan{"Reserve normal", ColorGreen})
+		case stats.PreEOLWarning:
+			rows = append(rows, textSpan{"Reserve 80% used", ColorYellow})
+		case stats.PreEOLUrgent:
+			rows = append(rows, textSpan{"Reserve 90% used", ColorRed})
+		}
+	}
+	return rows
+}
+
+// lifeText formats a life time estimate as the range of life used
+func lifeText(used int) string {
+	if used > 100 {
+		return "exceeded"
+	}
+	return fmt.Sprintf("%d-%d%%", used-10, used)
+}
+
+// color returns the colour of a device's wear: red from the critical
+// threshold, once past its rated life or with its reserve nearly gone,
+// yellow from the warn threshold or with its reserve running low
+func (p *StoragePage) color(f stats.FlashInfo) color.NRGBA {
+	switch {
+	case f.LifeUsed > 100 || f.PreEOL == stats.PreEOLUrgent ||
+		(p.critical > 0 && f.LifeUsed >= p.critical):
+		return ColorRed
+	case f.PreEOL == stats.PreEOLWarning || (p.warn > 0 && f.LifeUsed >= p.warn):
+		return ColorYellow
+	default:
+		return ColorGreen
+	}
+}
+
+// TextLines shows the title above as many rows as fit
+func (p *StoragePage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	lines := []string{centerText(tr(p.Title()), cols)}
+	for i, row := range p.rows(s, rows < 3) {
+		if i >= rows-1 {
+			break
+		}
+		lines = append(lines, row.text)
+	}
+	return lines
+}
@@ -0,0 +1,101 @@
+package renderer
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestStoragePageRows(t *testing.T) {
+	page := NewStoragePage(70, 90, 0)
+	s := &stats.SystemStats{Hostname: "pi"}
+	if rows := page.rows(s, false); len(rows) != 1 || rows[0].text != storageWaitingText {
+		t.Errorf("before the first read: got %v", rows)
+	}
+	s.Flash = []stats.FlashInfo{}
+	if rows := page.rows(s, false); len(rows) != 1 || rows[0].text != storageNoneText {
+		t.Errorf("without flash storage: got %v", rows)
+	}
+
+	s.Flash = []stats.FlashInfo{
+		{Device: "mmcblk0", Type: "MMC", Name: "8GTF4R", Date: "06/2021", LifeUsed: 80, PreEOL: stats.PreEOLNormal},
+		{Device: "mmcblk1", Type: "SD", Name: "SC32G", Date: "01/2019"},
+	}
+	want := []textSpan{
+		{"mmcblk0 eMMC 8GTF4R", ColorGreen},
+		{"Wear 70-80%", ColorYellow},
+		{"Reserve normal", ColorGreen},
+		{"mmcblk1 SD SC32G", ColorGreen},
+		{"Made 01/2019", ColorGreen},
+	}
+	if rows := page.rows(s, false); !spansEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+	want = []textSpan{
+		{"mmcblk0 wear 70-80%", ColorYellow},
+		{"mmcblk1 SD 01/2019", ColorGreen},
+	}
+	if rows := page.rows(s, true); !spansEqual(rows, want) {
+		t.Errorf("compact rows = %v, want %v", rows, want)
+	}
+
+	if err := page.Render(display.NewOffscreenDisplay(128, 64), s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if err := page.Render(display.NewOffscreenDisplay(128, 32), s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+}
+
+func TestStoragePageColor(t *testing.T) {
+	page := NewStoragePage(70, 90, 0)
+	tests := []struct {
+		f    stats.FlashInfo
+		want color.NRGBA
+	}{
+		{stats.FlashInfo{LifeUsed: 10}, ColorGreen},
+		{stats.FlashInfo{LifeUsed: 70}, ColorYellow},
+		{stats.FlashInfo{LifeUsed: 90}, ColorRed},
+		{stats.FlashInfo{LifeUsed: 110}, ColorRed},
+		{stats.FlashInfo{LifeUsed: 10, PreEOL: stats.PreEOLWarning}, ColorYellow},
+		{stats.FlashInfo{LifeUsed: 10, PreEOL: stats.PreEOLUrgent}, ColorRed},
+	}
+	for _, tt := range tests {
+		if got := page.color(tt.f); got != tt.want {
+			t.Errorf("%+v: got %v, want %v", tt.f, got, tt.want)
+		}
+	}
+
+	// A zero threshold disables that level
+	if c := NewStoragePage(0, 0, 0).color(stats.FlashInfo{LifeUsed: 100}); c != ColorGreen {
+		t.Errorf("without thresholds: got %v, want green", c)
+	}
+	if got := lifeText(110); got != "exceeded" {
+		t.Errorf("lifeText(110) = %q", got)
+	}
+}
+
+func TestBuildPagesStorage(t *testing.T) {
+	cfg := config.Default()
+	r := NewRenderer(display.NewOffscreenDisplay(128, 64), cfg)
+	r.BuildPages(&stats.SystemStats{Hostname: "pi"})
+	for i := 0; i < r.PageCount(); i++ {
+		if r.PageType(i) == config.PageStorage {
+			t.Fatal("expected no storage page by default")
+		}
+	}
+
+	cfg.Pages.Storage.Enabled = true
+	r = NewRenderer(display.NewOffscreenDisplay(128, 64), cfg)
+	r.BuildPages(&stats.SystemStats{Hostname: "pi"})
+	found := false
+	for i := 0; i < r.PageCount(); i++ {
+		found = found || r.PageType(i) == config.PageStorage
+	}
+	if !found {
+		t.Error("expected a storage page when enabled")
+	}
+}
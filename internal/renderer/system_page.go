@@ -2,9 +2,10 @@ package renderer
 
 import (
 	"fmt"
-	"image"
 	"image/color"
+	"time"
 
+	"github.com/ausil/i2c-display/internal/config"
 	"github.com/ausil/i2c-display/internal/display"
 	"github.com/ausil/i2c-display/internal/stats"
 )
@@ -19,10 +20,18 @@ const (
 	SystemMetricCPU
 )
 
+// diskReadOnlyText replaces the hostname header while the disk is read-only
+const diskReadOnlyText = "DISK READ-ONLY"
+
 // SystemPage displays system statistics (disk, RAM, CPU temp)
 type SystemPage struct {
 	metricType SystemMetricType
-	lines      int // configured line count (0=auto, 2=default, 4=compact)
+	lines      int                      // configured line count (0=auto, 2=default, 4=compact)
+	intervals  map[string]time.Duration // per-source widget refresh intervals, see SetRefreshIntervals
+	updates    bool                     // show pending updates in the footer, see updates.enabled
+	unit       tempUnit                 // unit the CPU temperature is shown in
+	bytes      byteFormat               // units of the disk and memory sizes
+	widgets    widgetSet
 }
 
 // NewSystemPage creates a new system stats page showing all metrics
@@ -35,6 +44,13 @@ func NewSystemPageForMetric(metricType SystemMetricType, lines int) *SystemPage
 	return &SystemPage{metricType: metricType, lines: lines}
 }
 
+// SetRefreshIntervals sets how often each metric line is refreshed, keyed by
+// data source (see config.PagesConfig.RefreshIntervals). Sources without an
+// interval are checked on every refresh.
+func (p *SystemPage) SetRefreshIntervals(intervals map[string]time.Duration) {
+	p.intervals = intervals
+}
+
 // Title returns the page title
 func (p *SystemPage) Title() string {
 	switch p.metricType {
@@ -50,8 +66,6 @@ func (p *SystemPage) Title() string {
 }
 
 // Render draws the system stats page
-//
-//nolint:gocyclo,funlen // rendering logic naturally has many conditional branches for different display sizes
 func (p *SystemPage) Render(disp display.Display, s *stats.SystemStats) error {
 	// Clear display
 	if err := disp.Clear(); err != nil {
@@ -59,156 +73,324 @@ func (p *SystemPage) Render(disp display.Display, s *stats.SystemStats) error {
 	}
 
 	// Create adaptive layout
-	bounds := disp.GetBounds()
-	layout := NewLayout(bounds, p.lines)
-	maxWidth := bounds.Dx() - 2*MarginLeft
+	layout := NewLayout(disp.GetBounds(), p.lines)
 
-	// Optional: Hostname header (green on colour displays)
-	if layout.ShowHeader {
-		if err := DrawTextCenteredColorScaled(disp, layout.HeaderY, s.Hostname, ColorGreen, layout.TextScale); err != nil {
-			return err
-		}
+	// Optional: Hostname header (green on colour displays) and separator
+	if err := drawPageHeader(disp, layout, s.Hostname); err != nil {
+		return err
 	}
 
-	// Optional: Separator
-	if layout.ShowSeparator {
-		if err := DrawLine(disp, layout.SeparatorY); err != nil {
-			return err
-		}
+	p.buildWidgets(layout, display.AsColorDisplay(disp).Capabilities().Color())
+	if layout.ShowHeader {
+		p.widgets.add(&readOnlyBannerWidget{layout: layout}, p.intervals[config.SourceDisk])
+	}
+	if err := p.widgets.render(disp, s, time.Now()); err != nil {
+		return err
 	}
 
-	// Render content based on display size, metric type, and text scale.
+	// Show the display
+	return disp.Show()
+}
+
+// update redraws the metric lines that are due
+func (p *SystemPage) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	return p.widgets.update(disp, s, now)
+}
+
+// buildWidgets lays out one widget per metric line for the display size,
+// metric type and text scale. Large colour displays show the CPU
+// temperature as a dial.
+//
+//nolint:gocyclo,funlen // layout logic naturally has many conditional branches for different display sizes
+func (p *SystemPage) buildWidgets(layout *Layout, colorDisplay bool) {
+	p.widgets.reset()
+	maxWidth := layout.Width - 2*MarginLeft
+
+	diskInterval := p.intervals[config.SourceDisk]
+	memInterval := p.intervals[config.SourceMemory]
+	tempInterval := p.intervals[config.SourceTemperature]
+
 	if layout.TextScale > 0 && layout.TextScale < 1 {
 		// Scaled mode (128x32 acting as 128x64): text-only compact rows, no icons.
-		type scaledLine struct {
-			text string
-			c    color.NRGBA
-		}
-		slines := []scaledLine{
-			{
-				TruncateTextSmall(fmt.Sprintf("D:%.0f%% %.1f/%.1fG",
-					s.DiskPercent(), s.DiskUsedGB(), s.DiskTotalGB()), maxWidth),
-				MetricColor(s.DiskPercent()),
-			},
-			{
-				TruncateTextSmall(fmt.Sprintf("R:%.0f%% %.1f/%.1fG",
-					s.MemoryPercent(), s.MemoryUsedGB(), s.MemoryTotalGB()), maxWidth),
-				MetricColor(s.MemoryPercent()),
-			},
-		}
-		if s.CPUTemp > 0 {
-			slines = append(slines, scaledLine{
-				TruncateTextSmall(fmt.Sprintf("C:%.1fC", s.CPUTemp), maxWidth),
-				TempColor(s.CPUTemp),
-			})
-		} else {
-			slines = append(slines, scaledLine{"C:N/A", ColorGreen})
+		rows := []struct {
+			content  func(s *stats.SystemStats) []textSpan
+			interval time.Duration
+		}{
+			{func(s *stats.SystemStats) []textSpan {
+				return span(TruncateTextSmall(sprintf("D:%.0f%% ", s.DiskPercent())+
+					p.bytes.usage(s.DiskUsed, s.DiskTotal, true), maxWidth),
+					diskColor(s))
+			}, diskInterval},
+			{func(s *stats.SystemStats) []textSpan {
+				return span(TruncateTextSmall(sprintf("R:%.0f%% ", s.MemoryPercent())+
+					p.bytes.usage(s.MemoryUsed, s.MemoryTotal, true), maxWidth),
+					memoryLevels.color(s.MemoryPercent()))
+			}, memInterval},
+			{func(s *stats.SystemStats) []textSpan {
+				if s.CPUTemp > 0 {
+					return span(TruncateTextSmall(sprintf("C:%.1f%s", p.unit.value(s.CPUTemp), p.unit.symbol(layout.TextScale))+fanText(s, true), maxWidth), TempColor(p.unit.celsius(s.CPUTemp)))
+				}
+				return span("C:"+tr("N/A")+fanText(s, true), ColorGreen)
+			}, tempInterval},
 		}
-		for i, sl := range slines {
+		for i, row := range rows {
 			if i >= len(layout.ContentLines) {
 				break
 			}
-			if err := DrawTextColorScaled(disp, MarginLeft, layout.ContentLines[i], sl.text, sl.c, layout.TextScale); err != nil {
-				return err
-			}
+			p.widgets.add(&lineWidget{x: MarginLeft, y: layout.ContentLines[i], scale: layout.TextScale, content: row.content}, row.interval)
 		}
-	} else if layout.Height <= 32 && p.metricType == SystemMetricAll {
-		// Compact all-in-one view: each segment in its own colour
-		if len(layout.ContentLines) > 0 {
-			y := layout.ContentLines[0]
-			x := MarginLeft
+		return
+	}
+
+	if len(layout.ContentLines) == 0 {
+		return
+	}
 
+	if layout.Height <= 32 && p.metricType == SystemMetricAll {
+		// Compact all-in-one view: each segment in its own colour, refreshed
+		// as often as its fastest source
+		interval := minInterval(diskInterval, memInterval, tempInterval)
+		p.widgets.add(&lineWidget{x: MarginLeft, y: layout.ContentLines[0], content: func(s *stats.SystemStats) []textSpan {
 			diskPct := s.DiskPercent()
 			memPct := s.MemoryPercent()
-
-			diskSeg := fmt.Sprintf("D:%.0f%%", diskPct)
-			memSeg := fmt.Sprintf(" R:%.0f%%", memPct)
-
-			if err := DrawTextColor(disp, x, y, diskSeg, MetricColor(diskPct)); err != nil {
-				return err
-			}
-			x += MeasureText(diskSeg)
-
-			if err := DrawTextColor(disp, x, y, memSeg, MetricColor(memPct)); err != nil {
-				return err
+			spans := []textSpan{
+				{fmt.Sprintf("D:%.0f%%", diskPct), diskColor(s)},
+				{fmt.Sprintf(" R:%.0f%%", memPct), memoryLevels.color(memPct)},
 			}
-			x += MeasureText(memSeg)
-
 			if s.CPUTemp > 0 {
-				cpuSeg := fmt.Sprintf(" C:%.0fC", s.CPUTemp)
-				if err := DrawTextColor(disp, x, y, cpuSeg, TempColor(s.CPUTemp)); err != nil {
-					return err
-				}
+				spans = append(spans, textSpan{fmt.Sprintf(" C:%.0f%s", p.unit.value(s.CPUTemp), p.unit.symbol(0)), TempColor(p.unit.celsius(s.CPUTemp))})
 			}
+			if fan := fanText(s, true); fan != "" {
+				spans = append(spans, textSpan{fan, ColorGreen})
+			}
+			return spans
+		}}, interval)
+		return
+	}
+
+	// Icon + coloured text for each metric, with a bar gauge beside disk
+	// and memory usage when the line has room
+	initIcons()
+	iconMaxWidth := maxWidth - IconWidth - IconGap
+	bar := barWidth(layout)
+	usageMaxWidth := iconMaxWidth
+	if bar > 0 {
+		usageMaxWidth -= bar + barGap
+	}
+
+	disk := &lineWidget{icon: iconDisk, content: func(s *stats.SystemStats) []textSpan {
+		text := sprintf("%.1f%% (%s)", s.DiskPercent(), p.bytes.usage(s.DiskUsed, s.DiskTotal, false))
+		if layout.Height <= 32 {
+			text = p.bytes.usage(s.DiskUsed, s.DiskTotal, true)
+		}
+		return span(TruncateText(text+inodeText(s), usageMaxWidth), diskColor(s))
+	}}
+	memory := &lineWidget{icon: iconMemory, content: func(s *stats.SystemStats) []textSpan {
+		text := sprintf("%.1f%% (%s)", s.MemoryPercent(), p.bytes.usage(s.MemoryUsed, s.MemoryTotal, false))
+		if layout.Height <= 32 {
+			text = p.bytes.usage(s.MemoryUsed, s.MemoryTotal, true)
 		}
-	} else if layout.Height <= 32 {
-		// Small display, individual metric page: icon + coloured text
-		initIcons()
-		iconMaxWidth := maxWidth - IconWidth - IconGap
-		var icon *image.Gray
-		var text string
-		var c color.NRGBA
+		return span(TruncateText(text, usageMaxWidth), memoryLevels.color(s.MemoryPercent()))
+	}}
+	cpu := &lineWidget{icon: iconCPU, content: func(s *stats.SystemStats) []textSpan {
+		if s.CPUTemp > 0 {
+			return span(TruncateText(sprintf("%.1f%s", p.unit.value(s.CPUTemp), p.unit.symbol(0))+fanText(s, false), iconMaxWidth), TempColor(p.unit.celsius(s.CPUTemp)))
+		}
+		return span(tr("N/A")+fanText(s, false), ColorGreen)
+	}}
+
+	type row struct {
+		w        *lineWidget
+		interval time.Duration
+		usage    func(s *stats.SystemStats) float64 // percent shown as a bar; nil for none
+		levels   levels                             // colours of the bar
+	}
+	diskRow := row{disk, diskInterval, (*stats.SystemStats).DiskPercent, diskLevels}
+	memoryRow := row{memory, memInterval, (*stats.SystemStats).MemoryPercent, memoryLevels}
+	cpuRow := row{cpu, tempInterval, nil, levels{}}
+	var rows []row
+	if layout.Height <= 32 {
+		// Small display, individual metric page
 		switch p.metricType {
 		case SystemMetricDisk:
-			icon = iconDisk
-			text = fmt.Sprintf("%.1f/%.1fG", s.DiskUsedGB(), s.DiskTotalGB())
-			c = MetricColor(s.DiskPercent())
+			rows = []row{diskRow}
 		case SystemMetricMemory:
-			icon = iconMemory
-			text = fmt.Sprintf("%.1f/%.1fG", s.MemoryUsedGB(), s.MemoryTotalGB())
-			c = MetricColor(s.MemoryPercent())
+			rows = []row{memoryRow}
 		case SystemMetricCPU:
-			icon = iconCPU
-			if s.CPUTemp > 0 {
-				text = fmt.Sprintf("%.1fC", s.CPUTemp)
-				c = TempColor(s.CPUTemp)
-			} else {
-				text = "N/A"
-				c = ColorGreen
-			}
-		}
-		text = TruncateText(text, iconMaxWidth)
-		if len(layout.ContentLines) > 0 {
-			if err := DrawIconTextColor(disp, MarginLeft, layout.ContentLines[0], icon, text, c); err != nil {
-				return err
-			}
+			rows = []row{cpuRow}
 		}
 	} else {
-		// Standard display: icon + coloured text for each metric
-		initIcons()
-		iconMaxWidth := maxWidth - IconWidth - IconGap
-
-		type iconLine struct {
-			icon  *image.Gray
-			text  string
-			color color.NRGBA
-		}
-		lines := []iconLine{
-			{iconDisk, fmt.Sprintf("%.1f%% (%.1f/%.1fGB)",
-				s.DiskPercent(), s.DiskUsedGB(), s.DiskTotalGB()),
-				MetricColor(s.DiskPercent())},
-			{iconMemory, fmt.Sprintf("%.1f%% (%.1f/%.1fGB)",
-				s.MemoryPercent(), s.MemoryUsedGB(), s.MemoryTotalGB()),
-				MetricColor(s.MemoryPercent())},
+		rows = []row{diskRow, memoryRow, cpuRow}
+	}
+	footer := p.updates && layout.FooterY >= 0
+	gaugeArea := layout.GaugeArea
+	if footer {
+		gaugeArea.Max.Y = min(gaugeArea.Max.Y, layout.FooterY)
+	}
+	if colorDisplay && p.metricType == SystemMetricAll && gaugeRadius(gaugeArea) > 0 {
+		rows = rows[:2]
+		p.widgets.add(&tempGaugeWidget{area: gaugeArea, unit: p.unit}, tempInterval)
+	}
+	for i, r := range rows {
+		if i >= len(layout.ContentLines) {
+			break
 		}
-		if s.CPUTemp > 0 {
-			lines = append(lines, iconLine{iconCPU, fmt.Sprintf("%.1fC", s.CPUTemp),
-				TempColor(s.CPUTemp)})
-		} else {
-			lines = append(lines, iconLine{iconCPU, "N/A", ColorGreen})
+		r.w.x, r.w.y = MarginLeft, layout.ContentLines[i]
+		if r.usage == nil || bar == 0 {
+			p.widgets.add(r.w, r.interval)
+			continue
 		}
+		// Keep line redraws clear of the bar
+		r.w.width = IconWidth + IconGap + usageMaxWidth
+		p.widgets.add(r.w, r.interval)
+		p.widgets.add(&barWidget{
+			x: layout.Width - MarginRight - bar, y: r.w.y + 1,
+			w: bar, h: IconHeight - 2,
+			value: r.usage, levels: r.levels,
+		}, r.interval)
+	}
 
-		for i, line := range lines {
-			if i >= len(layout.ContentLines) {
-				break
-			}
-			text := TruncateText(line.text, iconMaxWidth)
-			if err := DrawIconTextColor(disp, MarginLeft, layout.ContentLines[i], line.icon, text, line.color); err != nil {
-				return err
+	if footer {
+		// Checked on every refresh; the result only changes once per check
+		p.widgets.add(&lineWidget{x: MarginLeft, y: layout.FooterY, content: func(s *stats.SystemStats) []textSpan {
+			text, c := updatesText(s)
+			if text == "" {
+				return nil
 			}
-		}
+			return span(TruncateText(text, maxWidth), c)
+		}}, 0)
 	}
+}
 
-	// Show the display
-	return disp.Show()
+// TextLines shows the hostname above the page's metrics, one per row
+func (p *SystemPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	diskUsage := p.bytes.usage(s.DiskUsed, s.DiskTotal, true)
+	disk := fitText(
+		sprintf(tr("Disk")+" %.0f%% ", s.DiskPercent())+diskUsage+inodeText(s),
+		sprintf("D:%.0f%% ", s.DiskPercent())+diskUsage,
+		cols)
+	memoryUsage := p.bytes.usage(s.MemoryUsed, s.MemoryTotal, true)
+	memory := fitText(
+		sprintf("RAM %.0f%% ", s.MemoryPercent())+memoryUsage,
+		sprintf("R:%.0f%% ", s.MemoryPercent())+memoryUsage,
+		cols)
+	cpu := "CPU " + tr("N/A")
+	if s.CPUTemp > 0 {
+		cpu = sprintf("CPU %.1f%s", p.unit.value(s.CPUTemp), p.unit.textSymbol())
+	}
+	cpu = fitText(cpu+fanText(s, false), cpu+fanText(s, true), cols)
+
+	header := s.Hostname
+	if s.DiskReadOnly {
+		header = tr(diskReadOnlyText)
+	}
+	lines := []string{centerText(header, cols)}
+	switch p.metricType {
+	case SystemMetricDisk:
+		return append(lines, disk)
+	case SystemMetricMemory:
+		return append(lines, memory)
+	case SystemMetricCPU:
+		return append(lines, cpu)
+	}
+	lines = append(lines, disk, memory, cpu)
+	if text, _ := updatesText(s); p.updates && text != "" {
+		lines = append(lines, text)
+	}
+	return lines
+}
+
+// updatesText returns the footer for the latest update check: a pending
+// reboot in red, pending updates in yellow, or "" before the first check
+func updatesText(s *stats.SystemStats) (string, color.NRGBA) {
+	u := s.Updates
+	switch {
+	case u == nil:
+		return "", ColorGreen
+	case u.RebootRequired && u.Count > 0:
+		return sprintf("Reboot, %d upd", u.Count), ColorRed
+	case u.RebootRequired:
+		return tr("Reboot required"), ColorRed
+	case u.Count == 1:
+		return tr("1 update"), ColorYellow
+	case u.Count > 1:
+		return sprintf("%d updates", u.Count), ColorYellow
+	case u.Err != nil:
+		return tr("Updates: error"), ColorYellow
+	default:
+		return tr("Up to date"), ColorGreen
+	}
+}
+
+// fanText formats the fan speed to follow the CPU temperature, or returns
+// "" when fan control is disabled
+func fanText(s *stats.SystemStats, compact bool) string {
+	if s.Fan == nil {
+		return ""
+	}
+	switch {
+	case compact && s.Fan.Duty == 0:
+		return " F:off"
+	case compact:
+		return fmt.Sprintf(" F:%d%%", s.Fan.Duty)
+	case s.Fan.Duty == 0:
+		return " fan off"
+	default:
+		return fmt.Sprintf(" fan %d%%", s.Fan.Duty)
+	}
+}
+
+// diskColor returns the colour of the disk metric: red while the filesystem
+// is read-only, otherwise by space or inode usage, whichever is higher
+func diskColor(s *stats.SystemStats) color.NRGBA {
+	if s.DiskReadOnly {
+		return ColorRed
+	}
+	return diskLevels.color(max(s.DiskPercent(), s.DiskInodePercent()))
+}
+
+// inodeText formats inode usage to follow the disk space, or returns "" while
+// it is below the warning level, so it only appears when it matters
+func inodeText(s *stats.SystemStats) string {
+	if s.DiskInodePercent() < diskLevels.warn {
+		return ""
+	}
+	return fmt.Sprintf(" i:%.0f%%", s.DiskInodePercent())
+}
+
+// readOnlyBannerWidget replaces the hostname header with a red warning while
+// the disk is mounted read-only, often the first sign of a failing SD card,
+// and puts the hostname back if it recovers
+type readOnlyBannerWidget struct {
+	layout *Layout
+	last   bool
+}
+
+func (w *readOnlyBannerWidget) draw(disp display.Display, s *stats.SystemStats, force bool) (bool, error) {
+	if !force && s.DiskReadOnly == w.last {
+		return false, nil
+	}
+	w.last = s.DiskReadOnly
+	if force && !s.DiskReadOnly {
+		return false, nil // drawPageHeader has drawn the hostname
+	}
+
+	text, c := s.Hostname, ColorGreen
+	if s.DiskReadOnly {
+		text, c = tr(diskReadOnlyText), ColorRed
+	}
+	height := ScaledTextHeight(w.layout.TextScale)
+	if err := display.AsColorDisplay(disp).FillRectColor(0, w.layout.HeaderY, w.layout.Width, height, color.Black); err != nil {
+		return false, err
+	}
+	if err := DrawTextCenteredColorScaled(disp, w.layout.HeaderY, text, c, w.layout.TextScale); err != nil {
+		return false, err
+	}
+	// The text box can reach the separator
+	if w.layout.ShowSeparator {
+		if err := DrawLine(disp, w.layout.SeparatorY); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
 }
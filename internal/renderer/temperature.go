@@ -0,0 +1,69 @@
+package renderer
+
+import (
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// tempUnit converts temperatures from the unit they are collected in,
+// system_info.temperature_unit, to the unit a page shows them in. The zero
+// value shows Celsius readings as Celsius.
+type tempUnit struct {
+	from string // unit of the readings in stats.SystemStats
+	to   string // unit shown
+}
+
+// value converts a reading to the shown unit
+func (u tempUnit) value(v float64) float64 {
+	if u.from == u.to {
+		return v
+	}
+	return stats.FromCelsius(stats.ToCelsius(v, u.from), u.to)
+}
+
+// celsius converts a reading to Celsius, the scale TempColor grades
+func (u tempUnit) celsius(v float64) float64 {
+	return stats.ToCelsius(v, u.from)
+}
+
+// symbol returns the shown unit's symbol for text drawn at scale: with a
+// degree sign when the font has one, which takes a fallback font, and the
+// bare letter otherwise
+func (u tempUnit) symbol(scale float64) string {
+	if _, ok := faceForScale(scale).GlyphAdvance('°'); ok {
+		return u.textSymbol()
+	}
+	return u.letter()
+}
+
+// textSymbol returns the shown unit's symbol for character displays, which
+// all have a degree sign
+func (u tempUnit) textSymbol() string {
+	if u.to == config.UnitKelvin {
+		return "K"
+	}
+	return "°" + u.letter()
+}
+
+// letter returns the shown unit's letter
+func (u tempUnit) letter() string {
+	switch u.to {
+	case config.UnitFahrenheit:
+		return "F"
+	case config.UnitKelvin:
+		return "K"
+	default:
+		return "C"
+	}
+}
+
+// temperatureUnit returns the conversion for a page type, following
+// pages.temperature_units and falling back to system_info.temperature_unit
+func (r *Renderer) temperatureUnit(pageType string) tempUnit {
+	from := r.config.SystemInfo.TemperatureUnit
+	to, ok := r.config.Pages.TemperatureUnits[pageType]
+	if !ok {
+		to = from
+	}
+	return tempUnit{from: from, to: to}
+}
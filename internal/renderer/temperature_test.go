@@ -0,0 +1,71 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestPageTemperatureUnits(t *testing.T) {
+	cfg := config.Default()
+	cfg.SystemInfo.TemperatureUnit = config.UnitFahrenheit
+	cfg.Pages.TemperatureUnits = map[string]string{config.PageTemperatures: config.UnitKelvin}
+	rend := NewRenderer(display.NewMockDisplay(128, 64), cfg)
+
+	// Readings arrive in the configured unit: 113°F is 45°C
+	s := &stats.SystemStats{
+		Hostname:     "testhost",
+		CPUTemp:      113,
+		Temperatures: []stats.TempReading{{Name: "GPU", Value: 113}},
+	}
+	rend.BuildPages(s)
+
+	var system *SystemPage
+	var temps *TemperaturesPage
+	for _, p := range rend.GetPages() {
+		switch p := p.(type) {
+		case *SystemPage:
+			system = p
+		case *TemperaturesPage:
+			temps = p
+		}
+	}
+	if system == nil || temps == nil {
+		t.Fatal("expected system and temperatures pages")
+	}
+
+	if got := system.TextLines(s, 20, 4)[3]; got != "CPU 113.0°F" {
+		t.Errorf("system page temperature = %q, want the configured unit", got)
+	}
+	if got := temps.TextLines(s, 20, 4)[1]; got != "GPU: 318.1K" {
+		t.Errorf("temperatures page reading = %q, want the override", got)
+	}
+	if c := TempColor(temps.unit.celsius(113)); c != ColorGreen {
+		t.Errorf("45°C graded %v, want green", c)
+	}
+}
+
+func TestTemperatureSymbol(t *testing.T) {
+	t.Cleanup(func() { _ = LoadFont("") })
+	celsius := tempUnit{}
+	kelvin := tempUnit{from: config.UnitCelsius, to: config.UnitKelvin}
+
+	// The built-in fonts have no degree sign
+	if got := celsius.symbol(0); got != "C" {
+		t.Errorf("symbol() = %q without a fallback font, want C", got)
+	}
+	if err := LoadFont(config.FontGo); err != nil {
+		t.Fatal(err)
+	}
+	if got := celsius.symbol(0); got != "°C" {
+		t.Errorf("symbol() = %q with a fallback font, want °C", got)
+	}
+	if got := celsius.symbol(0.5); got != "°C" {
+		t.Errorf("small symbol() = %q with a fallback font, want °C", got)
+	}
+	if got := kelvin.symbol(0); got != "K" {
+		t.Errorf("kelvin symbol() = %q, want K", got)
+	}
+}
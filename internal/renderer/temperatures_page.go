@@ -0,0 +1,101 @@
+package renderer
+
+import (
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// TemperaturesPage lists named temperature sensors (e.g. CPU, GPU, NVMe).
+// When there are more sensors than fit they are shown a screenful at a time.
+type TemperaturesPage struct {
+	lines   int      // configured line count (0=auto, 2=default, 4=compact)
+	unit    tempUnit // unit the readings are shown in
+	scroll  rowScroller
+	widgets widgetSet
+}
+
+// NewTemperaturesPage creates a new temperatures page
+func NewTemperaturesPage(lines int) *TemperaturesPage {
+	return &TemperaturesPage{lines: lines}
+}
+
+// Title returns the page title
+func (p *TemperaturesPage) Title() string {
+	return "Temperatures"
+}
+
+// Render draws one sensor per content line, coloured by temperature
+func (p *TemperaturesPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	maxWidth := bounds.Dx() - 2*MarginLeft
+
+	if err := drawPageHeader(disp, layout, s.Hostname); err != nil {
+		return err
+	}
+
+	// One widget per content row, coloured by its sensor's temperature
+	now := time.Now()
+	p.scroll.reset(now, len(layout.ContentLines))
+	p.scroll.advance(now, len(s.Temperatures))
+	p.widgets.reset()
+	small := layout.TextScale > 0 && layout.TextScale < 1
+	for row, y := range layout.ContentLines {
+		p.widgets.add(&lineWidget{
+			x:     MarginLeft,
+			y:     y,
+			scale: layout.TextScale,
+			content: func(s *stats.SystemStats) []textSpan {
+				i := p.scroll.row(row)
+				if i >= len(s.Temperatures) {
+					return nil
+				}
+				reading := s.Temperatures[i]
+				value, symbol := p.unit.value(reading.Value), p.unit.symbol(layout.TextScale)
+				var text string
+				if layout.Height <= 32 {
+					text = sprintf("%s:%.1f%s", reading.Name, value, symbol)
+				} else {
+					text = sprintf("%s: %.1f%s", reading.Name, value, symbol)
+				}
+				if small {
+					text = TruncateTextSmall(text, maxWidth)
+				} else {
+					text = TruncateText(text, maxWidth)
+				}
+				return span(text, TempColor(p.unit.celsius(reading.Value)))
+			},
+		}, 0)
+	}
+	p.widgets.addScrollIndicator(&p.scroll, layout)
+	if err := p.widgets.render(disp, s, now); err != nil {
+		return err
+	}
+
+	return disp.Show()
+}
+
+// update redraws the readings that changed or scrolled
+func (p *TemperaturesPage) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	p.scroll.advance(now, len(s.Temperatures))
+	return p.widgets.update(disp, s, now)
+}
+
+// TextLines lists one sensor per row, under the hostname when every sensor
+// still fits
+func (p *TemperaturesPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	var lines []string
+	if len(s.Temperatures) < rows {
+		lines = append(lines, centerText(s.Hostname, cols))
+	}
+	for _, reading := range s.Temperatures {
+		lines = append(lines, sprintf("%s: %.1f%s", reading.Name, p.unit.value(reading.Value), p.unit.textSymbol()))
+	}
+	return lines
+}
@@ -0,0 +1,191 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/pagetemplate"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// templateDefaultColor colours template lines without a colour of their own
+var templateDefaultColor = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+
+// TemplatePage shows a pages.templates page: each row is a Go template
+// executed against the stats, coloured by the first of its rules that
+// holds. A row whose template fails shows the error in red. Pages with more
+// lines than fit are shown a screenful at a time.
+type TemplatePage struct {
+	title   string
+	lines   int // configured line count (0=auto, 2=default, 4=compact)
+	rows    []templateRow
+	err     error // why the page config could not be used; shown in its place
+	scroll  rowScroller
+	widgets widgetSet
+}
+
+// templateRow is a parsed template line
+type templateRow struct {
+	text  *template.Template
+	color color.NRGBA
+	rules []templateColorRule
+}
+
+// templateColorRule is a parsed colour rule
+type templateColorRule struct {
+	when  *template.Template
+	color color.NRGBA
+}
+
+// NewTemplatePage creates a page from its config. Templates are checked when
+// the config is loaded; a colour that does not parse is reported on the page.
+func NewTemplatePage(cfg config.TemplatePageConfig, lines int) *TemplatePage {
+	p := &TemplatePage{title: cfg.Title, lines: lines}
+	p.rows, p.err = parseTemplateRows(cfg.Lines)
+	return p
+}
+
+// parseTemplateRows parses the lines of a template page
+func parseTemplateRows(lines []config.TemplateLine) ([]templateRow, error) {
+	rows := make([]templateRow, 0, len(lines))
+	for i, line := range lines {
+		text, err := pagetemplate.Parse("text", line.Text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		row := templateRow{text: text, color: templateDefaultColor}
+		if line.Color != "" {
+			if row.color, err = ParseColor(line.Color); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+		for _, rule := range line.Colors {
+			when, err := pagetemplate.Parse("when", rule.When)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			c, err := ParseColor(rule.Color)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			row.rules = append(row.rules, templateColorRule{when: when, color: c})
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Title returns the page title
+func (p *TemplatePage) Title() string {
+	return p.title
+}
+
+// Render draws a row per template line, as many as fit
+func (p *TemplatePage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	maxWidth := bounds.Dx() - 2*MarginLeft
+
+	if err := drawPageHeader(disp, layout, p.title); err != nil {
+		return err
+	}
+
+	// One widget per row, so only rows whose text or colour changed are
+	// redrawn
+	now := time.Now()
+	p.scroll.reset(now, len(layout.ContentLines))
+	p.scroll.advance(now, p.rowCount())
+	p.widgets.reset()
+	for row, y := range layout.ContentLines {
+		p.widgets.add(&lineWidget{
+			x:     MarginLeft,
+			y:     y,
+			scale: layout.TextScale,
+			content: func(s *stats.SystemStats) []textSpan {
+				text, c := p.row(s, p.scroll.row(row))
+				if text == "" {
+					return nil
+				}
+				if layout.TextScale > 0 && layout.TextScale < 1 {
+					return span(TruncateTextSmall(text, maxWidth), c)
+				}
+				return span(TruncateText(text, maxWidth), c)
+			},
+		}, 0)
+	}
+	p.widgets.addScrollIndicator(&p.scroll, layout)
+	if err := p.widgets.render(disp, s, now); err != nil {
+		return err
+	}
+
+	return disp.Show()
+}
+
+// update redraws the rows whose text or colour changed or that scrolled
+func (p *TemplatePage) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	p.scroll.advance(now, p.rowCount())
+	return p.widgets.update(disp, s, now)
+}
+
+// rowCount returns how many rows the page has
+func (p *TemplatePage) rowCount() int {
+	if p.err != nil {
+		return 1
+	}
+	return len(p.rows)
+}
+
+// row returns the text and colour of content row i: its template's first
+// line of output
+func (p *TemplatePage) row(s *stats.SystemStats, i int) (string, color.NRGBA) {
+	switch {
+	case p.err != nil:
+		if i == 0 {
+			return p.err.Error(), ColorRed
+		}
+		return "", ColorRed
+	case i >= len(p.rows):
+		return "", templateDefaultColor
+	}
+
+	r := p.rows[i]
+	text, err := executeTemplate(r.text, s)
+	if err != nil {
+		return err.Error(), ColorRed
+	}
+	for _, rule := range r.rules {
+		if holds, err := executeTemplate(rule.when, s); err == nil && strings.TrimSpace(holds) == "true" {
+			return text, rule.color
+		}
+	}
+	return text, r.color
+}
+
+// executeTemplate runs t against the stats and returns its first line
+func executeTemplate(t *template.Template, s *stats.SystemStats) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, s); err != nil {
+		return "", err
+	}
+	text, _, _ := strings.Cut(b.String(), "\n")
+	return strings.TrimRight(text, " \t\r"), nil
+}
+
+// TextLines shows the title above as many rows as fit
+func (p *TemplatePage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	lines := []string{centerText(p.title, cols)}
+	for i := range rows - 1 {
+		text, _ := p.row(s, i)
+		lines = append(lines, text)
+	}
+	return lines
+}
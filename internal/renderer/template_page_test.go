@@ -0,0 +1,76 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestTemplatePageRows(t *testing.T) {
+	p := NewTemplatePage(config.TemplatePageConfig{
+		Title: "Summary",
+		Lines: []config.TemplateLine{
+			{Text: "{{.Hostname}}\nignored"},
+			{
+				Text:  `CPU {{printf "%.0f" .CPUTemp}}C`,
+				Color: "green",
+				Colors: []config.TemplateColorRule{
+					{When: "{{gt .CPUTemp 70.0}}", Color: "red"},
+					{When: "{{gt .CPUTemp 55.0}}", Color: "yellow"},
+				},
+			},
+			{Text: "{{.Missing}}"},
+		},
+	}, 0)
+
+	s := &stats.SystemStats{Hostname: "pi", CPUTemp: 48}
+	if text, c := p.row(s, 0); text != "pi" || c != templateDefaultColor {
+		t.Errorf("row 0 = %q, want %q in white", text, "pi")
+	}
+	if text, c := p.row(s, 1); text != "CPU 48C" || c != ColorGreen {
+		t.Errorf("row 1 = %q, want %q in green", text, "CPU 48C")
+	}
+	s.CPUTemp = 60
+	if _, c := p.row(s, 1); c != ColorYellow {
+		t.Errorf("expected the first matching rule's colour, got %v", c)
+	}
+	s.CPUTemp = 75
+	if _, c := p.row(s, 1); c != ColorRed {
+		t.Errorf("expected the first matching rule's colour, got %v", c)
+	}
+	if text, c := p.row(s, 2); !strings.Contains(text, "Missing") || c != ColorRed {
+		t.Errorf("row 2 = %q, want the execution error in red", text)
+	}
+	if text, _ := p.row(s, 3); text != "" {
+		t.Errorf("expected row 3 empty, got %q", text)
+	}
+}
+
+func TestTemplatePageBadColor(t *testing.T) {
+	p := NewTemplatePage(config.TemplatePageConfig{
+		Title: "Summary",
+		Lines: []config.TemplateLine{{Text: "{{.Hostname}}", Color: "mauve"}},
+	}, 0)
+	if text, c := p.row(&stats.SystemStats{}, 0); !strings.HasPrefix(text, "line 1: ") || c != ColorRed {
+		t.Errorf("row 0 = %q, want the colour error in red", text)
+	}
+}
+
+func TestBuildPagesAddsTemplatePages(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.Templates = []config.TemplatePageConfig{{Title: "Summary", Lines: []config.TemplateLine{{Text: "{{.Hostname}}"}}}}
+
+	disp := display.NewMockDisplay(128, 64)
+	r := NewRenderer(disp, cfg)
+	r.BuildPages(&stats.SystemStats{Hostname: "testhost"})
+	last := r.PageCount() - 1
+	if got := r.PageType(last); got != config.PageTemplate {
+		t.Fatalf("PageType(%d) = %q, want %q", last, got, config.PageTemplate)
+	}
+	if err := r.RenderPage(last, &stats.SystemStats{Hostname: "testhost"}); err != nil {
+		t.Errorf("RenderPage failed: %v", err)
+	}
+}
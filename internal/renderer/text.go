@@ -1,13 +1,11 @@
 package renderer
 
 import (
-	"image"
 	"image/color"
 
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/basicfont"
-	"golang.org/x/image/math/fixed"
 
+	"github.com/ausil/i2c-display/internal/config"
 	"github.com/ausil/i2c-display/internal/display"
 )
 
@@ -19,81 +17,118 @@ var (
 	ColorRed    = color.NRGBA{R: 255, G: 0, B: 0, A: 255}
 )
 
-// MetricColor returns green/yellow/red based on a usage percentage.
-// 0-60% → green, 60-85% → yellow, >85% → red.
-func MetricColor(percent float64) color.NRGBA {
+// levels are the values at which a metric turns yellow, at or above warn,
+// and red, above crit
+type levels struct {
+	warn, crit float64
+}
+
+// color returns green, yellow or red for a value
+func (l levels) color(v float64) color.NRGBA {
 	switch {
-	case percent > 85:
+	case v > l.crit:
 		return ColorRed
-	case percent >= 60:
+	case v >= l.warn:
 		return ColorYellow
 	default:
 		return ColorGreen
 	}
 }
 
+// Default levels of usage percentages, Celsius temperatures and load
+// averages per CPU
+var (
+	defaultUsageLevels = levels{warn: 60, crit: 85}
+	defaultTempLevels  = levels{warn: 55, crit: 75}
+	defaultLoadLevels  = levels{warn: 0.7, crit: 1.0}
+)
+
+// Levels each metric is coloured by, set from the config by SetThresholds
+var (
+	diskLevels   = defaultUsageLevels
+	memoryLevels = defaultUsageLevels
+	cpuLevels    = defaultUsageLevels
+	tempLevels   = defaultTempLevels // in Celsius
+	loadLevels   = defaultLoadLevels // per CPU
+)
+
+// SetThresholds sets the levels at which metrics turn yellow and red from
+// the thresholds config, whose temperatures are in Celsius. Metrics left unset
+// go back to their defaults. Like SetLocale it applies to every renderer and
+// is meant to be called before pages are built.
+func SetThresholds(t config.ThresholdsConfig) {
+	usage := func(l config.LevelsConfig) levels {
+		if !l.IsSet() {
+			return defaultUsageLevels
+		}
+		return levels{warn: l.Warn, crit: l.Crit}
+	}
+	diskLevels, memoryLevels, cpuLevels = usage(t.Disk), usage(t.Memory), usage(t.CPU)
+	loadLevels = defaultLoadLevels
+	if t.Load.IsSet() {
+		loadLevels = levels{warn: t.Load.Warn, crit: t.Load.Crit}
+	}
+	tempLevels = defaultTempLevels
+	if t.Temperature.IsSet() {
+		tempLevels = levels{warn: t.Temperature.Warn, crit: t.Temperature.Crit}
+	}
+}
+
+// MetricColor returns green/yellow/red based on a usage percentage at the
+// default levels: 0-60% → green, 60-85% → yellow, >85% → red.
+func MetricColor(percent float64) color.NRGBA {
+	return defaultUsageLevels.color(percent)
+}
+
 // TempColor returns green/yellow/red based on CPU temperature in Celsius.
-// <55C → green, 55-75C → yellow, >75C → red.
+// By default <55C → green, 55-75C → yellow, >75C → red; see SetThresholds.
 func TempColor(celsius float64) color.NRGBA {
-	switch {
-	case celsius > 75:
-		return ColorRed
-	case celsius >= 55:
-		return ColorYellow
-	default:
-		return ColorGreen
-	}
+	return tempLevels.color(celsius)
 }
 
 // LoadColor returns green/yellow/red based on load average per CPU core.
-// loadAvg/numCPU < 0.7 → green, 0.7–1.0 → yellow, > 1.0 → red.
+// By default loadAvg/numCPU < 0.7 → green, 0.7–1.0 → yellow, > 1.0 → red;
+// see SetThresholds.
 func LoadColor(loadAvg float64, numCPU int) color.NRGBA {
 	if numCPU <= 0 {
 		numCPU = 1
 	}
-	perCore := loadAvg / float64(numCPU)
-	switch {
-	case perCore > 1.0:
-		return ColorRed
-	case perCore >= 0.7:
-		return ColorYellow
-	default:
-		return ColorGreen
-	}
+	return loadLevels.color(loadAvg / float64(numCPU))
 }
 
 // DrawText renders text at the specified position using a simple bitmap font
 func DrawText(disp display.Display, x, y int, text string) error {
-	// Use basicfont (7x13 font)
-	face := basicfont.Face7x13
-
-	// Measure text to create appropriately sized image
-	width := font.MeasureString(face, text).Ceil()
-	height := face.Metrics().Ascent.Ceil() + face.Metrics().Descent.Ceil()
-
-	// Create an image just large enough for the text
-	textImg := image.NewGray(image.Rect(0, 0, width, height))
-
-	// Create drawer with origin at (0, ascent) in the small image
-	drawer := &font.Drawer{
-		Dst:  textImg,
-		Src:  image.White,
-		Face: face,
-		Dot:  fixed.P(0, face.Metrics().Ascent.Ceil()),
-	}
+	return drawString(disp, x, y, textFace, text, color.White)
+}
 
-	// Draw the text
-	drawer.DrawString(text)
+// drawString renders text with its top-left corner at (x, y). The text box
+// is cleared to black first, matching what drawing an opaque text image
+// does. Rasterized strings come from textCache; displays implementing
+// display.ColorDisplay get the glyph pixels directly, others the cached image.
+func drawString(disp display.Display, x, y int, face font.Face, text string, c color.Color) error {
+	raster := textCache.get(face, text, c)
 
-	// Draw the text image at the specified position
-	return disp.DrawImage(x, y, textImg)
+	cd, ok := disp.(display.ColorDisplay)
+	if !ok {
+		return disp.DrawImage(x, y, raster.img)
+	}
+
+	bounds := raster.img.Bounds()
+	if err := cd.FillRectColor(x, y, bounds.Dx(), bounds.Dy(), color.Black); err != nil {
+		return err
+	}
+	for _, p := range raster.lit {
+		if err := cd.DrawPixelColor(x+p.X, y+p.Y, c); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // DrawTextCentered draws text centered horizontally
 func DrawTextCentered(disp display.Display, y int, text string) error {
 	bounds := disp.GetBounds()
-	face := basicfont.Face7x13
-	width := font.MeasureString(face, text).Ceil()
+	width := font.MeasureString(textFace, text).Ceil()
 	x := (bounds.Dx() - width) / 2
 	return DrawText(disp, x, y, text)
 }
@@ -102,26 +137,13 @@ func DrawTextCentered(disp display.Display, y int, text string) error {
 // On colour displays the colour is preserved; on monochrome displays
 // any bright colour is rendered as white.
 func DrawTextColor(disp display.Display, x, y int, text string, c color.Color) error {
-	face := basicfont.Face7x13
-	width := font.MeasureString(face, text).Ceil()
-	height := face.Metrics().Ascent.Ceil() + face.Metrics().Descent.Ceil()
-
-	textImg := image.NewNRGBA(image.Rect(0, 0, width, height))
-	drawer := &font.Drawer{
-		Dst:  textImg,
-		Src:  &image.Uniform{c},
-		Face: face,
-		Dot:  fixed.P(0, face.Metrics().Ascent.Ceil()),
-	}
-	drawer.DrawString(text)
-	return disp.DrawImage(x, y, textImg)
+	return drawString(disp, x, y, textFace, text, c)
 }
 
 // DrawTextCenteredColor draws coloured text centered horizontally.
 func DrawTextCenteredColor(disp display.Display, y int, text string, c color.Color) error {
 	bounds := disp.GetBounds()
-	face := basicfont.Face7x13
-	width := font.MeasureString(face, text).Ceil()
+	width := font.MeasureString(textFace, text).Ceil()
 	x := (bounds.Dx() - width) / 2
 	return DrawTextColor(disp, x, y, text, c)
 }
@@ -147,109 +169,63 @@ func ScaledTextHeight(scale float64) int {
 // any value in (0,1) uses the compact 5×7 font (Face5x7) directly, which is
 // far more legible than downsampling the larger font.
 func DrawTextColorScaled(disp display.Display, x, y int, text string, c color.Color, scale float64) error {
-	var face font.Face
-	if scale > 0 && scale < 1 {
-		face = Face5x7
-	} else {
-		face = basicfont.Face7x13
-	}
-	width := font.MeasureString(face, text).Ceil()
-	height := face.Metrics().Ascent.Ceil() + face.Metrics().Descent.Ceil()
-
-	textImg := image.NewNRGBA(image.Rect(0, 0, width, height))
-	drawer := &font.Drawer{
-		Dst:  textImg,
-		Src:  &image.Uniform{c},
-		Face: face,
-		Dot:  fixed.P(0, face.Metrics().Ascent.Ceil()),
-	}
-	drawer.DrawString(text)
-	return disp.DrawImage(x, y, textImg)
+	return drawString(disp, x, y, faceForScale(scale), text, c)
 }
 
 // DrawTextCenteredColorScaled draws centred coloured text using the font
 // appropriate for the given scale factor (see DrawTextColorScaled).
 func DrawTextCenteredColorScaled(disp display.Display, y int, text string, c color.Color, scale float64) error {
 	bounds := disp.GetBounds()
-	var face font.Face
-	if scale > 0 && scale < 1 {
-		face = Face5x7
-	} else {
-		face = basicfont.Face7x13
-	}
-	width := font.MeasureString(face, text).Ceil()
+	width := font.MeasureString(faceForScale(scale), text).Ceil()
 	x := (bounds.Dx() - width) / 2
 	return DrawTextColorScaled(disp, x, y, text, c, scale)
 }
 
 // MeasureTextSmall returns the pixel width of text rendered with Face5x7.
 func MeasureTextSmall(text string) int {
-	return font.MeasureString(Face5x7, text).Ceil()
+	return font.MeasureString(smallTextFace, text).Ceil()
 }
 
 // TruncateTextSmall truncates text to fit within maxWidth pixels as measured
 // by Face5x7, appending "..." when truncation occurs.
 func TruncateTextSmall(text string, maxWidth int) string {
-	if MeasureTextSmall(text) <= maxWidth {
-		return text
-	}
-
-	ellipsis := "..."
-	ellipsisWidth := MeasureTextSmall(ellipsis)
-	availableWidth := maxWidth - ellipsisWidth
-
-	left, right := 0, len(text)
-	result := text
-
-	for left < right {
-		mid := (left + right + 1) / 2
-		if MeasureTextSmall(text[:mid]) <= availableWidth {
-			result = text[:mid]
-			left = mid
-		} else {
-			right = mid - 1
-		}
-	}
-
-	if len(result) < len(text) {
-		return result + ellipsis
-	}
-	return text
+	return truncateText(smallTextFace, text, maxWidth)
 }
 
 // MeasureText returns the width of text in pixels
 func MeasureText(text string) int {
-	face := basicfont.Face7x13
-	return font.MeasureString(face, text).Ceil()
+	return font.MeasureString(textFace, text).Ceil()
 }
 
 // TruncateText truncates text to fit within maxWidth, adding "..." if needed
 func TruncateText(text string, maxWidth int) string {
-	if MeasureText(text) <= maxWidth {
+	return truncateText(textFace, text, maxWidth)
+}
+
+// truncateText cuts text to fit within maxWidth pixels in face, appending
+// "..." when it does, or as many of its dots as fit. Text is cut between
+// runes, never inside a multi-byte character.
+func truncateText(face font.Face, text string, maxWidth int) string {
+	if font.MeasureString(face, text).Ceil() <= maxWidth {
 		return text
 	}
 
-	// Binary search for the right length
 	ellipsis := "..."
-	ellipsisWidth := MeasureText(ellipsis)
-	availableWidth := maxWidth - ellipsisWidth
-
-	left, right := 0, len(text)
-	result := text
+	for ellipsis != "" && font.MeasureString(face, ellipsis).Ceil() > maxWidth {
+		ellipsis = ellipsis[1:]
+	}
+	availableWidth := maxWidth - font.MeasureString(face, ellipsis).Ceil()
 
+	// Binary search for the longest prefix that fits
+	runes := []rune(text)
+	left, right := 0, len(runes)
 	for left < right {
 		mid := (left + right + 1) / 2
-		if MeasureText(text[:mid]) <= availableWidth {
-			result = text[:mid]
+		if font.MeasureString(face, string(runes[:mid])).Ceil() <= availableWidth {
 			left = mid
 		} else {
 			right = mid - 1
 		}
 	}
-
-	if len(result) < len(text) {
-		return result + ellipsis
-	}
-
-	return text
+	return string(runes[:left]) + ellipsis
 }
@@ -1,6 +1,13 @@
 package renderer
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
 
 func TestMetricColor(t *testing.T) {
 	tests := []struct {
@@ -114,3 +121,68 @@ func TestTempColor(t *testing.T) {
 		})
 	}
 }
+
+func TestSetThresholds(t *testing.T) {
+	t.Cleanup(func() { SetThresholds(config.ThresholdsConfig{}) })
+
+	// A Pi idling at 60C stays green with raised levels
+	SetThresholds(config.ThresholdsConfig{
+		Disk:        config.LevelsConfig{Warn: 80, Crit: 95},
+		Temperature: config.LevelsConfig{Warn: 70, Crit: 85},
+	})
+	if got := TempColor(60); got != ColorGreen {
+		t.Errorf("TempColor(60) = %v, want green", got)
+	}
+	if got := TempColor(86); got != ColorRed {
+		t.Errorf("TempColor(86) = %v, want red", got)
+	}
+	s := &stats.SystemStats{DiskUsed: 70, DiskTotal: 100, MemoryUsed: 70, MemoryTotal: 100}
+	if got := diskColor(s); got != ColorGreen {
+		t.Errorf("disk at 70%% = %v, want green", got)
+	}
+	if got := memoryLevels.color(s.MemoryPercent()); got != ColorYellow {
+		t.Errorf("memory at 70%% = %v, want the default yellow", got)
+	}
+
+	// Unset levels go back to the defaults
+	SetThresholds(config.ThresholdsConfig{})
+	if got := TempColor(60); got != ColorYellow {
+		t.Errorf("TempColor(60) = %v after reset, want yellow", got)
+	}
+}
+
+// imageOnlyDisplay hides the ColorDisplay methods so text goes through the
+// intermediate image path
+type imageOnlyDisplay struct {
+	display.Display
+}
+
+func TestDrawTextColorDirectMatchesImagePath(t *testing.T) {
+	tests := []struct {
+		name  string
+		scale float64
+	}{
+		{"7x13", 1},
+		{"5x7", 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			direct := display.NewOffscreenDisplay(128, 32)
+			viaImage := display.NewOffscreenDisplay(128, 32)
+			// Pre-fill so clearing of the text box is compared too
+			direct.DrawRect(0, 0, 128, 32, true)
+			viaImage.DrawRect(0, 0, 128, 32, true)
+
+			if err := DrawTextColorScaled(direct, 3, 4, "Disk 42% ~g", ColorYellow, tt.scale); err != nil {
+				t.Fatalf("direct draw failed: %v", err)
+			}
+			if err := DrawTextColorScaled(imageOnlyDisplay{viaImage}, 3, 4, "Disk 42% ~g", ColorYellow, tt.scale); err != nil {
+				t.Fatalf("image draw failed: %v", err)
+			}
+			if !bytes.Equal(direct.GetBuffer(), viaImage.GetBuffer()) {
+				t.Error("direct glyph drawing differs from the image path")
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// topWaitingText is shown until the first process scan finishes
+const topWaitingText = "Scanning..."
+
+// TopPage lists the processes using the most CPU, or the most memory, one
+// per row with its usage in front so the columns line up
+type TopPage struct {
+	memory    bool       // list by memory rather than CPU
+	lines     int        // configured line count (0=auto, 2=default, 4=compact)
+	bytes     byteFormat // units of process memory
+	intervals map[string]time.Duration
+	widgets   widgetSet
+}
+
+// NewTopPage creates a page listing processes by CPU, or by memory when
+// memory is set
+func NewTopPage(memory bool, lines int) *TopPage {
+	return &TopPage{memory: memory, lines: lines}
+}
+
+// Title returns the page title
+func (p *TopPage) Title() string {
+	if p.memory {
+		return "Top memory"
+	}
+	return "Top CPU"
+}
+
+// SetRefreshIntervals sets how often the rows are refreshed, keyed by data
+// source (see config.PagesConfig.RefreshIntervals)
+func (p *TopPage) SetRefreshIntervals(intervals map[string]time.Duration) {
+	p.intervals = intervals
+}
+
+// Render draws the process list under the page title
+func (p *TopPage) Render(disp display.Display, s *stats.SystemStats) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+
+	bounds := disp.GetBounds()
+	layout := NewLayout(bounds, p.lines)
+	maxWidth := bounds.Dx() - 2*MarginLeft
+
+	if err := drawPageHeader(disp, layout, tr(p.Title())); err != nil {
+		return err
+	}
+
+	// One widget per row, refreshed at the process scan's cadence
+	p.widgets.reset()
+	interval := p.intervals[config.SourceProcesses]
+	for row, y := range layout.ContentLines {
+		p.widgets.add(&lineWidget{
+			x:     MarginLeft,
+			y:     y,
+			scale: layout.TextScale,
+			content: func(s *stats.SystemStats) []textSpan {
+				text, c := p.row(s, row)
+				if text == "" {
+					return nil
+				}
+				if layout.TextScale > 0 && layout.TextScale < 1 {
+					return span(TruncateTextSmall(text, maxWidth), c)
+				}
+				return span(TruncateText(text, maxWidth), c)
+			},
+		}, interval)
+	}
+	if err := p.widgets.render(disp, s, time.Now()); err != nil {
+		return err
+	}
+
+	return disp.Show()
+}
+
+// update redraws the rows that are due and changed
+func (p *TopPage) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	return p.widgets.update(disp, s, now)
+}
+
+// row returns the text and colour of process i: its CPU use, or its memory
+// coloured by its share of the total, then its name
+func (p *TopPage) row(s *stats.SystemStats, i int) (string, color.NRGBA) {
+	procs := s.TopCPU
+	if p.memory {
+		procs = s.TopMemory
+	}
+	if procs == nil {
+		if i == 0 {
+			return topWaitingText, ColorYellow
+		}
+		return "", ColorGreen
+	}
+	if i >= len(procs) {
+		return "", ColorGreen
+	}
+
+	proc := procs[i]
+	if !p.memory {
+		return sprintf("%5.1f%% %s", proc.CPU, proc.Name), cpuLevels.color(proc.CPU)
+	}
+	percent := 0.0
+	if s.MemoryTotal > 0 {
+		percent = float64(proc.RSS) / float64(s.MemoryTotal) * 100
+	}
+	return fmt.Sprintf("%5s %s", p.bytes.size(proc.RSS), proc.Name), memoryLevels.color(percent)
+}
+
+// TextLines shows the title above as many processes as fit
+func (p *TopPage) TextLines(s *stats.SystemStats, cols, rows int) []string {
+	lines := []string{centerText(tr(p.Title()), cols)}
+	for i := range rows - 1 {
+		text, _ := p.row(s, i)
+		lines = append(lines, text)
+	}
+	return lines
+}
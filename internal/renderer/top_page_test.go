@@ -0,0 +1,61 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func TestTopPageRows(t *testing.T) {
+	s := &stats.SystemStats{Hostname: "pi", MemoryTotal: 4 << 30}
+	cpu, memory := NewTopPage(false, 0), NewTopPage(true, 0)
+	if text, _ := cpu.row(s, 0); text != topWaitingText {
+		t.Errorf("row 0 = %q, want the waiting text", text)
+	}
+
+	s.TopCPU = []stats.ProcessInfo{{PID: 7, Name: "ffmpeg", CPU: 187.5}, {PID: 1, Name: "systemd", CPU: 0.3}}
+	s.TopMemory = []stats.ProcessInfo{{PID: 9, Name: "java", RSS: 3 << 30}, {PID: 7, Name: "ffmpeg", RSS: 120 << 20}}
+	tests := []struct {
+		page *TopPage
+		row  int
+		want string
+	}{
+		{cpu, 0, "187.5% ffmpeg"},
+		{cpu, 1, "  0.3% systemd"},
+		{cpu, 2, ""},
+		{memory, 0, " 3.0G java"},
+		{memory, 1, " 120M ffmpeg"},
+	}
+	for _, tt := range tests {
+		if text, _ := tt.page.row(s, tt.row); text != tt.want {
+			t.Errorf("%s row %d = %q, want %q", tt.page.Title(), tt.row, text, tt.want)
+		}
+	}
+	// Memory is coloured by its share of the total
+	if _, c := memory.row(s, 0); c != ColorYellow {
+		t.Errorf("expected 75%% of memory in yellow, got %v", c)
+	}
+
+	if err := cpu.Render(display.NewOffscreenDisplay(128, 64), s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+}
+
+func TestBuildPagesTop(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.Top.Count = 5
+	r := NewRenderer(display.NewOffscreenDisplay(128, 64), cfg)
+	r.BuildPages(&stats.SystemStats{Hostname: "pi"})
+
+	var titles []string
+	for i := 0; i < r.PageCount(); i++ {
+		if r.PageType(i) == config.PageTop {
+			titles = append(titles, r.PageTitle(i))
+		}
+	}
+	if len(titles) != 2 || titles[0] != "Top CPU" || titles[1] != "Top memory" {
+		t.Errorf("expected the CPU and memory top pages, got %q", titles)
+	}
+}
@@ -0,0 +1,212 @@
+package renderer
+
+import (
+	"image"
+	"image/draw"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/logger"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// Transition types
+const (
+	TransitionSlideLeft = "slide-left"
+	TransitionFade      = "fade"
+	TransitionWipe      = "wipe"
+)
+
+// transitionFrameInterval caps the animation frame rate so fast buses don't
+// spin the CPU flushing frames nobody can see
+const transitionFrameInterval = 20 * time.Millisecond
+
+// minTransitionFrames is the fewest frames a transition must fit in its
+// duration. If a single flush is slower than duration/minTransitionFrames the
+// bus is too slow to animate and transitions are switched off.
+const minTransitionFrames = 4
+
+// transitioner composites page changes into animated frames. Pages are
+// rendered into offscreen buffers so the outgoing and incoming frames can be
+// mixed before anything reaches the panel.
+type transitioner struct {
+	kind     string
+	duration time.Duration
+	disabled bool // set when the bus proved too slow
+
+	frames    [2]*display.OffscreenDisplay // previous and current page frames
+	current   int                          // index into frames of the last shown frame
+	composite *image.NRGBA
+	lastPage  int // -1 when the last frame did not come from a rotation page
+
+	setBrightness func(level uint8) error
+	brightness    func() uint8
+}
+
+// newTransitioner returns nil when transitions are not configured
+func newTransitioner(kind string, duration time.Duration, bounds image.Rectangle) *transitioner {
+	if duration <= 0 {
+		return nil
+	}
+	return &transitioner{
+		kind:     kind,
+		duration: duration,
+		frames: [2]*display.OffscreenDisplay{
+			display.NewOffscreenDisplay(bounds.Dx(), bounds.Dy()),
+			display.NewOffscreenDisplay(bounds.Dx(), bounds.Dy()),
+		},
+		composite: image.NewNRGBA(bounds),
+		lastPage:  -1,
+	}
+}
+
+// render draws page into the next offscreen frame and shows it on disp,
+// animating from the previous frame when the page index changed
+func (t *transitioner) render(disp display.Display, page Page, pageIdx int, s *stats.SystemStats) error {
+	next := 1 - t.current
+	if err := page.Render(t.frames[next], s); err != nil {
+		return err
+	}
+	from, to := t.frames[t.current].Image(), t.frames[next].Image()
+	changed := t.lastPage >= 0 && t.lastPage != pageIdx
+	t.current, t.lastPage = next, pageIdx
+
+	if !changed || t.disabled {
+		return blit(disp, to)
+	}
+	if t.kind == TransitionFade {
+		return t.fade(disp, to)
+	}
+	return t.animate(disp, from, to)
+}
+
+// reset forgets the last frame so the next page appears without animation,
+// e.g. after an alert or other transient page has been shown
+func (t *transitioner) reset() {
+	t.lastPage = -1
+}
+
+// animate plays a slide or wipe from one frame to the other
+func (t *transitioner) animate(disp display.Display, from, to *image.NRGBA) error {
+	start := time.Now()
+	for first := true; ; first = false {
+		p := float64(time.Since(start)) / float64(t.duration)
+		if p >= 1 {
+			break
+		}
+		t.compose(from, to, p)
+
+		frameStart := time.Now()
+		if err := blit(disp, t.composite); err != nil {
+			return err
+		}
+		elapsed := time.Since(frameStart)
+		if first && t.tooSlow(elapsed) {
+			break
+		}
+		if elapsed < transitionFrameInterval {
+			time.Sleep(transitionFrameInterval - elapsed)
+		}
+	}
+	return blit(disp, to)
+}
+
+// compose writes the frame at progress p (0-1) into t.composite
+func (t *transitioner) compose(from, to *image.NRGBA, p float64) {
+	bounds := from.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	off := int(p * float64(w))
+
+	switch t.kind {
+	case TransitionWipe:
+		// Incoming page is revealed left to right over the outgoing one
+		draw.Draw(t.composite, image.Rect(0, 0, off, h), to, image.Point{}, draw.Src)
+		draw.Draw(t.composite, image.Rect(off, 0, w, h), from, image.Pt(off, 0), draw.Src)
+	default:
+		// Both pages move left together
+		draw.Draw(t.composite, image.Rect(0, 0, w-off, h), from, image.Pt(off, 0), draw.Src)
+		draw.Draw(t.composite, image.Rect(w-off, 0, w, h), to, image.Point{}, draw.Src)
+	}
+}
+
+// fade ramps the brightness down, swaps the page while dark and ramps it
+// back up. Without brightness control, or while the panel is blanked, the
+// page is simply swapped.
+func (t *transitioner) fade(disp display.Display, to *image.NRGBA) error {
+	if t.setBrightness == nil || t.brightness == nil {
+		return blit(disp, to)
+	}
+	level := t.brightness()
+	if level == 0 {
+		return blit(disp, to)
+	}
+
+	swapped := false
+	start := time.Now()
+	for first := true; ; first = false {
+		p := float64(time.Since(start)) / float64(t.duration)
+		if p >= 1 {
+			break
+		}
+
+		frameStart := time.Now()
+		var err error
+		if p < 0.5 {
+			err = t.setBrightness(scaleLevel(level, 1-2*p))
+		} else {
+			if !swapped {
+				if err := blit(disp, to); err != nil {
+					return err
+				}
+				swapped = true
+			}
+			err = t.setBrightness(scaleLevel(level, 2*p-1))
+		}
+		if err != nil {
+			return err
+		}
+		elapsed := time.Since(frameStart)
+		if first && t.tooSlow(elapsed) {
+			break
+		}
+		if elapsed < transitionFrameInterval {
+			time.Sleep(transitionFrameInterval - elapsed)
+		}
+	}
+
+	if !swapped {
+		if err := blit(disp, to); err != nil {
+			return err
+		}
+	}
+	return t.setBrightness(level)
+}
+
+// tooSlow disables transitions when a single frame took too long to flush
+func (t *transitioner) tooSlow(frame time.Duration) bool {
+	if frame <= t.duration/minTransitionFrames {
+		return false
+	}
+	t.disabled = true
+	logger.Global().With().
+		Str("frame_time", frame.String()).
+		Str("duration", t.duration.String()).
+		Logger().Warn("Display bus too slow for page transitions, disabling them")
+	return true
+}
+
+// scaleLevel returns level scaled by frac (0-1)
+func scaleLevel(level uint8, frac float64) uint8 {
+	return uint8(float64(level) * frac) // #nosec G115 -- frac is within [0, 1]
+}
+
+// blit replaces the display contents with img and flushes it
+func blit(disp display.Display, img image.Image) error {
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+	if err := disp.DrawImage(0, 0, img); err != nil {
+		return err
+	}
+	return disp.Show()
+}
@@ -0,0 +1,175 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func solidFrame(c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 128, 64))
+	draw.Draw(img, img.Bounds(), &image.Uniform{c}, image.Point{}, draw.Src)
+	return img
+}
+
+func TestTransitionCompose(t *testing.T) {
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	black := color.NRGBA{A: 255}
+	from, to := solidFrame(white), solidFrame(black)
+
+	tests := []struct {
+		kind        string
+		p           float64
+		boundary    int         // first column of the right-hand frame
+		left, right color.NRGBA // expected colours either side of boundary
+	}{
+		// Slide: outgoing frame moves left, incoming follows from the right
+		{TransitionSlideLeft, 0.5, 64, white, black},
+		{TransitionSlideLeft, 0.25, 96, white, black},
+		// Wipe: incoming frame is revealed from the left edge
+		{TransitionWipe, 0.25, 32, black, white},
+	}
+
+	for _, tt := range tests {
+		tr := newTransitioner(tt.kind, time.Second, from.Bounds())
+		tr.compose(from, to, tt.p)
+
+		if got := tr.composite.NRGBAAt(tt.boundary-1, 10); got != tt.left {
+			t.Errorf("%s at %.2f: column %d = %v, want %v", tt.kind, tt.p, tt.boundary-1, got, tt.left)
+		}
+		if got := tr.composite.NRGBAAt(tt.boundary, 10); got != tt.right {
+			t.Errorf("%s at %.2f: column %d = %v, want %v", tt.kind, tt.p, tt.boundary, got, tt.right)
+		}
+	}
+}
+
+func newTransitionRenderer(t *testing.T, disp display.Display, kind string) (*Renderer, *stats.SystemStats) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Transitions = config.TransitionsConfig{Enabled: true, Type: kind, Duration: "100ms"}
+
+	r := NewRenderer(disp, cfg)
+	s := &stats.SystemStats{
+		Hostname:   "testhost",
+		Interfaces: []stats.NetInterface{{Name: "eth0", IPv4Addrs: []string{"192.168.1.100"}}},
+	}
+	r.BuildPages(s)
+	if r.PageCount() < 2 {
+		t.Fatalf("expected at least 2 pages, got %d", r.PageCount())
+	}
+	return r, s
+}
+
+func TestRenderPageAnimatesPageChange(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	r, s := newTransitionRenderer(t, disp, TransitionSlideLeft)
+
+	if err := r.RenderPage(0, s); err != nil {
+		t.Fatalf("RenderPage(0) failed: %v", err)
+	}
+	if n := countCalls(disp, "Show"); n != 1 {
+		t.Errorf("expected first render to flush once, got %d", n)
+	}
+
+	// Refreshing the same page must not animate
+	disp.ClearCalls()
+	if err := r.RenderPage(0, s); err != nil {
+		t.Fatalf("RenderPage(0) failed: %v", err)
+	}
+	if n := countCalls(disp, "Show"); n != 1 {
+		t.Errorf("expected refresh to flush once, got %d", n)
+	}
+
+	disp.ClearCalls()
+	if err := r.RenderPage(1, s); err != nil {
+		t.Fatalf("RenderPage(1) failed: %v", err)
+	}
+	if n := countCalls(disp, "Show"); n < 3 {
+		t.Errorf("expected page change to flush several frames, got %d", n)
+	}
+
+	// A transient page breaks the chain so the next page appears directly
+	if err := r.RenderTransient(NewAlertPage(0), s); err != nil {
+		t.Fatalf("RenderTransient() failed: %v", err)
+	}
+	disp.ClearCalls()
+	if err := r.RenderPage(0, s); err != nil {
+		t.Fatalf("RenderPage(0) failed: %v", err)
+	}
+	if n := countCalls(disp, "Show"); n != 1 {
+		t.Errorf("expected no animation after a transient page, got %d flushes", n)
+	}
+}
+
+func TestFadeTransitionRestoresBrightness(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	r, s := newTransitionRenderer(t, disp, TransitionFade)
+
+	var levels []uint8
+	r.SetBrightnessControl(func(level uint8) error {
+		levels = append(levels, level)
+		return nil
+	}, func() uint8 { return 200 })
+
+	if err := r.RenderPage(0, s); err != nil {
+		t.Fatalf("RenderPage(0) failed: %v", err)
+	}
+	if err := r.RenderPage(1, s); err != nil {
+		t.Fatalf("RenderPage(1) failed: %v", err)
+	}
+
+	if len(levels) < 3 {
+		t.Fatalf("expected a brightness ramp, got %v", levels)
+	}
+	if levels[len(levels)-1] != 200 {
+		t.Errorf("expected brightness restored to 200, got %d", levels[len(levels)-1])
+	}
+	dimmed := false
+	for _, l := range levels {
+		if l < 100 {
+			dimmed = true
+		}
+	}
+	if !dimmed {
+		t.Errorf("expected fade to dim the display, got %v", levels)
+	}
+}
+
+// slowDisplay simulates a bus that takes a long time to flush a frame
+type slowDisplay struct {
+	*display.MockDisplay
+}
+
+func (d slowDisplay) Show() error {
+	time.Sleep(40 * time.Millisecond)
+	return d.MockDisplay.Show()
+}
+
+func TestTransitionsDisabledOnSlowBus(t *testing.T) {
+	mock := display.NewMockDisplay(128, 64)
+	r, s := newTransitionRenderer(t, slowDisplay{mock}, TransitionWipe)
+
+	if err := r.RenderPage(0, s); err != nil {
+		t.Fatalf("RenderPage(0) failed: %v", err)
+	}
+	if err := r.RenderPage(1, s); err != nil {
+		t.Fatalf("RenderPage(1) failed: %v", err)
+	}
+	if !r.transition.disabled {
+		t.Fatal("expected transitions to be disabled on a slow bus")
+	}
+
+	mock.ClearCalls()
+	if err := r.RenderPage(0, s); err != nil {
+		t.Fatalf("RenderPage(0) failed: %v", err)
+	}
+	if n := countCalls(mock, "Show"); n != 1 {
+		t.Errorf("expected page change without animation, got %d flushes", n)
+	}
+}
@@ -0,0 +1,158 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// widget is a retained element of a page that can be redrawn on its own,
+// without clearing and redrawing the rest of the page
+type widget interface {
+	// draw renders the widget from s. Unless force is set it draws nothing
+	// and returns false when the content is unchanged since the last draw.
+	draw(disp display.Display, s *stats.SystemStats, force bool) (bool, error)
+}
+
+// widgetPage is a page built from widgets. After a full Render, update
+// redraws only the widgets whose refresh interval has elapsed and whose
+// content changed. The caller flushes the display when update reports a change.
+type widgetPage interface {
+	Page
+	update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error)
+}
+
+type scheduledWidget struct {
+	w        widget
+	interval time.Duration // 0 = check on every update
+	next     time.Time
+}
+
+// widgetSet schedules the widgets of a page. Pages rebuild it on every full
+// render since widget positions depend on the display layout.
+type widgetSet struct {
+	items []*scheduledWidget
+}
+
+// reset removes all widgets
+func (ws *widgetSet) reset() {
+	ws.items = ws.items[:0]
+}
+
+// add registers a widget refreshed every interval
+func (ws *widgetSet) add(w widget, interval time.Duration) {
+	ws.items = append(ws.items, &scheduledWidget{w: w, interval: interval})
+}
+
+// render draws every widget and schedules its next refresh
+func (ws *widgetSet) render(disp display.Display, s *stats.SystemStats, now time.Time) error {
+	for _, item := range ws.items {
+		if _, err := item.w.draw(disp, s, true); err != nil {
+			return err
+		}
+		item.next = now.Add(item.interval)
+	}
+	return nil
+}
+
+// update redraws the widgets that are due and reports whether any of them
+// changed the display
+func (ws *widgetSet) update(disp display.Display, s *stats.SystemStats, now time.Time) (bool, error) {
+	changed := false
+	for _, item := range ws.items {
+		if now.Before(item.next) {
+			continue
+		}
+		drawn, err := item.w.draw(disp, s, false)
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || drawn
+		item.next = now.Add(item.interval)
+	}
+	return changed, nil
+}
+
+// textSpan is a run of text in one colour
+type textSpan struct {
+	text string
+	c    color.NRGBA
+}
+
+// lineWidget is a single line of text, optionally preceded by an icon, whose
+// content is computed from the stats. Spans are drawn one after another.
+type lineWidget struct {
+	x, y    int
+	width   int // cleared before redraws; 0 clears to the right edge
+	scale   float64
+	icon    *image.Gray // optional; only the first span is drawn after it
+	content func(s *stats.SystemStats) []textSpan
+	last    []textSpan
+}
+
+func (w *lineWidget) draw(disp display.Display, s *stats.SystemStats, force bool) (bool, error) {
+	spans := w.content(s)
+	if !force && spansEqual(spans, w.last) {
+		return false, nil
+	}
+	w.last = spans
+
+	if !force {
+		// The line may have been longer before; clear its full width
+		width := w.width
+		if width == 0 {
+			width = disp.GetBounds().Dx() - w.x
+		}
+		height := ScaledTextHeight(w.scale)
+		if err := display.AsColorDisplay(disp).FillRectColor(w.x, w.y, width, height, color.Black); err != nil {
+			return false, err
+		}
+	}
+
+	if w.icon != nil && len(spans) > 0 {
+		return true, DrawIconTextColor(disp, w.x, w.y, w.icon, spans[0].text, spans[0].c)
+	}
+	x := w.x
+	for _, span := range spans {
+		if err := DrawTextColorScaled(disp, x, w.y, span.text, span.c, w.scale); err != nil {
+			return false, err
+		}
+		if w.scale > 0 && w.scale < 1 {
+			x += MeasureTextSmall(span.text)
+		} else {
+			x += MeasureText(span.text)
+		}
+	}
+	return true, nil
+}
+
+func spansEqual(a, b []textSpan) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// span returns a single-span line
+func span(text string, c color.NRGBA) []textSpan {
+	return []textSpan{{text: text, c: c}}
+}
+
+// minInterval returns the shortest of the given intervals
+func minInterval(intervals ...time.Duration) time.Duration {
+	shortest := intervals[0]
+	for _, d := range intervals[1:] {
+		if d < shortest {
+			shortest = d
+		}
+	}
+	return shortest
+}
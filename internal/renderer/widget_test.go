@@ -0,0 +1,117 @@
+package renderer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+func countCalls(disp *display.MockDisplay, name string) int {
+	n := 0
+	for _, call := range disp.GetCalls() {
+		if call == name {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRefreshPageRedrawsChangedWidgets(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	r := NewRenderer(disp, config.Default())
+	s := &stats.SystemStats{
+		Hostname:    "testhost",
+		MemoryUsed:  1 << 30,
+		MemoryTotal: 4 << 30,
+		DiskUsed:    10 << 30,
+		DiskTotal:   100 << 30,
+	}
+	r.BuildPages(s)
+
+	if err := r.RefreshPage(0, s); err != nil {
+		t.Fatalf("RefreshPage() failed: %v", err)
+	}
+	if countCalls(disp, "Clear") != 1 || countCalls(disp, "Show") != 1 {
+		t.Fatalf("expected a full render first, got calls %v", disp.GetCalls())
+	}
+
+	// Nothing changed: nothing is drawn or flushed
+	disp.ClearCalls()
+	if err := r.RefreshPage(0, s); err != nil {
+		t.Fatalf("RefreshPage() failed: %v", err)
+	}
+	if calls := disp.GetCalls(); len(calls) != 0 {
+		t.Errorf("expected no display calls for an unchanged page, got %v", calls)
+	}
+
+	// Memory changed: the line is redrawn without clearing the page
+	disp.ClearCalls()
+	s.MemoryUsed = 3 << 30
+	if err := r.RefreshPage(0, s); err != nil {
+		t.Fatalf("RefreshPage() failed: %v", err)
+	}
+	if countCalls(disp, "Clear") != 0 {
+		t.Error("expected a partial update not to clear the display")
+	}
+	if countCalls(disp, "Show") != 1 {
+		t.Errorf("expected the changed line to be flushed once, got %v", disp.GetCalls())
+	}
+}
+
+func TestWidgetRefreshIntervals(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	p := NewSystemPage(0)
+	p.SetRefreshIntervals(map[string]time.Duration{config.SourceDisk: 30 * time.Second})
+	s := &stats.SystemStats{Hostname: "testhost", DiskUsed: 10 << 30, DiskTotal: 100 << 30, MemoryTotal: 4 << 30}
+
+	if err := p.Render(disp, s); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	start := time.Now()
+
+	s.DiskUsed = 50 << 30
+	changed, err := p.update(disp, s, start.Add(5*time.Second))
+	if err != nil {
+		t.Fatalf("update() failed: %v", err)
+	}
+	if changed {
+		t.Error("expected disk line to wait for its 30s interval")
+	}
+
+	changed, err = p.update(disp, s, start.Add(31*time.Second))
+	if err != nil {
+		t.Fatalf("update() failed: %v", err)
+	}
+	if !changed {
+		t.Error("expected disk line to be redrawn once its interval elapsed")
+	}
+}
+
+func TestRefreshTransientClock(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	r := NewRenderer(disp, config.Default())
+	clock := NewClockPage(0)
+	now := time.Date(2026, 3, 14, 9, 26, 5, 0, time.UTC)
+	clock.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if err := r.RefreshTransient(clock, nil); err != nil {
+			t.Fatalf("RefreshTransient() failed: %v", err)
+		}
+		now = now.Add(time.Second)
+	}
+	if n := countCalls(disp, "Show"); n != 1 {
+		t.Errorf("expected the clock to be flushed once within a minute, got %d", n)
+	}
+
+	now = now.Add(time.Minute)
+	if err := r.RefreshTransient(clock, nil); err != nil {
+		t.Fatalf("RefreshTransient() failed: %v", err)
+	}
+	if n := countCalls(disp, "Show"); n != 2 {
+		t.Errorf("expected the clock to be redrawn when the minute changed, got %d flushes", n)
+	}
+}
@@ -3,6 +3,7 @@ package retry
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -12,7 +13,22 @@ type Config struct {
 	InitialDelay    time.Duration // Initial delay between retries
 	MaxDelay        time.Duration // Maximum delay between retries
 	Multiplier      float64       // Backoff multiplier (typically 2.0)
-	RetryableErrors []string      // List of error substrings that trigger retry
+	RetryableErrors []string      // List of error substrings that trigger retry; all errors when empty
+}
+
+// Retryable reports whether err should be retried: when RetryableErrors is
+// set, only errors whose message contains one of them are
+func (cfg Config) Retryable(err error) bool {
+	if len(cfg.RetryableErrors) == 0 {
+		return true
+	}
+	msg := err.Error()
+	for _, s := range cfg.RetryableErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
 }
 
 // DefaultConfig returns a sensible default retry configuration
@@ -28,7 +44,8 @@ func DefaultConfig() Config {
 // Operation is a function that can be retried
 type Operation func() error
 
-// Do executes an operation with exponential backoff retry logic
+// Do executes an operation with exponential backoff retry logic. An error
+// that is not retryable ends it straight away and is returned unwrapped.
 func Do(ctx context.Context, cfg Config, op Operation) error {
 	var lastErr error
 	delay := cfg.InitialDelay
@@ -38,6 +55,11 @@ func Do(ctx context.Context, cfg Config, op Operation) error {
 		if err := op(); err != nil {
 			lastErr = err
 
+			// Errors that are not retryable are returned as they are
+			if !cfg.Retryable(err) {
+				return err
+			}
+
 			// Check if we should retry
 			if attempt >= cfg.MaxAttempts {
 				return fmt.Errorf("operation failed after %d attempts: %w", cfg.MaxAttempts, lastErr)
@@ -85,6 +107,11 @@ func DoWithResult[T any](ctx context.Context, cfg Config, op func() (T, error))
 		if err != nil {
 			lastErr = err
 
+			// Errors that are not retryable are returned as they are
+			if !cfg.Retryable(err) {
+				return result, err
+			}
+
 			// Check if we should retry
 			if attempt >= cfg.MaxAttempts {
 				return result, fmt.Errorf("operation failed after %d attempts: %w", cfg.MaxAttempts, lastErr)
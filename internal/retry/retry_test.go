@@ -71,6 +71,30 @@ func TestDoMaxAttempts(t *testing.T) {
 	}
 }
 
+func TestDoRetryableErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.InitialDelay = time.Millisecond
+	cfg.RetryableErrors = []string{"timed out"}
+	ctx := context.Background()
+
+	callCount := 0
+	permanent := errors.New("no such device")
+	err := Do(ctx, cfg, func() error {
+		callCount++
+		if callCount == 1 {
+			return errors.New("connection timed out")
+		}
+		return permanent
+	})
+
+	if !errors.Is(err, permanent) || err.Error() != permanent.Error() {
+		t.Errorf("expected the permanent error unwrapped, got %v", err)
+	}
+	if callCount != 2 {
+		t.Errorf("expected 2 calls, got %d", callCount)
+	}
+}
+
 func TestDoContextCancellation(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.InitialDelay = 100 * time.Millisecond
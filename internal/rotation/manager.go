@@ -6,26 +6,61 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ausil/i2c-display/internal/alerts"
 	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/fan"
+	"github.com/ausil/i2c-display/internal/health"
 	"github.com/ausil/i2c-display/internal/logger"
 	"github.com/ausil/i2c-display/internal/metrics"
+	"github.com/ausil/i2c-display/internal/netwatch"
 	"github.com/ausil/i2c-display/internal/renderer"
 	"github.com/ausil/i2c-display/internal/stats"
+	"github.com/ausil/i2c-display/internal/thermal"
 )
 
 // Manager handles page rotation and refresh
 type Manager struct {
 	config             *config.Config
-	collector          *stats.SystemCollector
+	collector          stats.Collector
 	renderer           *renderer.Renderer
 	log                *logger.Logger
-	metricsCollector   *metrics.Collector // optional, nil if metrics disabled
+	metricsCollector   *metrics.Collector       // optional, nil if metrics disabled
+	frameCounter       *display.CountingDisplay // optional, source of per-frame render reports
+	reportLog          *logger.Logger           // sampled logger for render reports
+	alertEngine        *alerts.Engine           // optional, nil if alerts disabled
+	alertPage          *renderer.AlertPage
+	alertActive        bool             // true while alerts interrupt normal rotation
+	wakeFunc           func()           // optional, called when an alert with Wake fires
+	alertNotifier      *alerts.Notifier // optional, receives alert transitions
+	healthChecker      *health.Checker  // optional, receives component outcomes
+	thermalMonitor     *thermal.Monitor // optional, nil if thermal shutdown disabled
+	shutdownPage       *renderer.ShutdownPage
+	fanController      *fan.Controller // optional, nil if fan control disabled
+	shutdownActive     bool            // true while the thermal shutdown countdown holds the display
+	clockFunc          func() bool     // optional, reports whether the screensaver clock is showing
+	clockPage          *renderer.ClockPage
+	clockActive        bool                      // true while the screensaver clock replaces rotation
+	messagePage        *renderer.MessagePage     // pushed message; nil when none
+	messageUntil       time.Time                 // when messagePage expires
+	messageActive      bool                      // true while a message replaces rotation
+	calibrationPage    *renderer.CalibrationPage // shown while calibrating; nil otherwise
+	paused             bool                      // true while rotation is paused by Pause or HoldPage
+	holdUntil          time.Time                 // end of a timed HoldPage; zero while paused indefinitely
 	currentPage        int
 	lastInterfaceCount int
-	mu                 sync.Mutex // Protects currentPage and lastInterfaceCount
+	networkChanged     bool       // set by NetworkChanged; pages are rebuilt on the next refresh
+	networkWatched     bool       // true once WatchNetwork subscribed to change notifications
+	mu                 sync.Mutex // Protects currentPage, the network change state and the pause state
+	refreshNow         chan struct{}
+	nextNow            chan struct{} // Next requests, served by the rotation loop
 	stopOnce           sync.Once
-	rotationTicker     *time.Ticker
+	rotationInterval   time.Duration            // default time each page stays on screen
+	minRefresh         time.Duration            // shortest interval between refreshes the display tolerates
+	pageDurations      map[string]time.Duration // per page type overrides of rotationInterval
+	rotationTimer      *time.Timer              // re-armed with the next page's duration on each rotation
 	refreshTicker      *time.Ticker
+	refreshInterval    time.Duration // effective interval between refreshes, set by Start
 	stopChan           chan struct{}
 	stoppedChan        chan struct{}
 }
@@ -36,8 +71,82 @@ func (m *Manager) SetMetrics(c *metrics.Collector) {
 	m.metricsCollector = c
 }
 
-// NewManager creates a new rotation manager
-func NewManager(cfg *config.Config, collector *stats.SystemCollector, rend *renderer.Renderer) *Manager {
+// SetRenderReports logs a debug record of each rendered frame, with the draw
+// calls and flushed bytes counted by counter, which must wrap the renderer's
+// display. Only one frame in every is logged. Must be called before Start.
+func (m *Manager) SetRenderReports(counter *display.CountingDisplay, every int) {
+	m.frameCounter = counter
+	m.reportLog = m.log.Sampled(every)
+}
+
+// SetHealthChecker attaches a health checker. The manager registers the
+// display, collector, renderer and rotation components and records the
+// outcome of each refresh against them. Must be called before Start.
+func (m *Manager) SetHealthChecker(h *health.Checker) {
+	m.healthChecker = h
+	for _, name := range []string{health.ComponentDisplay, health.ComponentCollector, health.ComponentRenderer, health.ComponentRotation} {
+		h.RegisterComponent(name)
+	}
+}
+
+// recordHealth records the outcome of an operation for a health component
+func (m *Manager) recordHealth(component string, err error) {
+	if m.healthChecker == nil {
+		return
+	}
+	if err != nil {
+		m.healthChecker.RecordError(component, err)
+		return
+	}
+	m.healthChecker.RecordSuccess(component)
+}
+
+// SetAlertEngine attaches an alert engine. While any alert is firing the
+// manager shows the alert page instead of rotating. Must be called before Start.
+func (m *Manager) SetAlertEngine(e *alerts.Engine) {
+	m.alertEngine = e
+	m.alertPage = renderer.NewAlertPage(m.renderer.Lines())
+}
+
+// SetAlertNotifier registers a notifier that receives fired and cleared
+// alert transitions. Must be called before Start.
+func (m *Manager) SetAlertNotifier(n *alerts.Notifier) {
+	m.alertNotifier = n
+}
+
+// SetThermalMonitor attaches a thermal shutdown monitor. While its countdown
+// is running the manager shows the shutdown page in place of everything else.
+// Must be called before Start.
+func (m *Manager) SetThermalMonitor(t *thermal.Monitor) {
+	m.thermalMonitor = t
+	m.shutdownPage = renderer.NewShutdownPage(m.renderer.Lines(), t.Threshold())
+	m.shutdownPage.SetTemperatureUnit(m.config.SystemInfo.TemperatureUnit)
+}
+
+// SetFanController attaches a fan controller, fed the CPU temperature on
+// every refresh. Its speed is reported to pages in SystemStats.Fan.
+// Must be called before Start.
+func (m *Manager) SetFanController(f *fan.Controller) {
+	m.fanController = f
+}
+
+// SetWakeFunc registers a function called when an alert configured to wake
+// the display fires (typically ScreenSaver.Wake). Must be called before Start.
+func (m *Manager) SetWakeFunc(fn func()) {
+	m.wakeFunc = fn
+}
+
+// SetClockFunc registers a function reporting whether the screensaver clock
+// should replace normal rotation (typically ScreenSaver.ShowClock). Alerts and
+// thermal shutdown still take precedence. Must be called before Start.
+func (m *Manager) SetClockFunc(fn func() bool) {
+	m.clockFunc = fn
+	m.clockPage = renderer.NewClockPage(m.renderer.Lines())
+}
+
+// NewManager creates a new rotation manager. Collectors implementing
+// stats.TimedCollector also get their per-source read times recorded.
+func NewManager(cfg *config.Config, collector stats.Collector, rend *renderer.Renderer) *Manager {
 	return &Manager{
 		config:             cfg,
 		collector:          collector,
@@ -45,6 +154,8 @@ func NewManager(cfg *config.Config, collector *stats.SystemCollector, rend *rend
 		log:                logger.Global(),
 		currentPage:        0,
 		lastInterfaceCount: -1, // -1 forces a BuildPages on the first refresh
+		refreshNow:         make(chan struct{}, 1),
+		nextNow:            make(chan struct{}, 1),
 		stopChan:           make(chan struct{}),
 		stoppedChan:        make(chan struct{}),
 	}
@@ -63,30 +174,63 @@ func (m *Manager) Start(ctx context.Context) error {
 		return fmt.Errorf("invalid refresh interval: %w", err)
 	}
 
+	pageDurations, err := m.config.Pages.GetPageDurations()
+	if err != nil {
+		return fmt.Errorf("invalid page durations: %w", err)
+	}
+	m.rotationInterval = rotationInterval
+	m.pageDurations = pageDurations
+
+	// Slow panels such as e-paper set a floor on both intervals
+	if m.minRefresh = m.renderer.MinRefreshInterval(); refreshInterval < m.minRefresh {
+		m.log.With().
+			Dur("configured", refreshInterval).
+			Dur("minimum", m.minRefresh).
+			Logger().Info("Display refreshes slowly, raising the refresh interval")
+		refreshInterval = m.minRefresh
+	}
+
 	// Create tickers
-	m.rotationTicker = time.NewTicker(rotationInterval)
+	m.refreshInterval = refreshInterval
 	m.refreshTicker = time.NewTicker(refreshInterval)
 
 	// Initial render
 	if err := m.refreshCurrentPage(); err != nil {
+		m.refreshTicker.Stop()
 		return fmt.Errorf("initial render failed: %w", err)
 	}
 
+	// The first page's duration is known once the initial render built the pages
+	m.rotationTimer = time.NewTimer(m.pageDuration(m.CurrentPage()))
+
 	// Start rotation loop
 	go m.run(ctx)
 
 	return nil
 }
 
+// pageDuration returns how long the page at idx stays on screen
+func (m *Manager) pageDuration(idx int) time.Duration {
+	d, ok := m.pageDurations[m.renderer.PageType(idx)]
+	if !ok {
+		d = m.rotationInterval
+	}
+	return max(d, m.minRefresh)
+}
+
 // run is the main rotation loop
 func (m *Manager) run(ctx context.Context) {
 	defer func() {
 		if r := recover(); r != nil {
 			m.log.Errorf("PANIC in rotation manager: %v", r)
+			if m.healthChecker != nil {
+				// A dead rotation loop never recovers on its own
+				m.healthChecker.MarkUnhealthy(health.ComponentRotation, fmt.Errorf("rotation loop panic: %v", r))
+			}
 		}
 		close(m.stoppedChan)
 	}()
-	defer m.rotationTicker.Stop()
+	defer m.rotationTimer.Stop()
 	defer m.refreshTicker.Stop()
 
 	for {
@@ -95,12 +239,23 @@ func (m *Manager) run(ctx context.Context) {
 			return
 		case <-m.stopChan:
 			return
-		case <-m.rotationTicker.C:
+		case <-m.rotationTimer.C:
 			m.rotatePage()
+			m.rotationTimer.Reset(m.pageDuration(m.CurrentPage()))
 		case <-m.refreshTicker.C:
 			if err := m.refreshCurrentPage(); err != nil {
 				m.log.ErrorWithErr(err, "refresh error")
 			}
+		case <-m.refreshNow:
+			if err := m.refreshCurrentPage(); err != nil {
+				m.log.ErrorWithErr(err, "refresh error")
+			}
+		case <-m.nextNow:
+			m.advancePage()
+			m.rotationTimer.Reset(m.pageDuration(m.CurrentPage()))
+			if err := m.refreshCurrentPage(); err != nil {
+				m.log.ErrorWithErr(err, "refresh error")
+			}
 		}
 	}
 }
@@ -108,21 +263,93 @@ func (m *Manager) run(ctx context.Context) {
 // refreshCurrentPage collects new stats and re-renders the current page
 func (m *Manager) refreshCurrentPage() error {
 	// Collect current stats
+	refreshStart := time.Now()
 	systemStats, err := m.collector.Collect()
+	m.recordHealth(health.ComponentCollector, err)
 	if err != nil {
 		return fmt.Errorf("failed to collect stats: %w", err)
 	}
+	if tc, ok := m.collector.(stats.TimedCollector); ok && m.metricsCollector != nil {
+		for source, d := range tc.LastTimings() {
+			m.metricsCollector.RecordCollectDuration(source, d)
+		}
+	}
+	// Record every collection, not just while a graph is shown
+	m.renderer.History().Record(refreshStart, systemStats)
 
-	// Only rebuild pages when the interface count changes to avoid unnecessary work
+	// Only rebuild pages when the network changes, or the first-boot page
+	// joins or leaves the rotation, to avoid unnecessary work. Without
+	// change notifications a change in the interface count stands in.
 	m.mu.Lock()
-	interfaceCountChanged := len(systemStats.Interfaces) != m.lastInterfaceCount
-	if interfaceCountChanged {
-		m.lastInterfaceCount = len(systemStats.Interfaces)
-	}
+	rebuild := m.networkChanged || m.lastInterfaceCount == -1 ||
+		(!m.networkWatched && len(systemStats.Interfaces) != m.lastInterfaceCount)
+	m.networkChanged = false
+	m.lastInterfaceCount = len(systemStats.Interfaces)
 	m.mu.Unlock()
 
-	if interfaceCountChanged {
+	if rebuild || m.renderer.FirstBootChanged(systemStats) {
 		m.renderer.BuildPages(systemStats)
+		if m.renderer.PageCount() == 0 {
+			m.recordHealth(health.ComponentRenderer, fmt.Errorf("no pages to display"))
+		} else {
+			m.recordHealth(health.ComponentRenderer, nil)
+		}
+	}
+
+	if m.fanController != nil {
+		duty := m.fanController.Duty()
+		// A failed temperature read leaves the fan as it is rather than off
+		if systemStats.CPUTemp > 0 {
+			duty = m.fanController.Observe(stats.ToCelsius(systemStats.CPUTemp, m.config.SystemInfo.TemperatureUnit))
+		}
+		systemStats.Fan = &stats.FanStatus{Duty: duty}
+	}
+
+	if m.thermalMonitor != nil && m.evaluateThermal(systemStats) {
+		start := time.Now()
+		err = m.renderer.RenderTransient(m.shutdownPage, systemStats)
+		m.recordHealth(health.ComponentDisplay, err)
+		m.recordRefresh(m.shutdownPage.Title(), renderer.PageTypeOf(m.shutdownPage), refreshStart, start, err)
+		return err
+	}
+
+	if m.alertEngine != nil && m.evaluateAlerts(systemStats) {
+		start := time.Now()
+		err = m.renderer.RenderTransient(m.alertPage, systemStats)
+		m.recordHealth(health.ComponentDisplay, err)
+		m.recordRefresh(m.alertPage.Title(), renderer.PageTypeOf(m.alertPage), refreshStart, start, err)
+		return err
+	}
+
+	m.mu.Lock()
+	calibration := m.calibrationPage
+	m.mu.Unlock()
+	if calibration != nil {
+		start := time.Now()
+		err = m.renderer.RefreshTransient(calibration, systemStats)
+		m.recordHealth(health.ComponentDisplay, err)
+		m.recordRefresh(calibration.Title(), renderer.PageTypeOf(calibration), refreshStart, start, err)
+		return err
+	}
+
+	if message := m.currentMessage(time.Now()); message != nil {
+		start := time.Now()
+		err = m.renderer.RefreshTransient(message, systemStats)
+		m.recordHealth(health.ComponentDisplay, err)
+		m.recordRefresh(message.Title(), renderer.PageTypeOf(message), refreshStart, start, err)
+		return err
+	}
+
+	clock := m.clockFunc != nil && m.clockFunc()
+	m.mu.Lock()
+	m.clockActive = clock
+	m.mu.Unlock()
+	if clock {
+		start := time.Now()
+		err = m.renderer.RefreshTransient(m.clockPage, systemStats)
+		m.recordHealth(health.ComponentDisplay, err)
+		m.recordRefresh(m.clockPage.Title(), renderer.PageTypeOf(m.clockPage), refreshStart, start, err)
+		return err
 	}
 
 	// Ensure current page is valid after any rebuild
@@ -133,14 +360,15 @@ func (m *Manager) refreshCurrentPage() error {
 	pageIdx := m.currentPage
 	m.mu.Unlock()
 
-	// Render current page
-	pageTitle := m.renderer.PageTitle(pageIdx)
+	// Render current page; once it is on screen only its changed widgets are redrawn
+	pageTitle, pageType := m.renderer.PageTitle(pageIdx), m.renderer.PageType(pageIdx)
 	start := time.Now()
-	err = m.renderer.RenderPage(pageIdx, systemStats)
+	err = m.renderer.RefreshPage(pageIdx, systemStats)
+	m.recordHealth(health.ComponentDisplay, err)
+	m.recordRefresh(pageTitle, pageType, refreshStart, start, err)
 	if m.metricsCollector != nil {
-		m.metricsCollector.RecordDisplayRefresh(err == nil, time.Since(start), pageTitle)
 		m.metricsCollector.UpdateSystemMetrics(
-			systemStats.CPUTemp,
+			stats.ToCelsius(systemStats.CPUTemp, m.config.SystemInfo.TemperatureUnit),
 			systemStats.MemoryPercent(),
 			systemStats.DiskPercent(),
 			len(systemStats.Interfaces),
@@ -149,8 +377,113 @@ func (m *Manager) refreshCurrentPage() error {
 	return err
 }
 
+// recordRefresh records the metrics of a refresh of the page titled title.
+// The refresh latency covers collection and rendering, by page type so that
+// e.g. every network page counts together; the render duration only drawing
+// the page, by title, and is recorded for successful renders.
+func (m *Manager) recordRefresh(title, pageType string, refreshStart, renderStart time.Time, err error) {
+	renderTime := time.Since(renderStart)
+	if m.frameCounter != nil {
+		m.reportFrame(title, renderTime, err)
+	}
+	if m.metricsCollector == nil {
+		return
+	}
+	m.metricsCollector.RecordDisplayRefresh(err == nil, time.Since(refreshStart), pageType)
+	if err == nil {
+		m.metricsCollector.RecordPageRender(title, renderTime)
+	}
+}
+
+// reportFrame logs the render report of the frame just drawn. Counts are
+// taken on every frame, so a sampled-out frame does not inflate the next one.
+func (m *Manager) reportFrame(title string, d time.Duration, err error) {
+	counts := m.frameCounter.TakeCounts()
+	m.reportLog.With().
+		Str("page", title).
+		Int("draw_calls", counts.DrawCalls).
+		Int("flushes", counts.Flushes).
+		Int("bytes_flushed", counts.BytesFlushed).
+		Dur("duration", d).
+		Bool("ok", err == nil).
+		Logger().Debug("Frame rendered")
+}
+
+// evaluateAlerts runs the alert engine and reports whether alerts should
+// replace normal rotation on this refresh.
+func (m *Manager) evaluateAlerts(s *stats.SystemStats) bool {
+	fired, cleared := m.alertEngine.Evaluate(s)
+	for _, a := range fired {
+		m.log.With().Str("rule", a.Rule.Name).Float64("value", a.Value).Logger().Warn("Alert fired")
+		if a.Rule.Wake && m.wakeFunc != nil {
+			m.wakeFunc()
+		}
+		if m.alertNotifier != nil {
+			m.alertNotifier.Notify(alerts.EventFired, a)
+		}
+	}
+	for _, a := range cleared {
+		m.log.With().Str("rule", a.Rule.Name).Float64("value", a.Value).Logger().Info("Alert cleared")
+		if m.alertNotifier != nil {
+			m.alertNotifier.Notify(alerts.EventCleared, a)
+		}
+	}
+
+	active := m.alertEngine.Active()
+	messages := make([]string, 0, len(active))
+	for _, a := range active {
+		messages = append(messages, a.Message())
+	}
+	m.alertPage.SetMessages(messages)
+
+	m.mu.Lock()
+	m.alertActive = len(active) > 0
+	m.mu.Unlock()
+	return len(active) > 0
+}
+
+// evaluateThermal feeds the current temperature to the thermal monitor and
+// reports whether the shutdown countdown should replace normal rendering.
+func (m *Manager) evaluateThermal(s *stats.SystemStats) bool {
+	remaining, active := m.thermalMonitor.Observe(stats.ToCelsius(s.CPUTemp, m.config.SystemInfo.TemperatureUnit))
+	m.shutdownPage.SetRemaining(remaining)
+
+	m.mu.Lock()
+	started := active && !m.shutdownActive
+	m.shutdownActive = active
+	m.mu.Unlock()
+
+	if started && m.wakeFunc != nil {
+		m.wakeFunc()
+	}
+	return active
+}
+
 // rotatePage advances to the next page
 func (m *Manager) rotatePage() {
+	m.mu.Lock()
+	if m.alertActive || m.shutdownActive || m.calibrationPage != nil || m.messageActive || m.clockActive {
+		// Alerts, calibration, messages and the clock hold the display; resume rotation where we left off once resolved
+		m.mu.Unlock()
+		return
+	}
+	if m.paused {
+		if m.holdUntil.IsZero() || time.Now().Before(m.holdUntil) {
+			m.mu.Unlock()
+			return
+		}
+		// Timed hold expired
+		m.paused, m.holdUntil = false, time.Time{}
+		m.log.Info("Page hold expired, resuming rotation")
+	}
+	m.mu.Unlock()
+
+	m.advancePage()
+	// Refresh will happen on next refresh tick
+}
+
+// advancePage moves to the next rotation page
+func (m *Manager) advancePage() {
 	m.mu.Lock()
 	m.currentPage++
 	if m.currentPage >= m.renderer.PageCount() {
@@ -159,10 +492,181 @@ func (m *Manager) rotatePage() {
 	page := m.currentPage
 	m.mu.Unlock()
 
+	m.recordHealth(health.ComponentRotation, nil)
 	if m.metricsCollector != nil {
 		m.metricsCollector.RecordPageRotation(page)
 	}
-	// Refresh will happen on next refresh tick
+}
+
+// Next switches to the next page now and restarts its rotation timer. A
+// paused or held rotation stays paused on the new page.
+func (m *Manager) Next() {
+	select {
+	case m.nextNow <- struct{}{}:
+	default:
+	}
+}
+
+// Pause stops page rotation on the current page until Resume is called.
+// Refreshes continue, so the page keeps showing live data.
+func (m *Manager) Pause() {
+	m.mu.Lock()
+	m.paused, m.holdUntil = true, time.Time{}
+	m.mu.Unlock()
+	m.log.Info("Rotation paused")
+}
+
+// Resume restarts page rotation after Pause or HoldPage
+func (m *Manager) Resume() {
+	m.mu.Lock()
+	m.paused, m.holdUntil = false, time.Time{}
+	m.mu.Unlock()
+	m.log.Info("Rotation resumed")
+}
+
+// Paused reports whether rotation is paused or held on a page
+func (m *Manager) Paused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paused
+}
+
+// HoldPage switches to the page at idx immediately and keeps it on screen
+// for d, after which rotation resumes. A zero d holds until Resume.
+func (m *Manager) HoldPage(idx int, d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("hold duration must not be negative, got %s", d)
+	}
+	if count := m.renderer.PageCount(); idx < 0 || idx >= count {
+		return fmt.Errorf("invalid page index %d (have %d pages)", idx, count)
+	}
+
+	m.mu.Lock()
+	m.currentPage = idx
+	m.paused, m.holdUntil = true, time.Time{}
+	if d > 0 {
+		m.holdUntil = time.Now().Add(d)
+	}
+	m.mu.Unlock()
+
+	m.log.With().Int("page", idx).Str("duration", d.String()).Logger().Info("Holding page")
+	// Show the page now rather than on the next refresh tick
+	m.requestRefresh()
+	return nil
+}
+
+// ShowMessage shows page in place of normal rotation for d, replacing any
+// message already shown. Alerts and thermal shutdown still take precedence.
+func (m *Manager) ShowMessage(page *renderer.MessagePage, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("message duration must be positive, got %s", d)
+	}
+
+	m.mu.Lock()
+	m.messagePage = page
+	m.messageUntil = time.Now().Add(d)
+	m.messageActive = true
+	m.mu.Unlock()
+
+	m.log.With().Str("text", page.Text()).Str("duration", d.String()).Logger().Info("Showing message")
+	m.requestRefresh()
+	// Remove the message promptly rather than on the next refresh tick
+	time.AfterFunc(d, m.requestRefresh)
+	return nil
+}
+
+// ShowCalibration shows page in place of normal rotation until
+// StopCalibration, redrawing it as its values change. Alerts and thermal
+// shutdown still take precedence.
+func (m *Manager) ShowCalibration(page *renderer.CalibrationPage) {
+	m.mu.Lock()
+	m.calibrationPage = page
+	m.mu.Unlock()
+	m.requestRefresh()
+}
+
+// StopCalibration removes the calibration page and resumes rotation
+func (m *Manager) StopCalibration() {
+	m.mu.Lock()
+	m.calibrationPage = nil
+	m.mu.Unlock()
+	m.requestRefresh()
+}
+
+// currentMessage returns the message to show at now, clearing it once expired
+func (m *Manager) currentMessage(now time.Time) *renderer.MessagePage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.messagePage != nil && !now.Before(m.messageUntil) {
+		m.messagePage = nil
+		m.log.Debug("Message expired")
+	}
+	m.messageActive = m.messagePage != nil
+	return m.messagePage
+}
+
+// WatchNetwork subscribes to the kernel's link and address change
+// notifications until ctx is done, calling NetworkChanged on each change,
+// in place of polling the interface count. It fails where notifications are
+// unavailable, leaving polling in place.
+func (m *Manager) WatchNetwork(ctx context.Context) error {
+	if err := netwatch.Watch(ctx, m.NetworkChanged); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.networkWatched = true
+	m.mu.Unlock()
+	return nil
+}
+
+// NetworkChanged re-reads the network and rebuilds the pages now, for an
+// interface or address that came, went or changed
+func (m *Manager) NetworkChanged() {
+	if ic, ok := m.collector.(stats.InvalidatingCollector); ok {
+		ic.Invalidate(config.SourceNetwork)
+	}
+	m.mu.Lock()
+	m.networkChanged = true
+	m.mu.Unlock()
+	m.log.Debug("Network changed, rebuilding pages")
+	m.requestRefresh()
+}
+
+// Sleep powers the display down until Wake, e.g. before the host suspends.
+// Stats are still collected, but nothing is drawn.
+func (m *Manager) Sleep() error {
+	m.log.Info("Putting the display to sleep")
+	return m.renderer.Sleep()
+}
+
+// Wake re-initializes the display after Sleep and redraws the current page
+func (m *Manager) Wake() error {
+	m.log.Info("Waking the display")
+	if err := m.renderer.Wake(); err != nil {
+		return err
+	}
+	m.requestRefresh()
+	return nil
+}
+
+// requestRefresh asks the run loop to refresh the display now. Requests made
+// while one is pending are merged.
+func (m *Manager) requestRefresh() {
+	select {
+	case m.refreshNow <- struct{}{}:
+	default:
+	}
+}
+
+// PageIndex returns the index of the first page of the given config page
+// type (config.PageNetwork etc.), or -1 if there is none
+func (m *Manager) PageIndex(pageType string) int {
+	for i := 0; i < m.renderer.PageCount(); i++ {
+		if m.renderer.PageType(i) == pageType {
+			return i
+		}
+	}
+	return -1
 }
 
 // Stop stops the rotation manager gracefully
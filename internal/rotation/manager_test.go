@@ -5,10 +5,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ausil/i2c-display/internal/alerts"
 	"github.com/ausil/i2c-display/internal/config"
 	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/health"
+	"github.com/ausil/i2c-display/internal/logger"
 	"github.com/ausil/i2c-display/internal/renderer"
 	"github.com/ausil/i2c-display/internal/stats"
+	"github.com/ausil/i2c-display/internal/thermal"
 )
 
 func TestManager(t *testing.T) {
@@ -137,3 +141,403 @@ func TestManagerInvalidIntervals(t *testing.T) {
 		t.Error("expected error for invalid rotation interval")
 	}
 }
+
+func TestManagerAlertInterruptsRotation(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.RotationInterval = "20ms"
+	cfg.Pages.RefreshInterval = "10ms"
+
+	disp := display.NewMockDisplay(128, 64)
+	disp.Init()
+
+	collector, _ := stats.NewSystemCollector(cfg)
+	rend := renderer.NewRenderer(disp, cfg)
+
+	mgr := NewManager(cfg, collector, rend)
+	// load >= 0 always holds, so the alert fires on the first refresh
+	mgr.SetAlertEngine(alerts.NewEngine([]alerts.Rule{
+		{Name: "Load", Metric: alerts.MetricLoad, Operator: ">=", Threshold: 0, Wake: true},
+	}))
+	woken := make(chan struct{}, 1)
+	mgr.SetWakeFunc(func() {
+		select {
+		case woken <- struct{}{}:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := mgr.Start(ctx); err != nil {
+		t.Fatalf("failed to start manager: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	mgr.Stop()
+
+	if mgr.CurrentPage() != 0 {
+		t.Errorf("expected rotation to be held on page 0 while alert active, got %d", mgr.CurrentPage())
+	}
+	select {
+	case <-woken:
+	default:
+		t.Error("expected wake func to be called when alert fired")
+	}
+}
+
+func TestManagerThermalKelvin(t *testing.T) {
+	cfg := config.Default()
+	cfg.SystemInfo.TemperatureUnit = config.UnitKelvin
+	cfg.Thermal.Samples = 1
+	rend := renderer.NewRenderer(display.NewMockDisplay(128, 64), cfg)
+	mgr := NewManager(cfg, &networkCollector{}, rend)
+	// The default threshold of 85 is in Celsius, not kelvin
+	mgr.SetThermalMonitor(thermal.NewMonitor(cfg.Thermal, logger.NewDefault()))
+
+	if mgr.evaluateThermal(&stats.SystemStats{CPUTemp: 320}) {
+		t.Error("expected a CPU at 320K (47C) not to start the shutdown countdown")
+	}
+	if !mgr.evaluateThermal(&stats.SystemStats{CPUTemp: 360}) {
+		t.Error("expected a CPU at 360K (87C) to start the shutdown countdown")
+	}
+}
+
+func TestManagerClockHoldsRotation(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.RotationInterval = "20ms"
+	cfg.Pages.RefreshInterval = "10ms"
+
+	disp := display.NewMockDisplay(128, 64)
+	disp.Init()
+
+	collector, _ := stats.NewSystemCollector(cfg)
+	rend := renderer.NewRenderer(disp, cfg)
+
+	mgr := NewManager(cfg, collector, rend)
+	mgr.SetClockFunc(func() bool { return true })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := mgr.Start(ctx); err != nil {
+		t.Fatalf("failed to start manager: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	mgr.Stop()
+
+	if mgr.CurrentPage() != 0 {
+		t.Errorf("expected rotation to be held on page 0 while clock shown, got %d", mgr.CurrentPage())
+	}
+}
+
+func TestManagerShowMessage(t *testing.T) {
+	cfg := config.Default()
+	collector, err := stats.NewSystemCollector(cfg)
+	if err != nil {
+		t.Fatalf("failed to create collector: %v", err)
+	}
+	rend := renderer.NewRenderer(display.NewMockDisplay(128, 64), cfg)
+	rend.BuildPages(&stats.SystemStats{
+		Hostname:   "testhost",
+		Interfaces: []stats.NetInterface{{Name: "eth0", IPv4Addrs: []string{"192.168.1.100"}}},
+	})
+	if rend.PageCount() < 2 {
+		t.Fatalf("expected several pages, got %d", rend.PageCount())
+	}
+	mgr := NewManager(cfg, collector, rend)
+	// The clock would otherwise replace rotation; messages take precedence
+	mgr.SetClockFunc(func() bool { return true })
+
+	page, err := renderer.NewMessagePage("backup done", "", renderer.ColorGreen)
+	if err != nil {
+		t.Fatalf("NewMessagePage() failed: %v", err)
+	}
+	if err := mgr.ShowMessage(page, 0); err == nil {
+		t.Error("expected an error for a zero duration")
+	}
+	if err := mgr.ShowMessage(page, time.Hour); err != nil {
+		t.Fatalf("ShowMessage() failed: %v", err)
+	}
+
+	now := time.Now()
+	if got := mgr.currentMessage(now); got != page {
+		t.Fatal("expected the message to be shown")
+	}
+	mgr.rotatePage()
+	if mgr.CurrentPage() != 0 {
+		t.Errorf("expected rotation held while the message is shown, got page %d", mgr.CurrentPage())
+	}
+
+	if got := mgr.currentMessage(now.Add(time.Hour)); got != nil {
+		t.Error("expected the message removed once expired")
+	}
+	if err := mgr.refreshCurrentPage(); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+}
+
+func TestManagerShowCalibration(t *testing.T) {
+	cfg := config.Default()
+	collector, err := stats.NewSystemCollector(cfg)
+	if err != nil {
+		t.Fatalf("failed to create collector: %v", err)
+	}
+	mock := display.NewMockDisplay(128, 64)
+	rend := renderer.NewRenderer(mock, cfg)
+	rend.BuildPages(&stats.SystemStats{
+		Hostname:   "testhost",
+		Interfaces: []stats.NetInterface{{Name: "eth0", IPv4Addrs: []string{"192.168.1.100"}}},
+	})
+	mgr := NewManager(cfg, collector, rend)
+
+	page := renderer.NewCalibrationPage(255, 1)
+	mgr.ShowCalibration(page)
+	if err := mgr.refreshCurrentPage(); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	// The test image's solid block fills the bottom-left corner
+	if !mock.GetPixel(0, 63) {
+		t.Error("expected the calibration page to be drawn")
+	}
+	mgr.rotatePage()
+	if mgr.CurrentPage() != 0 {
+		t.Errorf("expected rotation held while calibrating, got page %d", mgr.CurrentPage())
+	}
+
+	mgr.StopCalibration()
+	mgr.rotatePage()
+	if mgr.CurrentPage() != 1 {
+		t.Errorf("expected rotation to resume after calibration, got page %d", mgr.CurrentPage())
+	}
+}
+
+func TestManagerRenderReportsTakeFrameCounts(t *testing.T) {
+	cfg := config.Default()
+	collector, err := stats.NewSystemCollector(cfg)
+	if err != nil {
+		t.Fatalf("failed to create collector: %v", err)
+	}
+
+	counter := display.NewCountingDisplay(display.NewMockDisplay(128, 64))
+	mgr := NewManager(cfg, collector, renderer.NewRenderer(counter, cfg))
+	mgr.SetRenderReports(counter, 10)
+
+	if err := mgr.refreshCurrentPage(); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	// The report consumed the frame's counts, sampled out or not
+	if got := counter.TakeCounts(); got != (display.FrameCounts{}) {
+		t.Errorf("expected frame counts taken by the report, got %+v", got)
+	}
+}
+
+func TestManagerRecordsHealth(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.RotationInterval = "1h"
+	cfg.Pages.RefreshInterval = "1h"
+
+	disp := display.NewMockDisplay(128, 64)
+	if err := disp.Init(); err != nil {
+		t.Fatalf("failed to init display: %v", err)
+	}
+	collector, err := stats.NewSystemCollector(cfg)
+	if err != nil {
+		t.Fatalf("failed to create collector: %v", err)
+	}
+
+	mgr := NewManager(cfg, collector, renderer.NewRenderer(disp, cfg))
+	checker := health.New()
+	mgr.SetHealthChecker(checker)
+
+	if len(checker.GetAllComponents()) != 4 {
+		t.Fatalf("expected 4 registered components, got %d", len(checker.GetAllComponents()))
+	}
+
+	if err := mgr.refreshCurrentPage(); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if got := checker.GetComponentStatus(health.ComponentDisplay).SuccessCount; got != 1 {
+		t.Errorf("expected 1 display success, got %d", got)
+	}
+
+	// An unchanged page is not flushed again, so put something else on the
+	// display first to make the refreshes below redraw the page in full
+	if err := mgr.renderer.RenderTransient(renderer.NewClockPage(0), nil); err != nil {
+		t.Fatalf("RenderTransient() failed: %v", err)
+	}
+	disp.SetError(true, "i2c write failed")
+	for i := 0; i < 3; i++ {
+		_ = mgr.refreshCurrentPage()
+	}
+	if got := checker.GetComponentStatus(health.ComponentDisplay).Status; got != health.StatusDegraded {
+		t.Errorf("expected display degraded after errors, got %s", got)
+	}
+	if got := checker.GetComponentStatus(health.ComponentCollector).Status; got != health.StatusHealthy {
+		t.Errorf("expected collector healthy, got %s", got)
+	}
+}
+
+func TestManagerPageDurations(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.RotationInterval = "5s"
+	cfg.Pages.Durations = map[string]string{config.PageNetwork: "2s"}
+
+	rend := renderer.NewRenderer(display.NewMockDisplay(128, 64), cfg)
+	rend.BuildPages(&stats.SystemStats{
+		Hostname:   "testhost",
+		Interfaces: []stats.NetInterface{{Name: "eth0", IPv4Addrs: []string{"192.168.1.100"}}},
+	})
+
+	mgr := NewManager(cfg, nil, rend)
+	mgr.rotationInterval, _ = cfg.Pages.GetRotationInterval()
+	mgr.pageDurations, _ = cfg.Pages.GetPageDurations()
+
+	for i := 0; i < rend.PageCount(); i++ {
+		want := 5 * time.Second
+		if rend.PageType(i) == config.PageNetwork {
+			want = 2 * time.Second
+		}
+		if got := mgr.pageDuration(i); got != want {
+			t.Errorf("page %d (%s): duration %s, want %s", i, rend.PageType(i), got, want)
+		}
+	}
+
+	// A slow display's minimum refresh interval is a floor on every duration
+	mgr.minRefresh = 3 * time.Second
+	for i := 0; i < rend.PageCount(); i++ {
+		if got := mgr.pageDuration(i); got < mgr.minRefresh {
+			t.Errorf("page %d (%s): duration %s is below the display minimum", i, rend.PageType(i), got)
+		}
+	}
+}
+
+func TestManagerPauseAndHold(t *testing.T) {
+	cfg := config.Default()
+	rend := renderer.NewRenderer(display.NewMockDisplay(128, 64), cfg)
+	rend.BuildPages(&stats.SystemStats{
+		Hostname:   "testhost",
+		Interfaces: []stats.NetInterface{{Name: "eth0", IPv4Addrs: []string{"192.168.1.100"}}},
+	})
+	mgr := NewManager(cfg, nil, rend)
+
+	mgr.Pause()
+	mgr.rotatePage()
+	if mgr.CurrentPage() != 0 {
+		t.Errorf("expected rotation paused on page 0, got %d", mgr.CurrentPage())
+	}
+	mgr.Resume()
+	mgr.rotatePage()
+	if mgr.CurrentPage() != 1 {
+		t.Errorf("expected rotation to advance after resume, got %d", mgr.CurrentPage())
+	}
+
+	network := mgr.PageIndex(config.PageNetwork)
+	if network < 0 {
+		t.Fatal("expected a network page")
+	}
+	if err := mgr.HoldPage(network, 0); err != nil {
+		t.Fatalf("HoldPage() failed: %v", err)
+	}
+	mgr.rotatePage()
+	if mgr.CurrentPage() != network || !mgr.Paused() {
+		t.Errorf("expected hold on network page %d, got page %d (paused %v)", network, mgr.CurrentPage(), mgr.Paused())
+	}
+
+	// A timed hold resumes rotation on its own
+	if err := mgr.HoldPage(0, time.Millisecond); err != nil {
+		t.Fatalf("HoldPage() failed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	mgr.rotatePage()
+	if mgr.Paused() || mgr.CurrentPage() != 1 {
+		t.Errorf("expected expired hold to resume rotation, got page %d (paused %v)", mgr.CurrentPage(), mgr.Paused())
+	}
+
+	if err := mgr.HoldPage(99, 0); err == nil {
+		t.Error("expected error for out of range page")
+	}
+	if err := mgr.HoldPage(0, -time.Second); err == nil {
+		t.Error("expected error for negative duration")
+	}
+}
+
+func TestManagerNext(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.RotationInterval = "1h"
+	collector, err := stats.NewSystemCollector(cfg)
+	if err != nil {
+		t.Fatalf("failed to create collector: %v", err)
+	}
+	rend := renderer.NewRenderer(display.NewMockDisplay(128, 64), cfg)
+	mgr := NewManager(cfg, collector, rend)
+	if err := mgr.Start(context.Background()); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer mgr.Stop()
+	if rend.PageCount() < 2 {
+		t.Skipf("need at least 2 pages on this system, got %d", rend.PageCount())
+	}
+
+	// Next works while paused and leaves rotation paused
+	mgr.Pause()
+	mgr.Next()
+	deadline := time.Now().Add(2 * time.Second)
+	for mgr.CurrentPage() != 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if mgr.CurrentPage() != 1 {
+		t.Errorf("expected Next to switch to page 1, got %d", mgr.CurrentPage())
+	}
+	if !mgr.Paused() {
+		t.Error("expected rotation to stay paused after Next")
+	}
+}
+
+// networkCollector returns fixed stats with the given interfaces and
+// records invalidated sources
+type networkCollector struct {
+	interfaces  []stats.NetInterface
+	invalidated []string
+}
+
+func (c *networkCollector) Collect() (*stats.SystemStats, error) {
+	return &stats.SystemStats{Hostname: "test", Interfaces: c.interfaces}, nil
+}
+
+func (c *networkCollector) Invalidate(source string) {
+	c.invalidated = append(c.invalidated, source)
+}
+
+func TestManagerNetworkChanged(t *testing.T) {
+	cfg := config.Default()
+	cfg.Pages.RotationInterval = "1h"
+	cfg.Pages.RefreshInterval = "1h"
+	collector := &networkCollector{}
+	rend := renderer.NewRenderer(display.NewMockDisplay(128, 64), cfg)
+	mgr := NewManager(cfg, collector, rend)
+	mgr.networkWatched = true
+	if err := mgr.refreshCurrentPage(); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	pages := rend.PageCount()
+
+	// With notifications, a new interface alone does not rebuild the pages
+	collector.interfaces = []stats.NetInterface{{Name: "eth0", IPv4Addrs: []string{"192.168.1.2"}}}
+	if err := mgr.refreshCurrentPage(); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if rend.PageCount() != pages {
+		t.Fatalf("expected %d pages without a change notification, got %d", pages, rend.PageCount())
+	}
+
+	mgr.NetworkChanged()
+	if len(collector.invalidated) != 1 || collector.invalidated[0] != config.SourceNetwork {
+		t.Errorf("expected the network source to be invalidated, got %v", collector.invalidated)
+	}
+	if err := mgr.refreshCurrentPage(); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if rend.PageCount() != pages+1 {
+		t.Errorf("expected the network page to be added, got %d pages from %d", rend.PageCount(), pages)
+	}
+}
@@ -0,0 +1,78 @@
+package rotation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/health"
+)
+
+// stallWatch is the state of WatchStalls, touched only by its goroutine
+type stallWatch struct {
+	timeout   time.Duration
+	lastFlush func() time.Time
+	reinit    func()
+	last      time.Time // last flush before the current stall
+	fired     time.Time // when the current stall was last acted on; zero while frames flow
+}
+
+// WatchStalls checks every refresh interval until ctx is done that a frame
+// was flushed within the last refreshes intervals, as reported by
+// lastFlush, e.g. display.WatchedDisplay.LastFlush. A stalled render loop,
+// such as one blocked on a wedged bus, is logged as an incident, marks the
+// display unhealthy and calls reinit, if set, to re-create the display. If
+// frames do not resume, this repeats every refreshes intervals. A flush that
+// never returns cannot be freed in the process; the unhealthy display
+// withholds systemd watchdog pings so systemd restarts the service. Must be
+// called after Start.
+func (m *Manager) WatchStalls(ctx context.Context, refreshes int, lastFlush func() time.Time, reinit func()) {
+	w := &stallWatch{
+		timeout:   time.Duration(refreshes) * m.refreshInterval,
+		lastFlush: lastFlush,
+		reinit:    reinit,
+	}
+	ticker := time.NewTicker(m.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.checkStall(w, now)
+		}
+	}
+}
+
+// checkStall acts on a display that has not been flushed within the
+// timeout, at most once per timeout, and logs when frames resume
+func (m *Manager) checkStall(w *stallWatch, now time.Time) {
+	last := w.lastFlush()
+	stalled := now.Sub(last)
+	if stalled < w.timeout {
+		if !w.fired.IsZero() {
+			m.log.With().Dur("stalled_for", last.Sub(w.last).Round(time.Millisecond)).Logger().Info("Render loop recovered")
+			w.fired = time.Time{}
+		}
+		return
+	}
+	if !w.fired.IsZero() && now.Sub(w.fired) < w.timeout {
+		return
+	}
+	w.last, w.fired = last, now
+
+	err := fmt.Errorf("no frame flushed for %s", stalled.Round(time.Second))
+	m.log.With().
+		Err(err).
+		Str("last_flush", last.Format(time.RFC3339)).
+		Dur("timeout", w.timeout).
+		Int("page", m.CurrentPage()).
+		Bool("reinit", w.reinit != nil).
+		Logger().Error("Render loop stalled")
+	if m.healthChecker != nil {
+		m.healthChecker.MarkUnhealthy(health.ComponentDisplay, err)
+	}
+	if w.reinit != nil {
+		w.reinit()
+	}
+}
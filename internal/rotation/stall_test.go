@@ -0,0 +1,94 @@
+package rotation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/health"
+	"github.com/ausil/i2c-display/internal/renderer"
+)
+
+func TestManagerCheckStall(t *testing.T) {
+	cfg := config.Default()
+	rend := renderer.NewRenderer(display.NewMockDisplay(128, 64), cfg)
+	mgr := NewManager(cfg, &networkCollector{}, rend)
+	checker := health.New()
+	mgr.SetHealthChecker(checker)
+
+	last := time.Unix(1000, 0)
+	reinits := 0
+	w := &stallWatch{
+		timeout:   10 * time.Second,
+		lastFlush: func() time.Time { return last },
+		reinit:    func() { reinits++ },
+	}
+	mgr.checkStall(w, last.Add(9*time.Second))
+	if reinits != 0 {
+		t.Fatal("expected no action within the timeout")
+	}
+
+	mgr.checkStall(w, last.Add(10*time.Second))
+	if reinits != 1 {
+		t.Fatalf("expected a re-init once stalled, got %d", reinits)
+	}
+	if got := checker.GetComponentStatus(health.ComponentDisplay).Status; got != health.StatusUnhealthy {
+		t.Errorf("expected display unhealthy when stalled, got %s", got)
+	}
+
+	// Acted on at most once per timeout while the stall lasts
+	mgr.checkStall(w, last.Add(15*time.Second))
+	if reinits != 1 {
+		t.Errorf("expected no second re-init within the timeout, got %d", reinits)
+	}
+	mgr.checkStall(w, last.Add(20*time.Second))
+	if reinits != 2 {
+		t.Errorf("expected another re-init after the timeout, got %d", reinits)
+	}
+
+	// A flush ends the stall
+	last = last.Add(21 * time.Second)
+	mgr.checkStall(w, last.Add(time.Second))
+	if !w.fired.IsZero() {
+		t.Error("expected the stall cleared once frames resume")
+	}
+	if reinits != 2 {
+		t.Errorf("expected no re-init once frames resume, got %d", reinits)
+	}
+}
+
+func TestManagerCheckStallQueuedWedgedBus(t *testing.T) {
+	// A wedged bus blocks only the double buffer's flusher: refreshes keep
+	// succeeding, but nothing reaches the watched display below the queue
+	cfg := config.Default()
+	wedged := &wedgedDisplay{MockDisplay: display.NewMockDisplay(128, 64), release: make(chan struct{})}
+	defer close(wedged.release)
+	watched := display.NewWatchedDisplay(wedged)
+	queued := display.NewQueuedDisplay(watched, nil)
+	rend := renderer.NewRenderer(queued, cfg)
+	mgr := NewManager(cfg, &networkCollector{}, rend)
+
+	for i := 0; i < 3; i++ {
+		if err := mgr.refreshCurrentPage(); err != nil {
+			t.Fatalf("refresh failed: %v", err)
+		}
+	}
+	reinits := 0
+	w := &stallWatch{timeout: time.Second, lastFlush: watched.LastFlush, reinit: func() { reinits++ }}
+	mgr.checkStall(w, time.Now().Add(2*time.Second))
+	if reinits != 1 {
+		t.Errorf("expected the wedged flusher to be noticed, got %d re-inits", reinits)
+	}
+}
+
+// wedgedDisplay blocks every Show until release is closed
+type wedgedDisplay struct {
+	*display.MockDisplay
+	release chan struct{}
+}
+
+func (w *wedgedDisplay) Show() error {
+	<-w.release
+	return nil
+}
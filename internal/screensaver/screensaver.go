@@ -26,19 +26,34 @@ const (
 	ModeOff Mode = "off"
 	// ModeDim - dim the display after inactivity
 	ModeDim Mode = "dim"
-	// ModeBlank - turn off display after inactivity
+	// ModeBlank - turn off the backlight and power the panel down after
+	// inactivity
 	ModeBlank Mode = "blank"
+	// ModeShift - periodically move content by a few pixels after inactivity
+	ModeShift Mode = "shift"
+	// ModeClock - replace rotation with a dimmed clock after inactivity
+	ModeClock Mode = "clock"
 )
 
+// Shifter translates rendered content, e.g. display.ShiftDisplay
+type Shifter interface {
+	SetOffset(dx, dy int)
+}
+
+// shiftPattern walks a ring around the origin; each entry is scaled by ShiftPixels
+var shiftPattern = [][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+
 // Config holds screen saver configuration
 type Config struct {
 	Enabled          bool          `json:"enabled"`
-	Mode             Mode          `json:"mode"`              // "off", "dim", or "blank"
+	Mode             Mode          `json:"mode"`              // "off", "dim", "blank", "shift" or "clock"
 	IdleTimeout      time.Duration `json:"idle_timeout"`      // Time before activation (unused when ActiveHours.Enabled)
 	DimBrightness    uint8         `json:"dim_brightness"`    // Brightness when dimmed (0-255)
 	NormalBrightness uint8         `json:"normal_brightness"` // Normal operating brightness
 	ActiveHours      ActiveHours   // If enabled, suppresses screensaver during the configured window
 	WakeDuration     time.Duration // How long a manual Wake() keeps the display on
+	ShiftPixels      int           // Maximum offset in pixels for ModeShift
+	ShiftInterval    time.Duration // How often content moves in ModeShift
 }
 
 // ScreenSaver manages display power saving
@@ -49,7 +64,13 @@ type ScreenSaver struct {
 	mu         sync.RWMutex
 	lastActive time.Time
 	isActive   bool      // true if screen saver is currently active
+	asleep     bool      // ModeBlank put the panel to sleep
 	wakedUntil time.Time // non-zero while a manual wake is in effect
+	shifter    Shifter   // optional, required for ModeShift
+	shiftStep  int       // index into shiftPattern
+	lastShift  time.Time
+	night      bool // night mode caps brightness at nightLevel
+	nightLevel uint8
 	ticker     *time.Ticker
 	stopChan   chan struct{}
 }
@@ -66,6 +87,12 @@ func New(cfg Config, disp display.Display, log *logger.Logger) *ScreenSaver {
 	}
 }
 
+// SetShifter attaches the content translator used by ModeShift.
+// Must be called before Start.
+func (s *ScreenSaver) SetShifter(sh Shifter) {
+	s.shifter = sh
+}
+
 // Start starts the screen saver monitor
 func (s *ScreenSaver) Start(ctx context.Context) error {
 	if !s.cfg.Enabled {
@@ -79,7 +106,10 @@ func (s *ScreenSaver) Start(ctx context.Context) error {
 		Logger().Info("Starting screen saver")
 
 	// Set initial brightness
-	if err := s.disp.SetBrightness(s.cfg.NormalBrightness); err != nil {
+	s.mu.RLock()
+	level := s.normalLevel()
+	s.mu.RUnlock()
+	if err := s.disp.SetBrightness(level); err != nil {
 		s.log.ErrorWithErr(err, "Failed to set initial brightness")
 	}
 
@@ -140,15 +170,37 @@ func (s *ScreenSaver) check() {
 		shouldActivate = idle >= s.cfg.IdleTimeout && !s.isActive
 		shouldDeactivate = idle < s.cfg.IdleTimeout && s.isActive
 	}
+	shouldShift := s.isActive && !shouldDeactivate && s.cfg.Mode == ModeShift &&
+		now.Sub(s.lastShift) >= s.cfg.ShiftInterval
 	s.mu.Unlock()
 
 	if shouldActivate {
 		s.activate()
 	} else if shouldDeactivate {
 		s.deactivate()
+	} else if shouldShift {
+		s.shift()
 	}
 }
 
+// shift moves content to the next position in the burn-in pattern
+func (s *ScreenSaver) shift() {
+	s.mu.Lock()
+	if s.shifter == nil {
+		s.mu.Unlock()
+		return
+	}
+	p := shiftPattern[s.shiftStep%len(shiftPattern)]
+	s.shiftStep++
+	s.lastShift = time.Now()
+	dx, dy := p[0]*s.cfg.ShiftPixels, p[1]*s.cfg.ShiftPixels
+	shifter := s.shifter
+	s.mu.Unlock()
+
+	shifter.SetOffset(dx, dy)
+	s.log.With().Int("dx", dx).Int("dy", dy).Logger().Debug("Shifted display content")
+}
+
 // inActiveHours reports whether t falls within the configured active window.
 // Must be called with s.mu held.
 func (s *ScreenSaver) inActiveHours(t time.Time) bool {
@@ -182,13 +234,22 @@ func parseHHMM(s string) (hour, minute int) {
 func (s *ScreenSaver) activate() {
 	s.log.With().Str("mode", string(s.cfg.Mode)).Logger().Info("Activating screen saver")
 
+	s.mu.RLock()
+	dim := min(s.cfg.DimBrightness, s.normalLevel())
+	s.mu.RUnlock()
+
 	// Perform display operations without holding the lock
 	var err error
 	switch s.cfg.Mode {
-	case ModeDim:
-		err = s.disp.SetBrightness(s.cfg.DimBrightness)
+	case ModeDim, ModeClock:
+		err = s.disp.SetBrightness(dim)
 	case ModeBlank:
-		err = s.disp.SetBrightness(0)
+		// Software-dimmed displays have no sleep mode, so blank them first
+		if err = s.disp.SetBrightness(0); err == nil {
+			err = s.disp.Sleep()
+		}
+	case ModeShift:
+		s.shift()
 	}
 
 	if err != nil {
@@ -199,6 +260,7 @@ func (s *ScreenSaver) activate() {
 	// Only set isActive flag if brightness change succeeded
 	s.mu.Lock()
 	s.isActive = true
+	s.asleep = s.cfg.Mode == ModeBlank
 	s.mu.Unlock()
 }
 
@@ -206,8 +268,23 @@ func (s *ScreenSaver) activate() {
 func (s *ScreenSaver) deactivate() {
 	s.log.Debug("Deactivating screen saver")
 
-	// Perform display operation without holding the lock
-	if err := s.disp.SetBrightness(s.cfg.NormalBrightness); err != nil {
+	if s.shifter != nil {
+		s.shifter.SetOffset(0, 0)
+	}
+
+	s.mu.RLock()
+	level := s.normalLevel()
+	asleep := s.asleep
+	s.mu.RUnlock()
+
+	// Perform display operations without holding the lock
+	if asleep {
+		if err := s.disp.Wake(); err != nil {
+			s.log.ErrorWithErr(err, "Failed to wake display")
+			return
+		}
+	}
+	if err := s.disp.SetBrightness(level); err != nil {
 		s.log.ErrorWithErr(err, "Failed to restore brightness")
 		return
 	}
@@ -215,6 +292,7 @@ func (s *ScreenSaver) deactivate() {
 	// Only clear isActive flag if brightness change succeeded
 	s.mu.Lock()
 	s.isActive = false
+	s.asleep = false
 	s.mu.Unlock()
 }
 
@@ -260,6 +338,48 @@ func (s *ScreenSaver) Wake() {
 	s.log.With().Str("duration", duration.String()).Logger().Info("Display woken manually")
 }
 
+// SetNormalBrightness changes the brightness used while the screensaver is
+// inactive, e.g. from ambient light auto-brightness. The new level is applied
+// immediately unless the display is currently dimmed or blanked, in which
+// case it takes effect on the next wake.
+func (s *ScreenSaver) SetNormalBrightness(level uint8) {
+	s.mu.Lock()
+	s.cfg.NormalBrightness = level
+	active := s.cfg.Enabled && s.isActive
+	level = s.normalLevel()
+	s.mu.Unlock()
+
+	if active {
+		return
+	}
+	if err := s.disp.SetBrightness(level); err != nil {
+		s.log.ErrorWithErr(err, "Failed to set brightness")
+	}
+}
+
+// SetNight turns the night brightness cap on or off. While on, the normal and
+// dimmed levels are limited to level. The resulting brightness is applied
+// immediately.
+func (s *ScreenSaver) SetNight(on bool, level uint8) {
+	s.mu.Lock()
+	s.night = on
+	s.nightLevel = level
+	s.mu.Unlock()
+
+	if err := s.disp.SetBrightness(s.Brightness()); err != nil {
+		s.log.ErrorWithErr(err, "Failed to set night brightness")
+	}
+}
+
+// normalLevel returns the brightness used while the screensaver is inactive,
+// capped by night mode. Must be called with s.mu held.
+func (s *ScreenSaver) normalLevel() uint8 {
+	if s.night {
+		return min(s.cfg.NormalBrightness, s.nightLevel)
+	}
+	return s.cfg.NormalBrightness
+}
+
 // IsActive returns whether the screen saver is currently active
 func (s *ScreenSaver) IsActive() bool {
 	s.mu.RLock()
@@ -267,6 +387,32 @@ func (s *ScreenSaver) IsActive() bool {
 	return s.isActive
 }
 
+// Brightness returns the level the screensaver currently holds the display
+// at: the normal level when inactive, otherwise the level for its mode
+func (s *ScreenSaver) Brightness() uint8 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.cfg.Enabled || !s.isActive {
+		return s.normalLevel()
+	}
+	switch s.cfg.Mode {
+	case ModeDim, ModeClock:
+		return min(s.cfg.DimBrightness, s.normalLevel())
+	case ModeBlank:
+		return 0
+	default:
+		return s.normalLevel()
+	}
+}
+
+// ShowClock reports whether the clock should replace normal rotation, i.e.
+// the screensaver is active in clock mode
+func (s *ScreenSaver) ShowClock() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg.Enabled && s.isActive && s.cfg.Mode == ModeClock
+}
+
 // Config returns the current screen saver configuration
 func (s *ScreenSaver) Config() Config {
 	s.mu.RLock()
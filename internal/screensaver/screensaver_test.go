@@ -2,6 +2,7 @@ package screensaver
 
 import (
 	"context"
+	"slices"
 	"testing"
 	"time"
 
@@ -141,6 +142,30 @@ func TestScreenSaverModes(t *testing.T) {
 	}
 }
 
+func TestBlankSleepsPanel(t *testing.T) {
+	cfg := Config{
+		Enabled:          true,
+		Mode:             ModeBlank,
+		IdleTimeout:      50 * time.Millisecond,
+		NormalBrightness: 200,
+	}
+
+	disp := display.NewMockDisplay(128, 64)
+	ss := New(cfg, disp, logger.NewDefault())
+
+	time.Sleep(100 * time.Millisecond)
+	ss.check()
+	if got := disp.GetCalls(); !slices.Equal(got, []string{"SetBrightness([0])", "Sleep"}) {
+		t.Errorf("blanking made calls %v, want the backlight off then Sleep", got)
+	}
+
+	disp.ClearCalls()
+	ss.ResetActivity()
+	if got := disp.GetCalls(); !slices.Equal(got, []string{"Wake", "SetBrightness([200])"}) {
+		t.Errorf("waking made calls %v, want Wake then the normal brightness", got)
+	}
+}
+
 func TestUpdateConfig(t *testing.T) {
 	initialCfg := Config{
 		Enabled:          true,
@@ -349,3 +374,171 @@ func TestStartStop(t *testing.T) {
 
 	// Should not panic or error
 }
+
+func TestSetNormalBrightness(t *testing.T) {
+	cfg := Config{
+		Enabled:          true,
+		Mode:             ModeDim,
+		IdleTimeout:      50 * time.Millisecond,
+		DimBrightness:    50,
+		NormalBrightness: 255,
+	}
+
+	disp := display.NewMockDisplay(128, 64)
+	ss := New(cfg, disp, logger.NewDefault())
+
+	// Applied immediately while the screensaver is inactive
+	ss.SetNormalBrightness(120)
+	calls := disp.GetCalls()
+	if calls[len(calls)-1] != "SetBrightness([120])" {
+		t.Errorf("expected brightness 120 applied, got %v", calls)
+	}
+
+	// While dimmed the new level is only remembered
+	time.Sleep(100 * time.Millisecond)
+	ss.check()
+	disp.ClearCalls()
+	ss.SetNormalBrightness(200)
+	if calls := disp.GetCalls(); len(calls) != 0 {
+		t.Errorf("expected no brightness change while dimmed, got %v", calls)
+	}
+
+	// Waking restores the updated normal level
+	ss.ResetActivity()
+	calls = disp.GetCalls()
+	if calls[len(calls)-1] != "SetBrightness([200])" {
+		t.Errorf("expected brightness 200 restored on wake, got %v", calls)
+	}
+}
+
+type recordingShifter struct {
+	offsets [][2]int
+}
+
+func (r *recordingShifter) SetOffset(dx, dy int) {
+	r.offsets = append(r.offsets, [2]int{dx, dy})
+}
+
+func TestShiftMode(t *testing.T) {
+	cfg := Config{
+		Enabled:          true,
+		Mode:             ModeShift,
+		IdleTimeout:      50 * time.Millisecond,
+		NormalBrightness: 255,
+		ShiftPixels:      2,
+		ShiftInterval:    50 * time.Millisecond,
+	}
+
+	disp := display.NewMockDisplay(128, 64)
+	ss := New(cfg, disp, logger.NewDefault())
+	sh := &recordingShifter{}
+	ss.SetShifter(sh)
+
+	time.Sleep(100 * time.Millisecond)
+	ss.check()
+	if !ss.IsActive() {
+		t.Fatal("screen saver should be active")
+	}
+	if len(sh.offsets) != 1 || sh.offsets[0] != [2]int{2, 0} {
+		t.Fatalf("expected first shift to (2,0), got %v", sh.offsets)
+	}
+	for _, call := range disp.GetCalls() {
+		if call == "SetBrightness([0])" {
+			t.Error("shift mode should not blank the display")
+		}
+	}
+
+	// Not yet time for the next step
+	ss.check()
+	if len(sh.offsets) != 1 {
+		t.Errorf("expected no shift before interval, got %v", sh.offsets)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	ss.check()
+	if len(sh.offsets) != 2 || sh.offsets[1] != [2]int{2, 2} {
+		t.Errorf("expected second shift to (2,2), got %v", sh.offsets)
+	}
+
+	ss.ResetActivity()
+	if last := sh.offsets[len(sh.offsets)-1]; last != [2]int{0, 0} {
+		t.Errorf("expected offset reset on wake, got %v", last)
+	}
+}
+
+func TestClockMode(t *testing.T) {
+	cfg := Config{
+		Enabled:          true,
+		Mode:             ModeClock,
+		IdleTimeout:      50 * time.Millisecond,
+		DimBrightness:    30,
+		NormalBrightness: 255,
+	}
+
+	disp := display.NewMockDisplay(128, 64)
+	ss := New(cfg, disp, logger.NewDefault())
+
+	if ss.ShowClock() {
+		t.Error("clock should not show before idle timeout")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	ss.check()
+	if !ss.ShowClock() {
+		t.Fatal("clock should show once idle")
+	}
+	calls := disp.GetCalls()
+	if len(calls) == 0 || calls[len(calls)-1] != "SetBrightness([30])" {
+		t.Errorf("expected clock mode to dim the display, got %v", calls)
+	}
+	if got := ss.Brightness(); got != 30 {
+		t.Errorf("Brightness() = %d while clock shown, want 30", got)
+	}
+
+	ss.ResetActivity()
+	if ss.ShowClock() {
+		t.Error("clock should stop showing on activity")
+	}
+	if got := ss.Brightness(); got != 255 {
+		t.Errorf("Brightness() = %d after wake, want 255", got)
+	}
+}
+
+func TestNightCapsBrightness(t *testing.T) {
+	cfg := Config{
+		Enabled:          true,
+		Mode:             ModeDim,
+		IdleTimeout:      50 * time.Millisecond,
+		DimBrightness:    50,
+		NormalBrightness: 255,
+	}
+
+	disp := display.NewMockDisplay(128, 64)
+	ss := New(cfg, disp, logger.NewDefault())
+
+	ss.SetNight(true, 20)
+	calls := disp.GetCalls()
+	if len(calls) == 0 || calls[len(calls)-1] != "SetBrightness([20])" {
+		t.Errorf("expected night mode to apply its level, got %v", calls)
+	}
+
+	// Auto-brightness cannot raise the level above the night cap
+	ss.SetNormalBrightness(200)
+	if got := ss.Brightness(); got != 20 {
+		t.Errorf("Brightness() = %d at night, want 20", got)
+	}
+
+	// Dimming never brightens the display
+	time.Sleep(100 * time.Millisecond)
+	ss.check()
+	if got := ss.Brightness(); got != 20 {
+		t.Errorf("Brightness() = %d dimmed at night, want 20", got)
+	}
+
+	ss.ResetActivity()
+	ss.SetNight(false, 20)
+	calls = disp.GetCalls()
+	if calls[len(calls)-1] != "SetBrightness([200])" {
+		t.Errorf("expected the normal level after night ends, got %v", calls)
+	}
+}
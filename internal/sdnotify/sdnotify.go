@@ -0,0 +1,87 @@
+// Package sdnotify implements the systemd service notification protocol
+// (sd_notify) used by Type=notify units and the service watchdog.
+package sdnotify
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// Notification states understood by systemd
+const (
+	Ready     = "READY=1"
+	Reloading = "RELOADING=1"
+	Stopping  = "STOPPING=1"
+	Watchdog  = "WATCHDOG=1"
+)
+
+// Notify sends state to the service manager. It returns false without an
+// error when the process was not started with NOTIFY_SOCKET, so callers can
+// notify unconditionally.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// A leading @ names an abstract socket, which net handles itself
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WatchdogTimeout returns the watchdog timeout systemd expects pings
+// within, or 0 if the watchdog is not enabled for this process
+func WatchdogTimeout() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	// WATCHDOG_PID, when set, says which process the watchdog is meant for
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// RunWatchdog pings the watchdog every timeout/2 until ctx is cancelled,
+// but only while check returns nil. Withholding pings lets systemd restart
+// a daemon that is still running but no longer doing its job.
+func RunWatchdog(ctx context.Context, timeout time.Duration, check func() error, log *logger.Logger) {
+	ticker := time.NewTicker(timeout / 2)
+	defer ticker.Stop()
+
+	withholding := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := check(); err != nil {
+			if !withholding {
+				log.With().Err(err).Logger().Warn("Withholding watchdog ping, systemd will restart the service if this persists")
+				withholding = true
+			}
+			continue
+		}
+		if withholding {
+			log.Info("Health restored, resuming watchdog pings")
+			withholding = false
+		}
+		if _, err := Notify(Watchdog); err != nil {
+			log.ErrorWithErr(err, "Failed to send watchdog ping")
+		}
+	}
+}
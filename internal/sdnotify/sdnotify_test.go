@@ -0,0 +1,119 @@
+package sdnotify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// listen creates a notify socket and points NOTIFY_SOCKET at it
+func listen(t *testing.T) *net.UnixConn {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("ListenUnixgram() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func receive(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	buf := make([]byte, 256)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestNotify(t *testing.T) {
+	conn := listen(t)
+
+	sent, err := Notify(Ready)
+	if err != nil || !sent {
+		t.Fatalf("Notify() = %v, %v, want true, nil", sent, err)
+	}
+	if got := receive(t, conn); got != Ready {
+		t.Errorf("received %q, want %q", got, Ready)
+	}
+}
+
+func TestNotifyWithoutSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	sent, err := Notify(Ready)
+	if err != nil || sent {
+		t.Errorf("Notify() = %v, %v, want false, nil", sent, err)
+	}
+}
+
+func TestWatchdogTimeout(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name string
+		usec string
+		pid  string
+		want time.Duration
+	}{
+		{"unset", "", "", 0},
+		{"enabled", "30000000", "", 30 * time.Second},
+		{"own pid", "2000000", pid, 2 * time.Second},
+		{"other pid", "2000000", "1", 0},
+		{"invalid", "soon", "", 0},
+		{"zero", "0", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tt.usec)
+			t.Setenv("WATCHDOG_PID", tt.pid)
+			if got := WatchdogTimeout(); got != tt.want {
+				t.Errorf("WatchdogTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWatchdog(t *testing.T) {
+	conn := listen(t)
+
+	var healthy atomic.Bool
+	check := func() error {
+		if !healthy.Load() {
+			return errors.New("render loop stalled")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		RunWatchdog(ctx, 20*time.Millisecond, check, logger.NewDefault())
+		close(done)
+	}()
+
+	// No pings while the check fails
+	_ = conn.SetReadDeadline(time.Now().Add(60 * time.Millisecond))
+	if _, err := conn.Read(make([]byte, 64)); err == nil {
+		t.Fatal("watchdog pinged while check was failing")
+	}
+
+	healthy.Store(true)
+	if got := receive(t, conn); got != Watchdog {
+		t.Errorf("received %q, want %q", got, Watchdog)
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,366 @@
+// Package setup implements the interactive "i2c-displayd setup" wizard. It
+// looks for displays on the I2C buses, asks a few questions, shows a test
+// pattern to confirm the orientation and writes a validated config file.
+package setup
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/internal/renderer"
+)
+
+// Found is a display found on an I2C bus
+type Found struct {
+	Bus string
+	display.Detection
+}
+
+// Wizard asks its questions on Out and reads the answers from In, one per
+// line. An empty answer takes the default shown in brackets.
+type Wizard struct {
+	In  io.Reader
+	Out io.Writer
+
+	// Scan looks for displays; ScanBuses by default
+	Scan func() []Found
+	// Open creates and initializes the display showing the test pattern;
+	// display.NewDisplay by default
+	Open func(cfg *config.DisplayConfig) (display.Display, error)
+
+	lines *bufio.Scanner
+}
+
+// displayFile is the display section written to the config file, with only
+// the settings the wizard asked about
+type displayFile struct {
+	Type       string `json:"type"`
+	I2CBus     string `json:"i2c_bus,omitempty"`
+	I2CAddress string `json:"i2c_address,omitempty"`
+	SPIBus     string `json:"spi_bus,omitempty"`
+	DCPin      string `json:"dc_pin,omitempty"`
+	RSTPin     string `json:"rst_pin,omitempty"`
+	BusyPin    string `json:"busy_pin,omitempty"`
+	Rotation   int    `json:"rotation"`
+}
+
+// configFile is the config file the wizard writes
+type configFile struct {
+	Display    displayFile `json:"display"`
+	SystemInfo struct {
+		TemperatureUnit string `json:"temperature_unit"`
+	} `json:"system_info"`
+}
+
+// ScanBuses probes every /dev/i2c-* bus for a supported display
+func ScanBuses() []Found {
+	buses, _ := filepath.Glob("/dev/i2c-*")
+	var found []Found
+	for _, bus := range buses {
+		if det, err := display.Detect(bus); err == nil {
+			found = append(found, Found{Bus: bus, Detection: det})
+		}
+	}
+	return found
+}
+
+// Run walks through the questions and writes the config file to path
+func (w *Wizard) Run(path string) error {
+	if w.Scan == nil {
+		w.Scan = ScanBuses
+	}
+	if w.Open == nil {
+		w.Open = openDisplay
+	}
+	w.lines = bufio.NewScanner(w.In)
+
+	var f configFile
+	if err := w.chooseDisplay(&f.Display); err != nil {
+		return err
+	}
+	if err := w.chooseRotation(&f.Display); err != nil {
+		return err
+	}
+	unit, err := w.choose("Temperature unit", []string{"celsius", "fahrenheit"}, "celsius")
+	if err != nil {
+		return err
+	}
+	f.SystemInfo.TemperatureUnit = unit
+
+	return w.write(path, f)
+}
+
+// chooseDisplay offers a display found on the buses, or asks for the type
+// and how it is wired
+func (w *Wizard) chooseDisplay(d *displayFile) error {
+	w.printf("Looking for displays on the I2C buses...\n")
+	for _, found := range w.Scan() {
+		ok, err := w.confirm(fmt.Sprintf("Found %s at %s on %s. Use it?", found.Type, found.Address, found.Bus), true)
+		if err != nil {
+			return err
+		}
+		if ok {
+			d.Type, d.I2CBus, d.I2CAddress = found.Type, found.Bus, found.Address
+			return nil
+		}
+	}
+
+	types := slices.DeleteFunc(config.DisplayTypes(), func(t string) bool {
+		dc := config.DisplayConfig{Type: t}
+		return dc.IsPreview()
+	})
+	var err error
+	if d.Type, err = w.choose("Display type", types, "ssd1306"); err != nil {
+		return err
+	}
+
+	dc := config.DisplayConfig{Type: d.Type}
+	defaults := config.Default().Display
+	switch {
+	case dc.IsEPaper():
+		// Pins of the Waveshare 2.13" HAT
+		return w.askAll([]question{
+			{"SPI bus", "SPI0.0", &d.SPIBus},
+			{"Data/command GPIO", "GPIO25", &d.DCPin},
+			{"Reset GPIO", "GPIO17", &d.RSTPin},
+			{"Busy GPIO", "GPIO24", &d.BusyPin},
+		})
+	case dc.IsSPI():
+		return w.askAll([]question{
+			{"SPI bus", "SPI0.0", &d.SPIBus},
+			{"Data/command GPIO", "GPIO24", &d.DCPin},
+			{"Reset GPIO", "GPIO25", &d.RSTPin},
+		})
+	case strings.HasPrefix(d.Type, "uctronics"):
+		// The bridge is always at 0x18 on the default bus
+		return nil
+	case dc.IsI2C():
+		return w.askAll([]question{
+			{"I2C bus", defaults.I2CBus, &d.I2CBus},
+			{"I2C address", defaults.I2CAddress, &d.I2CAddress},
+		})
+	}
+	return nil
+}
+
+// chooseRotation asks for the rotation until the test pattern shows the
+// right way up, or the display cannot be opened
+func (w *Wizard) chooseRotation(d *displayFile) error {
+	rotation := "0"
+	for {
+		var err error
+		rotation, err = w.choose("Rotation in quarter turns clockwise", []string{"0", "1", "2", "3"}, rotation)
+		if err != nil {
+			return err
+		}
+		d.Rotation, _ = strconv.Atoi(rotation)
+
+		disp, err := w.showTestPattern(d)
+		if err != nil {
+			w.printf("Could not show the test pattern: %v\n", err)
+			ok, err := w.confirm("Write the config anyway?", false)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New("setup cancelled")
+			}
+			return nil
+		}
+		ok, err := w.confirm("Does the display read \"TOP\" along its top edge?", true)
+		_ = disp.Clear()
+		_ = disp.Show()
+		_ = disp.Close()
+		if err != nil || ok {
+			return err
+		}
+	}
+}
+
+// showTestPattern opens the display as configured so far and draws a
+// border with "TOP" along the top edge
+func (w *Wizard) showTestPattern(d *displayFile) (display.Display, error) {
+	cfg := config.Default()
+	applyDisplay(&cfg.Display, d)
+	disp, err := w.Open(&cfg.Display)
+	if err != nil {
+		return nil, err
+	}
+	if err := drawTestPattern(disp); err != nil {
+		_ = disp.Close()
+		return nil, err
+	}
+	return disp, nil
+}
+
+// drawTestPattern draws the orientation test pattern
+func drawTestPattern(disp display.Display) error {
+	if display.AsColorDisplay(disp).Capabilities().Text() {
+		if err := display.WriteLines(disp, []string{"TOP", "i2c-display"}); err != nil {
+			return err
+		}
+		return disp.Show()
+	}
+
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+	b := disp.GetBounds()
+	if err := disp.DrawRect(0, 0, b.Dx(), b.Dy(), false); err != nil {
+		return err
+	}
+	if err := renderer.DrawTextCentered(disp, 2, "TOP"); err != nil {
+		return err
+	}
+	if err := renderer.DrawText(disp, 2, b.Dy()/2, "i2c-display"); err != nil {
+		return err
+	}
+	return disp.Show()
+}
+
+// openDisplay creates and initializes the configured display
+func openDisplay(cfg *config.DisplayConfig) (display.Display, error) {
+	disp, err := display.NewDisplay(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := disp.Init(); err != nil {
+		_ = disp.Close()
+		return nil, err
+	}
+	return disp, nil
+}
+
+// applyDisplay copies the wizard's display settings into cfg
+func applyDisplay(cfg *config.DisplayConfig, d *displayFile) {
+	cfg.Type, cfg.Rotation = d.Type, d.Rotation
+	if d.I2CBus != "" {
+		cfg.I2CBus = d.I2CBus
+	}
+	if d.I2CAddress != "" {
+		cfg.I2CAddress = d.I2CAddress
+	}
+	cfg.SPIBus, cfg.DCPin, cfg.RSTPin, cfg.BusyPin = d.SPIBus, d.DCPin, d.RSTPin, d.BusyPin
+	cfg.ApplyDisplayDefaults()
+}
+
+// write saves f to path once it loads as a valid config, asking before
+// replacing an existing file
+func (w *Wizard) write(path string, f configFile) error {
+	if _, err := os.Stat(path); err == nil {
+		ok, err := w.confirm(fmt.Sprintf("%s exists. Replace it?", path), false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("setup cancelled")
+		}
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { // #nosec G301 -- config directories are world-readable
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil { // #nosec G306 -- the config holds no secrets yet
+		return err
+	}
+	if _, err := config.Load(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	w.printf("Wrote %s. Start the service with: sudo systemctl enable --now i2c-display\n", path)
+	return nil
+}
+
+// question is one of a series of questions with free-form answers
+type question struct {
+	prompt, def string
+	answer      *string
+}
+
+// askAll asks each question in turn
+func (w *Wizard) askAll(questions []question) error {
+	for _, q := range questions {
+		var err error
+		if *q.answer, err = w.ask(q.prompt, q.def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ask asks a question and returns the answer, or def if it is empty
+func (w *Wizard) ask(prompt, def string) (string, error) {
+	w.printf("%s [%s]: ", prompt, def)
+	if !w.lines.Scan() {
+		if err := w.lines.Err(); err != nil {
+			return "", err
+		}
+		return "", errors.New("setup cancelled: no more input")
+	}
+	if answer := strings.TrimSpace(w.lines.Text()); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// choose asks until the answer is one of choices, listing them after a
+// wrong one
+func (w *Wizard) choose(prompt string, choices []string, def string) (string, error) {
+	for {
+		answer, err := w.ask(prompt, def)
+		if err != nil {
+			return "", err
+		}
+		if slices.Contains(choices, answer) {
+			return answer, nil
+		}
+		w.printf("Choose one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// confirm asks a yes/no question
+func (w *Wizard) confirm(prompt string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		answer, err := w.ask(prompt, hint)
+		if err != nil {
+			return false, err
+		}
+		if answer == hint {
+			return def, nil
+		}
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// printf writes to the wizard's output
+func (w *Wizard) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(w.Out, format, args...)
+}
@@ -0,0 +1,92 @@
+package setup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/display"
+)
+
+func TestWizardFoundDisplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "i2c-display", "config.json")
+	var out strings.Builder
+	var opened []int // rotation of each display opened
+	w := &Wizard{
+		// Accept the display, try rotation 1, then 2, and pick fahrenheit
+		In:  strings.NewReader("\n1\nn\n2\ny\nkelvin\nfahrenheit\n"),
+		Out: &out,
+		Scan: func() []Found {
+			return []Found{{Bus: "/dev/i2c-3", Detection: display.Detection{Type: "sh1106", Address: "0x3D"}}}
+		},
+		Open: func(cfg *config.DisplayConfig) (display.Display, error) {
+			opened = append(opened, cfg.Rotation)
+			return display.NewMockDisplay(cfg.Width, cfg.Height), nil
+		},
+	}
+	if err := w.Run(path); err != nil {
+		t.Fatalf("Run() failed: %v\n%s", err, out.String())
+	}
+	if len(opened) != 2 || opened[0] != 1 || opened[1] != 2 {
+		t.Errorf("expected the test pattern at rotations 1 and 2, got %v", opened)
+	}
+	if !strings.Contains(out.String(), "Choose one of: celsius, fahrenheit") {
+		t.Errorf("expected the units listed after a wrong answer:\n%s", out.String())
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("written config does not load: %v", err)
+	}
+	if cfg.Display.Type != "sh1106" || cfg.Display.I2CBus != "/dev/i2c-3" || cfg.Display.I2CAddress != "0x3D" || cfg.Display.Rotation != 2 {
+		t.Errorf("unexpected display config %+v", cfg.Display)
+	}
+	if cfg.SystemInfo.TemperatureUnit != "fahrenheit" {
+		t.Errorf("temperature unit = %q, want fahrenheit", cfg.SystemInfo.TemperatureUnit)
+	}
+}
+
+func TestWizardSPIDisplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w := &Wizard{
+		// Type the display and take the default pins; the display cannot
+		// be opened, so write the config anyway and replace the old file
+		In:   strings.NewReader("st7789\n\nGPIO5\n\n\ny\n\ny\n"),
+		Out:  &strings.Builder{},
+		Scan: func() []Found { return nil },
+		Open: func(*config.DisplayConfig) (display.Display, error) {
+			return nil, errors.New("no SPI here")
+		},
+	}
+	if err := w.Run(path); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("written config does not load: %v", err)
+	}
+	if cfg.Display.SPIBus != "SPI0.0" || cfg.Display.DCPin != "GPIO5" || cfg.Display.RSTPin != "GPIO25" {
+		t.Errorf("unexpected display config %+v", cfg.Display)
+	}
+}
+
+func TestWizardCancelled(t *testing.T) {
+	w := &Wizard{
+		In:   strings.NewReader("ssd1306\n"),
+		Out:  &strings.Builder{},
+		Scan: func() []Found { return nil },
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := w.Run(path); err == nil {
+		t.Fatal("expected an error when the input ends")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no config written, got %v", err)
+	}
+}
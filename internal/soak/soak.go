@@ -0,0 +1,254 @@
+// Package soak implements the -soak burn-in test: it flushes worst-case
+// frames back to back for a set time and records how many flushes fail and
+// how long they take, to qualify new panels and I2C cabling.
+package soak
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+)
+
+// Frame is one of the frames cycled by the soak test. Draw fills the
+// display's buffer; the test flushes it.
+type Frame struct {
+	Name string
+	Draw func(disp display.Display) error
+}
+
+// Options configure a soak test
+type Options struct {
+	Duration       time.Duration // how long to run; stops early when the context is cancelled
+	ReportInterval time.Duration // how often OnReport is called; 0 only reports at the end
+	OnReport       func(Report)  // called with the totals so far
+	Frames         []Frame       // frames to cycle; DefaultFrames when empty
+}
+
+// Report summarises a soak test so far
+type Report struct {
+	Elapsed time.Duration
+	Flushes int            // calls to Show
+	Errors  int            // failed draws and flushes
+	ByFrame map[string]int // errors by frame name
+	LastErr error
+
+	Min, Mean, Max time.Duration // flush latency
+	P50, P95, P99  time.Duration // flush latency percentiles, to about 20%
+}
+
+// ErrorRate returns the share of flushes that failed
+func (r Report) ErrorRate() float64 {
+	if r.Flushes == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Flushes)
+}
+
+// Run cycles the frames on disp until the duration passes or ctx is
+// cancelled, and returns the final report. Errors are counted, not
+// returned, so a flaky bus runs to the end; the error is only for a test
+// that could not start.
+func Run(ctx context.Context, disp display.Display, opts Options) (Report, error) {
+	frames := opts.Frames
+	if len(frames) == 0 {
+		frames = DefaultFrames(disp)
+	}
+	if opts.Duration <= 0 {
+		return Report{}, fmt.Errorf("soak duration must be positive, got %v", opts.Duration)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	r := newRecorder()
+	start := time.Now()
+	nextReport := start.Add(opts.ReportInterval)
+	for i := 0; ctx.Err() == nil; i++ {
+		f := frames[i%len(frames)]
+		if err := f.Draw(disp); err != nil {
+			r.fail(f.Name, err)
+			continue
+		}
+		t := time.Now()
+		err := disp.Show()
+		r.flush(time.Since(t))
+		if err != nil {
+			r.fail(f.Name, err)
+		}
+
+		if opts.ReportInterval > 0 && opts.OnReport != nil && !time.Now().Before(nextReport) {
+			opts.OnReport(r.report(time.Since(start)))
+			nextReport = nextReport.Add(opts.ReportInterval)
+		}
+	}
+
+	report := r.report(time.Since(start))
+	if opts.OnReport != nil {
+		opts.OnReport(report)
+	}
+	return report, nil
+}
+
+// DefaultFrames returns the worst-case frames for disp: every pixel lit, a
+// one-pixel checkerboard and its inverse, which toggle every bit of the
+// buffer between frames, and a full page of text that changes each time.
+// Character displays get full and alternating cells instead.
+func DefaultFrames(disp display.Display) []Frame {
+	caps := display.AsColorDisplay(disp).Capabilities()
+	if caps.Text() {
+		return textFrames(caps.TextColumns, caps.TextRows)
+	}
+
+	page := 0
+	return []Frame{
+		{Name: "white", Draw: func(d display.Display) error {
+			b := d.GetBounds()
+			return d.DrawRect(0, 0, b.Dx(), b.Dy(), true)
+		}},
+		{Name: "checkerboard", Draw: func(d display.Display) error { return drawChecker(d, 0) }},
+		{Name: "inverse checkerboard", Draw: func(d display.Display) error { return drawChecker(d, 1) }},
+		{Name: "page", Draw: func(d display.Display) error {
+			page++
+			if err := d.Clear(); err != nil {
+				return err
+			}
+			b := d.GetBounds()
+			for y := 0; y+8 <= b.Dy(); y += 10 {
+				line := fmt.Sprintf("PAGE %d %s", page, strings.Repeat("#", b.Dx()/6))
+				if err := d.DrawText(0, y, line, display.FontSmall); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+	}
+}
+
+// drawChecker draws a one-pixel checkerboard, with the top left pixel lit
+// when phase is 0
+func drawChecker(d display.Display, phase int) error {
+	b := d.GetBounds()
+	for y := range b.Dy() {
+		for x := range b.Dx() {
+			if err := d.DrawPixel(x, y, (x+y+phase)%2 == 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// textFrames are the soak frames for a character display
+func textFrames(cols, rows int) []Frame {
+	lines := func(fill func(row int) string) []string {
+		l := make([]string, rows)
+		for i := range l {
+			l[i] = fill(i)
+		}
+		return l
+	}
+	alternate := func(phase int) func(int) string {
+		return func(row int) string {
+			if (row+phase)%2 == 0 {
+				return strings.Repeat("#", cols)
+			}
+			return strings.Repeat(" ", cols)
+		}
+	}
+	show := func(l []string) func(display.Display) error {
+		return func(d display.Display) error { return display.WriteLines(d, l) }
+	}
+	return []Frame{
+		{Name: "full", Draw: show(lines(func(int) string { return strings.Repeat("#", cols) }))},
+		{Name: "alternate rows", Draw: show(lines(alternate(0)))},
+		{Name: "inverse alternate rows", Draw: show(lines(alternate(1)))},
+	}
+}
+
+// latencyBuckets is the number of histogram buckets, four per doubling of
+// microseconds, reaching past a minute
+const latencyBuckets = 4 * 26
+
+// recorder accumulates the results of a soak test. Latencies go into a
+// fixed histogram so a soak of days uses no more memory than one of minutes.
+type recorder struct {
+	flushes, errors int
+	byFrame         map[string]int
+	lastErr         error
+
+	total, min, max time.Duration
+	buckets         [latencyBuckets]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{byFrame: map[string]int{}}
+}
+
+// flush records the latency of a flush
+func (r *recorder) flush(d time.Duration) {
+	if r.flushes == 0 || d < r.min {
+		r.min = d
+	}
+	r.max = max(r.max, d)
+	r.total += d
+	r.flushes++
+	r.buckets[latencyBucket(d)]++
+}
+
+// fail records an error drawing or flushing frame
+func (r *recorder) fail(frame string, err error) {
+	r.errors++
+	r.byFrame[frame]++
+	r.lastErr = err
+}
+
+// report returns the totals so far
+func (r *recorder) report(elapsed time.Duration) Report {
+	rep := Report{
+		Elapsed: elapsed,
+		Flushes: r.flushes,
+		Errors:  r.errors,
+		ByFrame: make(map[string]int, len(r.byFrame)),
+		LastErr: r.lastErr,
+		Min:     r.min,
+		Max:     r.max,
+	}
+	for name, n := range r.byFrame {
+		rep.ByFrame[name] = n
+	}
+	if r.flushes > 0 {
+		rep.Mean = r.total / time.Duration(r.flushes)
+		rep.P50 = r.percentile(0.50)
+		rep.P95 = r.percentile(0.95)
+		rep.P99 = r.percentile(0.99)
+	}
+	return rep
+}
+
+// percentile returns the upper bound of the bucket holding the p quantile,
+// within the observed range
+func (r *recorder) percentile(p float64) time.Duration {
+	want := int(math.Ceil(p * float64(r.flushes)))
+	seen := 0
+	for i, n := range r.buckets {
+		seen += n
+		if seen >= want {
+			upper := time.Duration(math.Exp2(float64(i+1)/4)) * time.Microsecond
+			return min(max(upper, r.min), r.max)
+		}
+	}
+	return r.max
+}
+
+// latencyBucket returns the histogram bucket of d
+func latencyBucket(d time.Duration) int {
+	us := float64(d.Microseconds())
+	if us < 1 {
+		return 0
+	}
+	return min(int(4*math.Log2(us)), latencyBuckets-1)
+}
@@ -0,0 +1,104 @@
+package soak
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/display"
+)
+
+func TestRun(t *testing.T) {
+	disp := display.NewMockDisplay(128, 64)
+	var reports []Report
+	rep, err := Run(context.Background(), disp, Options{
+		Duration:       100 * time.Millisecond,
+		ReportInterval: 20 * time.Millisecond,
+		OnReport:       func(r Report) { reports = append(reports, r) },
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if rep.Flushes == 0 {
+		t.Fatal("no frames flushed")
+	}
+	if rep.Errors != 0 || rep.ErrorRate() != 0 {
+		t.Errorf("errors = %d (%v), want none", rep.Errors, rep.LastErr)
+	}
+	if rep.Min > rep.P50 || rep.P50 > rep.P95 || rep.P95 > rep.P99 || rep.P99 > rep.Max {
+		t.Errorf("latencies out of order: min %v p50 %v p95 %v p99 %v max %v", rep.Min, rep.P50, rep.P95, rep.P99, rep.Max)
+	}
+	if len(reports) < 2 {
+		t.Fatalf("got %d reports, want progress reports and a final one", len(reports))
+	}
+	if last := reports[len(reports)-1]; last.Flushes != rep.Flushes {
+		t.Errorf("final report has %d flushes, Run returned %d", last.Flushes, rep.Flushes)
+	}
+}
+
+func TestRunCountsErrors(t *testing.T) {
+	disp := display.NewFaultyDisplay(display.NewMockDisplay(32, 16), 1)
+	rep, err := Run(context.Background(), disp, Options{Duration: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if rep.Flushes == 0 || rep.Errors != rep.Flushes {
+		t.Errorf("got %d errors in %d flushes, want every flush to fail", rep.Errors, rep.Flushes)
+	}
+	if rep.ErrorRate() != 1 {
+		t.Errorf("ErrorRate() = %v, want 1", rep.ErrorRate())
+	}
+	if rep.ByFrame["white"] == 0 || rep.ByFrame["checkerboard"] == 0 {
+		t.Errorf("ByFrame = %v, want errors for each frame", rep.ByFrame)
+	}
+	if !errors.Is(rep.LastErr, display.ErrInjectedFault) {
+		t.Errorf("LastErr = %v, want an injected fault", rep.LastErr)
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rep, err := Run(ctx, display.NewMockDisplay(32, 16), Options{Duration: time.Hour})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if rep.Flushes != 0 {
+		t.Errorf("flushed %d frames after cancel", rep.Flushes)
+	}
+}
+
+func TestRunRejectsZeroDuration(t *testing.T) {
+	if _, err := Run(context.Background(), display.NewMockDisplay(32, 16), Options{}); err == nil {
+		t.Error("expected an error for a zero duration")
+	}
+}
+
+func TestRecorderPercentiles(t *testing.T) {
+	r := newRecorder()
+	for range 90 {
+		r.flush(time.Millisecond)
+	}
+	for range 10 {
+		r.flush(100 * time.Millisecond)
+	}
+	rep := r.report(time.Second)
+
+	near := func(got, want time.Duration) bool {
+		return got >= want && got <= want*6/5
+	}
+	if !near(rep.P50, time.Millisecond) {
+		t.Errorf("P50 = %v, want about 1ms", rep.P50)
+	}
+	if rep.P95 != 100*time.Millisecond || rep.P99 != 100*time.Millisecond {
+		t.Errorf("P95, P99 = %v, %v, want 100ms", rep.P95, rep.P99)
+	}
+	if rep.Min != time.Millisecond || rep.Max != 100*time.Millisecond {
+		t.Errorf("min, max = %v, %v", rep.Min, rep.Max)
+	}
+	if want := (90*time.Millisecond + time.Second) / 100; rep.Mean != want {
+		t.Errorf("Mean = %v, want %v", rep.Mean, want)
+	}
+}
@@ -0,0 +1,63 @@
+package stats
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+)
+
+// ifaFlags is the IFA_FLAGS address attribute: the address flags widened to
+// 32 bits, superseding the 8-bit field in the message header when present
+const ifaFlags = 8
+
+// addressSources returns how each IPv4 address on the system was assigned,
+// keyed by address. Addresses added with a finite lifetime, as DHCP clients
+// do, lack the kernel's permanent flag; manually configured ones have it.
+func addressSources() (map[string]string, error) {
+	rib, err := syscall.NetlinkRIB(syscall.RTM_GETADDR, syscall.AF_INET)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]string)
+	for i := range msgs {
+		m := &msgs[i]
+		if m.Header.Type != syscall.RTM_NEWADDR || len(m.Data) < syscall.SizeofIfAddrmsg {
+			continue
+		}
+		attrs, err := syscall.ParseNetlinkRouteAttr(m)
+		if err != nil {
+			continue
+		}
+		flags := uint32(m.Data[2]) // ifaddrmsg.ifa_flags
+		var addr net.IP
+		for _, a := range attrs {
+			switch a.Attr.Type {
+			case syscall.IFA_LOCAL:
+				addr = net.IP(a.Value)
+			case syscall.IFA_ADDRESS:
+				// The peer on point-to-point links; IFA_LOCAL is ours
+				if addr == nil {
+					addr = net.IP(a.Value)
+				}
+			case ifaFlags:
+				if len(a.Value) >= 4 {
+					flags = binary.NativeEndian.Uint32(a.Value)
+				}
+			}
+		}
+		if addr == nil {
+			continue
+		}
+		if flags&syscall.IFA_F_PERMANENT != 0 {
+			sources[addr.String()] = AddressingStatic
+		} else {
+			sources[addr.String()] = AddressingDHCP
+		}
+	}
+	return sources, nil
+}
@@ -0,0 +1,14 @@
+package stats
+
+import "testing"
+
+func TestAddressSourcesLoopback(t *testing.T) {
+	sources, err := addressSources()
+	if err != nil {
+		t.Skipf("netlink unavailable: %v", err)
+	}
+	// The kernel configures the loopback address itself, without a lifetime
+	if got, ok := sources["127.0.0.1"]; ok && got != AddressingStatic {
+		t.Errorf("127.0.0.1 is %q, want %q", got, AddressingStatic)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package stats
+
+import "errors"
+
+// addressSources reports that address assignment is only known on Linux
+func addressSources() (map[string]string, error) {
+	return nil, errors.New("address sources are only available on Linux")
+}
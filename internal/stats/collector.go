@@ -1,18 +1,56 @@
 package stats
 
+import "time"
+
 // SystemStats contains all collected system information
 type SystemStats struct {
-	Hostname    string
-	CPUTemp     float64 // in degrees Celsius
-	MemoryUsed  uint64  // in bytes
-	MemoryTotal uint64  // in bytes
-	DiskUsed    uint64  // in bytes
-	DiskTotal   uint64  // in bytes
-	Interfaces  []NetInterface
-	LoadAvg1    float64 // 1-minute load average
-	LoadAvg5    float64 // 5-minute load average
-	LoadAvg15   float64 // 15-minute load average
-	NumCPU      int     // number of logical CPUs
+	Hostname        string
+	CPUTemp         float64 // in degrees Celsius
+	MemoryUsed      uint64  // in bytes
+	MemoryTotal     uint64  // in bytes
+	DiskUsed        uint64  // in bytes
+	DiskTotal       uint64  // in bytes
+	DiskInodesUsed  uint64
+	DiskInodesTotal uint64 // 0 on filesystems without a fixed inode count
+	DiskReadOnly    bool   // the disk_path filesystem is mounted read-only
+	Interfaces      []NetInterface
+	DNSServers      []string      // name servers in resolver order
+	LoadAvg1        float64       // 1-minute load average
+	LoadAvg5        float64       // 5-minute load average
+	LoadAvg15       float64       // 15-minute load average
+	NumCPU          int           // number of logical CPUs
+	Uptime          time.Duration // time since boot; 0 when unknown
+
+	Temperatures []TempReading  // named sensors from system_info.temperature_sensors
+	Throttled    *ThrottleFlags // Raspberry Pi throttling flags; nil elsewhere
+
+	// Processes using the most CPU and memory, busiest first; nil when
+	// pages.top is disabled
+	TopCPU    []ProcessInfo
+	TopMemory []ProcessInfo
+
+	Flash []FlashInfo // SD cards and eMMC devices; nil when pages.storage is disabled
+
+	Exec     map[string]ExecOutput // finished pages.exec command output, keyed by page title
+	HTTPJSON map[string]ExecOutput // finished pages.http_json fields, a line each, keyed by page title
+
+	Connectivity *ConnectivityStatus // latest reachability check; nil when disabled or before the first
+	Latency      []LatencyReading    // latest ping per latency monitor host; nil when disabled or before the first
+	Updates      *UpdateStatus       // latest pending update check; nil when disabled or before the first
+	TimeSync     *TimeSyncStatus     // latest clock synchronisation check; nil when disabled or before the first
+
+	Fan *FanStatus // set by the rotation manager when fan control is enabled
+}
+
+// FanStatus is the state of the temperature-controlled fan
+type FanStatus struct {
+	Duty int // speed in percent, 0 when off
+}
+
+// TempReading is a single named temperature sensor value
+type TempReading struct {
+	Name  string
+	Value float64 // in the configured temperature unit
 }
 
 // NetInterface represents a network interface with its addresses
@@ -20,13 +58,38 @@ type NetInterface struct {
 	Name      string
 	IPv4Addrs []string
 	IPv6Addrs []string
+	Gateway   string // IPv4 default gateway through the interface; "" when none
+	// Addressing tells how the first IPv4 address was assigned:
+	// AddressingDHCP, AddressingStatic, or "" when unknown
+	Addressing string
 }
 
+// How an interface's address was assigned (NetInterface.Addressing)
+const (
+	AddressingDHCP   = "dhcp"
+	AddressingStatic = "static"
+)
+
 // Collector is the interface for collecting system statistics
 type Collector interface {
 	Collect() (*SystemStats, error)
 }
 
+// TimedCollector is a Collector that also reports how long each data source
+// took to read during the last Collect (see SystemCollector.LastTimings)
+type TimedCollector interface {
+	Collector
+	LastTimings() map[string]time.Duration
+}
+
+// InvalidatingCollector is a Collector whose cached readings can be
+// discarded before their refresh interval ends (see
+// SystemCollector.Invalidate)
+type InvalidatingCollector interface {
+	Collector
+	Invalidate(source string)
+}
+
 // MemoryPercent returns memory usage as a percentage
 func (s *SystemStats) MemoryPercent() float64 {
 	if s.MemoryTotal == 0 {
@@ -43,6 +106,14 @@ func (s *SystemStats) DiskPercent() float64 {
 	return (float64(s.DiskUsed) / float64(s.DiskTotal)) * 100
 }
 
+// DiskInodePercent returns inode usage as a percentage
+func (s *SystemStats) DiskInodePercent() float64 {
+	if s.DiskInodesTotal == 0 {
+		return 0
+	}
+	return (float64(s.DiskInodesUsed) / float64(s.DiskInodesTotal)) * 100
+}
+
 // MemoryUsedGB returns memory used in gigabytes
 func (s *SystemStats) MemoryUsedGB() float64 {
 	return float64(s.MemoryUsed) / (1024 * 1024 * 1024)
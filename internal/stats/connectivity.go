@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+// maxPublicIPResponse caps how much of the public IP service's reply is read
+const maxPublicIPResponse = 256
+
+// ConnectivityStatus is the result of the latest internet reachability check
+type ConnectivityStatus struct {
+	Up       bool
+	Latency  time.Duration // how long the last successful check took
+	Err      error         // why the last check failed
+	At       time.Time     // when the last check finished
+	PublicIP string        // last looked-up public address; "" when unknown or not allowed
+}
+
+// connectivityRunner checks reachability in the background on its interval,
+// so a slow or unreachable target never holds up Collect. When allowed it
+// also looks up the public IP, at most once per public IP interval.
+type connectivityRunner struct {
+	target  string
+	timeout time.Duration
+
+	publicIPURL      string // "" when the lookup is not allowed
+	publicIPInterval time.Duration
+
+	client *http.Client
+	dialer net.Dialer
+
+	ipFetched time.Time // when the public IP was last looked up successfully, by update
+
+	runner[ConnectivityStatus]
+}
+
+// newConnectivityRunner creates a runner for the connectivity config.
+// Durations are validated at config load time.
+func newConnectivityRunner(cfg config.ConnectivityConfig) *connectivityRunner {
+	interval, _ := time.ParseDuration(cfg.Interval)
+	timeout, _ := time.ParseDuration(cfg.Timeout)
+	r := &connectivityRunner{
+		target:  cfg.Target,
+		timeout: timeout,
+		client:  &http.Client{},
+	}
+	if cfg.PublicIP {
+		r.publicIPURL = cfg.PublicIPURL
+		r.publicIPInterval, _ = time.ParseDuration(cfg.PublicIPInterval)
+	}
+	r.runner = runner[ConnectivityStatus]{interval: interval, check: r.update}
+	return r
+}
+
+// update checks the target once, then refreshes the public IP if it is due
+func (r *connectivityRunner) update(status ConnectivityStatus) ConnectivityStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	start := time.Now()
+	err := r.probe(ctx)
+	latency := time.Since(start)
+	cancel()
+
+	status.Up = err == nil
+	status.Err = err
+	if err == nil {
+		status.Latency = latency
+	}
+	// A failed lookup keeps the cached address and is retried on the next check
+	if err == nil && r.publicIPURL != "" &&
+		(r.ipFetched.IsZero() || time.Since(r.ipFetched) >= r.publicIPInterval) {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		if ip, err := r.lookupPublicIP(ctx); err == nil {
+			status.PublicIP = ip
+			r.ipFetched = time.Now()
+		}
+		cancel()
+	}
+	status.At = time.Now()
+	return status
+}
+
+// probe sends a HEAD request to an http(s) target, or connects to a
+// host:port target over TCP. Any HTTP response counts as reachable.
+func (r *connectivityRunner) probe(ctx context.Context) error {
+	if !strings.Contains(r.target, "://") {
+		conn, err := r.dialer.DialContext(ctx, "tcp", r.target)
+		if err != nil {
+			return fmt.Errorf("connect %s: %w", r.target, err)
+		}
+		return conn.Close()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, r.target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HEAD %s: %w", r.target, err)
+	}
+	return resp.Body.Close()
+}
+
+// lookupPublicIP fetches the public address as plain text from the
+// configured service
+func (r *connectivityRunner) lookupPublicIP(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.publicIPURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("public IP lookup: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("public IP lookup: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPublicIPResponse))
+	if err != nil {
+		return "", fmt.Errorf("public IP lookup: %w", err)
+	}
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return "", fmt.Errorf("public IP lookup: response is not an address")
+	}
+	return ip.String(), nil
+}
@@ -0,0 +1,92 @@
+package stats
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+func connectivityConfig(target string) config.ConnectivityConfig {
+	cfg := config.Default().Connectivity
+	cfg.Enabled = true
+	cfg.Target = target
+	return cfg
+}
+
+func TestConnectivityRunnerTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+
+	status := waitForRun(t, &newConnectivityRunner(connectivityConfig(addr)).runner, time.Now())
+	if !status.Up || status.Err != nil {
+		t.Errorf("expected %s to be reachable, got %+v", addr, status)
+	}
+
+	// Nothing listens once the listener is closed
+	_ = ln.Close()
+	status = waitForRun(t, &newConnectivityRunner(connectivityConfig(addr)).runner, time.Now())
+	if status.Up || status.Err == nil {
+		t.Errorf("expected %s to be unreachable, got %+v", addr, status)
+	}
+}
+
+func TestConnectivityRunnerHTTPAndPublicIP(t *testing.T) {
+	var lookups atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/ip":
+			lookups.Add(1)
+			_, _ = w.Write([]byte("203.0.113.9\n"))
+		default:
+			// Any response means the internet is reachable
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := connectivityConfig(srv.URL + "/check")
+	cfg.PublicIP = true
+	cfg.PublicIPURL = srv.URL + "/ip"
+	cfg.Interval = "1m"
+	r := newConnectivityRunner(cfg)
+
+	start := time.Now()
+	status := waitForRun(t, &r.runner, start)
+	if !status.Up || status.PublicIP != "203.0.113.9" {
+		t.Fatalf("expected up with a public IP, got %+v", status)
+	}
+
+	// The next check is within the public IP interval, so the cached address
+	// is kept without another lookup
+	status = waitForRun(t, &r.runner, start.Add(time.Minute))
+	if n := lookups.Load(); n != 1 {
+		t.Errorf("expected one public IP lookup, got %d", n)
+	}
+	if status.PublicIP != "203.0.113.9" {
+		t.Errorf("expected the cached public IP, got %q", status.PublicIP)
+	}
+}
+
+func TestConnectivityRunnerPublicIPNotAllowed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/ip" {
+			t.Error("public IP looked up although not allowed")
+		}
+	}))
+	defer srv.Close()
+
+	cfg := connectivityConfig(srv.URL)
+	cfg.PublicIPURL = srv.URL + "/ip"
+	r := newConnectivityRunner(cfg)
+	if status := waitForRun(t, &r.runner, time.Now()); status.PublicIP != "" {
+		t.Errorf("expected no public IP, got %q", status.PublicIP)
+	}
+}
@@ -3,28 +3,68 @@ package stats
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
 
+// Temperature source keywords understood by CPUTempCollector.
+const (
+	// TempSourceVcgencmd reads the Raspberry Pi SoC/GPU temperature via `vcgencmd measure_temp`.
+	TempSourceVcgencmd = "vcgencmd"
+	// TempSourceHwmonPrefix selects a hwmon sensor by chip name or tempN_label, e.g. "hwmon:cpu_thermal".
+	TempSourceHwmonPrefix = "hwmon:"
+)
+
+const defaultHwmonRoot = "/sys/class/hwmon"
+
 // CPUTempCollector collects CPU temperature
 type CPUTempCollector struct {
-	source string
+	source    string
+	hwmonRoot string
+	runCmd    func(name string, args ...string) ([]byte, error)
 }
 
-// NewCPUTempCollector creates a new CPU temperature collector
+// NewCPUTempCollector creates a new CPU temperature collector.
+// source may be a sysfs file path, "vcgencmd", or "hwmon:<label>".
 func NewCPUTempCollector(source string) *CPUTempCollector {
 	return &CPUTempCollector{
-		source: source,
+		source:    source,
+		hwmonRoot: defaultHwmonRoot,
+		runCmd:    runCommand,
 	}
 }
 
-// GetTemperature reads the CPU temperature from sysfs
+// runCommand executes a command and returns its standard output.
+func runCommand(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output() // #nosec G204 -- command name is a fixed keyword, not user input
+}
+
+// GetTemperature reads the temperature from the configured source.
 // Returns temperature in Celsius
 func (c *CPUTempCollector) GetTemperature() (float64, error) {
-	data, err := os.ReadFile(c.source)
+	switch {
+	case c.source == "":
+		return 0, fmt.Errorf("no temperature source configured")
+	case c.source == TempSourceVcgencmd:
+		return c.readVcgencmd()
+	case strings.HasPrefix(c.source, TempSourceHwmonPrefix):
+		path, err := findHwmonInput(c.hwmonRoot, strings.TrimPrefix(c.source, TempSourceHwmonPrefix))
+		if err != nil {
+			return 0, err
+		}
+		return readMilliCelsius(path)
+	default:
+		return readMilliCelsius(c.source)
+	}
+}
+
+// readMilliCelsius reads a sysfs file containing a temperature in millidegrees Celsius.
+func readMilliCelsius(path string) (float64, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from trusted config or sysfs enumeration
 	if err != nil {
-		return 0, fmt.Errorf("failed to read temperature from %s: %w", c.source, err)
+		return 0, fmt.Errorf("failed to read temperature from %s: %w", path, err)
 	}
 
 	// The temperature is typically in millidegrees Celsius
@@ -37,3 +77,59 @@ func (c *CPUTempCollector) GetTemperature() (float64, error) {
 	// Convert from millidegrees to degrees
 	return float64(tempMilli) / 1000.0, nil
 }
+
+// readVcgencmd runs `vcgencmd measure_temp` and parses output like "temp=48.3'C".
+func (c *CPUTempCollector) readVcgencmd() (float64, error) {
+	out, err := c.runCmd("vcgencmd", "measure_temp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to run vcgencmd: %w", err)
+	}
+	return parseVcgencmdTemp(string(out))
+}
+
+// parseVcgencmdTemp extracts the Celsius value from vcgencmd measure_temp output.
+func parseVcgencmdTemp(out string) (float64, error) {
+	s := strings.TrimSpace(out)
+	if !strings.HasPrefix(s, "temp=") {
+		return 0, fmt.Errorf("unexpected vcgencmd output: %q", s)
+	}
+	s = strings.TrimPrefix(s, "temp=")
+	s = strings.TrimSuffix(s, "'C")
+	temp, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse vcgencmd temperature %q: %w", out, err)
+	}
+	return temp, nil
+}
+
+// findHwmonInput locates the tempN_input file for a hwmon sensor. The label
+// matches either a chip's "name" file (first temperature input is used) or
+// the contents of a tempN_label file.
+func findHwmonInput(root, label string) (string, error) {
+	chips, err := filepath.Glob(filepath.Join(root, "hwmon*"))
+	if err != nil {
+		return "", fmt.Errorf("failed to list hwmon devices: %w", err)
+	}
+
+	for _, chip := range chips {
+		if name, err := os.ReadFile(filepath.Join(chip, "name")); err == nil { // #nosec G304 -- sysfs enumeration
+			if strings.TrimSpace(string(name)) == label {
+				input := filepath.Join(chip, "temp1_input")
+				if _, err := os.Stat(input); err == nil {
+					return input, nil
+				}
+			}
+		}
+
+		labels, _ := filepath.Glob(filepath.Join(chip, "temp*_label"))
+		for _, lf := range labels {
+			data, err := os.ReadFile(lf) // #nosec G304 -- sysfs enumeration
+			if err != nil || strings.TrimSpace(string(data)) != label {
+				continue
+			}
+			return strings.TrimSuffix(lf, "_label") + "_input", nil
+		}
+	}
+
+	return "", fmt.Errorf("hwmon sensor %q not found under %s", label, root)
+}
@@ -0,0 +1,106 @@
+package stats
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseVcgencmdTemp(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{"temp=48.3'C\n", 48.3, false},
+		{"temp=60.0'C", 60.0, false},
+		{"error", 0, true},
+		{"temp=abc'C", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseVcgencmdTemp(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseVcgencmdTemp(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseVcgencmdTemp(%q) = %f, want %f", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCPUTempCollectorVcgencmd(t *testing.T) {
+	c := NewCPUTempCollector(TempSourceVcgencmd)
+	c.runCmd = func(name string, args ...string) ([]byte, error) {
+		if name != "vcgencmd" || len(args) != 1 || args[0] != "measure_temp" {
+			t.Errorf("unexpected command %s %v", name, args)
+		}
+		return []byte("temp=51.5'C\n"), nil
+	}
+
+	temp, err := c.GetTemperature()
+	if err != nil {
+		t.Fatalf("GetTemperature() failed: %v", err)
+	}
+	if temp != 51.5 {
+		t.Errorf("expected 51.5, got %f", temp)
+	}
+
+	c.runCmd = func(string, ...string) ([]byte, error) { return nil, errors.New("not found") }
+	if _, err := c.GetTemperature(); err == nil {
+		t.Error("expected error when vcgencmd fails")
+	}
+}
+
+func TestCPUTempCollectorHwmon(t *testing.T) {
+	root := t.TempDir()
+	writeFile := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("hwmon0/name", "cpu_thermal\n")
+	writeFile("hwmon0/temp1_input", "42000\n")
+	writeFile("hwmon1/name", "nvme\n")
+	writeFile("hwmon1/temp1_input", "30000\n")
+	writeFile("hwmon1/temp2_label", "Composite\n")
+	writeFile("hwmon1/temp2_input", "38500\n")
+
+	tests := []struct {
+		source string
+		want   float64
+	}{
+		{"hwmon:cpu_thermal", 42.0},
+		{"hwmon:nvme", 30.0},
+		{"hwmon:Composite", 38.5},
+	}
+	for _, tt := range tests {
+		c := NewCPUTempCollector(tt.source)
+		c.hwmonRoot = root
+		got, err := c.GetTemperature()
+		if err != nil {
+			t.Errorf("%s: GetTemperature() failed: %v", tt.source, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %f, got %f", tt.source, tt.want, got)
+		}
+	}
+
+	c := NewCPUTempCollector("hwmon:missing")
+	c.hwmonRoot = root
+	if _, err := c.GetTemperature(); err == nil {
+		t.Error("expected error for unknown hwmon label")
+	}
+}
+
+func TestCPUTempCollectorEmptySource(t *testing.T) {
+	if _, err := NewCPUTempCollector("").GetTemperature(); err == nil {
+		t.Error("expected error for empty source")
+	}
+}
@@ -5,11 +5,24 @@ import (
 	"syscall"
 )
 
+// statfsReadOnly is the read-only mount flag in Statfs_t.Flags (ST_RDONLY on
+// Linux, MNT_RDONLY on the BSDs)
+const statfsReadOnly = 1
+
 // DiskCollector collects disk usage statistics
 type DiskCollector struct {
 	path string
 }
 
+// DiskUsage is the space and inode usage of a filesystem
+type DiskUsage struct {
+	Used        uint64 // in bytes
+	Total       uint64 // in bytes
+	InodesUsed  uint64
+	InodesTotal uint64 // 0 on filesystems without a fixed inode count
+	ReadOnly    bool   // mounted read-only, such as after the kernel remounts a failing SD card
+}
+
 // NewDiskCollector creates a new disk collector
 func NewDiskCollector(path string) *DiskCollector {
 	return &DiskCollector{
@@ -20,20 +33,36 @@ func NewDiskCollector(path string) *DiskCollector {
 // GetDisk reads disk usage statistics using statfs
 // Returns used and total disk space in bytes
 func (d *DiskCollector) GetDisk() (used, total uint64, err error) {
-	var stat syscall.Statfs_t
-	err = syscall.Statfs(d.path, &stat)
+	usage, err := d.GetUsage()
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to stat filesystem at %s: %w", d.path, err)
+		return 0, 0, err
 	}
+	return usage.Used, usage.Total, nil
+}
 
-	// Total size = blocks * block size
-	total = stat.Blocks * uint64(stat.Bsize) /* #nosec G115 -- block size is always positive */
+// GetUsage reads disk space and inode usage and the mount's read-only flag
+// using statfs
+func (d *DiskCollector) GetUsage() (DiskUsage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(d.path, &stat); err != nil {
+		return DiskUsage{}, fmt.Errorf("failed to stat filesystem at %s: %w", d.path, err)
+	}
 
-	// Available space = available blocks * block size
-	available := stat.Bavail * uint64(stat.Bsize) /* #nosec G115 -- block size is always positive */
+	// Total size = blocks * block size
+	total := stat.Blocks * uint64(stat.Bsize) /* #nosec G115 -- block size is always positive */
 
-	// Used space = total - available
-	used = total - available
+	// Available space = available blocks * block size. The BSDs count
+	// blocks reserved for root as negative availability.
+	available := uint64(max(stat.Bavail, 0)) * uint64(stat.Bsize) /* #nosec G115 -- both are non-negative */
 
-	return used, total, nil
+	usage := DiskUsage{
+		Used:        total - available,
+		Total:       total,
+		InodesTotal: stat.Files,
+		ReadOnly:    uint64(stat.Flags)&statfsReadOnly != 0, /* #nosec G115 -- flags are a bit mask */
+	}
+	if free := uint64(max(stat.Ffree, 0)); free <= stat.Files { /* #nosec G115 -- non-negative */
+		usage.InodesUsed = stat.Files - free
+	}
+	return usage, nil
 }
@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+const (
+	// defaultExecTimeout bounds a command run when pages.exec[].timeout is unset
+	defaultExecTimeout = 10 * time.Second
+	// maxExecOutput caps how much stdout is read from a command
+	maxExecOutput = 64 * 1024
+	// maxExecLines caps how many output lines are kept; no display shows more
+	maxExecLines = 32
+	// execWaitDelay bounds how long a command's output is still read once it
+	// has exited or timed out, as a child it left running may hold the pipe
+	// open indefinitely
+	execWaitDelay = time.Second
+)
+
+// ExecOutput is the most recent result of an exec page command
+type ExecOutput struct {
+	Lines []string  // non-empty stdout lines, trailing whitespace trimmed
+	Err   error     // set if the last run failed; Lines then holds the previous output
+	At    time.Time // when the last run finished
+}
+
+// execRunner runs one exec page command in the background on its interval,
+// so a slow command never holds up Collect
+type execRunner struct {
+	title   string
+	command []string
+	timeout time.Duration
+
+	runner[ExecOutput]
+}
+
+// newExecRunner creates a runner for an exec page. Durations are validated
+// at config load time.
+func newExecRunner(cfg config.ExecPageConfig) *execRunner {
+	interval, _ := time.ParseDuration(cfg.Interval)
+	timeout := defaultExecTimeout
+	if cfg.Timeout != "" {
+		timeout, _ = time.ParseDuration(cfg.Timeout)
+	}
+	r := &execRunner{
+		title:   cfg.Title,
+		command: cfg.Command,
+		timeout: timeout,
+	}
+	r.runner = runner[ExecOutput]{interval: interval, check: r.update}
+	return r
+}
+
+// update executes the command once, keeping the previous lines if it fails
+func (r *execRunner) update(out ExecOutput) ExecOutput {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	lines, err := runExecCommand(ctx, r.command)
+	out.At = time.Now()
+	out.Err = err
+	if err == nil {
+		out.Lines = lines
+	}
+	return out
+}
+
+// runExecCommand runs argv without a shell and returns its non-empty stdout lines
+func runExecCommand(ctx context.Context, argv []string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...) // #nosec G204 -- command comes from the daemon config
+	var stdout bytes.Buffer
+	cmd.Stdout = &limitedWriter{w: &stdout, n: maxExecOutput}
+	cmd.WaitDelay = execWaitDelay
+	// A command that succeeded but left a child holding stdout still counts
+	if err := cmd.Run(); err != nil && (!errors.Is(err, exec.ErrWaitDelay) || ctx.Err() != nil) {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%s timed out", argv[0])
+		}
+		return nil, fmt.Errorf("%s: %w", argv[0], err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() && len(lines) < maxExecLines {
+		if line := strings.TrimRight(scanner.Text(), " \t\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// limitedWriter keeps the first n bytes written and silently discards the
+// rest, so a chatty command cannot exhaust memory or fail on a closed pipe
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.n > 0 {
+		keep := p[:min(len(p), l.n)]
+		if _, err := l.w.Write(keep); err != nil {
+			return 0, err
+		}
+		l.n -= len(keep)
+	}
+	return len(p), nil
+}
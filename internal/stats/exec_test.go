@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+func TestExecRunnerCapturesLines(t *testing.T) {
+	r := newExecRunner(config.ExecPageConfig{
+		Title:    "Test",
+		Command:  []string{"printf", "one  \n\ntwo\n"},
+		Interval: "1h",
+	})
+
+	out := waitForRun(t, &r.runner, time.Now())
+	if out.Err != nil {
+		t.Fatalf("unexpected error: %v", out.Err)
+	}
+	if strings.Join(out.Lines, "|") != "one|two" {
+		t.Errorf("expected lines [one two], got %q", out.Lines)
+	}
+}
+
+func TestExecRunnerKeepsOutputOnFailure(t *testing.T) {
+	r := newExecRunner(config.ExecPageConfig{
+		Title:    "Test",
+		Command:  []string{"echo", "ok"},
+		Interval: "1m",
+	})
+	start := time.Now()
+	if out := waitForRun(t, &r.runner, start); len(out.Lines) != 1 {
+		t.Fatalf("expected one line, got %q", out.Lines)
+	}
+
+	// Not due yet: no new run, same output
+	r.command = []string{"false"}
+	if out, _ := r.poll(start.Add(30 * time.Second)); out.Err != nil {
+		t.Fatal("command re-ran before its interval elapsed")
+	}
+
+	out := waitForRun(t, &r.runner, start.Add(time.Minute))
+	if out.Err == nil {
+		t.Fatal("failing command never reported an error")
+	}
+	if len(out.Lines) != 1 || out.Lines[0] != "ok" {
+		t.Errorf("expected previous output kept after failure, got %q", out.Lines)
+	}
+}
+
+func TestExecRunnerTimeout(t *testing.T) {
+	r := newExecRunner(config.ExecPageConfig{
+		Title:    "Test",
+		Command:  []string{"sleep", "5"},
+		Interval: "1h",
+		Timeout:  "50ms",
+	})
+
+	out := waitForRun(t, &r.runner, time.Now())
+	if out.Err == nil || !strings.Contains(out.Err.Error(), "timed out") {
+		t.Errorf("expected a timeout error, got %v", out.Err)
+	}
+}
+
+func TestExecRunnerChildHoldsOutput(t *testing.T) {
+	// The background sleep keeps stdout open after the shell exits
+	r := newExecRunner(config.ExecPageConfig{
+		Title:    "Test",
+		Command:  []string{"sh", "-c", "echo ok; sleep 5 &"},
+		Interval: "1h",
+		Timeout:  "10s",
+	})
+
+	start := time.Now()
+	out := waitForRun(t, &r.runner, start)
+	if out.Err != nil || len(out.Lines) != 1 || out.Lines[0] != "ok" {
+		t.Errorf("expected the output of the exited command, got %q, %v", out.Lines, out.Err)
+	}
+	if d := time.Since(start); d > 3*time.Second {
+		t.Errorf("expected the run to end soon after the command exited, took %s", d)
+	}
+}
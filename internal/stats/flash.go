@@ -0,0 +1,104 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultBlockPath = "/sys/block"
+
+// mmcDevice matches whole MMC block devices, not their boot and RPMB areas
+var mmcDevice = regexp.MustCompile(`^mmcblk[0-9]+$`)
+
+// Pre-EOL states an eMMC reports for its reserved blocks
+const (
+	PreEOLUnknown = 0
+	PreEOLNormal  = 1
+	PreEOLWarning = 2 // 80% of reserved blocks consumed
+	PreEOLUrgent  = 3 // 90% of reserved blocks consumed
+)
+
+// FlashInfo describes an SD card or eMMC device and its wear, where known
+type FlashInfo struct {
+	Device string // block device, e.g. "mmcblk0"
+	Type   string // "MMC" for eMMC or "SD"
+	Name   string // product name
+	Date   string // manufacturing date, "MM/YYYY"
+
+	// LifeUsed is the eMMC's estimate of its rated life used, as the upper
+	// bound of a 10% step: 10 means 0-10%, 100 means 90-100% and 110 that
+	// the rated life is exceeded. 0 when the device reports none, as SD
+	// cards never do.
+	LifeUsed int
+	PreEOL   int // PreEOL* state of the reserved blocks
+}
+
+// FlashCollector reads the wear estimates and identity of MMC devices
+// from sysfs
+type FlashCollector struct {
+	path string
+}
+
+// NewFlashCollector creates a new flash storage collector
+func NewFlashCollector() *FlashCollector {
+	return NewFlashCollectorWithPath(defaultBlockPath)
+}
+
+// NewFlashCollectorWithPath creates a collector reading from a custom
+// /sys/block (for testing)
+func NewFlashCollectorWithPath(path string) *FlashCollector {
+	return &FlashCollector{path: path}
+}
+
+// GetFlash returns the SD cards and eMMC devices, in device order, or an
+// empty list when there are none. Devices that are neither, such as SDIO
+// Wi-Fi, are skipped.
+func (c *FlashCollector) GetFlash() ([]FlashInfo, error) {
+	entries, err := os.ReadDir(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", c.path, err)
+	}
+
+	devices := []FlashInfo{}
+	for _, e := range entries {
+		if !mmcDevice.MatchString(e.Name()) {
+			continue
+		}
+		dir := filepath.Join(c.path, e.Name(), "device")
+		info := FlashInfo{
+			Device: e.Name(),
+			Type:   readSysfs(dir, "type"),
+			Name:   readSysfs(dir, "name"),
+			Date:   readSysfs(dir, "date"),
+		}
+		if info.Type != "MMC" && info.Type != "SD" {
+			continue
+		}
+		// Type A and B estimates cover different kinds of memory; report the
+		// more worn
+		for _, field := range strings.Fields(readSysfs(dir, "life_time")) {
+			if v, err := strconv.ParseUint(field, 0, 8); err == nil && v >= 1 && v <= 11 {
+				info.LifeUsed = max(info.LifeUsed, int(v)*10)
+			}
+		}
+		if v, err := strconv.ParseUint(readSysfs(dir, "pre_eol_info"), 0, 8); err == nil && v <= PreEOLUrgent {
+			info.PreEOL = int(v)
+		}
+		devices = append(devices, info)
+	}
+	return devices, nil
+}
+
+// readSysfs returns the trimmed contents of a sysfs attribute, or "" when
+// the device lacks it
+func readSysfs(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name)) // #nosec G304 -- sysfs enumeration
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,65 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFlashCollector(t *testing.T) {
+	devices, err := NewFlashCollectorWithPath("../../testdata/sys/block").GetFlash()
+	if err != nil {
+		t.Fatalf("GetFlash() failed: %v", err)
+	}
+
+	// Boot areas, SDIO and non-MMC devices are skipped
+	want := []FlashInfo{
+		{Device: "mmcblk0", Type: "MMC", Name: "8GTF4R", Date: "06/2021", LifeUsed: 80, PreEOL: PreEOLNormal},
+		{Device: "mmcblk1", Type: "SD", Name: "SC32G", Date: "01/2019"},
+	}
+	if len(devices) != len(want) {
+		t.Fatalf("expected %d devices, got %+v", len(want), devices)
+	}
+	for i := range want {
+		if devices[i] != want[i] {
+			t.Errorf("device %d: expected %+v, got %+v", i, want[i], devices[i])
+		}
+	}
+}
+
+func TestFlashCollectorLifeTime(t *testing.T) {
+	tests := []struct {
+		lifeTime, preEOL  string
+		wantLife, wantEOL int
+	}{
+		{"0x01 0x01", "0x01", 10, PreEOLNormal},
+		{"0x0B 0x03", "0x03", 110, PreEOLUrgent},
+		{"0x00 0x00", "0x00", 0, PreEOLUnknown}, // not defined
+		{"garbage", "0x07", 0, PreEOLUnknown},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		dir := filepath.Join(root, "mmcblk0", "device")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range map[string]string{"type": "MMC\n", "life_time": tt.lifeTime + "\n", "pre_eol_info": tt.preEOL + "\n"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		devices, err := NewFlashCollectorWithPath(root).GetFlash()
+		if err != nil || len(devices) != 1 {
+			t.Fatalf("GetFlash() = %+v, %v", devices, err)
+		}
+		if devices[0].LifeUsed != tt.wantLife || devices[0].PreEOL != tt.wantEOL {
+			t.Errorf("%q/%q: expected life %d, pre-EOL %d; got %d, %d",
+				tt.lifeTime, tt.preEOL, tt.wantLife, tt.wantEOL, devices[0].LifeUsed, devices[0].PreEOL)
+		}
+	}
+
+	if _, err := NewFlashCollectorWithPath("/nonexistent/block").GetFlash(); err == nil {
+		t.Error("expected error for nonexistent path")
+	}
+}
@@ -0,0 +1,185 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/jsonpath"
+)
+
+const (
+	// defaultHTTPJSONTimeout bounds a request when pages.http_json[].timeout is unset
+	defaultHTTPJSONTimeout = 10 * time.Second
+	// maxHTTPJSONResponse caps how much of a response is decoded
+	maxHTTPJSONResponse = 1 << 20
+	// httpJSONMissing stands in for a value missing from the response
+	httpJSONMissing = "-"
+)
+
+// httpJSONField is a configured field with its path parsed
+type httpJSONField struct {
+	path  jsonpath.Path
+	label string
+}
+
+// httpJSONRunner fetches one http_json page's URL in the background on its
+// interval and renders its fields, in the same form as exec page output
+type httpJSONRunner struct {
+	title   string
+	url     string
+	headers map[string]string
+	fields  []httpJSONField
+	timeout time.Duration
+	client  *http.Client
+
+	runner[ExecOutput]
+}
+
+// newHTTPJSONRunner creates a runner for an http_json page. Durations and
+// paths are validated at config load time.
+func newHTTPJSONRunner(cfg config.HTTPJSONPageConfig) *httpJSONRunner {
+	interval, _ := time.ParseDuration(cfg.Interval)
+	timeout := defaultHTTPJSONTimeout
+	if cfg.Timeout != "" {
+		timeout, _ = time.ParseDuration(cfg.Timeout)
+	}
+	fields := make([]httpJSONField, 0, len(cfg.Fields))
+	for _, f := range cfg.Fields {
+		path, _ := jsonpath.Parse(f.Path)
+		fields = append(fields, httpJSONField{path: path, label: f.Label})
+	}
+	r := &httpJSONRunner{
+		title:   cfg.Title,
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		fields:  fields,
+		timeout: timeout,
+		client:  &http.Client{},
+	}
+	r.runner = runner[ExecOutput]{interval: interval, check: r.update}
+	return r
+}
+
+// update fetches the URL once and renders the fields, keeping the previous
+// lines if the fetch fails
+func (r *httpJSONRunner) update(out ExecOutput) ExecOutput {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	lines, err := r.fetch(ctx)
+	out.At = time.Now()
+	out.Err = err
+	if err == nil {
+		out.Lines = lines
+	}
+	return out
+}
+
+// fetch requests the URL and renders a line per field
+func (r *httpJSONRunner) fetch(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range r.headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		// The URL is long and already known; keep the reason
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errors.New("request timed out")
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %s", resp.Status)
+	}
+
+	var doc any
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxHTTPJSONResponse)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	lines := make([]string, 0, len(r.fields))
+	for _, f := range r.fields {
+		value, ok := f.path.Lookup(doc)
+		lines = append(lines, renderHTTPJSONLabel(f.label, value, ok))
+	}
+	return lines, nil
+}
+
+// renderHTTPJSONLabel replaces the label's "{value}" placeholders with the
+// value, formatted by the printf verb in "{value:%.1f}" when given. Missing
+// values and nulls show as "-".
+func renderHTTPJSONLabel(label string, value any, found bool) string {
+	if label == "" {
+		label = "{value}"
+	}
+	var b strings.Builder
+	for {
+		before, after, ok := strings.Cut(label, "{value")
+		if !ok {
+			b.WriteString(label)
+			return b.String()
+		}
+		spec, rest, closed := strings.Cut(after, "}")
+		if !closed || (spec != "" && !strings.HasPrefix(spec, ":")) {
+			// Not a placeholder; keep the text as it is
+			b.WriteString(before + "{value")
+			label = after
+			continue
+		}
+		b.WriteString(before)
+		b.WriteString(formatHTTPJSONValue(value, found, strings.TrimPrefix(spec, ":")))
+		label = rest
+	}
+}
+
+// formatHTTPJSONValue formats a decoded JSON value, with verb when set.
+// Numbers given as strings are formatted as numbers.
+func formatHTTPJSONValue(value any, found bool, verb string) string {
+	if !found || value == nil {
+		return httpJSONMissing
+	}
+	if verb != "" {
+		switch v := value.(type) {
+		case float64:
+			return fmt.Sprintf(verb, v)
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return fmt.Sprintf(verb, f)
+			}
+			return fmt.Sprintf(verb, v)
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		// Objects and arrays show as compact JSON
+		data, err := json.Marshal(v)
+		if err != nil {
+			return httpJSONMissing
+		}
+		return string(data)
+	}
+}
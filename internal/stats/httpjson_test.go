@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+const piholeSummary = `{
+	"dns_queries_today": 24512,
+	"ads_blocked_today": 3071,
+	"ads_percentage_today": "12.528557",
+	"status": "enabled",
+	"gravity_last_updated": {"relative": {"days": 2}},
+	"reply_NXDOMAIN": null
+}`
+
+func TestHTTPJSONRunner(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			t.Errorf("expected the configured header, got %v", r.Header)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(piholeSummary))
+	}))
+	defer srv.Close()
+
+	r := newHTTPJSONRunner(config.HTTPJSONPageConfig{
+		Title:    "Pi-hole",
+		URL:      srv.URL,
+		Headers:  map[string]string{"X-Api-Key": "secret"},
+		Interval: "1m",
+		Fields: []config.HTTPJSONField{
+			{Path: "$.ads_blocked_today", Label: "Blocked {value}"},
+			{Path: "ads_percentage_today", Label: "Blocked {value:%.1f}%"},
+			{Path: "status"},
+			{Path: "gravity_last_updated.relative.days", Label: "Gravity {value}d ago"},
+			{Path: "reply_NXDOMAIN", Label: "NX {value}"},
+			{Path: "clients_ever_seen", Label: "Clients {value}"},
+		},
+	})
+
+	out := waitForRun(t, &r.runner, time.Now())
+	if out.Err != nil {
+		t.Fatalf("unexpected error: %v", out.Err)
+	}
+	want := []string{"Blocked 3071", "Blocked 12.5%", "enabled", "Gravity 2d ago", "NX -", "Clients -"}
+	if !slices.Equal(out.Lines, want) {
+		t.Errorf("lines = %q, want %q", out.Lines, want)
+	}
+
+	// A failed fetch keeps the previous values
+	status = http.StatusServiceUnavailable
+	r.started = time.Time{}
+	out = waitForRun(t, &r.runner, time.Now())
+	if out.Err == nil || out.Err.Error() != "HTTP 503 Service Unavailable" || !slices.Equal(out.Lines, want) {
+		t.Errorf("expected the error with the previous lines, got %q, %v", out.Lines, out.Err)
+	}
+}
+
+func TestRenderHTTPJSONLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		value any
+		want  string
+	}{
+		{"", 42.0, "42"},
+		{"{value} / {value:%03.0f}", 7.0, "7 / 007"},
+		{"Temp {value:%.1f}C", "41.26", "Temp 41.3C"},
+		{"Printing: {value}", true, "Printing: true"},
+		{"Job {value}", map[string]any{"file": "cube.gcode"}, `Job {"file":"cube.gcode"}`},
+		{"{values} {value}", "x", "{values} x"},
+	}
+	for _, tt := range tests {
+		if got := renderHTTPJSONLabel(tt.label, tt.value, true); got != tt.want {
+			t.Errorf("renderHTTPJSONLabel(%q, %v) = %q, want %q", tt.label, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package stats
+
+import (
+	"net/netip"
+	"slices"
+)
+
+// IPv6 address scopes, as labelled on the network pages
+const (
+	IPv6ScopeGlobal      = "GUA" // global unicast
+	IPv6ScopeUniqueLocal = "ULA" // unique local, fc00::/7
+	IPv6ScopeLinkLocal   = "LL"  // link-local, fe80::/10
+)
+
+// IPv6Scope returns the scope of an IPv6 address, which may carry a zone:
+// one of the IPv6Scope constants, or "" for other addresses (loopback,
+// multicast) and strings that are not IPv6 addresses
+func IPv6Scope(addr string) string {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil || !ip.Is6() || ip.Is4In6() {
+		return ""
+	}
+	switch {
+	case ip.IsLinkLocalUnicast():
+		return IPv6ScopeLinkLocal
+	case ip.IsPrivate():
+		return IPv6ScopeUniqueLocal
+	case ip.IsGlobalUnicast():
+		return IPv6ScopeGlobal
+	default:
+		return ""
+	}
+}
+
+// ipv6ScopeRank orders scopes for PreferGUA: global first, then unique
+// local, then link-local
+var ipv6ScopeRank = map[string]int{
+	IPv6ScopeGlobal:      0,
+	IPv6ScopeUniqueLocal: 1,
+	IPv6ScopeLinkLocal:   2,
+	"":                   3,
+}
+
+// preferGlobal orders addrs global first, keeping the kernel's order
+// within each scope
+func preferGlobal(addrs []string) {
+	slices.SortStableFunc(addrs, func(a, b string) int {
+		return ipv6ScopeRank[IPv6Scope(a)] - ipv6ScopeRank[IPv6Scope(b)]
+	})
+}
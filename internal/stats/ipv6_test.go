@@ -0,0 +1,31 @@
+package stats
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIPv6Scope(t *testing.T) {
+	tests := map[string]string{
+		"2001:db8:85a3::8a2e:370:7334": IPv6ScopeGlobal,
+		"fd12:3456:789a:1::2":          IPv6ScopeUniqueLocal,
+		"fe80::1%eth0":                 IPv6ScopeLinkLocal,
+		"::1":                          "",
+		"192.168.1.2":                  "",
+		"no addr":                      "",
+	}
+	for addr, want := range tests {
+		if got := IPv6Scope(addr); got != want {
+			t.Errorf("IPv6Scope(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestPreferGlobal(t *testing.T) {
+	addrs := []string{"fe80::1%eth0", "fd00::2", "2001:db8::3", "fd00::4", "2001:db8::5"}
+	preferGlobal(addrs)
+	want := []string{"2001:db8::3", "2001:db8::5", "fd00::2", "fd00::4", "fe80::1%eth0"}
+	if !slices.Equal(addrs, want) {
+		t.Errorf("preferGlobal = %q, want %q", addrs, want)
+	}
+}
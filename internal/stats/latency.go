@@ -0,0 +1,83 @@
+package stats
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+// latencyHistorySize is how many round trips are kept per host for graphs
+const latencyHistorySize = 60
+
+// LatencyReading is the latest ping result for one latency monitor host
+type LatencyReading struct {
+	Name    string
+	RTT     time.Duration   // round trip time of the last ping; 0 when it failed
+	Err     error           // why the last ping failed
+	History []time.Duration // recent round trip times, oldest first; 0 for lost pings
+}
+
+// latencyRunner pings the latency monitor hosts in the background on its
+// interval, so unreachable hosts never hold up Collect
+type latencyRunner struct {
+	targets []config.LatencyTarget
+	timeout time.Duration
+	ping    func(ctx context.Context, host string) (time.Duration, error)
+
+	runner[[]LatencyReading]
+}
+
+// newLatencyRunner creates a runner for the latency config. Durations are
+// validated at config load time.
+func newLatencyRunner(cfg config.LatencyConfig) *latencyRunner {
+	interval, _ := time.ParseDuration(cfg.Interval)
+	timeout, _ := time.ParseDuration(cfg.Timeout)
+	r := &latencyRunner{
+		targets: cfg.Hosts,
+		timeout: timeout,
+		ping:    ping,
+	}
+	r.runner = runner[[]LatencyReading]{interval: interval, check: r.update}
+	return r
+}
+
+// update pings every host once, in parallel, and appends the results to the
+// previous readings' history. The readings are new slices, as the previous
+// ones may still be in use.
+func (r *latencyRunner) update(prev []LatencyReading) []LatencyReading {
+	rtts := make([]time.Duration, len(r.targets))
+	errs := make([]error, len(r.targets))
+	var wg sync.WaitGroup
+	for i, target := range r.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+			defer cancel()
+			rtts[i], errs[i] = r.ping(ctx, target.Host)
+		}()
+	}
+	wg.Wait()
+
+	readings := make([]LatencyReading, len(r.targets))
+	for i, target := range r.targets {
+		var history []time.Duration
+		if i < len(prev) {
+			history = prev[i].History
+		}
+		if errs[i] != nil {
+			rtts[i] = 0
+		}
+		// A new slice, so readings already handed out stay unchanged
+		history = append(history[max(len(history)-latencyHistorySize+1, 0):len(history):len(history)], rtts[i])
+
+		name := target.Name
+		if name == "" {
+			name = target.Host
+		}
+		readings[i] = LatencyReading{Name: name, RTT: rtts[i], Err: errs[i], History: history}
+	}
+	return readings
+}
@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+func TestLatencyRunner(t *testing.T) {
+	cfg := config.Default().Latency
+	cfg.Enabled = true
+	cfg.Hosts = []config.LatencyTarget{{Name: "ISP", Host: "gw"}, {Host: "down"}}
+	r := newLatencyRunner(cfg)
+	r.ping = func(_ context.Context, host string) (time.Duration, error) {
+		if host == "down" {
+			return 0, errors.New("no reply from down")
+		}
+		return 12 * time.Millisecond, nil
+	}
+
+	start := time.Now()
+	first := waitForRun(t, &r.runner, start)
+	if first[0].Name != "ISP" || first[0].RTT != 12*time.Millisecond || first[0].Err != nil {
+		t.Errorf("unexpected reading %+v", first[0])
+	}
+	// Unnamed hosts are labelled with the host
+	if first[1].Name != "down" || first[1].Err == nil || first[1].RTT != 0 {
+		t.Errorf("unexpected reading %+v", first[1])
+	}
+
+	// Not due yet: no new round
+	if again, _ := r.poll(start.Add(time.Second)); len(again[0].History) != 1 {
+		t.Fatal("pinged again before the interval elapsed")
+	}
+
+	second := waitForRun(t, &r.runner, start.Add(10*time.Second))
+	if len(second[0].History) != 2 || len(second[1].History) != 2 || second[1].History[1] != 0 {
+		t.Errorf("expected two samples with the lost ping as 0, got %v and %v", second[0].History, second[1].History)
+	}
+	// Readings already handed out are not modified
+	if len(first[0].History) != 1 {
+		t.Errorf("earlier readings changed: %v", first[0].History)
+	}
+}
+
+func TestLatencyRunnerHistoryLimit(t *testing.T) {
+	cfg := config.Default().Latency
+	cfg.Hosts = []config.LatencyTarget{{Host: "gw"}}
+	r := newLatencyRunner(cfg)
+	r.ping = func(context.Context, string) (time.Duration, error) { return time.Millisecond, nil }
+
+	var readings []LatencyReading
+	for range latencyHistorySize + 5 {
+		readings = r.update(readings)
+	}
+	if n := len(readings[0].History); n != latencyHistorySize {
+		t.Errorf("expected %d samples, got %d", latencyHistorySize, n)
+	}
+}
+
+func TestPingLoopback(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	rtt, err := ping(ctx, "127.0.0.1")
+	if err != nil {
+		// Needs ping sockets to be allowed, or root
+		t.Skipf("ping unavailable: %v", err)
+	}
+	if rtt <= 0 {
+		t.Errorf("expected a positive round trip time, got %v", rtt)
+	}
+}
@@ -7,8 +7,6 @@ import (
 	"strings"
 )
 
-const defaultLoadAvgPath = "/proc/loadavg"
-
 // LoadAvgCollector collects system load averages
 type LoadAvgCollector struct {
 	path string
@@ -24,8 +22,12 @@ func NewLoadAvgCollectorWithPath(path string) *LoadAvgCollector {
 	return &LoadAvgCollector{path: path}
 }
 
-// GetLoadAvg reads /proc/loadavg and returns the 1m, 5m, and 15m load averages
+// GetLoadAvg reads /proc/loadavg and returns the 1m, 5m, and 15m load
+// averages. Systems without /proc are asked through sysctl.
 func (c *LoadAvgCollector) GetLoadAvg() (avg1, avg5, avg15 float64, err error) {
+	if c.path == "" {
+		return platformLoadAvg()
+	}
 	data, err := os.ReadFile(c.path)
 	if err != nil {
 		return 0, 0, 0, fmt.Errorf("failed to read load average from %s: %w", c.path, err)
@@ -16,7 +16,7 @@ type MemoryCollector struct {
 // NewMemoryCollector creates a new memory collector
 func NewMemoryCollector() *MemoryCollector {
 	return &MemoryCollector{
-		meminfoPath: "/proc/meminfo",
+		meminfoPath: defaultMeminfoPath,
 	}
 }
 
@@ -27,9 +27,13 @@ func NewMemoryCollectorWithPath(path string) *MemoryCollector {
 	}
 }
 
-// GetMemory reads memory statistics from /proc/meminfo
+// GetMemory reads memory statistics from /proc/meminfo, or through sysctl
+// on systems without /proc
 // Returns used and total memory in bytes
 func (m *MemoryCollector) GetMemory() (used, total uint64, err error) {
+	if m.meminfoPath == "" {
+		return platformMemory()
+	}
 	var file *os.File
 	file, err = os.Open(m.meminfoPath)
 	if err != nil {
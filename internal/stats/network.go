@@ -1,22 +1,40 @@
 package stats
 
 import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"net"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/ausil/i2c-display/internal/config"
 )
 
+const defaultRoutePath = "/proc/net/route"
+
+// defaultResolvConfPaths are tried in order for the name servers. With
+// systemd-resolved, /etc/resolv.conf only names the local stub resolver; the
+// upstream servers are listed in its own copy.
+var defaultResolvConfPaths = []string{"/run/systemd/resolve/resolv.conf", "/etc/resolv.conf"}
+
 // NetworkCollector collects network interface information
 type NetworkCollector struct {
-	config config.NetworkConfig
+	config      config.NetworkConfig
+	routePath   string
+	resolvPaths []string
+	sources     func() (map[string]string, error) // how each IPv4 address was assigned
 }
 
 // NewNetworkCollector creates a new network collector
 func NewNetworkCollector(cfg config.NetworkConfig) *NetworkCollector {
 	return &NetworkCollector{
-		config: cfg,
+		config:      cfg,
+		routePath:   defaultRoutePath,
+		resolvPaths: defaultResolvConfPaths,
+		sources:     addressSources,
 	}
 }
 
@@ -29,6 +47,11 @@ func (n *NetworkCollector) GetInterfaces() ([]NetInterface, error) {
 
 	var result []NetInterface
 
+	// Gateways and address sources are extras; interfaces are still listed
+	// when they cannot be read
+	gateways, _ := n.gateways()
+	sources, _ := n.sources()
+
 	for _, iface := range ifaces {
 		// Skip down interfaces
 		if iface.Flags&net.FlagUp == 0 {
@@ -46,7 +69,8 @@ func (n *NetworkCollector) GetInterfaces() ([]NetInterface, error) {
 		}
 
 		netIface := NetInterface{
-			Name: iface.Name,
+			Name:    iface.Name,
+			Gateway: gateways[iface.Name],
 		}
 
 		for _, addr := range addrs {
@@ -67,14 +91,27 @@ func (n *NetworkCollector) GetInterfaces() ([]NetInterface, error) {
 				}
 			} else {
 				if n.config.ShowIPv6 {
-					// Filter out link-local IPv6 addresses
-					if !ip.IsLinkLocalUnicast() {
+					// Link-local addresses are skipped unless asked for
+					switch {
+					case !ip.IsLinkLocalUnicast():
+						netIface.IPv6Addrs = append(netIface.IPv6Addrs, ip.String())
+					case n.config.IPv6LinkLocal && n.config.IPv6Zones:
+						netIface.IPv6Addrs = append(netIface.IPv6Addrs, ip.String()+"%"+iface.Name)
+					case n.config.IPv6LinkLocal:
 						netIface.IPv6Addrs = append(netIface.IPv6Addrs, ip.String())
 					}
 				}
 			}
 		}
 
+		if n.config.PreferGUA {
+			preferGlobal(netIface.IPv6Addrs)
+		}
+
+		if len(netIface.IPv4Addrs) > 0 {
+			netIface.Addressing = sources[netIface.IPv4Addrs[0]]
+		}
+
 		// Only add interface if it has addresses we care about
 		if len(netIface.IPv4Addrs) > 0 || len(netIface.IPv6Addrs) > 0 {
 			result = append(result, netIface)
@@ -84,6 +121,67 @@ func (n *NetworkCollector) GetInterfaces() ([]NetInterface, error) {
 	return result, nil
 }
 
+// gateways reads the IPv4 default gateway of each interface from the kernel
+// routing table, keyed by interface name. Where an interface has several
+// default routes the first is used.
+func (n *NetworkCollector) gateways() (map[string]string, error) {
+	f, err := os.Open(n.routePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routes from %s: %w", n.routePath, err)
+	}
+	defer f.Close()
+
+	gateways := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+		if _, ok := gateways[fields[0]]; ok {
+			continue
+		}
+		if gw := parseRouteAddr(fields[2]); gw != nil && !gw.IsUnspecified() {
+			gateways[fields[0]] = gw.String()
+		}
+	}
+	return gateways, scanner.Err()
+}
+
+// parseRouteAddr decodes an address from /proc/net/route, which the kernel
+// prints as hex in host byte order
+func parseRouteAddr(s string) net.IP {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 4 {
+		return nil
+	}
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, binary.NativeEndian.Uint32(b))
+	return ip
+}
+
+// GetDNSServers returns the name servers from the first resolv.conf that can
+// be read, in order. It returns nil when there is none.
+func (n *NetworkCollector) GetDNSServers() []string {
+	for _, path := range n.resolvPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var servers []string
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && fields[0] == "nameserver" {
+				servers = append(servers, fields[1])
+			}
+		}
+		return servers
+	}
+	return nil
+}
+
 // shouldInclude checks if an interface should be included based on filters
 func (n *NetworkCollector) shouldInclude(name string) bool {
 	// First check exclude patterns
@@ -0,0 +1,145 @@
+package stats
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ICMP echo message types
+const (
+	icmpv4EchoRequest = 8
+	icmpv4EchoReply   = 0
+	icmpv6EchoRequest = 128
+	icmpv6EchoReply   = 129
+)
+
+// pingSeq numbers echo requests, so late replies to earlier pings are ignored
+var pingSeq atomic.Uint32
+
+// ping sends one ICMP echo request to host and returns the round trip time.
+// It uses an unprivileged ping socket where net.ipv4.ping_group_range
+// allows one, and a raw socket otherwise, which needs root or CAP_NET_RAW.
+func ping(ctx context.Context, host string) (time.Duration, error) {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return 0, err
+	}
+	ip := addrs[0].IP
+	for _, a := range addrs {
+		if a.IP.To4() != nil {
+			ip = a.IP // prefer IPv4, like ping
+			break
+		}
+	}
+	v4 := ip.To4() != nil
+
+	conn, raw, err := listenICMP(v4)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return 0, err
+		}
+	}
+
+	id := uint16(os.Getpid())
+	seq := uint16(pingSeq.Add(1))
+	msg := make([]byte, 16) // header and 8 bytes of payload
+	msg[0] = icmpv6EchoRequest
+	if v4 {
+		msg[0] = icmpv4EchoRequest
+	}
+	binary.BigEndian.PutUint16(msg[4:], id)
+	binary.BigEndian.PutUint16(msg[6:], seq)
+	if v4 {
+		// The kernel fills in the ICMPv6 checksum
+		binary.BigEndian.PutUint16(msg[2:], icmpChecksum(msg))
+	}
+
+	var dst net.Addr = &net.UDPAddr{IP: ip}
+	if raw {
+		dst = &net.IPAddr{IP: ip}
+	}
+	start := time.Now()
+	if _, err := conn.WriteTo(msg, dst); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return 0, fmt.Errorf("no reply from %s", host)
+			}
+			return 0, err
+		}
+		reply := buf[:n]
+		if raw && v4 && len(reply) > 0 {
+			// Raw IPv4 sockets deliver the IP header too
+			reply = reply[min(int(reply[0]&0x0f)*4, len(reply)):]
+		}
+		if len(reply) < 8 || binary.BigEndian.Uint16(reply[6:]) != seq {
+			continue
+		}
+		// Ping sockets only deliver replies to their own requests; raw
+		// sockets see every ICMP message
+		if raw && binary.BigEndian.Uint16(reply[4:]) != id {
+			continue
+		}
+		if (v4 && reply[0] == icmpv4EchoReply) || (!v4 && reply[0] == icmpv6EchoReply) {
+			return time.Since(start), nil
+		}
+	}
+}
+
+// listenICMP opens an ICMP socket for the address family, reporting whether
+// it is a raw socket
+func listenICMP(v4 bool) (net.PacketConn, bool, error) {
+	family, proto := syscall.AF_INET6, syscall.IPPROTO_ICMPV6
+	if v4 {
+		family, proto = syscall.AF_INET, syscall.IPPROTO_ICMP
+	}
+	raw := false
+	fd, err := syscall.Socket(family, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, proto)
+	if errors.Is(err, syscall.EACCES) || errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EPROTONOSUPPORT) {
+		raw = true
+		fd, err = syscall.Socket(family, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, proto)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("open ICMP socket: %w", err)
+	}
+
+	f := os.NewFile(uintptr(fd), "icmp")
+	defer f.Close() // FilePacketConn holds its own copy of the descriptor
+	conn, err := net.FilePacketConn(f)
+	if err != nil {
+		return nil, false, fmt.Errorf("open ICMP socket: %w", err)
+	}
+	return conn, raw, nil
+}
+
+// icmpChecksum returns the Internet checksum of msg, with its checksum
+// field zero
+func icmpChecksum(msg []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(msg); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(msg[i:]))
+	}
+	if len(msg)%2 == 1 {
+		sum += uint32(msg[len(msg)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package stats
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ping reports that ICMP echo is only implemented on Linux
+func ping(_ context.Context, _ string) (time.Duration, error) {
+	return 0, errors.New("ping is only available on Linux")
+}
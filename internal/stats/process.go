@@ -0,0 +1,175 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const defaultProcPath = "/proc"
+
+// ProcessInfo is a process's resource use, as listed on the top pages
+type ProcessInfo struct {
+	PID  int
+	Name string  // command name, as in /proc/[pid]/comm
+	CPU  float64 // percent of one CPU since the previous scan, like top
+	RSS  uint64  // resident memory in bytes
+}
+
+// ProcessCollector finds the processes using the most CPU and memory by
+// scanning /proc. CPU use is measured between consecutive scans, so the
+// first scan reports none.
+type ProcessCollector struct {
+	path     string
+	pageSize uint64
+
+	// CPU time of each process and of the whole system at the last scan,
+	// in clock ticks
+	lastTicks map[int]uint64
+	lastTotal uint64
+}
+
+// NewProcessCollector creates a new process collector
+func NewProcessCollector() *ProcessCollector {
+	return NewProcessCollectorWithPath(defaultProcPath)
+}
+
+// NewProcessCollectorWithPath creates a collector reading from a custom proc root (for testing)
+func NewProcessCollectorWithPath(path string) *ProcessCollector {
+	return &ProcessCollector{path: path, pageSize: uint64(os.Getpagesize())}
+}
+
+// GetTopProcesses scans the processes and returns the n using the most CPU
+// and the n using the most memory, busiest first. Processes that exit
+// during the scan are skipped.
+func (c *ProcessCollector) GetTopProcesses(n int) (byCPU, byMemory []ProcessInfo, err error) {
+	total, numCPU, err := c.cpuTotal()
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := os.ReadDir(c.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", c.path, err)
+	}
+
+	ticks := make(map[int]uint64, len(c.lastTicks))
+	var procs []ProcessInfo
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || !e.IsDir() {
+			continue
+		}
+		info, used, err := c.readProcess(pid)
+		if err != nil {
+			continue
+		}
+		ticks[pid] = used
+		if prev, ok := c.lastTicks[pid]; ok && total > c.lastTotal && used >= prev {
+			// The system total covers every CPU; scale to one like top
+			info.CPU = float64(used-prev) / float64(total-c.lastTotal) * 100 * float64(numCPU)
+		}
+		procs = append(procs, info)
+	}
+	c.lastTicks, c.lastTotal = ticks, total
+
+	byCPU = topProcesses(procs, n, func(a, b ProcessInfo) bool { return a.CPU > b.CPU })
+	byMemory = topProcesses(procs, n, func(a, b ProcessInfo) bool { return a.RSS > b.RSS })
+	return byCPU, byMemory, nil
+}
+
+// topProcesses returns the first n of procs ordered by more, ties broken by
+// PID for a stable listing
+func topProcesses(procs []ProcessInfo, n int, more func(a, b ProcessInfo) bool) []ProcessInfo {
+	sorted := slices.Clone(procs)
+	slices.SortFunc(sorted, func(a, b ProcessInfo) int {
+		switch {
+		case more(a, b):
+			return -1
+		case more(b, a):
+			return 1
+		default:
+			return a.PID - b.PID
+		}
+	})
+	return sorted[:min(n, len(sorted))]
+}
+
+// readProcess reads a process's name, memory and CPU time in clock ticks
+func (c *ProcessCollector) readProcess(pid int) (ProcessInfo, uint64, error) {
+	dir := filepath.Join(c.path, strconv.Itoa(pid))
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return ProcessInfo{}, 0, err
+	}
+	// pid (comm) state ppid ...; comm may itself contain spaces and ")"
+	open, closing := bytes.IndexByte(stat, '('), bytes.LastIndexByte(stat, ')')
+	if open < 0 || closing < open {
+		return ProcessInfo{}, 0, fmt.Errorf("unexpected stat format for pid %d", pid)
+	}
+	fields := strings.Fields(string(stat[closing+1:]))
+	// utime and stime are fields 14 and 15 of stat, 12 and 13 after comm
+	if len(fields) < 13 {
+		return ProcessInfo{}, 0, fmt.Errorf("unexpected stat format for pid %d", pid)
+	}
+	utime, err1 := strconv.ParseUint(fields[11], 10, 64)
+	stime, err2 := strconv.ParseUint(fields[12], 10, 64)
+	if err1 != nil || err2 != nil {
+		return ProcessInfo{}, 0, fmt.Errorf("unexpected stat format for pid %d", pid)
+	}
+
+	// statm: size resident ...; in pages
+	var rss uint64
+	if statm, err := os.ReadFile(filepath.Join(dir, "statm")); err == nil {
+		if f := strings.Fields(string(statm)); len(f) >= 2 {
+			rss, _ = strconv.ParseUint(f[1], 10, 64)
+		}
+	}
+
+	return ProcessInfo{
+		PID:  pid,
+		Name: string(stat[open+1 : closing]),
+		RSS:  rss * c.pageSize,
+	}, utime + stime, nil
+}
+
+// cpuTotal returns the CPU time spent in all states by all CPUs, in clock
+// ticks, and the number of CPUs, from /proc/stat
+func (c *ProcessCollector) cpuTotal() (total uint64, numCPU int, err error) {
+	path := filepath.Join(c.path, "stat")
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+
+	found := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) > 1 && fields[0] == "cpu":
+			for _, v := range fields[1:] {
+				n, err := strconv.ParseUint(v, 10, 64)
+				if err != nil {
+					return 0, 0, fmt.Errorf("unexpected cpu line in %s: %q", path, scanner.Text())
+				}
+				total += n
+			}
+			found = true
+		case len(fields) > 0 && strings.HasPrefix(fields[0], "cpu"):
+			numCPU++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return 0, 0, fmt.Errorf("no cpu line in %s", path)
+	}
+	return total, max(numCPU, 1), nil
+}
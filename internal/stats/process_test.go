@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeProc writes a fake /proc with the given total CPU ticks and, per PID,
+// the command name, CPU ticks and resident pages
+func writeProc(t *testing.T, root string, total uint64, procs map[int]struct {
+	comm  string
+	ticks uint64
+	pages uint64
+}) {
+	t.Helper()
+	// Two CPUs; the "cpu" line sums them
+	stat := fmt.Sprintf("cpu  %d 0 0 0 0 0 0 0 0 0\ncpu0 1 0 0 0\ncpu1 1 0 0 0\nintr 0\n", total)
+	if err := os.WriteFile(filepath.Join(root, "stat"), []byte(stat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for pid, p := range procs {
+		dir := filepath.Join(root, fmt.Sprint(pid))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		// utime is field 14; split the ticks between utime and stime
+		line := fmt.Sprintf("%d (%s) S 1 1 1 0 -1 0 0 0 0 0 %d %d 0 0 20 0 1 0 0 0 0\n", pid, p.comm, p.ticks/2, p.ticks-p.ticks/2)
+		if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(line), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "statm"), []byte(fmt.Sprintf("1000 %d 0 0 0 0 0\n", p.pages)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestProcessCollector(t *testing.T) {
+	root := t.TempDir()
+	type proc = struct {
+		comm  string
+		ticks uint64
+		pages uint64
+	}
+	writeProc(t, root, 1000, map[int]proc{
+		1:   {"systemd", 100, 300},
+		42:  {"nginx: worker", 100, 900},
+		100: {"a (b) c", 100, 10},
+	})
+
+	c := NewProcessCollectorWithPath(root)
+	c.pageSize = 4096
+	byCPU, byMemory, err := c.GetTopProcesses(2)
+	if err != nil {
+		t.Fatalf("GetTopProcesses() failed: %v", err)
+	}
+	// Without a previous scan nothing has measurable CPU use
+	if len(byCPU) != 2 || byCPU[0].CPU != 0 {
+		t.Errorf("expected two processes without CPU use, got %+v", byCPU)
+	}
+	if len(byMemory) != 2 || byMemory[0].Name != "nginx: worker" || byMemory[0].RSS != 900*4096 || byMemory[1].PID != 1 {
+		t.Errorf("unexpected memory ranking %+v", byMemory)
+	}
+
+	// 200 of the 1000 ticks both CPUs spent went to PID 100: 40% of one CPU
+	writeProc(t, root, 2000, map[int]proc{
+		1:   {"systemd", 110, 300},
+		42:  {"nginx: worker", 150, 900},
+		100: {"a (b) c", 300, 10},
+	})
+	byCPU, _, err = c.GetTopProcesses(3)
+	if err != nil {
+		t.Fatalf("GetTopProcesses() failed: %v", err)
+	}
+	if byCPU[0].Name != "a (b) c" || byCPU[0].CPU != 40 || byCPU[1].PID != 42 || byCPU[1].CPU != 10 {
+		t.Errorf("unexpected CPU ranking %+v", byCPU)
+	}
+}
+
+func TestProcessCollectorMissingProc(t *testing.T) {
+	if _, _, err := NewProcessCollectorWithPath("/nonexistent/proc").GetTopProcesses(3); err == nil {
+		t.Error("expected error for a missing proc root")
+	}
+}
@@ -0,0 +1,323 @@
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// maxRecordedFrame caps the length of a line read from a recording
+const maxRecordedFrame = 1 << 20
+
+// recordedFrame is a line of a recording: a snapshot and when it was taken,
+// relative to the first
+type recordedFrame struct {
+	Offset time.Duration `json:"offset"`
+	Stats  SystemStats   `json:"stats"`
+}
+
+// Recorder is a Collector that writes every snapshot another collector
+// returns to a stream of JSON lines, for replaying with a Replayer. A write
+// that fails stops the recording but not collection.
+type Recorder struct {
+	collector Collector
+	log       *logger.Logger
+
+	mu     sync.Mutex
+	enc    *json.Encoder // nil once a write failed
+	start  time.Time
+	now    func() time.Time
+	frames int
+}
+
+// NewRecorder records the snapshots of collector to w
+func NewRecorder(collector Collector, w io.Writer) *Recorder {
+	return &Recorder{
+		collector: collector,
+		log:       logger.Global(),
+		enc:       json.NewEncoder(w),
+		now:       time.Now,
+	}
+}
+
+// Collect collects from the recorded collector and writes the snapshot
+func (r *Recorder) Collect() (*SystemStats, error) {
+	s, err := r.collector.Collect()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.enc == nil {
+		return s, nil
+	}
+	now := r.now()
+	if r.start.IsZero() {
+		r.start = now
+	}
+	if err := r.enc.Encode(recordedFrame{Offset: now.Sub(r.start), Stats: *s}); err != nil {
+		r.log.ErrorWithErr(err, "Failed to record stats, recording stopped")
+		r.enc = nil
+		return s, nil
+	}
+	r.frames++
+	return s, nil
+}
+
+// Frames returns how many snapshots have been recorded
+func (r *Recorder) Frames() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.frames
+}
+
+// LastTimings passes on the recorded collector's read times, if it has any
+func (r *Recorder) LastTimings() map[string]time.Duration {
+	if tc, ok := r.collector.(TimedCollector); ok {
+		return tc.LastTimings()
+	}
+	return nil
+}
+
+// Invalidate passes on to the recorded collector, if it caches readings
+func (r *Recorder) Invalidate(source string) {
+	if ic, ok := r.collector.(InvalidatingCollector); ok {
+		ic.Invalidate(source)
+	}
+}
+
+// Replayer is a Collector that plays back a recording: each Collect returns
+// the snapshot recorded at the same time since the first Collect as it was
+// since the start of the recording. It starts over at the end.
+type Replayer struct {
+	frames []recordedFrame
+	period time.Duration // length of one pass; 0 for a single frame
+
+	mu    sync.Mutex
+	start time.Time
+	now   func() time.Time
+}
+
+// LoadReplay reads a recording made by a Recorder
+func LoadReplay(path string) (*Replayer, error) {
+	f, err := os.Open(path) // #nosec G304 -- path given on the command line
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewReplayer(f)
+}
+
+// NewReplayer reads a recording made by a Recorder from r
+func NewReplayer(r io.Reader) (*Replayer, error) {
+	var frames []recordedFrame
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordedFrame)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var frame recordedFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(frames) > 0 && frame.Offset < frames[len(frames)-1].Offset {
+			return nil, fmt.Errorf("line %d: frame recorded before the previous one", line)
+		}
+		frames = append(frames, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("recording has no frames")
+	}
+
+	// The last frame stays up as long as the one before it did
+	var period time.Duration
+	if n := len(frames); n > 1 {
+		last := frames[n-1].Offset
+		period = last + last - frames[n-2].Offset
+	}
+	return &Replayer{frames: frames, period: period, now: time.Now}, nil
+}
+
+// Collect returns the snapshot due now
+func (p *Replayer) Collect() (*SystemStats, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if p.start.IsZero() {
+		p.start = now
+	}
+	var elapsed time.Duration
+	if p.period > 0 {
+		elapsed = now.Sub(p.start) % p.period
+	}
+	i := sort.Search(len(p.frames), func(i int) bool {
+		return p.frames[i].Offset > elapsed
+	}) - 1
+	s := p.frames[max(i, 0)].Stats
+	return &s, nil
+}
+
+// Frames returns how many snapshots the recording has
+func (p *Replayer) Frames() int {
+	return len(p.frames)
+}
+
+// recordedError is an error played back from a recording, which keeps only
+// its message
+type recordedError string
+
+func (e recordedError) Error() string {
+	return string(e)
+}
+
+// errorText returns the message of err, or "" when nil
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// replayedError returns the error recorded as msg, or nil when empty
+func replayedError(msg string) error {
+	if msg == "" {
+		return nil
+	}
+	return recordedError(msg)
+}
+
+// Errors do not survive JSON on their own; the types holding one record it
+// as its message
+
+// MarshalJSON records Err as its message
+func (o ExecOutput) MarshalJSON() ([]byte, error) {
+	type plain ExecOutput
+	return json.Marshal(struct {
+		plain
+		Err string `json:",omitempty"`
+	}{plain(o), errorText(o.Err)})
+}
+
+// UnmarshalJSON reads output recorded by MarshalJSON
+func (o *ExecOutput) UnmarshalJSON(b []byte) error {
+	type plain ExecOutput
+	var v struct {
+		plain
+		Err string
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*o = ExecOutput(v.plain)
+	o.Err = replayedError(v.Err)
+	return nil
+}
+
+// MarshalJSON records Err as its message
+func (c ConnectivityStatus) MarshalJSON() ([]byte, error) {
+	type plain ConnectivityStatus
+	return json.Marshal(struct {
+		plain
+		Err string `json:",omitempty"`
+	}{plain(c), errorText(c.Err)})
+}
+
+// UnmarshalJSON reads a status recorded by MarshalJSON
+func (c *ConnectivityStatus) UnmarshalJSON(b []byte) error {
+	type plain ConnectivityStatus
+	var v struct {
+		plain
+		Err string
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*c = ConnectivityStatus(v.plain)
+	c.Err = replayedError(v.Err)
+	return nil
+}
+
+// MarshalJSON records Err as its message
+func (u UpdateStatus) MarshalJSON() ([]byte, error) {
+	type plain UpdateStatus
+	return json.Marshal(struct {
+		plain
+		Err string `json:",omitempty"`
+	}{plain(u), errorText(u.Err)})
+}
+
+// UnmarshalJSON reads a status recorded by MarshalJSON
+func (u *UpdateStatus) UnmarshalJSON(b []byte) error {
+	type plain UpdateStatus
+	var v struct {
+		plain
+		Err string
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*u = UpdateStatus(v.plain)
+	u.Err = replayedError(v.Err)
+	return nil
+}
+
+// MarshalJSON records Err as its message
+func (t TimeSyncStatus) MarshalJSON() ([]byte, error) {
+	type plain TimeSyncStatus
+	return json.Marshal(struct {
+		plain
+		Err string `json:",omitempty"`
+	}{plain(t), errorText(t.Err)})
+}
+
+// UnmarshalJSON reads a status recorded by MarshalJSON
+func (t *TimeSyncStatus) UnmarshalJSON(b []byte) error {
+	type plain TimeSyncStatus
+	var v struct {
+		plain
+		Err string
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*t = TimeSyncStatus(v.plain)
+	t.Err = replayedError(v.Err)
+	return nil
+}
+
+// MarshalJSON records Err as its message
+func (l LatencyReading) MarshalJSON() ([]byte, error) {
+	type plain LatencyReading
+	return json.Marshal(struct {
+		plain
+		Err string `json:",omitempty"`
+	}{plain(l), errorText(l.Err)})
+}
+
+// UnmarshalJSON reads a reading recorded by MarshalJSON
+func (l *LatencyReading) UnmarshalJSON(b []byte) error {
+	type plain LatencyReading
+	var v struct {
+		plain
+		Err string
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*l = LatencyReading(v.plain)
+	l.Err = replayedError(v.Err)
+	return nil
+}
@@ -0,0 +1,120 @@
+package stats
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sequenceCollector returns each of its snapshots in turn
+type sequenceCollector struct {
+	stats []SystemStats
+	next  int
+}
+
+func (c *sequenceCollector) Collect() (*SystemStats, error) {
+	s := c.stats[c.next%len(c.stats)]
+	c.next++
+	return &s, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first := SystemStats{
+		Hostname:   "pi",
+		CPUTemp:    48.5,
+		Interfaces: []NetInterface{{Name: "eth0", IPv4Addrs: []string{"192.168.1.2"}}},
+		Uptime:     3 * time.Hour,
+		Exec:       map[string]ExecOutput{"Jobs": {Lines: []string{"3 queued"}, Err: errors.New("exit status 1"), At: at}},
+		Connectivity: &ConnectivityStatus{
+			Err: errors.New("no route to host"),
+			At:  at,
+		},
+		Updates:  &UpdateStatus{Count: 4, At: at},
+		TimeSync: &TimeSyncStatus{Synced: true, Source: "chrony", Err: errors.New("timeout"), At: at},
+		Latency:  []LatencyReading{{Name: "gw", RTT: time.Millisecond, Err: errors.New("lost")}},
+	}
+	second := first
+	second.CPUTemp = 61
+
+	var buf bytes.Buffer
+	now := at
+	rec := NewRecorder(&sequenceCollector{stats: []SystemStats{first, second}}, &buf)
+	rec.now = func() time.Time { return now }
+	for range 2 {
+		if _, err := rec.Collect(); err != nil {
+			t.Fatalf("Collect() failed: %v", err)
+		}
+		now = now.Add(2 * time.Second)
+	}
+	if rec.Frames() != 2 {
+		t.Fatalf("expected 2 frames recorded, got %d", rec.Frames())
+	}
+
+	replay, err := NewReplayer(&buf)
+	if err != nil {
+		t.Fatalf("NewReplayer() failed: %v", err)
+	}
+	now = at
+	replay.now = func() time.Time { return now }
+
+	got, _ := replay.Collect()
+	if !reflect.DeepEqual(*got, replayed(first)) {
+		t.Errorf("first frame = %+v, want %+v", *got, first)
+	}
+	if got.Exec["Jobs"].Err == nil || got.Exec["Jobs"].Err.Error() != "exit status 1" {
+		t.Errorf("expected the exec error to be replayed, got %v", got.Exec["Jobs"].Err)
+	}
+	if got.Updates.Err != nil {
+		t.Errorf("expected no update error, got %v", got.Updates.Err)
+	}
+
+	// Frames play at their recorded times and start over after the last
+	for _, tt := range []struct {
+		after time.Duration
+		temp  float64
+	}{{time.Second, 48.5}, {2 * time.Second, 61}, {3 * time.Second, 61}, {4 * time.Second, 48.5}} {
+		now = at.Add(tt.after)
+		if got, _ := replay.Collect(); got.CPUTemp != tt.temp {
+			t.Errorf("after %s: CPU temperature %.1f, want %.1f", tt.after, got.CPUTemp, tt.temp)
+		}
+	}
+}
+
+// replayed returns s as a replay returns it, with errors reduced to their
+// messages
+func replayed(s SystemStats) SystemStats {
+	exec := make(map[string]ExecOutput, len(s.Exec))
+	for title, out := range s.Exec {
+		out.Err = replayedError(errorText(out.Err))
+		exec[title] = out
+	}
+	s.Exec = exec
+	conn := *s.Connectivity
+	conn.Err = replayedError(errorText(conn.Err))
+	s.Connectivity = &conn
+	sync := *s.TimeSync
+	sync.Err = replayedError(errorText(sync.Err))
+	s.TimeSync = &sync
+	latency := append([]LatencyReading(nil), s.Latency...)
+	for i := range latency {
+		latency[i].Err = replayedError(errorText(latency[i].Err))
+	}
+	s.Latency = latency
+	return s
+}
+
+func TestNewReplayerErrors(t *testing.T) {
+	for _, tt := range []struct{ name, input string }{
+		{"empty", ""},
+		{"not JSON", "{"},
+		{"out of order", `{"offset":2000000000,"stats":{}}` + "\n" + `{"offset":1000000000,"stats":{}}`},
+	} {
+		if _, err := NewReplayer(strings.NewReader(tt.input)); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
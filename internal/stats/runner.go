@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"sync"
+	"time"
+)
+
+// runner runs a check in the background on its interval and keeps its
+// latest result, so a slow or hanging check never holds up Collect. At most
+// one check runs at a time.
+type runner[T any] struct {
+	interval time.Duration
+	// check runs once and returns the new result. It is given the previous
+	// result to carry values over from, such as the output kept when a
+	// command fails.
+	check func(prev T) T
+
+	mu      sync.Mutex
+	running bool
+	started time.Time
+	result  T
+	runs    int // checks finished so far
+}
+
+// poll starts a check if one is due and none is in progress, and returns the
+// latest finished result. ok is false until the first check finishes.
+func (r *runner[T]) poll(now time.Time) (result T, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.running && (r.started.IsZero() || now.Sub(r.started) >= r.interval) {
+		r.running = true
+		r.started = now
+		go r.run(r.result)
+	}
+	return r.result, r.runs > 0
+}
+
+// run checks once and stores the result
+func (r *runner[T]) run(prev T) {
+	result := r.check(prev)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.running = false
+	r.runs++
+	r.result = result
+}
@@ -0,0 +1,76 @@
+package stats
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForRun polls r at now until a check started after the call has
+// finished, and returns its result. now must be due for a check, and no
+// check may be in progress when it is called.
+func waitForRun[T any](t *testing.T, r *runner[T], now time.Time) T {
+	t.Helper()
+	r.mu.Lock()
+	runs := r.runs
+	r.mu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		r.poll(now)
+		r.mu.Lock()
+		result, finished := r.result, r.runs > runs && !r.running
+		r.mu.Unlock()
+		if finished {
+			return result
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("check did not finish")
+	var zero T
+	return zero
+}
+
+func TestRunner(t *testing.T) {
+	release := make(chan struct{})
+	var checks atomic.Int32
+	r := &runner[int]{interval: time.Minute, check: func(prev int) int {
+		<-release
+		checks.Add(1)
+		return prev + 1
+	}}
+
+	// Nothing to return until the first check finishes
+	start := time.Now()
+	if _, ok := r.poll(start); ok {
+		t.Fatal("expected no result before the first check finished")
+	}
+	// A check in progress is not started again, even once due
+	r.poll(start.Add(time.Hour))
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if got, ok := r.poll(start); ok {
+			if got != 1 {
+				t.Errorf("expected the first result, got %d", got)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("first check did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Not due yet
+	if got, ok := r.poll(start.Add(30 * time.Second)); !ok || got != 1 {
+		t.Errorf("expected the kept result, got %d, %v", got, ok)
+	}
+	// Due: the next check builds on the previous result
+	if got := waitForRun(t, r, start.Add(time.Minute)); got != 2 {
+		t.Errorf("expected the previous result carried over, got %d", got)
+	}
+	if n := checks.Load(); n != 2 {
+		t.Errorf("expected 2 checks, got %d", n)
+	}
+}
@@ -0,0 +1,193 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/health"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// Service reads each of a SystemCollector's sources in its own goroutine on
+// its own interval and serves the latest readings, so Collect never waits on
+// a slow disk or a flaky sysfs read. A source that fails to read keeps its
+// previous reading, or the zero value until it is first read.
+type Service struct {
+	sc       *SystemCollector
+	interval time.Duration // for sources without a pages.refresh_intervals entry
+	log      *logger.Logger
+
+	mu       sync.Mutex
+	snapshot SystemStats
+	timings  map[string]time.Duration // read time of each source read since the last LastTimings
+	failed   map[string]error         // sources whose last read failed, with the error
+	health   *health.Checker          // optional, receives the outcome of each read
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewService creates a service reading sc's sources. Sources without an
+// interval of their own are read every pages.refresh_interval. sc must not
+// be used directly once the service has started.
+func NewService(sc *SystemCollector) *Service {
+	interval, err := sc.config.Pages.GetRefreshInterval()
+	if err != nil || interval <= 0 {
+		// Only an unvalidated config gets here
+		interval = time.Second
+	}
+	return &Service{
+		sc:       sc,
+		interval: interval,
+		log:      logger.Global(),
+		timings:  make(map[string]time.Duration),
+		failed:   make(map[string]error),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// SetHealthChecker registers an optional health component per source, named
+// "collector.<source>", and records the outcome of each read against it, so
+// a source failing repeatedly shows as degraded. It never turns the service
+// unhealthy, as the source's previous reading is still shown. Sources that
+// failed to read in Start are recorded straight away.
+func (svc *Service) SetHealthChecker(h *health.Checker) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.health = h
+	for _, src := range svc.sc.sources {
+		h.RegisterOptionalComponent(healthComponent(src.name))
+		if err, ok := svc.failed[src.name]; ok {
+			h.RecordError(healthComponent(src.name), err)
+		}
+	}
+}
+
+// healthComponent names the health component of a source
+func healthComponent(source string) string {
+	return health.ComponentCollector + "." + source
+}
+
+// Start reads every source once and then re-reads each on its interval
+// until ctx is done or Stop is called. A source that cannot be read, such as
+// a disk on a dead NFS mount, is shown as zero until a later read succeeds;
+// Start only fails when no source can be read.
+func (svc *Service) Start(ctx context.Context) error {
+	var errs []error
+	for _, src := range svc.sc.sources {
+		if err := svc.read(src); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 && len(errs) == len(svc.sc.sources) {
+		return fmt.Errorf("no stats source could be read: %w", errors.Join(errs...))
+	}
+
+	for _, src := range svc.sc.sources {
+		interval, ok := svc.sc.intervals[src.name]
+		if !ok {
+			interval = svc.interval
+		}
+		svc.wg.Add(1)
+		go svc.run(ctx, src, interval)
+	}
+	return nil
+}
+
+// run re-reads src every interval
+func (svc *Service) run(ctx context.Context, src *source, interval time.Duration) {
+	defer svc.wg.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			svc.log.Errorf("PANIC reading stats source %s: %v", src.name, r)
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-svc.stopChan:
+			return
+		case <-ticker.C:
+			_ = svc.read(src) // failures are logged; the previous reading stays
+		}
+	}
+}
+
+// read reads src and stores the reading in the snapshot, logging when the
+// source starts or stops failing
+func (svc *Service) read(src *source) error {
+	start := time.Now()
+	store, err := svc.sc.get(src)
+	d := time.Since(start)
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if svc.health != nil {
+		if err != nil {
+			svc.health.RecordError(healthComponent(src.name), err)
+		} else {
+			svc.health.RecordSuccess(healthComponent(src.name))
+		}
+	}
+	if err != nil {
+		if _, ok := svc.failed[src.name]; !ok {
+			svc.log.With().Str("source", src.name).Err(err).Logger().Warn("Failed to read stats, keeping the previous reading")
+		}
+		svc.failed[src.name] = err
+		return err
+	}
+	if _, ok := svc.failed[src.name]; ok {
+		delete(svc.failed, src.name)
+		svc.log.With().Str("source", src.name).Logger().Info("Stats readable again")
+	}
+	store(&svc.snapshot)
+	svc.timings[src.name] = d
+	return nil
+}
+
+// Collect returns the latest reading of every source without reading any
+func (svc *Service) Collect() (*SystemStats, error) {
+	svc.mu.Lock()
+	stats := svc.snapshot
+	svc.mu.Unlock()
+
+	svc.sc.pollBackground(&stats, svc.sc.now())
+	return &stats, nil
+}
+
+// LastTimings returns how long each source took to read, keyed by
+// config.Source* name, for the sources read since the previous call
+func (svc *Service) LastTimings() map[string]time.Duration {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	timings := maps.Clone(svc.timings)
+	clear(svc.timings)
+	return timings
+}
+
+// Invalidate re-reads source (a config.Source* name) now, so the next
+// Collect returns a fresh reading whatever its interval
+func (svc *Service) Invalidate(source string) {
+	for _, src := range svc.sc.sources {
+		if src.name == source {
+			_ = svc.read(src)
+			return
+		}
+	}
+}
+
+// Stop stops reading sources and waits for reads in progress to finish
+func (svc *Service) Stop() {
+	svc.stopOnce.Do(func() {
+		close(svc.stopChan)
+	})
+	svc.wg.Wait()
+}
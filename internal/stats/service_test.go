@@ -0,0 +1,188 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/health"
+)
+
+func TestService(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "temp")
+	if err := os.WriteFile(tempFile, []byte("40000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.SystemInfo.TemperatureSource = tempFile
+	cfg.SystemInfo.DiskPath = "/"
+	cfg.Pages.RefreshInterval = "1h"
+	cfg.Pages.RefreshIntervals = map[string]string{config.SourceTemperature: "10ms"}
+
+	sc, err := NewSystemCollector(cfg)
+	if err != nil {
+		t.Fatalf("NewSystemCollector() failed: %v", err)
+	}
+	svc := NewService(sc)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := svc.Start(ctx); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer svc.Stop()
+
+	s, err := svc.Collect()
+	if err != nil {
+		t.Fatalf("Collect() failed: %v", err)
+	}
+	if s.CPUTemp != 40 || s.MemoryTotal == 0 || s.Hostname == "" {
+		t.Errorf("expected every source read by Start, got %+v", s)
+	}
+	if timings := svc.LastTimings(); len(timings) != len(sc.sources) {
+		t.Errorf("expected a timing per source, got %v", timings)
+	}
+
+	// The temperature is re-read on its own interval
+	if err := os.WriteFile(tempFile, []byte("50000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if s, _ := svc.Collect(); s.CPUTemp == 50 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if s, _ := svc.Collect(); s.CPUTemp != 50 {
+		t.Errorf("expected the temperature to be re-read, got %.1f", s.CPUTemp)
+	}
+	if _, ok := svc.LastTimings()[config.SourceMemory]; ok {
+		t.Error("expected memory, on the hourly interval, not to be re-read")
+	}
+
+	// An invalidated source is read before Invalidate returns
+	svc.Invalidate(config.SourceMemory)
+	if _, ok := svc.LastTimings()[config.SourceMemory]; !ok {
+		t.Error("expected Invalidate to re-read memory")
+	}
+}
+
+func TestServiceKeepsReadingOnFailure(t *testing.T) {
+	cfg := config.Default()
+	cfg.SystemInfo.DiskPath = "/"
+	sc, err := NewSystemCollector(cfg)
+	if err != nil {
+		t.Fatalf("NewSystemCollector() failed: %v", err)
+	}
+	svc := NewService(sc)
+	if err := svc.Start(context.Background()); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer svc.Stop()
+	before, _ := svc.Collect()
+
+	// A failed read keeps the previous reading
+	sc.diskCollector = NewDiskCollector(filepath.Join(t.TempDir(), "missing"))
+	svc.Invalidate(config.SourceDisk)
+	after, _ := svc.Collect()
+	if after.DiskTotal != before.DiskTotal || after.DiskTotal == 0 {
+		t.Errorf("expected the previous disk reading to be kept, got %d from %d", after.DiskTotal, before.DiskTotal)
+	}
+}
+
+func TestServiceReadTimeout(t *testing.T) {
+	sc, err := NewSystemCollector(config.Default())
+	if err != nil {
+		t.Fatalf("NewSystemCollector() failed: %v", err)
+	}
+	release := make(chan struct{})
+	hung := &source{name: config.SourceDisk, read: func() (func(*SystemStats), error) {
+		<-release
+		return func(s *SystemStats) { s.DiskTotal = 42 }, nil
+	}}
+	load := &source{name: config.SourceLoad, read: func() (func(*SystemStats), error) {
+		return func(s *SystemStats) { s.LoadAvg1 = 1 }, nil
+	}}
+	sc.sources = []*source{hung, load}
+	sc.timeout = 20 * time.Millisecond
+
+	svc := NewService(sc)
+	h := health.New()
+	svc.SetHealthChecker(h)
+
+	// The hung source does not stop the service from starting
+	start := time.Now()
+	if err := svc.Start(context.Background()); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("expected Start to give up after the timeout, took %s", d)
+	}
+	defer svc.Stop()
+	if s, _ := svc.Collect(); s.DiskTotal != 0 || s.LoadAvg1 != 1 {
+		t.Errorf("expected the disk left at zero and the load read, got %d and %.1f", s.DiskTotal, s.LoadAvg1)
+	}
+
+	// While the read hangs, further reads fail at once
+	for range 2 {
+		svc.Invalidate(config.SourceDisk)
+	}
+	if comp := h.GetComponentStatus("collector.disk"); comp == nil || comp.Status != health.StatusDegraded {
+		t.Errorf("expected the disk source degraded after 3 failures, got %+v", comp)
+	}
+
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for !hung.mu.TryLock() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	hung.mu.Unlock()
+	svc.Invalidate(config.SourceDisk)
+	if s, _ := svc.Collect(); s.DiskTotal != 42 {
+		t.Errorf("expected the read to succeed once it no longer hangs, got %d", s.DiskTotal)
+	}
+}
+
+func TestServiceStartFailures(t *testing.T) {
+	sc, err := NewSystemCollector(config.Default())
+	if err != nil {
+		t.Fatalf("NewSystemCollector() failed: %v", err)
+	}
+	failing := func(name string) *source {
+		return &source{name: name, read: func() (func(*SystemStats), error) {
+			return nil, errors.New(name + ": not readable")
+		}}
+	}
+
+	// Failures before the health checker is attached are still recorded
+	sc.sources = []*source{failing(config.SourceDisk), {name: config.SourceLoad, read: func() (func(*SystemStats), error) {
+		return func(*SystemStats) {}, nil
+	}}}
+	svc := NewService(sc)
+	if err := svc.Start(context.Background()); err != nil {
+		t.Fatalf("expected Start to succeed with one source readable, got %v", err)
+	}
+	svc.Stop()
+	h := health.New()
+	svc.SetHealthChecker(h)
+	if comp := h.GetComponentStatus("collector.disk"); comp == nil || comp.TotalErrors != 1 {
+		t.Errorf("expected the failed disk read recorded, got %+v", comp)
+	}
+	if comp := h.GetComponentStatus("collector.load"); comp == nil || comp.TotalErrors != 0 {
+		t.Errorf("expected no error recorded for load, got %+v", comp)
+	}
+
+	// Start fails only when no source can be read
+	sc.sources = []*source{failing(config.SourceDisk), failing(config.SourceLoad)}
+	svc = NewService(sc)
+	err = svc.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "disk: not readable") || !strings.Contains(err.Error(), "load: not readable") {
+		t.Errorf("expected every source's error, got %v", err)
+	}
+}
@@ -1,7 +1,11 @@
 package stats
 
 import (
+	"math"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ausil/i2c-display/internal/config"
 )
@@ -133,6 +137,35 @@ func TestDiskCollector(t *testing.T) {
 	}
 }
 
+func TestDiskCollectorUsage(t *testing.T) {
+	usage, err := NewDiskCollector(t.TempDir()).GetUsage()
+	if err != nil {
+		t.Fatalf("GetUsage() failed: %v", err)
+	}
+
+	if usage.Total == 0 || usage.Used > usage.Total {
+		t.Errorf("unexpected space usage %d/%d", usage.Used, usage.Total)
+	}
+	if usage.InodesUsed > usage.InodesTotal {
+		t.Errorf("inodes used (%d) should not exceed total (%d)", usage.InodesUsed, usage.InodesTotal)
+	}
+	if usage.ReadOnly {
+		t.Error("expected the test's temporary directory to be writable")
+	}
+}
+
+func TestDiskInodePercent(t *testing.T) {
+	s := &SystemStats{DiskInodesUsed: 25, DiskInodesTotal: 100}
+	if got := s.DiskInodePercent(); got != 25 {
+		t.Errorf("DiskInodePercent() = %v, want 25", got)
+	}
+	// Filesystems such as btrfs report no inode count
+	s = &SystemStats{DiskInodesUsed: 25}
+	if got := s.DiskInodePercent(); got != 0 {
+		t.Errorf("DiskInodePercent() without a total = %v, want 0", got)
+	}
+}
+
 func TestDiskCollectorNonExistent(t *testing.T) {
 	collector := NewDiskCollector("/nonexistent/path/that/does/not/exist")
 
@@ -344,6 +377,47 @@ func TestSystemCollectorFahrenheit(t *testing.T) {
 	}
 }
 
+func TestSystemCollectorKelvin(t *testing.T) {
+	cfg := config.Default()
+	cfg.SystemInfo.TemperatureSource = "../../testdata/sys/class/thermal/thermal_zone0/temp"
+	cfg.SystemInfo.TemperatureUnit = config.UnitKelvin
+
+	collector, err := NewSystemCollector(cfg)
+	if err != nil {
+		t.Fatalf("NewSystemCollector() failed: %v", err)
+	}
+
+	stats, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("Collect() failed: %v", err)
+	}
+
+	// Test data is 45.2°C, which is 318.35K
+	if stats.CPUTemp < 317.35 || stats.CPUTemp > 319.35 {
+		t.Errorf("expected temp~318.4K, got %.1fK", stats.CPUTemp)
+	}
+}
+
+func TestTemperatureConversion(t *testing.T) {
+	tests := []struct {
+		unit  string
+		value float64 // 45°C in unit
+	}{
+		{config.UnitCelsius, 45},
+		{config.UnitFahrenheit, 113},
+		{config.UnitKelvin, 318.15},
+		{"", 45},
+	}
+	for _, tt := range tests {
+		if got := FromCelsius(45, tt.unit); math.Abs(got-tt.value) > 1e-9 {
+			t.Errorf("FromCelsius(45, %q) = %v, want %v", tt.unit, got, tt.value)
+		}
+		if got := ToCelsius(tt.value, tt.unit); math.Abs(got-45) > 1e-9 {
+			t.Errorf("ToCelsius(%v, %q) = %v, want 45", tt.value, tt.unit, got)
+		}
+	}
+}
+
 func TestSystemCollectorCollect(t *testing.T) {
 	cfg := config.Default()
 	cfg.SystemInfo.TemperatureSource = "../../testdata/sys/class/thermal/thermal_zone0/temp"
@@ -402,6 +476,72 @@ func TestSystemCollectorCollectNoTemp(t *testing.T) {
 	}
 }
 
+func TestSystemCollectorStaggersSources(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "temp")
+	if err := os.WriteFile(tempFile, []byte("40000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.SystemInfo.TemperatureSource = tempFile
+	cfg.SystemInfo.DiskPath = "/"
+	cfg.Pages.RefreshIntervals = map[string]string{config.SourceTemperature: "10s"}
+
+	collector, err := NewSystemCollector(cfg)
+	if err != nil {
+		t.Fatalf("NewSystemCollector() failed: %v", err)
+	}
+	now := time.Now()
+	collector.now = func() time.Time { return now }
+
+	collect := func() *SystemStats {
+		t.Helper()
+		s, err := collector.Collect()
+		if err != nil {
+			t.Fatalf("Collect() failed: %v", err)
+		}
+		return s
+	}
+
+	if s := collect(); s.CPUTemp != 40 {
+		t.Fatalf("expected 40C, got %.1f", s.CPUTemp)
+	}
+	if err := os.WriteFile(tempFile, []byte("50000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Within the interval the previous reading is reused
+	now = now.Add(5 * time.Second)
+	s := collect()
+	if s.CPUTemp != 40 {
+		t.Errorf("expected cached 40C before the interval elapsed, got %.1f", s.CPUTemp)
+	}
+	if s.MemoryTotal == 0 {
+		t.Error("expected memory to be collected on every call")
+	}
+	timings := collector.LastTimings()
+	if _, ok := timings[config.SourceTemperature]; ok {
+		t.Error("expected no timing for a cached source")
+	}
+	if _, ok := timings[config.SourceMemory]; !ok {
+		t.Error("expected a timing for memory, which was re-read")
+	}
+
+	now = now.Add(5 * time.Second)
+	if s := collect(); s.CPUTemp != 50 {
+		t.Errorf("expected fresh 50C once the interval elapsed, got %.1f", s.CPUTemp)
+	}
+
+	// An invalidated source is re-read before its interval elapses
+	if err := os.WriteFile(tempFile, []byte("60000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	collector.Invalidate(config.SourceTemperature)
+	if s := collect(); s.CPUTemp != 60 {
+		t.Errorf("expected 60C after Invalidate, got %.1f", s.CPUTemp)
+	}
+}
+
 func TestNetworkCollectorIPv6(t *testing.T) {
 	cfg := config.NetworkConfig{
 		AutoDetect: true,
@@ -473,3 +613,91 @@ func TestNetworkCollectorNoIPVersions(t *testing.T) {
 		}
 	}
 }
+
+func TestSystemCollectorTemperatureSensors(t *testing.T) {
+	cfg := config.Default()
+	cfg.SystemInfo.TemperatureSensors = []config.TemperatureSensorConfig{
+		{Name: "SoC", Source: "../../testdata/sys/class/thermal/thermal_zone0/temp"},
+		{Name: "Missing", Source: "/nonexistent/temp"},
+	}
+
+	collector, err := NewSystemCollector(cfg)
+	if err != nil {
+		t.Fatalf("NewSystemCollector() failed: %v", err)
+	}
+
+	stats, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("Collect() failed: %v", err)
+	}
+
+	if len(stats.Temperatures) != 1 {
+		t.Fatalf("expected 1 readable sensor, got %d", len(stats.Temperatures))
+	}
+	if stats.Temperatures[0].Name != "SoC" {
+		t.Errorf("expected sensor name SoC, got %s", stats.Temperatures[0].Name)
+	}
+	if stats.Temperatures[0].Value < 45.1 || stats.Temperatures[0].Value > 45.3 {
+		t.Errorf("expected ~45.2, got %f", stats.Temperatures[0].Value)
+	}
+}
+
+func TestNetworkCollectorGateways(t *testing.T) {
+	collector := NewNetworkCollector(config.NetworkConfig{})
+	collector.routePath = "../../testdata/proc/net/route"
+
+	gateways, err := collector.gateways()
+	if err != nil {
+		t.Fatalf("gateways() failed: %v", err)
+	}
+	want := map[string]string{"eth0": "192.168.1.1", "wlan0": "10.0.0.1"}
+	if len(gateways) != len(want) {
+		t.Fatalf("gateways() = %v, want %v", gateways, want)
+	}
+	for name, gw := range want {
+		if gateways[name] != gw {
+			t.Errorf("gateway of %s = %q, want %q", name, gateways[name], gw)
+		}
+	}
+}
+
+func TestNetworkCollectorDNSServers(t *testing.T) {
+	dir := t.TempDir()
+	stub := filepath.Join(dir, "stub.conf")
+	if err := os.WriteFile(stub, []byte("# generated\nnameserver 1.1.1.1\nsearch lan\nnameserver 2606:4700:4700::1111\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	collector := NewNetworkCollector(config.NetworkConfig{})
+	// The first readable file wins
+	collector.resolvPaths = []string{filepath.Join(dir, "missing.conf"), stub}
+	servers := collector.GetDNSServers()
+	if len(servers) != 2 || servers[0] != "1.1.1.1" || servers[1] != "2606:4700:4700::1111" {
+		t.Errorf("GetDNSServers() = %v", servers)
+	}
+
+	collector.resolvPaths = []string{filepath.Join(dir, "missing.conf")}
+	if servers := collector.GetDNSServers(); servers != nil {
+		t.Errorf("GetDNSServers() = %v, want nil without a resolv.conf", servers)
+	}
+}
+
+func TestNetworkCollectorAddressing(t *testing.T) {
+	collector := NewNetworkCollector(config.NetworkConfig{
+		AutoDetect: true,
+		ShowIPv4:   true,
+	})
+	collector.sources = func() (map[string]string, error) {
+		return map[string]string{"127.0.0.1": AddressingStatic}, nil
+	}
+
+	interfaces, err := collector.GetInterfaces()
+	if err != nil {
+		t.Fatalf("GetInterfaces() failed: %v", err)
+	}
+	for _, iface := range interfaces {
+		if len(iface.IPv4Addrs) > 0 && iface.IPv4Addrs[0] == "127.0.0.1" && iface.Addressing != AddressingStatic {
+			t.Errorf("expected the loopback address to be static, got %q", iface.Addressing)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+//go:build darwin || freebsd
+
+package stats
+
+import (
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// There is no /proc here; memory, load and uptime come from sysctl
+const (
+	defaultMeminfoPath = ""
+	defaultLoadAvgPath = ""
+	defaultUptimePath  = ""
+)
+
+// platformMemory reads total memory and the free page count. Pages the
+// kernel could reclaim, such as inactive ones, count as used.
+func platformMemory() (used, total uint64, err error) {
+	totalName, freeName := "hw.memsize", "vm.page_free_count"
+	if runtime.GOOS == "freebsd" {
+		totalName, freeName = "hw.physmem", "vm.stats.vm.v_free_count"
+	}
+	if total, err = sysctlUint64(totalName); err != nil {
+		return 0, 0, err
+	}
+	free, err := sysctlUint64(freeName)
+	if err != nil {
+		return 0, 0, err
+	}
+	pageSize, err := sysctlUint64("hw.pagesize")
+	if err != nil {
+		return 0, 0, err
+	}
+	if free*pageSize > total {
+		return 0, total, nil
+	}
+	return total - free*pageSize, total, nil
+}
+
+// platformLoadAvg reads vm.loadavg, a struct loadavg: three fixed-point
+// averages followed by their scale
+func platformLoadAvg() (avg1, avg5, avg15 float64, err error) {
+	b, err := sysctlBytes("vm.loadavg", 24)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	scale := float64(binary.NativeEndian.Uint64(b[16:24]))
+	if scale == 0 {
+		return 0, 0, 0, fmt.Errorf("vm.loadavg has no scale")
+	}
+	avg := func(i int) float64 {
+		return float64(binary.NativeEndian.Uint32(b[4*i:])) / scale
+	}
+	return avg(0), avg(1), avg(2), nil
+}
+
+// platformUptime reads kern.boottime, a struct timeval
+func platformUptime() (time.Duration, error) {
+	b, err := sysctlBytes("kern.boottime", 16)
+	if err != nil {
+		return 0, err
+	}
+	boot := time.Unix(int64(binary.NativeEndian.Uint64(b[0:8])), 0) // #nosec G115 -- seconds since the epoch
+	return time.Since(boot), nil
+}
+
+// sysctlUint64 reads a 32 or 64-bit integer sysctl
+func sysctlUint64(name string) (uint64, error) {
+	b, err := sysctlBytes(name, 8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.NativeEndian.Uint64(b), nil
+}
+
+// sysctlBytes reads a binary sysctl, zero-padded to at least size bytes:
+// syscall.Sysctl treats values as strings and drops a trailing zero byte
+func sysctlBytes(name string, size int) ([]byte, error) {
+	value, err := syscall.Sysctl(name)
+	if err != nil {
+		return nil, fmt.Errorf("sysctl %s: %w", name, err)
+	}
+	b := []byte(value)
+	if len(b) < size {
+		b = append(b, make([]byte, size-len(b))...)
+	}
+	return b, nil
+}
@@ -0,0 +1,27 @@
+//go:build darwin || freebsd
+
+package stats
+
+import "testing"
+
+func TestPlatformCollectors(t *testing.T) {
+	used, total, err := NewMemoryCollector().GetMemory()
+	if err != nil {
+		t.Fatalf("GetMemory() failed: %v", err)
+	}
+	if total == 0 || used > total {
+		t.Errorf("unexpected memory reading: used %d of %d", used, total)
+	}
+
+	if _, _, _, err := NewLoadAvgCollector().GetLoadAvg(); err != nil {
+		t.Errorf("GetLoadAvg() failed: %v", err)
+	}
+
+	uptime, err := NewUptimeCollector().GetUptime()
+	if err != nil {
+		t.Fatalf("GetUptime() failed: %v", err)
+	}
+	if uptime <= 0 {
+		t.Errorf("expected a positive uptime, got %s", uptime)
+	}
+}
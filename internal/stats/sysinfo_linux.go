@@ -0,0 +1,28 @@
+package stats
+
+import (
+	"errors"
+	"time"
+)
+
+// The kernel reports memory, load and uptime as text under /proc
+const (
+	defaultMeminfoPath = "/proc/meminfo"
+	defaultLoadAvgPath = "/proc/loadavg"
+	defaultUptimePath  = "/proc/uptime"
+)
+
+// The collectors always read /proc on Linux; these only satisfy the other
+// platforms' fallbacks
+
+func platformMemory() (used, total uint64, err error) {
+	return 0, 0, errors.ErrUnsupported
+}
+
+func platformLoadAvg() (avg1, avg5, avg15 float64, err error) {
+	return 0, 0, 0, errors.ErrUnsupported
+}
+
+func platformUptime() (time.Duration, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,28 @@
+//go:build !linux && !darwin && !freebsd
+
+package stats
+
+import (
+	"errors"
+	"time"
+)
+
+// Neither /proc nor a known sysctl is available; memory reads as zero and
+// load and uptime as unavailable
+const (
+	defaultMeminfoPath = ""
+	defaultLoadAvgPath = ""
+	defaultUptimePath  = ""
+)
+
+func platformMemory() (used, total uint64, err error) {
+	return 0, 0, errors.ErrUnsupported
+}
+
+func platformLoadAvg() (avg1, avg5, avg15 float64, err error) {
+	return 0, 0, 0, errors.ErrUnsupported
+}
+
+func platformUptime() (time.Duration, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -1,23 +1,60 @@
 package stats
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ausil/i2c-display/internal/config"
 )
 
+// defaultCollectTimeout bounds a source read when pages.collect_timeout is
+// unset
+const defaultCollectTimeout = 5 * time.Second
+
 // SystemCollector collects all system statistics
 type SystemCollector struct {
-	config        *config.Config
-	cpuCollector  *CPUTempCollector
-	memCollector  *MemoryCollector
-	diskCollector *DiskCollector
-	netCollector  *NetworkCollector
-	loadCollector *LoadAvgCollector
-	hostname      string
+	config          *config.Config
+	cpuCollector    *CPUTempCollector
+	memCollector    *MemoryCollector
+	diskCollector   *DiskCollector
+	netCollector    *NetworkCollector
+	loadCollector   *LoadAvgCollector
+	uptimeCollector *UptimeCollector
+	sensors         []namedTempCollector
+	throttle        *ThrottleCollector // nil off a Raspberry Pi
+	execRunners     []*execRunner
+	httpJSONRunners []*httpJSONRunner
+	connectivity    *connectivityRunner // nil when the check is disabled
+	latency         *latencyRunner      // nil when the monitor is disabled
+	updates         *updatesRunner      // nil when the update check is disabled
+	timeSync        *timeSyncRunner     // nil when the time sync check is disabled
+	procCollector   *ProcessCollector   // nil when the top pages are disabled
+	flashCollector  *FlashCollector     // nil when the storage page is disabled
+	hostname        string
+
+	// Staggered collection: each source is re-read only when its interval
+	// from pages.refresh_intervals has elapsed, otherwise the previous
+	// reading is reused
+	sources     []*source
+	timeout     time.Duration // bounds each read of a source
+	intervals   map[string]time.Duration
+	collectedAt map[string]time.Time
+	timings     map[string]time.Duration // read time of each source re-read by the last Collect
+	last        SystemStats
+	now         func() time.Time
+	mu          sync.Mutex
+}
+
+// namedTempCollector pairs a temperature collector with its display name
+type namedTempCollector struct {
+	name      string
+	collector *CPUTempCollector
 }
 
 // NewSystemCollector creates a new system collector
@@ -34,70 +71,411 @@ func NewSystemCollector(cfg *config.Config) (*SystemCollector, error) {
 		}
 	}
 
-	return &SystemCollector{
-		config:        cfg,
-		cpuCollector:  NewCPUTempCollector(cfg.SystemInfo.TemperatureSource),
-		memCollector:  NewMemoryCollector(),
-		diskCollector: NewDiskCollector(cfg.SystemInfo.DiskPath),
-		netCollector:  NewNetworkCollector(cfg.Network),
-		loadCollector: NewLoadAvgCollector(),
-		hostname:      hostname,
-	}, nil
+	// Intervals are validated at config load time
+	intervals, _ := cfg.Pages.GetSourceIntervals()
+	timeout := defaultCollectTimeout
+	if cfg.Pages.CollectTimeout != "" {
+		timeout, _ = time.ParseDuration(cfg.Pages.CollectTimeout)
+	}
+
+	sensors := make([]namedTempCollector, 0, len(cfg.SystemInfo.TemperatureSensors))
+	for _, sensor := range cfg.SystemInfo.TemperatureSensors {
+		sensors = append(sensors, namedTempCollector{
+			name:      sensor.Name,
+			collector: NewCPUTempCollector(sensor.Source),
+		})
+	}
+
+	execRunners := make([]*execRunner, 0, len(cfg.Pages.Exec))
+	for _, e := range cfg.Pages.Exec {
+		execRunners = append(execRunners, newExecRunner(e))
+	}
+	httpJSONRunners := make([]*httpJSONRunner, 0, len(cfg.Pages.HTTPJSON))
+	for _, h := range cfg.Pages.HTTPJSON {
+		httpJSONRunners = append(httpJSONRunners, newHTTPJSONRunner(h))
+	}
+
+	var connectivity *connectivityRunner
+	if cfg.Connectivity.Enabled {
+		connectivity = newConnectivityRunner(cfg.Connectivity)
+	}
+
+	var procCollector *ProcessCollector
+	if cfg.Pages.Top.Count > 0 {
+		procCollector = NewProcessCollector()
+	}
+
+	var flashCollector *FlashCollector
+	if cfg.Pages.Storage.Enabled {
+		flashCollector = NewFlashCollector()
+	}
+
+	var updates *updatesRunner
+	if cfg.Updates.Enabled {
+		updates = newUpdatesRunner(cfg.Updates)
+	}
+
+	var timeSync *timeSyncRunner
+	if cfg.TimeSync.Enabled {
+		timeSync = newTimeSyncRunner(cfg.TimeSync)
+	}
+
+	var latency *latencyRunner
+	if cfg.Latency.Enabled {
+		latency = newLatencyRunner(cfg.Latency)
+	}
+
+	sc := &SystemCollector{
+		config:          cfg,
+		cpuCollector:    NewCPUTempCollector(cfg.SystemInfo.TemperatureSource),
+		memCollector:    NewMemoryCollector(),
+		diskCollector:   NewDiskCollector(cfg.SystemInfo.DiskPath),
+		netCollector:    NewNetworkCollector(cfg.Network),
+		loadCollector:   NewLoadAvgCollector(),
+		uptimeCollector: NewUptimeCollector(),
+		sensors:         sensors,
+		throttle:        NewThrottleCollector(),
+		execRunners:     execRunners,
+		httpJSONRunners: httpJSONRunners,
+		connectivity:    connectivity,
+		latency:         latency,
+		updates:         updates,
+		timeSync:        timeSync,
+		procCollector:   procCollector,
+		flashCollector:  flashCollector,
+		hostname:        hostname,
+		intervals:       intervals,
+		timeout:         timeout,
+		collectedAt:     make(map[string]time.Time),
+		timings:         make(map[string]time.Duration),
+		now:             time.Now,
+	}
+	sc.sources = sc.newSources()
+	return sc, nil
 }
 
-// Collect gathers all system statistics
+// source is a data source read as a unit on its own interval. read returns
+// a function storing the reading in a snapshot, so the read itself can run
+// without holding the snapshot.
+type source struct {
+	name string
+	read func() (func(*SystemStats), error)
+	mu   sync.Mutex // serializes reads, for collectors that keep state between them
+}
+
+// get reads the source, giving up once ctx is done. A read that gives up
+// keeps running in the background, as a hung statfs cannot be interrupted;
+// until it returns, further reads fail at once rather than pile up behind it.
+func (src *source) get(ctx context.Context) (func(*SystemStats), error) {
+	if !src.mu.TryLock() {
+		return nil, fmt.Errorf("%s: previous read still running", src.name)
+	}
+
+	type result struct {
+		store func(*SystemStats)
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer src.mu.Unlock()
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("%s: panic: %v", src.name, r)}
+			}
+		}()
+		store, err := src.read()
+		done <- result{store, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.store, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s: read timed out", src.name)
+	}
+}
+
+// get reads src, bounded by the configured timeout
+func (sc *SystemCollector) get(src *source) (func(*SystemStats), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sc.timeout)
+	defer cancel()
+	return src.get(ctx)
+}
+
+// newSources returns the sources that pages.refresh_intervals can stagger,
+// in the order Collect reads them
+func (sc *SystemCollector) newSources() []*source {
+	sources := []*source{
+		{name: config.SourceTemperature, read: sc.readTemperature},
+		{name: config.SourceMemory, read: sc.readMemory},
+		{name: config.SourceDisk, read: sc.readDisk},
+		{name: config.SourceLoad, read: sc.readLoad},
+		{name: config.SourceNetwork, read: sc.readNetwork},
+	}
+	if sc.procCollector != nil {
+		sources = append(sources, &source{name: config.SourceProcesses, read: sc.readProcesses})
+	}
+	if sc.flashCollector != nil {
+		sources = append(sources, &source{name: config.SourceStorage, read: sc.readStorage})
+	}
+	return sources
+}
+
+// Collect gathers all system statistics. Sources with a refresh interval
+// are only re-read once it has elapsed; until then their last reading is
+// returned.
 func (sc *SystemCollector) Collect() (*SystemStats, error) {
-	stats := &SystemStats{
-		Hostname: sc.hostname,
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	now := sc.now()
+	stats := sc.last
+	clear(sc.timings)
+
+	for _, src := range sc.sources {
+		if !sc.due(src.name, now) {
+			continue
+		}
+		start := time.Now()
+		store, err := sc.get(src)
+		if err != nil {
+			return nil, err
+		}
+		store(&stats)
+		sc.collectedAt[src.name] = now
+		sc.timings[src.name] = time.Since(start)
 	}
 
-	// Collect CPU temperature
-	temp, err := sc.cpuCollector.GetTemperature()
-	if err != nil {
-		// Log warning but continue - temperature might not be available
-		stats.CPUTemp = 0
-	} else {
-		// Convert to configured unit
-		if sc.config.SystemInfo.TemperatureUnit == "fahrenheit" {
-			stats.CPUTemp = (temp * 9 / 5) + 32
-		} else {
-			stats.CPUTemp = temp
+	sc.last = stats
+	sc.pollBackground(&stats, now)
+	return &stats, nil
+}
+
+// readTemperature reads the CPU and named sensor temperatures. Sensors that
+// cannot be read are left out rather than failing the collection.
+func (sc *SystemCollector) readTemperature() (func(*SystemStats), error) {
+	cpuTemp := 0.0
+	if temp, err := sc.cpuCollector.GetTemperature(); err == nil {
+		cpuTemp = sc.convertTemp(temp)
+	}
+
+	var temperatures []TempReading
+	for _, sensor := range sc.sensors {
+		if temp, err := sensor.collector.GetTemperature(); err == nil {
+			temperatures = append(temperatures, TempReading{
+				Name:  sensor.name,
+				Value: sc.convertTemp(temp),
+			})
+		}
+	}
+
+	// The Pi's throttling flags follow its temperature
+	var throttled *ThrottleFlags
+	if sc.throttle != nil {
+		if flags, err := sc.throttle.GetThrottled(); err == nil {
+			throttled = &flags
 		}
 	}
 
-	// Collect memory stats
-	memUsed, memTotal, err := sc.memCollector.GetMemory()
+	return func(s *SystemStats) {
+		s.CPUTemp = cpuTemp
+		s.Temperatures = temperatures
+		s.Throttled = throttled
+	}, nil
+}
+
+// readMemory reads memory usage
+func (sc *SystemCollector) readMemory() (func(*SystemStats), error) {
+	used, total, err := sc.memCollector.GetMemory()
+	if errors.Is(err, errors.ErrUnsupported) {
+		// No way to read memory on this system; show it as zero
+		return func(s *SystemStats) {}, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get memory stats: %w", err)
 	}
-	stats.MemoryUsed = memUsed
-	stats.MemoryTotal = memTotal
+	return func(s *SystemStats) {
+		s.MemoryUsed, s.MemoryTotal = used, total
+	}, nil
+}
 
-	// Collect disk stats
-	diskUsed, diskTotal, err := sc.diskCollector.GetDisk()
+// readDisk reads usage of the configured disk
+func (sc *SystemCollector) readDisk() (func(*SystemStats), error) {
+	disk, err := sc.diskCollector.GetUsage()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get disk stats: %w", err)
 	}
-	stats.DiskUsed = diskUsed
-	stats.DiskTotal = diskTotal
+	return func(s *SystemStats) {
+		s.DiskUsed = disk.Used
+		s.DiskTotal = disk.Total
+		s.DiskInodesUsed = disk.InodesUsed
+		s.DiskInodesTotal = disk.InodesTotal
+		s.DiskReadOnly = disk.ReadOnly
+	}, nil
+}
 
-	// Collect load averages
+// readLoad reads the load averages, which are left zero when unavailable
+func (sc *SystemCollector) readLoad() (func(*SystemStats), error) {
 	avg1, avg5, avg15, err := sc.loadCollector.GetLoadAvg()
 	if err != nil {
-		// load average unavailable — leave as zero
-	} else {
-		stats.LoadAvg1 = avg1
-		stats.LoadAvg5 = avg5
-		stats.LoadAvg15 = avg15
+		avg1, avg5, avg15 = 0, 0, 0
 	}
-	stats.NumCPU = runtime.NumCPU()
+	return func(s *SystemStats) {
+		s.LoadAvg1, s.LoadAvg5, s.LoadAvg15 = avg1, avg5, avg15
+	}, nil
+}
 
-	// Collect network interfaces
+// readNetwork reads the network interfaces and name servers
+func (sc *SystemCollector) readNetwork() (func(*SystemStats), error) {
 	interfaces, err := sc.netCollector.GetInterfaces()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get network interfaces: %w", err)
 	}
-	stats.Interfaces = interfaces
+	dnsServers := sc.netCollector.GetDNSServers()
+	return func(s *SystemStats) {
+		s.Interfaces = interfaces
+		s.DNSServers = dnsServers
+	}, nil
+}
+
+// readProcesses scans processes for the top pages; a failed scan leaves the
+// previous lists
+func (sc *SystemCollector) readProcesses() (func(*SystemStats), error) {
+	byCPU, byMemory, err := sc.procCollector.GetTopProcesses(sc.config.Pages.Top.Count)
+	if err != nil {
+		return func(*SystemStats) {}, nil
+	}
+	return func(s *SystemStats) {
+		s.TopCPU, s.TopMemory = byCPU, byMemory
+	}, nil
+}
+
+// readStorage reads the flash wear estimates; a failed read leaves the
+// previous ones
+func (sc *SystemCollector) readStorage() (func(*SystemStats), error) {
+	flash, err := sc.flashCollector.GetFlash()
+	if err != nil {
+		return func(*SystemStats) {}, nil
+	}
+	return func(s *SystemStats) {
+		s.Flash = flash
+	}, nil
+}
+
+// pollBackground fills in what is cheap to read or already gathered in the
+// background: the hostname, CPU count and uptime, and the latest finished
+// output of the exec, http_json, connectivity, update, time sync and
+// latency runners
+func (sc *SystemCollector) pollBackground(stats *SystemStats, now time.Time) {
+	stats.Hostname = sc.hostname
+	stats.NumCPU = runtime.NumCPU()
+
+	// Uptime is cheap to read, so it is always fresh; 0 when unavailable
+	stats.Uptime, _ = sc.uptimeCollector.GetUptime()
+
+	// Exec commands and http_json fetches run in the background on their own
+	// intervals; only finished output is reported
+	stats.Exec = nil
+	if len(sc.execRunners) > 0 {
+		stats.Exec = make(map[string]ExecOutput, len(sc.execRunners))
+		for _, r := range sc.execRunners {
+			if out, ok := r.poll(now); ok {
+				stats.Exec[r.title] = out
+			}
+		}
+	}
+	stats.HTTPJSON = nil
+	if len(sc.httpJSONRunners) > 0 {
+		stats.HTTPJSON = make(map[string]ExecOutput, len(sc.httpJSONRunners))
+		for _, r := range sc.httpJSONRunners {
+			if out, ok := r.poll(now); ok {
+				stats.HTTPJSON[r.title] = out
+			}
+		}
+	}
+
+	// The reachability check and pings also run in the background
+	stats.Connectivity = nil
+	if sc.connectivity != nil {
+		if status, ok := sc.connectivity.poll(now); ok {
+			stats.Connectivity = &status
+		}
+	}
+
+	stats.Updates = nil
+	if sc.updates != nil {
+		if status, ok := sc.updates.poll(now); ok {
+			stats.Updates = &status
+		}
+	}
+
+	stats.TimeSync = nil
+	if sc.timeSync != nil {
+		if status, ok := sc.timeSync.poll(now); ok {
+			stats.TimeSync = &status
+		}
+	}
+
+	stats.Latency = nil
+	if sc.latency != nil {
+		stats.Latency, _ = sc.latency.poll(now)
+	}
+}
 
-	return stats, nil
+// LastTimings returns how long each source took to read during the last
+// Collect, keyed by config.Source* name. Sources served from the previous
+// reading are omitted.
+func (sc *SystemCollector) LastTimings() map[string]time.Duration {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	timings := make(map[string]time.Duration, len(sc.timings))
+	for source, d := range sc.timings {
+		timings[source] = d
+	}
+	return timings
+}
+
+// Invalidate discards the reading of source (a config.Source* name), so
+// the next Collect re-reads it whatever its refresh interval
+func (sc *SystemCollector) Invalidate(source string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	delete(sc.collectedAt, source)
+}
+
+// due reports whether source should be re-read at now
+func (sc *SystemCollector) due(source string, now time.Time) bool {
+	last, ok := sc.collectedAt[source]
+	return !ok || now.Sub(last) >= sc.intervals[source]
+}
+
+// convertTemp converts a Celsius reading to the configured unit
+func (sc *SystemCollector) convertTemp(celsius float64) float64 {
+	return FromCelsius(celsius, sc.config.SystemInfo.TemperatureUnit)
+}
+
+// FromCelsius converts a Celsius temperature to unit, one of
+// config.TemperatureUnits. Any other unit is taken to be Celsius.
+func FromCelsius(celsius float64, unit string) float64 {
+	switch unit {
+	case config.UnitFahrenheit:
+		return celsius*9/5 + 32
+	case config.UnitKelvin:
+		return celsius + 273.15
+	default:
+		return celsius
+	}
+}
+
+// ToCelsius converts a temperature in unit, one of config.TemperatureUnits,
+// to Celsius. Any other unit is taken to be Celsius.
+func ToCelsius(value float64, unit string) float64 {
+	switch unit {
+	case config.UnitFahrenheit:
+		return (value - 32) * 5 / 9
+	case config.UnitKelvin:
+		return value - 273.15
+	default:
+		return value
+	}
 }
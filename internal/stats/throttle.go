@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// defaultThrottledPath is where the Raspberry Pi firmware driver exposes the
+// flags `vcgencmd get_throttled` reports
+const defaultThrottledPath = "/sys/devices/platform/soc/soc:firmware/get_throttled"
+
+// ThrottleFlags are the Raspberry Pi firmware's throttling flags. The low
+// bits are conditions active now; the same bits shifted by ThrottleOccurred
+// record that they have happened since boot.
+type ThrottleFlags uint32
+
+// Throttling conditions
+const (
+	ThrottleUnderVoltage ThrottleFlags = 1 << 0 // supply voltage below 4.63V
+	ThrottleFreqCapped   ThrottleFlags = 1 << 1 // ARM frequency capped
+	ThrottleThrottled    ThrottleFlags = 1 << 2 // ARM frequency reduced
+	ThrottleSoftTemp     ThrottleFlags = 1 << 3 // soft temperature limit reached
+
+	ThrottleOccurred = 16 // shift from a condition to its "has occurred" bit
+)
+
+// Active reports whether the condition is active now
+func (f ThrottleFlags) Active(cond ThrottleFlags) bool {
+	return f&cond != 0
+}
+
+// Occurred reports whether the condition has happened since boot
+func (f ThrottleFlags) Occurred(cond ThrottleFlags) bool {
+	return f&(cond<<ThrottleOccurred) != 0
+}
+
+// ThrottleCollector reads the Raspberry Pi throttling flags, from sysfs when
+// the firmware driver provides them and from vcgencmd otherwise
+type ThrottleCollector struct {
+	path   string
+	runCmd func(name string, args ...string) ([]byte, error)
+}
+
+// NewThrottleCollector returns a collector for the throttling flags, or nil
+// when neither source exists, as on anything but a Raspberry Pi
+func NewThrottleCollector() *ThrottleCollector {
+	if _, err := os.Stat(defaultThrottledPath); err != nil {
+		if _, err := exec.LookPath("vcgencmd"); err != nil {
+			return nil
+		}
+	}
+	return NewThrottleCollectorWithPath(defaultThrottledPath)
+}
+
+// NewThrottleCollectorWithPath creates a collector reading from a custom
+// path (for testing)
+func NewThrottleCollectorWithPath(path string) *ThrottleCollector {
+	return &ThrottleCollector{path: path, runCmd: runCommand}
+}
+
+// GetThrottled returns the current throttling flags
+func (c *ThrottleCollector) GetThrottled() (ThrottleFlags, error) {
+	// The driver prints the flags in hex, without a prefix
+	if data, err := os.ReadFile(c.path); err == nil {
+		return parseThrottled(strings.TrimSpace(string(data)))
+	}
+
+	out, err := c.runCmd("vcgencmd", "get_throttled")
+	if err != nil {
+		return 0, fmt.Errorf("failed to run vcgencmd: %w", err)
+	}
+	// e.g. "throttled=0x50005"
+	s := strings.TrimSpace(string(out))
+	if !strings.HasPrefix(s, "throttled=0x") {
+		return 0, fmt.Errorf("unexpected vcgencmd output: %q", s)
+	}
+	return parseThrottled(strings.TrimPrefix(s, "throttled=0x"))
+}
+
+// parseThrottled parses the flags as hex digits
+func parseThrottled(s string) (ThrottleFlags, error) {
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse throttled flags %q: %w", s, err)
+	}
+	return ThrottleFlags(v), nil
+}
@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestThrottleCollectorSysfs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "get_throttled")
+	if err := os.WriteFile(path, []byte("50005\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	flags, err := NewThrottleCollectorWithPath(path).GetThrottled()
+	if err != nil {
+		t.Fatalf("GetThrottled() failed: %v", err)
+	}
+	// Under-voltage and throttling now; both have occurred
+	if !flags.Active(ThrottleUnderVoltage) || !flags.Active(ThrottleThrottled) {
+		t.Errorf("expected under-voltage and throttling active in %#x", flags)
+	}
+	if flags.Active(ThrottleFreqCapped) || flags.Active(ThrottleSoftTemp) {
+		t.Errorf("expected no frequency cap or soft limit active in %#x", flags)
+	}
+	if !flags.Occurred(ThrottleUnderVoltage) || !flags.Occurred(ThrottleThrottled) || flags.Occurred(ThrottleFreqCapped) {
+		t.Errorf("unexpected occurred flags in %#x", flags)
+	}
+}
+
+func TestThrottleCollectorVcgencmd(t *testing.T) {
+	c := NewThrottleCollectorWithPath("/nonexistent/get_throttled")
+
+	c.runCmd = func(name string, args ...string) ([]byte, error) {
+		if name != "vcgencmd" || len(args) != 1 || args[0] != "get_throttled" {
+			t.Errorf("unexpected command %s %v", name, args)
+		}
+		return []byte("throttled=0x80000\n"), nil
+	}
+	flags, err := c.GetThrottled()
+	if err != nil {
+		t.Fatalf("GetThrottled() failed: %v", err)
+	}
+	if flags.Active(ThrottleSoftTemp) || !flags.Occurred(ThrottleSoftTemp) {
+		t.Errorf("expected the soft temperature limit to have occurred only, got %#x", flags)
+	}
+
+	for _, out := range []string{"error=1 error_msg=\"Command not registered\"", "throttled=0xzz"} {
+		c.runCmd = func(string, ...string) ([]byte, error) { return []byte(out), nil }
+		if _, err := c.GetThrottled(); err == nil {
+			t.Errorf("expected error for output %q", out)
+		}
+	}
+
+	c.runCmd = func(string, ...string) ([]byte, error) { return nil, errors.New("not found") }
+	if _, err := c.GetThrottled(); err == nil {
+		t.Error("expected error when vcgencmd fails")
+	}
+}
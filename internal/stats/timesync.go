@@ -0,0 +1,126 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+// TimeSyncStatus is the result of the latest clock synchronisation check
+type TimeSyncStatus struct {
+	Synced bool
+	// Offset is how far the system clock is behind its time source,
+	// negative when ahead; only set when OffsetKnown
+	Offset      time.Duration
+	OffsetKnown bool
+	Source      string    // "chrony" or "timesyncd"; "" when the check failed
+	Err         error     // why the last check failed
+	At          time.Time // when the last check finished
+}
+
+// timeSyncRunner asks chrony, or systemd-timesyncd through timedatectl,
+// whether the clock is synchronised, in the background on its interval
+type timeSyncRunner struct {
+	timeout time.Duration
+	chronyc bool // chronyc is installed
+	command func(ctx context.Context, name string, args ...string) (out []byte, code int, err error)
+
+	runner[TimeSyncStatus]
+}
+
+// newTimeSyncRunner creates a runner for the time sync config. Durations are
+// validated at config load time.
+func newTimeSyncRunner(cfg config.TimeSyncConfig) *timeSyncRunner {
+	interval, _ := time.ParseDuration(cfg.Interval)
+	timeout, _ := time.ParseDuration(cfg.Timeout)
+	_, err := exec.LookPath("chronyc")
+	r := &timeSyncRunner{
+		timeout: timeout,
+		chronyc: err == nil,
+		command: runStatus,
+	}
+	r.runner = runner[TimeSyncStatus]{interval: interval, check: r.update}
+	return r
+}
+
+// update checks the synchronisation once
+func (r *timeSyncRunner) update(TimeSyncStatus) TimeSyncStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	status := r.sync(ctx)
+	status.At = time.Now()
+	return status
+}
+
+// sync asks chrony when it is installed and running, and timedatectl
+// otherwise
+func (r *timeSyncRunner) sync(ctx context.Context) TimeSyncStatus {
+	if r.chronyc {
+		out, code, err := r.command(ctx, "chronyc", "-c", "tracking")
+		if err == nil && code == 0 {
+			status, err := parseChronyTracking(out)
+			if err != nil {
+				return TimeSyncStatus{Err: err}
+			}
+			return status
+		}
+		// chronyd is not running; the system may use timesyncd instead
+	}
+
+	out, code, err := r.command(ctx, "timedatectl", "show", "--property=NTPSynchronized", "--value")
+	if err != nil {
+		return TimeSyncStatus{Err: err}
+	}
+	if code != 0 {
+		return TimeSyncStatus{Err: fmt.Errorf("timedatectl exited with status %d", code)}
+	}
+	status := TimeSyncStatus{Source: "timesyncd", Synced: strings.TrimSpace(string(out)) == "yes"}
+
+	// Only timesyncd reports an offset; other NTP clients leave it unknown
+	if out, code, err := r.command(ctx, "timedatectl", "timesync-status"); err == nil && code == 0 {
+		status.Offset, status.OffsetKnown = parseTimesyncOffset(out)
+	}
+	return status
+}
+
+// parseChronyTracking parses `chronyc -c tracking`: reference ID, name,
+// stratum, reference time, system time correction in seconds (positive when
+// the clock is slow), ..., and the leap status last
+func parseChronyTracking(out []byte) (TimeSyncStatus, error) {
+	fields := strings.Split(strings.TrimSpace(string(out)), ",")
+	if len(fields) < 14 {
+		return TimeSyncStatus{}, fmt.Errorf("unexpected chronyc output: %q", out)
+	}
+	correction, err := strconv.ParseFloat(fields[4], 64)
+	if err != nil {
+		return TimeSyncStatus{}, fmt.Errorf("unexpected chronyc offset %q: %w", fields[4], err)
+	}
+	return TimeSyncStatus{
+		Source:      "chrony",
+		Synced:      fields[len(fields)-1] != "Not synchronised",
+		Offset:      time.Duration(correction * float64(time.Second)),
+		OffsetKnown: true,
+	}, nil
+}
+
+// parseTimesyncOffset finds the "Offset: -306us" line of timedatectl
+// timesync-status, which is positive when the clock is behind the server
+func parseTimesyncOffset(out []byte) (time.Duration, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "Offset:")
+		if !ok {
+			continue
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		return d, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,106 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+const chronyTrackingOutput = "A9FEA97B,169.254.169.123,4,1717171717.123456789,-0.000031235,0.000012345,0.000456789,-12.345,0.001,0.023,0.000123,0.000456,64.3,Normal\n"
+
+const timesyncStatusOutput = `       Server: 192.168.1.1 (pool.ntp.org)
+Poll interval: 34min 8s (min: 32s; max 34min 8s)
+         Leap: normal
+      Version: 4
+      Stratum: 2
+    Reference: C0A80101
+    Precision: 1us (-24)
+Root distance: 23.112ms (max: 5s)
+       Offset: -306us
+        Delay: 1.209ms
+       Jitter: 211us
+ Packet count: 42
+    Frequency: +12.345ppm
+`
+
+// timeSyncRunnerFor returns a runner whose commands are answered by command
+func timeSyncRunnerFor(chronyc bool, command func(name string, args ...string) ([]byte, int, error)) *timeSyncRunner {
+	cfg := config.Default().TimeSync
+	cfg.Enabled = true
+	r := newTimeSyncRunner(cfg)
+	r.chronyc = chronyc
+	r.command = func(_ context.Context, name string, args ...string) ([]byte, int, error) {
+		return command(name, args...)
+	}
+	return r
+}
+
+func TestTimeSyncChrony(t *testing.T) {
+	r := timeSyncRunnerFor(true, func(name string, args ...string) ([]byte, int, error) {
+		if name != "chronyc" || strings.Join(args, " ") != "-c tracking" {
+			t.Errorf("unexpected command %s %v", name, args)
+		}
+		return []byte(chronyTrackingOutput), 0, nil
+	})
+
+	status := r.sync(context.Background())
+	if !status.Synced || status.Source != "chrony" || status.Err != nil {
+		t.Fatalf("expected chrony to be synchronised, got %+v", status)
+	}
+	if !status.OffsetKnown || status.Offset != -31235*time.Nanosecond {
+		t.Errorf("expected an offset of -31.235us, got %v", status.Offset)
+	}
+
+	unsynced := strings.Replace(chronyTrackingOutput, "Normal", "Not synchronised", 1)
+	if status, err := parseChronyTracking([]byte(unsynced)); err != nil || status.Synced {
+		t.Errorf("expected an unsynchronised clock, got %+v, %v", status, err)
+	}
+	if _, err := parseChronyTracking([]byte("506 Cannot talk to daemon\n")); err == nil {
+		t.Error("expected an error for unexpected output")
+	}
+}
+
+func TestTimeSyncTimedatectl(t *testing.T) {
+	synced := "yes\n"
+	r := timeSyncRunnerFor(true, func(name string, args ...string) ([]byte, int, error) {
+		switch strings.Join(append([]string{name}, args...), " ") {
+		case "chronyc -c tracking":
+			// Installed but chronyd is not running
+			return nil, 1, nil
+		case "timedatectl show --property=NTPSynchronized --value":
+			return []byte(synced), 0, nil
+		case "timedatectl timesync-status":
+			return []byte(timesyncStatusOutput), 0, nil
+		}
+		t.Errorf("unexpected command %s %v", name, args)
+		return nil, 0, nil
+	})
+
+	status := r.sync(context.Background())
+	if !status.Synced || status.Source != "timesyncd" || status.Err != nil {
+		t.Fatalf("expected timesyncd to be synchronised, got %+v", status)
+	}
+	if !status.OffsetKnown || status.Offset != -306*time.Microsecond {
+		t.Errorf("expected an offset of -306us, got %v", status.Offset)
+	}
+
+	synced = "no\n"
+	if status := r.sync(context.Background()); status.Synced {
+		t.Errorf("expected an unsynchronised clock, got %+v", status)
+	}
+}
+
+func TestTimeSyncFailure(t *testing.T) {
+	r := timeSyncRunnerFor(false, func(name string, _ ...string) ([]byte, int, error) {
+		return nil, 0, errors.New(name + ": executable file not found")
+	})
+
+	status := waitForRun(t, &r.runner, time.Now())
+	if status.Err == nil || status.Synced || status.At.IsZero() {
+		t.Errorf("expected the error, got %+v", status)
+	}
+}
@@ -0,0 +1,181 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+// defaultRebootRequiredPath is created by Debian and Ubuntu packages whose
+// update needs a reboot
+const defaultRebootRequiredPath = "/run/reboot-required"
+
+// UpdateStatus is the result of the latest pending package update check
+type UpdateStatus struct {
+	Count          int       // packages with an update pending
+	RebootRequired bool      // an installed update needs a reboot
+	Err            error     // why the last count failed; Count is then the previous one
+	At             time.Time // when the last check finished
+}
+
+// updatesRunner counts pending package updates in the background on its
+// interval, since asking the package manager can take a while. It never
+// refreshes the package lists itself; apt and dnf do that on their own
+// timers.
+type updatesRunner struct {
+	manager    string // "apt", "dnf" or "none"
+	timeout    time.Duration
+	rebootPath string
+	// command runs a program and returns its output and exit code; err is
+	// only set when it could not be run to completion
+	command func(ctx context.Context, name string, args ...string) (out []byte, code int, err error)
+
+	runner[UpdateStatus]
+}
+
+// newUpdatesRunner creates a runner for the updates config, detecting the
+// package manager when set to "auto". Durations are validated at config
+// load time.
+func newUpdatesRunner(cfg config.UpdatesConfig) *updatesRunner {
+	interval, _ := time.ParseDuration(cfg.Interval)
+	timeout, _ := time.ParseDuration(cfg.Timeout)
+	manager := cfg.Manager
+	if manager == "auto" {
+		manager = detectPackageManager()
+	}
+	r := &updatesRunner{
+		manager:    manager,
+		timeout:    timeout,
+		rebootPath: defaultRebootRequiredPath,
+		command:    runStatus,
+	}
+	r.runner = runner[UpdateStatus]{interval: interval, check: r.update}
+	return r
+}
+
+// detectPackageManager returns the first of apt and dnf installed, or "none"
+func detectPackageManager() string {
+	if _, err := exec.LookPath("apt-get"); err == nil {
+		return "apt"
+	}
+	if _, err := exec.LookPath("dnf"); err == nil {
+		return "dnf"
+	}
+	return "none"
+}
+
+// runStatus runs a command and returns its standard output and exit code
+func runStatus(ctx context.Context, name string, args ...string) ([]byte, int, error) {
+	cmd := exec.CommandContext(ctx, name, args...) // #nosec G204 -- fixed package manager commands
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	cmd.WaitDelay = execWaitDelay
+	out, err := cmd.Output()
+	if errors.Is(err, exec.ErrWaitDelay) && ctx.Err() == nil {
+		err = nil // exited successfully, leaving a child holding stdout
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		return out, exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, 0, nil
+}
+
+// update counts the updates and checks whether a reboot is needed,
+// keeping the previous count if counting fails
+func (r *updatesRunner) update(status UpdateStatus) UpdateStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	count, err := r.count(ctx)
+	status.At = time.Now()
+	status.Err = err
+	status.RebootRequired = r.rebootRequired(ctx)
+	if err == nil {
+		status.Count = count
+	}
+	return status
+}
+
+// count asks the package manager how many packages can be upgraded, from
+// its cached package lists
+func (r *updatesRunner) count(ctx context.Context) (int, error) {
+	switch r.manager {
+	case "apt":
+		out, code, err := r.command(ctx, "apt-get", "--simulate", "upgrade")
+		if err != nil {
+			return 0, err
+		}
+		if code != 0 {
+			return 0, fmt.Errorf("apt-get exited with status %d", code)
+		}
+		return countAptUpgrades(out), nil
+	case "dnf":
+		// Exit status 100 means updates are available
+		out, code, err := r.command(ctx, "dnf", "check-update", "--cacheonly", "--quiet")
+		if err != nil {
+			return 0, err
+		}
+		if code != 0 && code != 100 {
+			return 0, fmt.Errorf("dnf exited with status %d", code)
+		}
+		return countDnfUpdates(out), nil
+	default:
+		return 0, nil
+	}
+}
+
+// rebootRequired reports whether an installed update needs a reboot: the
+// Debian flag file, or on dnf systems needs-restarting's verdict
+func (r *updatesRunner) rebootRequired(ctx context.Context) bool {
+	if _, err := os.Stat(r.rebootPath); err == nil {
+		return true
+	}
+	if r.manager != "dnf" {
+		return false
+	}
+	// Exit status 1 means a reboot is needed
+	_, code, err := r.command(ctx, "dnf", "needs-restarting", "--reboothint")
+	return err == nil && code == 1
+}
+
+// countAptUpgrades counts the "Inst" lines of a simulated apt-get upgrade,
+// one per package upgraded
+func countAptUpgrades(out []byte) int {
+	n := 0
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "Inst ") {
+			n++
+		}
+	}
+	return n
+}
+
+// countDnfUpdates counts the package lines of dnf check-update: name.arch,
+// version and repository. Packages listed under "Obsoleting Packages" also
+// appear as updates, so the count stops there.
+func countDnfUpdates(out []byte) int {
+	n := 0
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "Obsoleting") {
+			break
+		}
+		if fields := strings.Fields(line); len(fields) == 3 && !strings.HasPrefix(line, " ") && strings.Contains(fields[0], ".") {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,120 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+)
+
+const aptSimulateOutput = `Reading package lists...
+Building dependency tree...
+Calculating upgrade...
+The following packages will be upgraded:
+  libc6 openssl
+2 upgraded, 0 newly installed, 0 to remove and 0 not upgraded.
+Inst libc6 [2.36-9] (2.36-9+deb12u4 Debian-Security:12/stable-security [arm64])
+Inst openssl [3.0.11-1] (3.0.13-1 Debian-Security:12/stable-security [arm64])
+Conf libc6 (2.36-9+deb12u4 Debian-Security:12/stable-security [arm64])
+Conf openssl (3.0.13-1 Debian-Security:12/stable-security [arm64])
+`
+
+const dnfCheckUpdateOutput = `
+kernel.aarch64                 6.8.9-300.fc40            updates
+openssl-libs.aarch64           1:3.2.1-6.fc40            updates
+python3.aarch64                3.12.3-2.fc40             updates
+Obsoleting Packages
+grub2-tools.aarch64            1:2.06-120.fc40           updates
+    grub2-tools.aarch64        1:2.06-110.fc40           @anaconda
+`
+
+// updatesRunnerFor returns a runner for manager whose commands are answered
+// by command, with the reboot flag file at a temporary path
+func updatesRunnerFor(t *testing.T, manager string, command func(name string, args ...string) ([]byte, int, error)) *updatesRunner {
+	t.Helper()
+	cfg := config.Default().Updates
+	cfg.Enabled = true
+	cfg.Manager = manager
+	r := newUpdatesRunner(cfg)
+	r.rebootPath = filepath.Join(t.TempDir(), "reboot-required")
+	r.command = func(_ context.Context, name string, args ...string) ([]byte, int, error) {
+		return command(name, args...)
+	}
+	return r
+}
+
+func TestUpdatesRunnerApt(t *testing.T) {
+	r := updatesRunnerFor(t, "apt", func(name string, args ...string) ([]byte, int, error) {
+		if name != "apt-get" || strings.Join(args, " ") != "--simulate upgrade" {
+			t.Errorf("unexpected command %s %v", name, args)
+		}
+		return []byte(aptSimulateOutput), 0, nil
+	})
+
+	status := waitForRun(t, &r.runner, time.Now())
+	if status.Count != 2 || status.RebootRequired || status.Err != nil {
+		t.Errorf("expected 2 updates and no reboot, got %+v", status)
+	}
+
+	// An installed update asks for a reboot
+	if err := os.WriteFile(r.rebootPath, []byte("*** System restart required ***\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r.started = time.Time{}
+	if status := waitForRun(t, &r.runner, time.Now()); !status.RebootRequired {
+		t.Errorf("expected a reboot to be required, got %+v", status)
+	}
+}
+
+func TestUpdatesRunnerDnf(t *testing.T) {
+	r := updatesRunnerFor(t, "dnf", func(name string, args ...string) ([]byte, int, error) {
+		switch strings.Join(args, " ") {
+		case "check-update --cacheonly --quiet":
+			return []byte(dnfCheckUpdateOutput), 100, nil
+		case "needs-restarting --reboothint":
+			return nil, 1, nil
+		}
+		t.Errorf("unexpected command %s %v", name, args)
+		return nil, 0, nil
+	})
+
+	status := waitForRun(t, &r.runner, time.Now())
+	if status.Count != 3 || !status.RebootRequired || status.Err != nil {
+		t.Errorf("expected 3 updates and a reboot, got %+v", status)
+	}
+}
+
+func TestUpdatesRunnerFailureKeepsCount(t *testing.T) {
+	fail := false
+	r := updatesRunnerFor(t, "apt", func(string, ...string) ([]byte, int, error) {
+		if fail {
+			return nil, 0, errors.New("apt-get: executable file not found")
+		}
+		return []byte(aptSimulateOutput), 0, nil
+	})
+	if status := waitForRun(t, &r.runner, time.Now()); status.Count != 2 {
+		t.Fatalf("expected 2 updates, got %+v", status)
+	}
+
+	fail = true
+	r.started = time.Time{}
+	status := waitForRun(t, &r.runner, time.Now())
+	if status.Err == nil || status.Count != 2 {
+		t.Errorf("expected the error with the previous count, got %+v", status)
+	}
+}
+
+func TestUpdatesRunnerNone(t *testing.T) {
+	r := updatesRunnerFor(t, "none", func(name string, args ...string) ([]byte, int, error) {
+		t.Errorf("unexpected command %s %v", name, args)
+		return nil, 0, nil
+	})
+	if status := waitForRun(t, &r.runner, time.Now()); status.Count != 0 || status.RebootRequired || status.Err != nil {
+		t.Errorf("expected nothing pending, got %+v", status)
+	}
+}
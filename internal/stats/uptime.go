@@ -0,0 +1,47 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UptimeCollector reads how long the system has been running
+type UptimeCollector struct {
+	path string
+}
+
+// NewUptimeCollector creates a new uptime collector
+func NewUptimeCollector() *UptimeCollector {
+	return &UptimeCollector{path: defaultUptimePath}
+}
+
+// NewUptimeCollectorWithPath creates a collector reading from a custom path (for testing)
+func NewUptimeCollectorWithPath(path string) *UptimeCollector {
+	return &UptimeCollector{path: path}
+}
+
+// GetUptime reads /proc/uptime and returns the time since boot. Systems
+// without /proc are asked through sysctl.
+func (c *UptimeCollector) GetUptime() (time.Duration, error) {
+	if c.path == "" {
+		return platformUptime()
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read uptime from %s: %w", c.path, err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 1 {
+		return 0, fmt.Errorf("unexpected uptime format: %q", string(data))
+	}
+
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse uptime %q: %w", fields[0], err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,37 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUptimeCollector(t *testing.T) {
+	collector := NewUptimeCollectorWithPath("../../testdata/proc/uptime")
+
+	uptime, err := collector.GetUptime()
+	if err != nil {
+		t.Fatalf("GetUptime() failed: %v", err)
+	}
+	if want := 3725410 * time.Millisecond; uptime != want {
+		t.Errorf("expected %v, got %v", want, uptime)
+	}
+}
+
+func TestUptimeCollectorMalformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uptime")
+
+	for _, content := range []string{"\n", "abc 14512.77\n"} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		if _, err := NewUptimeCollectorWithPath(path).GetUptime(); err == nil {
+			t.Errorf("expected error for malformed input %q", content)
+		}
+	}
+
+	if _, err := NewUptimeCollectorWithPath("/nonexistent/uptime").GetUptime(); err == nil {
+		t.Error("expected error for nonexistent path")
+	}
+}
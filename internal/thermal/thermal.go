@@ -0,0 +1,112 @@
+package thermal
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+// Monitor watches CPU temperature samples and, once the critical threshold
+// has been exceeded for the configured number of consecutive samples, starts
+// a countdown after which the configured command (typically an orderly
+// shutdown) is executed exactly once. The countdown is aborted if the
+// temperature drops back below the threshold before it expires.
+type Monitor struct {
+	threshold float64
+	samples   int
+	countdown time.Duration
+	command   []string
+	log       *logger.Logger
+
+	mu        sync.Mutex
+	over      int       // consecutive samples at or above threshold
+	deadline  time.Time // zero when no countdown is running
+	triggered bool
+
+	now     func() time.Time
+	runFunc func(argv []string) error
+}
+
+// NewMonitor creates a thermal shutdown monitor from config.
+// Config must already be validated.
+func NewMonitor(cfg config.ThermalConfig, log *logger.Logger) *Monitor {
+	countdown, _ := time.ParseDuration(cfg.Countdown)
+	return &Monitor{
+		threshold: cfg.Threshold,
+		samples:   cfg.Samples,
+		countdown: countdown,
+		command:   cfg.Command,
+		log:       log,
+		now:       time.Now,
+		runFunc:   runCommand,
+	}
+}
+
+// Threshold returns the critical temperature
+func (m *Monitor) Threshold() float64 {
+	return m.threshold
+}
+
+// Observe records a temperature sample. It reports whether the shutdown
+// countdown is active and how long remains before the command runs. Once the
+// command has been triggered the countdown stays active with zero remaining.
+func (m *Monitor) Observe(temp float64) (remaining time.Duration, active bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.triggered {
+		return 0, true
+	}
+
+	if temp < m.threshold {
+		if !m.deadline.IsZero() {
+			m.log.With().Float64("temp", temp).Logger().Warn("Temperature recovered, thermal shutdown aborted")
+		}
+		m.over = 0
+		m.deadline = time.Time{}
+		return 0, false
+	}
+
+	m.over++
+	if m.over < m.samples {
+		return 0, false
+	}
+
+	now := m.now()
+	if m.deadline.IsZero() {
+		m.deadline = now.Add(m.countdown)
+		m.log.With().Float64("temp", temp).Float64("threshold", m.threshold).
+			Str("countdown", m.countdown.String()).Logger().Error("Critical temperature, thermal shutdown countdown started")
+	}
+
+	remaining = m.deadline.Sub(now)
+	if remaining <= 0 {
+		m.triggered = true
+		m.log.With().Str("command", strings.Join(m.command, " ")).Logger().Error("Executing thermal shutdown command")
+		go func() {
+			if err := m.runFunc(m.command); err != nil {
+				m.log.ErrorWithErr(err, "Thermal shutdown command failed")
+			}
+		}()
+		return 0, true
+	}
+	return remaining, true
+}
+
+// runCommand executes argv with a bounded timeout
+func runCommand(argv []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...) // #nosec G204 -- command comes from trusted config
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package thermal
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/config"
+	"github.com/ausil/i2c-display/internal/logger"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	calls [][]string
+	done  chan struct{}
+}
+
+func (r *recorder) run(argv []string) error {
+	r.mu.Lock()
+	r.calls = append(r.calls, argv)
+	r.mu.Unlock()
+	r.done <- struct{}{}
+	return nil
+}
+
+func newTestMonitor(t *testing.T) (*Monitor, *recorder, *time.Time) {
+	t.Helper()
+	m := NewMonitor(config.ThermalConfig{
+		Enabled:   true,
+		Threshold: 85,
+		Samples:   3,
+		Countdown: "30s",
+		Command:   []string{"systemctl", "poweroff"},
+	}, logger.NewDefault())
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rec := &recorder{done: make(chan struct{}, 1)}
+	m.now = func() time.Time { return now }
+	m.runFunc = rec.run
+	return m, rec, &now
+}
+
+func TestMonitorRequiresConsecutiveSamples(t *testing.T) {
+	m, _, _ := newTestMonitor(t)
+
+	for i := 0; i < 2; i++ {
+		if _, active := m.Observe(90); active {
+			t.Fatalf("countdown started after %d samples", i+1)
+		}
+	}
+	// A cool sample resets the streak
+	m.Observe(70)
+	for i := 0; i < 2; i++ {
+		if _, active := m.Observe(90); active {
+			t.Fatal("countdown started without enough consecutive samples")
+		}
+	}
+
+	remaining, active := m.Observe(90)
+	if !active {
+		t.Fatal("expected countdown to start after 3 consecutive samples")
+	}
+	if remaining != 30*time.Second {
+		t.Errorf("expected 30s remaining, got %v", remaining)
+	}
+}
+
+func TestMonitorAbortsOnRecovery(t *testing.T) {
+	m, rec, now := newTestMonitor(t)
+
+	for i := 0; i < 3; i++ {
+		m.Observe(90)
+	}
+	*now = now.Add(10 * time.Second)
+	if _, active := m.Observe(80); active {
+		t.Error("expected countdown to abort when temperature recovers")
+	}
+
+	*now = now.Add(time.Minute)
+	m.Observe(90)
+	if len(rec.calls) != 0 {
+		t.Errorf("expected no command execution, got %v", rec.calls)
+	}
+}
+
+func TestMonitorExecutesOnce(t *testing.T) {
+	m, rec, now := newTestMonitor(t)
+
+	for i := 0; i < 3; i++ {
+		m.Observe(90)
+	}
+	*now = now.Add(30 * time.Second)
+	if remaining, active := m.Observe(90); !active || remaining != 0 {
+		t.Fatalf("expected triggered state, got remaining=%v active=%v", remaining, active)
+	}
+
+	select {
+	case <-rec.done:
+	case <-time.After(time.Second):
+		t.Fatal("command was not executed")
+	}
+
+	// Further samples, even cool ones, never re-run or abort
+	m.Observe(90)
+	if _, active := m.Observe(50); !active {
+		t.Error("expected monitor to stay triggered")
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.calls) != 1 || rec.calls[0][1] != "poweroff" {
+		t.Errorf("expected a single poweroff call, got %v", rec.calls)
+	}
+}
@@ -0,0 +1,135 @@
+package timeseries
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// History files start with fileMagic and a format version, followed by a
+// little-endian uint16 metric count. Each metric is its name, prefixed by a
+// byte holding its length, then a uint32 sample count and that many samples,
+// oldest first, each an int64 Unix time in nanoseconds and a float32 value.
+// Version 1 files held only the load average and are not read.
+const (
+	fileMagic   = "I2CH"
+	fileVersion = 2
+	fileHeader  = len(fileMagic) + 1 + 2
+	sampleSize  = 8 + 4
+)
+
+// MarshalBinary encodes the store's samples
+func (s *Store) MarshalBinary() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data := make([]byte, fileHeader)
+	copy(data, fileMagic)
+	data[len(fileMagic)] = fileVersion
+	count := 0
+	for _, name := range s.metricNames() {
+		if len(name) > math.MaxUint8 {
+			return nil, fmt.Errorf("metric name %.16q... too long", name)
+		}
+		samples := s.series[name].samples()
+		data = append(data, byte(len(name)))
+		data = append(data, name...)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(samples))) // #nosec G115 -- at most the capacity
+		for _, sample := range samples {
+			data = binary.LittleEndian.AppendUint64(data, uint64(sample.Time.UnixNano())) // #nosec G115 -- round-tripped as int64
+			data = binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(sample.Value)))
+		}
+		count++
+	}
+	binary.LittleEndian.PutUint16(data[len(fileMagic)+1:], uint16(count)) // #nosec G115 -- a handful of metrics
+	return data, nil
+}
+
+// UnmarshalBinary replaces the store's samples with ones encoded by
+// MarshalBinary, keeping the newest of each metric when there are more than
+// fit
+func (s *Store) UnmarshalBinary(data []byte) error {
+	if len(data) < fileHeader || string(data[:len(fileMagic)]) != fileMagic {
+		return errors.New("not a history file")
+	}
+	if v := data[len(fileMagic)]; v != fileVersion {
+		return fmt.Errorf("unsupported history version %d", v)
+	}
+	n := int(binary.LittleEndian.Uint16(data[len(fileMagic)+1:]))
+	body := data[fileHeader:]
+
+	series := make(map[string][]Sample, n)
+	for range n {
+		if len(body) < 1 || len(body) < 1+int(body[0])+4 {
+			return errors.New("history truncated")
+		}
+		name := string(body[1 : 1+body[0]])
+		body = body[1+len(name):]
+		// Checked in uint64 so a corrupt count cannot overflow int on 32-bit
+		count := uint64(binary.LittleEndian.Uint32(body))
+		body = body[4:]
+		if count > uint64(len(body))/sampleSize {
+			return fmt.Errorf("history of %s truncated: %d of %d samples", name, len(body)/sampleSize, count)
+		}
+		samples := make([]Sample, count)
+		for i := range samples {
+			samples[i] = Sample{
+				Time:  time.Unix(0, int64(binary.LittleEndian.Uint64(body))), // #nosec G115 -- written from an int64
+				Value: float64(math.Float32frombits(binary.LittleEndian.Uint32(body[8:]))),
+			}
+			body = body[sampleSize:]
+		}
+		series[name] = samples
+	}
+	if len(body) != 0 {
+		return fmt.Errorf("history has %d trailing bytes", len(body))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.series)
+	for name, samples := range series {
+		for _, sample := range samples {
+			s.add(name, sample)
+		}
+	}
+	return nil
+}
+
+// Save writes the store's samples to path, replacing the file atomically so
+// a crash cannot leave it half written
+func (s *Store) Save(path string) error {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load restores the samples saved by Save, so graphs resume where they left
+// off. A missing file, as on the first run, leaves the store empty.
+func (s *Store) Load(path string) error {
+	data, err := os.ReadFile(path) // #nosec G304 -- history path from trusted config
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.UnmarshalBinary(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+// Package timeseries keeps recent samples of the system metrics for graphs
+// and the HTTP API, in a ring buffer per metric bounded by a sample count
+// and an age.
+package timeseries
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+// Metrics recorded from the system stats, named as alert rules name them
+const (
+	MetricLoad    = "load"     // 1-minute load average
+	MetricCPUTemp = "cpu_temp" // CPU temperature in system_info.temperature_unit
+	MetricMemory  = "memory"   // percent of memory used
+	MetricDisk    = "disk"     // percent of disk space used
+)
+
+// Sample is a metric's value at a point in time
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Store holds the recent samples of each metric, oldest first. It is safe
+// for concurrent use.
+type Store struct {
+	capacity  int           // samples kept per metric
+	retention time.Duration // age beyond which samples are dropped; 0 keeps them
+
+	mu     sync.RWMutex
+	series map[string]*ring
+}
+
+// New creates a store keeping up to capacity samples per metric, none older
+// than retention before the newest. A retention of 0 keeps samples until
+// they are pushed out by newer ones.
+func New(capacity int, retention time.Duration) *Store {
+	return &Store{
+		capacity:  max(capacity, 1),
+		retention: retention,
+		series:    make(map[string]*ring),
+	}
+}
+
+// Add appends a sample of metric taken at t, dropping the oldest once the
+// metric is full or its samples are older than the retention
+func (s *Store) Add(metric string, t time.Time, value float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(metric, Sample{Time: t, Value: value})
+}
+
+// add appends a sample. Must be called with s.mu held.
+func (s *Store) add(metric string, sample Sample) {
+	r, ok := s.series[metric]
+	if !ok {
+		r = &ring{buf: make([]Sample, s.capacity)}
+		s.series[metric] = r
+	}
+	r.push(sample)
+	if s.retention > 0 {
+		r.dropBefore(sample.Time.Add(-s.retention))
+	}
+}
+
+// Record adds a sample of each metric in the stats taken at t. Readings that
+// failed, such as a missing temperature sensor, are left out.
+func (s *Store) Record(t time.Time, st *stats.SystemStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(MetricLoad, Sample{t, st.LoadAvg1})
+	if st.CPUTemp > 0 {
+		s.add(MetricCPUTemp, Sample{t, st.CPUTemp})
+	}
+	if st.MemoryTotal > 0 {
+		s.add(MetricMemory, Sample{t, st.MemoryPercent()})
+	}
+	if st.DiskTotal > 0 {
+		s.add(MetricDisk, Sample{t, st.DiskPercent()})
+	}
+}
+
+// Samples returns a copy of a metric's samples, oldest first, or nil when
+// it has none
+func (s *Store) Samples(metric string) []Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.series[metric]
+	if !ok {
+		return nil
+	}
+	return r.samples()
+}
+
+// Values returns a metric's values, oldest first, without their times
+func (s *Store) Values(metric string) []float64 {
+	samples := s.Samples(metric)
+	values := make([]float64, len(samples))
+	for i, sample := range samples {
+		values[i] = sample.Value
+	}
+	return values
+}
+
+// Metrics returns the names of the metrics with samples, sorted
+func (s *Store) Metrics() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.metricNames()
+}
+
+// metricNames returns the sorted names of the metrics with samples. Must be
+// called with s.mu held.
+func (s *Store) metricNames() []string {
+	names := make([]string, 0, len(s.series))
+	for name, r := range s.series {
+		if r.n > 0 {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Downsample reduces samples to at most n by averaging runs of neighbours,
+// each taking the time of its newest sample. Fewer than n samples are
+// returned as they are.
+func Downsample(samples []Sample, n int) []Sample {
+	if n <= 0 || len(samples) <= n {
+		return samples
+	}
+	out := make([]Sample, n)
+	per := float64(len(samples)) / float64(n)
+	for i := range out {
+		start := int(float64(i) * per)
+		end := min(int(float64(i+1)*per), len(samples))
+		if start >= end {
+			out[i] = samples[min(start, len(samples)-1)]
+			continue
+		}
+		sum := 0.0
+		for _, sample := range samples[start:end] {
+			sum += sample.Value
+		}
+		out[i] = Sample{Time: samples[end-1].Time, Value: sum / float64(end-start)}
+	}
+	return out
+}
+
+// ring is a fixed-size buffer of samples, overwriting the oldest when full
+type ring struct {
+	buf   []Sample
+	start int // index of the oldest sample
+	n     int // samples held
+}
+
+// push appends a sample, overwriting the oldest when full
+func (r *ring) push(s Sample) {
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = s
+		r.n++
+		return
+	}
+	r.buf[r.start] = s
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// dropBefore removes the samples older than t
+func (r *ring) dropBefore(t time.Time) {
+	for r.n > 0 && r.buf[r.start].Time.Before(t) {
+		r.start = (r.start + 1) % len(r.buf)
+		r.n--
+	}
+}
+
+// samples returns a copy of the samples, oldest first
+func (r *ring) samples() []Sample {
+	out := make([]Sample, r.n)
+	for i := range out {
+		out[i] = r.buf[(r.start+i)%len(r.buf)]
+	}
+	return out
+}
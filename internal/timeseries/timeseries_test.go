@@ -0,0 +1,129 @@
+package timeseries
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ausil/i2c-display/internal/stats"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestStoreCapacity(t *testing.T) {
+	s := New(3, 0)
+	for i := range 5 {
+		s.Add(MetricLoad, epoch.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	if got := s.Values(MetricLoad); !slices.Equal(got, []float64{2, 3, 4}) {
+		t.Errorf("Values() = %v, want the newest three oldest first", got)
+	}
+	if got := s.Values(MetricDisk); len(got) != 0 {
+		t.Errorf("expected no samples of an unrecorded metric, got %v", got)
+	}
+}
+
+func TestStoreRetention(t *testing.T) {
+	s := New(100, 10*time.Second)
+	for _, sec := range []int{0, 5, 9, 12, 18} {
+		s.Add(MetricLoad, epoch.Add(time.Duration(sec)*time.Second), float64(sec))
+	}
+	if got := s.Values(MetricLoad); !slices.Equal(got, []float64{9, 12, 18}) {
+		t.Errorf("Values() = %v, want the samples of the last 10s", got)
+	}
+
+	// A sample after a long gap, as after a restart, drops the stale ones
+	s.Add(MetricLoad, epoch.Add(time.Hour), 1)
+	if got := s.Values(MetricLoad); !slices.Equal(got, []float64{1}) {
+		t.Errorf("Values() after a gap = %v, want [1]", got)
+	}
+}
+
+func TestStoreRecord(t *testing.T) {
+	s := New(10, 0)
+	s.Record(epoch, &stats.SystemStats{
+		LoadAvg1:    1.5,
+		MemoryUsed:  1 << 30,
+		MemoryTotal: 4 << 30,
+	})
+	if got := s.Metrics(); !slices.Equal(got, []string{MetricLoad, MetricMemory}) {
+		t.Errorf("Metrics() = %v, want load and memory only", got)
+	}
+	samples := s.Samples(MetricMemory)
+	if len(samples) != 1 || samples[0].Value != 25 || !samples[0].Time.Equal(epoch) {
+		t.Errorf("memory samples = %v, want 25%% at the epoch", samples)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	samples := make([]Sample, 6)
+	for i := range samples {
+		samples[i] = Sample{Time: epoch.Add(time.Duration(i) * time.Second), Value: float64(i)}
+	}
+
+	got := Downsample(samples, 3)
+	want := []Sample{
+		{epoch.Add(time.Second), 0.5},
+		{epoch.Add(3 * time.Second), 2.5},
+		{epoch.Add(5 * time.Second), 4.5},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Downsample(6 to 3) = %v, want %v", got, want)
+	}
+	if got := Downsample(samples, 10); len(got) != len(samples) {
+		t.Errorf("expected fewer samples than points to be kept, got %d", len(got))
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "history.bin")
+	before := New(10, 0)
+	for i, load := range []float64{0.25, 0.5, 1.5} {
+		before.Add(MetricLoad, epoch.Add(time.Duration(i)*time.Second), load)
+	}
+	before.Add(MetricCPUTemp, epoch, 48)
+	if err := before.Save(path); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	after := New(2, 0)
+	if err := after.Load(path); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if got := after.Values(MetricLoad); !slices.Equal(got, []float64{0.5, 1.5}) {
+		t.Errorf("restored load = %v, want the newest two that fit", got)
+	}
+	if got := after.Samples(MetricCPUTemp); len(got) != 1 || got[0].Value != 48 || !got[0].Time.Equal(epoch) {
+		t.Errorf("restored cpu_temp = %v, want 48 at the epoch", got)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	s := New(10, 0)
+
+	if err := s.Load(filepath.Join(dir, "missing.bin")); err != nil {
+		t.Errorf("expected a missing file to leave the store empty, got %v", err)
+	}
+
+	for name, data := range map[string][]byte{
+		"foreign":    []byte("not a history file"),
+		"version 1":  {'I', '2', 'C', 'H', 1, 0, 0},
+		"truncated":  {'I', '2', 'C', 'H', fileVersion, 1, 0, 4, 'l', 'o', 'a', 'd', 2, 0, 0, 0},
+		"trailing":   {'I', '2', 'C', 'H', fileVersion, 0, 0, 0},
+		"huge count": {'I', '2', 'C', 'H', fileVersion, 1, 0, 4, 'l', 'o', 'a', 'd', 0xff, 0xff, 0xff, 0xff},
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Load(path); err == nil {
+			t.Errorf("expected an error loading a %s file", name)
+		}
+	}
+	if got := s.Metrics(); len(got) != 0 {
+		t.Errorf("expected a bad file to leave the store empty, got %v", got)
+	}
+}
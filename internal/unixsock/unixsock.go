@@ -0,0 +1,119 @@
+// Package unixsock creates the daemon's Unix sockets. A socket is only
+// reachable by its owner and group from the moment it appears, and an
+// existing path is only replaced when it is a socket nobody is listening on.
+package unixsock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// dialTimeout bounds the check for a daemon already listening on the path
+const dialTimeout = time.Second
+
+// Listen creates a Unix socket at path, owned by group when set and mode
+// 0660. The socket is set up in a private directory and renamed into
+// place, so it is never reachable with looser permissions. A stale socket
+// left by a previous run is replaced; a socket another process is still
+// listening on, or a path that is not a socket, is refused. Closing the
+// listener leaves the socket in place; call Remove once it is closed.
+func Listen(path, group string) (net.Listener, error) {
+	if err := checkStale(path); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sock-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	tmp := filepath.Join(dir, "s")
+	ln, err := (&net.ListenConfig{}).Listen(context.Background(), "unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := setPermissions(tmp, group); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("failed to move socket into place: %w", err)
+	}
+	return ln, nil
+}
+
+// Remove removes the socket at path, if it is still one
+func Remove(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("not removing %s: not a socket", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// checkStale removes a socket left at path by a process that is gone, and
+// refuses to touch anything else
+func checkStale(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("refusing to replace %s: not a socket", path)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	if conn, err := (&net.Dialer{}).DialContext(ctx, "unix", path); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	return nil
+}
+
+// setPermissions restricts a socket to its owner and group, giving it to
+// group when set
+func setPermissions(path, group string) error {
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return fmt.Errorf("failed to look up socket group: %w", err)
+		}
+		gid, err := strconv.Atoi(g.Gid)
+		if err != nil {
+			return fmt.Errorf("invalid gid %q for group %s", g.Gid, group)
+		}
+		if err := os.Chown(path, -1, gid); err != nil {
+			return fmt.Errorf("failed to set socket group: %w", err)
+		}
+	}
+	if err := os.Chmod(path, 0o660); err != nil { // #nosec G302 -- group access is how other users reach the socket
+		return fmt.Errorf("failed to set socket mode: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package unixsock
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.sock")
+
+	ln, err := Listen(path, "")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatalf("socket not created: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != 0o660 {
+		t.Errorf("socket mode = %s, want a socket with mode 660", info.Mode())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected only the socket left in its directory, got %v", entries)
+	}
+
+	// Closing leaves the socket for Remove, so it is never removed twice
+	_ = ln.Close()
+	if _, err := os.Lstat(path); err != nil {
+		t.Errorf("expected the socket kept after Close, got %v", err)
+	}
+	if err := Remove(path); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the socket removed, got %v", err)
+	}
+	if err := Remove(path); err != nil {
+		t.Errorf("Remove() of a missing socket failed: %v", err)
+	}
+
+	// Files that are not sockets are left alone
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(path, ""); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Errorf("expected Listen to refuse a regular file, got %v", err)
+	}
+	if err := Remove(path); err == nil {
+		t.Error("expected Remove to refuse a regular file")
+	}
+}
@@ -0,0 +1,48 @@
+// Package config exposes the daemon configuration to programs embedding the
+// display, renderer and rotation packages. The JSON format is the same as
+// the daemon's config file; see the README for every option.
+package config
+
+import "github.com/ausil/i2c-display/internal/config"
+
+// Config is the complete configuration
+type Config = config.Config
+
+// DisplayConfig selects and configures the display hardware
+type DisplayConfig = config.DisplayConfig
+
+// PagesConfig controls rotation timing and which pages are shown
+type PagesConfig = config.PagesConfig
+
+// Page types, as used in pages.durations and pages.disabled and returned
+// by Renderer.PageType
+const (
+	PageSystem        = config.PageSystem
+	PageTemperatures  = config.PageTemperatures
+	PagePower         = config.PagePower
+	PageLoad          = config.PageLoad
+	PageNetwork       = config.PageNetwork
+	PageNetworkDetail = config.PageNetworkDetail
+	PageConnectivity  = config.PageConnectivity
+	PageLatency       = config.PageLatency
+	PageExec          = config.PageExec
+	PageHTTPJSON      = config.PageHTTPJSON
+	PageTemplate      = config.PageTemplate
+	PageQR            = config.PageQR
+	PageFirstBoot     = config.PageFirstBoot
+	PageTop           = config.PageTop
+	PageStorage       = config.PageStorage
+	PagePlugin        = config.PagePlugin
+	PageCustom        = config.PageCustom
+)
+
+// Default returns a configuration with default values
+func Default() *Config {
+	return config.Default()
+}
+
+// Load reads and validates a JSON config file, applying defaults for
+// anything it leaves out
+func Load(path string) (*Config, error) {
+	return config.Load(path)
+}
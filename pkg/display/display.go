@@ -0,0 +1,87 @@
+// Package display exposes the display drivers used by i2c-displayd, so other
+// Go programs can drive the same SSD1306, ST7735, SSD1680, UCTRONICS and HD44780
+// panels or draw into an in-memory display.
+package display
+
+import (
+	"image"
+
+	"github.com/ausil/i2c-display/internal/display"
+	"github.com/ausil/i2c-display/pkg/config"
+)
+
+// Display is the drawing interface every driver implements
+type Display = display.Display
+
+// ColorDisplay is implemented by displays that can draw in colour
+type ColorDisplay = display.ColorDisplay
+
+// Capabilities describes what a display can draw
+type Capabilities = display.Capabilities
+
+// TextDisplay is implemented by character displays, which show lines of
+// text instead of pixels
+type TextDisplay = display.TextDisplay
+
+// MockDisplay is an in-memory monochrome display that records its calls,
+// for tests
+type MockDisplay = display.MockDisplay
+
+// OffscreenDisplay is an in-memory colour display whose image can be read
+// back, for previews and screenshots
+type OffscreenDisplay = display.OffscreenDisplay
+
+// BufferFormat is the layout of the bytes a display's GetBuffer returns
+type BufferFormat = display.BufferFormat
+
+// Buffer formats
+const (
+	BufferFormatMonoPages = display.BufferFormatMonoPages
+	BufferFormatRGB565    = display.BufferFormatRGB565
+)
+
+// New creates the driver selected by cfg.Type (e.g. "ssd1306",
+// "st7735_160x80", "uctronics_colour"; see DISPLAY_TYPES.md). Call Init
+// before drawing.
+func New(cfg *config.DisplayConfig) (Display, error) {
+	return display.NewDisplay(cfg)
+}
+
+// NewMock creates an in-memory monochrome display
+func NewMock(width, height int) *MockDisplay {
+	return display.NewMockDisplay(width, height)
+}
+
+// NewOffscreen creates an in-memory colour display
+func NewOffscreen(width, height int) *OffscreenDisplay {
+	return display.NewOffscreenDisplay(width, height)
+}
+
+// AsColorDisplay returns d as a ColorDisplay, adapting monochrome displays
+// by thresholding colours to on/off pixels
+func AsColorDisplay(d Display) ColorDisplay {
+	return display.AsColorDisplay(d)
+}
+
+// DecodeBuffer converts a width x height display buffer in the given format
+// into an image
+func DecodeBuffer(buf []byte, format BufferFormat, width, height int) (*image.NRGBA, error) {
+	return display.DecodeBuffer(buf, format, width, height)
+}
+
+// Snapshot returns the contents of d's buffer as an image, for any driver
+func Snapshot(d Display) (*image.NRGBA, error) {
+	return display.Snapshot(d)
+}
+
+// Sleep blanks d and powers its panel down where the driver can, e.g.
+// before the host suspends. Call Init to wake it, then redraw.
+func Sleep(d Display) error {
+	return display.Sleep(d)
+}
+
+// WriteLines sets the text shown by the next Show on a character display.
+// It fails for pixel displays; check Capabilities().Text() first.
+func WriteLines(d Display, lines []string) error {
+	return display.WriteLines(d, lines)
+}
@@ -0,0 +1,131 @@
+// Package renderer exposes the page renderer used by i2c-displayd. Programs
+// embedding it can add their own pages to the rotation alongside the
+// built-in ones:
+//
+//	type uptimePage struct{}
+//
+//	func (uptimePage) Title() string { return "Uptime" }
+//
+//	func (uptimePage) Render(disp display.Display, s *stats.SystemStats) error {
+//		if err := disp.Clear(); err != nil {
+//			return err
+//		}
+//		if err := renderer.DrawTextCentered(disp, 20, uptime()); err != nil {
+//			return err
+//		}
+//		return disp.Show()
+//	}
+//
+//	rend := renderer.New(disp, cfg)
+//	rend.RegisterPage(uptimePage{})
+//	rend.BuildPages(initialStats)
+package renderer
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/ausil/i2c-display/internal/renderer"
+	"github.com/ausil/i2c-display/pkg/config"
+	"github.com/ausil/i2c-display/pkg/display"
+)
+
+// Page is a displayable page. Render draws the whole page, including
+// clearing the display first and calling Show at the end.
+type Page = renderer.Page
+
+// Renderer builds the rotation pages and draws them to a display
+type Renderer = renderer.Renderer
+
+// Layout holds the header, separator and content line positions for a
+// display size, so custom pages can match the built-in ones
+type Layout = renderer.Layout
+
+// Margins used by the built-in pages
+const (
+	MarginLeft  = renderer.MarginLeft
+	MarginRight = renderer.MarginRight
+)
+
+// Colours used by the built-in pages
+var (
+	ColorGreen  = renderer.ColorGreen
+	ColorYellow = renderer.ColorYellow
+	ColorRed    = renderer.ColorRed
+)
+
+// New creates a renderer drawing to disp
+func New(disp display.Display, cfg *config.Config) *Renderer {
+	return renderer.NewRenderer(disp, cfg)
+}
+
+// NewLayout creates the layout for a display with the given bounds and
+// configured line count (0=auto, 2=default, 4=compact)
+func NewLayout(bounds image.Rectangle, lines int) *Layout {
+	return renderer.NewLayout(bounds, lines)
+}
+
+// ParseColor parses a colour name (white, red, green, yellow, blue, cyan,
+// magenta, orange, black) or a "#rrggbb" hex value
+func ParseColor(s string) (color.NRGBA, error) {
+	return renderer.ParseColor(s)
+}
+
+// DrawTextCentered draws text in the standard 7x13 font, centred horizontally
+func DrawTextCentered(disp display.Display, y int, text string) error {
+	return renderer.DrawTextCentered(disp, y, text)
+}
+
+// DrawTextColorScaled draws coloured text at (x, y). A scale in (0, 1)
+// selects the compact 5x7 font, anything else the standard 7x13 font.
+func DrawTextColorScaled(disp display.Display, x, y int, text string, c color.Color, scale float64) error {
+	return renderer.DrawTextColorScaled(disp, x, y, text, c, scale)
+}
+
+// DrawTextCenteredColorScaled is DrawTextColorScaled centred horizontally
+func DrawTextCenteredColorScaled(disp display.Display, y int, text string, c color.Color, scale float64) error {
+	return renderer.DrawTextCenteredColorScaled(disp, y, text, c, scale)
+}
+
+// DrawLine draws the separator line used below page headers at y
+func DrawLine(disp display.Display, y int) error {
+	return renderer.DrawLine(disp, y)
+}
+
+// DrawBar draws a horizontal gauge at (x, y) filled in proportion to percent
+func DrawBar(disp display.Display, x, y, w, h int, percent float64, c color.Color) error {
+	return renderer.DrawBar(disp, x, y, w, h, percent, c)
+}
+
+// DrawArc draws part of a ring of radius r and the given thickness centred
+// on (cx, cy), from start to end in degrees clockwise from 12 o'clock
+func DrawArc(disp display.Display, cx, cy, r, thickness int, start, end float64, c color.Color) error {
+	return renderer.DrawArc(disp, cx, cy, r, thickness, start, end, c)
+}
+
+// DrawCircle draws a one pixel circle outline
+func DrawCircle(disp display.Display, cx, cy, r int, c color.Color) error {
+	return renderer.DrawCircle(disp, cx, cy, r, c)
+}
+
+// DrawGauge draws a 270° dial filled in proportion to percent
+func DrawGauge(disp display.Display, cx, cy, r int, percent float64, c color.Color) error {
+	return renderer.DrawGauge(disp, cx, cy, r, percent, c)
+}
+
+// MeasureText returns the width of text in the standard font
+func MeasureText(text string) int {
+	return renderer.MeasureText(text)
+}
+
+// TruncateText shortens text with "..." to fit maxWidth in the standard font
+func TruncateText(text string, maxWidth int) string {
+	return renderer.TruncateText(text, maxWidth)
+}
+
+// LoadFont sets the font drawing characters the built-in ASCII fonts lack:
+// "go" for the embedded Go Mono font or the path of a TrueType or OpenType
+// font. Call it before drawing anything.
+func LoadFont(spec string) error {
+	return renderer.LoadFont(spec)
+}
@@ -0,0 +1,55 @@
+package renderer_test
+
+import (
+	"testing"
+
+	"github.com/ausil/i2c-display/pkg/config"
+	"github.com/ausil/i2c-display/pkg/display"
+	"github.com/ausil/i2c-display/pkg/renderer"
+	"github.com/ausil/i2c-display/pkg/stats"
+)
+
+type bannerPage struct {
+	rendered int
+}
+
+func (p *bannerPage) Title() string { return "Banner" }
+
+func (p *bannerPage) Render(disp display.Display, s *stats.SystemStats) error {
+	p.rendered++
+	if err := disp.Clear(); err != nil {
+		return err
+	}
+	if err := renderer.DrawTextCentered(disp, 20, "hello "+s.Hostname); err != nil {
+		return err
+	}
+	return disp.Show()
+}
+
+func TestRegisterPage(t *testing.T) {
+	cfg := config.Default()
+	disp := display.NewMock(128, 64)
+	rend := renderer.New(disp, cfg)
+
+	page := &bannerPage{}
+	rend.RegisterPage(page)
+	rend.BuildPages(&stats.SystemStats{Hostname: "pi"})
+
+	last := rend.PageCount() - 1
+	if got := rend.PageType(last); got != config.PageCustom {
+		t.Fatalf("PageType(%d) = %q, want %q", last, got, config.PageCustom)
+	}
+	if err := rend.RenderPage(last, &stats.SystemStats{Hostname: "pi"}); err != nil {
+		t.Fatalf("RenderPage failed: %v", err)
+	}
+	if page.rendered != 1 {
+		t.Errorf("expected the registered page rendered once, got %d", page.rendered)
+	}
+
+	// Custom pages can be disabled like any other page type
+	cfg.Pages.Disabled = []string{config.PageCustom}
+	rend.BuildPages(&stats.SystemStats{Hostname: "pi"})
+	if got := rend.PageType(rend.PageCount() - 1); got == config.PageCustom {
+		t.Error("expected custom pages left out when disabled")
+	}
+}
@@ -0,0 +1,21 @@
+// Package rotation exposes the manager that drives page rotation, periodic
+// refresh, alerts and transient pages in i2c-displayd, for programs that
+// embed the renderer.
+package rotation
+
+import (
+	"github.com/ausil/i2c-display/internal/rotation"
+	"github.com/ausil/i2c-display/pkg/config"
+	"github.com/ausil/i2c-display/pkg/renderer"
+	"github.com/ausil/i2c-display/pkg/stats"
+)
+
+// Manager rotates and refreshes the renderer's pages. Start runs it until
+// the context is cancelled or Stop is called.
+type Manager = rotation.Manager
+
+// New creates a manager that collects stats from collector and draws with
+// rend, timed by cfg.Pages. Call rend.BuildPages once before Start.
+func New(cfg *config.Config, collector stats.Collector, rend *renderer.Renderer) *Manager {
+	return rotation.NewManager(cfg, collector, rend)
+}
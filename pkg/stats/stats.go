@@ -0,0 +1,41 @@
+// Package stats exposes the system statistics that pages render, and the
+// collector i2c-displayd uses to gather them.
+package stats
+
+import (
+	"github.com/ausil/i2c-display/internal/stats"
+	"github.com/ausil/i2c-display/pkg/config"
+)
+
+// SystemStats is one snapshot of system information
+type SystemStats = stats.SystemStats
+
+// NetInterface is a network interface with its addresses
+type NetInterface = stats.NetInterface
+
+// TempReading is a named temperature sensor value
+type TempReading = stats.TempReading
+
+// Collector gathers SystemStats. Implement it to feed the rotation manager
+// from another source.
+type Collector = stats.Collector
+
+// SystemCollector reads stats from the local system
+type SystemCollector = stats.SystemCollector
+
+// NewSystemCollector creates a collector for the local system, configured
+// by cfg.SystemInfo, cfg.Network and cfg.Pages
+func NewSystemCollector(cfg *config.Config) (*SystemCollector, error) {
+	return stats.NewSystemCollector(cfg)
+}
+
+// Service reads a SystemCollector's sources in the background, each on its
+// own interval, and serves the latest readings without blocking. Start it
+// before handing it to a rotation manager as its Collector.
+type Service = stats.Service
+
+// NewService creates a service over sc, which must not be used directly
+// once the service has started
+func NewService(sc *SystemCollector) *Service {
+	return stats.NewService(sc)
+}
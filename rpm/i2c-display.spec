@@ -58,6 +58,7 @@ install -m 0755 -vp %{gobuilddir}/bin/* %{buildroot}%{_bindir}/
 # Install config
 install -d %{buildroot}%{_sysconfdir}/i2c-display
 install -m 0644 configs/config.example.json %{buildroot}%{_sysconfdir}/i2c-display/config.json
+install -d %{buildroot}%{_sysconfdir}/i2c-display/pages.d
 
 # Install example configs to docdir
 install -d %{buildroot}%{_docdir}/i2c-display/configs/platforms
@@ -92,8 +93,10 @@ install -m 0644 man/i2c-displayd.1 %{buildroot}%{_mandir}/man1/i2c-displayd.1
 %doc BUILDING.md CHANGELOG.md CONTRIBUTING.md DISPLAY_TYPES.md LICENSES.md README.md SECURITY.md
 %doc %{_docdir}/i2c-display/configs/
 %{_bindir}/i2c-displayd
+%{_bindir}/i2c-displayctl
 %{_mandir}/man1/i2c-displayd.1*
 %config(noreplace) %{_sysconfdir}/i2c-display/config.json
+%dir %{_sysconfdir}/i2c-display/pages.d
 %{_unitdir}/i2c-display.service
 
 %changelog